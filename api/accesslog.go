@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// siad can log every API request to an access log, with the method, path,
+// status, latency and caller of the request. The query string and the body of
+// a form or JSON request are logged as well, so that the calls of an
+// integration can be debugged without capturing its traffic, but the values
+// of parameters that hold secrets, such as passwords, seeds and the paths of
+// keyfiles, are replaced with RedactedValue. Other bodies, and bodies larger
+// than maxLoggedBodySize, are not logged. Credentials sent in headers are
+// never logged.
+
+const (
+	// RedactedValue replaces the value of a sensitive parameter in the
+	// access log.
+	RedactedValue = "REDACTED"
+
+	// maxLoggedBodySize is the size of the largest request body that is
+	// written to the access log.
+	maxLoggedBodySize = 4 << 10
+)
+
+var (
+	// sensitiveParamWords are the words that mark the name of a parameter
+	// as sensitive. They cover parameters such as encryptionpassword,
+	// newpassword, seed and keyfiles.
+	sensitiveParamWords = []string{"password", "seed", "secret", "keyfile"}
+)
+
+// statusWriter records the status of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	mu     sync.Mutex
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.mu.Lock()
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.mu.Unlock()
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	sw.mu.Lock()
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	sw.mu.Unlock()
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, if the underlying writer does.
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Status returns the status of the response, which is 200 if the handler
+// did not write one.
+func (sw *statusWriter) Status() int {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
+
+// sensitiveParam returns true if the value of a parameter must not be
+// logged.
+func sensitiveParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range sensitiveParamWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValues replaces the values of the sensitive parameters of a query
+// string or form body, and returns it encoded.
+func redactValues(values url.Values) string {
+	for name := range values {
+		if sensitiveParam(name) {
+			values[name] = []string{RedactedValue}
+		}
+	}
+	return values.Encode()
+}
+
+// redactJSON replaces the values of the sensitive fields of a decoded JSON
+// value, at any depth.
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if sensitiveParam(name) {
+				v[name] = RedactedValue
+			} else {
+				v[name] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
+
+// redactBody returns the body of a request as it is written to the access
+// log. ok is false if the body is not logged.
+func redactBody(contentType string, body []byte) (redacted string, ok bool) {
+	if len(body) == 0 || len(body) > maxLoggedBodySize {
+		return "", false
+	}
+	switch {
+	case contentType == "" || strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "", false
+		}
+		return redactValues(values), true
+	case strings.HasPrefix(contentType, "application/json"):
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return "", false
+		}
+		b, err := json.Marshal(redactJSON(v))
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+	return "", false
+}
+
+// readLoggedBody reads the body of a request if it can be logged, and
+// replaces it with a reader that returns the same bytes to the handler.
+func readLoggedBody(req *http.Request) []byte {
+	if req.Body == nil || req.ContentLength == 0 || req.ContentLength > maxLoggedBodySize {
+		return nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxLoggedBodySize+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+// LogRequests is middleware that writes every request to an access log, with
+// the values of sensitive parameters redacted. If log is nil, h is returned
+// unchanged.
+func LogRequests(h http.Handler, log *persist.Logger) http.Handler {
+	if log == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		body := readLoggedBody(req)
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, req)
+
+		call := req.Method + " " + req.URL.Path
+		if req.URL.RawQuery != "" {
+			if query, err := url.ParseQuery(req.URL.RawQuery); err == nil {
+				call += "?" + redactValues(query)
+			}
+		}
+		caller := clientID(req)
+		if caller == "" {
+			caller = "-"
+		}
+		line := fmt.Sprintf("%v %v %v %v %q", call, sw.Status(), time.Since(start).Round(time.Microsecond), caller, req.UserAgent())
+		if b, ok := redactBody(req.Header.Get("Content-Type"), body); ok {
+			line += " " + b
+		}
+		log.Println(line)
+	})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// TestRedactBody checks that the values of sensitive parameters are redacted
+// from the logged bodies, and that other bodies are not logged.
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		contentType, body string
+		want              string
+		logged            bool
+	}{
+		{"application/x-www-form-urlencoded", "encryptionpassword=foo&dictionary=english", "dictionary=english&encryptionpassword=" + RedactedValue, true},
+		{"", "seed=foo+bar&keyfiles=%2Fhome%2Fkey.siakey&amount=5", "amount=5&keyfiles=" + RedactedValue + "&seed=" + RedactedValue, true},
+		{"application/json", `{"calls":[{"path":"/wallet/unlock","params":{"EncryptionPassword":"foo"}}],"newpassword":{"a":1}}`, `{"calls":[{"params":{"EncryptionPassword":"` + RedactedValue + `"},"path":"/wallet/unlock"}],"newpassword":"` + RedactedValue + `"}`, true},
+		{"application/json", `{"password":"foo"`, "", false},
+		{"application/octet-stream", "password=foo", "", false},
+		{"", strings.Repeat("a", maxLoggedBodySize+1), "", false},
+		{"", "", "", false},
+	}
+	for _, test := range tests {
+		got, ok := redactBody(test.contentType, []byte(test.body))
+		if ok != test.logged || got != test.want {
+			t.Errorf("redactBody(%q, %q): got %q, %v", test.contentType, test.body, got, ok)
+		}
+		if strings.Contains(got, "foo") {
+			t.Errorf("redactBody(%q, %q): secret was logged: %q", test.contentType, test.body, got)
+		}
+	}
+}
+
+// TestLogRequests checks that requests are written to the access log with
+// their secrets redacted, and that the handler still receives the body.
+func TestLogRequests(t *testing.T) {
+	var buf bytes.Buffer
+	log := persist.NewLogger(&buf)
+	h := LogRequests(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		if string(b) != "encryptionpassword=foo" {
+			t.Error("handler received the wrong body:", string(b))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}), log)
+
+	req := httptest.NewRequest("POST", "/wallet/unlock?wallet=hot&password=foo", strings.NewReader("encryptionpassword=foo"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Basic Zm9vOmZvbw==")
+	req.Header.Set("User-Agent", "Sia-Agent")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	logged := buf.String()
+	want := `POST /wallet/unlock?password=` + RedactedValue + `&wallet=hot 401 `
+	if !strings.Contains(logged, want) {
+		t.Fatalf("expected log to contain %q, got:\n%v", want, logged)
+	}
+	want = `192.0.2.1 "Sia-Agent" encryptionpassword=` + RedactedValue
+	if !strings.Contains(logged, want) {
+		t.Fatalf("expected log to contain %q, got:\n%v", want, logged)
+	}
+	if strings.Contains(logged, "foo") || strings.Contains(logged, "Zm9v") {
+		t.Fatal("secret was logged:", logged)
+	}
+}
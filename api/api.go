@@ -1,7 +1,9 @@
 package api
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -13,23 +15,21 @@ import (
 )
 
 // Error is a type that is encoded as JSON and returned in an API response in
-// the event of an error. Only the Message field is required. More fields may
-// be added to this struct in the future for better error reporting.
+// the event of an error. Only the Message field is required; WriteError fills
+// in the Code.
 type Error struct {
+	// Code is a stable, machine-readable identifier of the error, such as
+	// "wallet_locked" or "insufficient_funds", that clients can branch on
+	// instead of matching the Message. It is one of the ErrCode constants.
+	Code string `json:"code,omitempty"`
+
 	// Message describes the error in English. Typically it is set to
 	// `err.Error()`. This field is required.
 	Message string `json:"message"`
 
-	// TODO: add a Param field with the (omitempty option in the json tag)
-	// to indicate that the error was caused by an invalid, missing, or
-	// incorrect parameter. This is not trivial as the API does not
-	// currently do parameter validation itself. For example, the
-	// /gateway/connect endpoint relies on the gateway.Connect method to
-	// validate the netaddress. However, this prevents the API from knowing
-	// whether an error returned by gateway.Connect is because of a
-	// connection error or an invalid netaddress parameter. Validating
-	// parameters in the API is not sufficient, as a parameter's value may
-	// be valid or invalid depending on the current state of a module.
+	// Param is the name of the parameter that caused the error, if the
+	// error was caused by an invalid or missing parameter.
+	Param string `json:"param,omitempty"`
 }
 
 // Error implements the error interface for the Error type. It returns only the
@@ -89,11 +89,13 @@ func HttpPOSTAuthenticated(url string, data string, password string) (resp *http
 }
 
 // RequireUserAgent is middleware that requires all requests to set a
-// UserAgent that contains the specified string.
+// UserAgent that contains the specified string. Requests from the web pages of
+// an origin allowed by AllowCORS are exempt, as browsers cannot set the
+// UserAgent.
 func RequireUserAgent(h http.Handler, ua string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		if !strings.Contains(req.UserAgent(), ua) {
-			WriteError(w, Error{"Browser access disabled due to security vulnerability. Use Sia-UI or siac."}, http.StatusBadRequest)
+		if !strings.Contains(req.UserAgent(), ua) && !fromAllowedOrigin(req) {
+			WriteError(w, Error{Message: "Browser access disabled due to security vulnerability. Use Sia-UI or siac."}, http.StatusBadRequest)
 			return
 		}
 		h.ServeHTTP(w, req)
@@ -110,9 +112,11 @@ func RequirePassword(h httprouter.Handle, password string) httprouter.Handle {
 	}
 	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		_, pass, ok := req.BasicAuth()
-		if !ok || pass != password {
+		// Compare in constant time, so that the password cannot be
+		// recovered by timing failed requests.
+		if !ok || subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
 			w.Header().Set("WWW-Authenticate", "Basic realm=\"SiaAPI\"")
-			WriteError(w, Error{"API authentication failed."}, http.StatusUnauthorized)
+			WriteError(w, Error{Message: "API authentication failed."}, http.StatusUnauthorized)
 			return
 		}
 		h(w, req, ps)
@@ -163,7 +167,22 @@ type API struct {
 	tpool    modules.TransactionPool
 	wallet   modules.Wallet
 
+	// wallets contains additional wallets, keyed by name, that can be
+	// selected in any wallet call using the 'wallet' parameter. The wallet
+	// field is used when no name is provided.
+	wallets map[string]modules.Wallet
+
 	router http.Handler
+
+	// routes serves the routes of the API without the middleware applied
+	// to each request, for the calls made by /batch.
+	routes http.Handler
+
+	// spec is the OpenAPI document served at /spec.
+	spec OpenAPISpec
+
+	// limiter rate limits the requests of each client.
+	limiter *rateLimiter
 }
 
 // api.ServeHTTP implements the http.Handler interface.
@@ -184,16 +203,38 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		renter:   r,
 		tpool:    tp,
 		wallet:   w,
+		wallets:  make(map[string]modules.Wallet),
+		limiter:  newRateLimiter(),
 	}
 
 	// Register API handlers
-	router := httprouter.New()
+	router := api.newRouter(requiredPassword)
+
+	// Generate the OpenAPI document from the registered routes.
+	api.spec = buildSpec(router.routes)
+
+	// Apply UserAgent middleware and return the API
+	api.routes = ServeVersioned(router)
+	api.router = cleanCloseHandler(RequireUserAgent(api.routes, requiredUserAgent))
+	return api
+}
+
+// newRouter returns a router with the routes of the modules of the API
+// registered.
+func (api *API) newRouter(requiredPassword string) *specRouter {
+	router := &specRouter{Router: httprouter.New(), limiter: api.limiter}
 	router.NotFound = http.HandlerFunc(UnrecognizedCallHandler)
 	router.RedirectTrailingSlash = false
+	router.GET("/spec", api.specHandler)
+	router.GET("/events", api.eventsHandler)
+	router.POST("/batch", api.batchHandler)
 
 	// Consensus API Calls
 	if api.cs != nil {
 		router.GET("/consensus", api.consensusHandler)
+		router.GET("/consensus/deployments", api.consensusDeploymentsHandler)
+		router.GET("/consensus/waitforblock", api.consensusWaitForBlockHandler)
+		router.POST("/consensus/validate/reserves", api.consensusValidateReservesHandler)
 		router.POST("/consensus/validate/transactionset", api.consensusValidateTransactionsetHandler)
 	}
 
@@ -202,6 +243,8 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		router.GET("/explorer", api.explorerHandler)
 		router.GET("/explorer/blocks/:height", api.explorerBlocksHandler)
 		router.GET("/explorer/hashes/:hash", api.explorerHashHandler)
+		router.GET("/explorer/mempool", api.explorerMempoolHandler)
+		router.POST("/explorer/reindex", RequirePassword(api.explorerReindexHandler, requiredPassword))
 	}
 
 	// Gateway API Calls
@@ -209,6 +252,11 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		router.GET("/gateway", api.gatewayHandler)
 		router.POST("/gateway/connect/:netaddress", RequirePassword(api.gatewayConnectHandler, requiredPassword))
 		router.POST("/gateway/disconnect/:netaddress", RequirePassword(api.gatewayDisconnectHandler, requiredPassword))
+		router.GET("/gateway/peers/summary", api.gatewayPeersSummaryHandler)
+		router.POST("/gateway/add/:netaddress", RequirePassword(api.gatewayAddHandler, requiredPassword))
+		router.POST("/gateway/remove/:netaddress", RequirePassword(api.gatewayRemoveHandler, requiredPassword))
+		router.POST("/gateway/ban/:address", RequirePassword(api.gatewayBanHandler, requiredPassword))
+		router.POST("/gateway/unban/:address", RequirePassword(api.gatewayUnbanHandler, requiredPassword))
 	}
 
 	// Host API Calls
@@ -217,7 +265,14 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		router.GET("/host", api.hostHandlerGET)                                                   // Get the host status.
 		router.POST("/host", RequirePassword(api.hostHandlerPOST, requiredPassword))              // Change the settings of the host.
 		router.POST("/host/announce", RequirePassword(api.hostAnnounceHandler, requiredPassword)) // Announce the host to the network.
+		router.GET("/host/consistency", api.hostConsistencyHandlerGET)
+		router.POST("/host/consistency", RequirePassword(api.hostConsistencyHandlerPOST, requiredPassword))
 		router.GET("/host/estimatescore", api.hostEstimateScoreGET)
+		router.GET("/host/contracts", api.hostContractsHandler)
+		router.GET("/host/contracts/:id/usage", api.hostContractUsageHandler)
+		router.GET("/host/obligations/:id/failure", api.hostObligationFailureHandler)
+		router.GET("/host/simulatedowntime", api.hostSimulateDowntimeHandler)
+		router.POST("/host/reindexobligations", RequirePassword(api.hostReindexObligationsHandler, requiredPassword))
 
 		// Calls pertaining to the storage manager that the host uses.
 		router.GET("/host/storage", api.storageHandler)
@@ -234,15 +289,19 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		router.POST("/miner/header", RequirePassword(api.minerHeaderHandlerPOST, requiredPassword))
 		router.GET("/miner/start", RequirePassword(api.minerStartHandler, requiredPassword))
 		router.GET("/miner/stop", RequirePassword(api.minerStopHandler, requiredPassword))
+		router.GET("/miner/withhold", api.minerWithholdHandlerGET)
+		router.POST("/miner/withhold", RequirePassword(api.minerWithholdHandlerPOST, requiredPassword))
 	}
 
 	// Renter API Calls
 	if api.renter != nil {
 		router.GET("/renter", api.renterHandlerGET)
 		router.POST("/renter", RequirePassword(api.renterHandlerPOST, requiredPassword))
+		router.GET("/renter/bandwidth", api.renterBandwidthHandler)
 		router.GET("/renter/contracts", api.renterContractsHandler)
 		router.GET("/renter/downloads", api.renterDownloadsHandler)
 		router.GET("/renter/files", api.renterFilesHandler)
+		router.GET("/renter/files/*siapath", api.renterFileHostsHandler)
 		router.GET("/renter/prices", api.renterPricesHandler)
 
 		// TODO: re-enable these routes once the new .sia format has been
@@ -252,11 +311,16 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 		// router.GET("/renter/share", RequirePassword(api.renterShareHandler, requiredPassword))
 		// router.GET("/renter/shareascii", RequirePassword(api.renterShareAsciiHandler, requiredPassword))
 
+		router.POST("/renter/contracts/recover", RequirePassword(api.renterContractsRecoverHandler, requiredPassword))
 		router.POST("/renter/delete/*siapath", RequirePassword(api.renterDeleteHandler, requiredPassword))
 		router.GET("/renter/download/*siapath", RequirePassword(api.renterDownloadHandler, requiredPassword))
 		router.GET("/renter/downloadasync/*siapath", RequirePassword(api.renterDownloadAsyncHandler, requiredPassword))
+		router.POST("/renter/recoverfiles", RequirePassword(api.renterRecoverFilesHandler, requiredPassword))
 		router.POST("/renter/rename/*siapath", RequirePassword(api.renterRenameHandler, requiredPassword))
 		router.POST("/renter/upload/*siapath", RequirePassword(api.renterUploadHandler, requiredPassword))
+		router.POST("/renter/update/*siapath", RequirePassword(api.renterUpdateHandler, requiredPassword))
+		router.GET("/renter/prefetch", api.renterPrefetchHandlerGET)
+		router.POST("/renter/prefetch/*siapath", RequirePassword(api.renterPrefetchHandlerPOST, requiredPassword))
 
 		// HostDB endpoints.
 		router.GET("/hostdb/active", api.hostdbActiveHandler)
@@ -268,7 +332,9 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 	if api.tpool != nil {
 		router.GET("/tpool/fee", api.tpoolFeeHandlerGET)
 		router.GET("/tpool/raw/:id", api.tpoolRawHandlerGET)
-		router.POST("/tpool/raw", api.tpoolRawHandlerPOST)
+		router.GET("/tpool/transactions", api.tpoolTransactionsHandlerGET)
+		router.GET("/tpool/transactions/:id", api.tpoolTransactionHandlerGET)
+		router.POST("/tpool/raw", RequirePassword(api.tpoolRawHandlerPOST, requiredPassword))
 
 		// TODO: re-enable this route once the transaction pool API has been finalized
 		//router.GET("/transactionpool/transactions", api.transactionpoolTransactionsHandler)
@@ -276,40 +342,81 @@ func New(requiredUserAgent string, requiredPassword string, cs modules.Consensus
 
 	// Wallet API Calls
 	if api.wallet != nil {
-		router.GET("/wallet", api.walletHandler)
-		router.POST("/wallet/033x", RequirePassword(api.wallet033xHandler, requiredPassword))
-		router.GET("/wallet/address", RequirePassword(api.walletAddressHandler, requiredPassword))
-		router.GET("/wallet/addresses", api.walletAddressesHandler)
-		router.GET("/wallet/backup", RequirePassword(api.walletBackupHandler, requiredPassword))
-		router.POST("/wallet/init", RequirePassword(api.walletInitHandler, requiredPassword))
-		router.POST("/wallet/init/seed", RequirePassword(api.walletInitSeedHandler, requiredPassword))
-		router.POST("/wallet/lock", RequirePassword(api.walletLockHandler, requiredPassword))
-		router.POST("/wallet/seed", RequirePassword(api.walletSeedHandler, requiredPassword))
-		router.GET("/wallet/seeds", RequirePassword(api.walletSeedsHandler, requiredPassword))
-		router.POST("/wallet/siacoins", RequirePassword(api.walletSiacoinsHandler, requiredPassword))
-		router.POST("/wallet/siafunds", RequirePassword(api.walletSiafundsHandler, requiredPassword))
-		router.POST("/wallet/siagkey", RequirePassword(api.walletSiagkeyHandler, requiredPassword))
-		router.POST("/wallet/sweep/seed", RequirePassword(api.walletSweepSeedHandler, requiredPassword))
-		router.GET("/wallet/transaction/:id", api.walletTransactionHandler)
-		router.GET("/wallet/transactions", api.walletTransactionsHandler)
-		router.GET("/wallet/transactions/:addr", api.walletTransactionsAddrHandler)
-		router.GET("/wallet/verify/address/:addr", api.walletVerifyAddressHandler)
-		router.POST("/wallet/unlock", RequirePassword(api.walletUnlockHandler, requiredPassword))
-		router.POST("/wallet/changepassword", RequirePassword(api.walletChangePasswordHandler, requiredPassword))
+		router.GET("/wallet", api.withWallet((*API).walletHandler))
+		router.GET("/wallets", api.walletsHandler)
+		router.POST("/wallet/033x", RequirePassword(api.withWallet((*API).wallet033xHandler), requiredPassword))
+		router.GET("/wallet/address", RequirePassword(api.withWallet((*API).walletAddressHandler), requiredPassword))
+		router.GET("/wallet/address/:index", RequirePassword(api.withWallet((*API).walletAddressIndexHandler), requiredPassword))
+		router.GET("/wallet/addresses", api.withWallet((*API).walletAddressesHandler))
+		router.GET("/wallet/backup", RequirePassword(api.withWallet((*API).walletBackupHandler), requiredPassword))
+		router.GET("/wallet/events", api.withWallet((*API).walletEventsHandler))
+		router.GET("/wallet/history/daily", api.withWallet((*API).walletHistoryDailyHandler))
+		router.POST("/wallet/init", RequirePassword(api.withWallet((*API).walletInitHandler), requiredPassword))
+		router.POST("/wallet/init/seed", RequirePassword(api.withWallet((*API).walletInitSeedHandler), requiredPassword))
+		router.POST("/wallet/lock", RequirePassword(api.withWallet((*API).walletLockHandler), requiredPassword))
+		router.POST("/wallet/partial/create", RequirePassword(api.withWallet((*API).walletPartialCreateHandler), requiredPassword))
+		router.POST("/wallet/partial/finalize", RequirePassword(api.withWallet((*API).walletPartialFinalizeHandler), requiredPassword))
+		router.POST("/wallet/partial/merge", RequirePassword(api.withWallet((*API).walletPartialMergeHandler), requiredPassword))
+		router.POST("/wallet/partial/sign", RequirePassword(api.withWallet((*API).walletPartialSignHandler), requiredPassword))
+		router.POST("/wallet/reindex", RequirePassword(api.withWallet((*API).walletReindexHandler), requiredPassword))
+		router.GET("/wallet/requests", api.withWallet((*API).walletRequestsHandlerGET))
+		router.POST("/wallet/requests", RequirePassword(api.withWallet((*API).walletRequestsHandlerPOST), requiredPassword))
+		router.GET("/wallet/requests/:addr", api.withWallet((*API).walletRequestHandler))
+		router.GET("/wallet/reserves", RequirePassword(api.withWallet((*API).walletReservesHandler), requiredPassword))
+		router.POST("/wallet/restore", RequirePassword(api.withWallet((*API).walletRestoreHandler), requiredPassword))
+		router.POST("/wallet/seed", RequirePassword(api.withWallet((*API).walletSeedHandler), requiredPassword))
+		router.GET("/wallet/settings", api.withWallet((*API).walletSettingsHandlerGET))
+		router.POST("/wallet/settings", RequirePassword(api.withWallet((*API).walletSettingsHandlerPOST), requiredPassword))
+		router.GET("/wallet/seeds", RequirePassword(api.withWallet((*API).walletSeedsHandler), requiredPassword))
+		router.POST("/wallet/siacoins", RequirePassword(api.withWallet((*API).walletSiacoinsHandler), requiredPassword))
+		router.POST("/wallet/siafunds", RequirePassword(api.withWallet((*API).walletSiafundsHandler), requiredPassword))
+		router.POST("/wallet/siafunds/broadcast", RequirePassword(api.withWallet((*API).walletSiafundsBroadcastHandler), requiredPassword))
+		router.POST("/wallet/siafunds/claim", RequirePassword(api.withWallet((*API).walletSiafundsClaimHandler), requiredPassword))
+		router.POST("/wallet/siafunds/prepare", RequirePassword(api.withWallet((*API).walletSiafundsPrepareHandler), requiredPassword))
+		router.POST("/wallet/siafunds/trackdir", RequirePassword(api.withWallet((*API).walletSiafundsTrackDirHandler), requiredPassword))
+		router.POST("/wallet/siafunds/watchsiagaddress", RequirePassword(api.withWallet((*API).walletSiafundsWatchSiagAddressHandler), requiredPassword))
+		router.POST("/wallet/siagkey", RequirePassword(api.withWallet((*API).walletSiagkeyHandler), requiredPassword))
+		router.POST("/wallet/sweep/seed", RequirePassword(api.withWallet((*API).walletSweepSeedHandler), requiredPassword))
+		router.GET("/wallet/transaction/:id", api.withWallet((*API).walletTransactionHandler))
+		router.POST("/wallet/transaction/:id/bumpfee", RequirePassword(api.withWallet((*API).walletTransactionBumpFeeHandler), requiredPassword))
+		router.POST("/wallet/transaction/:id/cancel", RequirePassword(api.withWallet((*API).walletTransactionCancelHandler), requiredPassword))
+		router.POST("/wallet/transaction/:id/label", RequirePassword(api.withWallet((*API).walletTransactionLabelHandler), requiredPassword))
+		router.GET("/wallet/transactions", api.withWallet((*API).walletTransactionsHandler))
+		router.GET("/wallet/transactions/:addr", api.withWallet((*API).walletTransactionsAddrHandler))
+		router.GET("/wallet/verify/address/:addr", api.withWallet((*API).walletVerifyAddressHandler))
+		router.GET("/wallet/unconfirmed", api.withWallet((*API).walletUnconfirmedHandler))
+		router.POST("/wallet/unlock", RequirePassword(api.withWallet((*API).walletUnlockHandler), requiredPassword))
+		router.POST("/wallet/changepassword", RequirePassword(api.withWallet((*API).walletChangePasswordHandler), requiredPassword))
 	}
 
-	// Apply UserAgent middleware and return the API
-	api.router = cleanCloseHandler(RequireUserAgent(router, requiredUserAgent))
-	return api
+	return router
+}
+
+// AddWallet makes an additional wallet available to the API under the
+// provided name. Wallet calls select it by setting the 'wallet' parameter.
+// AddWallet must be called before the API begins serving requests.
+func (api *API) AddWallet(name string, w modules.Wallet) error {
+	if name == "" {
+		return errors.New("wallet name cannot be empty")
+	}
+	if _, exists := api.wallets[name]; exists {
+		return errors.New("a wallet named " + name + " already exists")
+	}
+	api.wallets[name] = w
+	return nil
 }
 
 // UnrecognizedCallHandler handles calls to unknown pages (404).
 func UnrecognizedCallHandler(w http.ResponseWriter, req *http.Request) {
-	WriteError(w, Error{"404 - Refer to API.md"}, http.StatusNotFound)
+	WriteError(w, Error{Message: "404 - Refer to API.md"}, http.StatusNotFound)
 }
 
-// WriteError an error to the API caller.
+// WriteError an error to the API caller. If the error has no Code, it is
+// derived from the status code.
 func WriteError(w http.ResponseWriter, err Error, code int) {
+	if err.Code == "" {
+		err.Code = errorCode(err, code)
+	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(code)
 	encodingErr := json.NewEncoder(w).Encode(err)
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// /batch makes several API calls in a single request, so that clients on
+// high-latency links need only one round trip to fetch e.g. the status of the
+// wallet, the consensus height and the status of the host. The calls are
+// made in order, each with the headers of the batch request, so they are
+// authenticated and rate limited like calls made on their own. A call that
+// fails does not stop the calls after it. Routes whose response may not be
+// JSON, such as downloads, and /batch and /events cannot be batched; calls to
+// them are rejected before they are made. The responses of a batch are
+// buffered, so their total size is limited.
+
+const (
+	// maxBatchCalls is the largest number of calls in a batch.
+	maxBatchCalls = 64
+
+	// maxBatchSize is the largest size of the body of a batch request.
+	maxBatchSize = 1 << 20
+
+	// maxBatchResponseSize is the largest total size of the responses of the
+	// calls in a batch.
+	maxBatchResponseSize = 16 << 20
+)
+
+var (
+	// errBatchResponseTooLarge is returned by a batchResponseWriter when the
+	// response goes over its limit.
+	errBatchResponseTooLarge = errors.New("response is too large for a batch")
+)
+
+type (
+	// BatchCall is an API call made by /batch. Path may include a query
+	// string and a version prefix. Params are form values, sent in the
+	// query string of a GET call and in the body of a POST call. Body is the
+	// JSON body of routes that take one.
+	BatchCall struct {
+		Method string            `json:"method"`
+		Path   string            `json:"path"`
+		Params map[string]string `json:"params,omitempty"`
+		Body   json.RawMessage   `json:"body,omitempty"`
+	}
+
+	// BatchResponse is the response to a BatchCall. Body is the JSON
+	// response, and is omitted if the call responded with no content.
+	BatchResponse struct {
+		Status int             `json:"status"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	}
+
+	// batchResponseWriter records the response to a BatchCall. Writes that
+	// take the body over limit fail and set tooLarge.
+	batchResponseWriter struct {
+		header   http.Header
+		status   int
+		body     bytes.Buffer
+		limit    int
+		tooLarge bool
+	}
+)
+
+// Header implements http.ResponseWriter.
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write implements http.ResponseWriter.
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.tooLarge || w.body.Len()+len(b) > w.limit {
+		w.tooLarge = true
+		return 0, errBatchResponseTooLarge
+	}
+	return w.body.Write(b)
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *batchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// batchError returns the response to a call that could not be made.
+func batchError(status int, message string) BatchResponse {
+	err := Error{Message: message}
+	err.Code = errorCode(err, status)
+	body, _ := json.Marshal(err)
+	return BatchResponse{Status: status, Body: body}
+}
+
+// newBatchRequest returns the request of a call, made with the headers and
+// context of the batch request.
+func newBatchRequest(req *http.Request, call BatchCall) (*http.Request, error) {
+	if call.Method != "GET" && call.Method != "POST" {
+		return nil, Error{Message: "method must be GET or POST"}
+	}
+	u, err := url.Parse(call.Path)
+	if err != nil || !strings.HasPrefix(u.Path, "/") || u.Host != "" {
+		return nil, Error{Message: "path must be an absolute path of the API"}
+	}
+	_, path, _ := splitVersion(u.Path)
+	if doc, ok := lookupRouteDoc(call.Method, path); ok && (doc.nobatch || doc.binary) {
+		return nil, Error{Message: path + " cannot be called in a batch"}
+	}
+	if len(call.Params) > 0 && len(call.Body) > 0 {
+		return nil, Error{Message: "a call cannot have both params and a body"}
+	}
+
+	params := make(url.Values)
+	for k, v := range call.Params {
+		params.Set(k, v)
+	}
+	var body []byte
+	contentType := ""
+	switch {
+	case len(call.Body) > 0:
+		body, contentType = call.Body, "application/json"
+	case call.Method == "POST":
+		body, contentType = []byte(params.Encode()), "application/x-www-form-urlencoded"
+	case len(params) > 0:
+		q := u.Query()
+		for k, v := range params {
+			q[k] = v
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	// The version of the batch request is cleared from the context, so that
+	// the version prefix of the call is handled.
+	ctx := context.WithValue(req.Context(), versionKey{}, nil)
+	sub, err := http.NewRequest(call.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, Error{Message: err.Error()}
+	}
+	sub = sub.WithContext(ctx)
+	sub.Header = req.Header.Clone()
+	sub.Header.Del("Content-Length")
+	sub.Header.Del("Content-Type")
+	if contentType != "" {
+		sub.Header.Set("Content-Type", contentType)
+	}
+	sub.Host = req.Host
+	sub.RemoteAddr = req.RemoteAddr
+	return sub, nil
+}
+
+// batchHandler handles API calls to /batch.
+func (api *API) batchHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var calls []BatchCall
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBatchSize)).Decode(&calls)
+	if err != nil {
+		WriteError(w, Error{Message: "could not decode batch: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if len(calls) > maxBatchCalls {
+		WriteError(w, Error{Message: "a batch can contain at most " + strconv.Itoa(maxBatchCalls) + " calls"}, http.StatusBadRequest)
+		return
+	}
+
+	responses := make([]BatchResponse, len(calls))
+	remaining := maxBatchResponseSize
+	for i, call := range calls {
+		if req.Context().Err() != nil {
+			return
+		}
+		sub, err := newBatchRequest(req, call)
+		if err != nil {
+			responses[i] = batchError(http.StatusBadRequest, err.Error())
+			continue
+		}
+		bw := &batchResponseWriter{header: make(http.Header), limit: remaining}
+		api.routes.ServeHTTP(bw, sub)
+		if bw.status == 0 {
+			bw.status = http.StatusOK
+		}
+		if bw.tooLarge {
+			responses[i] = batchError(http.StatusNotAcceptable, "the response of "+call.Path+" is too large for a batch, make the call outside of a batch")
+			continue
+		}
+		remaining -= bw.body.Len()
+		body := bytes.TrimSpace(bw.body.Bytes())
+		if len(body) > 0 && !json.Valid(body) {
+			responses[i] = batchError(http.StatusNotAcceptable, "the response of "+call.Path+" is not JSON, make the call outside of a batch")
+			continue
+		}
+		responses[i] = BatchResponse{Status: bw.status, Body: body}
+	}
+	WriteJSON(w, responses)
+}
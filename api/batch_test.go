@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestBatch checks that /batch makes its calls in order, with the API
+// password of the batch request.
+func TestBatch(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createAuthenticatedServerTester(t.Name(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	batch := func(password string, calls []BatchCall) []BatchResponse {
+		body, err := json.Marshal(calls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest("POST", "http://"+st.server.listener.Addr().String()+"/batch", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("User-Agent", "Sia-Agent")
+		if password != "" {
+			req.SetBasicAuth("", password)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatal("batch failed:", decodeError(resp))
+		}
+		var responses []BatchResponse
+		if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+			t.Fatal(err)
+		}
+		if len(responses) != len(calls) {
+			t.Fatalf("expected %v responses, got %v", len(calls), len(responses))
+		}
+		return responses
+	}
+
+	responses := batch("password", []BatchCall{
+		{Method: "GET", Path: "/consensus"},
+		{Method: "GET", Path: "/v1/wallet/transactions", Params: map[string]string{"startheight": "0", "endheight": "10000"}},
+		{Method: "POST", Path: "/wallet/lock"},
+		{Method: "GET", Path: "/wallet"},
+		{Method: "POST", Path: "/batch"},
+		{Method: "GET", Path: "/foo"},
+		{Method: "DELETE", Path: "/wallet"},
+		{Method: "GET", Path: "/v1/renter/download/foo", Params: map[string]string{"httpresp": "true"}},
+		{Method: "GET", Path: "/miner/header"},
+	})
+	var cg ConsensusGET
+	if responses[0].Status != http.StatusOK || json.Unmarshal(responses[0].Body, &cg) != nil || cg.Height != st.cs.Height() {
+		t.Error("unexpected /consensus response:", responses[0].Status, string(responses[0].Body))
+	}
+	var wtg WalletTransactionsGET
+	if responses[1].Status != http.StatusOK || json.Unmarshal(responses[1].Body, &wtg) != nil || len(wtg.ConfirmedTransactions) == 0 {
+		t.Error("unexpected /wallet/transactions response:", responses[1].Status, string(responses[1].Body))
+	}
+	if responses[2].Status != http.StatusNoContent || responses[2].Body != nil {
+		t.Error("unexpected /wallet/lock response:", responses[2].Status, string(responses[2].Body))
+	}
+	// The calls are made in order, so the wallet is locked.
+	var wg WalletGET
+	if responses[3].Status != http.StatusOK || json.Unmarshal(responses[3].Body, &wg) != nil || wg.Unlocked {
+		t.Error("unexpected /wallet response:", responses[3].Status, string(responses[3].Body))
+	}
+	for i, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusBadRequest, http.StatusBadRequest, http.StatusBadRequest} {
+		if r := responses[4+i]; r.Status != status {
+			t.Errorf("expected status %v, got %v: %s", status, r.Status, r.Body)
+		}
+	}
+	// Routes that cannot be batched are rejected before they are called.
+	for _, r := range append([]BatchResponse{responses[4]}, responses[7:]...) {
+		var apiErr Error
+		if json.Unmarshal(r.Body, &apiErr) != nil || !strings.Contains(apiErr.Message, "cannot be called in a batch") {
+			t.Error("expected the call to be rejected, got", string(r.Body))
+		}
+	}
+
+	// Calls that require the password fail without it.
+	responses = batch("", []BatchCall{{Method: "POST", Path: "/wallet/lock"}})
+	if responses[0].Status != http.StatusUnauthorized {
+		t.Error("expected the call to fail without the password, got", responses[0].Status)
+	}
+}
+
+// TestBatchResponseWriterLimit checks that a batchResponseWriter fails writes
+// that take the response over its limit.
+func TestBatchResponseWriterLimit(t *testing.T) {
+	bw := &batchResponseWriter{header: make(http.Header), limit: 10}
+	if _, err := bw.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bw.Write([]byte("a")); err != errBatchResponseTooLarge {
+		t.Fatal("expected errBatchResponseTooLarge, got", err)
+	}
+	if !bw.tooLarge || bw.body.Len() != 10 {
+		t.Fatal("response over the limit was buffered")
+	}
+}
+
+// TestLookupRouteDoc checks that requests are matched to the routeDocs of
+// the routes that serve them.
+func TestLookupRouteDoc(t *testing.T) {
+	tests := []struct {
+		method, path string
+		summary      string
+	}{
+		{"GET", "/consensus", routeDocs["GET /consensus"].summary},
+		{"GET", "/renter/download/foo/bar", routeDocs["GET /renter/download/*siapath"].summary},
+		{"GET", "/wallet/transactions/unconfirmed", routeDocs["GET /wallet/transactions/:addr"].summary},
+		{"POST", "/consensus", ""},
+		{"GET", "/wallet/transactions/foo/bar", ""},
+		{"GET", "/foo", ""},
+	}
+	for _, test := range tests {
+		doc, ok := lookupRouteDoc(test.method, test.path)
+		if ok != (test.summary != "") || doc.summary != test.summary {
+			t.Errorf("%v %v: expected %q, got %q", test.method, test.path, test.summary, doc.summary)
+		}
+	}
+}
@@ -1,14 +1,28 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/julienschmidt/httprouter"
 )
 
+const (
+	// defaultWaitForBlockTimeout is how long a call to
+	// /consensus/waitforblock waits for a block if no timeout is provided.
+	defaultWaitForBlockTimeout = 30 * time.Second
+
+	// maxWaitForBlockTimeout is the longest a call to
+	// /consensus/waitforblock can wait for a block.
+	maxWaitForBlockTimeout = 5 * time.Minute
+)
+
 // ConsensusGET contains general information about the consensus set, with tags
 // to support idiomatic json encodings.
 type ConsensusGET struct {
@@ -17,18 +31,141 @@ type ConsensusGET struct {
 	CurrentBlock types.BlockID     `json:"currentblock"`
 	Target       types.Target      `json:"target"`
 	Difficulty   types.Currency    `json:"difficulty"`
+
+	// HeaviestKnownHeight is the height of the block with the most
+	// accumulated work that the node has seen, and OnHeaviestFork is false if
+	// that block is not the current block.
+	HeaviestKnownHeight types.BlockHeight `json:"heaviestknownheight"`
+	OnHeaviestFork      bool              `json:"onheaviestfork"`
 }
 
-// consensusHandler handles the API calls to /consensus.
-func (api *API) consensusHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+// ConsensusWaitForBlockGET contains the state of the consensus set once the
+// block that a call to /consensus/waitforblock waited for arrived, or when the
+// call timed out.
+type ConsensusWaitForBlockGET struct {
+	ConsensusGET
+	TimedOut bool `json:"timedout"`
+}
+
+// ConsensusDeploymentsGET contains the signaling progress and activation
+// state of every deployment known to the consensus set.
+type ConsensusDeploymentsGET struct {
+	Deployments []modules.DeploymentStatus `json:"deployments"`
+}
+
+// ConsensusValidateReservesPOST contains the siacoins that a proof of reserves
+// shows to be controlled at the proof's height.
+type ConsensusValidateReservesPOST struct {
+	Height types.BlockHeight `json:"height"`
+	Total  types.Currency    `json:"total"`
+}
+
+// consensusGET returns the state of the consensus set.
+func (api *API) consensusGET() ConsensusGET {
 	cbid := api.cs.CurrentBlock().ID()
 	currentTarget, _ := api.cs.ChildTarget(cbid)
-	WriteJSON(w, ConsensusGET{
+	_, heaviestHeight, onHeaviest := api.cs.HeaviestKnownBlock()
+	return ConsensusGET{
 		Synced:       api.cs.Synced(),
 		Height:       api.cs.Height(),
 		CurrentBlock: cbid,
 		Target:       currentTarget,
 		Difficulty:   currentTarget.Difficulty(),
+
+		HeaviestKnownHeight: heaviestHeight,
+		OnHeaviestFork:      onHeaviest,
+	}
+}
+
+// consensusHandler handles the API calls to /consensus.
+func (api *API) consensusHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, api.consensusGET())
+}
+
+// blockWaiter is subscribed to the consensus set while a call to
+// /consensus/waitforblock waits, and is notified of every change.
+type blockWaiter struct {
+	changed chan struct{}
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber. It must
+// not call the consensus set, which is locked while it runs.
+func (bw *blockWaiter) ProcessConsensusChange(modules.ConsensusChange) {
+	select {
+	case bw.changed <- struct{}{}:
+	default:
+	}
+}
+
+// consensusWaitForBlockHandler handles the API calls to
+// /consensus/waitforblock. The call blocks until the chain reaches 'height',
+// or, if no height is provided, until the current block changes, or the
+// timeout expires.
+func (api *API) consensusWaitForBlockHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var height types.BlockHeight
+	waitForHeight := req.FormValue("height") != ""
+	if waitForHeight {
+		h, err := strconv.ParseUint(req.FormValue("height"), 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "unable to parse height: " + err.Error(), Param: "height"}, http.StatusBadRequest)
+			return
+		}
+		height = types.BlockHeight(h)
+	}
+	timeout := defaultWaitForBlockTimeout
+	if t := req.FormValue("timeout"); t != "" {
+		secs, err := strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "unable to parse timeout: " + err.Error(), Param: "timeout"}, http.StatusBadRequest)
+			return
+		}
+		if secs > uint64(maxWaitForBlockTimeout/time.Second) {
+			WriteError(w, Error{Message: "timeout cannot be greater than " + maxWaitForBlockTimeout.String(), Param: "timeout"}, http.StatusBadRequest)
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+	}
+
+	// Stop waiting if the client goes away.
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+
+	// Subscribe before reading the current block, so that a block that
+	// arrives in between is not missed.
+	bw := &blockWaiter{changed: make(chan struct{}, 1)}
+	if err := api.cs.ConsensusSetSubscribe(bw, modules.ConsensusChangeRecent, ctx.Done()); err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /consensus/waitforblock: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	defer api.cs.Unsubscribe(bw)
+	start := api.cs.CurrentBlock().ID()
+	arrived := func() bool {
+		if waitForHeight {
+			return api.cs.Height() >= height
+		}
+		return api.cs.CurrentBlock().ID() != start
+	}
+
+	for !arrived() {
+		select {
+		case <-bw.changed:
+		case <-ctx.Done():
+			WriteJSON(w, ConsensusWaitForBlockGET{
+				ConsensusGET: api.consensusGET(),
+				TimedOut:     !arrived(),
+			})
+			return
+		}
+	}
+	WriteJSON(w, ConsensusWaitForBlockGET{
+		ConsensusGET: api.consensusGET(),
+	})
+}
+
+// consensusDeploymentsHandler handles the API calls to /consensus/deployments.
+func (api *API) consensusDeploymentsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, ConsensusDeploymentsGET{
+		Deployments: api.cs.Deployments(),
 	})
 }
 
@@ -38,13 +175,33 @@ func (api *API) consensusValidateTransactionsetHandler(w http.ResponseWriter, re
 	var txnset []types.Transaction
 	err := json.NewDecoder(req.Body).Decode(&txnset)
 	if err != nil {
-		WriteError(w, Error{"could not decode transaction set: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "could not decode transaction set: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	_, err = api.cs.TryTransactionSet(txnset)
 	if err != nil {
-		WriteError(w, Error{"transaction set validation failed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "transaction set validation failed: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
 }
+
+// consensusValidateReservesHandler handles the API calls to
+// /consensus/validate/reserves.
+func (api *API) consensusValidateReservesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var proof modules.ReservesProof
+	err := json.NewDecoder(req.Body).Decode(&proof)
+	if err != nil {
+		WriteError(w, Error{Message: "could not decode proof of reserves: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	total, err := modules.VerifyReservesProof(api.cs, proof)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "proof of reserves validation failed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, ConsensusValidateReservesPOST{
+		Height: proof.Height,
+		Total:  total,
+	})
+}
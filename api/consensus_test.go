@@ -3,11 +3,13 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/url"
 	"testing"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -40,6 +42,109 @@ func TestIntegrationConsensusGET(t *testing.T) {
 	}
 }
 
+// TestConsensusDeploymentsGET probes the GET call to /consensus/deployments.
+func TestConsensusDeploymentsGET(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var cdg ConsensusDeploymentsGET
+	err = st.getAPI("/consensus/deployments", &cdg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cdg.Deployments) != len(types.Deployments) {
+		t.Fatalf("expected %v deployments, got %v", len(types.Deployments), len(cdg.Deployments))
+	}
+	for i, d := range cdg.Deployments {
+		if d.Name != types.Deployments[i].Name || d.Bit != types.Deployments[i].Bit {
+			t.Error("wrong deployment returned in deployments GET call:", d.Name)
+		}
+		if d.Threshold != types.DeploymentThreshold {
+			t.Error("wrong threshold returned in deployments GET call:", d.Threshold)
+		}
+		height := st.server.api.cs.Height()
+		if d.WindowStart != height-height%types.DeploymentWindow || d.WindowStart+d.WindowBlocks != height+1 {
+			t.Error("wrong window returned in deployments GET call:", d.WindowStart, d.WindowBlocks)
+		}
+	}
+}
+
+// TestConsensusWaitForBlock probes the GET call to /consensus/waitforblock.
+func TestConsensusWaitForBlock(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// A height that was already reached returns immediately.
+	height := st.server.api.cs.Height()
+	var cwg ConsensusWaitForBlockGET
+	err = st.getAPI(fmt.Sprintf("/consensus/waitforblock?height=%v", height), &cwg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cwg.TimedOut || cwg.Height != height {
+		t.Fatal("wrong response to a height that was already reached:", cwg.TimedOut, cwg.Height)
+	}
+
+	// Without a new block, the call times out.
+	err = st.getAPI("/consensus/waitforblock?timeout=1", &cwg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cwg.TimedOut || cwg.Height != height {
+		t.Fatal("expected the call to time out:", cwg.TimedOut, cwg.Height)
+	}
+
+	// Wait for the next height, and for any new block, while a block is
+	// mined.
+	calls := []string{
+		fmt.Sprintf("/consensus/waitforblock?height=%v&timeout=30", height+1),
+		"/consensus/waitforblock?timeout=30",
+	}
+	for _, call := range calls {
+		errChan := make(chan error, 1)
+		var cwg ConsensusWaitForBlockGET
+		go func() {
+			errChan <- st.getAPI(call, &cwg)
+		}()
+		time.Sleep(500 * time.Millisecond)
+		b, err := st.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := <-errChan; err != nil {
+			t.Fatal(err)
+		}
+		if cwg.TimedOut || cwg.CurrentBlock != b.ID() {
+			t.Fatal("wrong response after a block was mined:", call, cwg.TimedOut, cwg.Height)
+		}
+	}
+
+	// Bad parameters are rejected.
+	for _, call := range []string{
+		"/consensus/waitforblock?height=foo",
+		"/consensus/waitforblock?timeout=-1",
+		"/consensus/waitforblock?timeout=301",
+	} {
+		if err := st.getAPI(call, &cwg); err == nil {
+			t.Error("expected an error from", call)
+		}
+	}
+}
+
 // TestConsensusValidateTransactionSet probes the POST call to
 // /consensus/validate/transactionset.
 func TestConsensusValidateTransactionSet(t *testing.T) {
@@ -131,3 +236,56 @@ func TestConsensusValidateTransactionSet(t *testing.T) {
 		t.Fatal("expected validation error")
 	}
 }
+
+// TestConsensusValidateReserves checks that a proof of reserves created with
+// /wallet/reserves is accepted by /consensus/validate/reserves, and that a
+// tampered proof is rejected.
+func TestConsensusValidateReserves(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var wrg WalletReservesGET
+	if err := st.getAPI("/wallet/reserves?amount=1000&challenge=audit", &wrg); err != nil {
+		t.Fatal(err)
+	}
+	if wrg.Proof.Challenge != "audit" || wrg.Proof.Total.Cmp(types.NewCurrency64(1000)) < 0 {
+		t.Fatal("wrong proof returned:", wrg.Proof.Challenge, wrg.Proof.Total)
+	}
+
+	validate := func(proof modules.ReservesProof) (ConsensusValidateReservesPOST, error) {
+		jsonProof, err := json.Marshal(proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := HttpPOST("http://"+st.server.listener.Addr().String()+"/consensus/validate/reserves", string(jsonProof))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var cvr ConsensusValidateReservesPOST
+		if non2xx(resp.StatusCode) {
+			return cvr, decodeError(resp)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&cvr)
+		return cvr, err
+	}
+	cvr, err := validate(wrg.Proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cvr.Total.Equals(wrg.Proof.Total) || cvr.Height != wrg.Proof.Height {
+		t.Fatal("validation returned the wrong total or height:", cvr.Total, cvr.Height)
+	}
+
+	wrg.Proof.Challenge = "another audit"
+	if _, err := validate(wrg.Proof); err == nil {
+		t.Fatal("expected a proof with a different challenge to be rejected")
+	}
+}
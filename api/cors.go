@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Web pages may only call the API from the origins that are allowed by the
+// CORS configuration of siad. A request from an allowed origin gets the CORS
+// headers that let the browser read the response, and since browsers cannot
+// set the user agent, it does not need the Sia-Agent user agent. Routes that
+// require the API password still require it. Requests from other origins are
+// served as before.
+
+var (
+	// corsAllowedHeaders are the request headers that web pages of an
+	// allowed origin may send.
+	corsAllowedHeaders = "Authorization, Content-Type"
+
+	// corsExposedHeaders are the response headers that web pages of an
+	// allowed origin may read.
+	corsExposedHeaders = "Deprecation, Retry-After, Sia-API-Version, Sunset, Warning, X-RateLimit-Limit, X-RateLimit-Remaining, " + totalCountHeader
+
+	// corsMaxAge is the number of seconds that browsers may cache the
+	// response to a preflight request.
+	corsMaxAge = "600"
+)
+
+// CORSConfig configures the cross-origin requests that the API allows.
+type CORSConfig struct {
+	// AllowedOrigins are the origins of the web pages that may call the
+	// API, e.g. https://wallet.example.com. "*" allows every origin.
+	AllowedOrigins []string
+
+	// AllowedMethods are the HTTP methods that web pages of an allowed
+	// origin may use.
+	AllowedMethods []string
+}
+
+// corsKey is the context key that marks a request from an allowed origin.
+type corsKey struct{}
+
+// fromAllowedOrigin returns true if a request was made by a web page of an
+// origin allowed by the CORS configuration.
+func fromAllowedOrigin(req *http.Request) bool {
+	allowed, _ := req.Context().Value(corsKey{}).(bool)
+	return allowed
+}
+
+// allowsOrigin returns true if the configuration allows the origin.
+func (c CORSConfig) allowsOrigin(origin string) bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsMethod returns true if the configuration allows the method.
+func (c CORSConfig) allowsMethod(method string) bool {
+	for _, m := range c.AllowedMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowCORS is middleware that serves the CORS headers to the web pages of
+// the origins allowed by c, and answers their preflight requests. If c allows
+// no origins, h is returned unchanged.
+func AllowCORS(h http.Handler, c CORSConfig) http.Handler {
+	if len(c.AllowedOrigins) == 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		if origin == "" {
+			h.ServeHTTP(w, req)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		// Answer preflight requests.
+		if method := req.Header.Get("Access-Control-Request-Method"); req.Method == "OPTIONS" && method != "" {
+			if !c.allowsOrigin(origin) || !c.allowsMethod(method) {
+				WriteError(w, Error{Message: "cross-origin " + method + " requests from " + origin + " are not allowed"}, http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !c.allowsOrigin(origin) {
+			h.ServeHTTP(w, req)
+			return
+		}
+		// Browsers do not send a preflight request for every method, so the
+		// method is checked here as well.
+		if !c.allowsMethod(req.Method) {
+			WriteError(w, Error{Message: "cross-origin " + req.Method + " requests are not allowed"}, http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), corsKey{}, true)))
+	})
+}
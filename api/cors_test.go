@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAllowCORS checks the CORS headers served to allowed and disallowed
+// origins, and that requests from allowed origins do not need the user agent.
+func TestAllowCORS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		WriteSuccess(w)
+	})
+	h := AllowCORS(RequireUserAgent(ok, "Sia-Agent"), CORSConfig{
+		AllowedOrigins: []string{"https://wallet.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+	})
+	serve := func(method, origin, preflightMethod string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/wallet", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflightMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", preflightMethod)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A preflight request from an allowed origin is answered.
+	rec := serve("OPTIONS", "https://wallet.example.com", "POST")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "https://wallet.example.com" || rec.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Fatal("unexpected preflight response:", rec.Code, rec.Header())
+	}
+	// Preflight requests for other origins or methods are refused.
+	if rec := serve("OPTIONS", "https://evil.example.com", "POST"); rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("unexpected preflight response:", rec.Code, rec.Header())
+	}
+	if rec := serve("OPTIONS", "https://wallet.example.com", "DELETE"); rec.Code != http.StatusForbidden {
+		t.Fatal("unexpected preflight response:", rec.Code)
+	}
+
+	// A request from an allowed origin gets the CORS headers and does not
+	// need the user agent.
+	rec = serve("GET", "https://wallet.example.com", "")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "https://wallet.example.com" || rec.Header().Get("Access-Control-Expose-Headers") == "" {
+		t.Fatal("unexpected response:", rec.Code, rec.Header())
+	}
+	if rec := serve("DELETE", "https://wallet.example.com", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected a disallowed method to be refused, got", rec.Code)
+	}
+
+	// Requests from other origins, or without an origin, still need the
+	// user agent.
+	for _, origin := range []string{"https://evil.example.com", ""} {
+		rec := serve("GET", origin, "")
+		if rec.Code != http.StatusBadRequest || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatal("unexpected response for origin", origin, rec.Code, rec.Header())
+		}
+	}
+
+	// Every origin is allowed with "*".
+	h = AllowCORS(ok, CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+	if rec := serve("GET", "https://any.example.com", ""); rec.Header().Get("Access-Control-Allow-Origin") != "https://any.example.com" {
+		t.Fatal("expected every origin to be allowed, got", rec.Header())
+	}
+}
@@ -31,6 +31,10 @@ func addStorageToAllHosts(sts []*serverTester) error {
 		if err != nil {
 			return err
 		}
+		err = st.waitForStorageFolders()
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// Every error response carries a Code that clients can branch on instead of
+// matching the message, which may change between releases. The codes are
+// stable: a code is never renamed or reused for a different error. Errors of
+// the modules that clients commonly handle have their own code, which the
+// handlers set with moduleErrorCode from the error itself, so that rewording
+// a message never changes its code; other errors have the code of their HTTP
+// status.
+
+const (
+	// ErrCodeBadRequest is the code of invalid requests.
+	ErrCodeBadRequest = "bad_request"
+
+	// ErrCodeInvalidParam is the code of requests with an invalid or missing
+	// parameter, named by the Param of the error.
+	ErrCodeInvalidParam = "invalid_param"
+
+	// ErrCodeUnauthorized is the code of requests without the correct API
+	// password.
+	ErrCodeUnauthorized = "unauthorized"
+
+	// ErrCodeForbidden is the code of requests that are not allowed, such as
+	// cross-origin requests from an origin that is not allowed.
+	ErrCodeForbidden = "forbidden"
+
+	// ErrCodeNotFound is the code of requests for routes or items that do
+	// not exist.
+	ErrCodeNotFound = "not_found"
+
+	// ErrCodeMethodNotAllowed is the code of requests with a method that the
+	// route does not allow.
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+
+	// ErrCodeNotAcceptable is the code of calls in a batch whose response
+	// is not JSON or is too large.
+	ErrCodeNotAcceptable = "not_acceptable"
+
+	// ErrCodeRateLimited is the code of requests over the rate limit of the
+	// client.
+	ErrCodeRateLimited = "rate_limited"
+
+	// ErrCodeInternal is the code of errors of siad.
+	ErrCodeInternal = "internal_error"
+
+	// ErrCodeUnavailable is the code of requests that siad cannot serve yet,
+	// such as those made while it is starting.
+	ErrCodeUnavailable = "unavailable"
+
+	// ErrCodeWalletLocked is the code of requests that need the wallet to
+	// be unlocked.
+	ErrCodeWalletLocked = "wallet_locked"
+
+	// ErrCodeWalletNotInitialized is the code of requests that need the
+	// wallet to have been created.
+	ErrCodeWalletNotInitialized = "wallet_not_initialized"
+
+	// ErrCodeWrongPassword is the code of requests with an incorrect wallet
+	// password.
+	ErrCodeWrongPassword = "wrong_password"
+
+	// ErrCodeInsufficientFunds is the code of requests that need more money
+	// than the wallet can spend.
+	ErrCodeInsufficientFunds = "insufficient_funds"
+
+	// ErrCodeNotSynced is the code of requests that need the consensus set
+	// to be synced.
+	ErrCodeNotSynced = "not_synced"
+)
+
+var (
+	// moduleErrorCodes are the codes of the errors of the modules. An error
+	// that is or wraps a module error has its code.
+	moduleErrorCodes = []struct {
+		err  error
+		code string
+	}{
+		{modules.ErrLockedWallet, ErrCodeWalletLocked},
+		{modules.ErrUnencryptedWallet, ErrCodeWalletNotInitialized},
+		{modules.ErrBadEncryptionKey, ErrCodeWrongPassword},
+		{modules.ErrLowBalance, ErrCodeInsufficientFunds},
+		{modules.ErrIncompleteTransactions, ErrCodeInsufficientFunds},
+		{modules.ErrNotSynced, ErrCodeNotSynced},
+	}
+
+	// statusErrorCodes are the codes of the errors of each HTTP status.
+	statusErrorCodes = map[int]string{
+		http.StatusBadRequest:          ErrCodeBadRequest,
+		http.StatusUnauthorized:        ErrCodeUnauthorized,
+		http.StatusForbidden:           ErrCodeForbidden,
+		http.StatusNotFound:            ErrCodeNotFound,
+		http.StatusMethodNotAllowed:    ErrCodeMethodNotAllowed,
+		http.StatusNotAcceptable:       ErrCodeNotAcceptable,
+		http.StatusTooManyRequests:     ErrCodeRateLimited,
+		http.StatusInternalServerError: ErrCodeInternal,
+		http.StatusServiceUnavailable:  ErrCodeUnavailable,
+	}
+)
+
+// moduleErrorCode returns the code of err if it is or wraps an error of a
+// module that has its own code, and "" otherwise, which leaves WriteError to
+// use the code of the HTTP status.
+func moduleErrorCode(err error) string {
+	for _, mc := range moduleErrorCodes {
+		if errors.Is(err, mc.err) {
+			return mc.code
+		}
+	}
+	return ""
+}
+
+// errorCode returns the code of an error written with an HTTP status, for
+// errors without a code of their own.
+func errorCode(err Error, status int) string {
+	if err.Param != "" && status == http.StatusBadRequest {
+		return ErrCodeInvalidParam
+	}
+	if code, ok := statusErrorCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return ErrCodeInternal
+	}
+	return ErrCodeBadRequest
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestModuleErrorCode probes moduleErrorCode.
+func TestModuleErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{modules.ErrLockedWallet, ErrCodeWalletLocked},
+		{build.ExtendErr("unable to fund transaction", modules.ErrLowBalance), ErrCodeInsufficientFunds},
+		{modules.ErrIncompleteTransactions, ErrCodeInsufficientFunds},
+		{modules.ErrBadEncryptionKey, ErrCodeWrongPassword},
+		{modules.ErrUnencryptedWallet, ErrCodeWalletNotInitialized},
+		{build.ExtendErr("cannot sweep", modules.ErrNotSynced), ErrCodeNotSynced},
+		// A message that reads like a module error does not have its code.
+		{errors.New(modules.ErrLockedWallet.Error()), ""},
+		{errors.New("update failed"), ""},
+	}
+	for _, test := range tests {
+		if code := moduleErrorCode(test.err); code != test.code {
+			t.Errorf("%q: expected %q, got %q", test.err, test.code, code)
+		}
+	}
+}
+
+// TestErrorCode probes errorCode.
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err    Error
+		status int
+		code   string
+	}{
+		{Error{Message: "unable to parse seconds", Param: "seconds"}, http.StatusBadRequest, ErrCodeInvalidParam},
+		{Error{Message: "error when calling /wallet/siacoins: " + modules.ErrLockedWallet.Error()}, http.StatusBadRequest, ErrCodeBadRequest},
+		{Error{Message: "API authentication failed."}, http.StatusUnauthorized, ErrCodeUnauthorized},
+		{Error{Message: "404 - Refer to API.md"}, http.StatusNotFound, ErrCodeNotFound},
+		{Error{Message: "update failed"}, http.StatusInternalServerError, ErrCodeInternal},
+		{Error{Message: "bad gateway"}, http.StatusBadGateway, ErrCodeInternal},
+		{Error{Message: "invalid"}, http.StatusConflict, ErrCodeBadRequest},
+	}
+	for _, test := range tests {
+		if code := errorCode(test.err, test.status); code != test.code {
+			t.Errorf("%q (%v): expected %v, got %v", test.err.Message, test.status, test.code, code)
+		}
+	}
+}
+
+// TestWriteErrorCode checks that WriteError fills in the code of an error,
+// but keeps a code that was set.
+func TestWriteErrorCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, Error{Message: "unable to parse amount", Param: "amount"}, http.StatusBadRequest)
+	var e Error
+	if err := json.NewDecoder(rec.Body).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Code != ErrCodeInvalidParam || e.Param != "amount" || e.Message != "unable to parse amount" {
+		t.Fatal("unexpected error:", e)
+	}
+
+	rec = httptest.NewRecorder()
+	WriteError(rec, Error{Code: ErrCodeNotSynced, Message: "consensus is not synced"}, http.StatusServiceUnavailable)
+	if err := json.NewDecoder(rec.Body).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Code != ErrCodeNotSynced {
+		t.Fatal("expected the code to be kept, got", e.Code)
+	}
+}
+
+// TestWalletErrorCodes checks that the wallet calls return the codes of the
+// module errors they fail with.
+func TestWalletErrorCodes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	expectCode := func(err error, code string) {
+		t.Helper()
+		if apiErr, ok := err.(Error); !ok || apiErr.Code != code {
+			t.Fatalf("expected an error with code %v, got %v", code, err)
+		}
+	}
+	var wag WalletAddressGET
+	if err := st.getAPI("/wallet/address", &wag); err != nil {
+		t.Fatal(err)
+	}
+	values := url.Values{}
+	values.Set("amount", types.SiacoinPrecision.Mul64(1e12).String())
+	values.Set("destination", wag.Address.String())
+	expectCode(st.stdPostAPI("/wallet/siacoins", values), ErrCodeInsufficientFunds)
+
+	if err := st.stdPostAPI("/wallet/lock", nil); err != nil {
+		t.Fatal(err)
+	}
+	expectCode(st.stdPostAPI("/wallet/siacoins", values), ErrCodeWalletLocked)
+	expectCode(st.stdPostAPI("/wallet/unlock", url.Values{"encryptionpassword": {"wrong"}}), ErrCodeWrongPassword)
+}
@@ -0,0 +1,434 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/net/websocket"
+)
+
+// The /events route streams the events of the daemon over a WebSocket, so
+// that UIs and monitoring agents do not need to poll. Each message is a JSON
+// encoded Event. Blocks, reorgs and wallet events are sent as they happen.
+// Contracts and peers are checked for changes after every block and every
+// eventPollInterval, and storage proofs are reported when a block confirms
+// them. A client that falls more than eventBufferSize events behind is
+// disconnected.
+
+const (
+	// EventBlock is sent when a block is added to the blockchain.
+	EventBlock = "block"
+
+	// EventReorg is sent when blocks are removed from the blockchain by a
+	// reorg. The blocks of the new chain are sent as EventBlock events
+	// after it.
+	EventReorg = "reorg"
+
+	// EventWallet is sent for each event of the wallet, such as a payment.
+	EventWallet = "wallet"
+
+	// EventContract is sent when the renter forms or renews a contract.
+	EventContract = "contract"
+
+	// EventStorageProof is sent when a block confirms a storage proof for a
+	// contract of the renter or a storage obligation of the host.
+	EventStorageProof = "storageproof"
+
+	// EventPeerConnect and EventPeerDisconnect are sent when the gateway
+	// connects to or disconnects from a peer.
+	EventPeerConnect    = "peerconnect"
+	EventPeerDisconnect = "peerdisconnect"
+
+	// eventBufferSize is the number of events that are buffered for a
+	// client before it is disconnected.
+	eventBufferSize = 256
+)
+
+var (
+	// eventTypes lists the types of events that can be streamed.
+	eventTypes = []string{EventBlock, EventReorg, EventWallet, EventContract, EventStorageProof, EventPeerConnect, EventPeerDisconnect}
+
+	// eventPollInterval is how often contracts and peers are checked for
+	// changes between blocks.
+	eventPollInterval = build.Select(build.Var{
+		Standard: 5 * time.Second,
+		Dev:      2 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// errUnknownEventType is returned when an unknown type of event is
+	// requested.
+	errUnknownEventType = errors.New("unknown event type")
+
+	// errEventsOrigin is returned when a web page of another site opens
+	// the stream.
+	errEventsOrigin = errors.New("cross-origin event streams are not allowed")
+)
+
+type (
+	// An Event is a message of the /events stream. The type of Data
+	// depends on Type: EventBlockData for blocks, EventReorgData for reorgs,
+	// modules.WalletEvent for wallet events, EventContractData for
+	// contracts, EventStorageProofData for storage proofs, and modules.Peer
+	// for peers.
+	Event struct {
+		Type      string          `json:"type"`
+		Timestamp types.Timestamp `json:"timestamp"`
+		Data      interface{}     `json:"data"`
+	}
+
+	// EventBlockData describes a block added to the blockchain.
+	EventBlockData struct {
+		ID        types.BlockID     `json:"id"`
+		Height    types.BlockHeight `json:"height"`
+		Timestamp types.Timestamp   `json:"timestamp"`
+	}
+
+	// EventReorgData describes the blocks removed by a reorg, most recent
+	// first. Height is the height of the last block that was kept.
+	EventReorgData struct {
+		RevertedBlocks []types.BlockID   `json:"revertedblocks"`
+		Height         types.BlockHeight `json:"height"`
+	}
+
+	// EventContractData describes a contract formed by the renter.
+	EventContractData struct {
+		ID            types.FileContractID `json:"id"`
+		HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+		NetAddress    modules.NetAddress   `json:"netaddress"`
+		StartHeight   types.BlockHeight    `json:"startheight"`
+		EndHeight     types.BlockHeight    `json:"endheight"`
+	}
+
+	// EventStorageProofData describes a storage proof confirmed in a block.
+	EventStorageProofData struct {
+		ContractID    types.FileContractID `json:"contractid"`
+		TransactionID types.TransactionID  `json:"transactionid"`
+		BlockID       types.BlockID        `json:"blockid"`
+		Height        types.BlockHeight    `json:"height"`
+	}
+)
+
+// eventStream sends the events of the daemon to a single client.
+type eventStream struct {
+	api   *API
+	types map[string]bool
+
+	events    chan Event
+	changed   chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// height is the height of the current block. It is only used by
+	// ProcessConsensusChange, which is never called concurrently.
+	height types.BlockHeight
+
+	// proofs are the storage proofs confirmed since the last check, which
+	// have not yet been matched against the contracts of the renter and
+	// the host.
+	proofs   []EventStorageProofData
+	proofsMu sync.Mutex
+
+	// contracts and peers are the state at the last check. They are only
+	// used by the poll goroutine.
+	contracts map[types.FileContractID]struct{}
+	peers     map[modules.NetAddress]modules.Peer
+}
+
+// parseEventTypes parses a comma-separated list of event types. An empty
+// list selects every type.
+func parseEventTypes(list string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	if list == "" {
+		for _, t := range eventTypes {
+			selected[t] = true
+		}
+		return selected, nil
+	}
+	for _, t := range strings.Split(list, ",") {
+		t = strings.TrimSpace(t)
+		known := false
+		for _, et := range eventTypes {
+			known = known || t == et
+		}
+		if !known {
+			return nil, errors.New(errUnknownEventType.Error() + " " + t)
+		}
+		selected[t] = true
+	}
+	return selected, nil
+}
+
+// close stops the stream. It is safe to call more than once.
+func (s *eventStream) close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+}
+
+// send queues an event for the client, if it is of a selected type. The
+// stream is closed if the client has fallen too far behind.
+func (s *eventStream) send(e Event) {
+	if !s.types[e.Type] {
+		return
+	}
+	select {
+	case s.events <- e:
+	default:
+		s.close()
+	}
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber. It must
+// not call the modules, because the consensus set is locked while it runs.
+func (s *eventStream) ProcessConsensusChange(cc modules.ConsensusChange) {
+	now := types.CurrentTimestamp()
+	if len(cc.RevertedBlocks) > 0 {
+		reverted := make([]types.BlockID, len(cc.RevertedBlocks))
+		for i, b := range cc.RevertedBlocks {
+			reverted[i] = b.ID()
+		}
+		s.height -= types.BlockHeight(len(cc.RevertedBlocks))
+		s.send(Event{Type: EventReorg, Timestamp: now, Data: EventReorgData{
+			RevertedBlocks: reverted,
+			Height:         s.height,
+		}})
+	}
+	var proofs []EventStorageProofData
+	for _, b := range cc.AppliedBlocks {
+		s.height++
+		s.send(Event{Type: EventBlock, Timestamp: now, Data: EventBlockData{
+			ID:        b.ID(),
+			Height:    s.height,
+			Timestamp: b.Timestamp,
+		}})
+		for _, txn := range b.Transactions {
+			for _, sp := range txn.StorageProofs {
+				proofs = append(proofs, EventStorageProofData{
+					ContractID:    sp.ParentID,
+					TransactionID: txn.ID(),
+					BlockID:       b.ID(),
+					Height:        s.height,
+				})
+			}
+		}
+	}
+	if len(proofs) > 0 {
+		s.proofsMu.Lock()
+		s.proofs = append(s.proofs, proofs...)
+		s.proofsMu.Unlock()
+	}
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// checkContracts sends an event for each contract of the renter that was
+// formed since the last check.
+func (s *eventStream) checkContracts(report bool) {
+	contracts := make(map[types.FileContractID]struct{})
+	for _, c := range s.api.renter.Contracts() {
+		contracts[c.ID] = struct{}{}
+		if _, exists := s.contracts[c.ID]; exists || !report {
+			continue
+		}
+		s.send(Event{Type: EventContract, Timestamp: types.CurrentTimestamp(), Data: EventContractData{
+			ID:            c.ID,
+			HostPublicKey: c.HostPublicKey,
+			NetAddress:    c.NetAddress,
+			StartHeight:   c.StartHeight,
+			EndHeight:     c.EndHeight(),
+		}})
+	}
+	s.contracts = contracts
+}
+
+// checkStorageProofs sends an event for each confirmed storage proof of a
+// contract of the renter or an obligation of the host.
+func (s *eventStream) checkStorageProofs() {
+	s.proofsMu.Lock()
+	proofs := s.proofs
+	s.proofs = nil
+	s.proofsMu.Unlock()
+	if len(proofs) == 0 {
+		return
+	}
+	ours := make(map[types.FileContractID]bool)
+	if s.api.renter != nil {
+		for _, c := range s.api.renter.Contracts() {
+			ours[c.ID] = true
+		}
+	}
+	if s.api.host != nil {
+		for _, so := range s.api.host.StorageObligations() {
+			ours[so.ObligationID] = true
+		}
+	}
+	for _, p := range proofs {
+		if ours[p.ContractID] {
+			s.send(Event{Type: EventStorageProof, Timestamp: types.CurrentTimestamp(), Data: p})
+		}
+	}
+}
+
+// checkPeers sends an event for each peer that the gateway connected to or
+// disconnected from since the last check.
+func (s *eventStream) checkPeers(report bool) {
+	now := types.CurrentTimestamp()
+	peers := make(map[modules.NetAddress]modules.Peer)
+	for _, p := range s.api.gateway.Peers() {
+		peers[p.NetAddress] = p
+		if _, exists := s.peers[p.NetAddress]; !exists && report {
+			s.send(Event{Type: EventPeerConnect, Timestamp: now, Data: p})
+		}
+	}
+	for addr, p := range s.peers {
+		if _, exists := peers[addr]; !exists && report {
+			s.send(Event{Type: EventPeerDisconnect, Timestamp: now, Data: p})
+		}
+	}
+	s.peers = peers
+}
+
+// check checks the contracts, storage proofs and peers for changes.
+func (s *eventStream) check(report bool) {
+	if s.api.renter != nil && s.types[EventContract] {
+		s.checkContracts(report)
+	}
+	if s.types[EventStorageProof] {
+		s.checkStorageProofs()
+	}
+	if s.api.gateway != nil && (s.types[EventPeerConnect] || s.types[EventPeerDisconnect]) {
+		s.checkPeers(report)
+	}
+}
+
+// poll checks for changes after every block and every eventPollInterval,
+// until the stream is closed.
+func (s *eventStream) poll() {
+	s.check(false)
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.changed:
+		case <-ticker.C:
+		case <-s.closed:
+			return
+		}
+		s.check(true)
+	}
+}
+
+// streamWallet sends the events of the wallet that happen after the stream
+// was opened, until the stream is closed.
+func (s *eventStream) streamWallet() {
+	// Events returns at once with a closed channel, so it can be used to
+	// find the most recent event.
+	now := make(chan struct{})
+	close(now)
+	var since uint64
+	if events := s.api.wallet.Events(0, now); len(events) > 0 {
+		since = events[len(events)-1].ID
+	}
+	for {
+		events := s.api.wallet.Events(since, s.closed)
+		select {
+		case <-s.closed:
+			return
+		default:
+		}
+		for _, e := range events {
+			s.send(Event{Type: EventWallet, Timestamp: e.Timestamp, Data: e})
+			since = e.ID
+		}
+	}
+}
+
+// streamEvents sends events to the client until either side closes the
+// connection.
+func (api *API) streamEvents(ws *websocket.Conn, selected map[string]bool) {
+	s := &eventStream{
+		api:     api,
+		types:   selected,
+		events:  make(chan Event, eventBufferSize),
+		changed: make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	defer s.close()
+
+	if api.cs != nil && (selected[EventBlock] || selected[EventReorg] || selected[EventStorageProof]) {
+		s.height = api.cs.Height()
+		if err := api.cs.ConsensusSetSubscribe(s, modules.ConsensusChangeRecent, s.closed); err != nil {
+			return
+		}
+		defer api.cs.Unsubscribe(s)
+	}
+	if api.wallet != nil && selected[EventWallet] {
+		go s.streamWallet()
+	}
+	go s.poll()
+
+	// The client does not send anything, so reading only returns when the
+	// connection is closed.
+	go func() {
+		io.Copy(ioutil.Discard, ws)
+		s.close()
+	}()
+
+	for {
+		select {
+		case e := <-s.events:
+			if err := websocket.JSON.Send(ws, e); err != nil {
+				return
+			}
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// checkEventsOrigin rejects WebSocket connections opened by web pages of
+// other sites, unless their origin is allowed by AllowCORS. Clients that are
+// not browsers do not send an Origin.
+func checkEventsOrigin(config *websocket.Config, req *http.Request) error {
+	if req.Header.Get("Origin") == "" || fromAllowedOrigin(req) {
+		return nil
+	}
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if origin == nil || origin.Host != req.Host {
+		return errEventsOrigin
+	}
+	return nil
+}
+
+// eventsHandler handles API calls to /events, which stream the events of the
+// daemon over a WebSocket.
+func (api *API) eventsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	selected, err := parseEventTypes(req.FormValue("types"))
+	if err != nil {
+		WriteError(w, Error{Message: "unable to parse types: " + err.Error(), Param: "types"}, http.StatusBadRequest)
+		return
+	}
+	websocket.Server{
+		Handshake: checkEventsOrigin,
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			api.streamEvents(ws, selected)
+		},
+	}.ServeHTTP(w, req)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/gateway"
+
+	"golang.org/x/net/websocket"
+)
+
+// dialEvents opens the /events stream of a server tester.
+func (st *serverTester) dialEvents(query, origin string) (*websocket.Conn, error) {
+	addr := st.server.listener.Addr().String()
+	config, err := websocket.NewConfig("ws://"+addr+"/events"+query, origin)
+	if err != nil {
+		return nil, err
+	}
+	config.Header.Set("User-Agent", "Sia-Agent")
+	return websocket.DialConfig(config)
+}
+
+// receiveEvent reads the next event of a stream, decoding its data into
+// data.
+func receiveEvent(ws *websocket.Conn, data interface{}) (string, error) {
+	var e struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if err := websocket.JSON.Receive(ws, &e); err != nil {
+		return "", err
+	}
+	return e.Type, json.Unmarshal(e.Data, data)
+}
+
+// TestParseEventTypes checks that event types are validated.
+func TestParseEventTypes(t *testing.T) {
+	all, err := parseEventTypes("")
+	if err != nil || len(all) != len(eventTypes) {
+		t.Fatal("expected every type, got", all, err)
+	}
+	selected, err := parseEventTypes("block, wallet")
+	if err != nil || len(selected) != 2 || !selected[EventBlock] || !selected[EventWallet] {
+		t.Fatal("expected block and wallet, got", selected, err)
+	}
+	if _, err := parseEventTypes("block,foo"); err == nil {
+		t.Fatal("expected an error for an unknown type")
+	}
+}
+
+// TestEvents checks that blocks and peer connections are streamed by
+// /events.
+func TestEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	if err := st.stdGetAPI("/events?types=foo"); err == nil {
+		t.Fatal("expected an error for an unknown type")
+	}
+	if _, err := st.dialEvents("", "http://example.com"); err == nil {
+		t.Fatal("expected a cross-origin stream to be rejected")
+	}
+
+	ws, err := st.dialEvents("?types=block,peerconnect,peerdisconnect", "http://"+st.server.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	// Wait for the stream to subscribe to the consensus set.
+	time.Sleep(500 * time.Millisecond)
+
+	b, err := st.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block EventBlockData
+	if typ, err := receiveEvent(ws, &block); err != nil || typ != EventBlock {
+		t.Fatal("expected a block event, got", typ, err)
+	}
+	if block.ID != b.ID() || block.Height != st.cs.Height() {
+		t.Fatalf("wrong block event: %+v", block)
+	}
+
+	peer, err := gateway.New("localhost:0", false, build.TempDir("api", t.Name()+"2", "gateway"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.stdPostAPI("/gateway/connect/"+string(peer.Address()), nil); err != nil {
+		t.Fatal(err)
+	}
+	var p modules.Peer
+	if typ, err := receiveEvent(ws, &p); err != nil || typ != EventPeerConnect {
+		t.Fatal("expected a peerconnect event, got", typ, err)
+	}
+	if p.NetAddress != peer.Address() {
+		t.Fatal("wrong peer connected:", p.NetAddress)
+	}
+	if err := peer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if typ, err := receiveEvent(ws, &p); err != nil || typ != EventPeerDisconnect {
+		t.Fatal("expected a peerdisconnect event, got", typ, err)
+	}
+}
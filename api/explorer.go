@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -74,6 +75,22 @@ type (
 		Transaction  ExplorerTransaction   `json:"transaction"`
 		Transactions []ExplorerTransaction `json:"transactions"`
 	}
+
+	// ExplorerMempoolTransaction is an unconfirmed transaction with its fee
+	// and the time it has spent in the transaction pool.
+	ExplorerMempoolTransaction struct {
+		modules.ExplorerMempoolTransaction
+		FeePerByte types.Currency `json:"feeperbyte"`
+		Age        uint64         `json:"age"` // seconds
+	}
+
+	// ExplorerMempoolGET is the object returned as a response to a GET
+	// request to /explorer/mempool.
+	ExplorerMempoolGET struct {
+		Transactions []ExplorerMempoolTransaction `json:"transactions"`
+		TotalSize    uint64                       `json:"totalsize"`
+		TotalFees    types.Currency               `json:"totalfees"`
+	}
 )
 
 // buildExplorerTransaction takes a transaction and the height + id of the
@@ -203,14 +220,14 @@ func (api *API) explorerBlocksHandler(w http.ResponseWriter, req *http.Request,
 	var height types.BlockHeight
 	_, err := fmt.Sscan(ps.ByName("height"), &height)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	// Fetch and return the explorer block.
 	block, exists := api.cs.BlockAtHeight(height)
 	if !exists {
-		WriteError(w, Error{"no block found at input height in call to /explorer/block"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "no block found at input height in call to /explorer/block"}, http.StatusBadRequest)
 		return
 	}
 	WriteJSON(w, ExplorerBlockGET{
@@ -253,7 +270,7 @@ func (api *API) explorerHashHandler(w http.ResponseWriter, req *http.Request, ps
 	if err != nil {
 		addr, err := scanAddress(ps.ByName("hash"))
 		if err != nil {
-			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 			return
 		}
 		hash = crypto.Hash(addr)
@@ -262,7 +279,7 @@ func (api *API) explorerHashHandler(w http.ResponseWriter, req *http.Request, ps
 	// TODO: lookups on the zero hash are too expensive to allow. Need a
 	// better way to handle this case.
 	if hash == (crypto.Hash{}) {
-		WriteError(w, Error{"can't lookup the empty unlock hash"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "can't lookup the empty unlock hash"}, http.StatusBadRequest)
 		return
 	}
 
@@ -348,7 +365,7 @@ func (api *API) explorerHashHandler(w http.ResponseWriter, req *http.Request, ps
 	}
 
 	// Hash not found, return an error.
-	WriteError(w, Error{"unrecognized hash used as input to /explorer/hash"}, http.StatusBadRequest)
+	WriteError(w, Error{Message: "unrecognized hash used as input to /explorer/hash"}, http.StatusBadRequest)
 }
 
 // explorerHandler handles API calls to /explorer
@@ -358,3 +375,45 @@ func (api *API) explorerHandler(w http.ResponseWriter, req *http.Request, _ http
 		BlockFacts: facts,
 	})
 }
+
+// explorerMempoolHandler handles API calls to /explorer/mempool.
+func (api *API) explorerMempoolHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	txns := []ExplorerMempoolTransaction{}
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"age":        func(i, j int) bool { return txns[i].Age < txns[j].Age },
+		"fee":        func(i, j int) bool { return txns[i].Fee.Cmp(txns[j].Fee) < 0 },
+		"feeperbyte": func(i, j int) bool { return txns[i].FeePerByte.Cmp(txns[j].FeePerByte) < 0 },
+		"size":       func(i, j int) bool { return txns[i].Size < txns[j].Size },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /explorer/mempool: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var emg ExplorerMempoolGET
+	for _, txn := range api.explorer.MempoolTransactions() {
+		var feePerByte types.Currency
+		if txn.Size > 0 {
+			feePerByte = txn.Fee.Div64(txn.Size)
+		}
+		txns = append(txns, ExplorerMempoolTransaction{
+			ExplorerMempoolTransaction: txn,
+			FeePerByte:                 feePerByte,
+			Age:                        uint64(time.Since(txn.FirstSeen) / time.Second),
+		})
+		emg.TotalSize += txn.Size
+		emg.TotalFees = emg.TotalFees.Add(txn.Fee)
+	}
+	start, end := lp.Apply(w, txns)
+	emg.Transactions = txns[start:end]
+	WriteJSON(w, emg)
+}
+
+// explorerReindexHandler handles API calls to /explorer/reindex.
+func (api *API) explorerReindexHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.explorer.Reindex()
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /explorer/reindex: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
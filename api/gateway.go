@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 
+	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
 
 	"github.com/julienschmidt/httprouter"
@@ -10,20 +11,32 @@ import (
 
 // GatewayGET contains the fields returned by a GET call to "/gateway".
 type GatewayGET struct {
-	NetAddress modules.NetAddress `json:"netaddress"`
-	Peers      []modules.Peer     `json:"peers"`
+	NetAddress  modules.NetAddress `json:"netaddress"`
+	Peers       []modules.Peer     `json:"peers"`
+	BannedHosts []string           `json:"bannedhosts"`
 }
 
 // gatewayHandler handles the API call asking for the gatway status.
 func (api *API) gatewayHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	peers := api.gateway.Peers()
+	var peers []modules.Peer
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"netaddress": func(i, j int) bool { return peers[i].NetAddress < peers[j].NetAddress },
+		"version":    func(i, j int) bool { return build.VersionCmp(peers[i].Version, peers[j].Version) < 0 },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /gateway: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	peers = api.gateway.Peers()
+	start, end := lp.Apply(w, peers)
+	peers = peers[start:end]
 	// nil slices are marshalled as 'null' in JSON, whereas 0-length slices are
 	// marshalled as '[]'. The latter is preferred, indicating that the value
 	// exists but contains no elements.
 	if peers == nil {
 		peers = make([]modules.Peer, 0)
 	}
-	WriteJSON(w, GatewayGET{api.gateway.Address(), peers})
+	WriteJSON(w, GatewayGET{api.gateway.Address(), peers, api.gateway.BannedHosts()})
 }
 
 // gatewayConnectHandler handles the API call to add a peer to the gateway.
@@ -31,7 +44,7 @@ func (api *API) gatewayConnectHandler(w http.ResponseWriter, req *http.Request,
 	addr := modules.NetAddress(ps.ByName("netaddress"))
 	err := api.gateway.Connect(addr)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -43,9 +56,63 @@ func (api *API) gatewayDisconnectHandler(w http.ResponseWriter, req *http.Reques
 	addr := modules.NetAddress(ps.ByName("netaddress"))
 	err := api.gateway.Disconnect(addr)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// gatewayAddHandler handles the API call to add a node to the gateway's node
+// list.
+func (api *API) gatewayAddHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	addr := modules.NetAddress(ps.ByName("netaddress"))
+	err := api.gateway.AddNode(addr)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	WriteSuccess(w)
 }
+
+// gatewayRemoveHandler handles the API call to remove a node from the
+// gateway's node list.
+func (api *API) gatewayRemoveHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	addr := modules.NetAddress(ps.ByName("netaddress"))
+	err := api.gateway.RemoveNode(addr)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// gatewayBanHandler handles the API call to ban an IP address.
+func (api *API) gatewayBanHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	err := api.gateway.Ban(ps.ByName("address"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// gatewayUnbanHandler handles the API call to lift the ban of an IP address.
+func (api *API) gatewayUnbanHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	err := api.gateway.Unban(ps.ByName("address"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteSuccess(w)
+}
+
+// gatewayPeersSummaryHandler handles the API call asking for the distribution
+// of the gateway's peers across subnets.
+func (api *API) gatewayPeersSummaryHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, api.gateway.PeerSummary())
+}
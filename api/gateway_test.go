@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/gateway"
 )
 
@@ -63,6 +64,16 @@ func TestGatewayPeerConnect(t *testing.T) {
 	if len(info.Peers) != 1 || info.Peers[0].NetAddress != peer.Address() {
 		t.Fatal("/gateway/connect did not connect to peer", peer.Address())
 	}
+
+	// The peer is local, so it is not listed by subnet.
+	var summary modules.GatewayPeerSummary
+	err = st.getAPI("/gateway/peers/summary", &summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Outbound != 1 || summary.Local != 1 || len(summary.Subnets) != 0 || summary.Limits != modules.DefaultGatewayPeerLimits {
+		t.Fatalf("/gateway/peers/summary returned a bad summary: %+v", summary)
+	}
 }
 
 // TestGatewayPeerDisconnect checks that /gateway/disconnect removes the
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// siad reports whether it is ready to serve requests at /readyz, for load
+// balancers and orchestrators. Each loaded module that has to be in a certain
+// state before the node is useful is checked, and a check that fails gives
+// its reason.
+
+const (
+	// ReadinessConsensus, ReadinessWallet and ReadinessStorage are the names
+	// of the readiness checks of the consensus set, the wallet, and the
+	// storage folders of the host.
+	ReadinessConsensus = "consensus"
+	ReadinessWallet    = "wallet"
+	ReadinessStorage   = "storage"
+)
+
+type (
+	// A ReadinessCheck is the result of one of the checks of /readyz. Reason
+	// explains why the check failed.
+	ReadinessCheck struct {
+		Name   string `json:"name"`
+		Ready  bool   `json:"ready"`
+		Reason string `json:"reason,omitempty"`
+	}
+)
+
+// checkConsensus checks that the consensus set is synced.
+func (api *API) checkConsensus() ReadinessCheck {
+	c := ReadinessCheck{Name: ReadinessConsensus, Ready: api.cs.Synced()}
+	if !c.Ready {
+		c.Reason = fmt.Sprintf("consensus is not synced, at height %v", api.cs.Height())
+	}
+	return c
+}
+
+// checkWallet checks that the wallet has been created and is unlocked.
+func (api *API) checkWallet() ReadinessCheck {
+	c := ReadinessCheck{Name: ReadinessWallet}
+	switch {
+	case !api.wallet.Encrypted():
+		c.Reason = "wallet has not been created"
+	case !api.wallet.Unlocked():
+		c.Reason = "wallet is locked"
+	default:
+		c.Ready = true
+	}
+	return c
+}
+
+// checkStorage checks that the storage folders of the host are available. A
+// host that does not accept contracts and has no storage folders is not
+// checked.
+func (api *API) checkStorage() (ReadinessCheck, bool) {
+	folders := api.host.StorageFolders()
+	if len(folders) == 0 && !api.host.InternalSettings().AcceptingContracts {
+		return ReadinessCheck{}, false
+	}
+	c := ReadinessCheck{Name: ReadinessStorage}
+	if len(folders) == 0 {
+		c.Reason = "host accepts contracts but has no storage folders"
+		return c, true
+	}
+	for _, sf := range folders {
+		if sf.Status != modules.StorageFolderStatusReady {
+			c.Reason = fmt.Sprintf("storage folder %v is %v", sf.Path, sf.Status)
+			return c, true
+		}
+	}
+	c.Ready = true
+	return c, true
+}
+
+// ReadinessChecks runs the readiness checks of the loaded modules.
+func (api *API) ReadinessChecks() []ReadinessCheck {
+	var checks []ReadinessCheck
+	if api.cs != nil {
+		checks = append(checks, api.checkConsensus())
+	}
+	if api.wallet != nil {
+		checks = append(checks, api.checkWallet())
+	}
+	if api.host != nil {
+		if c, ok := api.checkStorage(); ok {
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"testing"
+)
+
+// TestReadinessChecks checks the readiness checks of the consensus set and
+// the wallet.
+func TestReadinessChecks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	checks := st.server.api.ReadinessChecks()
+	if len(checks) != 2 {
+		t.Fatal("expected the consensus and wallet checks, got", checks)
+	}
+	for _, c := range checks {
+		if !c.Ready {
+			t.Fatalf("check failed: %+v", c)
+		}
+	}
+
+	if err := st.stdPostAPI("/wallet/lock", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range st.server.api.ReadinessChecks() {
+		if c.Name == ReadinessWallet && (c.Ready || c.Reason != "wallet is locked") {
+			t.Fatalf("expected the wallet check to fail: %+v", c)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -15,7 +19,7 @@ import (
 var (
 	// errNoPath is returned when a call fails to provide a nonempty string
 	// for the path parameter.
-	errNoPath = Error{"path parameter is required"}
+	errNoPath = Error{Message: "path parameter is required"}
 
 	// errStorageFolderNotFound is returned if a call is made looking for a
 	// storage folder which does not appear to exist within the storage
@@ -35,6 +39,35 @@ type (
 		WorkingStatus        modules.HostWorkingStatus        `json:"workingstatus"`
 	}
 
+	// HostContractsGET contains the storage obligations of the host.
+	HostContractsGET struct {
+		Contracts []modules.StorageObligation `json:"contracts"`
+	}
+
+	// HostContractUsageGET contains the usage timeline of a storage
+	// obligation.
+	HostContractUsageGET struct {
+		Usage []modules.HostContractUsageSnapshot `json:"usage"`
+	}
+
+	// HostObligationFailureGET contains the forensic record of a storage
+	// obligation whose storage proof was missed.
+	HostObligationFailureGET struct {
+		modules.HostObligationFailure
+	}
+
+	// HostSimulateDowntimeGET contains the worst-case outcome of a period of
+	// host downtime, as returned by /host/simulatedowntime.
+	HostSimulateDowntimeGET struct {
+		modules.HostDowntimeSimulation
+	}
+
+	// HostConsistencyGET contains the result of a consistency check of the
+	// host's stored sectors, as returned by /host/consistency.
+	HostConsistencyGET struct {
+		modules.HostConsistency
+	}
+
 	// HostEstimateScoreGET contains the information that is returned from a
 	// /host/estimatescore call.
 	HostEstimateScoreGET struct {
@@ -81,17 +114,16 @@ func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httpr
 	WriteJSON(w, hg)
 }
 
-// parseHostSettings a request's query strings and returns a
-// modules.HostInternalSettings configured with the request's query string
+// parseHostSettings a request's query strings and returns the provided
+// modules.HostInternalSettings updated with the request's query string
 // parameters.
-func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettings, error) {
-	settings := api.host.InternalSettings()
+func parseHostSettings(settings modules.HostInternalSettings, req *http.Request) (modules.HostInternalSettings, error) {
 
 	if req.FormValue("acceptingcontracts") != "" {
-		var x bool
-		_, err := fmt.Sscan(req.FormValue("acceptingcontracts"), &x)
+		// fmt.Sscan reads anything that is not a boolean as false.
+		x, err := strconv.ParseBool(req.FormValue("acceptingcontracts"))
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse acceptingcontracts: " + err.Error())
 		}
 		settings.AcceptingContracts = x
 	}
@@ -99,7 +131,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x uint64
 		_, err := fmt.Sscan(req.FormValue("maxdownloadbatchsize"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse maxdownloadbatchsize: " + err.Error())
 		}
 		settings.MaxDownloadBatchSize = x
 	}
@@ -107,7 +139,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.BlockHeight
 		_, err := fmt.Sscan(req.FormValue("maxduration"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse maxduration: " + err.Error())
 		}
 		settings.MaxDuration = x
 	}
@@ -115,7 +147,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x uint64
 		_, err := fmt.Sscan(req.FormValue("maxrevisebatchsize"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse maxrevisebatchsize: " + err.Error())
 		}
 		settings.MaxReviseBatchSize = x
 	}
@@ -123,7 +155,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x modules.NetAddress
 		_, err := fmt.Sscan(req.FormValue("netaddress"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse netaddress: " + err.Error())
 		}
 		settings.NetAddress = x
 	}
@@ -131,7 +163,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.BlockHeight
 		_, err := fmt.Sscan(req.FormValue("windowsize"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse windowsize: " + err.Error())
 		}
 		settings.WindowSize = x
 	}
@@ -140,7 +172,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("collateral"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse collateral: " + err.Error())
 		}
 		settings.Collateral = x
 	}
@@ -148,7 +180,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("collateralbudget"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse collateralbudget: " + err.Error())
 		}
 		settings.CollateralBudget = x
 	}
@@ -156,7 +188,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("maxcollateral"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse maxcollateral: " + err.Error())
 		}
 		settings.MaxCollateral = x
 	}
@@ -165,7 +197,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("mincontractprice"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse mincontractprice: " + err.Error())
 		}
 		settings.MinContractPrice = x
 	}
@@ -173,7 +205,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("mindownloadbandwidthprice"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse mindownloadbandwidthprice: " + err.Error())
 		}
 		settings.MinDownloadBandwidthPrice = x
 	}
@@ -181,7 +213,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("minstorageprice"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse minstorageprice: " + err.Error())
 		}
 		settings.MinStoragePrice = x
 	}
@@ -189,7 +221,7 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("minuploadbandwidthprice"), &x)
 		if err != nil {
-			return modules.HostInternalSettings{}, nil
+			return modules.HostInternalSettings{}, errors.New("unable to parse minuploadbandwidthprice: " + err.Error())
 		}
 		settings.MinUploadBandwidthPrice = x
 	}
@@ -202,13 +234,13 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 func (api *API) hostEstimateScoreGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	// This call requires a renter, check that it is present.
 	if api.renter == nil {
-		WriteError(w, Error{"cannot call /host/estimatescore without the renter module"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "cannot call /host/estimatescore without the renter module"}, http.StatusBadRequest)
 		return
 	}
 
-	settings, err := api.parseHostSettings(req)
+	settings, err := parseHostSettings(api.host.InternalSettings(), req)
 	if err != nil {
-		WriteError(w, Error{"error parsing host settings: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error parsing host settings: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	var totalStorage, remainingStorage uint64
@@ -250,20 +282,56 @@ func (api *API) hostEstimateScoreGET(w http.ResponseWriter, req *http.Request, _
 // hostHandlerPOST handles POST request to the /host API endpoint, which sets
 // the internal settings of the host.
 func (api *API) hostHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	settings, err := api.parseHostSettings(req)
+	settings, err := parseHostSettings(api.host.InternalSettings(), req)
 	if err != nil {
-		WriteError(w, Error{"error parsing host settings: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error parsing host settings: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	err = api.host.SetInternalSettings(settings)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
 }
 
+// SetHostSettings changes the internal settings of the host, as /host [POST]
+// does with the same parameters. Settings that are not provided are left
+// unchanged.
+func (api *API) SetHostSettings(values url.Values) error {
+	if api.host == nil {
+		return errors.New("the host module is not loaded")
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if err := CheckHostSettings(values); err != nil {
+		return err
+	}
+	settings, err := parseHostSettings(api.host.InternalSettings(), &http.Request{Form: values})
+	if err != nil {
+		return err
+	}
+	return api.host.SetInternalSettings(settings)
+}
+
+// CheckHostSettings checks that values only holds parameters of /host [POST],
+// and that they can be parsed, without needing the host.
+func CheckHostSettings(values url.Values) error {
+	for name := range values {
+		known := false
+		for _, param := range HostSettingParams {
+			known = known || param == name
+		}
+		if !known {
+			return errors.New("unknown host setting " + name)
+		}
+	}
+	_, err := parseHostSettings(modules.HostInternalSettings{}, &http.Request{Form: values})
+	return err
+}
+
 // hostAnnounceHandler handles the API call to get the host to announce itself
 // to the network.
 func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -274,17 +342,177 @@ func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _
 		err = api.host.Announce()
 	}
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostContractsHandler handles API calls to /host/contracts.
+func (api *API) hostContractsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var obligations []modules.StorageObligation
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"negotiationheight": func(i, j int) bool {
+			return obligations[i].NegotiationHeight < obligations[j].NegotiationHeight
+		},
+		"obligationid": func(i, j int) bool {
+			return bytes.Compare(obligations[i].ObligationID[:], obligations[j].ObligationID[:]) < 0
+		},
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /host/contracts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	obligations = api.host.StorageObligations()
+	start, end := lp.Apply(w, obligations)
+	WriteJSON(w, HostContractsGET{
+		Contracts: obligations[start:end],
+	})
+}
+
+// hostContractUsageHandler handles API calls to /host/contracts/:id/usage.
+func (api *API) hostContractUsageHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	h, err := scanHash(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/contracts/:id/usage: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	usage, err := api.host.StorageObligationUsage(types.FileContractID(h))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/contracts/:id/usage: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostContractUsageGET{
+		Usage: usage,
+	})
+}
+
+// hostObligationFailureHandler handles API calls to
+// /host/obligations/:id/failure.
+func (api *API) hostObligationFailureHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	h, err := scanHash(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/obligations/:id/failure: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	failure, err := api.host.StorageObligationFailure(types.FileContractID(h))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/obligations/:id/failure: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostObligationFailureGET{failure})
+}
+
+// hostReindexObligationsHandler handles API calls to /host/reindexobligations.
+func (api *API) hostReindexObligationsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.host.ReindexObligations()
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/reindexobligations: " + err.Error()}, http.StatusInternalServerError)
 		return
 	}
 	WriteSuccess(w)
 }
 
+// hostConsistencyHandlerGET handles GET calls to /host/consistency, returning
+// the result of the most recent consistency check.
+func (api *API) hostConsistencyHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostConsistencyGET{api.host.Consistency()})
+}
+
+// hostConsistencyHandlerPOST handles POST calls to /host/consistency, running
+// a new consistency check and optionally cleaning up the stored sectors first.
+func (api *API) hostConsistencyHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	cleanup, err := scanBool(req.FormValue("cleanup"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/consistency: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	hc, err := api.host.CheckConsistency(cleanup)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/consistency: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, HostConsistencyGET{hc})
+}
+
+// parseDays parses a non-negative, finite number of days from a form value.
+func parseDays(req *http.Request, name string) (float64, error) {
+	days, err := strconv.ParseFloat(req.FormValue(name), 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse %v: %v", name, err)
+	}
+	if math.IsNaN(days) || math.IsInf(days, 0) || days < 0 {
+		return 0, fmt.Errorf("%v must be a non-negative number of days", name)
+	}
+	return days, nil
+}
+
+// daysToBlocks converts a number of days into the number of blocks expected
+// to be found in that time, rounding up.
+func daysToBlocks(days float64) types.BlockHeight {
+	return types.BlockHeight(math.Ceil(days * 24 * 60 * 60 / float64(types.BlockFrequency)))
+}
+
+// hostSimulateDowntimeHandler handles API calls to /host/simulatedowntime,
+// which estimates the collateral and revenue that the host would lose if it
+// were offline for the given number of days.
+func (api *API) hostSimulateDowntimeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if api.cs == nil {
+		WriteError(w, Error{Message: "cannot call /host/simulatedowntime without the consensus module"}, http.StatusBadRequest)
+		return
+	}
+	days, err := parseDays(req, "days")
+	if err == nil && days == 0 {
+		err = errors.New("days must be greater than zero")
+	}
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/simulatedowntime: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var delay float64
+	if req.FormValue("startdays") != "" {
+		delay, err = parseDays(req, "startdays")
+		if err != nil {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/simulatedowntime: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	missedPercent := 100.0
+	if req.FormValue("missedpercent") != "" {
+		missedPercent, err = strconv.ParseFloat(req.FormValue("missedpercent"), 64)
+		if err != nil || !(missedPercent >= 0 && missedPercent <= 100) {
+			WriteError(w, Error{Message: "error when calling /host/simulatedowntime: missedpercent must be a number between 0 and 100", Param: "missedpercent"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	start := api.cs.Height() + daysToBlocks(delay)
+	sim, err := api.host.SimulateDowntime(start, start+daysToBlocks(days), missedPercent/100)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /host/simulatedowntime: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostSimulateDowntimeGET{sim})
+}
+
 // storageHandler returns a bunch of information about storage management on
 // the host.
 func (api *API) storageHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var folders []modules.StorageFolderMetadata
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"capacity":          func(i, j int) bool { return folders[i].Capacity < folders[j].Capacity },
+		"capacityremaining": func(i, j int) bool { return folders[i].CapacityRemaining < folders[j].CapacityRemaining },
+		"index":             func(i, j int) bool { return folders[i].Index < folders[j].Index },
+		"path":              func(i, j int) bool { return folders[i].Path < folders[j].Path },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /host/storage: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	folders = api.host.StorageFolders()
+	start, end := lp.Apply(w, folders)
 	WriteJSON(w, StorageGET{
-		Folders: api.host.StorageFolders(),
+		Folders: folders[start:end],
 	})
 }
 
@@ -294,12 +522,12 @@ func (api *API) storageFoldersAddHandler(w http.ResponseWriter, req *http.Reques
 	var folderSize uint64
 	_, err := fmt.Sscan(req.FormValue("size"), &folderSize)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	err = api.host.AddStorageFolder(folderPath, folderSize)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -309,26 +537,26 @@ func (api *API) storageFoldersAddHandler(w http.ResponseWriter, req *http.Reques
 func (api *API) storageFoldersResizeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	folderPath := req.FormValue("path")
 	if folderPath == "" {
-		WriteError(w, Error{"path parameter is required"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "path parameter is required"}, http.StatusBadRequest)
 		return
 	}
 
 	storageFolders := api.host.StorageFolders()
 	folderIndex, err := folderIndex(folderPath, storageFolders)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	var newSize uint64
 	_, err = fmt.Sscan(req.FormValue("newsize"), &newSize)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	err = api.host.ResizeStorageFolder(uint16(folderIndex), newSize, false)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -339,21 +567,21 @@ func (api *API) storageFoldersResizeHandler(w http.ResponseWriter, req *http.Req
 func (api *API) storageFoldersRemoveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	folderPath := req.FormValue("path")
 	if folderPath == "" {
-		WriteError(w, Error{"path parameter is required"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "path parameter is required"}, http.StatusBadRequest)
 		return
 	}
 
 	storageFolders := api.host.StorageFolders()
 	folderIndex, err := folderIndex(folderPath, storageFolders)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	force := req.FormValue("force") == "true"
 	err = api.host.RemoveStorageFolder(uint16(folderIndex), force)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -364,12 +592,12 @@ func (api *API) storageFoldersRemoveHandler(w http.ResponseWriter, req *http.Req
 func (api *API) storageSectorsDeleteHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	sectorRoot, err := scanHash(ps.ByName("merkleroot"))
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	err = api.host.DeleteSector(sectorRoot)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -658,6 +658,10 @@ func TestStorageFolderUnavailable(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	err = st.waitForStorageFolders()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	var sfs StorageGET
 	err = st.getAPI("/host/storage", &sfs)
@@ -1002,3 +1006,134 @@ func TestDeleteNonexistentSector(t *testing.T) {
 		t.Fatalf("expected error to be %v; got %v", crypto.ErrHashWrongLen, err)
 	}
 }
+
+// TestHostReindexObligations probes the POST call to
+// /host/reindexobligations.
+func TestHostReindexObligations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	if err := st.stdPostAPI("/host/reindexobligations", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHostSimulateDowntime probes the /host/simulatedowntime endpoint.
+func TestHostSimulateDowntime(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var hsdg HostSimulateDowntimeGET
+	if err := st.getAPI("/host/simulatedowntime?days=1&startdays=2", &hsdg); err != nil {
+		t.Fatal(err)
+	}
+	height := st.cs.Height()
+	if hsdg.StartHeight != height+daysToBlocks(2) || hsdg.EndHeight != height+daysToBlocks(3) {
+		t.Errorf("expected downtime from %v to %v, got %v to %v", height+daysToBlocks(2), height+daysToBlocks(3), hsdg.StartHeight, hsdg.EndHeight)
+	}
+	if hsdg.ProofsAtRisk != 0 || !hsdg.CollateralBurned.IsZero() {
+		t.Error("expected no proofs at risk for a host without contracts")
+	}
+
+	for _, query := range []string{"", "?days=0", "?days=-1", "?days=NaN", "?days=1&startdays=x", "?days=1&missedpercent=101"} {
+		if err := st.getAPI("/host/simulatedowntime"+query, &hsdg); err == nil {
+			t.Errorf("expected %q to be rejected", query)
+		}
+	}
+}
+
+// TestSetHostSettings probes SetHostSettings, and checks that /host [POST]
+// rejects settings that cannot be parsed.
+func TestSetHostSettings(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	err = st.server.api.SetHostSettings(url.Values{"acceptingcontracts": {"true"}, "maxduration": {"1000"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := st.host.InternalSettings()
+	if !settings.AcceptingContracts || settings.MaxDuration != 1000 {
+		t.Errorf("the settings were not applied: %+v", settings)
+	}
+	if err := st.server.api.SetHostSettings(url.Values{"price": {"1"}}); err == nil {
+		t.Error("expected an unknown setting to be rejected")
+	}
+	if err := st.server.api.SetHostSettings(url.Values{"maxduration": {"forever"}}); err == nil {
+		t.Error("expected an invalid setting to be rejected")
+	}
+	if err := st.stdPostAPI("/host", url.Values{"maxduration": {"forever"}}); err == nil {
+		t.Error("expected /host to reject an invalid setting")
+	}
+	if st.host.InternalSettings().MaxDuration != 1000 {
+		t.Error("an invalid setting changed the settings")
+	}
+}
+
+// TestHostContractUsage checks that the host reports the data uploaded and
+// downloaded under a contract in the contract's usage timeline.
+func TestHostContractUsage(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, path := setupTestDownload(t, 1024, "test.dat", true)
+	defer func() {
+		st.server.panicClose()
+		os.Remove(path)
+	}()
+
+	downloadPath := filepath.Join(st.dir, "test-downloaded.dat")
+	if err := st.stdGetAPI("/renter/download/test.dat?destination=" + downloadPath); err != nil {
+		t.Fatal(err)
+	}
+
+	var hcg HostContractsGET
+	if err := st.getAPI("/host/contracts", &hcg); err != nil {
+		t.Fatal(err)
+	}
+	if len(hcg.Contracts) != 1 {
+		t.Fatal("expected one contract, got", len(hcg.Contracts))
+	}
+	var hcug HostContractUsageGET
+	if err := st.getAPI("/host/contracts/"+hcg.Contracts[0].ObligationID.String()+"/usage", &hcug); err != nil {
+		t.Fatal(err)
+	}
+	var added, downloaded uint64
+	revenue := types.ZeroCurrency
+	for _, snapshot := range hcug.Usage {
+		added += snapshot.BytesAdded
+		downloaded += snapshot.BytesDownloaded
+		revenue = revenue.Add(snapshot.Revenue)
+	}
+	if added == 0 || downloaded == 0 || revenue.IsZero() {
+		t.Fatalf("usage was not recorded: %+v", hcug.Usage)
+	}
+
+	// Unknown contracts should be rejected.
+	err := st.getAPI("/host/contracts/"+types.FileContractID{}.String()+"/usage", &hcug)
+	if err == nil {
+		t.Fatal("expected an error for an unknown contract")
+	}
+}
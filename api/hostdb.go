@@ -40,6 +40,12 @@ type (
 // hostdbActiveHandler handles the API call asking for the list of active
 // hosts.
 func (api *API) hostdbActiveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var extendedHosts []ExtendedHostDBEntry
+	lp, err := ParseListParams(req, hostdbSortFields(&extendedHosts))
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /hostdb/active: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
 	var numHosts uint64
 	hosts := api.renter.ActiveHosts()
 
@@ -50,7 +56,7 @@ func (api *API) hostdbActiveHandler(w http.ResponseWriter, req *http.Request, _
 		// Parse the value for 'numhosts'.
 		_, err := fmt.Sscan(req.FormValue("numhosts"), &numHosts)
 		if err != nil {
-			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Message: "unable to parse numhosts: " + err.Error(), Param: "numhosts"}, http.StatusBadRequest)
 			return
 		}
 
@@ -61,24 +67,30 @@ func (api *API) hostdbActiveHandler(w http.ResponseWriter, req *http.Request, _
 	}
 
 	// Convert the entries into extended entries.
-	var extendedHosts []ExtendedHostDBEntry
-	for _, host := range hosts {
+	for _, host := range hosts[:numHosts] {
 		extendedHosts = append(extendedHosts, ExtendedHostDBEntry{
 			HostDBEntry:     host,
 			PublicKeyString: host.PublicKey.String(),
 		})
 	}
 
+	start, end := lp.Apply(w, extendedHosts)
 	WriteJSON(w, HostdbActiveGET{
-		Hosts: extendedHosts[:numHosts],
+		Hosts: extendedHosts[start:end],
 	})
 }
 
 // hostdbAllHandler handles the API call asking for the list of all hosts.
 func (api *API) hostdbAllHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var extendedHosts []ExtendedHostDBEntry
+	lp, err := ParseListParams(req, hostdbSortFields(&extendedHosts))
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /hostdb/all: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
 	// Get the set of all hosts and convert them into extended hosts.
 	hosts := api.renter.AllHosts()
-	var extendedHosts []ExtendedHostDBEntry
 	for _, host := range hosts {
 		extendedHosts = append(extendedHosts, ExtendedHostDBEntry{
 			HostDBEntry:     host,
@@ -86,11 +98,22 @@ func (api *API) hostdbAllHandler(w http.ResponseWriter, req *http.Request, _ htt
 		})
 	}
 
+	start, end := lp.Apply(w, extendedHosts)
 	WriteJSON(w, HostdbAllGET{
-		Hosts: extendedHosts,
+		Hosts: extendedHosts[start:end],
 	})
 }
 
+// hostdbSortFields returns the fields that the lists of hosts can be sorted
+// by, comparing the hosts of the list that hosts points to.
+func hostdbSortFields(hosts *[]ExtendedHostDBEntry) map[string]func(i, j int) bool {
+	return map[string]func(i, j int) bool{
+		"firstseen":    func(i, j int) bool { return (*hosts)[i].FirstSeen < (*hosts)[j].FirstSeen },
+		"netaddress":   func(i, j int) bool { return (*hosts)[i].NetAddress < (*hosts)[j].NetAddress },
+		"storageprice": func(i, j int) bool { return (*hosts)[i].StoragePrice.Cmp((*hosts)[j].StoragePrice) < 0 },
+	}
+}
+
 // hostdbHostsHandler handles the API call asking for a specific host,
 // returning detailed informatino about that host.
 func (api *API) hostdbHostsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -99,7 +122,7 @@ func (api *API) hostdbHostsHandler(w http.ResponseWriter, req *http.Request, ps
 
 	entry, exists := api.renter.Host(pk)
 	if !exists {
-		WriteError(w, Error{"requested host does not exist"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "requested host does not exist"}, http.StatusBadRequest)
 		return
 	}
 	breakdown := api.renter.ScoreBreakdown(entry)
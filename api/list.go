@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Routes that return a list take the start, limit and sort parameters. start
+// is the index of the first item to return, and limit is the largest number
+// of items to return, or all remaining items if it is zero or omitted. sort
+// names the field to sort the items by, prefixed with '-' for descending
+// order; each route documents the fields that it can sort by. The items are
+// sorted before they are paged, and the number of items before paging is
+// sent in the X-Total-Count header.
+
+const (
+	// totalCountHeader is the header containing the number of items of a
+	// list before paging.
+	totalCountHeader = "X-Total-Count"
+)
+
+var (
+	// listParamNames are the paging and sorting parameters of the routes
+	// that return a list.
+	listParamNames = []string{"start", "limit", "sort"}
+)
+
+// ListParams are the paging and sorting parameters of a list route.
+type ListParams struct {
+	start uint64
+	limit uint64
+
+	// less compares the items of the list by the requested sort field. It
+	// is nil if no sort field was requested.
+	less func(i, j int) bool
+}
+
+// ParseListParams parses the paging and sorting parameters of a request.
+// less holds the comparison function of each field that the list can be
+// sorted by. For compatibility, offset is accepted in place of start.
+func ParseListParams(req *http.Request, less map[string]func(i, j int) bool) (lp ListParams, err error) {
+	start := req.FormValue("start")
+	if start == "" {
+		start = req.FormValue("offset")
+	}
+	if start != "" {
+		lp.start, err = strconv.ParseUint(start, 10, 64)
+		if err != nil {
+			return ListParams{}, errors.New("unable to parse start: " + err.Error())
+		}
+	}
+	if limit := req.FormValue("limit"); limit != "" {
+		lp.limit, err = strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return ListParams{}, errors.New("unable to parse limit: " + err.Error())
+		}
+	}
+	if field := req.FormValue("sort"); field != "" {
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		lf, ok := less[field]
+		if !ok {
+			var fields []string
+			for f := range less {
+				fields = append(fields, f)
+			}
+			sort.Strings(fields)
+			return ListParams{}, errors.New("cannot sort by " + field + ", the list can be sorted by: " + strings.Join(fields, ", "))
+		}
+		lp.less = lf
+		if desc {
+			lp.less = func(i, j int) bool { return lf(j, i) }
+		}
+	}
+	return lp, nil
+}
+
+// Apply sorts a list by the requested field, and returns the bounds of the
+// requested page. The length of the list is sent in the X-Total-Count
+// header. The comparison functions must index the same slice as list.
+func (lp ListParams) Apply(w http.ResponseWriter, list interface{}) (start, end int) {
+	if lp.less != nil {
+		sort.SliceStable(list, lp.less)
+	}
+	n := reflect.ValueOf(list).Len()
+	w.Header().Set(totalCountHeader, strconv.Itoa(n))
+	start, end = n, n
+	if lp.start < uint64(n) {
+		start = int(lp.start)
+	}
+	if lp.limit > 0 && lp.limit < uint64(end-start) {
+		end = start + int(lp.limit)
+	}
+	return start, end
+}
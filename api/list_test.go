@@ -0,0 +1,155 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestListParams checks that lists are sorted and paged as requested.
+func TestListParams(t *testing.T) {
+	var list []int
+	less := map[string]func(i, j int) bool{
+		"value": func(i, j int) bool { return list[i] < list[j] },
+	}
+	tests := []struct {
+		query string
+		exp   []int
+	}{
+		{"", []int{3, 1, 4, 1, 5}},
+		{"?sort=value", []int{1, 1, 3, 4, 5}},
+		{"?sort=-value", []int{5, 4, 3, 1, 1}},
+		{"?start=1&limit=2", []int{1, 4}},
+		{"?offset=3", []int{1, 5}},
+		{"?sort=-value&start=1&limit=3", []int{4, 3, 1}},
+		{"?start=4&limit=10", []int{5}},
+		{"?start=10", []int{}},
+	}
+	for _, test := range tests {
+		list = []int{3, 1, 4, 1, 5}
+		lp, err := ParseListParams(httptest.NewRequest("GET", "/"+test.query, nil), less)
+		if err != nil {
+			t.Fatal(test.query, err)
+		}
+		rec := httptest.NewRecorder()
+		start, end := lp.Apply(rec, list)
+		page := list[start:end]
+		if len(page) != len(test.exp) {
+			t.Fatalf("%v: expected %v, got %v", test.query, test.exp, page)
+		}
+		for i := range page {
+			if page[i] != test.exp[i] {
+				t.Fatalf("%v: expected %v, got %v", test.query, test.exp, page)
+			}
+		}
+		if rec.Header().Get(totalCountHeader) != "5" {
+			t.Fatalf("%v: wrong total count %q", test.query, rec.Header().Get(totalCountHeader))
+		}
+	}
+
+	for _, query := range []string{"?start=-1", "?limit=foo", "?sort=name", "?sort=-"} {
+		if _, err := ParseListParams(httptest.NewRequest("GET", "/"+query, nil), less); err == nil {
+			t.Errorf("%v: expected an error", query)
+		}
+	}
+}
+
+// TestListRoutes checks that the list routes of the API accept the list
+// parameters and report the total number of items.
+func TestListRoutes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Add two storage folders to the host so that /host/storage has more
+	// than one page.
+	for i := 0; i < 2; i++ {
+		folder := filepath.Join(st.dir, "folder"+strconv.Itoa(i))
+		if err := os.MkdirAll(folder, 0700); err != nil {
+			t.Fatal(err)
+		}
+		addValues := url.Values{}
+		addValues.Set("path", folder)
+		addValues.Set("size", mediumSizeFolderString)
+		if err := st.stdPostAPI("/host/storage/folders/add", addValues); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sg StorageGET
+	if err := st.getAPI("/host/storage?sort=-path&limit=1", &sg); err != nil {
+		t.Fatal(err)
+	}
+	if len(sg.Folders) != 1 || filepath.Base(sg.Folders[0].Path) != "folder1" {
+		t.Fatalf("expected only folder1, got %v", sg.Folders)
+	}
+
+	addr := "http://" + st.server.listener.Addr().String()
+	calls := map[string]string{
+		"/host/storage":       "2",
+		"/hostdb/active":      "",
+		"/hostdb/all":         "",
+		"/renter/contracts":   "0",
+		"/renter/downloads":   "0",
+		"/tpool/transactions": "0",
+		"/wallet/transactions/" + (types.UnlockHash{}).String(): "0",
+	}
+	for call, total := range calls {
+		resp, err := HttpGET(addr + call + "?limit=1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%v: expected status %v, got %v", call, http.StatusOK, resp.StatusCode)
+		}
+		if count := resp.Header.Get(totalCountHeader); count == "" || (total != "" && count != total) {
+			t.Fatalf("%v: expected a total count of %q, got %q", call, total, count)
+		}
+
+		resp, err = HttpGET(addr + call + "?sort=foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%v: expected an unknown sort field to be rejected, got status %v", call, resp.StatusCode)
+		}
+	}
+}
+
+// TestExplorerMempoolList checks that /explorer/mempool accepts the list
+// parameters.
+func TestExplorerMempoolList(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createExplorerServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var emg ExplorerMempoolGET
+	if err := st.getAPI("/explorer/mempool?sort=-feeperbyte&limit=1", &emg); err != nil {
+		t.Fatal(err)
+	}
+	if len(emg.Transactions) != 0 {
+		t.Fatal("expected an empty mempool, got", emg.Transactions)
+	}
+	if err := st.getAPI("/explorer/mempool?sort=foo", &emg); err == nil {
+		t.Fatal("expected an unknown sort field to be rejected")
+	}
+}
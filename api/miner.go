@@ -1,7 +1,11 @@
 package api
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/types"
@@ -16,8 +20,16 @@ type (
 		BlocksMined      int  `json:"blocksmined"`
 		CPUHashrate      int  `json:"cpuhashrate"`
 		CPUMining        bool `json:"cpumining"`
+		CPUThreads       int  `json:"cputhreads"`
 		StaleBlocksMined int  `json:"staleblocksmined"`
 	}
+
+	// MinerWithholdGET contains the information that is returned after a GET
+	// request to /miner/withhold.
+	MinerWithholdGET struct {
+		Seconds        float64 `json:"seconds"`
+		WithheldBlocks int     `json:"withheldblocks"`
+	}
 )
 
 // minerHandler handles the API call that queries the miner's status.
@@ -29,6 +41,10 @@ func (api *API) minerHandler(w http.ResponseWriter, req *http.Request, _ httprou
 		CPUMining:        api.miner.CPUMining(),
 		StaleBlocksMined: staleMined,
 	}
+	// The cpu miner is single threaded.
+	if mg.CPUMining {
+		mg.CPUThreads = 1
+	}
 	WriteJSON(w, mg)
 }
 
@@ -49,7 +65,7 @@ func (api *API) minerStopHandler(w http.ResponseWriter, req *http.Request, _ htt
 func (api *API) minerHeaderHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	bhfw, target, err := api.miner.HeaderForWork()
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	w.Write(encoding.MarshalAll(target, bhfw))
@@ -61,12 +77,41 @@ func (api *API) minerHeaderHandlerPOST(w http.ResponseWriter, req *http.Request,
 	var bh types.BlockHeader
 	err := encoding.NewDecoder(req.Body).Decode(&bh)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	err = api.miner.SubmitHeader(bh)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// minerWithholdHandlerGET handles the API call that returns the block
+// withholding settings of the miner.
+func (api *API) minerWithholdHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	delay, withheld := api.miner.BlockWithholding()
+	WriteJSON(w, MinerWithholdGET{
+		Seconds:        delay.Seconds(),
+		WithheldBlocks: withheld,
+	})
+}
+
+// minerWithholdHandlerPOST handles the API call that sets how long the miner
+// withholds solved blocks.
+func (api *API) minerWithholdHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	seconds, err := strconv.ParseFloat(req.FormValue("seconds"), 64)
+	if err == nil && (math.IsNaN(seconds) || math.IsInf(seconds, 0)) {
+		err = errors.New("seconds must be a finite number")
+	}
+	if err != nil {
+		WriteError(w, Error{Message: "unable to parse seconds: " + err.Error(), Param: "seconds"}, http.StatusBadRequest)
+		return
+	}
+	err = api.miner.SetBlockWithholding(time.Duration(seconds * float64(time.Second)))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -2,6 +2,7 @@ package api
 
 import (
 	"io/ioutil"
+	"net/url"
 	"testing"
 	"time"
 
@@ -77,6 +78,9 @@ func TestMinerStartStop(t *testing.T) {
 	if !mg.CPUMining {
 		t.Error("cpu is not reporting through the api that it is mining")
 	}
+	if mg.CPUThreads != 1 {
+		t.Error("expected the cpu miner to report 1 thread, got", mg.CPUThreads)
+	}
 
 	// Stop the cpu miner and wait for the stop call to go through.
 	err = st.stdGetAPI("/miner/stop")
@@ -96,6 +100,9 @@ func TestMinerStartStop(t *testing.T) {
 	if mg.CPUMining {
 		t.Error("cpu is not reporting through the api that it is mining")
 	}
+	if mg.CPUThreads != 0 {
+		t.Error("expected the stopped cpu miner to report 0 threads, got", mg.CPUThreads)
+	}
 }
 
 // TestMinerHeader checks that the header GET and POST calls are
@@ -150,3 +157,32 @@ func TestMinerHeader(t *testing.T) {
 		t.Errorf("block height did not increase after trying to mine a block through the api, started at %v and ended at %v", startingHeight, st.cs.Height())
 	}
 }
+
+// TestMinerWithhold checks the GET and POST calls to /miner/withhold.
+func TestMinerWithhold(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	for _, seconds := range []string{"", "-1", "NaN"} {
+		if err := st.stdPostAPI("/miner/withhold", url.Values{"seconds": {seconds}}); err == nil {
+			t.Errorf("expected an error for seconds %q", seconds)
+		}
+	}
+	if err := st.stdPostAPI("/miner/withhold", url.Values{"seconds": {"2.5"}}); err != nil {
+		t.Fatal(err)
+	}
+	var mwg MinerWithholdGET
+	if err := st.getAPI("/miner/withhold", &mwg); err != nil {
+		t.Fatal(err)
+	}
+	if mwg.Seconds != 2.5 || mwg.WithheldBlocks != 0 {
+		t.Errorf("unexpected withholding settings: %+v", mwg)
+	}
+}
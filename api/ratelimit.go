@@ -0,0 +1,207 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// The API can rate limit each client, so that a misbehaving client such as a
+// UI polling in a tight loop cannot starve the daemon. Clients are told apart
+// by their IP address. Every route belongs to a class, and each client has a
+// token bucket per class: a request takes a token, and the tokens refill at
+// the rate of the class up to its burst. A request that finds the bucket
+// empty fails with 429 Too Many Requests, and a Retry-After header giving the
+// number of seconds until a token is available. Responses to rate limited
+// routes carry the burst and the number of remaining tokens in the
+// X-RateLimit-Limit and X-RateLimit-Remaining headers. Classes without a
+// limit are not rate limited.
+
+const (
+	// RouteClassRead is the class of the GET routes.
+	RouteClassRead = "read"
+
+	// RouteClassWrite is the class of the POST routes.
+	RouteClassWrite = "write"
+
+	// RouteClassExpensive is the class of the routes that do much more work
+	// than a typical call, such as rescanning the blockchain.
+	RouteClassExpensive = "expensive"
+)
+
+var (
+	// RouteClasses are the classes of the routes, from the cheapest to the
+	// most expensive.
+	RouteClasses = []string{RouteClassRead, RouteClassWrite, RouteClassExpensive}
+
+	// ratePruneInterval is how often the buckets of idle clients are
+	// removed.
+	ratePruneInterval = time.Minute
+)
+
+// A RateLimit is the rate at which a client may call the routes of a class.
+type RateLimit struct {
+	// Rate is the number of requests per second.
+	Rate float64
+
+	// Burst is the number of requests that may be made at once after the
+	// client has been idle.
+	Burst int
+}
+
+type (
+	// rateKey identifies the token bucket of a client for a class.
+	rateKey struct {
+		client string
+		class  string
+	}
+
+	// rateBucket is a token bucket. tokens is the number of tokens at time
+	// last.
+	rateBucket struct {
+		tokens float64
+		last   time.Time
+	}
+
+	// rateLimiter rate limits the requests of each client.
+	rateLimiter struct {
+		limits    map[string]RateLimit
+		buckets   map[rateKey]*rateBucket
+		lastPrune time.Time
+		mu        sync.Mutex
+	}
+)
+
+// newRateLimiter returns a rateLimiter without limits.
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limits:  make(map[string]RateLimit),
+		buckets: make(map[rateKey]*rateBucket),
+	}
+}
+
+// routeClass returns the class of a route.
+func routeClass(method, path string) string {
+	switch {
+	case routeDocs[method+" "+path].expensive:
+		return RouteClassExpensive
+	case method == "GET":
+		return RouteClassRead
+	default:
+		return RouteClassWrite
+	}
+}
+
+// clientID identifies the client of a request by its IP address. Requests
+// made over a Unix socket have no address, and share a single client.
+func clientID(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// refill adds the tokens accrued since the last request to b.
+func (b *rateBucket) refill(limit RateLimit, now time.Time) {
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+}
+
+// setLimits replaces the limits of the classes.
+func (rl *rateLimiter) setLimits(limits map[string]RateLimit) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limits = limits
+	rl.buckets = make(map[rateKey]*rateBucket)
+}
+
+// take takes a token from the bucket of a client for a class. It returns
+// the limit of the class and the remaining tokens, or, if the bucket is
+// empty, how long the client has to wait for a token. ok is false if the
+// class is not limited.
+func (rl *rateLimiter) take(client, class string, now time.Time) (limit RateLimit, remaining int, wait time.Duration, ok bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	limit, ok = rl.limits[class]
+	if !ok {
+		return RateLimit{}, 0, 0, false
+	}
+
+	// Remove the buckets that have refilled, as their clients are idle.
+	if now.Sub(rl.lastPrune) > ratePruneInterval {
+		for key, b := range rl.buckets {
+			b.refill(rl.limits[key.class], now)
+			if b.tokens >= float64(rl.limits[key.class].Burst) {
+				delete(rl.buckets, key)
+			}
+		}
+		rl.lastPrune = now
+	}
+
+	key := rateKey{client: client, class: class}
+	b, exists := rl.buckets[key]
+	if !exists {
+		b = &rateBucket{tokens: float64(limit.Burst), last: now}
+		rl.buckets[key] = b
+	}
+	b.refill(limit, now)
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return limit, 0, wait, true
+	}
+	b.tokens--
+	return limit, int(b.tokens), 0, true
+}
+
+// limit is middleware that rate limits the requests to a route of a class.
+func (rl *rateLimiter) limit(class string, h httprouter.Handle) httprouter.Handle {
+	if rl == nil {
+		return h
+	}
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		limit, remaining, wait, ok := rl.take(clientID(req), class, time.Now())
+		if ok {
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			if wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				WriteError(w, Error{Message: "too many " + class + " requests, retry in " + wait.Round(time.Millisecond).String()}, http.StatusTooManyRequests)
+				return
+			}
+		}
+		h(w, req, ps)
+	}
+}
+
+// SetRateLimits sets the rate limits of the classes of routes. Classes
+// without a limit are not rate limited.
+func (api *API) SetRateLimits(limits map[string]RateLimit) error {
+	for class, limit := range limits {
+		if routeClassIndex(class) < 0 {
+			return errors.New("unknown route class " + class)
+		}
+		if limit.Rate <= 0 || limit.Burst < 1 {
+			return errors.New("the rate limit of " + class + " routes must have a positive rate and burst")
+		}
+	}
+	api.limiter.setLimits(limits)
+	return nil
+}
+
+// routeClassIndex returns the index of a class in RouteClasses, or -1 if it
+// is not a class.
+func routeClassIndex(class string) int {
+	for i, c := range RouteClasses {
+		if c == class {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// TestRouteClass checks the classes of some routes.
+func TestRouteClass(t *testing.T) {
+	tests := []struct {
+		method, path, class string
+	}{
+		{"GET", "/wallet", RouteClassRead},
+		{"POST", "/wallet/siacoins", RouteClassWrite},
+		{"POST", "/wallet/reindex", RouteClassExpensive},
+		{"POST", "/wallet/unlock", RouteClassExpensive},
+	}
+	for _, test := range tests {
+		if class := routeClass(test.method, test.path); class != test.class {
+			t.Errorf("%v %v: expected %v, got %v", test.method, test.path, test.class, class)
+		}
+	}
+}
+
+// TestRateLimiterTake checks that the token buckets of each client empty and
+// refill at the rate of their class.
+func TestRateLimiterTake(t *testing.T) {
+	rl := newRateLimiter()
+	rl.setLimits(map[string]RateLimit{RouteClassExpensive: {Rate: 0.5, Burst: 2}})
+	now := time.Unix(1e9, 0)
+
+	// Classes without a limit are not limited.
+	if _, _, _, ok := rl.take("a", RouteClassRead, now); ok {
+		t.Fatal("read requests should not be limited")
+	}
+
+	// The burst is available at once.
+	for want := 1; want >= 0; want-- {
+		_, remaining, wait, ok := rl.take("a", RouteClassExpensive, now)
+		if !ok || wait != 0 || remaining != want {
+			t.Fatalf("expected %v remaining, got %v %v %v", want, remaining, wait, ok)
+		}
+	}
+	_, _, wait, _ := rl.take("a", RouteClassExpensive, now)
+	if wait != 2*time.Second {
+		t.Fatal("expected to wait 2s, got", wait)
+	}
+
+	// Other clients have their own bucket.
+	if _, _, wait, _ := rl.take("b", RouteClassExpensive, now); wait != 0 {
+		t.Fatal("client b was limited by client a")
+	}
+
+	// The bucket refills over time.
+	now = now.Add(time.Second)
+	if _, _, wait, _ := rl.take("a", RouteClassExpensive, now); wait != time.Second {
+		t.Fatal("expected to wait 1s, got", wait)
+	}
+	now = now.Add(time.Second)
+	if _, _, wait, _ := rl.take("a", RouteClassExpensive, now); wait != 0 {
+		t.Fatal("expected a token after 2s, got", wait)
+	}
+
+	// Idle clients are pruned.
+	now = now.Add(ratePruneInterval + time.Second)
+	rl.take("c", RouteClassExpensive, now)
+	if len(rl.buckets) != 1 {
+		t.Fatal("expected the buckets of idle clients to be pruned, got", len(rl.buckets))
+	}
+}
+
+// TestRateLimitHeaders checks the headers of rate limited responses.
+func TestRateLimitHeaders(t *testing.T) {
+	api := &API{limiter: newRateLimiter()}
+	if err := api.SetRateLimits(map[string]RateLimit{"cheap": {Rate: 1, Burst: 1}}); err == nil {
+		t.Fatal("expected an unknown class to be rejected")
+	}
+	if err := api.SetRateLimits(map[string]RateLimit{RouteClassRead: {Rate: 0, Burst: 1}}); err == nil {
+		t.Fatal("expected a zero rate to be rejected")
+	}
+	if err := api.SetRateLimits(map[string]RateLimit{RouteClassRead: {Rate: 1, Burst: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	h := api.limiter.limit(RouteClassRead, func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		WriteSuccess(w)
+	})
+
+	req := httptest.NewRequest("GET", "/wallet", nil)
+	rec := httptest.NewRecorder()
+	h(rec, req, nil)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("X-RateLimit-Limit") != "1" || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatal("unexpected response:", rec.Code, rec.Header())
+	}
+	rec = httptest.NewRecorder()
+	h(rec, req, nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatal("expected the request to be limited, got", rec.Code, rec.Header())
+	}
+}
@@ -4,10 +4,12 @@ package api
 // zeroing them out.
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -120,11 +122,33 @@ type (
 		UploadSpending types.Currency `json:"uploadspending"`
 	}
 
+	// RenterBandwidth lists the data that the renter exchanged with each
+	// host, and what it paid the hosts for bandwidth.
+	RenterBandwidth struct {
+		Hosts []RenterHostBandwidth `json:"hosts"`
+	}
+
+	// RenterHostBandwidth is the bandwidth of one host. The efficiencies are
+	// the fractions of the data paid for that the host accepted or
+	// delivered; a host with a low efficiency is paid for bandwidth that the
+	// renter did not receive.
+	RenterHostBandwidth struct {
+		modules.RenterBandwidth
+		UploadEfficiency   float64 `json:"uploadefficiency"`
+		DownloadEfficiency float64 `json:"downloadefficiency"`
+	}
+
 	// RenterContracts contains the renter's contracts.
 	RenterContracts struct {
 		Contracts []RenterContract `json:"contracts"`
 	}
 
+	// RenterContractsRecoverPOST reports the outcome of each contract that a
+	// POST call to /renter/contracts/recover attempted to recover.
+	RenterContractsRecoverPOST struct {
+		Contracts []modules.RenterContractRecovery `json:"contracts"`
+	}
+
 	// DownloadQueue contains the renter's download queue.
 	RenterDownloadQueue struct {
 		Downloads []DownloadInfo `json:"downloads"`
@@ -135,11 +159,24 @@ type (
 		Files []modules.FileInfo `json:"files"`
 	}
 
+	// RenterFileHosts lists the hosts storing each piece of a file.
+	RenterFileHosts struct {
+		SiaPath string                   `json:"siapath"`
+		Chunks  []modules.FileChunkHosts `json:"chunks"`
+	}
+
 	// RenterLoad lists files that were loaded into the renter.
 	RenterLoad struct {
 		FilesAdded []string `json:"filesadded"`
 	}
 
+	// RenterPrefetchGET lists the files with chunks requested for prefetching
+	// into the local cache, and the total size of the cached chunks.
+	RenterPrefetchGET struct {
+		Files       []modules.RenterPrefetchInfo `json:"files"`
+		CachedBytes uint64                       `json:"cachedbytes"`
+	}
+
 	// RenterPricesGET lists the data that is returned when a GET call is made
 	// to /renter/prices.
 	RenterPricesGET struct {
@@ -197,11 +234,61 @@ func (api *API) renterHandlerGET(w http.ResponseWriter, req *http.Request, _ htt
 
 // renterHandlerPOST handles the API call to set the Renter's settings.
 func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := req.ParseForm(); err != nil {
+		WriteError(w, Error{Message: "unable to parse form: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	settings := api.renter.Settings()
+
+	// The allowance is only changed if one of its parameters was supplied, so
+	// that the schedule can be set on its own.
+	setAllowance := false
+	for _, param := range []string{"funds", "hosts", "period", "renewwindow"} {
+		if _, ok := req.Form[param]; ok {
+			setAllowance = true
+		}
+	}
+	if setAllowance {
+		allowance, err := scanAllowance(req)
+		if err != nil {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance = allowance
+	}
+
+	// Scan the upload schedule. (optional parameters)
+	for _, param := range []struct {
+		name string
+		dst  interface{}
+	}{
+		{"uploadwindowstart", &settings.Schedule.WindowStart},
+		{"uploadwindowend", &settings.Schedule.WindowEnd},
+		{"maxsystemtraffic", &settings.Schedule.MaxSystemTraffic},
+	} {
+		if v := req.FormValue(param.name); v != "" {
+			if _, err := fmt.Sscan(v, param.dst); err != nil {
+				WriteError(w, Error{Message: "unable to parse " + param.name + ": " + err.Error(), Param: param.name}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	// Set the settings in the renter.
+	err := api.renter.SetSettings(settings)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// scanAllowance scans the allowance parameters of a POST call to /renter.
+func scanAllowance(req *http.Request) (modules.Allowance, error) {
 	// Scan the allowance amount.
 	funds, ok := scanAmount(req.FormValue("funds"))
 	if !ok {
-		WriteError(w, Error{"unable to parse funds"}, http.StatusBadRequest)
-		return
+		return modules.Allowance{}, errors.New("unable to parse funds")
 	}
 
 	// Scan the number of hosts to use. (optional parameter)
@@ -209,12 +296,10 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 	if req.FormValue("hosts") != "" {
 		_, err := fmt.Sscan(req.FormValue("hosts"), &hosts)
 		if err != nil {
-			WriteError(w, Error{"unable to parse hosts: " + err.Error()}, http.StatusBadRequest)
-			return
+			return modules.Allowance{}, errors.New("unable to parse hosts: " + err.Error())
 		}
 		if hosts != 0 && hosts < requiredHosts {
-			WriteError(w, Error{fmt.Sprintf("insufficient number of hosts, need at least %v but have %v", recommendedHosts, hosts)}, http.StatusBadRequest)
-			return
+			return modules.Allowance{}, fmt.Errorf("insufficient number of hosts, need at least %v but have %v", recommendedHosts, hosts)
 		}
 	} else {
 		hosts = recommendedHosts
@@ -224,8 +309,7 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 	var period types.BlockHeight
 	_, err := fmt.Sscan(req.FormValue("period"), &period)
 	if err != nil {
-		WriteError(w, Error{"unable to parse period: " + err.Error()}, http.StatusBadRequest)
-		return
+		return modules.Allowance{}, errors.New("unable to parse period: " + err.Error())
 	}
 
 	// Scan the renew window. (optional parameter)
@@ -233,36 +317,53 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 	if req.FormValue("renewwindow") != "" {
 		_, err = fmt.Sscan(req.FormValue("renewwindow"), &renewWindow)
 		if err != nil {
-			WriteError(w, Error{"unable to parse renewwindow: " + err.Error()}, http.StatusBadRequest)
-			return
+			return modules.Allowance{}, errors.New("unable to parse renewwindow: " + err.Error())
 		}
 		if renewWindow != 0 && renewWindow < requiredRenewWindow {
-			WriteError(w, Error{fmt.Sprintf("renew window is too small, must be at least %v blocks but have %v blocks", requiredRenewWindow, renewWindow)}, http.StatusBadRequest)
-			return
+			return modules.Allowance{}, fmt.Errorf("renew window is too small, must be at least %v blocks but have %v blocks", requiredRenewWindow, renewWindow)
 		}
 	} else {
 		renewWindow = period / 2
 	}
 
-	// Set the settings in the renter.
-	err = api.renter.SetSettings(modules.RenterSettings{
-		Allowance: modules.Allowance{
-			Funds:       funds,
-			Hosts:       hosts,
-			Period:      period,
-			RenewWindow: renewWindow,
-		},
-	})
-	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
-		return
+	return modules.Allowance{
+		Funds:       funds,
+		Hosts:       hosts,
+		Period:      period,
+		RenewWindow: renewWindow,
+	}, nil
+}
+
+// renterBandwidthHandler handles the API call to request the data that the
+// renter exchanged with each host.
+func (api *API) renterBandwidthHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	hosts := []RenterHostBandwidth{}
+	for _, rb := range api.renter.Bandwidth() {
+		hosts = append(hosts, RenterHostBandwidth{
+			RenterBandwidth:    rb,
+			UploadEfficiency:   rb.UploadEfficiency(),
+			DownloadEfficiency: rb.DownloadEfficiency(),
+		})
 	}
-	WriteSuccess(w)
+	WriteJSON(w, RenterBandwidth{
+		Hosts: hosts,
+	})
 }
 
 // renterContractsHandler handles the API call to request the Renter's contracts.
-func (api *API) renterContractsHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+func (api *API) renterContractsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	contracts := []RenterContract{}
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"endheight":   func(i, j int) bool { return contracts[i].EndHeight < contracts[j].EndHeight },
+		"netaddress":  func(i, j int) bool { return contracts[i].NetAddress < contracts[j].NetAddress },
+		"size":        func(i, j int) bool { return contracts[i].Size < contracts[j].Size },
+		"startheight": func(i, j int) bool { return contracts[i].StartHeight < contracts[j].StartHeight },
+		"totalcost":   func(i, j int) bool { return contracts[i].TotalCost.Cmp(contracts[j].TotalCost) < 0 },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /renter/contracts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
 	for _, c := range api.renter.Contracts() {
 		contracts = append(contracts, RenterContract{
 			DownloadSpending: c.DownloadSpending,
@@ -280,14 +381,24 @@ func (api *API) renterContractsHandler(w http.ResponseWriter, _ *http.Request, _
 			UploadSpending:   c.UploadSpending,
 		})
 	}
+	start, end := lp.Apply(w, contracts)
 	WriteJSON(w, RenterContracts{
-		Contracts: contracts,
+		Contracts: contracts[start:end],
 	})
 }
 
 // renterDownloadsHandler handles the API call to request the download queue.
-func (api *API) renterDownloadsHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+func (api *API) renterDownloadsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var downloads []DownloadInfo
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"filesize":  func(i, j int) bool { return downloads[i].Filesize < downloads[j].Filesize },
+		"siapath":   func(i, j int) bool { return downloads[i].SiaPath < downloads[j].SiaPath },
+		"starttime": func(i, j int) bool { return downloads[i].StartTime.Before(downloads[j].StartTime) },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /renter/downloads: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
 	for _, d := range api.renter.DownloadQueue() {
 		downloads = append(downloads, DownloadInfo{
 			SiaPath:     d.SiaPath,
@@ -300,8 +411,9 @@ func (api *API) renterDownloadsHandler(w http.ResponseWriter, _ *http.Request, _
 	}
 	// sort the downloads by newest first
 	sort.Slice(downloads, func(i, j int) bool { return downloads[i].StartTime.After(downloads[j].StartTime) })
+	start, end := lp.Apply(w, downloads)
 	WriteJSON(w, RenterDownloadQueue{
-		Downloads: downloads,
+		Downloads: downloads[start:end],
 	})
 }
 
@@ -309,13 +421,13 @@ func (api *API) renterDownloadsHandler(w http.ResponseWriter, _ *http.Request, _
 func (api *API) renterLoadHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	source := req.FormValue("source")
 	if !filepath.IsAbs(source) {
-		WriteError(w, Error{"source must be an absolute path"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "source must be an absolute path", Param: "source"}, http.StatusBadRequest)
 		return
 	}
 
 	files, err := api.renter.LoadSharedFiles(source)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -327,10 +439,45 @@ func (api *API) renterLoadHandler(w http.ResponseWriter, req *http.Request, _ ht
 func (api *API) renterLoadAsciiHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	files, err := api.renter.LoadSharedFilesAscii(req.FormValue("asciisia"))
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	WriteJSON(w, RenterLoad{FilesAdded: files})
+}
+
+// renterContractsRecoverHandler handles the API call to reattach old
+// contracts by their IDs.
+func (api *API) renterContractsRecoverHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var ids []types.FileContractID
+	for _, s := range strings.Split(req.FormValue("contracts"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		h, err := scanHash(s)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /renter/contracts/recover: invalid contract ID " + s, Param: "contracts"}, http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, types.FileContractID(h))
+	}
+	if len(ids) == 0 {
+		WriteError(w, Error{Message: "error when calling /renter/contracts/recover: no contract IDs were provided", Param: "contracts"}, http.StatusBadRequest)
 		return
 	}
+	WriteJSON(w, RenterContractsRecoverPOST{
+		Contracts: api.renter.RecoverContracts(ids),
+	})
+}
 
+// renterRecoverFilesHandler handles the API call to rebuild the file list from
+// the metadata stored on the renter's hosts.
+func (api *API) renterRecoverFilesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	files, err := api.renter.RecoverFiles()
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /renter/recoverfiles: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
 	WriteJSON(w, RenterLoad{FilesAdded: files})
 }
 
@@ -339,7 +486,7 @@ func (api *API) renterLoadAsciiHandler(w http.ResponseWriter, req *http.Request,
 func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	err := api.renter.RenameFile(strings.TrimPrefix(ps.ByName("siapath"), "/"), req.FormValue("newsiapath"))
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -348,8 +495,42 @@ func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps
 
 // renterFilesHandler handles the API call to list all of the files.
 func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var files []modules.FileInfo
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"siapath":        func(i, j int) bool { return files[i].SiaPath < files[j].SiaPath },
+		"filesize":       func(i, j int) bool { return files[i].Filesize < files[j].Filesize },
+		"redundancy":     func(i, j int) bool { return files[i].Redundancy < files[j].Redundancy },
+		"uploadprogress": func(i, j int) bool { return files[i].UploadProgress < files[j].UploadProgress },
+		"expiration":     func(i, j int) bool { return files[i].Expiration < files[j].Expiration },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /renter/files: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	files = api.renter.FileList()
+	start, end := lp.Apply(w, files)
 	WriteJSON(w, RenterFiles{
-		Files: api.renter.FileList(),
+		Files: files[start:end],
+	})
+}
+
+// renterFileHostsHandler handles the API call to list the hosts storing a
+// file. The route is /renter/files/<siapath>/hosts.
+func (api *API) renterFileHostsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	siapath := strings.TrimPrefix(ps.ByName("siapath"), "/")
+	if !strings.HasSuffix(siapath, "/hosts") {
+		UnrecognizedCallHandler(w, req)
+		return
+	}
+	siapath = strings.TrimSuffix(siapath, "/hosts")
+	chunks, err := api.renter.FileHosts(siapath)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /renter/files/" + siapath + "/hosts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterFileHosts{
+		SiaPath: siapath,
+		Chunks:  chunks,
 	})
 }
 
@@ -366,7 +547,7 @@ func (api *API) renterPricesHandler(w http.ResponseWriter, req *http.Request, _
 func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	err := api.renter.DeleteFile(strings.TrimPrefix(ps.ByName("siapath"), "/"))
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -377,7 +558,7 @@ func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps
 func (api *API) renterDownloadHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	params, err := parseDownloadParameters(w, req, ps)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -391,7 +572,7 @@ func (api *API) renterDownloadHandler(w http.ResponseWriter, req *http.Request,
 		select {
 		case err = <-errchan:
 			if err != nil {
-				WriteError(w, Error{"download failed: " + err.Error()}, http.StatusInternalServerError)
+				WriteError(w, Error{Code: moduleErrorCode(err), Message: "download failed: " + err.Error()}, http.StatusInternalServerError)
 				return
 			}
 		case <-time.After(time.Millisecond * 100):
@@ -399,7 +580,7 @@ func (api *API) renterDownloadHandler(w http.ResponseWriter, req *http.Request,
 	} else {
 		err := api.renter.Download(params)
 		if err != nil {
-			WriteError(w, Error{"download failed: " + err.Error()}, http.StatusInternalServerError)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "download failed: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
 	}
@@ -486,13 +667,13 @@ func (api *API) renterShareHandler(w http.ResponseWriter, req *http.Request, ps
 	destination := req.FormValue("destination")
 	// Check that the destination path is absolute.
 	if !filepath.IsAbs(destination) {
-		WriteError(w, Error{"destination must be an absolute path"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "destination must be an absolute path", Param: "destination"}, http.StatusBadRequest)
 		return
 	}
 
 	err := api.renter.ShareFiles(strings.Split(req.FormValue("siapaths"), ","), destination)
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -504,7 +685,7 @@ func (api *API) renterShareHandler(w http.ResponseWriter, req *http.Request, ps
 func (api *API) renterShareAsciiHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	ascii, err := api.renter.ShareFilesAscii(strings.Split(req.FormValue("siapaths"), ","))
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteJSON(w, RenterShareASCII{
@@ -516,7 +697,7 @@ func (api *API) renterShareAsciiHandler(w http.ResponseWriter, req *http.Request
 func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	source := req.FormValue("source")
 	if !filepath.IsAbs(source) {
-		WriteError(w, Error{"source must be an absolute path"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "source must be an absolute path", Param: "source"}, http.StatusBadRequest)
 		return
 	}
 
@@ -525,7 +706,7 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 	if req.FormValue("datapieces") != "" || req.FormValue("paritypieces") != "" {
 		// Check that both values have been supplied.
 		if req.FormValue("datapieces") == "" || req.FormValue("paritypieces") == "" {
-			WriteError(w, Error{"must provide both the datapieces paramaeter and the paritypieces parameter if specifying erasure coding parameters"}, http.StatusBadRequest)
+			WriteError(w, Error{Message: "must provide both the datapieces paramaeter and the paritypieces parameter if specifying erasure coding parameters"}, http.StatusBadRequest)
 			return
 		}
 
@@ -533,31 +714,31 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		var dataPieces, parityPieces int
 		_, err := fmt.Sscan(req.FormValue("datapieces"), &dataPieces)
 		if err != nil {
-			WriteError(w, Error{"unable to read parameter 'datapieces': " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Message: "unable to read parameter 'datapieces': " + err.Error(), Param: "datapieces"}, http.StatusBadRequest)
 			return
 		}
 		_, err = fmt.Sscan(req.FormValue("paritypieces"), &parityPieces)
 		if err != nil {
-			WriteError(w, Error{"unable to read parameter 'paritypieces': " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Message: "unable to read parameter 'paritypieces': " + err.Error(), Param: "paritypieces"}, http.StatusBadRequest)
 			return
 		}
 
 		// Verify that sane values for parityPieces and redundancy are being
 		// supplied.
 		if parityPieces < requiredParityPieces {
-			WriteError(w, Error{fmt.Sprintf("a minimum of %v parity pieces is required, but %v parity pieces requested", parityPieces, requiredParityPieces)}, http.StatusBadRequest)
+			WriteError(w, Error{Message: fmt.Sprintf("a minimum of %v parity pieces is required, but %v parity pieces requested", parityPieces, requiredParityPieces)}, http.StatusBadRequest)
 			return
 		}
 		redundancy := float64(dataPieces+parityPieces) / float64(dataPieces)
 		if float64(dataPieces+parityPieces)/float64(dataPieces) < requiredRedundancy {
-			WriteError(w, Error{fmt.Sprintf("a redundancy of %.2f is required, but redundancy of %.2f supplied", redundancy, requiredRedundancy)}, http.StatusBadRequest)
+			WriteError(w, Error{Message: fmt.Sprintf("a redundancy of %.2f is required, but redundancy of %.2f supplied", redundancy, requiredRedundancy)}, http.StatusBadRequest)
 			return
 		}
 
 		// Create the erasure coder.
 		ec, err = renter.NewRSCode(dataPieces, parityPieces)
 		if err != nil {
-			WriteError(w, Error{"unable to encode file using the provided parameters: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "unable to encode file using the provided parameters: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
@@ -569,7 +750,94 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		ErasureCode: ec,
 	})
 	if err != nil {
-		WriteError(w, Error{"upload failed: " + err.Error()}, http.StatusInternalServerError)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "upload failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterUpdateHandler handles the API call to replace the contents of an
+// uploaded file, re-uploading only the chunks that changed.
+func (api *API) renterUpdateHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	source := req.FormValue("source")
+	if !filepath.IsAbs(source) {
+		WriteError(w, Error{Message: "source must be an absolute path", Param: "source"}, http.StatusBadRequest)
+		return
+	}
+	update, err := api.renter.UpdateFile(strings.TrimPrefix(ps.ByName("siapath"), "/"), source)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "update failed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, update)
+}
+
+// maxChunkListLen is the maximum number of chunks in a chunk list, which
+// bounds the memory used to parse one.
+const maxChunkListLen = 1 << 20
+
+// parseChunkList parses a comma-separated list of chunk indices and inclusive
+// ranges of chunk indices, such as "0,2,5-9".
+func parseChunkList(s string) ([]uint64, error) {
+	var chunks []uint64
+	if s == "" {
+		return nil, nil
+	}
+	for _, field := range strings.Split(s, ",") {
+		bounds := strings.SplitN(field, "-", 2)
+		first, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chunk %q", field)
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 64)
+			if err != nil || last < first {
+				return nil, fmt.Errorf("invalid chunk range %q", field)
+			}
+		}
+		if uint64(len(chunks))+last-first >= maxChunkListLen {
+			return nil, errors.New("too many chunks")
+		}
+		for i := first; i <= last; i++ {
+			chunks = append(chunks, i)
+			if i == last {
+				break
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// renterPrefetchHandlerGET handles the API call that reports the progress of
+// prefetching files into the local cache.
+func (api *API) renterPrefetchHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	rpg := RenterPrefetchGET{Files: api.renter.PrefetchInfo()}
+	for _, info := range rpg.Files {
+		rpg.CachedBytes += info.CachedBytes
+	}
+	WriteJSON(w, rpg)
+}
+
+// renterPrefetchHandlerPOST handles the API call that prefetches chunks of a
+// file into the local cache, or evicts them.
+func (api *API) renterPrefetchHandlerPOST(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	siaPath := strings.TrimPrefix(ps.ByName("siapath"), "/")
+	if req.FormValue("evict") == "true" {
+		if err := api.renter.EvictPrefetched(siaPath); err != nil {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "eviction failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+	chunks, err := parseChunkList(req.FormValue("chunks"))
+	if err != nil {
+		WriteError(w, Error{Message: "unable to parse chunks: " + err.Error(), Param: "chunks"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.Prefetch(siaPath, chunks); err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "prefetch failed: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -538,6 +539,288 @@ func TestRenterPaths(t *testing.T) {
 	}
 }
 
+// TestRenterFileHosts checks that /renter/files/<siapath>/hosts reports the
+// host storing each piece of an uploaded file.
+func TestRenterFileHosts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, path := setupTestDownload(t, 1024, "test.dat", true)
+	defer func() {
+		st.server.panicClose()
+		os.Remove(path)
+	}()
+
+	var hg HostGET
+	if err := st.getAPI("/host", &hg); err != nil {
+		t.Fatal(err)
+	}
+	var rfh RenterFileHosts
+	if err := st.getAPI("/renter/files/test.dat/hosts", &rfh); err != nil {
+		t.Fatal(err)
+	}
+	if rfh.SiaPath != "test.dat" || len(rfh.Chunks) != 1 {
+		t.Fatal("wrong file hosts returned:", rfh)
+	}
+	// The renter stores at most one piece of each chunk per host, so only
+	// one of the two pieces is on the only host.
+	pieces := rfh.Chunks[0].Pieces
+	if len(pieces) != 1 || pieces[0].Piece > 1 {
+		t.Fatal("expected a single piece, got", pieces)
+	}
+	for _, p := range pieces {
+		if p.NetAddress != hg.ExternalSettings.NetAddress {
+			t.Fatal("piece is not reported on the host:", p)
+		}
+		if !p.Online || p.Score.IsZero() {
+			t.Fatal("host should be online with a nonzero score:", p)
+		}
+	}
+
+	// Unknown files and malformed routes should be rejected.
+	if err := st.getAPI("/renter/files/dne/hosts", &rfh); err == nil {
+		t.Fatal("expected an error for an unknown file")
+	}
+	if err := st.getAPI("/renter/files/test.dat", &rfh); err == nil {
+		t.Fatal("expected an error for a route without /hosts")
+	}
+}
+
+// TestRenterBandwidth probes the /renter/bandwidth route after a file is
+// uploaded and downloaded.
+func TestRenterBandwidth(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, path := setupTestDownload(t, 1024, "test.dat", true)
+	defer func() {
+		st.server.panicClose()
+		os.Remove(path)
+	}()
+	downpath := filepath.Join(st.dir, "down.dat")
+	if err := st.getAPI("/renter/download/test.dat?destination="+downpath, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var hg HostGET
+	if err := st.getAPI("/host", &hg); err != nil {
+		t.Fatal(err)
+	}
+	var rb RenterBandwidth
+	if err := st.getAPI("/renter/bandwidth", &rb); err != nil {
+		t.Fatal(err)
+	}
+	if len(rb.Hosts) != 1 {
+		t.Fatal("expected bandwidth of one host, got", len(rb.Hosts))
+	}
+	h := rb.Hosts[0]
+	if h.NetAddress != hg.ExternalSettings.NetAddress {
+		t.Fatal("wrong host:", h.NetAddress)
+	}
+	// Each transfer moves a whole sector, plus protocol overhead.
+	if h.UploadBytes < modules.SectorSize || h.DownloadBytes < modules.SectorSize {
+		t.Fatal("bytes on the wire were not recorded:", h.UploadBytes, h.DownloadBytes)
+	}
+	if h.UploadPaidBytes == 0 || h.UploadUsedBytes != h.UploadPaidBytes || h.UploadSpending.IsZero() {
+		t.Fatal("wrong upload accounting:", h.UploadPaidBytes, h.UploadUsedBytes, h.UploadSpending)
+	}
+	if h.DownloadPaidBytes == 0 || h.DownloadUsedBytes != h.DownloadPaidBytes || h.DownloadSpending.IsZero() {
+		t.Fatal("wrong download accounting:", h.DownloadPaidBytes, h.DownloadUsedBytes, h.DownloadSpending)
+	}
+	if h.UploadEfficiency != 1 || h.DownloadEfficiency != 1 {
+		t.Fatal("expected every paid transfer to succeed:", h.UploadEfficiency, h.DownloadEfficiency)
+	}
+}
+
+// TestRenterUpdate checks that /renter/update re-uploads the changed chunks of
+// a file, after which the new contents can be downloaded.
+func TestRenterUpdate(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, path := setupTestDownload(t, 1024, "test.dat", true)
+	defer func() {
+		st.server.panicClose()
+		os.Remove(path)
+	}()
+
+	// An unchanged file has no chunks to re-upload.
+	updateValues := url.Values{}
+	updateValues.Set("source", path)
+	var update modules.RenterFileUpdate
+	if err := st.postAPI("/renter/update/test.dat", updateValues, &update); err != nil {
+		t.Fatal(err)
+	}
+	if update.Chunks != 1 || update.ChangedChunks != 0 {
+		t.Fatal("expected no changed chunks, got", update)
+	}
+
+	// Change the file and update it.
+	if err := createRandFile(path, 1024); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.postAPI("/renter/update/test.dat", updateValues, &update); err != nil {
+		t.Fatal(err)
+	}
+	if update.Chunks != 1 || update.ChangedChunks != 1 {
+		t.Fatal("expected one changed chunk, got", update)
+	}
+	err := retry(200, time.Second, func() error {
+		var rf RenterFiles
+		st.getAPI("/renter/files", &rf)
+		if len(rf.Files) != 1 || !rf.Files[0].Available {
+			return errors.New("the updated file is not available")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	downpath := filepath.Join(st.dir, "down.dat")
+	if err := st.getAPI("/renter/download/test.dat?destination="+downpath, nil); err != nil {
+		t.Fatal(err)
+	}
+	orig, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	down, err := ioutil.ReadFile(downpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(orig, down) {
+		t.Fatal("downloaded file does not match the updated file")
+	}
+
+	// Relative paths and unknown files are rejected.
+	updateValues.Set("source", "test.dat")
+	if err := st.stdPostAPI("/renter/update/test.dat", updateValues); err == nil {
+		t.Fatal("expected an error for a relative source")
+	}
+	updateValues.Set("source", path)
+	if err := st.stdPostAPI("/renter/update/dne", updateValues); err == nil {
+		t.Fatal("expected an error for an unknown file")
+	}
+}
+
+// TestRenterPrefetch checks that prefetched files are downloaded from the
+// local cache.
+func TestRenterPrefetch(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, path := setupTestDownload(t, 1024, "test.dat", true)
+	defer func() {
+		st.server.panicClose()
+		os.Remove(path)
+	}()
+
+	if err := st.stdPostAPI("/renter/prefetch/test.dat", url.Values{"chunks": {"1"}}); err == nil {
+		t.Fatal("expected an error for a chunk beyond the end of the file")
+	}
+	if err := st.stdPostAPI("/renter/prefetch/test.dat", url.Values{}); err != nil {
+		t.Fatal(err)
+	}
+	err := retry(100, 100*time.Millisecond, func() error {
+		var rpg RenterPrefetchGET
+		if err := st.getAPI("/renter/prefetch", &rpg); err != nil {
+			return err
+		}
+		if len(rpg.Files) != 1 || rpg.Files[0].Active || rpg.Files[0].CachedChunks != 1 {
+			return fmt.Errorf("file was not prefetched: %+v", rpg.Files)
+		}
+		if rpg.Files[0].Error != "" {
+			t.Fatal(rpg.Files[0].Error)
+		}
+		if rpg.CachedBytes != 1024 {
+			return fmt.Errorf("expected 1024 cached bytes, got %v", rpg.CachedBytes)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Downloads served from the cache do not enter the download queue.
+	var queue RenterDownloadQueue
+	if err := st.getAPI("/renter/downloads", &queue); err != nil {
+		t.Fatal(err)
+	}
+	queued := len(queue.Downloads)
+	downpath := filepath.Join(st.dir, "down.dat")
+	if err := st.getAPI("/renter/download/test.dat?destination="+downpath, nil); err != nil {
+		t.Fatal(err)
+	}
+	orig, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	down, err := ioutil.ReadFile(downpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(orig, down) {
+		t.Fatal("file downloaded from the cache does not match the original")
+	}
+	if err := st.getAPI("/renter/downloads", &queue); err != nil {
+		t.Fatal(err)
+	}
+	if len(queue.Downloads) != queued {
+		t.Fatal("prefetched file was downloaded from the hosts")
+	}
+
+	// Evicted files are downloaded from the hosts again.
+	if err := st.stdPostAPI("/renter/prefetch/test.dat", url.Values{"evict": {"true"}}); err != nil {
+		t.Fatal(err)
+	}
+	var rpg RenterPrefetchGET
+	if err := st.getAPI("/renter/prefetch", &rpg); err != nil {
+		t.Fatal(err)
+	}
+	if len(rpg.Files) != 0 {
+		t.Fatal("evicted file is still cached:", rpg.Files)
+	}
+	if err := st.getAPI("/renter/download/test.dat?destination="+downpath, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI("/renter/downloads", &queue); err != nil {
+		t.Fatal(err)
+	}
+	if len(queue.Downloads) != queued+1 {
+		t.Fatal("evicted file was not downloaded from the hosts")
+	}
+}
+
+// TestParseChunkList probes parseChunkList.
+func TestParseChunkList(t *testing.T) {
+	tests := []struct {
+		in     string
+		chunks []uint64
+		valid  bool
+	}{
+		{"", nil, true},
+		{"3", []uint64{3}, true},
+		{"0,2,5-7", []uint64{0, 2, 5, 6, 7}, true},
+		{"4-4", []uint64{4}, true},
+		{"5-3", nil, false},
+		{"a", nil, false},
+		{"1,", nil, false},
+		{"0-18446744073709551615", nil, false},
+	}
+	for _, test := range tests {
+		chunks, err := parseChunkList(test.in)
+		if test.valid != (err == nil) {
+			t.Errorf("%q: unexpected error value %v", test.in, err)
+		} else if test.valid && !reflect.DeepEqual(chunks, test.chunks) {
+			t.Errorf("%q: expected %v, got %v", test.in, test.chunks, chunks)
+		}
+	}
+}
+
 // TestRenterConflicts tests that the renter handles naming conflicts properly.
 func TestRenterConflicts(t *testing.T) {
 	if testing.Short() {
@@ -586,9 +869,9 @@ func TestRenterConflicts(t *testing.T) {
 
 	// Upload using the same nickname.
 	err = st.stdPostAPI("/renter/upload/foo/bar.sia/test", uploadValues)
-	expectedErr := Error{"upload failed: " + renter.ErrPathOverload.Error()}
-	if err != expectedErr {
-		t.Fatalf("expected %v, got %v", Error{"upload failed: " + renter.ErrPathOverload.Error()}, err)
+	expectedErr := Error{Message: "upload failed: " + renter.ErrPathOverload.Error()}
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected %v, got %v", expectedErr, err)
 	}
 
 	// Upload using nickname that conflicts with folder.
@@ -2114,3 +2397,46 @@ func TestAdversarialPriceRenewal(t *testing.T) {
 		time.Sleep(time.Millisecond * 100)
 	}
 }
+
+// TestRenterSchedule checks that the upload schedule can be set through POST
+// /renter without changing the allowance.
+func TestRenterSchedule(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	scheduleValues := url.Values{}
+	scheduleValues.Set("uploadwindowstart", "22")
+	scheduleValues.Set("uploadwindowend", "6")
+	scheduleValues.Set("maxsystemtraffic", "1000000")
+	if err := st.stdPostAPI("/renter", scheduleValues); err != nil {
+		t.Fatal(err)
+	}
+	var rg RenterGET
+	if err := st.getAPI("/renter", &rg); err != nil {
+		t.Fatal(err)
+	}
+	expected := modules.RenterSchedule{WindowStart: 22, WindowEnd: 6, MaxSystemTraffic: 1e6}
+	if rg.Settings.Schedule != expected {
+		t.Fatalf("expected schedule %v, got %v", expected, rg.Settings.Schedule)
+	}
+	if !rg.Settings.Allowance.Funds.IsZero() || rg.Settings.Allowance.Period != 0 {
+		t.Fatal("setting the schedule changed the allowance:", rg.Settings.Allowance)
+	}
+
+	// Invalid hours are rejected.
+	scheduleValues.Set("uploadwindowend", "24")
+	if err := st.stdPostAPI("/renter", scheduleValues); err == nil {
+		t.Fatal("expected an error for an invalid hour")
+	}
+	scheduleValues.Set("uploadwindowend", "foo")
+	if err := st.stdPostAPI("/renter", scheduleValues); err == nil || !strings.HasPrefix(err.Error(), "unable to parse uploadwindowend") {
+		t.Fatal("expected a parse error, got", err)
+	}
+}
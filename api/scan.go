@@ -8,13 +8,14 @@ import (
 	"github.com/NebulousLabs/Sia/types"
 )
 
-// scanAmount scans a types.Currency from a string.
+// scanAmount scans a types.Currency from a string. Negative amounts are
+// rejected.
 func scanAmount(amount string) (types.Currency, bool) {
 	// use SetString manually to ensure that amount does not contain
 	// multiple values, which would confuse fmt.Scan
 	i, ok := new(big.Int).SetString(amount, 10)
-	if !ok {
-		return types.Currency{}, ok
+	if !ok || i.Sign() < 0 {
+		return types.Currency{}, false
 	}
 	return types.NewCurrency(i), true
 }
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"testing"
+)
+
+// TestScanAmount probes the scanAmount function.
+func TestScanAmount(t *testing.T) {
+	for _, in := range []string{"0", "1", "1000000000000000000000000"} {
+		c, ok := scanAmount(in)
+		if !ok || c.String() != in {
+			t.Errorf("scanAmount(%v): got %v, %v", in, c, ok)
+		}
+	}
+	// Negative amounts must be rejected rather than converted to a
+	// types.Currency, which cannot be negative.
+	for _, in := range []string{"", "-1", "1.5", "1e3", "1 2", "0x10"} {
+		if _, ok := scanAmount(in); ok {
+			t.Errorf("scanAmount(%v): expected failure", in)
+		}
+	}
+}
@@ -331,7 +331,7 @@ func assembleExplorerServerTester(testdir string) (*serverTester, error) {
 	if err != nil {
 		return nil, err
 	}
-	e, err := explorer.New(cs, filepath.Join(testdir, modules.ExplorerDir))
+	e, err := explorer.New(cs, nil, filepath.Join(testdir, modules.ExplorerDir))
 	if err != nil {
 		return nil, err
 	}
@@ -532,7 +532,29 @@ func (st *serverTester) setHostStorage() error {
 	values := url.Values{}
 	values.Set("path", st.dir)
 	values.Set("size", "1048576")
-	return st.stdPostAPI("/host/storage/folders/add", values)
+	err := st.stdPostAPI("/host/storage/folders/add", values)
+	if err != nil {
+		return err
+	}
+	return st.waitForStorageFolders()
+}
+
+// waitForStorageFolders blocks until none of the host's storage folders are
+// still being initialized.
+func (st *serverTester) waitForStorageFolders() error {
+	return build.Retry(100, 100*time.Millisecond, func() error {
+		var sg StorageGET
+		err := st.getAPI("/host/storage", &sg)
+		if err != nil {
+			return err
+		}
+		for _, sf := range sg.Folders {
+			if sf.Status == modules.StorageFolderStatusInitializing {
+				return errors.New("storage folder is still initializing")
+			}
+		}
+		return nil
+	})
 }
 
 // announceHost announces the host, mines a block, and waits for the
@@ -0,0 +1,562 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// The API serves an OpenAPI 3 document describing its routes at /spec. The
+// routes in the document are recorded as they are registered with the
+// router, and are described by the routeDocs table, so a route cannot be
+// added without documenting it. The schemas of the request and response
+// bodies are generated from the Go types that the handlers encode, using the
+// same rules as encoding/json.
+
+type (
+	// A routeDoc describes an API route in the OpenAPI document.
+	routeDoc struct {
+		summary string
+
+		// params are the form or query string parameters of the route.
+		// Path parameters are taken from the route itself.
+		params []string
+
+		// body is the JSON request body, and response the JSON response
+		// body. A nil response means that the route responds with 204 No
+		// Content.
+		body     interface{}
+		response interface{}
+
+		// binary is set if the route reads or writes Sia-encoded data
+		// instead of JSON.
+		binary bool
+
+		// deprecated is set if the route will be removed, and names the
+		// route that replaces it.
+		deprecated string
+
+		// nobatch is set if the route streams its response or may respond
+		// with something other than JSON, such as a download, so it cannot
+		// be called by /batch. Binary routes cannot be batched either.
+		nobatch bool
+
+		// expensive is set if the route does much more work than a typical
+		// call, such as rescanning the blockchain, and is rate limited as
+		// RouteClassExpensive.
+		expensive bool
+	}
+
+	// A specRoute is a route that was registered with a specRouter.
+	specRoute struct {
+		method string
+		path   string
+	}
+
+	// A specRouter is an httprouter.Router that records the routes
+	// registered with it.
+	specRouter struct {
+		*httprouter.Router
+		routes []specRoute
+
+		// limiter rate limits the requests to the routes.
+		limiter *rateLimiter
+	}
+)
+
+type (
+	// OpenAPISpec is the OpenAPI 3 document served at /spec.
+	OpenAPISpec struct {
+		OpenAPI    string                                 `json:"openapi"`
+		Info       OpenAPIInfo                            `json:"info"`
+		Servers    []OpenAPIServer                        `json:"servers"`
+		Paths      map[string]map[string]OpenAPIOperation `json:"paths"`
+		Components OpenAPIComponents                      `json:"components"`
+	}
+
+	// OpenAPIInfo contains the title and version of the API.
+	OpenAPIInfo struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+
+	// OpenAPIServer is the base URL of the routes, relative to the address
+	// of siad.
+	OpenAPIServer struct {
+		URL string `json:"url"`
+	}
+
+	// OpenAPIOperation describes a single route.
+	OpenAPIOperation struct {
+		OperationID string                     `json:"operationId"`
+		Summary     string                     `json:"summary"`
+		Description string                     `json:"description,omitempty"`
+		Deprecated  bool                       `json:"deprecated,omitempty"`
+		Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *OpenAPIBody               `json:"requestBody,omitempty"`
+		Responses   map[string]OpenAPIResponse `json:"responses"`
+	}
+
+	// OpenAPIParameter describes a path or query string parameter.
+	OpenAPIParameter struct {
+		Name     string         `json:"name"`
+		In       string         `json:"in"`
+		Required bool           `json:"required,omitempty"`
+		Schema   *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPIBody describes a request body.
+	OpenAPIBody struct {
+		Required bool                        `json:"required"`
+		Content  map[string]OpenAPIMediaType `json:"content"`
+	}
+
+	// OpenAPIResponse describes a response.
+	OpenAPIResponse struct {
+		Description string                      `json:"description"`
+		Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+	}
+
+	// OpenAPIMediaType gives the schema of a body.
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPIComponents contains the schemas of the named types that are
+	// referenced by the operations.
+	OpenAPIComponents struct {
+		Schemas map[string]*OpenAPISchema `json:"schemas"`
+	}
+
+	// OpenAPISchema describes the JSON encoding of a type.
+	OpenAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	}
+)
+
+// routeDocs describes every route that the API may register, keyed by method
+// and path.
+var routeDocs = map[string]routeDoc{
+	"POST /batch": {summary: "makes several API calls in order and returns their responses", body: []BatchCall{}, response: []BatchResponse{}, nobatch: true},
+	"GET /events": {summary: "streams the events of the daemon over a WebSocket", params: []string{"types"}, response: Event{}, nobatch: true},
+	"GET /spec":   {summary: "returns the OpenAPI document of the API", response: OpenAPISpec{}},
+
+	// Consensus
+	"GET /consensus":                          {summary: "returns information about the consensus set", response: ConsensusGET{}},
+	"GET /consensus/deployments":              {summary: "returns the status of the soft fork deployments", response: ConsensusDeploymentsGET{}},
+	"GET /consensus/waitforblock":             {summary: "waits until the chain reaches a height or a new block arrives", params: []string{"height", "timeout"}, response: ConsensusWaitForBlockGET{}},
+	"POST /consensus/validate/reserves":       {summary: "verifies a proof of reserves against the blockchain", body: modules.ReservesProof{}, response: ConsensusValidateReservesPOST{}},
+	"POST /consensus/validate/transactionset": {summary: "validates a set of transactions against the current consensus set", body: []types.Transaction{}},
+
+	// Explorer
+	"GET /explorer":                {summary: "returns statistics about the blockchain", response: ExplorerGET{}},
+	"GET /explorer/blocks/:height": {summary: "returns the block at the provided height", response: ExplorerBlockGET{}},
+	"GET /explorer/hashes/:hash":   {summary: "returns the block, transaction or address with the provided hash", response: ExplorerHashGET{}},
+	"GET /explorer/mempool":        {summary: "returns the unconfirmed transactions in the transaction pool, with their fees and ages", response: ExplorerMempoolGET{}},
+	"POST /explorer/reindex":       {summary: "rebuilds the explorer database by rescanning the blockchain", expensive: true},
+
+	// Gateway
+	"GET /gateway":                         {summary: "returns the address and peers of the gateway", params: listParamNames, response: GatewayGET{}},
+	"POST /gateway/connect/:netaddress":    {summary: "connects the gateway to a peer"},
+	"POST /gateway/disconnect/:netaddress": {summary: "disconnects the gateway from a peer"},
+	"POST /gateway/add/:netaddress":        {summary: "adds a node to the node list of the gateway"},
+	"POST /gateway/remove/:netaddress":     {summary: "removes a node from the node list of the gateway, disconnecting from it"},
+	"POST /gateway/ban/:address":           {summary: "bans an IP address from connecting to the gateway"},
+	"POST /gateway/unban/:address":         {summary: "lifts the ban of an IP address"},
+	"GET /gateway/peers/summary":           {summary: "returns the distribution of the gateway's peers across subnets", response: modules.GatewayPeerSummary{}},
+
+	// Host
+	"GET /host":                                     {summary: "returns the settings, metrics and status of the host", response: HostGET{}},
+	"POST /host":                                    {summary: "configures the hosting parameters", params: HostSettingParams},
+	"POST /host/announce":                           {summary: "announces the host to the network", params: []string{"netaddress"}},
+	"GET /host/consistency":                         {summary: "returns the result of the most recent check of the stored sectors against the storage obligations", response: HostConsistencyGET{}},
+	"POST /host/consistency":                        {summary: "checks the stored sectors against the storage obligations, optionally cleaning them up first", params: []string{"cleanup"}, response: HostConsistencyGET{}, expensive: true},
+	"GET /host/contracts":                           {summary: "lists the storage obligations of the host", params: listParamNames, response: HostContractsGET{}},
+	"GET /host/contracts/:id/usage":                 {summary: "returns the usage timeline of a storage obligation", response: HostContractUsageGET{}},
+	"GET /host/estimatescore":                       {summary: "estimates the hostdb score of the host with the provided settings", params: HostSettingParams, response: HostEstimateScoreGET{}},
+	"GET /host/obligations/:id/failure":             {summary: "returns why the storage proof of a failed storage obligation was missed", response: HostObligationFailureGET{}},
+	"POST /host/reindexobligations":                 {summary: "recomputes the confirmation status of the storage obligations", expensive: true},
+	"GET /host/simulatedowntime":                    {summary: "estimates the collateral and revenue the host would lose to a period of downtime", params: []string{"days", "startdays", "missedpercent"}, response: HostSimulateDowntimeGET{}},
+	"GET /host/storage":                             {summary: "lists the storage folders of the host", response: StorageGET{}},
+	"POST /host/storage/folders/add":                {summary: "adds a storage folder to the host", params: []string{"path", "size"}},
+	"POST /host/storage/folders/remove":             {summary: "removes a storage folder from the host", params: []string{"path", "force"}},
+	"POST /host/storage/folders/resize":             {summary: "resizes a storage folder of the host", params: []string{"path", "newsize"}},
+	"POST /host/storage/sectors/delete/:merkleroot": {summary: "deletes a sector from the host"},
+
+	// HostDB
+	"GET /hostdb/active":        {summary: "lists the active hosts of the hostdb", params: []string{"numhosts"}, response: HostdbActiveGET{}},
+	"GET /hostdb/all":           {summary: "lists all hosts of the hostdb", response: HostdbAllGET{}},
+	"GET /hostdb/hosts/:pubkey": {summary: "returns a host of the hostdb and the breakdown of its score", response: HostdbHostsGET{}},
+
+	// Miner
+	"GET /miner":           {summary: "returns the status of the miner", response: MinerGET{}},
+	"GET /miner/header":    {summary: "returns a Sia-encoded block header and target for external miners", binary: true},
+	"POST /miner/header":   {summary: "submits a Sia-encoded solved block header", binary: true},
+	"GET /miner/start":     {summary: "starts the CPU miner"},
+	"GET /miner/stop":      {summary: "stops the CPU miner"},
+	"GET /miner/withhold":  {summary: "returns how long solved blocks are withheld", response: MinerWithholdGET{}},
+	"POST /miner/withhold": {summary: "withholds solved blocks for a number of seconds on dev and testing networks", params: []string{"seconds"}},
+
+	// Renter
+	"GET /renter":                        {summary: "returns the settings and financial metrics of the renter", response: RenterGET{}},
+	"POST /renter":                       {summary: "sets the allowance and upload schedule of the renter", params: []string{"funds", "hosts", "period", "renewwindow", "uploadwindowstart", "uploadwindowend", "maxsystemtraffic"}},
+	"GET /renter/bandwidth":              {summary: "lists the data exchanged with each host and what was paid for it", response: RenterBandwidth{}},
+	"GET /renter/contracts":              {summary: "lists the contracts of the renter", response: RenterContracts{}},
+	"POST /renter/contracts/recover":     {summary: "reattaches old contracts by their IDs, fetching their latest revisions from the hosts", params: []string{"contracts"}, response: RenterContractsRecoverPOST{}},
+	"POST /renter/delete/*siapath":       {summary: "deletes a file from the renter"},
+	"GET /renter/download/*siapath":      {summary: "downloads a file", params: []string{"destination", "offset", "length", "httpresp", "async"}, nobatch: true},
+	"GET /renter/downloadasync/*siapath": {summary: "starts a download in the background", params: []string{"destination", "offset", "length"}, deprecated: "use /renter/download/*siapath with async=true instead"},
+	"GET /renter/downloads":              {summary: "lists the download queue of the renter", response: RenterDownloadQueue{}},
+	"GET /renter/files":                  {summary: "lists the files of the renter", params: listParamNames, response: RenterFiles{}},
+	"GET /renter/files/*siapath":         {summary: "lists the hosts that store the pieces of a file", response: RenterFileHosts{}},
+	"GET /renter/prices":                 {summary: "estimates the prices of storage, upload and download", response: RenterPricesGET{}},
+	"POST /renter/recoverfiles":          {summary: "recovers files from the metadata sectors stored on the renter's hosts", response: RenterLoad{}, expensive: true},
+	"POST /renter/rename/*siapath":       {summary: "renames a file", params: []string{"newsiapath"}},
+	"POST /renter/upload/*siapath":       {summary: "uploads a file", params: []string{"source", "datapieces", "paritypieces"}},
+	"POST /renter/update/*siapath":       {summary: "re-uploads the changed chunks of a file", params: []string{"source"}},
+	"GET /renter/prefetch":               {summary: "reports the progress of prefetching files into the local cache", response: RenterPrefetchGET{}},
+	"POST /renter/prefetch/*siapath":     {summary: "prefetches chunks of a file into the local cache, or evicts them", params: []string{"chunks", "evict"}},
+
+	// Transaction pool
+	"GET /tpool/fee":              {summary: "returns the minimum and maximum estimated fees per byte", response: TpoolFeeGET{}},
+	"GET /tpool/raw/:id":          {summary: "returns a transaction of the transaction pool and its parents", response: TpoolRawGET{}},
+	"GET /tpool/transactions":     {summary: "lists the transactions of the transaction pool with their sizes and fees", response: TpoolTransactionsGET{}},
+	"GET /tpool/transactions/:id": {summary: "describes a transaction of the transaction pool and estimates when it will be confirmed", response: TpoolTransactionGET{}},
+	"POST /tpool/raw":             {summary: "submits a transaction and its parents to the transaction pool", params: []string{"parents", "transaction"}},
+
+	// Wallet
+	"GET /wallet":                            {summary: "returns the status and balances of the wallet", params: []string{"wallet"}, response: WalletGET{}},
+	"GET /wallets":                           {summary: "lists the names of the loaded wallets", response: WalletsGET{}},
+	"POST /wallet/033x":                      {summary: "loads a v0.3.3.x wallet into the wallet", params: []string{"wallet", "source", "encryptionpassword"}},
+	"GET /wallet/address":                    {summary: "returns a new address of the wallet", params: []string{"wallet"}, response: WalletAddressGET{}},
+	"GET /wallet/address/:index":             {summary: "returns the address of the wallet at the provided index", params: []string{"wallet"}, response: WalletAddressGET{}},
+	"GET /wallet/addresses":                  {summary: "lists the addresses of the wallet", params: append([]string{"wallet", "offset"}, listParamNames...), response: WalletAddressesGET{}},
+	"GET /wallet/backup":                     {summary: "creates a backup of the wallet", params: []string{"wallet", "destination", "encryptionpassword"}},
+	"POST /wallet/changepassword":            {summary: "changes the password of the wallet", params: []string{"wallet", "encryptionpassword", "newpassword"}},
+	"GET /wallet/events":                     {summary: "waits for and returns the events of the wallet", params: []string{"wallet", "since", "timeout"}, response: WalletEventsGET{}},
+	"GET /wallet/history/daily":              {summary: "returns a daily snapshot of the wallet balance", params: []string{"wallet"}, response: WalletHistoryDailyGET{}},
+	"POST /wallet/init":                      {summary: "initializes the wallet with a new seed", params: []string{"wallet", "encryptionpassword", "dictionary", "force"}, response: WalletInitPOST{}},
+	"POST /wallet/init/seed":                 {summary: "initializes the wallet with an existing seed", params: []string{"wallet", "encryptionpassword", "dictionary", "seed", "force"}, expensive: true},
+	"POST /wallet/lock":                      {summary: "locks the wallet", params: []string{"wallet"}},
+	"POST /wallet/partial/create":            {summary: "creates a partial transaction from a transaction set, to be signed by several signers", params: []string{"wallet", "transactions", "description"}, response: WalletPartialPOST{}},
+	"POST /wallet/partial/finalize":          {summary: "turns a partial transaction with enough signatures into a signed transaction set", params: []string{"wallet", "partial", "broadcast"}, response: WalletPartialFinalizePOST{}},
+	"POST /wallet/partial/merge":             {summary: "combines the signatures of several copies of a partial transaction", params: []string{"wallet", "partials"}, response: WalletPartialPOST{}},
+	"POST /wallet/partial/sign":              {summary: "adds the signatures of the wallet to a partial transaction", params: []string{"wallet", "partial"}, response: WalletPartialPOST{}},
+	"POST /wallet/reindex":                   {summary: "rebuilds the outputs and history of the wallet by rescanning the blockchain", params: []string{"wallet"}, expensive: true},
+	"GET /wallet/requests":                   {summary: "lists the payment requests of the wallet", params: []string{"wallet"}, response: WalletRequestsGET{}},
+	"POST /wallet/requests":                  {summary: "creates a payment request for a fresh address", params: []string{"wallet", "amount", "label", "message", "expires"}, response: WalletPaymentRequest{}},
+	"GET /wallet/requests/:addr":             {summary: "returns the payment request for an address", params: []string{"wallet"}, response: WalletPaymentRequest{}},
+	"GET /wallet/reserves":                   {summary: "creates a proof that the wallet controls an amount of siacoins", params: []string{"wallet", "amount", "challenge"}, response: WalletReservesGET{}},
+	"POST /wallet/restore":                   {summary: "restores the wallet from a backup", params: []string{"wallet", "source", "encryptionpassword"}},
+	"POST /wallet/seed":                      {summary: "adds a seed to the wallet", params: []string{"wallet", "encryptionpassword", "dictionary", "seed"}, expensive: true},
+	"GET /wallet/seeds":                      {summary: "returns the seeds of the wallet", params: []string{"wallet", "dictionary"}, response: WalletSeedsGET{}},
+	"GET /wallet/settings":                   {summary: "returns the settings of the wallet", params: []string{"wallet"}, response: WalletSettingsGET{}},
+	"POST /wallet/settings":                  {summary: "changes the settings of the wallet", params: []string{"wallet", "changepolicy", "changeaddress", "gaplimit", "autoclaimsiafunds", "dustthreshold", "rejectdustoutputs", "excludedustinputs", "confirmedinputsonly", "coinselection", "oldestselectionthreshold", "minconfirmations"}},
+	"POST /wallet/siacoins":                  {summary: "sends siacoins", params: []string{"wallet", "amount", "destination", "outputs", "all", "confirmtarget", "replaceable", "data", "dryrun"}, response: WalletSiacoinsPOST{}},
+	"POST /wallet/siafunds":                  {summary: "sends siafunds", params: []string{"wallet", "amount", "destination", "outputs", "dryrun"}, response: WalletSiafundsPOST{}},
+	"POST /wallet/siafunds/broadcast":        {summary: "signs the wallet's inputs of a prepared siafund transaction and broadcasts it", params: []string{"wallet", "transactions"}, response: WalletSiafundsPOST{}},
+	"POST /wallet/siafunds/claim":            {summary: "claims the siacoins earned by the siafunds of the wallet", params: []string{"wallet"}, response: WalletSiafundsPOST{}},
+	"POST /wallet/siafunds/prepare":          {summary: "prepares a transaction sending siafunds from an address whose keys are kept offline", params: []string{"wallet", "amount", "destination", "source"}, response: WalletSiafundsPreparePOST{}},
+	"POST /wallet/siafunds/trackdir":         {summary: "loads every siag key file in a directory", params: []string{"wallet", "dir", "encryptionpassword"}, response: WalletSiafundsTrackDirPOST{}},
+	"POST /wallet/siafunds/watchsiagaddress": {summary: "watches the siafund addresses of siag key files", params: []string{"wallet", "keyfiles", "encryptionpassword"}},
+	"POST /wallet/siagkey":                   {summary: "loads siag key files into the wallet", params: []string{"wallet", "keyfiles", "encryptionpassword"}},
+	"POST /wallet/sweep/seed":                {summary: "sends the outputs of a seed to the wallet", params: []string{"wallet", "dictionary", "seed"}, response: WalletSweepPOST{}, expensive: true},
+	"GET /wallet/transaction/:id":            {summary: "returns a transaction of the wallet", params: []string{"wallet"}, response: WalletTransactionGETid{}},
+	"POST /wallet/transaction/:id/bumpfee":   {summary: "replaces an unconfirmed transaction with one that pays a higher fee", params: []string{"wallet", "fee"}, response: WalletTransactionReplacePOST{}},
+	"POST /wallet/transaction/:id/cancel":    {summary: "replaces an unconfirmed transaction with one that returns its funds to the wallet", params: []string{"wallet", "fee"}, response: WalletTransactionReplacePOST{}},
+	"POST /wallet/transaction/:id/label":     {summary: "sets the label of a transaction", params: []string{"wallet", "label"}},
+	"GET /wallet/transactions":               {summary: "lists the transactions of the wallet", params: append([]string{"wallet", "startheight", "endheight", "query", "minamount", "maxamount"}, listParamNames...), response: WalletTransactionsGET{}},
+	"GET /wallet/transactions/:addr":         {summary: "lists the transactions of the wallet that involve an address; 'unconfirmed' is a deprecated alias of /wallet/unconfirmed", params: []string{"wallet"}, response: WalletTransactionsGETaddr{}},
+	"GET /wallet/unconfirmed":                {summary: "lists the unconfirmed transactions of the wallet, oldest first", params: []string{"wallet"}, response: WalletTransactionsUnconfirmedGET{}},
+	"POST /wallet/unlock":                    {summary: "unlocks the wallet", params: []string{"wallet", "encryptionpassword"}, expensive: true},
+	"GET /wallet/verify/address/:addr":       {summary: "checks that an address is well formed", params: []string{"wallet"}, response: WalletVerifyAddressGET{}},
+}
+
+// HostSettingParams are the parameters of the settings that can be passed to
+// /host [POST], /host/estimatescore and API.SetHostSettings.
+var HostSettingParams = []string{
+	"acceptingcontracts", "maxdownloadbatchsize", "maxduration", "maxrevisebatchsize", "netaddress", "windowsize",
+	"collateral", "collateralbudget", "maxcollateral",
+	"mincontractprice", "mindownloadbandwidthprice", "minstorageprice", "minuploadbandwidthprice",
+}
+
+// matchRoute reports whether path is matched by route, a path of the router
+// that may contain :param and *param segments.
+func matchRoute(route, path string) bool {
+	routeSegs := strings.Split(route, "/")
+	pathSegs := strings.Split(path, "/")
+	for i, seg := range routeSegs {
+		if strings.HasPrefix(seg, "*") {
+			return i < len(pathSegs)
+		}
+		if i >= len(pathSegs) {
+			return false
+		}
+		if strings.HasPrefix(seg, ":") {
+			if pathSegs[i] == "" {
+				return false
+			}
+		} else if seg != pathSegs[i] {
+			return false
+		}
+	}
+	return len(routeSegs) == len(pathSegs)
+}
+
+// lookupRouteDoc returns the routeDoc of the route that serves a request with
+// the given method and unversioned path. Like the router, it prefers static
+// segments to parameters.
+func lookupRouteDoc(method, path string) (routeDoc, bool) {
+	var match routeDoc
+	found, fewestParams := false, 0
+	for key, doc := range routeDocs {
+		route := strings.TrimPrefix(key, method+" ")
+		if route == key || !matchRoute(route, path) {
+			continue
+		}
+		params := strings.Count(route, ":") + strings.Count(route, "*")
+		if !found || params < fewestParams {
+			match, found, fewestParams = doc, true, params
+		}
+	}
+	return match, found
+}
+
+// GET registers a handler for GET requests and records the route.
+func (r *specRouter) GET(path string, h httprouter.Handle) {
+	r.routes = append(r.routes, specRoute{method: "GET", path: path})
+	if msg := routeDocs["GET "+path].deprecated; msg != "" {
+		h = deprecated(h, msg)
+	}
+	r.Router.GET(path, r.limiter.limit(routeClass("GET", path), h))
+}
+
+// POST registers a handler for POST requests and records the route.
+func (r *specRouter) POST(path string, h httprouter.Handle) {
+	r.routes = append(r.routes, specRoute{method: "POST", path: path})
+	if msg := routeDocs["POST "+path].deprecated; msg != "" {
+		h = deprecated(h, msg)
+	}
+	r.Router.POST(path, r.limiter.limit(routeClass("POST", path), h))
+}
+
+// schemaBuilder generates the schemas of Go types, collecting the schemas of
+// named struct types as components.
+type schemaBuilder struct {
+	components map[string]*OpenAPISchema
+}
+
+// schema returns the schema of the JSON encoding of t.
+func (sb *schemaBuilder) schema(t reflect.Type) *OpenAPISchema {
+	// Types that encode themselves are strings in Sia, e.g. hashes and
+	// currencies.
+	if t == reflect.TypeOf(time.Time{}) {
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+	// Raw JSON can hold any value.
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return &OpenAPISchema{}
+	}
+	jsonMarshaler := reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler := reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	if t.Implements(jsonMarshaler) || t.Implements(textMarshaler) ||
+		reflect.PtrTo(t).Implements(jsonMarshaler) || reflect.PtrTo(t).Implements(textMarshaler) {
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &OpenAPISchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Ptr:
+		return sb.schema(t.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// byte slices are base64 encoded
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: sb.schema(t.Elem())}
+	case reflect.Array:
+		return &OpenAPISchema{Type: "array", Items: sb.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: sb.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sb.structSchema(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, exists := sb.components[name]; !exists {
+			// Reserve the name before generating the schema, so that
+			// recursive types terminate.
+			sb.components[name] = nil
+			sb.components[name] = sb.structSchema(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	default:
+		// interfaces can hold any value
+		return &OpenAPISchema{}
+	}
+}
+
+// structSchema returns the schema of a struct, following the field naming
+// rules of encoding/json.
+func (sb *schemaBuilder) structSchema(t reflect.Type) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			// The fields of embedded structs are promoted.
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for n, p := range sb.structSchema(ft).Properties {
+					s.Properties[n] = p
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = sb.schema(f.Type)
+	}
+	return s
+}
+
+// operationID returns a unique identifier for a route, e.g.
+// postWalletTransactionByIdBumpfee for POST /wallet/transaction/:id/bumpfee.
+func operationID(method, route string) string {
+	id := strings.ToLower(method)
+	for _, seg := range strings.Split(route, "/") {
+		if seg == "" {
+			continue
+		}
+		if seg[0] == ':' || seg[0] == '*' {
+			seg = "By" + strings.Title(seg[1:])
+		}
+		id += strings.Title(seg)
+	}
+	return id
+}
+
+// buildSpec generates the OpenAPI document of the provided routes.
+func buildSpec(routes []specRoute) OpenAPISpec {
+	spec := OpenAPISpec{
+		OpenAPI: "3.0.0",
+		Info: OpenAPIInfo{
+			Title:   "Sia API",
+			Version: build.Version,
+		},
+		Servers: []OpenAPIServer{{URL: versionPrefix(APIVersion)}},
+		Paths:   make(map[string]map[string]OpenAPIOperation),
+	}
+	sb := &schemaBuilder{components: make(map[string]*OpenAPISchema)}
+	errorSchema := sb.schema(reflect.TypeOf(Error{}))
+	for _, r := range routes {
+		doc, exists := routeDocs[r.method+" "+r.path]
+		if !exists {
+			build.Critical("API route is not documented:", r.method, r.path)
+		}
+
+		op := OpenAPIOperation{
+			OperationID: operationID(r.method, r.path),
+			Summary:     doc.summary,
+			Description: doc.deprecated,
+			Deprecated:  doc.deprecated != "",
+			Responses: map[string]OpenAPIResponse{
+				"default": {
+					Description: "error",
+					Content:     map[string]OpenAPIMediaType{"application/json": {Schema: errorSchema}},
+				},
+			},
+		}
+		// Path parameters are written as {name} in OpenAPI.
+		segs := strings.Split(r.path, "/")
+		for i, seg := range segs {
+			if seg == "" || (seg[0] != ':' && seg[0] != '*') {
+				continue
+			}
+			segs[i] = "{" + seg[1:] + "}"
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name:     seg[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &OpenAPISchema{Type: "string"},
+			})
+		}
+		for _, p := range doc.params {
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name:   p,
+				In:     "query",
+				Schema: &OpenAPISchema{Type: "string"},
+			})
+		}
+
+		binary := map[string]OpenAPIMediaType{"application/octet-stream": {Schema: &OpenAPISchema{Type: "string", Format: "binary"}}}
+		switch {
+		case doc.binary && r.method == "POST":
+			op.RequestBody = &OpenAPIBody{Required: true, Content: binary}
+		case doc.body != nil:
+			op.RequestBody = &OpenAPIBody{
+				Required: true,
+				Content:  map[string]OpenAPIMediaType{"application/json": {Schema: sb.schema(reflect.TypeOf(doc.body))}},
+			}
+		}
+		switch {
+		case doc.binary && r.method == "GET":
+			op.Responses["200"] = OpenAPIResponse{Description: "success", Content: binary}
+		case doc.response != nil:
+			op.Responses["200"] = OpenAPIResponse{
+				Description: "success",
+				Content:     map[string]OpenAPIMediaType{"application/json": {Schema: sb.schema(reflect.TypeOf(doc.response))}},
+			}
+		default:
+			op.Responses["204"] = OpenAPIResponse{Description: "success"}
+		}
+
+		p := strings.Join(segs, "/")
+		if spec.Paths[p] == nil {
+			spec.Paths[p] = make(map[string]OpenAPIOperation)
+		}
+		spec.Paths[p][strings.ToLower(r.method)] = op
+	}
+	spec.Components.Schemas = sb.components
+	return spec
+}
+
+// specHandler handles the API call to /spec.
+func (api *API) specHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, api.spec)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestSpec checks that /spec describes every route of a server that loads
+// all modules except the explorer, and that the schemas it references exist.
+func TestSpec(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var spec OpenAPISpec
+	if err := st.getAPI("/spec", &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.OpenAPI != "3.0.0" {
+		t.Fatal("wrong OpenAPI version:", spec.OpenAPI)
+	}
+	for route := range routeDocs {
+		fields := strings.SplitN(route, " ", 2)
+		method, path := strings.ToLower(fields[0]), fields[1]
+		if strings.HasPrefix(path, "/explorer") {
+			continue
+		}
+		segs := strings.Split(path, "/")
+		for i, seg := range segs {
+			if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+				segs[i] = "{" + seg[1:] + "}"
+			}
+		}
+		op, exists := spec.Paths[strings.Join(segs, "/")][method]
+		if !exists {
+			t.Error("route is missing from the spec:", route)
+			continue
+		}
+		if op.Summary == "" || op.OperationID == "" {
+			t.Error("route is not described:", route)
+		}
+	}
+
+	// Every reference must resolve to a schema.
+	var checkRefs func(s *OpenAPISchema)
+	checkRefs = func(s *OpenAPISchema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+			if spec.Components.Schemas[name] == nil {
+				t.Error("unresolved schema reference:", s.Ref)
+			}
+		}
+		checkRefs(s.Items)
+		checkRefs(s.AdditionalProperties)
+		for _, p := range s.Properties {
+			checkRefs(p)
+		}
+	}
+	for _, s := range spec.Components.Schemas {
+		checkRefs(s)
+	}
+	for _, ops := range spec.Paths {
+		for _, op := range ops {
+			for _, r := range op.Responses {
+				for _, c := range r.Content {
+					checkRefs(c.Schema)
+				}
+			}
+		}
+	}
+	if spec.Components.Schemas["api.ConsensusGET"] == nil {
+		t.Fatal("spec is missing the schema of ConsensusGET")
+	}
+	if s := spec.Components.Schemas["api.ConsensusGET"].Properties["height"]; s == nil || s.Type != "integer" {
+		t.Error("wrong schema for ConsensusGET.Height:", s)
+	}
+}
+
+// TestOperationID probes the operationID function.
+func TestOperationID(t *testing.T) {
+	tests := []struct {
+		method, path, id string
+	}{
+		{"GET", "/consensus", "getConsensus"},
+		{"POST", "/wallet/transaction/:id/bumpfee", "postWalletTransactionByIdBumpfee"},
+		{"GET", "/renter/files/*siapath", "getRenterFilesBySiapath"},
+	}
+	for _, test := range tests {
+		if id := operationID(test.method, test.path); id != test.id {
+			t.Errorf("operationID(%v, %v): expected %v, got %v", test.method, test.path, test.id, id)
+		}
+	}
+}
+
+// TestRouteDocs checks that every route registered by the API has a routeDoc
+// with the same method and path parameters, and that every routeDoc describes
+// a registered route.
+func TestRouteDocs(t *testing.T) {
+	// The routes of a module are registered if it is not nil, so a set of
+	// placeholder modules registers every route.
+	api := &API{
+		cs:       struct{ modules.ConsensusSet }{},
+		explorer: struct{ modules.Explorer }{},
+		gateway:  struct{ modules.Gateway }{},
+		host:     struct{ modules.Host }{},
+		miner:    struct{ modules.Miner }{},
+		renter:   struct{ modules.Renter }{},
+		tpool:    struct{ modules.TransactionPool }{},
+		wallet:   struct{ modules.Wallet }{},
+		limiter:  newRateLimiter(),
+	}
+	router := api.newRouter("")
+
+	// withoutParamNames replaces the names of the path parameters of a route,
+	// so that routes that differ only in those names can be reported.
+	withoutParamNames := func(route string) string {
+		segs := strings.Split(route, "/")
+		for i, seg := range segs {
+			if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+				segs[i] = seg[:1]
+			}
+		}
+		return strings.Join(segs, "/")
+	}
+	docs := make(map[string]string)
+	for route := range routeDocs {
+		docs[withoutParamNames(route)] = route
+	}
+
+	registered := make(map[string]bool)
+	for _, r := range router.routes {
+		route := r.method + " " + r.path
+		if registered[route] {
+			t.Error("route is registered twice:", route)
+		}
+		registered[route] = true
+		if _, ok := routeDocs[route]; ok {
+			continue
+		} else if doc, ok := docs[withoutParamNames(route)]; ok {
+			t.Errorf("route %v is documented with different path parameters as %v", route, doc)
+		} else {
+			t.Error("route is not documented:", route)
+		}
+	}
+	for route := range routeDocs {
+		if !registered[route] {
+			t.Error("documented route is not registered:", route)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/base64"
 	"net/http"
+	"sort"
 
 	"github.com/julienschmidt/httprouter"
 
@@ -18,6 +19,52 @@ type (
 		Maximum types.Currency `json:"maximum"`
 	}
 
+	// TpoolTransaction is a transaction in the transaction pool, with its
+	// encoded size and the miner fees that it pays.
+	TpoolTransaction struct {
+		ID         types.TransactionID `json:"id"`
+		Size       uint64              `json:"size"`
+		Fee        types.Currency      `json:"fee"`
+		FeePerByte types.Currency      `json:"feeperbyte"`
+	}
+
+	// TpoolFeeDistribution is the distribution of the fees per byte paid by
+	// the transactions in the transaction pool.
+	TpoolFeeDistribution struct {
+		Minimum       types.Currency `json:"minimum"`
+		LowerQuartile types.Currency `json:"lowerquartile"`
+		Median        types.Currency `json:"median"`
+		UpperQuartile types.Currency `json:"upperquartile"`
+		Maximum       types.Currency `json:"maximum"`
+	}
+
+	// TpoolTransactionsGET lists the transactions in the transaction pool,
+	// highest fee per byte first.
+	TpoolTransactionsGET struct {
+		Count           int                  `json:"count"`
+		Size            uint64               `json:"size"`
+		TotalFees       types.Currency       `json:"totalfees"`
+		FeeDistribution TpoolFeeDistribution `json:"feedistribution"`
+		Transactions    []TpoolTransaction   `json:"transactions"`
+	}
+
+	// TpoolTransactionGET describes a transaction in the transaction pool
+	// and its prospects of being confirmed. Miners consider a transaction
+	// together with its unconfirmed parents, so the fee per byte of the set
+	// is what competes with the rest of the pool. BytesAhead is the size of
+	// the transactions that pay a higher fee per byte than the set, and
+	// EstimatedBlocks the number of full blocks needed to confirm them and
+	// then the set.
+	TpoolTransactionGET struct {
+		TpoolTransaction
+		Transaction           types.Transaction  `json:"transaction"`
+		Parents               []TpoolTransaction `json:"parents"`
+		SetFeePerByte         types.Currency     `json:"setfeeperbyte"`
+		RecommendedFeePerByte types.Currency     `json:"recommendedfeeperbyte"`
+		BytesAhead            uint64             `json:"bytesahead"`
+		EstimatedBlocks       uint64             `json:"estimatedblocks"`
+	}
+
 	// TpoolRawGET contains the requested transaction encoded to the raw
 	// format, along with the id of that transaction.
 	TpoolRawGET struct {
@@ -47,17 +94,133 @@ func (api *API) tpoolFeeHandlerGET(w http.ResponseWriter, req *http.Request, ps
 	})
 }
 
+// tpoolTransaction returns the size and fees of a transaction.
+func tpoolTransaction(txn types.Transaction) TpoolTransaction {
+	tt := TpoolTransaction{
+		ID:   txn.ID(),
+		Size: uint64(len(encoding.Marshal(txn))),
+	}
+	for _, fee := range txn.MinerFees {
+		tt.Fee = tt.Fee.Add(fee)
+	}
+	tt.FeePerByte = tt.Fee.Div64(tt.Size)
+	return tt
+}
+
+// tpoolTransactions returns the transactions in the transaction pool, highest
+// fee per byte first.
+func (api *API) tpoolTransactions() []TpoolTransaction {
+	seen := make(map[types.TransactionID]struct{})
+	txns := make([]TpoolTransaction, 0)
+	for _, txn := range api.tpool.TransactionList() {
+		tt := tpoolTransaction(txn)
+		if _, ok := seen[tt.ID]; ok {
+			continue
+		}
+		seen[tt.ID] = struct{}{}
+		txns = append(txns, tt)
+	}
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].FeePerByte.Cmp(txns[j].FeePerByte) > 0
+	})
+	return txns
+}
+
+// feeDistribution returns the distribution of the fees per byte of txns,
+// which are sorted by decreasing fee per byte.
+func feeDistribution(txns []TpoolTransaction) TpoolFeeDistribution {
+	if len(txns) == 0 {
+		return TpoolFeeDistribution{}
+	}
+	quartile := func(q int) types.Currency {
+		return txns[(len(txns)-1)*(4-q)/4].FeePerByte
+	}
+	return TpoolFeeDistribution{
+		Minimum:       quartile(0),
+		LowerQuartile: quartile(1),
+		Median:        quartile(2),
+		UpperQuartile: quartile(3),
+		Maximum:       quartile(4),
+	}
+}
+
+// tpoolTransactionsHandlerGET lists the transactions in the transaction pool,
+// with their total size and the distribution of their fees.
+func (api *API) tpoolTransactionsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var txns []TpoolTransaction
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"fee":        func(i, j int) bool { return txns[i].Fee.Cmp(txns[j].Fee) < 0 },
+		"feeperbyte": func(i, j int) bool { return txns[i].FeePerByte.Cmp(txns[j].FeePerByte) < 0 },
+		"size":       func(i, j int) bool { return txns[i].Size < txns[j].Size },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /tpool/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txns = api.tpoolTransactions()
+	ttg := TpoolTransactionsGET{
+		Count:           len(txns),
+		FeeDistribution: feeDistribution(txns),
+	}
+	for _, tt := range txns {
+		ttg.Size += tt.Size
+		ttg.TotalFees = ttg.TotalFees.Add(tt.Fee)
+	}
+	start, end := lp.Apply(w, txns)
+	ttg.Transactions = txns[start:end]
+	WriteJSON(w, ttg)
+}
+
+// tpoolTransactionHandlerGET describes a transaction in the transaction pool
+// and estimates how many blocks it will take to be confirmed.
+func (api *API) tpoolTransactionHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	txid, err := decodeTransactionID(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{Message: "error decoding transaction id:" + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txn, parents, exists := api.tpool.Transaction(txid)
+	if !exists {
+		WriteError(w, Error{Message: "transaction not found in transaction pool"}, http.StatusBadRequest)
+		return
+	}
+
+	ttg := TpoolTransactionGET{
+		TpoolTransaction: tpoolTransaction(txn),
+		Transaction:      txn,
+		Parents:          make([]TpoolTransaction, 0, len(parents)),
+	}
+	setSize, setFee := ttg.Size, ttg.Fee
+	setIDs := map[types.TransactionID]struct{}{txid: {}}
+	for _, parent := range parents {
+		tt := tpoolTransaction(parent)
+		ttg.Parents = append(ttg.Parents, tt)
+		setSize += tt.Size
+		setFee = setFee.Add(tt.Fee)
+		setIDs[tt.ID] = struct{}{}
+	}
+	ttg.SetFeePerByte = setFee.Div64(setSize)
+	ttg.RecommendedFeePerByte, _ = api.tpool.FeeEstimation()
+	for _, tt := range api.tpoolTransactions() {
+		if _, ok := setIDs[tt.ID]; !ok && tt.FeePerByte.Cmp(ttg.SetFeePerByte) > 0 {
+			ttg.BytesAhead += tt.Size
+		}
+	}
+	ttg.EstimatedBlocks = (ttg.BytesAhead+setSize)/types.BlockSizeLimit + 1
+	WriteJSON(w, ttg)
+}
+
 // tpoolRawHandlerGET will provide the raw byte representation of a
 // transaction that matches the input id.
 func (api *API) tpoolRawHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	txid, err := decodeTransactionID(ps.ByName("id"))
 	if err != nil {
-		WriteError(w, Error{"error decoding transaction id:" + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error decoding transaction id:" + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	txn, parents, exists := api.tpool.Transaction(txid)
 	if !exists {
-		WriteError(w, Error{"transaction not found in transaction pool"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "transaction not found in transaction pool"}, http.StatusBadRequest)
 		return
 	}
 
@@ -88,12 +251,12 @@ func (api *API) tpoolRawHandlerPOST(w http.ResponseWriter, req *http.Request, _
 	var txn types.Transaction
 	err = encoding.Unmarshal(rawParents, &parents)
 	if err != nil {
-		WriteError(w, Error{"error decoding parents:" + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error decoding parents:" + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	err = encoding.Unmarshal(rawTransaction, &txn)
 	if err != nil {
-		WriteError(w, Error{"error decoding transaction:" + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error decoding transaction:" + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	txnSet := append(parents, txn)
@@ -103,7 +266,7 @@ func (api *API) tpoolRawHandlerPOST(w http.ResponseWriter, req *http.Request, _
 	api.tpool.Broadcast(txnSet)
 	err = api.tpool.AcceptTransactionSet(txnSet)
 	if err != nil && err != modules.ErrDuplicateTransactionSet {
-		WriteError(w, Error{"error accepting transaction set:" + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error accepting transaction set:" + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -199,3 +199,73 @@ func TestTransactionPoolFee(t *testing.T) {
 		t.Fatal("fee mismatch")
 	}
 }
+
+// TestTransactionPoolTransactions checks that the transactions of the
+// transaction pool are listed with their sizes and fees, and that a single
+// transaction can be inspected.
+func TestTransactionPoolTransactions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.panicClose()
+
+	var ttg TpoolTransactionsGET
+	err = st.getAPI("/tpool/transactions", &ttg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttg.Count != 0 || ttg.Size != 0 || len(ttg.Transactions) != 0 {
+		t.Fatal("expected an empty transaction pool:", ttg)
+	}
+
+	txns, err := st.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(1000), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.getAPI("/tpool/transactions", &ttg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttg.Count != len(txns) || len(ttg.Transactions) != len(txns) {
+		t.Fatalf("expected %v transactions, got %v", len(txns), ttg.Count)
+	}
+	var size uint64
+	for i, tt := range ttg.Transactions {
+		size += tt.Size
+		if i > 0 && tt.FeePerByte.Cmp(ttg.Transactions[i-1].FeePerByte) > 0 {
+			t.Fatal("transactions are not sorted by fee per byte")
+		}
+	}
+	if ttg.Size != size || ttg.TotalFees.IsZero() {
+		t.Fatal("wrong totals:", ttg.Size, ttg.TotalFees)
+	}
+	if ttg.FeeDistribution.Minimum.Cmp(ttg.FeeDistribution.Median) > 0 || ttg.FeeDistribution.Median.Cmp(ttg.FeeDistribution.Maximum) > 0 {
+		t.Fatal("wrong fee distribution:", ttg.FeeDistribution)
+	}
+
+	lastTxn := txns[len(txns)-1]
+	var tg TpoolTransactionGET
+	err = st.getAPI("/tpool/transactions/"+lastTxn.ID().String(), &tg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tg.ID != lastTxn.ID() || tg.Transaction.ID() != lastTxn.ID() || tg.Size != uint64(len(encoding.Marshal(lastTxn))) {
+		t.Fatal("wrong transaction:", tg.ID)
+	}
+	if len(tg.Parents) != len(txns)-1 {
+		t.Fatalf("expected %v parents, got %v", len(txns)-1, len(tg.Parents))
+	}
+	if tg.EstimatedBlocks != 1 || tg.SetFeePerByte.IsZero() {
+		t.Fatal("wrong confirmation estimate:", tg.EstimatedBlocks, tg.SetFeePerByte)
+	}
+
+	err = st.getAPI("/tpool/transactions/"+types.TransactionID{}.String(), &tg)
+	if err == nil {
+		t.Fatal("expected an error for a transaction that is not in the pool")
+	}
+}
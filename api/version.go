@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Every route of the API is served under the prefix of each supported
+// version, e.g. /v1/wallet, and at its original unversioned path. Requests
+// without a prefix are served as UnversionedAPIVersion, so existing clients
+// keep working when a later version changes the shape of a response. A
+// handler whose response changes between versions checks RequestVersion.
+//
+// A route that will be removed is marked deprecated in routeDocs. Its
+// responses carry a Deprecation header, a Sunset header with the date after
+// which it may be removed and a Warning naming its replacement, and /spec
+// marks it as deprecated. Deprecated routes are kept for at least one major
+// release.
+
+const (
+	// APIVersion is the latest version of the API.
+	APIVersion = 1
+
+	// UnversionedAPIVersion is the version of the API that serves requests
+	// without a version prefix.
+	UnversionedAPIVersion = 1
+
+	// DeprecationSunset is the date, sent in the Sunset header of deprecated
+	// routes, after which the next major release may remove them.
+	DeprecationSunset = "Fri, 01 Oct 2027 00:00:00 GMT"
+)
+
+// versionKey is the context key of the version of a request.
+type versionKey struct{}
+
+// RequestVersion returns the version of the API that a request was made
+// for.
+func RequestVersion(req *http.Request) int {
+	if v, ok := req.Context().Value(versionKey{}).(int); ok {
+		return v
+	}
+	return UnversionedAPIVersion
+}
+
+// versionPrefix returns the path prefix of a version of the API.
+func versionPrefix(version int) string {
+	return "/v" + strconv.Itoa(version)
+}
+
+// splitVersion splits the version prefix from the path of a request. ok is
+// false if the prefix names a version that is not supported.
+func splitVersion(path string) (version int, rest string, ok bool) {
+	if !strings.HasPrefix(path, "/v") {
+		return UnversionedAPIVersion, path, true
+	}
+	prefix := path
+	if i := strings.Index(path[1:], "/"); i >= 0 {
+		prefix = path[:i+1]
+	}
+	if len(prefix) < 3 || prefix[2] < '1' || prefix[2] > '9' {
+		return UnversionedAPIVersion, path, true
+	}
+	v, err := strconv.Atoi(prefix[2:])
+	if err != nil {
+		return UnversionedAPIVersion, path, true
+	}
+	if v > APIVersion {
+		return v, path, false
+	}
+	rest = strings.TrimPrefix(path, prefix)
+	if rest == "" {
+		rest = "/"
+	}
+	return v, rest, true
+}
+
+// ServeVersioned is middleware that strips the version prefix from the path of
+// a request and records the version of the request, so that the handler can
+// serve every version of the API. The version is sent in the Sia-API-Version
+// header of the response.
+func ServeVersioned(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The prefix was already stripped by an outer handler.
+		if _, ok := req.Context().Value(versionKey{}).(int); ok {
+			h.ServeHTTP(w, req)
+			return
+		}
+		version, path, ok := splitVersion(req.URL.Path)
+		if !ok {
+			WriteError(w, Error{Message: "unsupported API version " + strconv.Itoa(version) + ", the latest version is " + strconv.Itoa(APIVersion)}, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Sia-API-Version", strconv.Itoa(version))
+		req = req.WithContext(context.WithValue(req.Context(), versionKey{}, version))
+		if path != req.URL.Path {
+			u := *req.URL
+			u.Path = path
+			if u.RawPath != "" {
+				u.RawPath = strings.TrimPrefix(u.RawPath, versionPrefix(version))
+			}
+			req.URL = &u
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// markDeprecated marks a response as coming from a deprecated route, or a
+// deprecated use of a route, with a message naming its replacement.
+func markDeprecated(w http.ResponseWriter, message string) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Sunset", DeprecationSunset)
+	w.Header().Set("Warning", `299 - "`+strings.Replace(message, `"`, `'`, -1)+`"`)
+}
+
+// deprecated is middleware that marks the responses of a deprecated route.
+func deprecated(h httprouter.Handle, message string) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		markDeprecated(w, message)
+		h(w, req, ps)
+	}
+}
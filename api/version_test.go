@@ -0,0 +1,154 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// TestSplitVersion checks that version prefixes are split from paths.
+func TestSplitVersion(t *testing.T) {
+	tests := []struct {
+		path    string
+		version int
+		rest    string
+		ok      bool
+	}{
+		{"/wallet", UnversionedAPIVersion, "/wallet", true},
+		{"/v1/wallet", 1, "/wallet", true},
+		{"/v1", 1, "/", true},
+		{"/v1/", 1, "/", true},
+		{"/v2/wallet", 2, "/v2/wallet", false},
+		{"/v01/wallet", UnversionedAPIVersion, "/v01/wallet", true},
+		{"/vfoo/wallet", UnversionedAPIVersion, "/vfoo/wallet", true},
+		{"/v", UnversionedAPIVersion, "/v", true},
+		{"/", UnversionedAPIVersion, "/", true},
+	}
+	for _, test := range tests {
+		version, rest, ok := splitVersion(test.path)
+		if version != test.version || rest != test.rest || ok != test.ok {
+			t.Errorf("splitVersion(%q): expected %v %q %v, got %v %q %v", test.path, test.version, test.rest, test.ok, version, rest, ok)
+		}
+	}
+}
+
+// TestServeVersioned checks that versioned requests reach the handler
+// without their prefix, and that unsupported versions are rejected.
+func TestServeVersioned(t *testing.T) {
+	var gotPath, gotRawPath string
+	var gotVersion int
+	h := ServeVersioned(ServeVersioned(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotPath, gotRawPath = req.URL.Path, req.URL.RawPath
+		gotVersion = RequestVersion(req)
+	})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/renter/files/foo%2Fbar", nil))
+	if gotPath != "/renter/files/foo/bar" || gotRawPath != "/renter/files/foo%2Fbar" || gotVersion != 1 {
+		t.Fatal("wrong versioned request:", gotPath, gotRawPath, gotVersion)
+	}
+	if rec.Header().Get("Sia-API-Version") != "1" {
+		t.Fatal("missing Sia-API-Version header")
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/wallet", nil))
+	if gotPath != "/wallet" || gotVersion != UnversionedAPIVersion {
+		t.Fatal("wrong unversioned request:", gotPath, gotVersion)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/v9/wallet", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatal("expected 404 for an unsupported version, got", rec.Code)
+	}
+}
+
+// TestDeprecatedRoute checks that deprecated routes are marked in their
+// responses and in the spec.
+func TestDeprecatedRoute(t *testing.T) {
+	routeDocs["GET /foo"] = routeDoc{summary: "foo", deprecated: "use /bar instead"}
+	defer delete(routeDocs, "GET /foo")
+
+	router := &specRouter{Router: httprouter.New()}
+	router.GET("/foo", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		WriteSuccess(w)
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/foo", nil))
+	if rec.Header().Get("Deprecation") != "true" || rec.Header().Get("Sunset") != DeprecationSunset || rec.Header().Get("Warning") != `299 - "use /bar instead"` {
+		t.Fatal("deprecated route was not marked:", rec.Header())
+	}
+
+	spec := buildSpec(router.routes)
+	if op := spec.Paths["/foo"]["get"]; !op.Deprecated || op.Description != "use /bar instead" {
+		t.Fatalf("deprecated route was not marked in the spec: %+v", op)
+	}
+}
+
+// TestVersionedRoutes checks that the API serves its routes under /v1.
+func TestVersionedRoutes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var cg ConsensusGET
+	if err := st.getAPI("/v1/consensus", &cg); err != nil {
+		t.Fatal(err)
+	}
+	if cg.Height != st.cs.Height() {
+		t.Fatal("wrong height:", cg.Height)
+	}
+	if err := st.stdGetAPI("/v9/consensus"); err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+}
+
+// TestDeprecatedRoutes checks that the deprecated routes of the API, and the
+// deprecated uses of its routes, are marked in their responses.
+func TestDeprecatedRoutes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	addr := "http://" + st.server.listener.Addr().String()
+	tests := []struct {
+		call       string
+		deprecated bool
+	}{
+		{"/wallet/transactions/unconfirmed", true},
+		{"/v1/wallet/transactions/unconfirmed", true},
+		{"/renter/downloadasync/foo", true},
+		{"/wallet/unconfirmed", false},
+		{"/wallet/transactions/" + types.UnlockHash{}.String(), false},
+	}
+	for _, test := range tests {
+		resp, err := HttpGET(addr + test.call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if test.deprecated {
+			if resp.Header.Get("Deprecation") != "true" || resp.Header.Get("Sunset") != DeprecationSunset || resp.Header.Get("Warning") == "" {
+				t.Errorf("%v was not marked as deprecated: %v", test.call, resp.Header)
+			}
+		} else if resp.Header.Get("Deprecation") != "" || resp.Header.Get("Sunset") != "" {
+			t.Errorf("%v was marked as deprecated: %v", test.call, resp.Header)
+		}
+	}
+}
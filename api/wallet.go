@@ -1,11 +1,16 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
@@ -15,6 +20,16 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
+const (
+	// defaultWalletEventsTimeout is how long a call to /wallet/events waits
+	// for new events if no timeout is provided.
+	defaultWalletEventsTimeout = 30 * time.Second
+
+	// maxWalletEventsTimeout is the longest a call to /wallet/events can wait
+	// for new events.
+	maxWalletEventsTimeout = 5 * time.Minute
+)
+
 type (
 	// WalletGET contains general information about the wallet.
 	WalletGET struct {
@@ -22,6 +37,8 @@ type (
 		Unlocked   bool `json:"unlocked"`
 		Rescanning bool `json:"rescanning"`
 
+		ScanProgress modules.SeedScanProgress `json:"scanprogress"`
+
 		ConfirmedSiacoinBalance     types.Currency `json:"confirmedsiacoinbalance"`
 		UnconfirmedOutgoingSiacoins types.Currency `json:"unconfirmedoutgoingsiacoins"`
 		UnconfirmedIncomingSiacoins types.Currency `json:"unconfirmedincomingsiacoins"`
@@ -39,7 +56,28 @@ type (
 	// WalletAddressesGET contains the list of wallet addresses returned by a
 	// GET call to /wallet/addresses.
 	WalletAddressesGET struct {
-		Addresses []types.UnlockHash `json:"addresses"`
+		Addresses []types.UnlockHash       `json:"addresses"`
+		Balances  []modules.AddressBalance `json:"balances"`
+		Total     uint64                   `json:"total"`
+	}
+
+	// WalletEventsGET contains the events returned by a GET call to
+	// /wallet/events.
+	WalletEventsGET struct {
+		Events []modules.WalletEvent `json:"events"`
+	}
+
+	// WalletDailySnapshot contains the wallet's confirmed balances and the
+	// host's total revenue at the end of a day.
+	WalletDailySnapshot struct {
+		modules.BalanceSnapshot
+		HostRevenue types.Currency `json:"hostrevenue"`
+	}
+
+	// WalletHistoryDailyGET contains the daily snapshots returned by a GET
+	// call to /wallet/history/daily.
+	WalletHistoryDailyGET struct {
+		Days []WalletDailySnapshot `json:"days"`
 	}
 
 	// WalletInitPOST contains the primary seed that gets generated during a
@@ -48,16 +86,63 @@ type (
 		PrimarySeed string `json:"primaryseed"`
 	}
 
+	// WalletPartialPOST contains the partial transaction created, signed or
+	// merged by a POST call to /wallet/partial/create, /wallet/partial/sign
+	// or /wallet/partial/merge, and whether it has enough signatures to be
+	// finalized.
+	WalletPartialPOST struct {
+		Partial  modules.PartialTransaction `json:"partial"`
+		Complete bool                       `json:"complete"`
+	}
+
+	// WalletPartialFinalizePOST contains the signed transaction set created
+	// by a POST call to /wallet/partial/finalize.
+	WalletPartialFinalizePOST struct {
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+		Transactions   []types.Transaction   `json:"transactions"`
+	}
+
+	// WalletReservesGET contains the proof of reserves created by a GET call
+	// to /wallet/reserves.
+	WalletReservesGET struct {
+		Proof modules.ReservesProof `json:"proof"`
+	}
+
+	// WalletSettingsGET contains the settings of the wallet.
+	WalletSettingsGET struct {
+		modules.WalletSettings
+	}
+
 	// WalletSiacoinsPOST contains the transaction sent in the POST call to
-	// /wallet/siacoins.
+	// /wallet/siacoins. A dry run also returns the transactions it built
+	// instead of sending them.
 	WalletSiacoinsPOST struct {
 		TransactionIDs []types.TransactionID `json:"transactionids"`
+		Amount         types.Currency        `json:"amount"`
+		Fee            types.Currency        `json:"fee"`
+		Transactions   []types.Transaction   `json:"transactions,omitempty"`
 	}
 
 	// WalletSiafundsPOST contains the transaction sent in the POST call to
-	// /wallet/siafunds.
+	// /wallet/siafunds. A dry run also returns the transactions it built
+	// instead of sending them.
 	WalletSiafundsPOST struct {
 		TransactionIDs []types.TransactionID `json:"transactionids"`
+		Fee            types.Currency        `json:"fee"`
+		Transactions   []types.Transaction   `json:"transactions,omitempty"`
+	}
+
+	// WalletSiafundsPreparePOST contains the transaction set created by a
+	// POST call to /wallet/siafunds/prepare, whose siafund inputs still have
+	// to be signed.
+	WalletSiafundsPreparePOST struct {
+		Transactions []types.Transaction `json:"transactions"`
+	}
+
+	// WalletSiafundsTrackDirPOST reports the outcome of each siag keyfile
+	// loaded by a POST call to /wallet/siafunds/trackdir.
+	WalletSiafundsTrackDirPOST struct {
+		Keyfiles []modules.SiagKeyfileResult `json:"keyfiles"`
 	}
 
 	// WalletSeedsGET contains the seeds used by the wallet.
@@ -74,17 +159,45 @@ type (
 		Funds types.Currency `json:"funds"`
 	}
 
+	// WalletTransactionReplacePOST contains the transaction set that replaced
+	// an unconfirmed transaction in a POST call to
+	// /wallet/transaction/:id/bumpfee or /wallet/transaction/:id/cancel.
+	WalletTransactionReplacePOST struct {
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+
 	// WalletTransactionGETid contains the transaction returned by a call to
 	// /wallet/transaction/:id
 	WalletTransactionGETid struct {
 		Transaction modules.ProcessedTransaction `json:"transaction"`
+		Label       string                       `json:"label,omitempty"`
+		Memo        string                       `json:"memo,omitempty"`
 	}
 
 	// WalletTransactionsGET contains the specified set of confirmed and
-	// unconfirmed transactions.
+	// unconfirmed transactions, along with the labels attached to them. The
+	// labels are keyed by transaction id.
 	WalletTransactionsGET struct {
 		ConfirmedTransactions   []modules.ProcessedTransaction `json:"confirmedtransactions"`
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
+		Labels                  map[string]string              `json:"labels,omitempty"`
+		Memos                   map[string]string              `json:"memos,omitempty"`
+	}
+
+	// WalletTransactionsUnconfirmedGET contains the unconfirmed transactions
+	// of the wallet, oldest first, along with the labels and memos attached to
+	// them.
+	WalletTransactionsUnconfirmedGET struct {
+		Transactions []WalletPendingTransaction `json:"transactions"`
+		Labels       map[string]string          `json:"labels,omitempty"`
+		Memos        map[string]string          `json:"memos,omitempty"`
+	}
+
+	// WalletPendingTransaction is an unconfirmed transaction of the wallet,
+	// along with the number of seconds it has spent in the transaction pool.
+	WalletPendingTransaction struct {
+		modules.PendingTransaction
+		Age int64 `json:"age"`
 	}
 
 	// WalletTransactionsGETaddr contains the set of wallet transactions
@@ -95,6 +208,26 @@ type (
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
 	}
 
+	// WalletPaymentRequest is a payment request of the wallet, along with its
+	// sia: URI and the form of the URI recommended for QR codes.
+	WalletPaymentRequest struct {
+		modules.PaymentRequest
+		URI       string `json:"uri"`
+		QRPayload string `json:"qrpayload"`
+	}
+
+	// WalletRequestsGET contains the payment requests of the wallet, oldest
+	// first.
+	WalletRequestsGET struct {
+		Requests []WalletPaymentRequest `json:"requests"`
+	}
+
+	// WalletsGET contains the names of the additional wallets that can be
+	// selected using the 'wallet' parameter.
+	WalletsGET struct {
+		Wallets []string `json:"wallets"`
+	}
+
 	// WalletVerifyAddressGET contains a bool indicating if the address passed to
 	// /wallet/verify/address/:addr is a valid address.
 	WalletVerifyAddressGET struct {
@@ -117,6 +250,39 @@ func encryptionKeys(seedStr string) (validKeys []crypto.TwofishKey) {
 	return validKeys
 }
 
+// withWallet wraps a wallet handler so that it operates on the wallet selected
+// by the 'wallet' parameter. If no wallet is selected, the default wallet is
+// used.
+func (api *API) withWallet(h func(*API, http.ResponseWriter, *http.Request, httprouter.Params)) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		name := req.FormValue("wallet")
+		if name == "" {
+			h(api, w, req, ps)
+			return
+		}
+		wallet, exists := api.wallets[name]
+		if !exists {
+			WriteError(w, Error{Message: "no wallet named " + name + " has been loaded"}, http.StatusBadRequest)
+			return
+		}
+		named := *api
+		named.wallet = wallet
+		h(&named, w, req, ps)
+	}
+}
+
+// walletsHandler handles API calls to /wallets.
+func (api *API) walletsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	names := make([]string, 0, len(api.wallets))
+	for name := range api.wallets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	WriteJSON(w, WalletsGET{
+		Wallets: names,
+	})
+}
+
 // walletHander handles API calls to /wallet.
 func (api *API) walletHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	siacoinBal, siafundBal, siaclaimBal := api.wallet.ConfirmedBalance()
@@ -126,6 +292,8 @@ func (api *API) walletHandler(w http.ResponseWriter, req *http.Request, _ httpro
 		Unlocked:   api.wallet.Unlocked(),
 		Rescanning: api.wallet.Rescanning(),
 
+		ScanProgress: api.wallet.ScanProgress(),
+
 		ConfirmedSiacoinBalance:     siacoinBal,
 		UnconfirmedOutgoingSiacoins: siacoinsOut,
 		UnconfirmedIncomingSiacoins: siacoinsIn,
@@ -140,7 +308,7 @@ func (api *API) wallet033xHandler(w http.ResponseWriter, req *http.Request, _ ht
 	source := req.FormValue("source")
 	// Check that source is an absolute paths.
 	if !filepath.IsAbs(source) {
-		WriteError(w, Error{"error when calling /wallet/033x: source must be an absolute path"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error when calling /wallet/033x: source must be an absolute path", Param: "source"}, http.StatusBadRequest)
 		return
 	}
 	potentialKeys := encryptionKeys(req.FormValue("encryptionpassword"))
@@ -151,18 +319,35 @@ func (api *API) wallet033xHandler(w http.ResponseWriter, req *http.Request, _ ht
 			return
 		}
 		if err != nil && err != modules.ErrBadEncryptionKey {
-			WriteError(w, Error{"error when calling /wallet/033x: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/033x: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
-	WriteError(w, Error{modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
 }
 
 // walletAddressHandler handles API calls to /wallet/address.
 func (api *API) walletAddressHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	unlockConditions, err := api.wallet.NextAddress()
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/addresses: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/addresses: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletAddressGET{
+		Address: unlockConditions.UnlockHash(),
+	})
+}
+
+// walletAddressIndexHandler handles API calls to /wallet/address/:index.
+func (api *API) walletAddressIndexHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	index, err := strconv.ParseUint(ps.ByName("index"), 10, 64)
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /wallet/address/:index: unable to parse index: " + err.Error(), Param: "index"}, http.StatusBadRequest)
+		return
+	}
+	unlockConditions, err := api.wallet.DeriveAddress(index)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/address/:index: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteJSON(w, WalletAddressGET{
@@ -172,8 +357,29 @@ func (api *API) walletAddressHandler(w http.ResponseWriter, req *http.Request, _
 
 // walletAddressHandler handles API calls to /wallet/addresses.
 func (api *API) walletAddressesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var addrs []types.UnlockHash
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"address": func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 },
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /wallet/addresses: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// Select the requested page of addresses.
+	addrs = api.wallet.AllAddresses()
+	total := uint64(len(addrs))
+	start, end := lp.Apply(w, addrs)
+	addrs = addrs[start:end]
+	balances, err := api.wallet.AddressBalances(addrs)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/addresses: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
 	WriteJSON(w, WalletAddressesGET{
-		Addresses: api.wallet.AllAddresses(),
+		Addresses: addrs,
+		Balances:  balances,
+		Total:     total,
 	})
 }
 
@@ -182,17 +388,135 @@ func (api *API) walletBackupHandler(w http.ResponseWriter, req *http.Request, _
 	destination := req.FormValue("destination")
 	// Check that the destination is absolute.
 	if !filepath.IsAbs(destination) {
-		WriteError(w, Error{"error when calling /wallet/backup: destination must be an absolute path"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error when calling /wallet/backup: destination must be an absolute path", Param: "destination"}, http.StatusBadRequest)
+		return
+	}
+	// Without a password, the raw wallet database is copied.
+	password := req.FormValue("encryptionpassword")
+	if password == "" {
+		err := api.wallet.CreateBackup(destination)
+		if err != nil {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/backup: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
 		return
 	}
-	err := api.wallet.CreateBackup(destination)
+	for _, key := range encryptionKeys(password) {
+		err := api.wallet.ExportBackup(key, destination)
+		if err == nil {
+			WriteSuccess(w)
+			return
+		}
+		if err != modules.ErrBadEncryptionKey {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/backup: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/backup: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+}
+
+// walletReindexHandler handles API calls to /wallet/reindex.
+func (api *API) walletReindexHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.wallet.Reindex()
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/backup: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/reindex: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
 }
 
+// walletRestoreHandler handles API calls to /wallet/restore.
+func (api *API) walletRestoreHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	source := req.FormValue("source")
+	// Check that the source is absolute.
+	if !filepath.IsAbs(source) {
+		WriteError(w, Error{Message: "error when calling /wallet/restore: source must be an absolute path", Param: "source"}, http.StatusBadRequest)
+		return
+	}
+	for _, key := range encryptionKeys(req.FormValue("encryptionpassword")) {
+		err := api.wallet.RestoreBackup(key, source)
+		if err == nil {
+			WriteSuccess(w)
+			return
+		}
+		if err != modules.ErrBadEncryptionKey {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/restore: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/restore: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+}
+
+// walletEventsHandler handles API calls to /wallet/events. The call blocks
+// until there are events newer than 'since', or the timeout expires.
+func (api *API) walletEventsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var since uint64
+	if s := req.FormValue("since"); s != "" {
+		var err error
+		since, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "unable to parse since: " + err.Error(), Param: "since"}, http.StatusBadRequest)
+			return
+		}
+	}
+	timeout := defaultWalletEventsTimeout
+	if t := req.FormValue("timeout"); t != "" {
+		secs, err := strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "unable to parse timeout: " + err.Error(), Param: "timeout"}, http.StatusBadRequest)
+			return
+		}
+		if secs > uint64(maxWalletEventsTimeout/time.Second) {
+			WriteError(w, Error{Message: "timeout cannot be greater than " + maxWalletEventsTimeout.String(), Param: "timeout"}, http.StatusBadRequest)
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+	}
+
+	// Stop waiting if the client goes away.
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+	events := api.wallet.Events(since, ctx.Done())
+	if events == nil {
+		events = []modules.WalletEvent{}
+	}
+	WriteJSON(w, WalletEventsGET{
+		Events: events,
+	})
+}
+
+// walletHistoryDailyHandler handles API calls to /wallet/history/daily. The
+// host's revenue is carried forward to days on which the host did not record
+// a snapshot.
+func (api *API) walletHistoryDailyHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	balances, err := api.wallet.BalanceHistory()
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/history/daily: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var revenue []modules.HostRevenueSnapshot
+	if api.host != nil {
+		revenue = api.host.RevenueHistory()
+	}
+
+	days := make([]WalletDailySnapshot, len(balances))
+	var hostRevenue types.Currency
+	for i, balance := range balances {
+		for len(revenue) > 0 && revenue[0].Timestamp <= balance.Timestamp {
+			hostRevenue = revenue[0].Revenue
+			revenue = revenue[1:]
+		}
+		days[i] = WalletDailySnapshot{
+			BalanceSnapshot: balance,
+			HostRevenue:     hostRevenue,
+		}
+	}
+	WriteJSON(w, WalletHistoryDailyGET{
+		Days: days,
+	})
+}
+
 // walletInitHandler handles API calls to /wallet/init.
 func (api *API) walletInitHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var encryptionKey crypto.TwofishKey
@@ -203,13 +527,13 @@ func (api *API) walletInitHandler(w http.ResponseWriter, req *http.Request, _ ht
 	if req.FormValue("force") == "true" {
 		err := api.wallet.Reset()
 		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/init: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/init: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
 	seed, err := api.wallet.Encrypt(encryptionKey)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/init: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/init: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -219,7 +543,7 @@ func (api *API) walletInitHandler(w http.ResponseWriter, req *http.Request, _ ht
 	}
 	seedStr, err := modules.SeedToString(seed, dictID)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/init: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/init: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteJSON(w, WalletInitPOST{
@@ -239,21 +563,21 @@ func (api *API) walletInitSeedHandler(w http.ResponseWriter, req *http.Request,
 	}
 	seed, err := modules.StringToSeed(req.FormValue("seed"), dictID)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/init/seed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/init/seed: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	if req.FormValue("force") == "true" {
 		err = api.wallet.Reset()
 		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/init/seed: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/init/seed: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
 
 	err = api.wallet.InitFromSeed(encryptionKey, seed)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/init/seed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/init/seed: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -268,7 +592,7 @@ func (api *API) walletSeedHandler(w http.ResponseWriter, req *http.Request, _ ht
 	}
 	seed, err := modules.StringToSeed(req.FormValue("seed"), dictID)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/seed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/seed: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
@@ -280,11 +604,114 @@ func (api *API) walletSeedHandler(w http.ResponseWriter, req *http.Request, _ ht
 			return
 		}
 		if err != nil && err != modules.ErrBadEncryptionKey {
-			WriteError(w, Error{"error when calling /wallet/seed: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/seed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/seed: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+}
+
+// walletSettingsHandlerGET handles API calls to GET /wallet/settings.
+func (api *API) walletSettingsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	settings, err := api.wallet.Settings()
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/settings: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletSettingsGET{settings})
+}
+
+// walletSettingsHandlerPOST handles API calls to POST /wallet/settings.
+func (api *API) walletSettingsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	settings, err := api.wallet.Settings()
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/settings: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if policy := req.FormValue("changepolicy"); policy != "" {
+		if policy != settings.ChangePolicy {
+			// A fixed change address does not carry over between policies.
+			settings.ChangeAddress = types.UnlockHash{}
+		}
+		settings.ChangePolicy = policy
+	}
+	if addrStr := req.FormValue("changeaddress"); addrStr != "" {
+		addr, err := scanAddress(addrStr)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse changeaddress: " + err.Error(), Param: "changeaddress"}, http.StatusBadRequest)
+			return
+		}
+		settings.ChangeAddress = addr
+	}
+	if gapLimit := req.FormValue("gaplimit"); gapLimit != "" {
+		settings.GapLimit, err = strconv.ParseUint(gapLimit, 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse gaplimit: " + err.Error(), Param: "gaplimit"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if autoClaim := req.FormValue("autoclaimsiafunds"); autoClaim != "" {
+		settings.AutoClaimSiafunds, err = strconv.ParseBool(autoClaim)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse autoclaimsiafunds: " + err.Error(), Param: "autoclaimsiafunds"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if dustThreshold := req.FormValue("dustthreshold"); dustThreshold != "" {
+		threshold, ok := scanAmount(dustThreshold)
+		if !ok {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse dustthreshold", Param: "dustthreshold"}, http.StatusBadRequest)
+			return
+		}
+		settings.DustThreshold = threshold
+	}
+	if rejectDust := req.FormValue("rejectdustoutputs"); rejectDust != "" {
+		settings.RejectDustOutputs, err = strconv.ParseBool(rejectDust)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse rejectdustoutputs: " + err.Error(), Param: "rejectdustoutputs"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if excludeDust := req.FormValue("excludedustinputs"); excludeDust != "" {
+		settings.ExcludeDustInputs, err = strconv.ParseBool(excludeDust)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse excludedustinputs: " + err.Error(), Param: "excludedustinputs"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if confirmedOnly := req.FormValue("confirmedinputsonly"); confirmedOnly != "" {
+		settings.ConfirmedInputsOnly, err = strconv.ParseBool(confirmedOnly)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse confirmedinputsonly: " + err.Error(), Param: "confirmedinputsonly"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if selection := req.FormValue("coinselection"); selection != "" {
+		settings.CoinSelection = selection
+	}
+	if oldestThreshold := req.FormValue("oldestselectionthreshold"); oldestThreshold != "" {
+		threshold, ok := scanAmount(oldestThreshold)
+		if !ok {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse oldestselectionthreshold", Param: "oldestselectionthreshold"}, http.StatusBadRequest)
+			return
+		}
+		settings.OldestSelectionThreshold = threshold
+	}
+	if minConfirmations := req.FormValue("minconfirmations"); minConfirmations != "" {
+		var confs uint64
+		confs, err = strconv.ParseUint(minConfirmations, 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/settings: unable to parse minconfirmations: " + err.Error(), Param: "minconfirmations"}, http.StatusBadRequest)
 			return
 		}
+		settings.MinConfirmations = types.BlockHeight(confs)
 	}
-	WriteError(w, Error{"error when calling /wallet/seed: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+	err = api.wallet.SetSettings(settings)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/settings: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
 }
 
 // walletSiagkeyHandler handles API calls to /wallet/siagkey.
@@ -296,7 +723,7 @@ func (api *API) walletSiagkeyHandler(w http.ResponseWriter, req *http.Request, _
 	for _, keypath := range keyfiles {
 		// Check that all key paths are absolute paths.
 		if !filepath.IsAbs(keypath) {
-			WriteError(w, Error{"error when calling /wallet/siagkey: keyfiles contains a non-absolute path"}, http.StatusBadRequest)
+			WriteError(w, Error{Message: "error when calling /wallet/siagkey: keyfiles contains a non-absolute path"}, http.StatusBadRequest)
 			return
 		}
 	}
@@ -308,18 +735,70 @@ func (api *API) walletSiagkeyHandler(w http.ResponseWriter, req *http.Request, _
 			return
 		}
 		if err != nil && err != modules.ErrBadEncryptionKey {
-			WriteError(w, Error{"error when calling /wallet/siagkey: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siagkey: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/siagkey: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+}
+
+// walletSiafundsWatchSiagAddressHandler handles API calls to
+// /wallet/siafunds/watchsiagaddress.
+func (api *API) walletSiafundsWatchSiagAddressHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// Fetch the list of keyfiles from the post body.
+	keyfiles := strings.Split(req.FormValue("keyfiles"), ",")
+	potentialKeys := encryptionKeys(req.FormValue("encryptionpassword"))
+
+	for _, keypath := range keyfiles {
+		// Check that all key paths are absolute paths.
+		if !filepath.IsAbs(keypath) {
+			WriteError(w, Error{Message: "error when calling /wallet/siafunds/watchsiagaddress: keyfiles contains a non-absolute path"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, key := range potentialKeys {
+		err := api.wallet.WatchSiagAddress(key, keyfiles)
+		if err == nil {
+			WriteSuccess(w)
+			return
+		}
+		if err != modules.ErrBadEncryptionKey {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds/watchsiagaddress: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
-	WriteError(w, Error{"error when calling /wallet/siagkey: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/siafunds/watchsiagaddress: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+}
+
+// walletSiafundsTrackDirHandler handles API calls to
+// /wallet/siafunds/trackdir.
+func (api *API) walletSiafundsTrackDirHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	dir := req.FormValue("dir")
+	if !filepath.IsAbs(dir) {
+		WriteError(w, Error{Message: "error when calling /wallet/siafunds/trackdir: dir must be an absolute path", Param: "dir"}, http.StatusBadRequest)
+		return
+	}
+	potentialKeys := encryptionKeys(req.FormValue("encryptionpassword"))
+	for _, key := range potentialKeys {
+		results, err := api.wallet.LoadSiagKeyDir(key, dir)
+		if err == nil {
+			WriteJSON(w, WalletSiafundsTrackDirPOST{Keyfiles: results})
+			return
+		}
+		if err != modules.ErrBadEncryptionKey {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds/trackdir: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/siafunds/trackdir: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
 }
 
 // walletLockHanlder handles API calls to /wallet/lock.
 func (api *API) walletLockHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	err := api.wallet.Lock()
 	if err != nil {
-		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteSuccess(w)
@@ -335,26 +814,26 @@ func (api *API) walletSeedsHandler(w http.ResponseWriter, req *http.Request, _ h
 	// Get the primary seed information.
 	primarySeed, addrsRemaining, err := api.wallet.PrimarySeed()
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	primarySeedStr, err := modules.SeedToString(primarySeed, dictionary)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	// Get the list of seeds known to the wallet.
 	allSeeds, err := api.wallet.AllSeeds()
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	var allSeedsStrs []string
 	for _, seed := range allSeeds {
 		str, err := modules.SeedToString(seed, dictionary)
 		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/seeds: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 		allSeedsStrs = append(allSeedsStrs, str)
@@ -368,71 +847,368 @@ func (api *API) walletSeedsHandler(w http.ResponseWriter, req *http.Request, _ h
 
 // walletSiacoinsHandler handles API calls to /wallet/siacoins.
 func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// An optional confirmation target replaces the default fee estimate.
+	var target uint64
+	var replaceable bool
+	if t := req.FormValue("confirmtarget"); t != "" {
+		var err error
+		target, err = strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/siacoins: unable to parse confirmtarget: " + err.Error(), Param: "confirmtarget"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if r := req.FormValue("replaceable"); r != "" {
+		var err error
+		replaceable, err = strconv.ParseBool(r)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/siacoins: unable to parse replaceable: " + err.Error(), Param: "replaceable"}, http.StatusBadRequest)
+			return
+		} else if replaceable && target == 0 {
+			WriteError(w, Error{Message: "error when calling /wallet/siacoins: replaceable requires a confirmtarget", Param: "replaceable"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// An optional memo is attached to the transaction.
+	memo := []byte(req.FormValue("data"))
+	if len(memo) > modules.MaxMemoSize {
+		WriteError(w, Error{Message: "error when calling /wallet/siacoins: " + modules.ErrMemoTooLarge.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// A dry run builds the transaction without sending it.
+	dryRun, err := scanBool(req.FormValue("dryrun"))
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /wallet/siacoins: unable to parse dryrun: " + err.Error(), Param: "dryrun"}, http.StatusBadRequest)
+		return
+	}
+	sender := modules.WalletSender(api.wallet)
+	if dryRun {
+		sender = api.wallet.DryRun()
+	}
+
 	var txns []types.Transaction
-	if req.FormValue("outputs") != "" {
+	var sent types.Currency
+	if all := req.FormValue("all"); all != "" {
+		// the entire balance to a single destination
+		sendAll, err := strconv.ParseBool(all)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/siacoins: unable to parse all: " + err.Error(), Param: "all"}, http.StatusBadRequest)
+			return
+		}
+		if !sendAll {
+			WriteError(w, Error{Message: "error when calling /wallet/siacoins: all must be true if it is supplied", Param: "all"}, http.StatusBadRequest)
+			return
+		}
+		if req.FormValue("amount") != "" || req.FormValue("outputs") != "" || target != 0 || len(memo) > 0 {
+			WriteError(w, Error{Message: "error when calling /wallet/siacoins: 'all' cannot be combined with amount, outputs, confirmtarget or data"}, http.StatusBadRequest)
+			return
+		}
+		dest, err := scanAddress(req.FormValue("destination"))
+		if err != nil {
+			WriteError(w, Error{Message: "could not read address from POST call to /wallet/siacoins", Param: "address"}, http.StatusBadRequest)
+			return
+		}
+		txns, sent, err = sender.SendAllSiacoins(dest)
+		if err != nil {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+	} else if req.FormValue("outputs") != "" {
 		// multiple amounts + destinations
 		if req.FormValue("amount") != "" || req.FormValue("destination") != "" {
-			WriteError(w, Error{"cannot supply both 'outputs' and single amount+destination pair"}, http.StatusInternalServerError)
+			WriteError(w, Error{Message: "cannot supply both 'outputs' and single amount+destination pair"}, http.StatusInternalServerError)
 			return
 		}
 
 		var outputs []types.SiacoinOutput
 		err := json.Unmarshal([]byte(req.FormValue("outputs")), &outputs)
 		if err != nil {
-			WriteError(w, Error{"could not decode outputs: " + err.Error()}, http.StatusInternalServerError)
+			WriteError(w, Error{Message: "could not decode outputs: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
-		txns, err = api.wallet.SendSiacoinsMulti(outputs)
+		for _, sco := range outputs {
+			sent = sent.Add(sco.Value)
+		}
+		if target != 0 {
+			txns, err = sender.SendSiacoinsTarget(outputs, types.BlockHeight(target), replaceable, memo)
+		} else if len(memo) > 0 {
+			txns, err = sender.SendSiacoinsMemo(outputs, memo)
+		} else {
+			txns, err = sender.SendSiacoinsMulti(outputs)
+		}
 		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// single amount + destination
 		amount, ok := scanAmount(req.FormValue("amount"))
 		if !ok {
-			WriteError(w, Error{"could not read amount from POST call to /wallet/siacoins"}, http.StatusBadRequest)
+			WriteError(w, Error{Message: "could not read amount from POST call to /wallet/siacoins", Param: "amount"}, http.StatusBadRequest)
 			return
 		}
 		dest, err := scanAddress(req.FormValue("destination"))
 		if err != nil {
-			WriteError(w, Error{"could not read address from POST call to /wallet/siacoins"}, http.StatusBadRequest)
+			WriteError(w, Error{Message: "could not read address from POST call to /wallet/siacoins", Param: "address"}, http.StatusBadRequest)
 			return
 		}
 
-		txns, err = api.wallet.SendSiacoins(amount, dest)
+		output := types.SiacoinOutput{Value: amount, UnlockHash: dest}
+		sent = amount
+		if target != 0 {
+			txns, err = sender.SendSiacoinsTarget([]types.SiacoinOutput{output}, types.BlockHeight(target), replaceable, memo)
+		} else if len(memo) > 0 {
+			txns, err = sender.SendSiacoinsMemo([]types.SiacoinOutput{output}, memo)
+		} else {
+			txns, err = sender.SendSiacoins(amount, dest)
+		}
 		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
 
 	}
 
-	var txids []types.TransactionID
+	resp := WalletSiacoinsPOST{
+		Amount: sent,
+		Fee:    transactionSetFee(txns),
+	}
 	for _, txn := range txns {
-		txids = append(txids, txn.ID())
+		resp.TransactionIDs = append(resp.TransactionIDs, txn.ID())
 	}
-	WriteJSON(w, WalletSiacoinsPOST{
-		TransactionIDs: txids,
-	})
+	if dryRun {
+		resp.Transactions = txns
+	}
+	WriteJSON(w, resp)
+}
+
+// transactionSetFee returns the total miner fee of a transaction set.
+func transactionSetFee(txns []types.Transaction) (fee types.Currency) {
+	for _, txn := range txns {
+		for _, mf := range txn.MinerFees {
+			fee = fee.Add(mf)
+		}
+	}
+	return fee
 }
 
 // walletSiafundsHandler handles API calls to /wallet/siafunds.
 func (api *API) walletSiafundsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// A dry run builds the transaction without sending it.
+	dryRun, err := scanBool(req.FormValue("dryrun"))
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /wallet/siafunds: unable to parse dryrun: " + err.Error(), Param: "dryrun"}, http.StatusBadRequest)
+		return
+	}
+	sender := modules.WalletSender(api.wallet)
+	if dryRun {
+		sender = api.wallet.DryRun()
+	}
+
+	var txns []types.Transaction
+	if req.FormValue("outputs") != "" {
+		// multiple amounts + destinations
+		if req.FormValue("amount") != "" || req.FormValue("destination") != "" {
+			WriteError(w, Error{Message: "error when calling /wallet/siafunds: cannot supply both 'outputs' and single amount+destination pair"}, http.StatusBadRequest)
+			return
+		}
+		var outputs []types.SiafundOutput
+		if err := json.Unmarshal([]byte(req.FormValue("outputs")), &outputs); err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/siafunds: could not decode outputs: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		for i := range outputs {
+			outputs[i].ClaimStart = types.ZeroCurrency
+		}
+		txns, err = sender.SendSiafundsMulti(outputs)
+	} else {
+		// single amount + destination
+		amount, ok := scanAmount(req.FormValue("amount"))
+		if !ok {
+			WriteError(w, Error{Message: "could not read 'amount' from POST call to /wallet/siafunds", Param: "amount"}, http.StatusBadRequest)
+			return
+		}
+		dest, scanErr := scanAddress(req.FormValue("destination"))
+		if scanErr != nil {
+			WriteError(w, Error{Code: moduleErrorCode(scanErr), Message: "error when calling /wallet/siafunds: " + scanErr.Error()}, http.StatusBadRequest)
+			return
+		}
+		txns, err = sender.SendSiafunds(amount, dest)
+	}
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	resp := WalletSiafundsPOST{
+		Fee: transactionSetFee(txns),
+	}
+	for _, txn := range txns {
+		resp.TransactionIDs = append(resp.TransactionIDs, txn.ID())
+	}
+	if dryRun {
+		resp.Transactions = txns
+	}
+	WriteJSON(w, resp)
+}
+
+// walletSiafundsPrepareHandler handles API calls to /wallet/siafunds/prepare.
+func (api *API) walletSiafundsPrepareHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	amount, ok := scanAmount(req.FormValue("amount"))
 	if !ok {
-		WriteError(w, Error{"could not read 'amount' from POST call to /wallet/siafunds"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "could not read 'amount' from POST call to /wallet/siafunds/prepare", Param: "amount"}, http.StatusBadRequest)
 		return
 	}
 	dest, err := scanAddress(req.FormValue("destination"))
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/siafunds: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds/prepare: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	source, err := scanAddress(req.FormValue("source"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds/prepare: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	txns, err := api.wallet.PrepareSiafundTransaction(amount, dest, source)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds/prepare: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletSiafundsPreparePOST{
+		Transactions: txns,
+	})
+}
+
+// walletSiafundsBroadcastHandler handles API calls to
+// /wallet/siafunds/broadcast.
+func (api *API) walletSiafundsBroadcastHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// The transaction set is passed as a form value rather than as the body,
+	// so that the wallet can still be selected with the 'wallet' parameter.
+	var txnSet []types.Transaction
+	err := json.Unmarshal([]byte(req.FormValue("transactions")), &txnSet)
+	if err != nil {
+		WriteError(w, Error{Message: "could not decode transaction set: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txns, err := api.wallet.BroadcastSiafundTransaction(txnSet)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds/broadcast: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletSiafundsPOST{
+		TransactionIDs: txids,
+		Fee:            transactionSetFee(txns),
+	})
+}
+
+// readPartialTransaction decodes the partial transaction in the form value
+// 'partial'.
+func readPartialTransaction(req *http.Request) (modules.PartialTransaction, error) {
+	var pt modules.PartialTransaction
+	if err := json.Unmarshal([]byte(req.FormValue("partial")), &pt); err != nil {
+		return modules.PartialTransaction{}, errors.New("could not decode partial transaction: " + err.Error())
+	}
+	return pt, nil
+}
+
+// walletPartialCreateHandler handles API calls to /wallet/partial/create.
+func (api *API) walletPartialCreateHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var txnSet []types.Transaction
+	err := json.Unmarshal([]byte(req.FormValue("transactions")), &txnSet)
+	if err != nil {
+		WriteError(w, Error{Message: "could not decode transaction set: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pt, err := api.wallet.CreatePartialTransaction(txnSet)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/partial/create: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if desc := req.FormValue("description"); desc != "" {
+		pt.Metadata = map[string]string{"description": desc}
+	}
+	WriteJSON(w, WalletPartialPOST{Partial: pt, Complete: pt.Complete()})
+}
+
+// walletPartialSignHandler handles API calls to /wallet/partial/sign.
+func (api *API) walletPartialSignHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	pt, err := readPartialTransaction(req)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pt, err = api.wallet.SignPartialTransaction(pt)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/partial/sign: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletPartialPOST{Partial: pt, Complete: pt.Complete()})
+}
+
+// walletPartialMergeHandler handles API calls to /wallet/partial/merge.
+func (api *API) walletPartialMergeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var pts []modules.PartialTransaction
+	err := json.Unmarshal([]byte(req.FormValue("partials")), &pts)
+	if err != nil {
+		WriteError(w, Error{Message: "could not decode partial transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pt, err := modules.MergePartialTransactions(pts)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/partial/merge: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletPartialPOST{Partial: pt, Complete: pt.Complete()})
+}
+
+// walletPartialFinalizeHandler handles API calls to /wallet/partial/finalize.
+func (api *API) walletPartialFinalizeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	pt, err := readPartialTransaction(req)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: err.Error()}, http.StatusBadRequest)
 		return
 	}
+	broadcast, err := scanBool(req.FormValue("broadcast"))
+	if err != nil {
+		WriteError(w, Error{Message: "could not read 'broadcast' from POST call to /wallet/partial/finalize", Param: "broadcast"}, http.StatusBadRequest)
+		return
+	}
+	if broadcast && api.tpool == nil {
+		WriteError(w, Error{Message: "cannot broadcast a transaction without a transaction pool"}, http.StatusBadRequest)
+		return
+	}
+	txns, err := api.wallet.FinalizePartialTransaction(pt)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/partial/finalize: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if broadcast {
+		if err := api.tpool.AcceptTransactionSet(txns); err != nil {
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/partial/finalize: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletPartialFinalizePOST{
+		TransactionIDs: txids,
+		Transactions:   txns,
+	})
+}
 
-	txns, err := api.wallet.SendSiafunds(amount, dest)
+// walletSiafundsClaimHandler handles API calls to /wallet/siafunds/claim.
+func (api *API) walletSiafundsClaimHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	txns, err := api.wallet.ClaimSiafunds()
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/siafunds: " + err.Error()}, http.StatusInternalServerError)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/siafunds/claim: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	var txids []types.TransactionID
@@ -441,6 +1217,28 @@ func (api *API) walletSiafundsHandler(w http.ResponseWriter, req *http.Request,
 	}
 	WriteJSON(w, WalletSiafundsPOST{
 		TransactionIDs: txids,
+		Fee:            transactionSetFee(txns),
+	})
+}
+
+// walletReservesHandler handles API calls to /wallet/reserves.
+func (api *API) walletReservesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	amount := types.ZeroCurrency
+	if amountStr := req.FormValue("amount"); amountStr != "" {
+		var ok bool
+		amount, ok = scanAmount(amountStr)
+		if !ok {
+			WriteError(w, Error{Message: "could not read 'amount' from GET call to /wallet/reserves", Param: "amount"}, http.StatusBadRequest)
+			return
+		}
+	}
+	proof, err := api.wallet.ProveReserves(amount, req.FormValue("challenge"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/reserves: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletReservesGET{
+		Proof: proof,
 	})
 }
 
@@ -453,13 +1251,13 @@ func (api *API) walletSweepSeedHandler(w http.ResponseWriter, req *http.Request,
 	}
 	seed, err := modules.StringToSeed(req.FormValue("seed"), dictID)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/sweep/seed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/sweep/seed: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	coins, funds, err := api.wallet.SweepSeed(seed)
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/sweep/seed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/sweep/seed: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	WriteJSON(w, WalletSweepPOST{
@@ -475,71 +1273,353 @@ func (api *API) walletTransactionHandler(w http.ResponseWriter, req *http.Reques
 	jsonID := "\"" + ps.ByName("id") + "\""
 	err := id.UnmarshalJSON([]byte(jsonID))
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/history: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/history: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	txn, ok := api.wallet.Transaction(id)
 	if !ok {
-		WriteError(w, Error{"error when calling /wallet/transaction/:id  :  transaction not found"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error when calling /wallet/transaction/:id  :  transaction not found"}, http.StatusBadRequest)
 		return
 	}
+	memo, _ := modules.TransactionMemo(txn.Transaction)
 	WriteJSON(w, WalletTransactionGETid{
 		Transaction: txn,
+		Label:       api.wallet.TransactionLabels()[id],
+		Memo:        string(memo),
+	})
+}
+
+// walletTransactionLabelHandler handles API calls to
+// /wallet/transaction/:id/label.
+func (api *API) walletTransactionLabelHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// Parse the id from the url.
+	var id types.TransactionID
+	jsonID := "\"" + ps.ByName("id") + "\""
+	err := id.UnmarshalJSON([]byte(jsonID))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/transaction/:id/label: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	err = api.wallet.SetTransactionLabel(id, req.FormValue("label"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/transaction/:id/label: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletPaymentRequest returns the API representation of a payment request.
+func walletPaymentRequest(pr modules.PaymentRequest) WalletPaymentRequest {
+	uri := pr.URI()
+	return WalletPaymentRequest{
+		PaymentRequest: pr,
+		URI:            uri.String(),
+		QRPayload:      uri.QRPayload(),
+	}
+}
+
+// walletRequestsHandlerGET handles API calls to GET /wallet/requests.
+func (api *API) walletRequestsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	prs, err := api.wallet.PaymentRequests()
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/requests: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	wrg := WalletRequestsGET{Requests: make([]WalletPaymentRequest, 0, len(prs))}
+	for _, pr := range prs {
+		wrg.Requests = append(wrg.Requests, walletPaymentRequest(pr))
+	}
+	WriteJSON(w, wrg)
+}
+
+// walletRequestsHandlerPOST handles API calls to POST /wallet/requests, which
+// create a payment request for a fresh address.
+func (api *API) walletRequestsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	amount := types.ZeroCurrency
+	if a := req.FormValue("amount"); a != "" {
+		var ok bool
+		amount, ok = scanAmount(a)
+		if !ok {
+			WriteError(w, Error{Message: "error when calling /wallet/requests: could not read amount", Param: "amount"}, http.StatusBadRequest)
+			return
+		}
+	}
+	var expires uint64
+	if e := req.FormValue("expires"); e != "" {
+		var err error
+		expires, err = strconv.ParseUint(e, 10, 64)
+		if err != nil {
+			WriteError(w, Error{Message: "error when calling /wallet/requests: unable to parse expires: " + err.Error(), Param: "expires"}, http.StatusBadRequest)
+			return
+		}
+	}
+	pr, err := api.wallet.NewPaymentRequest(amount, req.FormValue("label"), req.FormValue("message"), types.Timestamp(expires))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/requests: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, walletPaymentRequest(pr))
+}
+
+// walletRequestHandler handles API calls to /wallet/requests/:addr.
+func (api *API) walletRequestHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	addr, err := scanAddress(ps.ByName("addr"))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/requests/:addr: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pr, err := api.wallet.PaymentRequest(addr)
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/requests/:addr: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, walletPaymentRequest(pr))
+}
+
+// walletTransactionBumpFeeHandler handles API calls to
+// /wallet/transaction/:id/bumpfee.
+func (api *API) walletTransactionBumpFeeHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	api.walletTransactionReplace(w, req, ps, false)
+}
+
+// walletTransactionCancelHandler handles API calls to
+// /wallet/transaction/:id/cancel.
+func (api *API) walletTransactionCancelHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	api.walletTransactionReplace(w, req, ps, true)
+}
+
+// walletTransactionReplace replaces an unconfirmed wallet transaction with one
+// that pays a higher fee. If cancel is true, the replacement returns the funds
+// to the wallet instead of making the original payments.
+func (api *API) walletTransactionReplace(w http.ResponseWriter, req *http.Request, ps httprouter.Params, cancel bool) {
+	call := "/wallet/transaction/:id/bumpfee"
+	if cancel {
+		call = "/wallet/transaction/:id/cancel"
+	}
+
+	// Parse the id from the url.
+	var id types.TransactionID
+	jsonID := "\"" + ps.ByName("id") + "\""
+	err := id.UnmarshalJSON([]byte(jsonID))
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling " + call + ": " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// The fee is optional; a zero fee lets the wallet pick one.
+	fee := types.ZeroCurrency
+	if req.FormValue("fee") != "" {
+		var ok bool
+		fee, ok = scanAmount(req.FormValue("fee"))
+		if !ok {
+			WriteError(w, Error{Message: "could not read 'fee' from POST call to " + call, Param: "fee"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var txns []types.Transaction
+	if cancel {
+		txns, err = api.wallet.CancelTransaction(id, fee)
+	} else {
+		txns, err = api.wallet.BumpTransactionFee(id, fee)
+	}
+	if err != nil {
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling " + call + ": " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletTransactionReplacePOST{
+		TransactionIDs: txids,
+	})
+}
+
+// labelsFor returns the labels attached to the provided transactions, keyed by
+// transaction id.
+func labelsFor(all map[types.TransactionID]string, txnSets ...[]modules.ProcessedTransaction) map[string]string {
+	labels := make(map[string]string)
+	for _, txns := range txnSets {
+		for _, txn := range txns {
+			if label, ok := all[txn.TransactionID]; ok {
+				labels[txn.TransactionID.String()] = label
+			}
+		}
+	}
+	return labels
+}
+
+// memosFor returns the memos attached to the provided transactions, keyed by
+// transaction id.
+func memosFor(txnSets ...[]modules.ProcessedTransaction) map[string]string {
+	memos := make(map[string]string)
+	for _, txns := range txnSets {
+		for _, txn := range txns {
+			if memo, ok := modules.TransactionMemo(txn.Transaction); ok {
+				memos[txn.TransactionID.String()] = string(memo)
+			}
+		}
+	}
+	return memos
+}
+
+// walletTransactionsSearchHandler handles API calls to /wallet/transactions
+// that supply search parameters instead of a range of heights.
+func (api *API) walletTransactionsSearchHandler(w http.ResponseWriter, req *http.Request) {
+	var q modules.TransactionQuery
+	if query := req.FormValue("query"); query != "" {
+		// The query is either an address or a substring of a label.
+		if err := q.Address.LoadString(query); err != nil {
+			q.Address = types.UnlockHash{}
+			q.Label = query
+		}
+	}
+	if minStr := req.FormValue("minamount"); minStr != "" {
+		min, ok := scanAmount(minStr)
+		if !ok {
+			WriteError(w, Error{Message: "could not read 'minamount' from call to /wallet/transactions", Param: "minamount"}, http.StatusBadRequest)
+			return
+		}
+		q.MinAmount = min
+	}
+	if maxStr := req.FormValue("maxamount"); maxStr != "" {
+		max, ok := scanAmount(maxStr)
+		if !ok {
+			WriteError(w, Error{Message: "could not read 'maxamount' from call to /wallet/transactions", Param: "maxamount"}, http.StatusBadRequest)
+			return
+		}
+		q.MaxAmount = max
+	}
+	if !q.MaxAmount.IsZero() && q.MinAmount.Cmp(q.MaxAmount) > 0 {
+		WriteError(w, Error{Message: "'minamount' cannot be greater than 'maxamount'", Param: "minamount"}, http.StatusBadRequest)
+		return
+	}
+
+	confirmedTxns, unconfirmedTxns := api.wallet.SearchTransactions(q)
+	api.writeWalletTransactions(w, req, confirmedTxns, unconfirmedTxns)
+}
+
+// writeWalletTransactions writes the requested page of the confirmed
+// transactions, along with the unconfirmed transactions, in response to a
+// call to /wallet/transactions.
+func (api *API) writeWalletTransactions(w http.ResponseWriter, req *http.Request, confirmedTxns, unconfirmedTxns []modules.ProcessedTransaction) {
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"confirmationheight": func(i, j int) bool {
+			return confirmedTxns[i].ConfirmationHeight < confirmedTxns[j].ConfirmationHeight
+		},
+		"confirmationtimestamp": func(i, j int) bool {
+			return confirmedTxns[i].ConfirmationTimestamp < confirmedTxns[j].ConfirmationTimestamp
+		},
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	start, end := lp.Apply(w, confirmedTxns)
+	confirmedTxns = confirmedTxns[start:end]
+	WriteJSON(w, WalletTransactionsGET{
+		ConfirmedTransactions:   confirmedTxns,
+		UnconfirmedTransactions: unconfirmedTxns,
+		Labels:                  labelsFor(api.wallet.TransactionLabels(), confirmedTxns, unconfirmedTxns),
+		Memos:                   memosFor(confirmedTxns, unconfirmedTxns),
 	})
 }
 
 // walletTransactionsHandler handles API calls to /wallet/transactions.
 func (api *API) walletTransactionsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if req.FormValue("query") != "" || req.FormValue("minamount") != "" || req.FormValue("maxamount") != "" {
+		api.walletTransactionsSearchHandler(w, req)
+		return
+	}
 	startheightStr, endheightStr := req.FormValue("startheight"), req.FormValue("endheight")
 	if startheightStr == "" || endheightStr == "" {
-		WriteError(w, Error{"startheight and endheight must be provided to a /wallet/transactions call."}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "startheight and endheight must be provided to a /wallet/transactions call.", Param: "startheight"}, http.StatusBadRequest)
 		return
 	}
 	// Get the start and end blocks.
 	start, err := strconv.Atoi(startheightStr)
 	if err != nil {
-		WriteError(w, Error{"parsing integer value for parameter `startheight` failed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "parsing integer value for parameter `startheight` failed: " + err.Error(), Param: "startheight"}, http.StatusBadRequest)
 		return
 	}
 	end, err := strconv.Atoi(endheightStr)
 	if err != nil {
-		WriteError(w, Error{"parsing integer value for parameter `endheight` failed: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "parsing integer value for parameter `endheight` failed: " + err.Error(), Param: "endheight"}, http.StatusBadRequest)
 		return
 	}
 	confirmedTxns, err := api.wallet.Transactions(types.BlockHeight(start), types.BlockHeight(end))
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 	unconfirmedTxns := api.wallet.UnconfirmedTransactions()
-
-	WriteJSON(w, WalletTransactionsGET{
-		ConfirmedTransactions:   confirmedTxns,
-		UnconfirmedTransactions: unconfirmedTxns,
-	})
+	api.writeWalletTransactions(w, req, confirmedTxns, unconfirmedTxns)
 }
 
 // walletTransactionsAddrHandler handles API calls to
 // /wallet/transactions/:addr.
 func (api *API) walletTransactionsAddrHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// The unconfirmed transactions used to be served here, as the router
+	// cannot hold a static route next to the :addr wildcard. They moved to
+	// /wallet/unconfirmed.
+	if ps.ByName("addr") == "unconfirmed" {
+		markDeprecated(w, "use /wallet/unconfirmed instead")
+		api.walletUnconfirmedHandler(w, req, ps)
+		return
+	}
+
 	// Parse the address being input.
 	jsonAddr := "\"" + ps.ByName("addr") + "\""
 	var addr types.UnlockHash
 	err := addr.UnmarshalJSON([]byte(jsonAddr))
 	if err != nil {
-		WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var confirmedATs []modules.ProcessedTransaction
+	lp, err := ParseListParams(req, map[string]func(i, j int) bool{
+		"confirmationheight": func(i, j int) bool {
+			return confirmedATs[i].ConfirmationHeight < confirmedATs[j].ConfirmationHeight
+		},
+		"confirmationtimestamp": func(i, j int) bool {
+			return confirmedATs[i].ConfirmationTimestamp < confirmedATs[j].ConfirmationTimestamp
+		},
+	})
+	if err != nil {
+		WriteError(w, Error{Message: "error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
 
-	confirmedATs := api.wallet.AddressTransactions(addr)
+	confirmedATs = api.wallet.AddressTransactions(addr)
 	unconfirmedATs := api.wallet.AddressUnconfirmedTransactions(addr)
+	start, end := lp.Apply(w, confirmedATs)
 	WriteJSON(w, WalletTransactionsGETaddr{
-		ConfirmedTransactions:   confirmedATs,
+		ConfirmedTransactions:   confirmedATs[start:end],
 		UnconfirmedTransactions: unconfirmedATs,
 	})
 }
 
+// walletUnconfirmedHandler handles API calls to /wallet/unconfirmed.
+func (api *API) walletUnconfirmedHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	pending := api.wallet.PendingTransactions()
+	txns := make([]WalletPendingTransaction, 0, len(pending))
+	pts := make([]modules.ProcessedTransaction, 0, len(pending))
+	for _, pt := range pending {
+		txns = append(txns, WalletPendingTransaction{
+			PendingTransaction: pt,
+			Age:                int64(time.Since(pt.FirstSeen).Seconds()),
+		})
+		pts = append(pts, pt.ProcessedTransaction)
+	}
+	WriteJSON(w, WalletTransactionsUnconfirmedGET{
+		Transactions: txns,
+		Labels:       labelsFor(api.wallet.TransactionLabels(), pts),
+		Memos:        memosFor(pts),
+	})
+}
+
 // walletUnlockHandler handles API calls to /wallet/unlock.
 func (api *API) walletUnlockHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	potentialKeys := encryptionKeys(req.FormValue("encryptionpassword"))
@@ -550,11 +1630,11 @@ func (api *API) walletUnlockHandler(w http.ResponseWriter, req *http.Request, _
 			return
 		}
 		if err != nil && err != modules.ErrBadEncryptionKey {
-			WriteError(w, Error{"error when calling /wallet/unlock: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/unlock: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
-	WriteError(w, Error{"error when calling /wallet/unlock: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/unlock: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
 }
 
 // walletChangePasswordHandler handles API calls to /wallet/changepassword
@@ -562,7 +1642,7 @@ func (api *API) walletChangePasswordHandler(w http.ResponseWriter, req *http.Req
 	var newKey crypto.TwofishKey
 	newPassword := req.FormValue("newpassword")
 	if newPassword == "" {
-		WriteError(w, Error{"a password must be provided to newpassword"}, http.StatusBadRequest)
+		WriteError(w, Error{Message: "a password must be provided to newpassword", Param: "newpassword"}, http.StatusBadRequest)
 		return
 	}
 	newKey = crypto.TwofishKey(crypto.HashObject(newPassword))
@@ -575,11 +1655,11 @@ func (api *API) walletChangePasswordHandler(w http.ResponseWriter, req *http.Req
 			return
 		}
 		if err != nil && err != modules.ErrBadEncryptionKey {
-			WriteError(w, Error{"error when calling /wallet/changepassword: " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{Code: moduleErrorCode(err), Message: "error when calling /wallet/changepassword: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
-	WriteError(w, Error{"error when calling /wallet/changepassword: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
+	WriteError(w, Error{Code: ErrCodeWrongPassword, Message: "error when calling /wallet/changepassword: " + modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
 }
 
 // walletVerifyAddressHandler handles API calls to /wallet/verify/address/:addr.
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -538,6 +541,208 @@ func TestIntegrationWalletLoadSeedPOST(t *testing.T) {
 	}
 }
 
+// TestWalletTransactionLabel probes the /wallet/transaction/:id/label call and
+// the search parameters of /wallet/transactions.
+func TestWalletTransactionLabel(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var wtg WalletTransactionsGET
+	err = st.getAPI("/wallet/transactions?startheight=0&endheight=10", &wtg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.ConfirmedTransactions) == 0 {
+		t.Fatal("expecting a few wallet transactions, corresponding to miner payouts.")
+	}
+	txid := wtg.ConfirmedTransactions[0].TransactionID
+
+	// Label the first transaction.
+	labelValues := url.Values{}
+	labelValues.Set("label", "first payout")
+	err = st.stdPostAPI(fmt.Sprintf("/wallet/transaction/%s/label", txid), labelValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Labeling an unknown transaction should fail.
+	err = st.stdPostAPI(fmt.Sprintf("/wallet/transaction/%s/label", types.TransactionID{}), labelValues)
+	if err == nil {
+		t.Fatal("expected an error when labeling an unknown transaction")
+	}
+
+	// The label should be returned alongside the transaction.
+	var wtgid WalletTransactionGETid
+	err = st.getAPI(fmt.Sprintf("/wallet/transaction/%s", txid), &wtgid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wtgid.Label != "first payout" {
+		t.Fatalf("expected label %q, got %q", "first payout", wtgid.Label)
+	}
+
+	// Searching by label should return only the labeled transaction.
+	err = st.getAPI("/wallet/transactions?query=payout", &wtg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wtg.ConfirmedTransactions) != 1 || wtg.ConfirmedTransactions[0].TransactionID != txid {
+		t.Fatal("search returned the wrong transactions")
+	}
+	if wtg.Labels[txid.String()] != "first payout" {
+		t.Fatal("search did not return the transaction's label")
+	}
+
+	// Inverted amount bounds should be rejected.
+	err = st.getAPI("/wallet/transactions?minamount=10&maxamount=5", &wtg)
+	if err == nil {
+		t.Fatal("expected an error for inverted amount bounds")
+	}
+}
+
+// TestWalletUnconfirmed probes the /wallet/unconfirmed api call.
+func TestWalletUnconfirmed(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var wtu WalletTransactionsUnconfirmedGET
+	if err := st.getAPI("/wallet/unconfirmed", &wtu); err != nil {
+		t.Fatal(err)
+	}
+	if len(wtu.Transactions) != 0 {
+		t.Fatal("expected no unconfirmed transactions, got", len(wtu.Transactions))
+	}
+
+	// Send siacoins with a memo, which should be reported as pending.
+	sendSiacoinsValues := url.Values{}
+	sendSiacoinsValues.Set("amount", "1234")
+	sendSiacoinsValues.Set("destination", types.UnlockHash{}.String())
+	sendSiacoinsValues.Set("data", "order 42")
+	if err := st.stdPostAPI("/wallet/siacoins", sendSiacoinsValues); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI("/wallet/unconfirmed", &wtu); err != nil {
+		t.Fatal(err)
+	}
+	if len(wtu.Transactions) == 0 {
+		t.Fatal("expected unconfirmed transactions")
+	}
+	var sent bool
+	for _, txn := range wtu.Transactions {
+		if txn.FirstSeen.IsZero() || txn.Age < 0 {
+			t.Error("wrong age of pending transaction:", txn.FirstSeen, txn.Age)
+		}
+		if len(txn.Inputs) == 0 && len(txn.Outputs) == 0 {
+			t.Error("pending transaction has no inputs or outputs")
+		}
+		for _, sco := range txn.Transaction.SiacoinOutputs {
+			sent = sent || (sco.UnlockHash == types.UnlockHash{} && sco.Value.Equals64(1234))
+		}
+	}
+	if !sent {
+		t.Error("the send is not among the unconfirmed transactions")
+	}
+	if len(wtu.Memos) != 1 {
+		t.Error("expected the memo of the send, got", wtu.Memos)
+	}
+
+	// After a block, nothing is pending.
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI("/wallet/unconfirmed", &wtu); err != nil {
+		t.Fatal(err)
+	}
+	if len(wtu.Transactions) != 0 {
+		t.Error("expected no unconfirmed transactions after a block, got", len(wtu.Transactions))
+	}
+}
+
+// TestWalletRequests probes the /wallet/requests calls, checking that a
+// payment to the address of a request fulfills it.
+func TestWalletRequests(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	amount := types.SiacoinPrecision.Mul64(50)
+	expires := types.CurrentTimestamp() + 3600
+	values := url.Values{}
+	values.Set("amount", amount.String())
+	values.Set("label", "Coffee Shop")
+	values.Set("expires", strconv.FormatUint(uint64(expires), 10))
+	var wpr WalletPaymentRequest
+	if err := st.postAPI("/wallet/requests", values, &wpr); err != nil {
+		t.Fatal(err)
+	}
+	if wpr.Status != modules.PaymentRequestPending || wpr.Label != "Coffee Shop" || wpr.Expires != expires {
+		t.Fatalf("unexpected payment request: %+v", wpr)
+	}
+	for _, uri := range []string{wpr.URI, wpr.QRPayload} {
+		pu, err := types.ParsePaymentURI(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pu.Address != wpr.Address || !pu.Amount.Equals(amount) || pu.Label != "Coffee Shop" || pu.Expires != expires {
+			t.Errorf("%v parsed as %+v", uri, pu)
+		}
+	}
+
+	// Pay the request and mine the payment.
+	sendValues := url.Values{}
+	sendValues.Set("amount", amount.String())
+	sendValues.Set("destination", wpr.Address.String())
+	if err := st.stdPostAPI("/wallet/siacoins", sendValues); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	var fulfilled WalletPaymentRequest
+	if err := st.getAPI("/wallet/requests/"+wpr.Address.String(), &fulfilled); err != nil {
+		t.Fatal(err)
+	}
+	if fulfilled.Status != modules.PaymentRequestFulfilled || !fulfilled.Received.Equals(amount) {
+		t.Fatalf("expected the request to be fulfilled, got %+v", fulfilled)
+	}
+
+	var wrg WalletRequestsGET
+	if err := st.getAPI("/wallet/requests", &wrg); err != nil {
+		t.Fatal(err)
+	}
+	if len(wrg.Requests) != 1 || wrg.Requests[0].Address != wpr.Address {
+		t.Fatalf("unexpected payment requests: %+v", wrg.Requests)
+	}
+
+	// Unknown requests and requests that have already expired are rejected.
+	if err := st.getAPI("/wallet/requests/"+types.UnlockHash{}.String(), &fulfilled); err == nil {
+		t.Error("expected an error for an unknown request")
+	}
+	values.Set("expires", "1")
+	if err := st.stdPostAPI("/wallet/requests", values); err == nil {
+		t.Error("expected an error for an expired request")
+	}
+}
+
 // TestWalletTransactionGETid queries the /wallet/transaction/:id
 // api call.
 func TestWalletTransactionGETid(t *testing.T) {
@@ -838,6 +1043,53 @@ func TestWalletRelativePathErrorBackup(t *testing.T) {
 	}
 }
 
+// TestWalletBackupEncrypted checks that /wallet/backup writes an encrypted
+// backup when given the wallet password, and that /wallet/restore only
+// accepts it with the right password and into an unencrypted wallet.
+func TestWalletBackupEncrypted(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	walletPassword := "testpass"
+	key := crypto.TwofishKey(crypto.HashObject(walletPassword))
+	testdir := build.TempDir("api", t.Name())
+	st, err := assembleServerTester(key, testdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	backupPath := filepath.Join(testdir, "wallet.backup")
+	err = st.stdGetAPI("/wallet/backup?encryptionpassword=wrong&destination=" + backupPath)
+	if err == nil || err.Error() != "error when calling /wallet/backup: "+modules.ErrBadEncryptionKey.Error() {
+		t.Fatal("expected a bad encryption key error, got", err)
+	}
+	err = st.stdGetAPI("/wallet/backup?encryptionpassword=" + walletPassword + "&destination=" + backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The backup cannot be restored with the wrong password, nor into a
+	// wallet that is already encrypted.
+	restoreValues := url.Values{}
+	restoreValues.Set("source", backupPath)
+	restoreValues.Set("encryptionpassword", "wrong")
+	err = st.stdPostAPI("/wallet/restore", restoreValues)
+	if err == nil || err.Error() != "error when calling /wallet/restore: "+modules.ErrBadEncryptionKey.Error() {
+		t.Fatal("expected a bad encryption key error, got", err)
+	}
+	restoreValues.Set("encryptionpassword", walletPassword)
+	if err = st.stdPostAPI("/wallet/restore", restoreValues); err == nil {
+		t.Fatal("restored a backup into an encrypted wallet")
+	}
+	restoreValues.Set("source", "wallet.backup")
+	err = st.stdPostAPI("/wallet/restore", restoreValues)
+	if err == nil || err.Error() != "error when calling /wallet/restore: source must be an absolute path" {
+		t.Fatal("expected a relative path error, got", err)
+	}
+}
+
 // Tests that the /wallet/033x call checks for relative paths.
 func TestWalletRelativePathError033x(t *testing.T) {
 	if testing.Short() {
@@ -893,6 +1145,119 @@ func TestWalletRelativePathError033x(t *testing.T) {
 	}
 }
 
+// TestWalletSiafundsWatchSiagAddress probes the POST call to
+// /wallet/siafunds/watchsiagaddress.
+func TestWalletSiafundsWatchSiagAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Relative paths should be rejected.
+	values := url.Values{}
+	values.Set("keyfiles", "/test.dat,../test.dat")
+	err = st.stdPostAPI("/wallet/siafunds/watchsiagaddress", values)
+	if err == nil || err.Error() != "error when calling /wallet/siafunds/watchsiagaddress: keyfiles contains a non-absolute path" {
+		t.Fatal(err)
+	}
+
+	// A valid keyfile with the wrong password should be rejected.
+	keyfile, err := filepath.Abs("../types/siag0of1of1.siakey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = url.Values{}
+	values.Set("keyfiles", keyfile)
+	values.Set("encryptionpassword", "wrong password")
+	err = st.stdPostAPI("/wallet/siafunds/watchsiagaddress", values)
+	if err == nil || err.Error() != "error when calling /wallet/siafunds/watchsiagaddress: "+modules.ErrBadEncryptionKey.Error() {
+		t.Fatal(err)
+	}
+
+	// The siafunds should not have been loaded.
+	var wg WalletGET
+	err = st.getAPI("/wallet", &wg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wg.SiafundBalance.IsZero() {
+		t.Fatal("siafunds were loaded with the wrong password:", wg.SiafundBalance)
+	}
+}
+
+// TestWalletSiafundsTrackDir probes the /wallet/siafunds/trackdir call.
+func TestWalletSiafundsTrackDir(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	walletPassword := "testpass"
+	key := crypto.TwofishKey(crypto.HashObject(walletPassword))
+	testdir := build.TempDir("api", t.Name())
+	st, err := assembleServerTester(key, testdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Relative paths should be rejected.
+	values := url.Values{}
+	values.Set("dir", "keys")
+	values.Set("encryptionpassword", walletPassword)
+	err = st.stdPostAPI("/wallet/siafunds/trackdir", values)
+	if err == nil || err.Error() != "error when calling /wallet/siafunds/trackdir: dir must be an absolute path" {
+		t.Fatal(err)
+	}
+
+	// Load a directory holding the 1 of 1 key and an unrelated keyfile of
+	// the 2 of 3 key, which cannot be loaded on its own.
+	dir := filepath.Join(testdir, "keys")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"siag0of1of1.siakey", "siag0of2of3.siakey"} {
+		data, err := ioutil.ReadFile(filepath.Join("../types", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	values.Set("dir", dir)
+	values.Set("encryptionpassword", "wrong")
+	err = st.stdPostAPI("/wallet/siafunds/trackdir", values)
+	if err == nil || err.Error() != "error when calling /wallet/siafunds/trackdir: "+modules.ErrBadEncryptionKey.Error() {
+		t.Fatal("expected a bad encryption key error, got", err)
+	}
+	values.Set("encryptionpassword", walletPassword)
+	var wstd WalletSiafundsTrackDirPOST
+	if err := st.postAPI("/wallet/siafunds/trackdir", values, &wstd); err != nil {
+		t.Fatal(err)
+	}
+	if len(wstd.Keyfiles) != 2 {
+		t.Fatal("expected 2 keyfiles, got", len(wstd.Keyfiles))
+	}
+	for _, kf := range wstd.Keyfiles {
+		insufficient := filepath.Base(kf.Keyfile) == "siag0of2of3.siakey"
+		if insufficient != (kf.Error == wallet.ErrInsufficientKeys.Error()) || (!insufficient && kf.Error != "") {
+			t.Error("unexpected keyfile result:", kf)
+		}
+	}
+	var wg WalletGET
+	if err := st.getAPI("/wallet", &wg); err != nil {
+		t.Fatal(err)
+	}
+	if !wg.SiafundBalance.Equals64(2000) {
+		t.Fatal("expected a siafund balance of 2000, got", wg.SiafundBalance)
+	}
+}
+
 // Tests that the /wallet/siagkey call checks for relative paths.
 func TestWalletRelativePathErrorSiag(t *testing.T) {
 	if testing.Short() {
@@ -1183,6 +1548,31 @@ func TestWalletSiafunds(t *testing.T) {
 	if wg.SiacoinClaimBalance.IsZero() {
 		t.Fatal("expected non-zero claim balance")
 	}
+	claimBalance := wg.SiacoinClaimBalance
+
+	// claim the balance and mine a block to confirm the claim
+	var wsp WalletSiafundsPOST
+	err = st.postAPI("/wallet/siafunds/claim", url.Values{}, &wsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wsp.TransactionIDs) == 0 {
+		t.Fatal("expected claim transactions")
+	}
+	_, err = st.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.getAPI("/wallet", &wg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wg.SiacoinClaimBalance.Cmp(claimBalance) >= 0 {
+		t.Fatal("claim balance was not claimed:", wg.SiacoinClaimBalance)
+	}
+	if wg.SiafundBalance.Cmp64(2000) != 0 {
+		t.Fatalf("bad siafund balance after claim: expected %v, got %v", 2000, wg.SiafundBalance)
+	}
 }
 
 // TestWalletVerifyAddress tests that the /wallet/verify/address/:addr endpoint
@@ -1451,3 +1841,947 @@ func TestWalletSiacoins(t *testing.T) {
 		}
 	}
 }
+
+// TestWalletNamed checks that additional named wallets can be selected in
+// wallet calls using the 'wallet' parameter.
+func TestWalletNamed(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Load a second wallet and add it to the API under the name "hot".
+	hot, err := wallet.New(st.cs, st.tpool, filepath.Join(st.dir, modules.NamedWalletsDir, "hot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hot.Close()
+	err = st.server.api.AddWallet("hot", hot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.server.api.AddWallet("hot", hot) == nil {
+		t.Fatal("expected an error when adding a duplicate wallet")
+	}
+
+	var wsg WalletsGET
+	err = st.getAPI("/wallets", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wsg.Wallets) != 1 || wsg.Wallets[0] != "hot" {
+		t.Fatal("unexpected list of wallets:", wsg.Wallets)
+	}
+
+	// The named wallet is independent of the default wallet.
+	var wg WalletGET
+	err = st.getAPI("/wallet?wallet=hot", &wg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wg.Encrypted || wg.Unlocked {
+		t.Fatal("named wallet should not be encrypted or unlocked")
+	}
+	err = st.getAPI("/wallet", &wg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wg.Encrypted || !wg.Unlocked {
+		t.Fatal("default wallet should be encrypted and unlocked")
+	}
+
+	// Initialize and unlock the named wallet through the API.
+	var wip WalletInitPOST
+	err = st.postAPI("/wallet/init?wallet=hot", url.Values{}, &wip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockValues := url.Values{}
+	unlockValues.Set("encryptionpassword", wip.PrimarySeed)
+	err = st.stdPostAPI("/wallet/unlock?wallet=hot", unlockValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hot.Unlocked() {
+		t.Fatal("named wallet was not unlocked")
+	}
+
+	// Addresses should come from the selected wallet.
+	var wag WalletAddressGET
+	err = st.getAPI("/wallet/address?wallet=hot", &wag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wvag WalletVerifyAddressGET
+	err = st.getAPI("/wallet/verify/address/"+wag.Address.String(), &wvag)
+	if err != nil || !wvag.Valid {
+		t.Fatal("address from named wallet should be a valid address", err)
+	}
+
+	// Selecting an unknown wallet should fail.
+	err = st.getAPI("/wallet?wallet=cold", &wg)
+	if err == nil {
+		t.Fatal("expected an error when selecting an unknown wallet")
+	}
+}
+
+// TestWalletSettings probes the GET and POST calls to /wallet/settings.
+func TestWalletSettings(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var wsg WalletSettingsGET
+	err = st.getAPI("/wallet/settings", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsg.ChangePolicy != modules.ChangePolicyFresh {
+		t.Fatal("default change policy should be fresh, got", wsg.ChangePolicy)
+	}
+
+	// Set a fixed change address owned by the wallet.
+	var wag WalletAddressGET
+	err = st.getAPI("/wallet/address", &wag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settingsValues := url.Values{}
+	settingsValues.Set("changepolicy", modules.ChangePolicyFixed)
+	settingsValues.Set("changeaddress", wag.Address.String())
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.getAPI("/wallet/settings", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsg.ChangePolicy != modules.ChangePolicyFixed || wsg.ChangeAddress != wag.Address {
+		t.Fatal("settings were not updated:", wsg)
+	}
+
+	// An unknown policy should be rejected.
+	settingsValues = url.Values{}
+	settingsValues.Set("changepolicy", "foo")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err == nil {
+		t.Fatal("expected an error when setting an unknown change policy")
+	}
+
+	// Changing the gap limit should leave the change policy alone.
+	gapLimit := wsg.GapLimit * 2
+	settingsValues = url.Values{}
+	settingsValues.Set("gaplimit", strconv.FormatUint(gapLimit, 10))
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.getAPI("/wallet/settings", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsg.GapLimit != gapLimit || wsg.ChangePolicy != modules.ChangePolicyFixed {
+		t.Fatal("gap limit was not updated:", wsg)
+	}
+	settingsValues.Set("gaplimit", "-1")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err == nil {
+		t.Fatal("expected an error when setting an invalid gap limit")
+	}
+
+	// Enable automatic siafund claims.
+	settingsValues = url.Values{}
+	settingsValues.Set("autoclaimsiafunds", "true")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.getAPI("/wallet/settings", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wsg.AutoClaimSiafunds || wsg.GapLimit != gapLimit {
+		t.Fatal("automatic siafund claims were not enabled:", wsg)
+	}
+	settingsValues.Set("autoclaimsiafunds", "maybe")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err == nil {
+		t.Fatal("expected an error when setting an invalid autoclaimsiafunds value")
+	}
+
+	// Set a dust threshold and reject dust outputs.
+	settingsValues = url.Values{}
+	settingsValues.Set("dustthreshold", types.SiacoinPrecision.String())
+	settingsValues.Set("rejectdustoutputs", "true")
+	settingsValues.Set("excludedustinputs", "true")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.getAPI("/wallet/settings", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wsg.DustThreshold.Equals(types.SiacoinPrecision) || !wsg.RejectDustOutputs || !wsg.ExcludeDustInputs || !wsg.AutoClaimSiafunds {
+		t.Fatal("dust settings were not updated:", wsg)
+	}
+	sendValues := url.Values{}
+	sendValues.Set("amount", "1000")
+	sendValues.Set("destination", wag.Address.String())
+	err = st.stdPostAPI("/wallet/siacoins", sendValues)
+	if err == nil {
+		t.Fatal("expected an error when sending dust")
+	}
+	settingsValues = url.Values{}
+	settingsValues.Set("dustthreshold", "foo")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err == nil {
+		t.Fatal("expected an error when setting an invalid dust threshold")
+	}
+
+	// Restrict the wallet to confirmed inputs.
+	settingsValues = url.Values{}
+	settingsValues.Set("confirmedinputsonly", "true")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.getAPI("/wallet/settings", &wsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wsg.ConfirmedInputsOnly || !wsg.ExcludeDustInputs {
+		t.Fatal("confirmedinputsonly was not updated:", wsg)
+	}
+	settingsValues.Set("confirmedinputsonly", "maybe")
+	err = st.stdPostAPI("/wallet/settings", settingsValues)
+	if err == nil {
+		t.Fatal("expected an error when setting an invalid confirmedinputsonly value")
+	}
+
+	// No seed scan is running, so no scan progress should be reported.
+	var wg WalletGET
+	err = st.getAPI("/wallet", &wg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wg.ScanProgress.Scanning {
+		t.Fatal("wallet should not report a seed scan")
+	}
+}
+
+// TestWalletEvents probes the long-polling /wallet/events endpoint.
+func TestWalletEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Events and snapshots are only recorded once the consensus set is
+	// synced. Mine a block that pays the wallet after that.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if !st.cs.Synced() {
+			return errors.New("consensus set is not synced")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	var weg WalletEventsGET
+	err = st.getAPI("/wallet/events?timeout=0", &weg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(weg.Events) == 0 {
+		t.Fatal("expected events for the mined block")
+	}
+	since := weg.Events[len(weg.Events)-1].ID
+
+	// Nothing has happened since the last event.
+	err = st.getAPI("/wallet/events?timeout=0&since="+strconv.FormatUint(since, 10), &weg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(weg.Events) != 0 {
+		t.Fatal("expected no new events, got", weg.Events)
+	}
+
+	// Wait for the next block in the background.
+	errChan := make(chan error)
+	go func() {
+		errChan <- st.getAPI("/wallet/events?timeout=10&since="+strconv.FormatUint(since, 10), &weg)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	block, err := st.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+	if len(weg.Events) != 1 || weg.Events[0].Type != modules.WalletEventConfirmation ||
+		weg.Events[0].TransactionID != types.TransactionID(block.ID()) {
+		t.Fatal("expected a confirmation of the miner payout, got", weg.Events)
+	}
+
+	// Bad parameters should be rejected.
+	if err := st.getAPI("/wallet/events?since=-1", &weg); err == nil {
+		t.Fatal("expected an error for a negative since")
+	}
+	if err := st.getAPI("/wallet/events?timeout=100000", &weg); err == nil {
+		t.Fatal("expected an error for a timeout that is too long")
+	}
+}
+
+// TestWalletHistoryDaily probes the /wallet/history/daily endpoint.
+func TestWalletHistoryDaily(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Events and snapshots are only recorded once the consensus set is
+	// synced. Mine a block that pays the wallet after that.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if !st.cs.Synced() {
+			return errors.New("consensus set is not synced")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	var whdg WalletHistoryDailyGET
+	err = st.getAPI("/wallet/history/daily", &whdg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(whdg.Days) == 0 {
+		t.Fatal("expected a snapshot for today")
+	}
+	today := whdg.Days[len(whdg.Days)-1]
+	if today.Timestamp != types.CurrentTimestamp()-types.CurrentTimestamp()%modules.SnapshotPeriod {
+		t.Fatal("last snapshot is not from today:", today.Timestamp)
+	}
+	if today.Height != st.cs.Height() || today.ConfirmedSiacoinBalance.IsZero() {
+		t.Fatal("wrong snapshot for today:", today)
+	}
+	if !today.HostRevenue.IsZero() {
+		t.Fatal("host should not have earned any revenue, got", today.HostRevenue)
+	}
+}
+
+// TestWalletAddressesBalances probes the balances and pagination of
+// /wallet/addresses.
+func TestWalletAddressesBalances(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Send some money to a new address.
+	var wag WalletAddressGET
+	err = st.getAPI("/wallet/address", &wag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := types.SiacoinPrecision.Mul64(100)
+	sendValues := url.Values{}
+	sendValues.Set("amount", value.String())
+	sendValues.Set("destination", wag.Address.String())
+	err = st.stdPostAPI("/wallet/siacoins", sendValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	var all WalletAddressesGET
+	err = st.getAPI("/wallet/addresses", &all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Total < 2 || uint64(len(all.Addresses)) != all.Total || len(all.Balances) != len(all.Addresses) {
+		t.Fatalf("expected %v addresses and balances, got %v and %v", all.Total, len(all.Addresses), len(all.Balances))
+	}
+	found := false
+	for i, b := range all.Balances {
+		if b.Address != all.Addresses[i] {
+			t.Fatal("balances are not in the order of the addresses")
+		}
+		if b.Address == wag.Address {
+			found = true
+			if !b.SiacoinBalance.Equals(value) || !b.SiacoinsReceived.Equals(value) {
+				t.Fatal("wrong balance for the new address:", b)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("new address was not listed")
+	}
+
+	// Fetch a single page.
+	var page WalletAddressesGET
+	err = st.getAPI("/wallet/addresses?offset=1&limit=1", &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != all.Total || len(page.Addresses) != 1 || page.Addresses[0] != all.Addresses[1] || page.Balances[0].Address != all.Addresses[1] {
+		t.Fatal("wrong page of addresses:", page)
+	}
+	err = st.getAPI(fmt.Sprintf("/wallet/addresses?offset=%v", all.Total), &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Addresses) != 0 || len(page.Balances) != 0 {
+		t.Fatal("expected an empty page past the last address:", page)
+	}
+	err = st.getAPI("/wallet/addresses?limit=-1", &page)
+	if err == nil {
+		t.Fatal("expected an error for an invalid limit")
+	}
+
+	// Sort the addresses in descending order.
+	err = st.getAPI("/wallet/addresses?start=0&limit=1&sort=-address", &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Addresses) != 1 || page.Addresses[0] != all.Addresses[len(all.Addresses)-1] {
+		t.Fatal("wrong page of sorted addresses:", page)
+	}
+	err = st.getAPI("/wallet/addresses?sort=balance", &page)
+	if err == nil {
+		t.Fatal("expected an error for an unknown sort field")
+	}
+}
+
+// TestWalletAddressIndex checks that /wallet/address/:index derives the same
+// address every time it is called.
+func TestWalletAddressIndex(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var wag1, wag2 WalletAddressGET
+	if err := st.getAPI("/wallet/address/3", &wag1); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI("/wallet/address/3", &wag2); err != nil {
+		t.Fatal(err)
+	}
+	if wag1.Address != wag2.Address {
+		t.Fatal("deriving the same index returned different addresses")
+	}
+	var addrs WalletAddressesGET
+	if err := st.getAPI("/wallet/addresses", &addrs); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, addr := range addrs.Addresses {
+		found = found || addr == wag1.Address
+	}
+	if !found {
+		t.Fatal("derived address is not listed by the wallet")
+	}
+
+	if err := st.getAPI("/wallet/address/foo", &wag1); err == nil {
+		t.Fatal("expected an error for an invalid index")
+	}
+	if err := st.getAPI("/wallet/address/1000000000", &wag1); err == nil {
+		t.Fatal("expected an error for an index past the gap limit")
+	}
+}
+
+// TestWalletTransactionReplace checks that an unconfirmed send can be bumped
+// and cancelled through the API.
+func TestWalletTransactionReplace(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	sendSiacoinsValues := url.Values{}
+	sendSiacoinsValues.Set("amount", types.SiacoinPrecision.String())
+	sendSiacoinsValues.Set("destination", types.UnlockHash{1}.String())
+	var wsp WalletSiacoinsPOST
+	if err := st.postAPI("/wallet/siacoins", sendSiacoinsValues, &wsp); err != nil {
+		t.Fatal(err)
+	}
+	sendID := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+
+	// A fee that is not higher than the original fee is rejected.
+	feeValues := url.Values{}
+	feeValues.Set("fee", "1")
+	if err := st.stdPostAPI("/wallet/transaction/"+sendID.String()+"/bumpfee", feeValues); err == nil {
+		t.Fatal("replaced a transaction without raising the fee")
+	}
+	feeValues.Set("fee", "foo")
+	if err := st.stdPostAPI("/wallet/transaction/"+sendID.String()+"/bumpfee", feeValues); err == nil || err.Error() != "could not read 'fee' from POST call to /wallet/transaction/:id/bumpfee" {
+		t.Fatal("expected a fee parsing error, got", err)
+	}
+
+	var bump WalletTransactionReplacePOST
+	if err := st.postAPI("/wallet/transaction/"+sendID.String()+"/bumpfee", nil, &bump); err != nil {
+		t.Fatal(err)
+	}
+	bumpID := bump.TransactionIDs[len(bump.TransactionIDs)-1]
+	var cancel WalletTransactionReplacePOST
+	if err := st.postAPI("/wallet/transaction/"+bumpID.String()+"/cancel", nil, &cancel); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.stdPostAPI("/wallet/transaction/"+bumpID.String()+"/cancel", nil); err == nil {
+		t.Fatal("cancelled a transaction that was already replaced")
+	}
+
+	// The wallet should only list the cancellation as unconfirmed.
+	var wtg WalletTransactionsGET
+	if err := st.getAPI("/wallet/transactions?startheight=0&endheight=10000", &wtg); err != nil {
+		t.Fatal(err)
+	}
+	for _, pt := range wtg.UnconfirmedTransactions {
+		if pt.TransactionID == sendID || pt.TransactionID == bumpID {
+			t.Fatal("replaced transaction is still listed as unconfirmed")
+		}
+	}
+}
+
+// TestWalletSiacoinsConfirmTarget checks the confirmtarget and replaceable
+// parameters of /wallet/siacoins.
+func TestWalletSiacoinsConfirmTarget(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	sendSiacoinsValues := url.Values{}
+	sendSiacoinsValues.Set("amount", types.SiacoinPrecision.String())
+	sendSiacoinsValues.Set("destination", types.UnlockHash{1}.String())
+	sendSiacoinsValues.Set("replaceable", "true")
+	if err := st.stdPostAPI("/wallet/siacoins", sendSiacoinsValues); err == nil || err.Error() != "error when calling /wallet/siacoins: replaceable requires a confirmtarget" {
+		t.Fatal("expected a missing confirmtarget error, got", err)
+	}
+	sendSiacoinsValues.Set("confirmtarget", "-1")
+	if err := st.stdPostAPI("/wallet/siacoins", sendSiacoinsValues); err == nil {
+		t.Fatal("accepted a negative confirmtarget")
+	}
+
+	// A send targeting the next block pays at least the fee of a send
+	// targeting a later block.
+	var fees [2]types.Currency
+	for i, target := range []string{"1", "10"} {
+		sendSiacoinsValues.Set("confirmtarget", target)
+		var wsp WalletSiacoinsPOST
+		if err := st.postAPI("/wallet/siacoins", sendSiacoinsValues, &wsp); err != nil {
+			t.Fatal(err)
+		}
+		var wtg WalletTransactionsGET
+		if err := st.getAPI("/wallet/transactions?startheight=0&endheight=10000", &wtg); err != nil {
+			t.Fatal(err)
+		}
+		for _, pt := range wtg.UnconfirmedTransactions {
+			if pt.TransactionID == wsp.TransactionIDs[len(wsp.TransactionIDs)-1] {
+				for _, fee := range pt.Transaction.MinerFees {
+					fees[i] = fees[i].Add(fee)
+				}
+			}
+		}
+	}
+	if fees[0].Cmp(fees[1]) < 0 || fees[1].IsZero() {
+		t.Fatal("unexpected fees for the confirmation targets:", fees)
+	}
+}
+
+// TestWalletReindex probes the POST call to /wallet/reindex.
+func TestWalletReindex(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	var before WalletGET
+	if err := st.getAPI("/wallet", &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.stdPostAPI("/wallet/reindex", nil); err != nil {
+		t.Fatal(err)
+	}
+	var after WalletGET
+	if err := st.getAPI("/wallet", &after); err != nil {
+		t.Fatal(err)
+	}
+	if !after.ConfirmedSiacoinBalance.Equals(before.ConfirmedSiacoinBalance) {
+		t.Fatalf("balance changed after reindex: %v vs %v", after.ConfirmedSiacoinBalance, before.ConfirmedSiacoinBalance)
+	}
+
+	// A locked wallet cannot be reindexed.
+	if err := st.stdPostAPI("/wallet/lock", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.stdPostAPI("/wallet/reindex", nil); err == nil || err.Error() != "error when calling /wallet/reindex: "+modules.ErrLockedWallet.Error() {
+		t.Fatal("expected a locked wallet error, got", err)
+	}
+}
+
+// TestWalletSiacoinsMemo probes the 'data' parameter of /wallet/siacoins and
+// the memos reported by the transaction history.
+func TestWalletSiacoinsMemo(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	sendValues := url.Values{}
+	sendValues.Set("amount", types.SiacoinPrecision.String())
+	sendValues.Set("destination", types.UnlockHash{1}.String())
+	sendValues.Set("data", strings.Repeat("x", modules.MaxMemoSize+1))
+	if err := st.stdPostAPI("/wallet/siacoins", sendValues); err == nil {
+		t.Fatal("expected an error when sending a memo that is too large")
+	}
+	sendValues.Set("data", "invoice #7")
+	var wsp WalletSiacoinsPOST
+	if err := st.postAPI("/wallet/siacoins", sendValues, &wsp); err != nil {
+		t.Fatal(err)
+	}
+	sendID := wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+
+	var wtg WalletTransactionsGET
+	if err := st.getAPI("/wallet/transactions?startheight=0&endheight=10000", &wtg); err != nil {
+		t.Fatal(err)
+	}
+	if memo := wtg.Memos[sendID.String()]; memo != "invoice #7" {
+		t.Fatalf("unconfirmed send has the wrong memo: %q", memo)
+	}
+
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	var wtgid WalletTransactionGETid
+	if err := st.getAPI("/wallet/transaction/"+sendID.String(), &wtgid); err != nil {
+		t.Fatal(err)
+	}
+	if wtgid.Memo != "invoice #7" {
+		t.Fatalf("confirmed send has the wrong memo: %q", wtgid.Memo)
+	}
+}
+
+// TestWalletSiafundsOffline probes the /wallet/siafunds/prepare and
+// /wallet/siafunds/broadcast calls, signing the prepared transaction with a
+// siag keyfile that is never loaded into the wallet.
+func TestWalletSiafundsOffline(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// Signing a transaction with an empty siafund input reveals the address
+	// of the keyfile.
+	keyfiles := []string{"../types/siag0of1of1.siakey"}
+	probe := types.Transaction{SiafundInputs: []types.SiafundInput{{}}}
+	if err := wallet.SignSiagTransaction(&probe, keyfiles); err != nil {
+		t.Fatal(err)
+	}
+	source := probe.SiafundInputs[0].UnlockConditions.UnlockHash()
+
+	values := url.Values{}
+	values.Set("amount", "10")
+	values.Set("destination", types.UnlockHash{1}.String())
+	values.Set("source", source.String())
+	var wspp WalletSiafundsPreparePOST
+	if err := st.postAPI("/wallet/siafunds/prepare", values, &wspp); err != nil {
+		t.Fatal(err)
+	}
+	if len(wspp.Transactions) == 0 {
+		t.Fatal("no transactions were prepared")
+	}
+	if err := wallet.SignSiagTransaction(&wspp.Transactions[len(wspp.Transactions)-1], keyfiles); err != nil {
+		t.Fatal(err)
+	}
+	txns, err := json.Marshal(wspp.Transactions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = url.Values{}
+	values.Set("transactions", string(txns))
+	var wsp WalletSiafundsPOST
+	if err := st.postAPI("/wallet/siafunds/broadcast", values, &wsp); err != nil {
+		t.Fatal(err)
+	}
+	if len(wsp.TransactionIDs) != len(wspp.Transactions) {
+		t.Fatal("wrong number of transactions broadcast:", len(wsp.TransactionIDs))
+	}
+	var trg TpoolRawGET
+	if err := st.getAPI("/tpool/raw/"+wsp.TransactionIDs[len(wsp.TransactionIDs)-1].String(), &trg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestWalletSiacoinsSendAll checks that the 'all' flag of /wallet/siacoins
+// sends the entire balance of the wallet.
+func TestWalletSiacoinsSendAll(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	// 'all' cannot be combined with an amount.
+	sendValues := url.Values{}
+	sendValues.Set("all", "true")
+	sendValues.Set("amount", "1")
+	sendValues.Set("destination", types.UnlockHash{1}.String())
+	if err := st.stdPostAPI("/wallet/siacoins", sendValues); err == nil {
+		t.Fatal("expected an error when combining 'all' with an amount")
+	}
+	sendValues.Del("amount")
+
+	var wg WalletGET
+	if err := st.getAPI("/wallet", &wg); err != nil {
+		t.Fatal(err)
+	}
+	var wsp WalletSiacoinsPOST
+	if err := st.postAPI("/wallet/siacoins", sendValues, &wsp); err != nil {
+		t.Fatal(err)
+	}
+	if wsp.Amount.IsZero() || wsp.Amount.Cmp(wg.ConfirmedSiacoinBalance) >= 0 {
+		t.Fatalf("sent %v of a balance of %v", wsp.Amount, wg.ConfirmedSiacoinBalance)
+	}
+	if err := st.postAPI("/wallet/siacoins", sendValues, &wsp); err == nil {
+		t.Fatal("sent siacoins from an empty wallet")
+	}
+}
+
+// TestWalletSiafundsMulti checks that /wallet/siafunds can send siafunds to
+// several addresses in one transaction, returning the rest to the wallet.
+func TestWalletSiafundsMulti(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	walletPassword := "testpass"
+	key := crypto.TwofishKey(crypto.HashObject(walletPassword))
+	testdir := build.TempDir("api", t.Name())
+	st, err := assembleServerTester(key, testdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+	for i := types.BlockHeight(0); i <= types.MaturityDelay; i++ {
+		if _, err := st.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	siagPath, _ := filepath.Abs("../types/siag0of1of1.siakey")
+	loadSiagValues := url.Values{}
+	loadSiagValues.Set("keyfiles", siagPath)
+	loadSiagValues.Set("encryptionpassword", walletPassword)
+	if err := st.stdPostAPI("/wallet/siagkey", loadSiagValues); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := []types.SiafundOutput{
+		{Value: types.NewCurrency64(300), UnlockHash: types.UnlockHash{1}},
+		{Value: types.NewCurrency64(200), UnlockHash: types.UnlockHash{2}},
+	}
+	outputsJSON, _ := json.Marshal(outputs)
+	sendValues := url.Values{}
+	sendValues.Set("outputs", string(outputsJSON))
+	sendValues.Set("amount", "500")
+	if err := st.stdPostAPI("/wallet/siafunds", sendValues); err == nil {
+		t.Fatal("expected an error when supplying both outputs and amount")
+	}
+	sendValues.Del("amount")
+	var wsp WalletSiafundsPOST
+	if err := st.postAPI("/wallet/siafunds", sendValues, &wsp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction should pay both outputs, and the rest of the siafunds
+	// should stay in the wallet.
+	var wtgid WalletTransactionGETid
+	if err := st.getAPI("/wallet/transaction/"+wsp.TransactionIDs[len(wsp.TransactionIDs)-1].String(), &wtgid); err != nil {
+		t.Fatal(err)
+	}
+	paid := make(map[types.UnlockHash]types.Currency)
+	for _, sfo := range wtgid.Transaction.Transaction.SiafundOutputs {
+		paid[sfo.UnlockHash] = paid[sfo.UnlockHash].Add(sfo.Value)
+	}
+	for _, sfo := range outputs {
+		if !paid[sfo.UnlockHash].Equals(sfo.Value) {
+			t.Errorf("expected %v siafunds to be sent to %v, got %v", sfo.Value, sfo.UnlockHash, paid[sfo.UnlockHash])
+		}
+	}
+	var wg WalletGET
+	if err := st.getAPI("/wallet", &wg); err != nil {
+		t.Fatal(err)
+	}
+	if wg.SiafundBalance.Cmp64(1500) != 0 {
+		t.Fatalf("bad siafund balance: expected %v, got %v", 1500, wg.SiafundBalance)
+	}
+}
+
+// TestWalletPartial checks that a siafund transaction signed offline can be
+// completed through the partial transaction calls.
+func TestWalletPartial(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	keyfiles := []string{"../types/siag0of1of1.siakey"}
+	probe := types.Transaction{SiafundInputs: []types.SiafundInput{{}}}
+	if err := wallet.SignSiagTransaction(&probe, keyfiles); err != nil {
+		t.Fatal(err)
+	}
+	values := url.Values{}
+	values.Set("amount", "10")
+	values.Set("destination", types.UnlockHash{1}.String())
+	values.Set("source", probe.SiafundInputs[0].UnlockConditions.UnlockHash().String())
+	var wspp WalletSiafundsPreparePOST
+	if err := st.postAPI("/wallet/siafunds/prepare", values, &wspp); err != nil {
+		t.Fatal(err)
+	}
+	if err := wallet.SignSiagTransaction(&wspp.Transactions[len(wspp.Transactions)-1], keyfiles); err != nil {
+		t.Fatal(err)
+	}
+
+	// The siag signatures are moved into the partial transaction, and the
+	// wallet adds its own.
+	txns, err := json.Marshal(wspp.Transactions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = url.Values{}
+	values.Set("transactions", string(txns))
+	values.Set("description", "offline siafunds")
+	var created WalletPartialPOST
+	if err := st.postAPI("/wallet/partial/create", values, &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Complete || created.Partial.Metadata["description"] != "offline siafunds" {
+		t.Fatal("unexpected partial transaction:", created)
+	}
+	partial, err := json.Marshal(created.Partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = url.Values{}
+	values.Set("partial", string(partial))
+	var signed WalletPartialPOST
+	if err := st.postAPI("/wallet/partial/sign", values, &signed); err != nil {
+		t.Fatal(err)
+	}
+	if !signed.Complete {
+		t.Fatal("partial transaction was not completed by the wallet")
+	}
+
+	partials, err := json.Marshal([]modules.PartialTransaction{created.Partial, signed.Partial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = url.Values{}
+	values.Set("partials", string(partials))
+	var merged WalletPartialPOST
+	if err := st.postAPI("/wallet/partial/merge", values, &merged); err != nil {
+		t.Fatal(err)
+	}
+	if !merged.Complete {
+		t.Fatal("signatures were lost in the merge")
+	}
+
+	partial, err = json.Marshal(merged.Partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = url.Values{}
+	values.Set("partial", string(partial))
+	values.Set("broadcast", "true")
+	var wpfp WalletPartialFinalizePOST
+	if err := st.postAPI("/wallet/partial/finalize", values, &wpfp); err != nil {
+		t.Fatal(err)
+	}
+	var trg TpoolRawGET
+	if err := st.getAPI("/tpool/raw/"+wpfp.TransactionIDs[len(wpfp.TransactionIDs)-1].String(), &trg); err != nil {
+		t.Fatal(err)
+	}
+}
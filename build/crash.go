@@ -0,0 +1,163 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A CrashReport records a panic or a critical error, along with the module in
+// which it occurred and the stack trace of the failing goroutine. Crash
+// reports are written to the crash directory as JSON files, so that they
+// survive the crash and can be inspected after the daemon restarts.
+type CrashReport struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	Module    string    `json:"module"`
+	Message   string    `json:"message"`
+	Stack     string    `json:"stack"`
+	Version   string    `json:"version"`
+	GoVersion string    `json:"goversion"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+
+	// Uploaded is set once the report has been sent to the crash report
+	// server.
+	Uploaded bool `json:"uploaded"`
+}
+
+// The kinds of crash report.
+const (
+	CrashKindPanic    = "panic"
+	CrashKindCritical = "critical"
+)
+
+// CrashReportExtension is the file extension of crash reports.
+const CrashReportExtension = ".crash.json"
+
+var (
+	crashDir   string
+	crashDirMu sync.Mutex
+)
+
+// SetCrashDir sets the directory that crash reports are written to. Crash
+// reports are not recorded until a directory is set.
+func SetCrashDir(dir string) {
+	crashDirMu.Lock()
+	crashDir = dir
+	crashDirMu.Unlock()
+}
+
+// CaptureCrash records a crash report if the calling goroutine is panicking,
+// and then resumes the panic. It must be deferred directly, typically at the
+// top of a long-running goroutine:
+//
+//	defer build.CaptureCrash("host")
+func CaptureCrash(module string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	recordCrash(CrashKindPanic, module, fmt.Sprint(r), debug.Stack())
+	panic(r)
+}
+
+// recordCrash writes a crash report to the crash directory. Errors are
+// printed rather than returned, as the program is already failing.
+func recordCrash(kind, module, message string, stack []byte) {
+	crashDirMu.Lock()
+	defer crashDirMu.Unlock()
+	if crashDir == "" {
+		return
+	}
+
+	now := time.Now()
+	cr := CrashReport{
+		ID:        fmt.Sprintf("%d-%s", now.UnixNano(), kind),
+		Time:      now,
+		Kind:      kind,
+		Module:    module,
+		Message:   message,
+		Stack:     string(stack),
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if err := WriteCrashReport(crashDir, cr); err != nil {
+		os.Stderr.WriteString("Unable to write crash report: " + err.Error() + "\n")
+	}
+}
+
+// WriteCrashReport writes a crash report to dir, replacing any previous
+// version of the report.
+func WriteCrashReport(dir string, cr CrashReport) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cr, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, cr.ID+CrashReportExtension), b, 0600)
+}
+
+// ReadCrashReports returns the crash reports in dir, oldest first. A missing
+// directory contains no crash reports.
+func ReadCrashReports(dir string) ([]CrashReport, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var reports []CrashReport
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), CrashReportExtension) {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var cr CrashReport
+		if err := json.Unmarshal(b, &cr); err != nil {
+			// A crash report may have been cut short by the crash it
+			// describes.
+			continue
+		}
+		reports = append(reports, cr)
+	}
+	return reports, nil
+}
+
+// callerModule returns the name of the Sia module that the calling goroutine
+// is running in, by finding the first frame of the stack that belongs to a
+// package under modules/.
+func callerModule() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		const prefix = "github.com/NebulousLabs/Sia/modules/"
+		if i := strings.Index(frame.Function, prefix); i >= 0 {
+			name := frame.Function[i+len(prefix):]
+			if j := strings.IndexAny(name, "/."); j >= 0 {
+				name = name[:j]
+			}
+			return name
+		}
+		if !more {
+			return ""
+		}
+	}
+}
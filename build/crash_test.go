@@ -0,0 +1,78 @@
+package build
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestCaptureCrash checks that CaptureCrash records a crash report for a
+// panicking goroutine and resumes the panic.
+func TestCaptureCrash(t *testing.T) {
+	dir := TempDir("build", t.Name())
+	os.RemoveAll(dir)
+	SetCrashDir(dir)
+	defer SetCrashDir("")
+
+	func() {
+		defer func() {
+			if r := recover(); r != "crash test" {
+				t.Fatal("panic was not resumed:", r)
+			}
+		}()
+		defer CaptureCrash("host")
+		panic("crash test")
+	}()
+
+	reports, err := ReadCrashReports(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatal("expected 1 crash report, got", len(reports))
+	}
+	cr := reports[0]
+	if cr.Kind != CrashKindPanic || cr.Module != "host" || cr.Message != "crash test" || cr.Version != Version {
+		t.Fatalf("unexpected crash report: %+v", cr)
+	}
+	if !strings.Contains(cr.Stack, "TestCaptureCrash") {
+		t.Error("crash report does not contain the stack of the panic:", cr.Stack)
+	}
+
+	// Critical should record a crash report as well.
+	func() {
+		defer func() { recover() }()
+		Critical("critical crash test")
+	}()
+	reports, err = ReadCrashReports(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports[1].Kind != CrashKindCritical || reports[1].Message != "critical crash test" {
+		t.Fatalf("unexpected crash reports: %+v", reports)
+	}
+
+	// Without a crash directory, nothing should be recorded.
+	SetCrashDir("")
+	func() {
+		defer func() { recover() }()
+		defer CaptureCrash("host")
+		panic("crash test")
+	}()
+	reports, err = ReadCrashReports(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 {
+		t.Fatal("crash report was recorded without a crash directory")
+	}
+}
+
+// TestReadCrashReportsMissingDir checks that a missing crash directory
+// contains no reports.
+func TestReadCrashReportsMissingDir(t *testing.T) {
+	reports, err := ReadCrashReports(TempDir("build", t.Name(), "missing"))
+	if err != nil || len(reports) != 0 {
+		t.Fatal("expected no reports, got", reports, err)
+	}
+}
@@ -4,18 +4,21 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
+	"strings"
 )
 
 // Critical should be called if a sanity check has failed, indicating developer
 // error. Critical is called with an extended message guiding the user to the
 // issue tracker on Github. If the program does not panic, the call stack for
-// the running goroutine is printed to help determine the error.
+// the running goroutine is printed to help determine the error, and a crash
+// report is recorded if a crash directory has been set.
 func Critical(v ...interface{}) {
 	s := "Critical error: " + fmt.Sprintln(v...) + "Please submit a bug report here: https://github.com/NebulousLabs/Sia/issues\n"
 	if Release != "testing" {
 		debug.PrintStack()
 		os.Stderr.WriteString(s)
 	}
+	recordCrash(CrashKindCritical, callerModule(), strings.TrimSpace(fmt.Sprintln(v...)), debug.Stack())
 	if DEBUG {
 		panic(s)
 	}
@@ -2,6 +2,7 @@ package build
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -29,13 +30,14 @@ func ComposeErrors(errs ...error) error {
 }
 
 // ExtendErr will return a new error which extends the input error with a
-// string. If the input error is nil, then 'nil' will be returned, discarding
-// the input string.
+// string. The input error is wrapped, so it can still be found with errors.Is.
+// If the input error is nil, then 'nil' will be returned, discarding the
+// input string.
 func ExtendErr(s string, err error) error {
 	if err == nil {
 		return nil
 	}
-	return errors.New(s + ": " + err.Error())
+	return fmt.Errorf("%s: %w", s, err)
 }
 
 // JoinErrors concatenates the elements of errs to create a single error. The
@@ -0,0 +1,43 @@
+package encoding
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fuzzObject exercises every kind of value that the decoder supports.
+type fuzzObject struct {
+	T0 test0
+	T1 test1
+	T4 test4
+	T5 test5
+	L  nestedList
+	Ss [][]string
+}
+
+// FuzzUnmarshal checks that decoding arbitrary input never panics or
+// exceeds the decoding limits, and that the encoding is canonical: an object
+// that decodes successfully re-encodes to the exact bytes it was decoded from.
+func FuzzUnmarshal(f *testing.F) {
+	for _, enc := range testEncodings {
+		f.Add(enc)
+	}
+	f.Add(Marshal(fuzzObject{
+		T0: testStructs[0].(test0),
+		T1: testStructs[1].(test1),
+		T4: testStructs[4].(test4),
+		T5: test5{"foo"},
+		L:  nestedList{&nestedList{}},
+		Ss: [][]string{{"foo", "bar"}, nil},
+	}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		buf := bytes.NewBuffer(data)
+		var obj fuzzObject
+		if err := NewDecoder(buf).Decode(&obj); err != nil {
+			return
+		}
+		if consumed := data[:len(data)-buf.Len()]; !bytes.Equal(Marshal(obj), consumed) {
+			t.Fatalf("decoded object re-encodes to different bytes:\n%x\n%x", Marshal(obj), consumed)
+		}
+	})
+}
@@ -15,12 +15,23 @@ import (
 const (
 	MaxObjectSize = 12e6 // 12 MB
 	MaxSliceSize  = 5e6  // 5 MB
+
+	// MaxSliceLen is the maximum number of elements in a decoded slice.
+	// Every element other than a zero-size one takes at least a byte to
+	// encode, so no valid object has more.
+	MaxSliceLen = MaxObjectSize
+
+	// MaxNesting is the maximum depth of pointers, slices, arrays and structs
+	// that the decoder will descend into. It guards against self-referential
+	// types, such as linked lists, exhausting the stack of the decoder.
+	MaxNesting = 64
 )
 
 var (
 	errBadPointer     = errors.New("cannot decode into invalid pointer")
 	ErrObjectTooLarge = errors.New("encoded object exceeds size limit")
 	ErrSliceTooLarge  = errors.New("encoded slice is too large")
+	ErrNestingTooDeep = errors.New("encoded object is nested too deeply")
 )
 
 type (
@@ -84,6 +95,7 @@ func (e *Encoder) encode(val reflect.Value) error {
 		if !val.IsNil() {
 			return e.encode(val.Elem())
 		}
+		return nil
 	case reflect.Bool:
 		if val.Bool() {
 			return e.write([]byte{1})
@@ -177,8 +189,9 @@ func WriteFile(filename string, v interface{}) error {
 
 // A Decoder reads and decodes values from an input stream.
 type Decoder struct {
-	r io.Reader
-	n int
+	r     io.Reader
+	n     int
+	depth int
 }
 
 // Read implements the io.Reader interface. It also keeps track of the total
@@ -211,8 +224,9 @@ func (d *Decoder) Decode(v interface{}) (err error) {
 		}
 	}()
 
-	// reset the read count
+	// reset the read count and nesting depth
 	d.n = 0
+	d.depth = 0
 
 	d.decode(pval.Elem())
 	return
@@ -252,6 +266,11 @@ func (d *Decoder) readN(n int) []byte {
 // val. The decoding rules are the inverse of those specified in the package
 // docstring.
 func (d *Decoder) decode(val reflect.Value) {
+	if d.depth++; d.depth > MaxNesting {
+		panic(ErrNestingTooDeep)
+	}
+	defer func() { d.depth-- }()
+
 	// check for UnmarshalSia interface first
 	if val.CanAddr() && val.Addr().CanInterface() {
 		if u, ok := val.Addr().Interface().(SiaUnmarshaler); ok {
@@ -283,9 +302,17 @@ func (d *Decoder) decode(val reflect.Value) {
 		}
 		val.SetBool(b[0] == 1)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		val.SetInt(DecInt64(d.readN(8)))
+		i := DecInt64(d.readN(8))
+		if val.OverflowInt(i) {
+			panic("integer value overflows " + val.Type().String())
+		}
+		val.SetInt(i)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		val.SetUint(DecUint64(d.readN(8)))
+		u := DecUint64(d.readN(8))
+		if val.OverflowUint(u) {
+			panic("integer value overflows " + val.Type().String())
+		}
+		val.SetUint(u)
 	case reflect.String:
 		strLen := DecUint64(d.readN(8))
 		if strLen > MaxSliceSize {
@@ -298,7 +325,12 @@ func (d *Decoder) decode(val reflect.Value) {
 		sliceLen := DecUint64(d.readN(8))
 		// sanity-check the sliceLen, otherwise you can crash a peer by making
 		// them allocate a massive slice
-		if sliceLen > 1<<31-1 || sliceLen*uint64(val.Type().Elem().Size()) > MaxSliceSize {
+		if sliceLen > MaxSliceLen || sliceLen*uint64(val.Type().Elem().Size()) > MaxSliceSize {
+			panic(ErrSliceTooLarge)
+		} else if buf, ok := d.r.(*bytes.Buffer); ok && val.Type().Elem().Size() > 0 && sliceLen > uint64(buf.Len()) {
+			// each element takes at least a byte to encode, so a slice with
+			// more elements than the remaining bytes cannot be decoded;
+			// reject it before allocating it.
 			panic(ErrSliceTooLarge)
 		} else if sliceLen == 0 {
 			return
@@ -333,7 +365,7 @@ func (d *Decoder) decode(val reflect.Value) {
 
 // NewDecoder returns a new decoder that reads from r.
 func NewDecoder(r io.Reader) *Decoder {
-	return &Decoder{r, 0}
+	return &Decoder{r: r}
 }
 
 // Unmarshal decodes the encoded value b and stores it in v, which must be a
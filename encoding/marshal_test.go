@@ -134,6 +134,12 @@ func TestDecode(t *testing.T) {
 		t.Error("expected bool error, got", err)
 	}
 
+	// integer that overflows its type
+	err = Unmarshal(EncUint64(1<<16), new(uint16))
+	if err == nil || err.Error() != "could not decode type uint16: integer value overflows uint16" {
+		t.Error("expected overflow error, got", err)
+	}
+
 	// non-pointer
 	err = Unmarshal([]byte{1, 2, 3}, "foo")
 	if err != errBadPointer {
@@ -181,6 +187,44 @@ func TestDecode(t *testing.T) {
 
 }
 
+// nestedList is a self-referential type, used to test the nesting limit.
+type nestedList struct {
+	Next *nestedList
+}
+
+// TestDecodeLimits checks that the decoder rejects inputs that exceed the
+// nesting and slice length limits, before allocating memory for them.
+func TestDecodeLimits(t *testing.T) {
+	// a list nested within the limit decodes, but a deeper one is rejected.
+	// Each level of the list is a pointer and a struct.
+	encList := func(depth int) []byte {
+		return append(bytes.Repeat([]byte{1}, depth), 0)
+	}
+	if err := Unmarshal(encList(MaxNesting/4), new(nestedList)); err != nil {
+		t.Error("expected list within the nesting limit to decode, got", err)
+	}
+	err := Unmarshal(encList(MaxNesting), new(nestedList))
+	if err == nil || !strings.Contains(err.Error(), ErrNestingTooDeep.Error()) {
+		t.Error("expected nesting error, got", err)
+	}
+	// the depth is reset between objects
+	dec := NewDecoder(bytes.NewBuffer(append(encList(MaxNesting/4), encList(MaxNesting/4)...)))
+	if err := dec.DecodeAll(new(nestedList), new(nestedList)); err != nil {
+		t.Error("expected consecutive lists to decode, got", err)
+	}
+
+	// a slice with more elements than the remaining bytes
+	err = Unmarshal(append(EncUint64(1000), make([]byte, 999)...), new([]bool))
+	if err == nil || !strings.Contains(err.Error(), ErrSliceTooLarge.Error()) {
+		t.Error("expected large slice error, got", err)
+	}
+	// a slice of zero-size elements longer than MaxSliceLen
+	err = Unmarshal(EncUint64(MaxSliceLen+1), new([]struct{}))
+	if err == nil || !strings.Contains(err.Error(), ErrSliceTooLarge.Error()) {
+		t.Error("expected large slice error, got", err)
+	}
+}
+
 // TestMarshalUnmarshal tests the Marshal and Unmarshal functions, which are
 // inverses of each other.
 func TestMarshalUnmarshal(t *testing.T) {
@@ -0,0 +1,337 @@
+package modules
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	siasync "github.com/NebulousLabs/Sia/sync"
+)
+
+// The bandwidth of siad can be limited at runtime. Global limits on upload and
+// download are partitioned among the traffic of the gateway, the host and the
+// renter: each module is guaranteed a share of the global limit in proportion
+// to its priority, and may use any bandwidth that the other modules leave
+// unused. Each module can also be given limits of its own. The limits are
+// enforced by wrapping the connections of each module with LimitConn, and are
+// shared by all of the modules in the process.
+
+const (
+	// bandwidthChunkSize is the largest number of bytes that a limited
+	// connection writes at once, so that large writes are spread out evenly.
+	bandwidthChunkSize = 1 << 14
+)
+
+// The kinds of traffic that bandwidth limits are applied to.
+const (
+	BandwidthGateway BandwidthClass = iota
+	BandwidthHost
+	BandwidthRenter
+
+	numBandwidthClasses
+)
+
+var (
+	// DefaultBandwidthLimits are the bandwidth limits of siad on startup. No
+	// limits are set, and the traffic of the gateway, which keeps the node in
+	// consensus, has the highest priority.
+	DefaultBandwidthLimits = BandwidthLimits{
+		Gateway: BandwidthModuleLimits{Priority: 3},
+		Host:    BandwidthModuleLimits{Priority: 2},
+		Renter:  BandwidthModuleLimits{Priority: 1},
+	}
+
+	// ErrBandwidthPriority is returned when a module is given a bandwidth
+	// priority of zero.
+	ErrBandwidthPriority = errors.New("bandwidth priority must be at least 1")
+
+	// bandwidth is the bandwidth limiter shared by the modules.
+	bandwidth = newBandwidthLimiter(DefaultBandwidthLimits)
+)
+
+type (
+	// BandwidthClass identifies the module that traffic belongs to.
+	BandwidthClass int
+
+	// BandwidthLimits are the bandwidth limits of siad, in bytes per second.
+	// A limit of zero means that the bandwidth is unlimited. The global limits
+	// are shared among the modules in proportion to their priority.
+	BandwidthLimits struct {
+		MaxUpload   uint64 `json:"maxupload"`
+		MaxDownload uint64 `json:"maxdownload"`
+
+		Gateway BandwidthModuleLimits `json:"gateway"`
+		Host    BandwidthModuleLimits `json:"host"`
+		Renter  BandwidthModuleLimits `json:"renter"`
+	}
+
+	// BandwidthModuleLimits are the bandwidth limits of a single module, in
+	// bytes per second, and the priority of its traffic relative to the other
+	// modules.
+	BandwidthModuleLimits struct {
+		MaxUpload   uint64 `json:"maxupload"`
+		MaxDownload uint64 `json:"maxdownload"`
+		Priority    uint64 `json:"priority"`
+	}
+
+	// BandwidthUsage is the number of bytes that each module has transferred
+	// over limited connections since siad was started.
+	BandwidthUsage struct {
+		Gateway BandwidthModuleUsage `json:"gateway"`
+		Host    BandwidthModuleUsage `json:"host"`
+		Renter  BandwidthModuleUsage `json:"renter"`
+	}
+
+	// BandwidthModuleUsage is the number of bytes that a single module has
+	// transferred.
+	BandwidthModuleUsage struct {
+		Uploaded   uint64 `json:"uploaded"`
+		Downloaded uint64 `json:"downloaded"`
+	}
+
+	// bandwidthDirection holds the token buckets that limit traffic in one
+	// direction.
+	bandwidthDirection struct {
+		global *siasync.TokenBucket
+		shares [numBandwidthClasses]*siasync.TokenBucket
+		limits [numBandwidthClasses]*siasync.TokenBucket
+		usage  [numBandwidthClasses]uint64 // atomic
+	}
+
+	// bandwidthLimiter enforces a set of bandwidth limits.
+	bandwidthLimiter struct {
+		upload   bandwidthDirection
+		download bandwidthDirection
+
+		limits BandwidthLimits
+		mu     sync.Mutex
+	}
+
+	// limitedConn is a net.Conn whose traffic is limited by a
+	// bandwidthLimiter.
+	limitedConn struct {
+		net.Conn
+		class   BandwidthClass
+		limiter *bandwidthLimiter
+
+		closeOnce sync.Once
+		closed    chan struct{}
+	}
+)
+
+// String returns the name of the module that the traffic belongs to.
+func (bc BandwidthClass) String() string {
+	switch bc {
+	case BandwidthGateway:
+		return "gateway"
+	case BandwidthHost:
+		return "host"
+	case BandwidthRenter:
+		return "renter"
+	}
+	return "unknown"
+}
+
+// module returns the limits of the module that traffic of class bc belongs
+// to.
+func (bl *BandwidthLimits) module(bc BandwidthClass) *BandwidthModuleLimits {
+	switch bc {
+	case BandwidthGateway:
+		return &bl.Gateway
+	case BandwidthHost:
+		return &bl.Host
+	default:
+		return &bl.Renter
+	}
+}
+
+// module returns the usage of the module that traffic of class bc belongs to.
+func (bu *BandwidthUsage) module(bc BandwidthClass) *BandwidthModuleUsage {
+	switch bc {
+	case BandwidthGateway:
+		return &bu.Gateway
+	case BandwidthHost:
+		return &bu.Host
+	default:
+		return &bu.Renter
+	}
+}
+
+// newBandwidthDirection returns a bandwidthDirection without limits.
+func newBandwidthDirection() bandwidthDirection {
+	bd := bandwidthDirection{global: siasync.NewTokenBucket(0, 0)}
+	for i := range bd.shares {
+		bd.shares[i] = siasync.NewTokenBucket(0, 0)
+		bd.limits[i] = siasync.NewTokenBucket(0, 0)
+	}
+	return bd
+}
+
+// setLimits sets the rates of the token buckets of bd, given the global limit,
+// the limits of each module, and their priorities. The burst size of each
+// bucket is one second of traffic.
+func (bd *bandwidthDirection) setLimits(global uint64, limits, priorities [numBandwidthClasses]uint64) {
+	var total uint64
+	for _, p := range priorities {
+		total += p
+	}
+	bd.global.SetRate(global, global)
+	for i := range bd.shares {
+		share := uint64(float64(global) * float64(priorities[i]) / float64(total))
+		if global != 0 && share == 0 {
+			share = 1
+		}
+		bd.shares[i].SetRate(share, share)
+		bd.limits[i].SetRate(limits[i], limits[i])
+	}
+}
+
+// reserve accounts for n bytes of traffic of class bc, and returns how long
+// the traffic has to be delayed. Traffic that exceeds the share of its module
+// is only delayed if the global limit is also exceeded, so that modules can
+// use the bandwidth that other modules leave unused.
+func (bd *bandwidthDirection) reserve(bc BandwidthClass, n uint64) time.Duration {
+	atomic.AddUint64(&bd.usage[bc], n)
+	delay := bd.global.Reserve(n)
+	if share := bd.shares[bc].Reserve(n); share < delay {
+		delay = share
+	}
+	if limit := bd.limits[bc].Reserve(n); limit > delay {
+		delay = limit
+	}
+	return delay
+}
+
+// newBandwidthLimiter returns a bandwidthLimiter that enforces 'limits'.
+func newBandwidthLimiter(limits BandwidthLimits) *bandwidthLimiter {
+	bl := &bandwidthLimiter{
+		upload:   newBandwidthDirection(),
+		download: newBandwidthDirection(),
+	}
+	if err := bl.setLimits(limits); err != nil {
+		panic(err)
+	}
+	return bl
+}
+
+// setLimits changes the limits enforced by bl.
+func (bl *bandwidthLimiter) setLimits(limits BandwidthLimits) error {
+	var uploads, downloads, priorities [numBandwidthClasses]uint64
+	for bc := BandwidthClass(0); bc < numBandwidthClasses; bc++ {
+		ml := limits.module(bc)
+		if ml.Priority == 0 {
+			return ErrBandwidthPriority
+		}
+		uploads[bc], downloads[bc], priorities[bc] = ml.MaxUpload, ml.MaxDownload, ml.Priority
+	}
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	bl.limits = limits
+	bl.upload.setLimits(limits.MaxUpload, uploads, priorities)
+	bl.download.setLimits(limits.MaxDownload, downloads, priorities)
+	return nil
+}
+
+// currentLimits returns the limits enforced by bl.
+func (bl *bandwidthLimiter) currentLimits() BandwidthLimits {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	return bl.limits
+}
+
+// usage returns the number of bytes transferred by each module.
+func (bl *bandwidthLimiter) usage() BandwidthUsage {
+	var bu BandwidthUsage
+	for bc := BandwidthClass(0); bc < numBandwidthClasses; bc++ {
+		mu := bu.module(bc)
+		mu.Uploaded = atomic.LoadUint64(&bl.upload.usage[bc])
+		mu.Downloaded = atomic.LoadUint64(&bl.download.usage[bc])
+	}
+	return bu
+}
+
+// limitConn returns a net.Conn whose traffic is limited by bl.
+func (bl *bandwidthLimiter) limitConn(conn net.Conn, bc BandwidthClass) net.Conn {
+	return &limitedConn{
+		Conn:    conn,
+		class:   bc,
+		limiter: bl,
+		closed:  make(chan struct{}),
+	}
+}
+
+// wait blocks for duration d, or until the connection is closed.
+func (lc *limitedConn) wait(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-lc.closed:
+	}
+}
+
+// Read implements io.Reader. The data is read before it is accounted for, so
+// the delay is applied after the read.
+func (lc *limitedConn) Read(b []byte) (int, error) {
+	n, err := lc.Conn.Read(b)
+	if n > 0 {
+		lc.wait(lc.limiter.download.reserve(lc.class, uint64(n)))
+	}
+	return n, err
+}
+
+// Write implements io.Writer. Large writes are split into chunks, each of
+// which is delayed before it is written.
+func (lc *limitedConn) Write(b []byte) (int, error) {
+	var written int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > bandwidthChunkSize {
+			chunk = chunk[:bandwidthChunkSize]
+		}
+		lc.wait(lc.limiter.upload.reserve(lc.class, uint64(len(chunk))))
+		n, err := lc.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// Close implements net.Conn, waking any reads and writes that are waiting for
+// bandwidth.
+func (lc *limitedConn) Close() error {
+	lc.closeOnce.Do(func() { close(lc.closed) })
+	return lc.Conn.Close()
+}
+
+// CurrentBandwidthLimits returns the bandwidth limits of siad.
+func CurrentBandwidthLimits() BandwidthLimits {
+	return bandwidth.currentLimits()
+}
+
+// CurrentBandwidthUsage returns the number of bytes that each module has
+// transferred since siad was started.
+func CurrentBandwidthUsage() BandwidthUsage {
+	return bandwidth.usage()
+}
+
+// LimitConn returns a net.Conn whose traffic is counted as traffic of class
+// bc, and is limited by the bandwidth limits of siad.
+func LimitConn(conn net.Conn, bc BandwidthClass) net.Conn {
+	return bandwidth.limitConn(conn, bc)
+}
+
+// SetBandwidthLimits changes the bandwidth limits of siad. The new limits
+// apply to existing connections immediately.
+func SetBandwidthLimits(limits BandwidthLimits) error {
+	return bandwidth.setLimits(limits)
+}
@@ -0,0 +1,110 @@
+package modules
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// approx reports whether d is within 100ms of want.
+func approx(d, want time.Duration) bool {
+	return d > want-100*time.Millisecond && d < want+100*time.Millisecond
+}
+
+// TestBandwidthPriorities checks that the global limit is shared among the
+// modules by priority, that a module can use bandwidth that the other modules
+// leave unused, and that the limits of a module are enforced on top of the
+// global limits.
+func TestBandwidthPriorities(t *testing.T) {
+	limits := DefaultBandwidthLimits
+	limits.MaxUpload = 6000
+	bl := newBandwidthLimiter(limits)
+
+	// The renter is the only module using bandwidth, so it can use all of
+	// the global limit rather than only its share of 1000 bytes per second.
+	if d := bl.upload.reserve(BandwidthRenter, 6000); d != 0 {
+		t.Fatal("burst should not be delayed, got", d)
+	}
+	if d := bl.upload.reserve(BandwidthRenter, 6000); !approx(d, time.Second) {
+		t.Fatal("renter should be limited by the global limit, got", d)
+	}
+	// The gateway's share of 3000 bytes per second is still available, so
+	// its traffic is not delayed by the renter's, but the renter's traffic is
+	// delayed by the gateway's.
+	if d := bl.upload.reserve(BandwidthGateway, 3000); d != 0 {
+		t.Fatal("gateway should be able to use its share, got", d)
+	}
+	if d := bl.upload.reserve(BandwidthRenter, 1000); !approx(d, 10000*time.Second/6000) {
+		t.Fatal("renter should wait for the gateway's traffic, got", d)
+	}
+
+	// Downloads are not limited.
+	if d := bl.download.reserve(BandwidthHost, 1<<30); d != 0 {
+		t.Fatal("downloads should not be limited, got", d)
+	}
+	limits.Host.MaxDownload = 1000
+	if err := bl.setLimits(limits); err != nil {
+		t.Fatal(err)
+	}
+	bl.download.reserve(BandwidthHost, 1000)
+	if d := bl.download.reserve(BandwidthHost, 500); !approx(d, 500*time.Millisecond) {
+		t.Fatal("host should be limited by its own limit, got", d)
+	}
+
+	usage := bl.usage()
+	if usage.Renter.Uploaded != 13000 || usage.Gateway.Uploaded != 3000 || usage.Host.Downloaded != 1<<30+1500 {
+		t.Fatal("unexpected usage:", usage)
+	}
+	if bl.currentLimits() != limits {
+		t.Fatal("limits were not updated")
+	}
+
+	limits.Renter.Priority = 0
+	if err := bl.setLimits(limits); err != ErrBandwidthPriority {
+		t.Fatal("expected ErrBandwidthPriority, got", err)
+	}
+}
+
+// TestLimitedConn checks that writes to a limited connection are delayed, and
+// that closing the connection wakes a write that is waiting for bandwidth.
+func TestLimitedConn(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	limits := DefaultBandwidthLimits
+	limits.Renter.MaxUpload = 1 << 20
+	bl := newBandwidthLimiter(limits)
+
+	c1, c2 := net.Pipe()
+	conn := bl.limitConn(c1, BandwidthRenter)
+	go io.Copy(ioutil.Discard, c2)
+
+	// The first second of traffic is the burst of the bucket, so writing
+	// two seconds of traffic takes about one second.
+	start := time.Now()
+	if _, err := conn.Write(make([]byte, 2<<20)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond || elapsed > 2*time.Second {
+		t.Fatal("expected the write to take about a second, took", elapsed)
+	}
+
+	// Start a write that would take ten seconds, and close the connection.
+	errChan := make(chan error)
+	go func() {
+		_, err := conn.Write(make([]byte, 10<<20))
+		errChan <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	conn.Close()
+	select {
+	case err := <-errChan:
+		if err == nil {
+			t.Fatal("write to a closed connection should fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("closing the connection did not wake the write")
+	}
+}
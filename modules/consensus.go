@@ -20,6 +20,28 @@ const (
 	DiffRevert DiffDirection = false
 )
 
+const (
+	// DeploymentDefined indicates that signaling for a deployment has not
+	// started yet.
+	DeploymentDefined = "defined"
+
+	// DeploymentStarted indicates that blocks are signaling for a deployment,
+	// but the threshold has not been reached in any complete window.
+	DeploymentStarted = "started"
+
+	// DeploymentLockedIn indicates that the signaling threshold was reached in
+	// the previous window, and that the deployment will activate at the start
+	// of the next window.
+	DeploymentLockedIn = "lockedin"
+
+	// DeploymentActive indicates that the rule change is in effect.
+	DeploymentActive = "active"
+
+	// DeploymentFailed indicates that the deployment timed out before it
+	// locked in.
+	DeploymentFailed = "failed"
+)
+
 var (
 	// ConsensusChangeBeginning is a special consensus change id that tells the
 	// consensus set to provide all consensus changes starting from the very
@@ -50,6 +72,10 @@ var (
 	// in a fork that is the heaviest known fork - the consensus set has not
 	// changed as a result of seeing the block.
 	ErrNonExtendingBlock = errors.New("block does not extend the longest fork")
+
+	// ErrNotSynced is returned, usually wrapped, by calls that cannot be
+	// made until the consensus set is synced.
+	ErrNotSynced = errors.New("blockchain is not synced")
 )
 
 type (
@@ -175,6 +201,31 @@ type (
 		Adjusted  types.Currency
 	}
 
+	// A DeploymentStatus reports the signaling progress and activation state of
+	// a deployment as of the current block.
+	DeploymentStatus struct {
+		Name          string            `json:"name"`
+		Bit           uint              `json:"bit"`
+		StartHeight   types.BlockHeight `json:"startheight"`
+		TimeoutHeight types.BlockHeight `json:"timeoutheight"`
+
+		// State is the state of the deployment in the current window, and
+		// Since is the height at which the deployment entered that state.
+		State string            `json:"state"`
+		Since types.BlockHeight `json:"since"`
+
+		// WindowStart is the height of the first block in the current window.
+		// WindowBlocks is the number of blocks in the current window so far,
+		// and WindowSignals is the number of those blocks that signal support
+		// for the deployment. Threshold is the number of signaling blocks in a
+		// window required for the deployment to lock in.
+		WindowStart      types.BlockHeight `json:"windowstart"`
+		WindowBlocks     types.BlockHeight `json:"windowblocks"`
+		WindowSignals    types.BlockHeight `json:"windowsignals"`
+		Threshold        types.BlockHeight `json:"threshold"`
+		SignalingPercent float64           `json:"signalingpercent"`
+	}
+
 	// A ConsensusSet accepts blocks and builds an understanding of network
 	// consensus.
 	ConsensusSet interface {
@@ -210,6 +261,10 @@ type (
 		// blockchain.
 		CurrentBlock() types.Block
 
+		// Deployments returns the signaling progress and activation state of
+		// every deployment known to the consensus set.
+		Deployments() []DeploymentStatus
+
 		// Flush will cause the consensus set to finish all in-progress
 		// routines.
 		Flush() error
@@ -217,6 +272,11 @@ type (
 		// Height returns the current height of consensus.
 		Height() types.BlockHeight
 
+		// HeaviestKnownBlock returns the ID and height of the block with the
+		// most accumulated work that the consensus set has seen since it
+		// started, and whether that block is the current block.
+		HeaviestKnownBlock() (id types.BlockID, height types.BlockHeight, current bool)
+
 		// Synced returns true if the consensus set is synced with the network.
 		Synced() bool
 
@@ -81,6 +81,19 @@ type ConsensusSet struct {
 	// whether the consensus set is synced with the network.
 	synced bool
 
+	// heaviestKnownID, heaviestKnownHeight and heaviestKnownDepth describe the
+	// block with the most accumulated work that has been added to the block
+	// tree since startup. It is usually the current block, but can also be the
+	// tip of a fork that is heavier than the current path without surpassing
+	// it by the required threshold, or that failed validation.
+	heaviestKnownID     types.BlockID
+	heaviestKnownHeight types.BlockHeight
+	heaviestKnownDepth  types.Target
+
+	// deployments caches the states of the deployments in past signaling
+	// windows.
+	deployments deploymentCache
+
 	// Interfaces to abstract the dependencies of the ConsensusSet.
 	marshaler       marshaler
 	blockRuleHelper blockRuleHelper
@@ -140,6 +153,10 @@ func New(gateway modules.Gateway, bootstrap bool, persistDir string) (*Consensus
 	if err != nil {
 		return nil, err
 	}
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		cs.updateHeaviestKnown(currentProcessedBlock(tx))
+		return nil
+	})
 
 	go func() {
 		// Sync with the network. Don't sync if we are testing because
@@ -292,6 +309,38 @@ func (cs *ConsensusSet) Height() (height types.BlockHeight) {
 	return height
 }
 
+// HeaviestKnownBlock returns the ID and height of the block with the most
+// accumulated work that the consensus set has seen since it started, and
+// whether that block is the current block.
+func (cs *ConsensusSet) HeaviestKnownBlock() (id types.BlockID, height types.BlockHeight, current bool) {
+	err := cs.tg.Add()
+	if err != nil {
+		return types.BlockID{}, 0, false
+	}
+	defer cs.tg.Done()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	_ = cs.db.View(func(tx *bolt.Tx) error {
+		current = currentBlockID(tx) == cs.heaviestKnownID
+		return nil
+	})
+	return cs.heaviestKnownID, cs.heaviestKnownHeight, current
+}
+
+// updateHeaviestKnown records pb as the heaviest known block if it has more
+// accumulated work than the current heaviest known block.
+func (cs *ConsensusSet) updateHeaviestKnown(pb *processedBlock) {
+	// A smaller depth means more accumulated work. The depth is all zeros
+	// before the first block is recorded.
+	if cs.heaviestKnownDepth != (types.Target{}) && pb.Depth.Cmp(cs.heaviestKnownDepth) >= 0 {
+		return
+	}
+	cs.heaviestKnownID = pb.Block.ID()
+	cs.heaviestKnownHeight = pb.Height
+	cs.heaviestKnownDepth = pb.Depth
+}
+
 // InCurrentPath returns true if the block presented is in the current path,
 // false otherwise.
 func (cs *ConsensusSet) InCurrentPath(id types.BlockID) (inPath bool) {
@@ -199,3 +199,49 @@ func TestDatabaseClosing(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestHeaviestKnownBlock checks that the consensus set reports when it knows
+// of a block that is heavier than its current block.
+func TestHeaviestKnownBlock(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	id, height, current := cst.cs.HeaviestKnownBlock()
+	if id != cst.cs.CurrentBlock().ID() || height != cst.cs.Height() || !current {
+		t.Fatal("the current block is not the heaviest known block")
+	}
+
+	// Record a fork that is two blocks heavier than the current path.
+	pb := cst.cs.dbCurrentProcessedBlock()
+	fork := processedBlock{
+		Block:  types.Block{ParentID: pb.Block.ID()},
+		Height: pb.Height + 2,
+		Depth:  pb.childDepth().AddDifficulties(pb.ChildTarget),
+	}
+	cst.cs.mu.Lock()
+	cst.cs.updateHeaviestKnown(&fork)
+	cst.cs.mu.Unlock()
+	id, height, current = cst.cs.HeaviestKnownBlock()
+	if id != fork.Block.ID() || height != fork.Height || current {
+		t.Fatal("the heavier fork was not reported")
+	}
+
+	// Once the current path is heavier than the fork, its tip is the
+	// heaviest known block again.
+	for i := 0; i < 3; i++ {
+		if _, err := cst.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, height, current = cst.cs.HeaviestKnownBlock()
+	if id != cst.cs.CurrentBlock().ID() || height != cst.cs.Height() || !current {
+		t.Fatal("the current block is not the heaviest known block after extending the current path")
+	}
+}
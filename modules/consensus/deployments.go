@@ -0,0 +1,183 @@
+package consensus
+
+import (
+	"sync"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// countSignals returns the number of blocks in the current path with heights
+// in the range [start, end) that signal support for the deployment.
+func countSignals(tx *bolt.Tx, d types.Deployment, start, end types.BlockHeight) (signals types.BlockHeight, err error) {
+	for height := start; height < end; height++ {
+		id, err := getPath(tx, height)
+		if err != nil {
+			return 0, err
+		}
+		pb, err := getBlockMap(tx, id)
+		if err != nil {
+			return 0, err
+		}
+		if d.Signals(pb.Block.DeploymentSignalBits()) {
+			signals++
+		}
+	}
+	return signals, nil
+}
+
+type (
+	// A deploymentWindow is the state of a deployment at the start of a
+	// signaling window.
+	deploymentWindow struct {
+		// parentID is the ID of the last block before the window. The state
+		// only depends on the blocks up to that block, so it stays valid
+		// while that block is in the current path.
+		parentID types.BlockID
+		state    string
+		since    types.BlockHeight
+	}
+
+	// deploymentCache holds the state of each deployment at the start of
+	// each signaling window that has been walked, keyed by deployment name
+	// and window start, so that only the windows since the last call need to
+	// be walked.
+	deploymentCache struct {
+		windows map[string]map[types.BlockHeight]deploymentWindow
+		mu      sync.Mutex
+	}
+)
+
+// deploymentStatus walks the signaling windows of the current path and
+// returns the state of the deployment in the window containing the current
+// block. State transitions only happen at window boundaries, and depend only
+// on the blocks of the previous window. The walk starts from the latest
+// cached window that is still in the current path.
+func (cs *ConsensusSet) deploymentStatus(tx *bolt.Tx, d types.Deployment) (modules.DeploymentStatus, error) {
+	height := blockHeight(tx)
+	windowStart := height - height%types.DeploymentWindow
+
+	cs.deployments.mu.Lock()
+	defer cs.deployments.mu.Unlock()
+	if cs.deployments.windows == nil {
+		cs.deployments.windows = make(map[string]map[types.BlockHeight]deploymentWindow)
+	}
+	windows := cs.deployments.windows[d.Name]
+	if windows == nil {
+		windows = make(map[types.BlockHeight]deploymentWindow)
+		cs.deployments.windows[d.Name] = windows
+	}
+
+	state := modules.DeploymentDefined
+	var since types.BlockHeight
+	start := types.BlockHeight(0)
+	for s := windowStart; s > 0; s -= types.DeploymentWindow {
+		w, ok := windows[s]
+		if !ok {
+			continue
+		}
+		parentID, err := getPath(tx, s-1)
+		if err != nil {
+			return modules.DeploymentStatus{}, err
+		}
+		if w.parentID == parentID {
+			state, since, start = w.state, w.since, s+types.DeploymentWindow
+			break
+		}
+	}
+
+	for ; start <= windowStart; start += types.DeploymentWindow {
+		if state == modules.DeploymentActive || state == modules.DeploymentFailed {
+			break
+		}
+		next := state
+		switch state {
+		case modules.DeploymentDefined:
+			if start >= d.TimeoutHeight {
+				next = modules.DeploymentFailed
+			} else if start >= d.StartHeight {
+				next = modules.DeploymentStarted
+			}
+		case modules.DeploymentStarted:
+			if start >= d.TimeoutHeight {
+				next = modules.DeploymentFailed
+				break
+			}
+			signals, err := countSignals(tx, d, start-types.DeploymentWindow, start)
+			if err != nil {
+				return modules.DeploymentStatus{}, err
+			}
+			if signals >= types.DeploymentThreshold {
+				next = modules.DeploymentLockedIn
+			}
+		case modules.DeploymentLockedIn:
+			next = modules.DeploymentActive
+		}
+		if next != state {
+			state = next
+			since = start
+		}
+		if start > 0 {
+			parentID, err := getPath(tx, start-1)
+			if err != nil {
+				return modules.DeploymentStatus{}, err
+			}
+			windows[start] = deploymentWindow{parentID: parentID, state: state, since: since}
+		}
+	}
+
+	status := modules.DeploymentStatus{
+		Name:          d.Name,
+		Bit:           d.Bit,
+		StartHeight:   d.StartHeight,
+		TimeoutHeight: d.TimeoutHeight,
+
+		State: state,
+		Since: since,
+
+		WindowStart:  windowStart,
+		WindowBlocks: height - windowStart + 1,
+		Threshold:    types.DeploymentThreshold,
+	}
+	// Signaling only matters while the deployment is started, so the blocks
+	// of the current window are only counted then.
+	if state == modules.DeploymentStarted {
+		signals, err := countSignals(tx, d, windowStart, height+1)
+		if err != nil {
+			return modules.DeploymentStatus{}, err
+		}
+		status.WindowSignals = signals
+		status.SignalingPercent = 100 * float64(signals) / float64(status.WindowBlocks)
+	}
+	return status, nil
+}
+
+// Deployments returns the signaling progress and activation state of every
+// deployment known to the consensus set.
+func (cs *ConsensusSet) Deployments() []modules.DeploymentStatus {
+	// A call to a closed database can cause undefined behavior.
+	err := cs.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer cs.tg.Done()
+
+	var statuses []modules.DeploymentStatus
+	err = cs.db.View(func(tx *bolt.Tx) error {
+		for _, d := range types.Deployments {
+			status, err := cs.deploymentStatus(tx, d)
+			if err != nil {
+				return err
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	if err != nil {
+		cs.log.Println("ERROR: unable to compute deployment status:", err)
+		return nil
+	}
+	return statuses
+}
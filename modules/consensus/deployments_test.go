@@ -0,0 +1,237 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// mineSignalingBlock mines a block that signals for the provided bits, or a
+// block without a signal if bits is 0.
+func (cst *consensusSetTester) mineSignalingBlock(bits uint64) error {
+	block, target, err := cst.miner.BlockForWork()
+	if err != nil {
+		return err
+	}
+	if bits != 0 {
+		block.Transactions = append(block.Transactions, types.Transaction{
+			ArbitraryData: [][]byte{types.DeploymentSignal(bits)},
+		})
+	}
+	block, _ = cst.miner.SolveBlock(block, target)
+	return cst.cs.AcceptBlock(block)
+}
+
+// deploymentStatus returns the status of a deployment as of the current block.
+func (cst *consensusSetTester) deploymentStatus(d types.Deployment) (status modules.DeploymentStatus, err error) {
+	err = cst.cs.db.View(func(tx *bolt.Tx) error {
+		status, err = cst.cs.deploymentStatus(tx, d)
+		return err
+	})
+	return status, err
+}
+
+// TestDeploymentSignalBits checks that signals are read from every
+// transaction in a block and that unrelated arbitrary data is ignored.
+func TestDeploymentSignalBits(t *testing.T) {
+	b := types.Block{
+		Transactions: []types.Transaction{
+			{ArbitraryData: [][]byte{append(modules.PrefixNonSia[:], make([]byte, 8)...)}},
+			{ArbitraryData: [][]byte{types.DeploymentSignal(1 << 3)}},
+			{ArbitraryData: [][]byte{types.DeploymentSignal(1 << 5), types.DeploymentSignal(1 << 3)}},
+			{ArbitraryData: [][]byte{types.DeploymentSignal(1 << 7)[:types.SpecifierLen+4]}},
+		},
+	}
+	if bits := b.DeploymentSignalBits(); bits != 1<<3|1<<5 {
+		t.Fatalf("expected bits %b, got %b", 1<<3|1<<5, bits)
+	}
+}
+
+// TestDeploymentActivation mines signaling blocks and checks that a deployment
+// moves from defined through started and locked in to active.
+func TestDeploymentActivation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Mine until the next block starts a new window.
+	window := types.DeploymentWindow
+	for (cst.cs.Height()+1)%window != 0 {
+		if err := cst.mineSignalingBlock(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := cst.cs.Height() + 1
+	d := types.Deployment{
+		Name:          "activate",
+		Bit:           4,
+		StartHeight:   start,
+		TimeoutHeight: start + 10*window,
+	}
+	status, err := cst.deploymentStatus(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentDefined {
+		t.Fatal("expected deployment to be defined, got", status.State)
+	}
+
+	// Signal for the deployment in one block fewer than required. The
+	// deployment should stay started.
+	for i := types.BlockHeight(0); i < window; i++ {
+		var bits uint64
+		if i < types.DeploymentThreshold-1 {
+			bits = 1 << d.Bit
+		}
+		if err := cst.mineSignalingBlock(bits); err != nil {
+			t.Fatal(err)
+		}
+		status, err = cst.deploymentStatus(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.State != modules.DeploymentStarted || status.Since != start {
+			t.Fatalf("expected deployment to be started since %v, got %v since %v", start, status.State, status.Since)
+		}
+		if status.WindowBlocks != i+1 {
+			t.Fatalf("expected %v blocks in window, got %v", i+1, status.WindowBlocks)
+		}
+	}
+	if status.WindowSignals != types.DeploymentThreshold-1 {
+		t.Fatalf("expected %v signals, got %v", types.DeploymentThreshold-1, status.WindowSignals)
+	}
+
+	// Signal in every block of the next window, alongside an unrelated bit.
+	for i := types.BlockHeight(0); i < window; i++ {
+		if err := cst.mineSignalingBlock(1<<d.Bit | 1<<(d.Bit+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status, err = cst.deploymentStatus(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentStarted || status.SignalingPercent != 100 {
+		t.Fatalf("expected started deployment with full signaling, got %v with %v%%", status.State, status.SignalingPercent)
+	}
+
+	// The next window should lock the deployment in, and the window after
+	// that should activate it, regardless of signaling.
+	if err := cst.mineSignalingBlock(0); err != nil {
+		t.Fatal(err)
+	}
+	status, err = cst.deploymentStatus(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentLockedIn || status.Since != start+2*window {
+		t.Fatalf("expected deployment to be locked in since %v, got %v since %v", start+2*window, status.State, status.Since)
+	}
+	for i := types.BlockHeight(0); i < window; i++ {
+		if err := cst.mineSignalingBlock(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status, err = cst.deploymentStatus(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentActive || status.Since != start+3*window {
+		t.Fatalf("expected deployment to be active since %v, got %v since %v", start+3*window, status.State, status.Since)
+	}
+
+	// A deployment that nobody signaled for should fail at its timeout.
+	unsignaled := types.Deployment{
+		Name:          "unsignaled",
+		Bit:           9,
+		StartHeight:   start,
+		TimeoutHeight: start + 2*window,
+	}
+	status, err = cst.deploymentStatus(unsignaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentFailed || status.Since != start+2*window {
+		t.Fatalf("expected deployment to have failed at %v, got %v since %v", start+2*window, status.State, status.Since)
+	}
+
+	// The known deployments never start in testing.
+	for _, status := range cst.cs.Deployments() {
+		if status.State != modules.DeploymentDefined {
+			t.Fatalf("expected deployment %v to be defined, got %v", status.Name, status.State)
+		}
+	}
+}
+
+// TestDeploymentStatusCache checks that deploymentStatus resumes from the
+// cached state of the current window, and ignores cached states whose window
+// is no longer in the current path.
+func TestDeploymentStatusCache(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cst, err := createConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.Close()
+
+	// Mine past the start of a window.
+	window := types.DeploymentWindow
+	for cst.cs.Height() < 2*window {
+		if err := cst.mineSignalingBlock(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := types.Deployment{
+		Name:          "cache",
+		Bit:           5,
+		StartHeight:   0,
+		TimeoutHeight: 100 * window,
+	}
+	status, err := cst.deploymentStatus(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentStarted {
+		t.Fatal("expected deployment to be started, got", status.State)
+	}
+	windowStart := status.WindowStart
+	cached, ok := cst.cs.deployments.windows[d.Name][windowStart]
+	if !ok || cached.state != modules.DeploymentStarted {
+		t.Fatalf("state of the current window was not cached: %+v", cached)
+	}
+
+	// A cached state is used while its window is in the current path.
+	cst.cs.deployments.windows[d.Name][windowStart] = deploymentWindow{parentID: cached.parentID, state: modules.DeploymentActive}
+	status, err = cst.deploymentStatus(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentActive {
+		t.Fatal("cached state was not used, got", status.State)
+	}
+
+	// A cached state from another path is recomputed.
+	cst.cs.deployments.windows[d.Name][windowStart] = deploymentWindow{parentID: types.BlockID{1}, state: modules.DeploymentActive}
+	status, err = cst.deploymentStatus(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != modules.DeploymentStarted {
+		t.Fatal("cached state from another path was used, got", status.State)
+	}
+	if cst.cs.deployments.windows[d.Name][windowStart] != cached {
+		t.Fatal("recomputed state was not cached")
+	}
+}
@@ -238,6 +238,7 @@ func (cs *ConsensusSet) managedReceiveBlocks(conn modules.PeerConn) (returnErr e
 
 // threadedReceiveBlocks is the calling end of the SendBlocks RPC.
 func (cs *ConsensusSet) threadedReceiveBlocks(conn modules.PeerConn) error {
+	defer build.CaptureCrash("consensus")
 	err := conn.SetDeadline(time.Now().Add(sendBlocksTimeout))
 	if err != nil {
 		return err
@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"time"
+
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -45,6 +47,19 @@ type (
 		TotalRevisionVolume types.Currency `json:"totalrevisionvolume"`
 	}
 
+	// An ExplorerMempoolTransaction is an unconfirmed transaction in the
+	// transaction pool.
+	ExplorerMempoolTransaction struct {
+		ID          types.TransactionID `json:"id"`
+		Transaction types.Transaction   `json:"rawtransaction"`
+		Size        uint64              `json:"size"`
+		Fee         types.Currency      `json:"fee"`
+
+		// FirstSeen is the time at which the explorer first saw the
+		// transaction in the transaction pool.
+		FirstSeen time.Time `json:"firstseen"`
+	}
+
 	// Explorer tracks the blockchain and provides tools for gathering
 	// statistics and finding objects or patterns within the blockchain.
 	Explorer interface {
@@ -99,6 +114,15 @@ type (
 		// the provided siafund output id.
 		SiafundOutputID(types.SiafundOutputID) []types.TransactionID
 
+		// MempoolTransactions returns the transactions in the transaction pool,
+		// oldest first. It is empty if the explorer was created without a
+		// transaction pool.
+		MempoolTransactions() []ExplorerMempoolTransaction
+
+		// Reindex discards the explorer's database and rebuilds it from the
+		// blocks of the consensus set.
+		Reindex() error
+
 		Close() error
 	}
 )
@@ -4,10 +4,13 @@ package explorer
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
 )
 
 const (
@@ -45,14 +48,20 @@ type (
 	// including various statistics and metrics.
 	Explorer struct {
 		cs         modules.ConsensusSet
+		tpool      modules.TransactionPool
 		db         *persist.BoltDatabase
 		persistDir string
+
+		// mempool holds the transaction sets of the transaction pool.
+		mempool map[modules.TransactionSetID][]modules.ExplorerMempoolTransaction
+		mu      sync.RWMutex
 	}
 )
 
 // New creates the internal data structures, and subscribes to
-// consensus for changes to the blockchain
-func New(cs modules.ConsensusSet, persistDir string) (*Explorer, error) {
+// consensus for changes to the blockchain. If tpool is not nil, the explorer
+// also subscribes to it to track unconfirmed transactions.
+func New(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir string) (*Explorer, error) {
 	// Check that input modules are non-nil
 	if cs == nil {
 		return nil, errNilCS
@@ -61,7 +70,10 @@ func New(cs modules.ConsensusSet, persistDir string) (*Explorer, error) {
 	// Initialize the explorer.
 	e := &Explorer{
 		cs:         cs,
+		tpool:      tpool,
 		persistDir: persistDir,
+
+		mempool: make(map[modules.TransactionSetID][]modules.ExplorerMempoolTransaction),
 	}
 
 	// Initialize the persistent structures, including the database.
@@ -82,11 +94,40 @@ func New(cs modules.ConsensusSet, persistDir string) (*Explorer, error) {
 		// TODO: restart from 0
 		return nil, errors.New("explorer subscription failed: " + err.Error())
 	}
+	if tpool != nil {
+		tpool.TransactionPoolSubscribe(e)
+	}
 
 	return e, nil
 }
 
+// Reindex discards the explorer database and rebuilds it from the blocks of
+// the consensus set. The call blocks until the explorer has caught up to the
+// current block.
+func (e *Explorer) Reindex() error {
+	e.cs.Unsubscribe(e)
+	err := e.db.Update(func(tx *bolt.Tx) error {
+		for _, b := range dbBuckets {
+			if err := tx.DeleteBucket(b); err != nil && err != bolt.ErrBucketNotFound {
+				return err
+			}
+		}
+		return initDatabase(tx)
+	})
+	if err != nil {
+		return err
+	}
+	err = e.cs.ConsensusSetSubscribe(e, modules.ConsensusChangeBeginning, nil)
+	if err != nil {
+		return errors.New("explorer subscription failed: " + err.Error())
+	}
+	return nil
+}
+
 // Close closes the explorer.
 func (e *Explorer) Close() error {
+	if e.tpool != nil {
+		e.tpool.Unsubscribe(e)
+	}
 	return e.db.Close()
 }
@@ -2,6 +2,7 @@ package explorer
 
 import (
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/build"
@@ -14,6 +15,8 @@ import (
 	"github.com/NebulousLabs/Sia/modules/wallet"
 	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
 )
 
 // Explorer tester struct is the helper object for explorer
@@ -67,7 +70,7 @@ func createExplorerTester(name string) (*explorerTester, error) {
 	if err != nil {
 		return nil, err
 	}
-	e, err := New(cs, filepath.Join(testdir, modules.ExplorerDir))
+	e, err := New(cs, tp, filepath.Join(testdir, modules.ExplorerDir))
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +151,7 @@ func (et *explorerTester) reorgToBlank() error {
 // TestNilExplorerDependencies tries to initialize an explorer with nil
 // dependencies, checks that the correct error is returned.
 func TestNilExplorerDependencies(t *testing.T) {
-	_, err := New(nil, "expdir")
+	_, err := New(nil, nil, "expdir")
 	if err != errNilCS {
 		t.Fatal("Expecting errNilCS")
 	}
@@ -170,7 +173,7 @@ func TestExplorerGenesisHeight(t *testing.T) {
 
 	// Create the explorer - from the subscription only the genesis block will
 	// be received.
-	e, err := New(cs, testdir)
+	e, err := New(cs, nil, testdir)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -185,3 +188,46 @@ func TestExplorerGenesisHeight(t *testing.T) {
 		t.Errorf("genesis block hash wrong height: expected 0, got %v", height)
 	}
 }
+
+// TestExplorerReindex checks that reindexing the explorer rebuilds the same
+// database from the consensus set.
+func TestExplorerReindex(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	et, err := createExplorerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := et.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	facts := et.explorer.LatestBlockFacts()
+
+	// Corrupt the database, so that the reindex must restore it.
+	err = et.explorer.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(bucketBlockIDs)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := et.explorer.Reindex(); err != nil {
+		t.Fatal(err)
+	}
+	if _, height, exists := et.explorer.Block(block.ID()); !exists || height != et.cs.Height() {
+		t.Fatal("explorer did not reindex the latest block")
+	}
+	if reindexed := et.explorer.LatestBlockFacts(); !reflect.DeepEqual(reindexed, facts) {
+		t.Fatalf("block facts changed after reindex: %v vs %v", reindexed, facts)
+	}
+
+	// The explorer should keep following the consensus set.
+	block, err = et.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, exists := et.explorer.Block(block.ID()); !exists {
+		t.Fatal("explorer did not process a block after reindex")
+	}
+}
@@ -0,0 +1,73 @@
+package explorer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// The explorer subscribes to the transaction pool to track the transactions
+// that have not been confirmed yet. The transaction pool replaces a set with a
+// larger one when new transactions depend on it, by reverting the old set and
+// applying the new one in the same update, so the time at which a transaction
+// was first seen is carried over from reverted sets. The mempool is not
+// persisted; after a restart, every transaction is first seen when the
+// transaction pool sends it again.
+
+// ReceiveUpdatedUnconfirmedTransactions updates the explorer's view of the
+// transaction pool.
+func (e *Explorer) ReceiveUpdatedUnconfirmedTransactions(diff *modules.TransactionPoolDiff) {
+	now := time.Now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	firstSeen := make(map[types.TransactionID]time.Time)
+	for _, setID := range diff.RevertedTransactions {
+		for _, txn := range e.mempool[setID] {
+			firstSeen[txn.ID] = txn.FirstSeen
+		}
+		delete(e.mempool, setID)
+	}
+	for _, set := range diff.AppliedTransactions {
+		txns := make([]modules.ExplorerMempoolTransaction, len(set.Transactions))
+		for i, txn := range set.Transactions {
+			var fee types.Currency
+			for _, mf := range txn.MinerFees {
+				fee = fee.Add(mf)
+			}
+			seen, ok := firstSeen[set.IDs[i]]
+			if !ok {
+				seen = now
+			}
+			txns[i] = modules.ExplorerMempoolTransaction{
+				ID:          set.IDs[i],
+				Transaction: txn,
+				Size:        set.Sizes[i],
+				Fee:         fee,
+				FirstSeen:   seen,
+			}
+		}
+		e.mempool[set.ID] = txns
+	}
+}
+
+// MempoolTransactions returns the transactions in the transaction pool,
+// oldest first.
+func (e *Explorer) MempoolTransactions() []modules.ExplorerMempoolTransaction {
+	e.mu.RLock()
+	var txns []modules.ExplorerMempoolTransaction
+	for _, set := range e.mempool {
+		txns = append(txns, set...)
+	}
+	e.mu.RUnlock()
+
+	// Transactions within a set keep their order, so that parents are
+	// listed before the transactions that spend their outputs.
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].FirstSeen.Before(txns[j].FirstSeen)
+	})
+	return txns
+}
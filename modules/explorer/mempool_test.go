@@ -0,0 +1,95 @@
+package explorer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationMempool checks that the explorer tracks the transactions in
+// the transaction pool until they are confirmed.
+func TestIntegrationMempool(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	et, err := createExplorerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txns := et.explorer.MempoolTransactions(); len(txns) != 0 {
+		t.Fatal("expected an empty mempool, got", len(txns))
+	}
+
+	_, err = et.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), types.UnlockHash{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns := et.explorer.MempoolTransactions()
+	if len(txns) == 0 {
+		t.Fatal("sent transactions are not in the mempool")
+	}
+	var fees types.Currency
+	for _, txn := range txns {
+		if txn.ID != txn.Transaction.ID() || txn.Size == 0 || txn.FirstSeen.IsZero() {
+			t.Fatal("mempool transaction is incomplete:", txn)
+		}
+		fees = fees.Add(txn.Fee)
+	}
+	if fees.IsZero() {
+		t.Fatal("mempool transactions do not pay a fee")
+	}
+
+	b, _ := et.miner.FindBlock()
+	if err := et.cs.AcceptBlock(b); err != nil {
+		t.Fatal(err)
+	}
+	if txns := et.explorer.MempoolTransactions(); len(txns) != 0 {
+		t.Fatal("confirmed transactions are still in the mempool:", len(txns))
+	}
+}
+
+// TestMempoolFirstSeen checks that a transaction keeps the time at which it
+// was first seen when its set is replaced by a larger one.
+func TestMempoolFirstSeen(t *testing.T) {
+	e := &Explorer{
+		mempool: make(map[modules.TransactionSetID][]modules.ExplorerMempoolTransaction),
+	}
+	parent := types.Transaction{MinerFees: []types.Currency{types.NewCurrency64(10), types.NewCurrency64(5)}}
+	child := types.Transaction{ArbitraryData: [][]byte{{1}}}
+	e.ReceiveUpdatedUnconfirmedTransactions(&modules.TransactionPoolDiff{
+		AppliedTransactions: []*modules.UnconfirmedTransactionSet{{
+			ID:           modules.TransactionSetID{1},
+			IDs:          []types.TransactionID{parent.ID()},
+			Sizes:        []uint64{100},
+			Transactions: []types.Transaction{parent},
+		}},
+	})
+	txns := e.MempoolTransactions()
+	if len(txns) != 1 || !txns[0].Fee.Equals64(15) {
+		t.Fatal("wrong mempool:", txns)
+	}
+	firstSeen := txns[0].FirstSeen
+
+	time.Sleep(10 * time.Millisecond)
+	e.ReceiveUpdatedUnconfirmedTransactions(&modules.TransactionPoolDiff{
+		RevertedTransactions: []modules.TransactionSetID{{1}},
+		AppliedTransactions: []*modules.UnconfirmedTransactionSet{{
+			ID:           modules.TransactionSetID{2},
+			IDs:          []types.TransactionID{parent.ID(), child.ID()},
+			Sizes:        []uint64{100, 50},
+			Transactions: []types.Transaction{parent, child},
+		}},
+	})
+	txns = e.MempoolTransactions()
+	if len(txns) != 2 {
+		t.Fatal("expected 2 mempool transactions, got", len(txns))
+	}
+	if txns[0].ID != parent.ID() || !txns[0].FirstSeen.Equal(firstSeen) {
+		t.Fatal("parent did not keep the time it was first seen")
+	}
+	if txns[1].ID != child.ID() || !txns[1].FirstSeen.After(firstSeen) {
+		t.Fatal("child was not seen after its parent")
+	}
+}
@@ -12,10 +12,29 @@ import (
 	"github.com/NebulousLabs/bolt"
 )
 
-var explorerMetadata = persist.Metadata{
-	Header:  "Sia Explorer",
-	Version: "0.5.2",
-}
+var (
+	explorerMetadata = persist.Metadata{
+		Header:  "Sia Explorer",
+		Version: "0.5.2",
+	}
+
+	// dbBuckets are the buckets of the explorer database.
+	dbBuckets = [][]byte{
+		bucketBlockFacts,
+		bucketBlockIDs,
+		bucketBlocksDifficulty,
+		bucketBlockTargets,
+		bucketFileContractHistories,
+		bucketFileContractIDs,
+		bucketInternal,
+		bucketSiacoinOutputIDs,
+		bucketSiacoinOutputs,
+		bucketSiafundOutputIDs,
+		bucketSiafundOutputs,
+		bucketTransactionIDs,
+		bucketUnlockHashes,
+	}
+)
 
 // initPersist initializes the persistent structures of the explorer module.
 func (e *Explorer) initPersist() error {
@@ -33,51 +52,40 @@ func (e *Explorer) initPersist() error {
 	e.db = db
 
 	// Initialize the database
-	err = e.db.Update(func(tx *bolt.Tx) error {
-		buckets := [][]byte{
-			bucketBlockFacts,
-			bucketBlockIDs,
-			bucketBlocksDifficulty,
-			bucketBlockTargets,
-			bucketFileContractHistories,
-			bucketFileContractIDs,
-			bucketInternal,
-			bucketSiacoinOutputIDs,
-			bucketSiacoinOutputs,
-			bucketSiafundOutputIDs,
-			bucketSiafundOutputs,
-			bucketTransactionIDs,
-			bucketUnlockHashes,
-		}
-		for _, b := range buckets {
-			_, err := tx.CreateBucketIfNotExists(b)
-			if err != nil {
-				return err
-			}
+	err = e.db.Update(initDatabase)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// initDatabase creates the buckets of the explorer database and sets the
+// default values of the internal bucket, leaving existing values untouched.
+func initDatabase(tx *bolt.Tx) error {
+	for _, b := range dbBuckets {
+		_, err := tx.CreateBucketIfNotExists(b)
+		if err != nil {
+			return err
 		}
+	}
 
-		// set default values for the bucketInternal
-		internalDefaults := []struct {
-			key, val []byte
-		}{
-			{internalBlockHeight, encoding.Marshal(types.BlockHeight(0))},
-			{internalRecentChange, encoding.Marshal(modules.ConsensusChangeID{})},
+	// set default values for the bucketInternal
+	internalDefaults := []struct {
+		key, val []byte
+	}{
+		{internalBlockHeight, encoding.Marshal(types.BlockHeight(0))},
+		{internalRecentChange, encoding.Marshal(modules.ConsensusChangeID{})},
+	}
+	b := tx.Bucket(bucketInternal)
+	for _, d := range internalDefaults {
+		if b.Get(d.key) != nil {
+			continue
 		}
-		b := tx.Bucket(bucketInternal)
-		for _, d := range internalDefaults {
-			if b.Get(d.key) != nil {
-				continue
-			}
-			err := b.Put(d.key, d.val)
-			if err != nil {
-				return err
-			}
+		err := b.Put(d.key, d.val)
+		if err != nil {
+			return err
 		}
-
-		return nil
-	})
-	if err != nil {
-		return err
 	}
 
 	return nil
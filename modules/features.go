@@ -0,0 +1,86 @@
+package modules
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// Risky subsystems ship disabled by default behind a feature flag, and can be
+// enabled per node without rebuilding siad, so that they can be rolled out in
+// stages. A subsystem registers its flag with RegisterFeature when its package
+// is initialized, and checks FeatureEnabled before it starts. The flags are
+// shared by all of the modules in the process. Most subsystems only check
+// their flag when they start, so a change may only take effect after a
+// restart.
+
+var (
+	// ErrUnknownFeature is returned when a feature flag that has not been
+	// registered is enabled or disabled.
+	ErrUnknownFeature = errors.New("unknown feature flag")
+
+	// features is the registry of feature flags.
+	features = struct {
+		mu    sync.RWMutex
+		flags map[string]*Feature
+	}{flags: make(map[string]*Feature)}
+)
+
+// A Feature is a flag that enables a subsystem which is disabled by default.
+type Feature struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// RegisterFeature adds a feature flag to the registry, disabled. It should be
+// called from the init function of the package that checks the flag.
+func RegisterFeature(name, description string) {
+	features.mu.Lock()
+	defer features.mu.Unlock()
+	if _, exists := features.flags[name]; exists {
+		build.Critical("feature flag registered twice:", name)
+		return
+	}
+	features.flags[name] = &Feature{
+		Name:        name,
+		Description: description,
+	}
+}
+
+// FeatureEnabled returns whether the feature flag is enabled. Flags that have
+// not been registered are disabled.
+func FeatureEnabled(name string) bool {
+	features.mu.RLock()
+	defer features.mu.RUnlock()
+	f, exists := features.flags[name]
+	return exists && f.Enabled
+}
+
+// SetFeature enables or disables a feature flag.
+func SetFeature(name string, enabled bool) error {
+	features.mu.Lock()
+	defer features.mu.Unlock()
+	f, exists := features.flags[name]
+	if !exists {
+		return ErrUnknownFeature
+	}
+	f.Enabled = enabled
+	return nil
+}
+
+// Features returns the registered feature flags, sorted by name.
+func Features() []Feature {
+	features.mu.RLock()
+	defer features.mu.RUnlock()
+	fs := make([]Feature, 0, len(features.flags))
+	for _, f := range features.flags {
+		fs = append(fs, *f)
+	}
+	sort.Slice(fs, func(i, j int) bool {
+		return fs[i].Name < fs[j].Name
+	})
+	return fs
+}
@@ -0,0 +1,43 @@
+package modules
+
+import "testing"
+
+// TestFeatures checks that registered feature flags start disabled and can be
+// enabled and disabled, and that unknown flags are rejected.
+func TestFeatures(t *testing.T) {
+	RegisterFeature("test-b", "second test flag")
+	RegisterFeature("test-a", "first test flag")
+	if FeatureEnabled("test-a") || FeatureEnabled("test-b") {
+		t.Fatal("feature flags should start disabled")
+	}
+
+	if err := SetFeature("test-a", true); err != nil {
+		t.Fatal(err)
+	}
+	if !FeatureEnabled("test-a") || FeatureEnabled("test-b") {
+		t.Fatal("only test-a should be enabled")
+	}
+	if err := SetFeature("test-a", false); err != nil {
+		t.Fatal(err)
+	}
+	if FeatureEnabled("test-a") {
+		t.Fatal("test-a should be disabled")
+	}
+
+	if err := SetFeature("test-unknown", true); err != ErrUnknownFeature {
+		t.Fatal("expected ErrUnknownFeature, got", err)
+	}
+	if FeatureEnabled("test-unknown") {
+		t.Fatal("unknown feature flags should be disabled")
+	}
+
+	var names []string
+	for _, f := range Features() {
+		if f.Name == "test-a" || f.Name == "test-b" {
+			names = append(names, f.Name)
+		}
+	}
+	if len(names) != 2 || names[0] != "test-a" || names[1] != "test-b" {
+		t.Fatal("feature flags should be sorted by name, got", names)
+	}
+}
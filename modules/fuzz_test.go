@@ -0,0 +1,85 @@
+package modules
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// fuzzReadObject checks that reading an object from arbitrary data, as the
+// host and renter read objects sent by their peers, never panics, and that
+// an object that is read successfully survives a round trip.
+func fuzzReadObject(t *testing.T, data []byte, obj interface{}, maxLen uint64) {
+	if err := encoding.ReadObject(bytes.NewReader(data), obj, maxLen); err != nil {
+		return
+	}
+	enc := encoding.Marshal(reflect.ValueOf(obj).Elem().Interface())
+	obj2 := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+	if err := encoding.Unmarshal(enc, obj2); err != nil {
+		t.Fatal("re-encoded object could not be decoded:", err)
+	}
+	if enc2 := encoding.Marshal(reflect.ValueOf(obj2).Elem().Interface()); !bytes.Equal(enc, enc2) {
+		t.Fatalf("object changed after a round trip:\n%x\n%x", enc, enc2)
+	}
+}
+
+// prefixed returns the length-prefixed encoding of v, as written by
+// encoding.WriteObject.
+func prefixed(v interface{}) []byte {
+	buf := new(bytes.Buffer)
+	encoding.WriteObject(buf, v)
+	return buf.Bytes()
+}
+
+// FuzzDownloadActions fuzzes the decoding of the download requests that
+// renters send to hosts.
+func FuzzDownloadActions(f *testing.F) {
+	f.Add(prefixed([]DownloadAction{{MerkleRoot: crypto.Hash{1}, Offset: 64, Length: SectorSize}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzReadObject(t, data, new([]DownloadAction), NegotiateMaxDownloadActionRequestSize)
+	})
+}
+
+// FuzzRevisionActions fuzzes the decoding of the modifications that renters
+// send to hosts when revising a contract.
+func FuzzRevisionActions(f *testing.F) {
+	f.Add(prefixed([]RevisionAction{{Type: ActionInsert, SectorIndex: 2, Data: []byte("foo")}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// The limit is smaller than the default MaxReviseBatchSize of the
+		// host, which only affects how much data a single input can hold.
+		fuzzReadObject(t, data, new([]RevisionAction), 1<<20)
+	})
+}
+
+// FuzzHostExternalSettings fuzzes the decoding of the settings that hosts
+// send to renters.
+func FuzzHostExternalSettings(f *testing.F) {
+	f.Add(prefixed(HostExternalSettings{
+		AcceptingContracts: true,
+		NetAddress:         "foo.com:1234",
+		StoragePrice:       types.NewCurrency64(1e9),
+		Version:            "1.2.0",
+	}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzReadObject(t, data, new(HostExternalSettings), NegotiateMaxHostExternalSettingsLen)
+	})
+}
+
+// FuzzDecodeAnnouncement fuzzes the decoding of host announcements, which
+// are read from the arbitrary data of transactions.
+func FuzzDecodeAnnouncement(f *testing.F) {
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: pk[:]}
+	ann, err := CreateAnnouncement("foo.com:1234", spk, sk)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(ann)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		DecodeAnnouncement(data)
+	})
+}
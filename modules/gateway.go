@@ -61,6 +61,14 @@ var (
 		Dev:     []NetAddress(nil),
 		Testing: []NetAddress(nil),
 	}).([]NetAddress)
+
+	// DefaultGatewayPeerLimits are the limits on inbound connections that a
+	// gateway starts with.
+	DefaultGatewayPeerLimits = build.Select(build.Var{
+		Standard: GatewayPeerLimits{MaxInboundPerIP: 2, MaxInboundPerSubnet: 4},
+		Dev:      GatewayPeerLimits{MaxInboundPerIP: 2, MaxInboundPerSubnet: 4},
+		Testing:  GatewayPeerLimits{MaxInboundPerIP: 2, MaxInboundPerSubnet: 3},
+	}).(GatewayPeerLimits)
 )
 
 type (
@@ -72,6 +80,33 @@ type (
 		Version    string     `json:"version"`
 	}
 
+	// GatewayPeerLimits are the limits on the inbound connections that a
+	// gateway accepts from a single IP address, and from a single subnet (the
+	// /24 of an IPv4 address, or the /48 of an IPv6 address). Connections from
+	// local addresses are not limited. A limit of zero disables it.
+	GatewayPeerLimits struct {
+		MaxInboundPerIP     int `json:"maxinboundperip"`
+		MaxInboundPerSubnet int `json:"maxinboundpersubnet"`
+	}
+
+	// GatewaySubnet counts the peers of a gateway in one subnet.
+	GatewaySubnet struct {
+		Subnet   string `json:"subnet"`
+		Inbound  int    `json:"inbound"`
+		Outbound int    `json:"outbound"`
+	}
+
+	// GatewayPeerSummary describes how the peers of a gateway are spread
+	// across subnets. Subnets are sorted by their number of inbound peers,
+	// most first. Local peers are counted, but not listed by subnet.
+	GatewayPeerSummary struct {
+		Inbound  int               `json:"inbound"`
+		Outbound int               `json:"outbound"`
+		Local    int               `json:"local"`
+		Limits   GatewayPeerLimits `json:"limits"`
+		Subnets  []GatewaySubnet   `json:"subnets"`
+	}
+
 	// A PeerConn is the connection type used when communicating with peers during
 	// an RPC. It is identical to a net.Conn with the additional RPCAddr method.
 	// This method acts as an identifier for peers and is the address that the
@@ -100,12 +135,39 @@ type (
 		// Disconnect terminates a connection to a peer.
 		Disconnect(NetAddress) error
 
+		// AddNode adds an address to the node list, the potential peers of
+		// the Gateway.
+		AddNode(NetAddress) error
+
+		// RemoveNode removes an address from the node list, disconnecting
+		// from it if it is a peer.
+		RemoveNode(NetAddress) error
+
+		// Ban disconnects from the peers on an IP address and refuses
+		// connections to and from it. The address may include a port, which
+		// is ignored.
+		Ban(string) error
+
+		// Unban lifts the ban of an IP address.
+		Unban(string) error
+
+		// BannedHosts returns the IP addresses that are banned.
+		BannedHosts() []string
+
 		// Address returns the Gateway's address.
 		Address() NetAddress
 
 		// Peers returns the addresses that the Gateway is currently connected to.
 		Peers() []Peer
 
+		// PeerSummary returns the distribution of the Gateway's peers across
+		// subnets, and the limits on inbound connections.
+		PeerSummary() GatewayPeerSummary
+
+		// SetPeerLimits sets the limits on inbound connections. They apply to
+		// new connections.
+		SetPeerLimits(GatewayPeerLimits) error
+
 		// RegisterRPC registers a function to handle incoming connections that
 		// supply the given RPC ID.
 		RegisterRPC(string, RPCFunc)
@@ -0,0 +1,189 @@
+package gateway
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// The operator can add and remove nodes from the node list, and ban hosts.
+// A ban applies to every port of an IP address: the gateway disconnects the
+// peers on that address, drops it from the node list, and neither accepts
+// connections from it nor connects to it until the ban is lifted. Bans are
+// saved in their own file, so that the node list keeps its format.
+
+const (
+	// bansFile is the name of the file that contains the banned hosts.
+	bansFile = "bans.json"
+)
+
+var (
+	// bansMetadata contains the header and version strings that identify the
+	// bans file.
+	bansMetadata = persist.Metadata{
+		Header:  "Gateway Bans",
+		Version: "1.3.1",
+	}
+
+	errBanned    = errors.New("address is banned")
+	errNotBanned = errors.New("address is not banned")
+)
+
+// banHost returns the IP address that a ban of addr applies to. addr may be
+// an IP address, with or without a port.
+func banHost(addr string) (string, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", errors.New("address must be an IP address: " + addr)
+	}
+	return ip.String(), nil
+}
+
+// isBanned returns whether the host of addr is banned.
+func (g *Gateway) isBanned(addr modules.NetAddress) bool {
+	host, err := banHost(string(addr))
+	if err != nil {
+		return false
+	}
+	_, banned := g.bans[host]
+	return banned
+}
+
+// loadBans loads the banned hosts from disk.
+func (g *Gateway) loadBans() error {
+	var hosts []string
+	err := persist.LoadJSON(bansMetadata, &hosts, filepath.Join(g.persistDir, bansFile))
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		g.bans[host] = struct{}{}
+	}
+	return nil
+}
+
+// saveBans stores the banned hosts on disk.
+func (g *Gateway) saveBans() error {
+	return persist.SaveJSON(bansMetadata, g.bannedHosts(), filepath.Join(g.persistDir, bansFile))
+}
+
+// bannedHosts returns the banned hosts, sorted.
+func (g *Gateway) bannedHosts() []string {
+	hosts := make([]string, 0, len(g.bans))
+	for host := range g.bans {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// AddNode adds an address to the node list, so that the gateway may connect
+// to it when it looks for peers.
+func (g *Gateway) AddNode(addr modules.NetAddress) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.addNode(addr); err != nil {
+		return err
+	}
+	g.log.Println("INFO: added node", addr)
+	return g.saveSync()
+}
+
+// RemoveNode removes an address from the node list, disconnecting from it if
+// it is a peer.
+func (g *Gateway) RemoveNode(addr modules.NetAddress) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, isNode := g.nodes[addr]
+	p, isPeer := g.peers[addr]
+	if !isNode && !isPeer {
+		return errors.New("no record of that node")
+	}
+	if isPeer {
+		p.sess.Close()
+		delete(g.peers, addr)
+	}
+	delete(g.nodes, addr)
+	g.log.Println("INFO: removed node", addr)
+	return g.saveSync()
+}
+
+// Ban disconnects from the peers on the IP address of addr, removes them from
+// the node list, and refuses connections to and from the address until it is
+// unbanned.
+func (g *Gateway) Ban(addr string) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+	host, err := banHost(addr)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for peerAddr, p := range g.peers {
+		if p.host() == host || peerAddr.Host() == host {
+			p.sess.Close()
+			delete(g.peers, peerAddr)
+		}
+	}
+	for nodeAddr := range g.nodes {
+		if nodeAddr.Host() == host {
+			delete(g.nodes, nodeAddr)
+		}
+	}
+	g.bans[host] = struct{}{}
+	g.log.Println("INFO: banned", host)
+	if err := g.saveBans(); err != nil {
+		return err
+	}
+	return g.saveSync()
+}
+
+// Unban lifts the ban of the IP address of addr.
+func (g *Gateway) Unban(addr string) error {
+	if err := g.threads.Add(); err != nil {
+		return err
+	}
+	defer g.threads.Done()
+	host, err := banHost(addr)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, banned := g.bans[host]; !banned {
+		return errNotBanned
+	}
+	delete(g.bans, host)
+	g.log.Println("INFO: unbanned", host)
+	return g.saveBans()
+}
+
+// BannedHosts returns the IP addresses that the gateway has banned.
+func (g *Gateway) BannedHosts() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.bannedHosts()
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"testing"
+)
+
+// TestAddRemoveNode tests that nodes can be added to and removed from the
+// node list.
+func TestAddRemoveNode(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+	defer g.Close()
+
+	if err := g.AddNode(dummyNode); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddNode(dummyNode); err != errNodeExists {
+		t.Fatal("expected errNodeExists, got", err)
+	}
+	g.mu.RLock()
+	_, exists := g.nodes[dummyNode]
+	g.mu.RUnlock()
+	if !exists {
+		t.Fatal("node was not added")
+	}
+
+	if err := g.RemoveNode(dummyNode); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RemoveNode(dummyNode); err == nil {
+		t.Fatal("removed a node that is not in the node list")
+	}
+	g.mu.RLock()
+	_, exists = g.nodes[dummyNode]
+	g.mu.RUnlock()
+	if exists {
+		t.Fatal("node was not removed")
+	}
+}
+
+// TestBan tests that the gateway disconnects from banned addresses and
+// refuses connections to and from them until they are unbanned.
+func TestBan(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+	defer g.Close()
+	g2 := newNamedTestingGateway(t, "2")
+	defer g2.Close()
+
+	if err := g.Ban("foo.com"); err == nil {
+		t.Fatal("banned an address that is not an IP address")
+	}
+	if err := g.Connect(g2.myAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ban the address of g2, including its port.
+	if err := g.Ban(string(g2.myAddr)); err != nil {
+		t.Fatal(err)
+	}
+	host := g2.myAddr.Host()
+	if bans := g.BannedHosts(); len(bans) != 1 || bans[0] != host {
+		t.Fatal("wrong banned hosts:", bans)
+	}
+	if len(g.Peers()) != 0 {
+		t.Fatal("gateway did not disconnect from the banned peer")
+	}
+	g.mu.RLock()
+	_, exists := g.nodes[g2.myAddr]
+	g.mu.RUnlock()
+	if exists {
+		t.Fatal("banned peer was not removed from the node list")
+	}
+	if err := g.AddNode(g2.myAddr); err != errBanned {
+		t.Fatal("expected errBanned when adding a banned node, got", err)
+	}
+	if err := g.Connect(g2.myAddr); err != errBanned {
+		t.Fatal("expected errBanned when connecting to a banned node, got", err)
+	}
+	if err := g2.Connect(g.myAddr); err == nil {
+		t.Fatal("gateway accepted a connection from a banned address")
+	}
+
+	// The ban is kept across restarts.
+	g.Close()
+	g, err := New("localhost:0", false, g.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if bans := g.BannedHosts(); len(bans) != 1 || bans[0] != host {
+		t.Fatal("bans were not loaded:", bans)
+	}
+
+	// Lift the ban.
+	if err := g.Unban(host); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Unban(host); err != errNotBanned {
+		t.Fatal("expected errNotBanned, got", err)
+	}
+	if err := g.Connect(g2.myAddr); err != nil {
+		t.Fatal(err)
+	}
+}
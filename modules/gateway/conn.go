@@ -27,10 +27,10 @@ func (g *Gateway) dial(addr modules.NetAddress) (net.Conn, error) {
 		Cancel:  g.threads.StopChan(),
 		Timeout: dialTimeout,
 	}
-	conn, err := dialer.Dial("tcp", string(addr))
+	conn, err := g.deps.dial(dialer, addr)
 	if err != nil {
 		return nil, err
 	}
 	conn.SetDeadline(time.Now().Add(connStdDeadline))
-	return conn, nil
+	return modules.LimitConn(conn, modules.BandwidthGateway), nil
 }
@@ -0,0 +1,34 @@
+package gateway
+
+import (
+	"net"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+type (
+	// dependencies defines the network dependencies of the Gateway. Tests can
+	// replace them to simulate an unreliable network.
+	dependencies interface {
+		// dial opens a connection to the given address using the dialer.
+		dial(*net.Dialer, modules.NetAddress) (net.Conn, error)
+
+		// listen gives the gateway the ability to receive incoming
+		// connections.
+		listen(string, string) (net.Listener, error)
+	}
+
+	// productionDependencies is an empty struct that implements all of the
+	// dependencies using the net package.
+	productionDependencies struct{}
+)
+
+// dial dials the address over TCP.
+func (productionDependencies) dial(d *net.Dialer, addr modules.NetAddress) (net.Conn, error) {
+	return d.Dial("tcp", string(addr))
+}
+
+// listen gives the gateway the ability to receive incoming connections.
+func (productionDependencies) listen(s1, s2 string) (net.Listener, error) {
+	return net.Listen(s1, s2)
+}
@@ -148,6 +148,36 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestNewOffline checks that an offline gateway refuses to connect to peers
+// and cannot be reached by them.
+func TestNewOffline(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	g, err := NewOffline(build.TempDir("gateway", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if g.Address() != "" {
+		t.Fatal("offline gateway should not have an address, got", g.Address())
+	}
+
+	peer := newTestingGateway(t)
+	defer peer.Close()
+	if err := g.Connect(peer.Address()); err != errOffline {
+		t.Fatal("expected offline error, got", err)
+	}
+	if err := peer.Connect(g.Address()); err == nil {
+		t.Fatal("peer should not be able to connect to an offline gateway")
+	}
+	if len(g.Peers()) != 0 || len(peer.Peers()) != 0 {
+		t.Fatal("offline gateway should have no peers")
+	}
+}
+
 // TestClose creates and closes a gateway.
 func TestClose(t *testing.T) {
 	if testing.Short() {
@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// To make eclipse attacks harder, the gateway limits the number of inbound
+// connections it accepts from a single IP address and from a single subnet,
+// and when it is full it kicks peers from the most crowded subnet first. The
+// limits use the address that a connection actually comes from, rather than
+// the address that the peer reports, which it is free to choose.
+
+var errNegativePeerLimit = errors.New("peer limits cannot be negative")
+
+// subnet returns the subnet of a host that inbound connections are limited
+// by: the /24 of an IPv4 address, or the /48 of an IPv6 address. A host that
+// is not an IP address is its own subnet.
+func subnet(host string) string {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	mask := net.CIDRMask(48, 128)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, mask = ip4, net.CIDRMask(24, 32)
+	}
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
+
+// localHost returns whether a host belongs to a local address range, which
+// inbound limits do not apply to.
+func localHost(host string) bool {
+	return modules.NetAddress(net.JoinHostPort(host, "0")).IsLocal()
+}
+
+// host returns the host that the peer's connection comes from. Peers that were
+// added without a connection use the host of their address.
+func (p *peer) host() string {
+	if p.remoteHost != "" {
+		return p.remoteHost
+	}
+	return p.NetAddress.Host()
+}
+
+// checkInboundLimits returns an error if accepting another inbound connection
+// from host would exceed the peer limits.
+func (g *Gateway) checkInboundLimits(host string) error {
+	if localHost(host) {
+		return nil
+	}
+	sn := subnet(host)
+	var fromIP, fromSubnet int
+	for _, p := range g.peers {
+		if !p.Inbound {
+			continue
+		}
+		if p.host() == host {
+			fromIP++
+		}
+		if subnet(p.host()) == sn {
+			fromSubnet++
+		}
+	}
+	if limit := g.peerLimits.MaxInboundPerIP; limit > 0 && fromIP >= limit {
+		return fmt.Errorf("already connected to %v inbound peers from %v", fromIP, host)
+	}
+	if limit := g.peerLimits.MaxInboundPerSubnet; limit > 0 && fromSubnet >= limit {
+		return fmt.Errorf("already connected to %v inbound peers from %v", fromSubnet, sn)
+	}
+	return nil
+}
+
+// crowdedSubnetPeers returns the addresses of the peers in the subnet that
+// holds the most of addrs, if that subnet holds more than one of them.
+// Otherwise it returns addrs.
+func (g *Gateway) crowdedSubnetPeers(addrs []modules.NetAddress) []modules.NetAddress {
+	bySubnet := make(map[string][]modules.NetAddress)
+	var crowded []modules.NetAddress
+	for _, addr := range addrs {
+		sn := subnet(g.peers[addr].host())
+		bySubnet[sn] = append(bySubnet[sn], addr)
+		if len(bySubnet[sn]) > len(crowded) {
+			crowded = bySubnet[sn]
+		}
+	}
+	if len(crowded) < 2 {
+		return addrs
+	}
+	return crowded
+}
+
+// PeerSummary returns the distribution of the Gateway's peers across subnets,
+// and the limits on inbound connections.
+func (g *Gateway) PeerSummary() modules.GatewayPeerSummary {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	summary := modules.GatewayPeerSummary{
+		Limits:  g.peerLimits,
+		Subnets: make([]modules.GatewaySubnet, 0),
+	}
+	index := make(map[string]int)
+	for _, p := range g.peers {
+		if p.Inbound {
+			summary.Inbound++
+		} else {
+			summary.Outbound++
+		}
+		if localHost(p.host()) {
+			summary.Local++
+			continue
+		}
+		sn := subnet(p.host())
+		i, ok := index[sn]
+		if !ok {
+			i = len(summary.Subnets)
+			index[sn] = i
+			summary.Subnets = append(summary.Subnets, modules.GatewaySubnet{Subnet: sn})
+		}
+		if p.Inbound {
+			summary.Subnets[i].Inbound++
+		} else {
+			summary.Subnets[i].Outbound++
+		}
+	}
+	sort.Slice(summary.Subnets, func(i, j int) bool {
+		si, sj := summary.Subnets[i], summary.Subnets[j]
+		if si.Inbound != sj.Inbound {
+			return si.Inbound > sj.Inbound
+		}
+		if si.Outbound != sj.Outbound {
+			return si.Outbound > sj.Outbound
+		}
+		return si.Subnet < sj.Subnet
+	})
+	return summary
+}
+
+// SetPeerLimits sets the limits on inbound connections. Peers that are already
+// connected are not disconnected.
+func (g *Gateway) SetPeerLimits(limits modules.GatewayPeerLimits) error {
+	if limits.MaxInboundPerIP < 0 || limits.MaxInboundPerSubnet < 0 {
+		return errNegativePeerLimit
+	}
+	g.mu.Lock()
+	g.peerLimits = limits
+	g.mu.Unlock()
+	g.log.Printf("INFO: inbound peer limits set to %v per IP and %v per subnet", limits.MaxInboundPerIP, limits.MaxInboundPerSubnet)
+	return nil
+}
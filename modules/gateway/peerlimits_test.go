@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestSubnet probes the subnet function.
+func TestSubnet(t *testing.T) {
+	tests := []struct {
+		host, subnet string
+	}{
+		{"1.2.3.4", "1.2.3.0/24"},
+		{"1.2.3.255", "1.2.3.0/24"},
+		{"::ffff:1.2.3.4", "1.2.3.0/24"},
+		{"2001:db8:1:2::1", "2001:db8:1::/48"},
+		{"foo.com", "foo.com"},
+	}
+	for _, test := range tests {
+		if sn := subnet(test.host); sn != test.subnet {
+			t.Errorf("%v: expected %v, got %v", test.host, test.subnet, sn)
+		}
+	}
+}
+
+// TestInboundPeerLimits checks that the gateway refuses inbound peers beyond
+// the limits for their IP address and subnet, and that it kicks peers from the
+// most crowded subnet to make room for new ones.
+func TestInboundPeerLimits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	g := newTestingGateway(t)
+	defer g.Close()
+
+	if err := g.SetPeerLimits(modules.GatewayPeerLimits{MaxInboundPerIP: -1}); err != errNegativePeerLimit {
+		t.Fatal("expected errNegativePeerLimit, got", err)
+	}
+	if err := g.SetPeerLimits(modules.GatewayPeerLimits{MaxInboundPerIP: 2, MaxInboundPerSubnet: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	inbound := func(host string, port int) *peer {
+		return &peer{
+			Peer: modules.Peer{
+				NetAddress: modules.NetAddress(fmt.Sprintf("%v:%v", host, port)),
+				Inbound:    true,
+			},
+			sess:       newClientStream(new(dummyConn), build.Version),
+			remoteHost: host,
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Two peers are accepted from an IP address, and three from its subnet.
+	for i, host := range []string{"1.2.3.4", "1.2.3.4", "1.2.3.5"} {
+		if err := g.acceptPeer(inbound(host, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.acceptPeer(inbound("1.2.3.6", 3)); err == nil {
+		t.Error("expected a fourth peer from the subnet to be refused")
+	}
+	g.peerLimits.MaxInboundPerSubnet = 0
+	if err := g.acceptPeer(inbound("1.2.3.4", 4)); err == nil {
+		t.Error("expected a third peer from the IP address to be refused")
+	}
+	// The limits apply to the address a connection comes from, not the
+	// address the peer reports, and local addresses are not limited.
+	spoofed := inbound("1.2.3.4", 5)
+	spoofed.NetAddress = "5.6.7.8:5"
+	if err := g.acceptPeer(spoofed); err == nil {
+		t.Error("expected a peer reporting a different address to be refused")
+	}
+	for i := 0; i < 3; i++ {
+		p := inbound("127.0.0.1", 10+i)
+		p.Local = true
+		if err := g.acceptPeer(p); err != nil {
+			t.Fatal("local peer was refused:", err)
+		}
+	}
+	g.mu.Unlock()
+	summary := g.PeerSummary()
+	g.mu.Lock()
+	if summary.Inbound != 6 || summary.Local != 3 || len(summary.Subnets) != 1 || summary.Subnets[0].Inbound != 3 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	// Fill the gateway with peers from distinct subnets. A new peer kicks a
+	// peer from the crowded subnet.
+	for i := 0; len(g.peers) < fullyConnectedThreshold; i++ {
+		g.addPeer(inbound(fmt.Sprintf("20.0.%v.1", i), 0))
+	}
+	if err := g.acceptPeer(inbound("9.9.9.9", 0)); err != nil {
+		t.Fatal(err)
+	}
+	crowded := 0
+	for _, p := range g.peers {
+		if subnet(p.host()) == "1.2.3.0/24" {
+			crowded++
+		}
+	}
+	if crowded != 2 {
+		t.Errorf("expected a peer to be kicked from the crowded subnet, %v remain", crowded)
+	}
+}
@@ -37,6 +37,9 @@ func (s invalidVersionError) Error() string {
 type peer struct {
 	modules.Peer
 	sess streamSession
+
+	// remoteHost is the host that an inbound connection came from.
+	remoteHost string
 }
 
 // sessionHeader is sent after the initial version exchange. It prevents peers
@@ -102,7 +105,7 @@ func (g *Gateway) permanentListen(closeChan chan struct{}) {
 			return
 		}
 
-		go g.threadedAcceptConn(conn)
+		go g.threadedAcceptConn(modules.LimitConn(conn, modules.BandwidthGateway))
 
 		// Sleep after each accept. This limits the rate at which the Gateway
 		// will accept new connections. The intent here is to prevent new
@@ -128,6 +131,15 @@ func (g *Gateway) threadedAcceptConn(conn net.Conn) {
 	addr := modules.NetAddress(conn.RemoteAddr().String())
 	g.log.Debugf("INFO: %v wants to connect", addr)
 
+	g.mu.RLock()
+	banned := g.isBanned(addr)
+	g.mu.RUnlock()
+	if banned {
+		g.log.Debugf("INFO: %v wanted to connect, but is banned", addr)
+		conn.Close()
+		return
+	}
+
 	remoteVersion, err := acceptVersionHandshake(conn, build.Version)
 	if err != nil {
 		g.log.Debugf("INFO: %v wanted to connect but version handshake failed: %v", addr, err)
@@ -195,11 +207,15 @@ func (g *Gateway) managedAcceptConnv130Peer(conn net.Conn, remoteVersion string)
 			NetAddress: remoteHeader.NetAddress,
 			Version:    remoteVersion,
 		},
-		sess: newServerStream(conn, remoteVersion),
+		sess:       newServerStream(conn, remoteVersion),
+		remoteHost: remoteHost(conn),
 	}
 	g.mu.Lock()
-	g.acceptPeer(peer)
+	err = g.acceptPeer(peer)
 	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
 
 	// Attempt to ping the supplied address. If successful, we will add
 	// remoteHeader.NetAddress to our node list after accepting the peer. We
@@ -247,7 +263,7 @@ func (g *Gateway) managedAcceptConnv100Peer(conn net.Conn, remoteVersion string)
 		return fmt.Errorf("already connected to a peer on that address: %v", remoteAddr)
 	}
 	// Accept the peer.
-	g.acceptPeer(&peer{
+	err = g.acceptPeer(&peer{
 		Peer: modules.Peer{
 			Inbound: true,
 			// NOTE: local may be true even if the supplied remoteAddr is not
@@ -256,8 +272,12 @@ func (g *Gateway) managedAcceptConnv100Peer(conn net.Conn, remoteVersion string)
 			NetAddress: remoteAddr,
 			Version:    remoteVersion,
 		},
-		sess: newServerStream(conn, remoteVersion),
+		sess:       newServerStream(conn, remoteVersion),
+		remoteHost: host,
 	})
+	if err != nil {
+		return err
+	}
 
 	// Attempt to ping the supplied address. If successful, and a connection is wanted,
 	// we will add remoteAddr to our node list after accepting the peer. We do this in a
@@ -286,26 +306,41 @@ func (g *Gateway) managedAcceptConnOldPeer(conn net.Conn, remoteVersion string)
 
 	// Old peers are unable to give us a dialback port, so we can't verify
 	// whether or not they are local peers.
-	g.acceptPeer(&peer{
+	err := g.acceptPeer(&peer{
 		Peer: modules.Peer{
 			Inbound:    true,
 			Local:      false,
 			NetAddress: addr,
 			Version:    remoteVersion,
 		},
-		sess: newServerStream(conn, remoteVersion),
+		sess:       newServerStream(conn, remoteVersion),
+		remoteHost: remoteHost(conn),
 	})
+	if err != nil {
+		return err
+	}
 	g.addNode(addr)
 	return nil
 }
 
+// remoteHost returns the host that a connection comes from.
+func remoteHost(conn net.Conn) string {
+	host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	return host
+}
+
 // acceptPeer makes room for the peer if necessary by kicking out existing
-// peers, then adds the peer to the peer list.
-func (g *Gateway) acceptPeer(p *peer) {
+// peers, then adds the peer to the peer list. An error is returned if the
+// peer would exceed the limits on inbound connections.
+func (g *Gateway) acceptPeer(p *peer) error {
+	if err := g.checkInboundLimits(p.host()); err != nil {
+		return err
+	}
+
 	// If we are not fully connected, add the peer without kicking any out.
 	if len(g.peers) < fullyConnectedThreshold {
 		g.addPeer(p)
-		return
+		return nil
 	}
 
 	// Select a peer to kick. Outbound peers and local peers are not
@@ -318,7 +353,7 @@ func (g *Gateway) acceptPeer(p *peer) {
 		}
 
 		// Prefer kicking a peer with the same hostname.
-		if addr.Host() == p.NetAddress.Host() {
+		if peer.host() == p.host() {
 			addrs = []modules.NetAddress{addr}
 			break
 		}
@@ -327,16 +362,19 @@ func (g *Gateway) acceptPeer(p *peer) {
 	if len(addrs) == 0 {
 		// There is nobody suitable to kick, therefore do not kick anyone.
 		g.addPeer(p)
-		return
+		return nil
 	}
 
-	// Of the remaining options, select one at random.
+	// Of the remaining options, select one at random, preferring peers from
+	// the most crowded subnet.
+	addrs = g.crowdedSubnetPeers(addrs)
 	kick := addrs[fastrand.Intn(len(addrs))]
 
 	g.peers[kick].sess.Close()
 	delete(g.peers, kick)
 	g.log.Printf("INFO: disconnected from %v to make room for %v\n", kick, p.NetAddress)
 	g.addPeer(p)
+	return nil
 }
 
 // acceptableVersion returns an error if the version is unacceptable.
@@ -468,6 +506,10 @@ func (g *Gateway) managedConnectv100Peer(conn net.Conn, remoteVersion string, re
 // managedConnect establishes a persistent connection to a peer, and adds it to
 // the Gateway's peer list.
 func (g *Gateway) managedConnect(addr modules.NetAddress) error {
+	if g.offline {
+		return errOffline
+	}
+
 	// Perform verification on the input address.
 	g.mu.RLock()
 	gaddr := g.myAddr
@@ -483,9 +525,12 @@ func (g *Gateway) managedConnect(addr modules.NetAddress) error {
 	}
 	g.mu.RLock()
 	_, exists := g.peers[addr]
+	banned := g.isBanned(addr)
 	g.mu.RUnlock()
 	if exists {
 		return errPeerExists
+	} else if banned {
+		return errBanned
 	}
 
 	// Dial the peer and perform peer initialization.
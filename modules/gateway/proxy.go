@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	errProxyScheme = errors.New("proxy URL must begin with http:// or https://")
+	errProxyHost   = errors.New("proxy URL must include a host")
+)
+
+type (
+	// proxyDependencies dials peers through an HTTP proxy using the CONNECT
+	// method, for nodes that can only reach the internet through a proxy.
+	// Listening is unaffected.
+	proxyDependencies struct {
+		productionDependencies
+		proxy *url.URL
+	}
+
+	// tunnelConn is a connection to a peer through a proxy. Bytes sent by the
+	// peer may have been buffered while reading the response of the proxy.
+	tunnelConn struct {
+		net.Conn
+		r          *bufio.Reader
+		remoteAddr net.Addr
+	}
+)
+
+// Read reads from the buffer before reading from the connection.
+func (tc tunnelConn) Read(b []byte) (int, error) {
+	return tc.r.Read(b)
+}
+
+// RemoteAddr returns the address of the peer, rather than that of the proxy.
+func (tc tunnelConn) RemoteAddr() net.Addr {
+	return tc.remoteAddr
+}
+
+// parseProxyURL parses the URL of an HTTP or HTTPS proxy. A proxy given
+// without a port uses the default port of its scheme.
+func parseProxyURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errProxyScheme
+	}
+	if u.Hostname() == "" {
+		return nil, errProxyHost
+	}
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	return u, nil
+}
+
+// dial asks the proxy to open a tunnel to the address, and returns the
+// tunnel.
+func (pd proxyDependencies) dial(d *net.Dialer, addr modules.NetAddress) (net.Conn, error) {
+	conn, err := d.Dial("tcp", pd.proxy.Host)
+	if err != nil {
+		return nil, err
+	}
+	if pd.proxy.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: pd.proxy.Hostname()})
+	}
+	// The request to the proxy is bound by the timeout of the dialer.
+	if d.Timeout != 0 {
+		conn.SetDeadline(time.Now().Add(d.Timeout))
+	}
+
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: string(addr)},
+		Host:   string(addr),
+		Header: make(http.Header),
+	}
+	if pd.proxy.User != nil {
+		password, _ := pd.proxy.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(pd.proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused connection to %v: %v", addr, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	remoteAddr, err := net.ResolveTCPAddr("tcp", string(addr))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tunnelConn{Conn: conn, r: r, remoteAddr: remoteAddr}, nil
+}
@@ -192,6 +192,7 @@ func (g *Gateway) threadedListenPeer(p *peer) {
 // threadedHandleConn reads header data from a connection, then routes it to the
 // appropriate handler for further processing.
 func (g *Gateway) threadedHandleConn(conn modules.PeerConn) {
+	defer build.CaptureCrash("gateway")
 	defer conn.Close()
 	if g.threads.Add() != nil {
 		return
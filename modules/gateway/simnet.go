@@ -0,0 +1,272 @@
+// +build testing
+
+package gateway
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/fastrand"
+)
+
+var (
+	// errSimLinkDown is returned when a connection is attempted or used over
+	// a simulated link that is down.
+	errSimLinkDown = errors.New("simulated link is down")
+
+	// errSimDropped is returned when the simulated network drops a
+	// connection.
+	errSimDropped = errors.New("simulated network dropped the connection")
+)
+
+type (
+	// SimLink describes the conditions of the simulated link between two
+	// gateways.
+	SimLink struct {
+		// Latency is added to every read and write on a connection over the
+		// link, and to every dial.
+		Latency time.Duration
+
+		// DropRate is the probability that a dial over the link fails, or
+		// that a write on an established connection cuts the connection.
+		DropRate float64
+
+		// Down partitions the two gateways. Dials over the link fail, and
+		// existing connections are closed.
+		Down bool
+	}
+
+	// A SimNetwork connects in-process gateways through simulated links that
+	// can inject latency, dropped connections, and partitions. Gateways
+	// created by a SimNetwork still talk over loopback TCP, so every RPC
+	// runs exactly as it would in production. It can be used to write
+	// multi-node sync and relay tests for new RPCs.
+	SimNetwork struct {
+		defaultLink SimLink
+		links       map[simLinkID]SimLink
+		conns       map[*simConn]struct{}
+		mu          sync.Mutex
+	}
+
+	// simLinkID identifies the link between two gateways by the ports they
+	// listen on, in sorted order.
+	simLinkID [2]string
+
+	// simDependencies connect a gateway to a SimNetwork.
+	simDependencies struct {
+		network *SimNetwork
+		port    string
+		mu      sync.Mutex
+	}
+
+	// simConn is a connection over a simulated link. Connections are only
+	// wrapped by the dialing gateway, so that the conditions of the link are
+	// applied once per connection.
+	simConn struct {
+		net.Conn
+		network *SimNetwork
+		link    simLinkID
+	}
+)
+
+// NewSimNetwork returns a SimNetwork whose links have no latency and never
+// drop connections.
+func NewSimNetwork() *SimNetwork {
+	return &SimNetwork{
+		links: make(map[simLinkID]SimLink),
+		conns: make(map[*simConn]struct{}),
+	}
+}
+
+// NewGateway returns a gateway that listens on a loopback address and is
+// connected to the other gateways of the network through simulated links.
+func (sn *SimNetwork) NewGateway(persistDir string) (*Gateway, error) {
+	return newGateway("localhost:0", false, false, persistDir, &simDependencies{network: sn})
+}
+
+// simPort returns the port of an address, which identifies a gateway in the
+// simulated network.
+func simPort(addr string) string {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return port
+}
+
+// newSimLinkID returns the ID of the link between the two addresses.
+func newSimLinkID(a, b string) simLinkID {
+	a, b = simPort(a), simPort(b)
+	if a > b {
+		a, b = b, a
+	}
+	return simLinkID{a, b}
+}
+
+// link returns the current conditions of a link.
+func (sn *SimNetwork) link(id simLinkID) SimLink {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	if l, ok := sn.links[id]; ok {
+		return l
+	}
+	return sn.defaultLink
+}
+
+// closeDownConns closes all connections over links that are down. It must be
+// called with the lock held.
+func (sn *SimNetwork) closeDownConns() {
+	for c := range sn.conns {
+		l, ok := sn.links[c.link]
+		if !ok {
+			l = sn.defaultLink
+		}
+		if l.Down {
+			c.Conn.Close()
+			delete(sn.conns, c)
+		}
+	}
+}
+
+// SetDefaultLink sets the conditions of all links that have not been set
+// individually.
+func (sn *SimNetwork) SetDefaultLink(l SimLink) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	sn.defaultLink = l
+	sn.closeDownConns()
+}
+
+// SetLink sets the conditions of the link between two gateways.
+func (sn *SimNetwork) SetLink(a, b modules.NetAddress, l SimLink) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	sn.links[newSimLinkID(string(a), string(b))] = l
+	sn.closeDownConns()
+}
+
+// Partition splits the gateways into groups that cannot reach each other.
+// Links within a group are left untouched.
+func (sn *SimNetwork) Partition(groups ...[]modules.NetAddress) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	for i := range groups {
+		for j := i + 1; j < len(groups); j++ {
+			for _, a := range groups[i] {
+				for _, b := range groups[j] {
+					id := newSimLinkID(string(a), string(b))
+					l, ok := sn.links[id]
+					if !ok {
+						l = sn.defaultLink
+					}
+					l.Down = true
+					sn.links[id] = l
+				}
+			}
+		}
+	}
+	sn.closeDownConns()
+}
+
+// Heal brings all links back up, undoing any partitions.
+func (sn *SimNetwork) Heal() {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	sn.defaultLink.Down = false
+	for id, l := range sn.links {
+		l.Down = false
+		sn.links[id] = l
+	}
+}
+
+// dial opens a connection over the simulated link to addr.
+func (sd *simDependencies) dial(d *net.Dialer, addr modules.NetAddress) (net.Conn, error) {
+	sd.mu.Lock()
+	id := newSimLinkID(sd.port, string(addr))
+	sd.mu.Unlock()
+
+	l := sd.network.link(id)
+	if l.Down {
+		return nil, errSimLinkDown
+	}
+	if simDrop(l.DropRate) {
+		return nil, errSimDropped
+	}
+	select {
+	case <-time.After(l.Latency):
+	case <-d.Cancel:
+		return nil, errors.New("dial cancelled")
+	}
+
+	conn, err := d.Dial("tcp", string(addr))
+	if err != nil {
+		return nil, err
+	}
+	c := &simConn{
+		Conn:    conn,
+		network: sd.network,
+		link:    id,
+	}
+	sd.network.mu.Lock()
+	sd.network.conns[c] = struct{}{}
+	sd.network.mu.Unlock()
+	return c, nil
+}
+
+// listen listens on a loopback address and records the port, which
+// identifies the gateway in the simulated network.
+func (sd *simDependencies) listen(network, addr string) (net.Listener, error) {
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	sd.mu.Lock()
+	sd.port = simPort(l.Addr().String())
+	sd.mu.Unlock()
+	return l, nil
+}
+
+// simDrop returns true with the given probability.
+func simDrop(rate float64) bool {
+	return rate > 0 && float64(fastrand.Intn(1e6)) < rate*1e6
+}
+
+// Read reads from the connection, delaying the data by the latency of the
+// link.
+func (c *simConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	l := c.network.link(c.link)
+	if l.Down {
+		c.Close()
+		return 0, errSimLinkDown
+	}
+	time.Sleep(l.Latency)
+	return n, err
+}
+
+// Write writes to the connection after the latency of the link has passed.
+// The connection is closed if the link is down or drops the connection.
+func (c *simConn) Write(b []byte) (int, error) {
+	l := c.network.link(c.link)
+	if l.Down {
+		c.Close()
+		return 0, errSimLinkDown
+	}
+	if simDrop(l.DropRate) {
+		c.Close()
+		return 0, errSimDropped
+	}
+	time.Sleep(l.Latency)
+	return c.Conn.Write(b)
+}
+
+// Close closes the connection and removes it from the network.
+func (c *simConn) Close() error {
+	c.network.mu.Lock()
+	delete(c.network.conns, c)
+	c.network.mu.Unlock()
+	return c.Conn.Close()
+}
@@ -0,0 +1,133 @@
+// +build testing
+
+package gateway
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// newSimGateway returns a gateway connected to the simulated network.
+func newSimGateway(t *testing.T, sn *SimNetwork, suffix string) *Gateway {
+	g, err := sn.NewGateway(build.TempDir("gateway", t.Name()+suffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// TestSimNetworkLatency checks that RPCs over a simulated link are delayed by
+// the latency of the link.
+func TestSimNetworkLatency(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	sn := NewSimNetwork()
+	g1 := newSimGateway(t, sn, "1")
+	defer g1.Close()
+	g2 := newSimGateway(t, sn, "2")
+	defer g2.Close()
+
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+	g2.RegisterRPC("Echo", func(conn modules.PeerConn) error {
+		var s string
+		if err := encoding.ReadObject(conn, &s, 16); err != nil {
+			return err
+		}
+		return encoding.WriteObject(conn, s)
+	})
+	echo := func(conn modules.PeerConn) error {
+		if err := encoding.WriteObject(conn, "foo"); err != nil {
+			return err
+		}
+		var s string
+		return encoding.ReadObject(conn, &s, 16)
+	}
+
+	latency := 100 * time.Millisecond
+	sn.SetLink(g1.Address(), g2.Address(), SimLink{Latency: latency})
+	start := time.Now()
+	if err := g1.RPC(g2.Address(), "Echo", echo); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*latency {
+		t.Fatalf("RPC took %v, expected at least %v", elapsed, 2*latency)
+	}
+}
+
+// TestSimNetworkPartition checks that partitioned gateways lose their
+// connections and cannot reconnect until the partition is healed.
+func TestSimNetworkPartition(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	sn := NewSimNetwork()
+	g1 := newSimGateway(t, sn, "1")
+	defer g1.Close()
+	g2 := newSimGateway(t, sn, "2")
+	defer g2.Close()
+	g3 := newSimGateway(t, sn, "3")
+	defer g3.Close()
+
+	for _, g := range []*Gateway{g2, g3} {
+		if err := g1.Connect(g.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Split g3 from the others. Both sides should notice that the
+	// connection was lost.
+	sn.Partition([]modules.NetAddress{g1.Address(), g2.Address()}, []modules.NetAddress{g3.Address()})
+	err := build.Retry(50, 100*time.Millisecond, func() error {
+		if len(g1.Peers()) != 1 || len(g3.Peers()) != 0 {
+			return errors.New("partitioned peers are still connected")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g1.Connect(g3.Address()); err == nil {
+		t.Fatal("connected across a partition")
+	}
+	if err := g3.Connect(g2.Address()); err == nil {
+		t.Fatal("connected across a partition")
+	}
+
+	sn.Heal()
+	if err := g3.Connect(g1.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSimNetworkDrops checks that a link that drops every connection
+// prevents gateways from connecting.
+func TestSimNetworkDrops(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	sn := NewSimNetwork()
+	g1 := newSimGateway(t, sn, "1")
+	defer g1.Close()
+	g2 := newSimGateway(t, sn, "2")
+	defer g2.Close()
+
+	sn.SetDefaultLink(SimLink{DropRate: 1})
+	if err := g1.Connect(g2.Address()); err == nil {
+		t.Fatal("connected over a link that drops every connection")
+	}
+	sn.SetDefaultLink(SimLink{})
+	if err := g1.Connect(g2.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
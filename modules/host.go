@@ -44,6 +44,52 @@ var (
 	// ConnectabilityStatus() if the host is not connectable at its configured
 	// netaddress.
 	HostConnectabilityStatusNotConnectable = HostConnectabilityStatus("not connectable")
+
+	// HostProofNoAttempt is the reason of a missed storage proof if the host
+	// never attempted it, typically because it was offline during the proof
+	// window.
+	HostProofNoAttempt = HostProofReason("no attempt")
+
+	// HostProofNoSectors is the reason of a missed storage proof if the
+	// obligation holds no data, so there is nothing to prove.
+	HostProofNoSectors = HostProofReason("no sectors")
+
+	// HostProofSegmentUnavailable is the reason of a missed storage proof if
+	// the consensus set could not provide the segment to prove.
+	HostProofSegmentUnavailable = HostProofReason("segment unavailable")
+
+	// HostProofSectorReadFailed is the reason of a missed storage proof if
+	// the sector containing the segment could not be read from disk.
+	HostProofSectorReadFailed = HostProofReason("sector read failed")
+
+	// HostProofFeeTooHigh is the reason of a missed storage proof if the
+	// transaction fee exceeded the value of the obligation.
+	HostProofFeeTooHigh = HostProofReason("fee too high")
+
+	// HostProofWalletLocked is the reason of a missed storage proof if the
+	// wallet was locked, so the transaction fee could not be paid.
+	HostProofWalletLocked = HostProofReason("wallet locked")
+
+	// HostProofFundingFailed is the reason of a missed storage proof if the
+	// unlocked wallet could not pay the transaction fee.
+	HostProofFundingFailed = HostProofReason("funding failed")
+
+	// HostProofSigningFailed is the reason of a missed storage proof if the
+	// wallet could not sign the proof transaction.
+	HostProofSigningFailed = HostProofReason("signing failed")
+
+	// HostProofTransactionPoolRejected is the reason of a missed storage
+	// proof if the transaction pool rejected the proof transaction.
+	HostProofTransactionPoolRejected = HostProofReason("transaction pool rejected")
+
+	// HostProofSubmitted is recorded when the proof transaction was accepted
+	// by the transaction pool. It is the reason of a missed storage proof if
+	// the transaction was never confirmed.
+	HostProofSubmitted = HostProofReason("submitted")
+
+	// HostProofReorged is the reason of a missed storage proof if the block
+	// confirming the proof was reverted by a reorg.
+	HostProofReorged = HostProofReason("reorg")
 )
 
 type (
@@ -77,6 +123,112 @@ type (
 		UploadBandwidthRevenue            types.Currency `json:"uploadbandwidthrevenue"`
 	}
 
+	// A HostRevenueSnapshot records the total revenue earned by the host as
+	// of the end of a day. Revenue includes contract compensation, storage
+	// revenue and bandwidth revenue.
+	HostRevenueSnapshot struct {
+		// Timestamp is the start of the day, in UTC.
+		Timestamp types.Timestamp `json:"timestamp"`
+		Revenue   types.Currency  `json:"revenue"`
+	}
+
+	// A HostContractUsageSnapshot records the data transferred under a single
+	// storage obligation during one snapshot period, along with the potential
+	// revenue that the transfers earned.
+	HostContractUsageSnapshot struct {
+		// Timestamp is the start of the period, in UTC.
+		Timestamp       types.Timestamp `json:"timestamp"`
+		BytesAdded      uint64          `json:"bytesadded"`
+		BytesRemoved    uint64          `json:"bytesremoved"`
+		BytesDownloaded uint64          `json:"bytesdownloaded"`
+		Revenue         types.Currency  `json:"revenue"`
+	}
+
+	// HostProofReason describes the outcome of an attempt of the host to
+	// submit a storage proof.
+	HostProofReason string
+
+	// A HostProofAttempt records an attempt of the host to submit the storage
+	// proof of an obligation, along with the transactions involved.
+	HostProofAttempt struct {
+		Height         types.BlockHeight     `json:"height"`
+		Timestamp      types.Timestamp       `json:"timestamp"`
+		Reason         HostProofReason       `json:"reason"`
+		Error          string                `json:"error"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+
+	// HostObligationFailure is the forensic record of a storage obligation
+	// whose storage proof was missed. Reason is the reason of the last
+	// attempt, which is the most likely cause of the failure, and Attempts
+	// lists the attempts in the order they were made.
+	HostObligationFailure struct {
+		ObligationID     types.FileContractID `json:"obligationid"`
+		Reason           HostProofReason      `json:"reason"`
+		ProofWindowStart types.BlockHeight    `json:"proofwindowstart"`
+		ProofDeadline    types.BlockHeight    `json:"proofdeadline"`
+		FailureHeight    types.BlockHeight    `json:"failureheight"`
+		FailureTimestamp types.Timestamp      `json:"failuretimestamp"`
+		Attempts         []HostProofAttempt   `json:"attempts"`
+	}
+
+	// HostDowntimeSimulation is the worst-case outcome of the host being
+	// offline between StartHeight and EndHeight. ProofsAtRisk is the number of
+	// storage obligations whose proof window overlaps the downtime, and
+	// ProofsMissed is the number of those assumed to be missed, choosing the
+	// most costly first. CollateralBurned and RevenueLost total the collateral
+	// and revenue that the host forfeits for the missed proofs.
+	HostDowntimeSimulation struct {
+		StartHeight      types.BlockHeight        `json:"startheight"`
+		EndHeight        types.BlockHeight        `json:"endheight"`
+		ProofsAtRisk     int                      `json:"proofsatrisk"`
+		ProofsMissed     int                      `json:"proofsmissed"`
+		CollateralBurned types.Currency           `json:"collateralburned"`
+		RevenueLost      types.Currency           `json:"revenuelost"`
+		Obligations      []HostDowntimeObligation `json:"obligations"`
+	}
+
+	// HostDowntimeObligation is a storage obligation whose proof is missed in
+	// a downtime simulation.
+	HostDowntimeObligation struct {
+		ObligationID     types.FileContractID `json:"obligationid"`
+		WindowStart      types.BlockHeight    `json:"windowstart"`
+		WindowEnd        types.BlockHeight    `json:"windowend"`
+		CollateralBurned types.Currency       `json:"collateralburned"`
+		RevenueLost      types.Currency       `json:"revenuelost"`
+	}
+
+	// HostConsistency is the result of cross-checking the sectors referenced
+	// by the host's storage obligations against the sectors held by its
+	// storage manager. Orphaned sectors are held but not referenced by any
+	// obligation, and take up space that can be reclaimed. Missing sectors
+	// are referenced but not held, and put the storage proofs of the
+	// obligations that reference them at risk.
+	HostConsistency struct {
+		CheckTime   types.Timestamp `json:"checktime"`
+		Obligations uint64          `json:"obligations"`
+
+		OrphanedSectors      uint64 `json:"orphanedsectors"`
+		ExcessVirtualSectors uint64 `json:"excessvirtualsectors"`
+		ReclaimableStorage   uint64 `json:"reclaimablestorage"` // bytes
+
+		MissingSectors      uint64               `json:"missingsectors"`
+		UndercountedSectors uint64               `json:"undercountedsectors"`
+		AtRiskContracts     []HostAtRiskContract `json:"atriskcontracts"`
+
+		// CleanedSectors is the number of orphaned sectors that were deleted
+		// by a cleanup before the check.
+		CleanedSectors uint64 `json:"cleanedsectors"`
+	}
+
+	// HostAtRiskContract is a storage obligation that references sectors that
+	// the host does not hold.
+	HostAtRiskContract struct {
+		ObligationID   types.FileContractID `json:"obligationid"`
+		Expiration     types.BlockHeight    `json:"expiration"`
+		MissingSectors uint64               `json:"missingsectors"`
+	}
+
 	// HostInternalSettings contains a list of settings that can be changed.
 	HostInternalSettings struct {
 		AcceptingContracts   bool              `json:"acceptingcontracts"`
@@ -111,7 +263,8 @@ type (
 	// StorageObligation contains information about a storage obligation that
 	// the host has accepted.
 	StorageObligation struct {
-		NegotiationHeight types.BlockHeight `json:"negotiationheight"`
+		ObligationID      types.FileContractID `json:"obligationid"`
+		NegotiationHeight types.BlockHeight    `json:"negotiationheight"`
 
 		OriginConfirmed     bool   `json:"originconfirmed"`
 		RevisionConstructed bool   `json:"revisionconstructed"`
@@ -139,6 +292,16 @@ type (
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// CheckConsistency cross-checks the sectors referenced by the host's
+		// storage obligations against the sectors held by its storage
+		// manager. If cleanup is set, orphaned sectors are deleted and
+		// virtual sector counts are corrected before the check.
+		CheckConsistency(cleanup bool) (HostConsistency, error)
+
+		// Consistency returns the result of the most recent consistency
+		// check. The host runs a check on startup.
+		Consistency() HostConsistency
+
 		// ExternalSettings returns the settings of the host as seen by an
 		// untrusted node querying the host for settings.
 		ExternalSettings() HostExternalSettings
@@ -146,6 +309,11 @@ type (
 		// FinancialMetrics returns the financial statistics of the host.
 		FinancialMetrics() HostFinancialMetrics
 
+		// RevenueHistory returns a daily snapshot of the host's total
+		// revenue, oldest first. Days on which the host was not running are
+		// missing.
+		RevenueHistory() []HostRevenueSnapshot
+
 		// InternalSettings returns the host's internal settings, including
 		// potentially private or sensitive information.
 		InternalSettings() HostInternalSettings
@@ -157,6 +325,11 @@ type (
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
+		// ReindexObligations recomputes whether the contract, revisions and
+		// storage proof of each storage obligation appear in the
+		// blockchain, without rescanning the host.
+		ReindexObligations() error
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
@@ -164,6 +337,20 @@ type (
 		// the host.
 		StorageObligations() []StorageObligation
 
+		// SimulateDowntime computes the collateral and revenue that the host
+		// would forfeit if it were offline between two heights and missed
+		// the given fraction of the storage proofs due in that time.
+		SimulateDowntime(start, end types.BlockHeight, missedFraction float64) (HostDowntimeSimulation, error)
+
+		// StorageObligationUsage returns the usage timeline of a storage
+		// obligation, oldest first. Periods without any transfers are
+		// missing.
+		StorageObligationUsage(types.FileContractID) ([]HostContractUsageSnapshot, error)
+
+		// StorageObligationFailure returns the forensic record of a storage
+		// obligation whose storage proof was missed.
+		StorageObligationFailure(types.FileContractID) (HostObligationFailure, error)
+
 		// ConnectabilityStatus returns the connectability status of the host, that
 		// is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
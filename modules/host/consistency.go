@@ -0,0 +1,143 @@
+package host
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/bolt"
+)
+
+// The host cross-checks the sectors referenced by its storage obligations
+// against the sectors held by the storage manager on startup. A sector can be
+// orphaned if the host crashes between adding a sector and saving the
+// obligation that references it, or if removing a sector fails, and it can go
+// missing if a storage folder is lost or a sector is deleted by hand. The
+// check holds the host lock, so that no obligation is modified while it runs.
+
+// managedCheckConsistency runs a consistency check, first reconciling the
+// storage manager with the storage obligations if cleanup is set, and saves
+// the result.
+func (h *Host) managedCheckConsistency(cleanup bool) (modules.HostConsistency, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Count the references to each sector, and remember the sectors of each
+	// obligation to find the obligations that are at risk.
+	expected := make(map[crypto.Hash]uint16)
+	var obligations []storageObligation
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return err
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				return nil
+			}
+			for _, root := range so.SectorRoots {
+				if expected[root] < math.MaxUint16 {
+					expected[root]++
+				}
+			}
+			obligations = append(obligations, so)
+			return nil
+		})
+	})
+	if err != nil {
+		return modules.HostConsistency{}, build.ExtendErr("unable to read storage obligations:", err)
+	}
+
+	var cleaned uint64
+	if cleanup {
+		cleaned, err = h.ReconcileSectors(expected)
+		if err != nil {
+			h.log.Println("WARN: could not reconcile all sectors:", err)
+		}
+	}
+	check, err := h.CheckSectors(expected)
+	if err != nil {
+		return modules.HostConsistency{}, err
+	}
+
+	missing := make(map[crypto.Hash]struct{}, len(check.Missing))
+	for _, root := range check.Missing {
+		missing[root] = struct{}{}
+	}
+	hc := modules.HostConsistency{
+		CheckTime:   types.CurrentTimestamp(),
+		Obligations: uint64(len(obligations)),
+
+		OrphanedSectors:      check.OrphanedSectors,
+		ExcessVirtualSectors: check.ExcessVirtualSectors,
+		ReclaimableStorage:   check.OrphanedSectors * modules.SectorSize,
+
+		MissingSectors:      uint64(len(check.Missing)),
+		UndercountedSectors: uint64(len(check.Undercounted)),
+		AtRiskContracts:     make([]modules.HostAtRiskContract, 0),
+
+		CleanedSectors: cleaned,
+	}
+	if len(missing) > 0 {
+		for _, so := range obligations {
+			var n uint64
+			for _, root := range so.SectorRoots {
+				if _, ok := missing[root]; ok {
+					n++
+				}
+			}
+			if n > 0 {
+				hc.AtRiskContracts = append(hc.AtRiskContracts, modules.HostAtRiskContract{
+					ObligationID:   so.id(),
+					Expiration:     so.expiration(),
+					MissingSectors: n,
+				})
+			}
+		}
+	}
+	sort.Slice(hc.AtRiskContracts, func(i, j int) bool {
+		return hc.AtRiskContracts[i].Expiration < hc.AtRiskContracts[j].Expiration
+	})
+	h.consistency = hc
+
+	if cleanup || hc.OrphanedSectors > 0 || hc.MissingSectors > 0 || hc.UndercountedSectors > 0 {
+		h.log.Printf("INFO: consistency check found %v orphaned, %v missing and %v undercounted sectors, %v contracts at risk, %v sectors cleaned up",
+			hc.OrphanedSectors, hc.MissingSectors, hc.UndercountedSectors, len(hc.AtRiskContracts), cleaned)
+	}
+	return hc, nil
+}
+
+// threadedCheckConsistency runs the consistency check on startup.
+func (h *Host) threadedCheckConsistency() {
+	if err := h.tg.Add(); err != nil {
+		return
+	}
+	defer h.tg.Done()
+	if _, err := h.managedCheckConsistency(false); err != nil {
+		h.log.Println("WARN: could not check the consistency of the stored sectors:", err)
+	}
+}
+
+// CheckConsistency cross-checks the sectors referenced by the host's storage
+// obligations against the sectors held by the storage manager. If cleanup is
+// set, orphaned sectors are deleted and virtual sector counts are corrected
+// before the check.
+func (h *Host) CheckConsistency(cleanup bool) (modules.HostConsistency, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostConsistency{}, err
+	}
+	defer h.tg.Done()
+	return h.managedCheckConsistency(cleanup)
+}
+
+// Consistency returns the result of the most recent consistency check. The
+// check time is zero if the check that runs on startup has not finished.
+func (h *Host) Consistency() modules.HostConsistency {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.consistency
+}
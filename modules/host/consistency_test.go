@@ -0,0 +1,92 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/bolt"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestCheckConsistency checks that the host reports orphaned sectors and the
+// obligations whose sectors are missing, and that a cleanup deletes the
+// orphaned sectors.
+func TestCheckConsistency(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Add a storage obligation, and make it reference a sector that the host
+	// holds and one that it does not.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	heldData := fastrand.Bytes(int(modules.SectorSize))
+	heldRoot := crypto.MerkleRoot(heldData)
+	orphanData := fastrand.Bytes(int(modules.SectorSize))
+	orphanRoot := crypto.MerkleRoot(orphanData)
+	missingRoot := crypto.MerkleRoot(fastrand.Bytes(int(modules.SectorSize)))
+	if err := ht.host.AddSector(heldRoot, heldData); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.AddSector(orphanRoot, orphanData); err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, so.id())
+		if err != nil {
+			return err
+		}
+		so.SectorRoots = []crypto.Hash{heldRoot, missingRoot}
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hc, err := ht.host.CheckConsistency(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hc.CheckTime == 0 || hc.Obligations != 1 {
+		t.Fatal("unexpected consistency check:", hc)
+	}
+	if hc.OrphanedSectors != 1 || hc.ReclaimableStorage != modules.SectorSize || hc.MissingSectors != 1 {
+		t.Fatal("orphaned and missing sectors were not reported:", hc)
+	}
+	if len(hc.AtRiskContracts) != 1 || hc.AtRiskContracts[0].ObligationID != so.id() || hc.AtRiskContracts[0].MissingSectors != 1 {
+		t.Fatal("obligation with a missing sector was not reported:", hc.AtRiskContracts)
+	}
+
+	hc, err = ht.host.CheckConsistency(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hc.CleanedSectors != 1 || hc.OrphanedSectors != 0 || hc.MissingSectors != 1 {
+		t.Fatal("orphaned sector was not cleaned up:", hc)
+	}
+	if _, err := ht.host.ReadSector(orphanRoot); err == nil {
+		t.Fatal("orphaned sector can still be read")
+	}
+	if _, err := ht.host.ReadSector(heldRoot); err != nil {
+		t.Fatal("referenced sector was deleted:", err)
+	}
+	if ht.host.Consistency().CheckTime != hc.CheckTime {
+		t.Fatal("result of the check was not saved")
+	}
+}
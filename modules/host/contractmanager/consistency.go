@@ -0,0 +1,136 @@
+package contractmanager
+
+import (
+	"sync/atomic"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// expectedSectorIDs returns the sector IDs of the expected sectors, along with
+// the number of virtual sectors expected for each ID.
+func (cm *ContractManager) expectedSectorIDs(expected map[crypto.Hash]uint16) (map[crypto.Hash]sectorID, map[sectorID]uint16) {
+	ids := make(map[crypto.Hash]sectorID, len(expected))
+	counts := make(map[sectorID]uint16, len(expected))
+	for root, count := range expected {
+		id := cm.managedSectorID(root)
+		ids[root] = id
+		counts[id] += count
+	}
+	return ids, counts
+}
+
+// CheckSectors compares the sectors held by the contract manager with
+// 'expected', which maps the root of each sector that the contract manager
+// should hold to the number of virtual sectors it should hold for that root.
+func (cm *ContractManager) CheckSectors(expected map[crypto.Hash]uint16) (modules.SectorCheck, error) {
+	err := cm.tg.Add()
+	if err != nil {
+		return modules.SectorCheck{}, err
+	}
+	defer cm.tg.Done()
+	ids, counts := cm.expectedSectorIDs(expected)
+
+	var check modules.SectorCheck
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	for root, id := range ids {
+		location, exists := cm.sectorLocations[id]
+		if exists {
+			sf, folderExists := cm.storageFolders[location.storageFolder]
+			exists = folderExists && atomic.LoadUint64(&sf.atomicUnavailable) == 0
+		}
+		if !exists {
+			check.Missing = append(check.Missing, root)
+		} else if location.count < counts[id] {
+			check.Undercounted = append(check.Undercounted, root)
+		}
+	}
+	for id, location := range cm.sectorLocations {
+		count, exists := counts[id]
+		if !exists {
+			check.OrphanedSectors++
+		} else if location.count > count {
+			check.ExcessVirtualSectors += uint64(location.count - count)
+		}
+	}
+	return check, nil
+}
+
+// ReconcileSectors makes the sectors held by the contract manager match
+// 'expected'. Sectors that are not expected are deleted, virtual sectors are
+// removed from sectors that are held more often than expected, and virtual
+// sectors are added to sectors that are held less often than expected.
+// Missing sectors cannot be restored. The caller must ensure that no sectors
+// are added or removed during the call, as a sector that is added concurrently
+// may be deleted. The number of physical sectors that were deleted is
+// returned.
+func (cm *ContractManager) ReconcileSectors(expected map[crypto.Hash]uint16) (uint64, error) {
+	err := cm.tg.Add()
+	if err != nil {
+		return 0, err
+	}
+	defer cm.tg.Done()
+	_, counts := cm.expectedSectorIDs(expected)
+
+	// Determine how far the virtual sector count of each sector is from the
+	// expected count. Orphaned sectors are deleted outright.
+	var orphaned, undercounted []sectorID
+	excess := make(map[sectorID]uint16)
+	cm.wal.mu.Lock()
+	for id, location := range cm.sectorLocations {
+		count, exists := counts[id]
+		if !exists {
+			orphaned = append(orphaned, id)
+		} else if location.count > count {
+			excess[id] = location.count - count
+		} else if location.count < count {
+			undercounted = append(undercounted, id)
+		}
+	}
+	cm.wal.mu.Unlock()
+
+	var deleted uint64
+	var errs []error
+	for _, id := range orphaned {
+		cm.wal.managedLockSector(id)
+		err := cm.wal.managedDeleteSector(id)
+		cm.wal.managedUnlockSector(id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted++
+	}
+	for id, n := range excess {
+		cm.wal.managedLockSector(id)
+		for i := uint16(0); i < n; i++ {
+			if err := cm.wal.managedRemoveSector(id); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+		cm.wal.managedUnlockSector(id)
+	}
+	for _, id := range undercounted {
+		cm.wal.managedLockSector(id)
+		for {
+			cm.wal.mu.Lock()
+			location, exists := cm.sectorLocations[id]
+			cm.wal.mu.Unlock()
+			if !exists || location.count >= counts[id] {
+				break
+			}
+			if err := cm.wal.managedAddVirtualSector(id, location); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+		cm.wal.managedUnlockSector(id)
+	}
+	if len(errs) > 0 {
+		return deleted, build.ComposeErrors(errs...)
+	}
+	return deleted, nil
+}
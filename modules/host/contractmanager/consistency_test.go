@@ -0,0 +1,102 @@
+package contractmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestCheckReconcileSectors checks that the contract manager reports orphaned,
+// missing, undercounted and overcounted sectors, and that reconciling the
+// sectors fixes all but the missing ones.
+func TestCheckReconcileSectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add one sector that is held twice but expected once, one that is held
+	// once but expected twice, and one that is not expected at all.
+	overRoot, overData := randSector()
+	underRoot, underData := randSector()
+	orphanRoot, orphanData := randSector()
+	missingRoot, _ := randSector()
+	for _, sector := range []struct {
+		root crypto.Hash
+		data []byte
+	}{{overRoot, overData}, {overRoot, overData}, {underRoot, underData}, {orphanRoot, orphanData}} {
+		if err := cmt.cm.AddSector(sector.root, sector.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := map[crypto.Hash]uint16{
+		overRoot:    1,
+		underRoot:   2,
+		missingRoot: 1,
+	}
+
+	check, err := cmt.cm.CheckSectors(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.OrphanedSectors != 1 || check.ExcessVirtualSectors != 1 {
+		t.Fatal("unexpected orphaned or excess sectors:", check)
+	}
+	if len(check.Missing) != 1 || check.Missing[0] != missingRoot {
+		t.Fatal("missing sector was not reported:", check.Missing)
+	}
+	if len(check.Undercounted) != 1 || check.Undercounted[0] != underRoot {
+		t.Fatal("undercounted sector was not reported:", check.Undercounted)
+	}
+
+	deleted, err := cmt.cm.ReconcileSectors(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatal("expected one orphaned sector to be deleted, got", deleted)
+	}
+	check, err = cmt.cm.CheckSectors(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.OrphanedSectors != 0 || check.ExcessVirtualSectors != 0 || len(check.Undercounted) != 0 || len(check.Missing) != 1 {
+		t.Fatal("sectors were not reconciled:", check)
+	}
+	if _, err := cmt.cm.ReadSector(orphanRoot); err == nil {
+		t.Fatal("orphaned sector was not deleted")
+	}
+
+	// The corrected counts are used when sectors are removed: the sector that
+	// was held once but expected twice survives one removal.
+	if err := cmt.cm.RemoveSector(underRoot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.cm.ReadSector(underRoot); err != nil {
+		t.Fatal("sector was deleted before its last reference was removed:", err)
+	}
+	if err := cmt.cm.RemoveSector(overRoot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.cm.ReadSector(overRoot); err == nil {
+		t.Fatal("excess virtual sectors were not removed")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -38,6 +39,30 @@ func (cmt *contractManagerTester) Close() error {
 	return cmt.cm.Close()
 }
 
+// addStorageFolder adds a storage folder to the contract manager and waits
+// until the storage folder has been initialized.
+func (cmt *contractManagerTester) addStorageFolder(path string, size uint64) error {
+	err := cmt.cm.AddStorageFolder(path, size)
+	if err != nil {
+		return err
+	}
+	for {
+		var found, initializing bool
+		for _, sf := range cmt.cm.StorageFolders() {
+			if sf.Path == path {
+				found = true
+				initializing = sf.Status == modules.StorageFolderStatusInitializing
+			}
+		}
+		if !found {
+			return errors.New("storage folder could not be initialized")
+		} else if !initializing {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // newContractManagerTester returns a ready-to-rock contract manager tester.
 func newContractManagerTester(name string) (*contractManagerTester, error) {
 	if testing.Short() {
@@ -47,7 +47,7 @@ func TestLoadMissingStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -160,7 +160,7 @@ func TestLoadMissingStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -314,7 +314,7 @@ func TestLoadMissingStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity)
+	err = cmt.addStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -495,7 +495,7 @@ func TestFolderRechecker(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -51,7 +51,7 @@ func TestAddSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -196,7 +196,7 @@ func TestAddSectorFillFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -277,7 +277,7 @@ func TestAddSectorFillLargerFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -389,7 +389,7 @@ func TestAddSectorRecovery(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -531,7 +531,7 @@ func TestAddVirtualSectorSerial(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -668,7 +668,7 @@ func TestAddVirtualSectorParallel(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -814,7 +814,7 @@ func TestAddVirtualSectorMassiveParallel(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -955,7 +955,7 @@ func TestRemoveSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1095,7 +1095,7 @@ func TestRemoveSectorVirtual(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1234,7 +1234,7 @@ func TestDeleteSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1374,7 +1374,7 @@ func TestDeleteSectorVirtual(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1517,7 +1517,7 @@ func TestSectorBalancing(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1527,7 +1527,7 @@ func TestSectorBalancing(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir2, modules.SectorSize*64)
+	err = cmt.addStorageFolder(storageFolderDir2, modules.SectorSize*64)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1537,7 +1537,7 @@ func TestSectorBalancing(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir3, modules.SectorSize*64*2)
+	err = cmt.addStorageFolder(storageFolderDir3, modules.SectorSize*64*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1706,7 +1706,7 @@ func TestFailingStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64*2)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1716,7 +1716,7 @@ func TestFailingStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir2, modules.SectorSize*64*2)
+	err = cmt.addStorageFolder(storageFolderDir2, modules.SectorSize*64*2)
 	if err != nil {
 		t.Fatal(err)
 	}
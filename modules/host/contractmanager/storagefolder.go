@@ -66,10 +66,18 @@ var (
 	// factor of 8 sectors.
 	errStorageFolderGranularity = fmt.Errorf("storage folder must be a factor of %v sectors", storageFolderGranularity)
 
+	// errStorageFolderInitStopped is returned if the contract manager shuts
+	// down before a storage folder has been initialized.
+	errStorageFolderInitStopped = errors.New("contract manager shut down before the storage folder was initialized")
+
 	// errStorageFolderNotFolder is returned if a storage folder gets added
 	// that is not a folder.
 	errStorageFolderNotFolder = errors.New("must use an existing folder")
 
+	// errStorageFolderInitializing is returned if an operation is attempted
+	// on a storage folder that is still being initialized.
+	errStorageFolderInitializing = errors.New("storage folder is still being initialized")
+
 	// errStorageFolderNotFound is returned if a storage folder cannot be
 	// found.
 	errStorageFolderNotFound = errors.New("could not find storage folder with that id")
@@ -98,6 +106,11 @@ type storageFolder struct {
 	// an error if it is queried.
 	atomicUnavailable uint64 // uint64 for alignment
 
+	// Atomic bool indicating whether or not the storage folder is still being
+	// initialized. Initializing storage folders are not synced by the WAL and
+	// do not receive new sectors until their files have been allocated.
+	atomicInitializing uint64
+
 	// The index, path, and usage are all saved directly to disk.
 	index uint16
 	path  string
@@ -265,12 +278,13 @@ func (sf *storageFolder) setUsage(sectorIndex uint32) {
 }
 
 // availableStorageFolders returns the contract manager's storage folders as a
-// slice, excluding any unavailable storeage folders.
+// slice, excluding any unavailable or initializing storeage folders.
 func (cm *ContractManager) availableStorageFolders() []*storageFolder {
 	sfs := make([]*storageFolder, 0)
 	for _, sf := range cm.storageFolders {
-		// Skip unavailable storage folders.
-		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		// Skip unavailable storage folders, and storage folders that are not
+		// yet ready to receive sectors.
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 || atomic.LoadUint64(&sf.atomicInitializing) == 1 {
 			continue
 		}
 		sfs = append(sfs, sf)
@@ -367,6 +381,9 @@ func (cm *ContractManager) ResizeStorageFolder(index uint16, newSize uint64, for
 	if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
 		return errStorageFolderNotFound
 	}
+	if atomic.LoadUint64(&sf.atomicInitializing) == 1 {
+		return errStorageFolderInitializing
+	}
 
 	if newSize/modules.SectorSize < MinimumSectorsPerStorageFolder {
 		return ErrSmallStorageFolder
@@ -416,6 +433,8 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 			CapacityRemaining: ((64 * uint64(len(sf.usage))) - sf.sectors) * modules.SectorSize,
 			Index:             sf.index,
 			Path:              sf.path,
+
+			Status: modules.StorageFolderStatusReady,
 		}
 
 		// Set some of the values to extreme numbers if the storage folder is
@@ -423,6 +442,9 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
 			sfm.FailedReads = 9999999999
 			sfm.FailedWrites = 9999999999
+			sfm.Status = modules.StorageFolderStatusUnavailable
+		} else if atomic.LoadUint64(&sf.atomicInitializing) == 1 {
+			sfm.Status = modules.StorageFolderStatusInitializing
 		}
 
 		// Add this storage folder to the list of storage folders.
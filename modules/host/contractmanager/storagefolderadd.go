@@ -87,15 +87,17 @@ func (wal *writeAheadLog) cleanupUnfinishedStorageFolderAdditions(scs []stateCha
 // any error checking that can be performed without accessing the contract
 // manager state.
 //
-// managedAddStorageFolder can take a long time, as it writes a giant, zeroed
-// out file to disk covering the entire range of the storage folder, and
-// failure can occur late in the operation. The WAL is notified that a long
-// running operation is in progress, so that any changes to disk can be
-// reverted in the event of unclean shutdown.
+// managedAddStorageFolder only registers the storage folder and creates its
+// files. Allocating the files can take a long time, as it writes a giant,
+// zeroed out file to disk covering the entire range of the storage folder, so
+// the allocation is performed in the background by
+// threadedInitializeStorageFolder. The WAL is notified that a long running
+// operation is in progress, so that any changes to disk can be reverted in the
+// event of unclean shutdown.
 func (wal *writeAheadLog) managedAddStorageFolder(sf *storageFolder) error {
-	// Lock the storage folder for the duration of the function.
+	// Lock the storage folder until it has been initialized. The lock is
+	// released by threadedInitializeStorageFolder.
 	sf.mu.Lock()
-	defer sf.mu.Unlock()
 
 	numSectors := uint64(len(sf.usage)) * 64
 	sectorLookupSize := numSectors * sectorMetadataDiskSize
@@ -165,7 +167,10 @@ func (wal *writeAheadLog) managedAddStorageFolder(sf *storageFolder) error {
 		// folder.
 		atomic.StoreUint64(&sf.atomicProgressDenominator, totalSize)
 
-		// Add the storage folder to the list of storage folders.
+		// Add the storage folder to the list of storage folders. The storage
+		// folder is marked as initializing so that it is not used for new
+		// sectors or synced by the WAL until its files have been allocated.
+		atomic.StoreUint64(&sf.atomicInitializing, 1)
 		wal.cm.storageFolders[index] = sf
 
 		// Add the storage folder to the list of unfinished storage folder
@@ -181,15 +186,54 @@ func (wal *writeAheadLog) managedAddStorageFolder(sf *storageFolder) error {
 		return nil
 	}()
 	if err != nil {
+		sf.mu.Unlock()
 		return err
 	}
 	// Block until the commitment to the unfinished storage folder addition is
 	// complete.
 	<-syncChan
 
+	// Hand the storage folder off to a background thread which will allocate
+	// its files. If the contract manager is shutting down, the unfinished
+	// addition will be cleaned up when the WAL is loaded at startup.
+	err = wal.cm.tg.Add()
+	if err != nil {
+		sf.mu.Unlock()
+		return err
+	}
+	go wal.threadedInitializeStorageFolder(sf)
+	return nil
+}
+
+// threadedInitializeStorageFolder allocates the files of a storage folder that
+// has been registered by managedAddStorageFolder, and then commits the
+// addition of the storage folder through the WAL. The storage folder is only
+// used for new sectors once the addition has been committed. The caller must
+// hold a lock on the storage folder, which is released when initialization
+// completes, and must have called tg.Add.
+func (wal *writeAheadLog) threadedInitializeStorageFolder(sf *storageFolder) {
+	defer wal.cm.tg.Done()
+
+	// The storage folder is only marked as ready once its lock has been
+	// released, so that it is immediately available for new sectors.
+	var initialized bool
+	defer func() {
+		sf.mu.Unlock()
+		if initialized {
+			atomic.StoreUint64(&sf.atomicInitializing, 0)
+		}
+	}()
+
+	numSectors := uint64(len(sf.usage)) * 64
+	sectorLookupSize := numSectors * sectorMetadataDiskSize
+	sectorHousingSize := numSectors * modules.SectorSize
+	totalSize := sectorLookupSize + sectorHousingSize
+	sectorLookupName := filepath.Join(sf.path, metadataFile)
+	sectorHousingName := filepath.Join(sf.path, sectorFile)
+
 	// Simulate a disk failure at this point.
 	if wal.cm.dependencies.disrupt("storageFolderAddFinish") {
-		return nil
+		return
 	}
 
 	// If there's an error in the rest of the function, the storage folder
@@ -197,6 +241,7 @@ func (wal *writeAheadLog) managedAddStorageFolder(sf *storageFolder) error {
 	// additions. Because the WAL is append-only, a stateChange needs to be
 	// appended which indicates that the storage folder was unable to be added
 	// successfully.
+	var err error
 	defer func(sf *storageFolder) {
 		if err != nil {
 			wal.mu.Lock()
@@ -216,28 +261,40 @@ func (wal *writeAheadLog) managedAddStorageFolder(sf *storageFolder) error {
 			wal.appendChange(stateChange{
 				ErroredStorageFolderAdditions: []uint16{sf.index},
 			})
+			wal.cm.log.Println("Unable to initialize storage folder", sf.path+":", err)
 		}
 	}(sf)
 
 	// Allocate the files on disk for the storage folder.
 	stepCount := sectorHousingSize / folderAllocationStepSize
 	for i := uint64(0); i < stepCount; i++ {
+		// Abort the initialization if the contract manager is shutting down.
+		select {
+		case <-wal.cm.tg.StopChan():
+			err = errStorageFolderInitStopped
+			return
+		default:
+		}
+
 		err = sf.sectorFile.Truncate(int64(folderAllocationStepSize * (i + 1)))
 		if err != nil {
-			return build.ExtendErr("could not allocate storage folder", err)
+			err = build.ExtendErr("could not allocate storage folder", err)
+			return
 		}
 		// After each iteration, update the progress numerator.
 		atomic.AddUint64(&sf.atomicProgressNumerator, folderAllocationStepSize)
 	}
 	err = sf.sectorFile.Truncate(int64(sectorHousingSize))
 	if err != nil {
-		return build.ExtendErr("could not allocate sector data file", err)
+		err = build.ExtendErr("could not allocate sector data file", err)
+		return
 	}
 
 	// Write the metadata file.
 	err = sf.metadataFile.Truncate(int64(sectorLookupSize))
 	if err != nil {
-		return build.ExtendErr("could not allocate sector metadata file", err)
+		err = build.ExtendErr("could not allocate sector metadata file", err)
+		return
 	}
 
 	// The file creation process is essentially complete at this point, report
@@ -268,17 +325,18 @@ func (wal *writeAheadLog) managedAddStorageFolder(sf *storageFolder) error {
 
 	// Simulate power failure at this point for some testing scenarios.
 	if wal.cm.dependencies.disrupt("incompleteAddStorageFolder") {
-		return nil
+		return
 	}
 
 	// Storage folder addition has completed successfully, commit the addition
-	// through the WAL.
+	// through the WAL. The storage folder is ready to receive sectors once the
+	// addition has been committed.
 	wal.mu.Lock()
 	wal.cm.storageFolders[sf.index] = sf
 	wal.appendChange(stateChange{
 		StorageFolderAdditions: []savedStorageFolder{sf.savedStorageFolder()},
 	})
-	syncChan = wal.syncChan
+	syncChan := wal.syncChan
 	wal.mu.Unlock()
 
 	// Wait to confirm the storage folder addition has completed until the WAL
@@ -288,7 +346,7 @@ func (wal *writeAheadLog) managedAddStorageFolder(sf *storageFolder) error {
 	// Set the progress back to '0'.
 	atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
 	atomic.StoreUint64(&sf.atomicProgressDenominator, 0)
-	return nil
+	initialized = true
 }
 
 // commitAddStorageFolder integrates a pending AddStorageFolder call into the
@@ -327,7 +385,11 @@ func (wal *writeAheadLog) commitAddStorageFolder(ssf savedStorageFolder) {
 	wal.cm.storageFolders[sf.index] = sf
 }
 
-// AddStorageFolder adds a storage folder to the contract manager.
+// AddStorageFolder adds a storage folder to the contract manager. The storage
+// folder is initialized in the background, and AddStorageFolder returns as
+// soon as the storage folder has been registered. Until initialization
+// completes, the storage folder is reported with an initializing status and
+// does not receive any sectors.
 func (cm *ContractManager) AddStorageFolder(path string, size uint64) error {
 	err := cm.tg.Add()
 	if err != nil {
@@ -33,7 +33,7 @@ func TestAddStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -135,7 +135,7 @@ func TestAddLargeStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	addErr := cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*16) // Total size must exceed the limit of the limitFile.
+	addErr := cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*16) // Total size must exceed the limit of the limitFile.
 	// Should be a storage folder error, but with all the context adding, I'm
 	// not sure how to check the error type.
 	if addErr == nil {
@@ -200,21 +200,21 @@ func TestAddStorageFolderConcurrent(t *testing.T) {
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		err := cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+		err := cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 		if err != nil {
 			t.Fatal(err)
 		}
 	}()
 	go func() {
 		defer wg.Done()
-		err := cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*8)
+		err := cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*8)
 		if err != nil {
 			t.Fatal(err)
 		}
 	}()
 	go func() {
 		defer wg.Done()
-		err = cmt.cm.AddStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity*8)
+		err = cmt.addStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity*8)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -325,7 +325,7 @@ func TestAddStorageFolderBlocking(t *testing.T) {
 	// on the underlying file.
 	sfOneSize := modules.SectorSize * storageFolderGranularity * 8
 	go func() {
-		err := cmt.cm.AddStorageFolder(storageFolderOne, sfOneSize)
+		err := cmt.addStorageFolder(storageFolderOne, sfOneSize)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -355,14 +355,14 @@ func TestAddStorageFolderBlocking(t *testing.T) {
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		err := cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*8)
+		err := cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*8)
 		if err != nil {
 			t.Fatal(err)
 		}
 	}()
 	go func() {
 		defer wg.Done()
-		err = cmt.cm.AddStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity*8)
+		err = cmt.addStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity*8)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -385,6 +385,83 @@ func TestAddStorageFolderBlocking(t *testing.T) {
 	}
 }
 
+// TestAddStorageFolderInitializing checks that a storage folder which is still
+// being initialized is reported as initializing, and does not receive sectors
+// or block the other storage folders.
+func TestAddStorageFolderInitializing(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := &dependencyBlockSFOne{
+		blockLifted: make(chan struct{}),
+		writeCalled: make(chan struct{}),
+	}
+	cmt, err := newMockedContractManagerTester(d, "TestAddStorageFolderInitializing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	err = os.MkdirAll(storageFolderOne, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// AddStorageFolder should return while the storage folder is still being
+	// initialized.
+	sfSize := modules.SectorSize * storageFolderGranularity * 8
+	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-time.After(time.Second * 5):
+		t.Fatal("storage folder not written out")
+	case <-d.writeCalled:
+	}
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Status != modules.StorageFolderStatusInitializing {
+		t.Fatal("storage folder should be reported as initializing:", sfs)
+	}
+
+	// The initializing storage folder should not be used for new sectors.
+	root, data := randSector()
+	err = cmt.cm.AddSector(root, data)
+	if err != errInsufficientStorageForSector {
+		t.Fatal("expected errInsufficientStorageForSector, got", err)
+	}
+	// Other storage folders can be added and used in the meantime.
+	err = cmt.addStorageFolder(storageFolderTwo, sfSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddSector(root, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Path == storageFolderOne && sf.CapacityRemaining != sf.Capacity {
+			t.Error("sector was placed in an initializing storage folder")
+		}
+	}
+
+	// Once initialized, the storage folder should be ready.
+	close(d.blockLifted)
+	cmt.cm.tg.Flush()
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Status != modules.StorageFolderStatusReady {
+			t.Error("storage folder should be ready:", sf.Path, sf.Status)
+		}
+	}
+}
+
 // TestAddStorageFolderConsecutive adds multiple storage folders consecutively
 // to the contract manager, blocking on the first one to make sure that the
 // others are still allowed to complete.
@@ -421,15 +498,15 @@ func TestAddStorageFolderConsecutive(t *testing.T) {
 	// Spin off the first goroutine, and then wait until write has been called
 	// on the underlying file.
 	sfSize := modules.SectorSize * storageFolderGranularity * 8
-	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
+	err = cmt.addStorageFolder(storageFolderOne, sfSize)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, sfSize)
+	err = cmt.addStorageFolder(storageFolderTwo, sfSize)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderThree, sfSize)
+	err = cmt.addStorageFolder(storageFolderThree, sfSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -474,11 +551,11 @@ func TestAddStorageFolderDoubleAdd(t *testing.T) {
 	// is used in each. The errors are not checked because one of the storage
 	// folders will succeed, but it's uncertain which one.
 	sfSize := modules.SectorSize * storageFolderGranularity * 8
-	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
+	err = cmt.addStorageFolder(storageFolderOne, sfSize)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize*2)
+	err = cmt.addStorageFolder(storageFolderOne, sfSize*2)
 	if err != ErrRepeatFolder {
 		t.Fatal(err)
 	}
@@ -568,11 +645,11 @@ func TestAddStorageFolderDoubleAddNoCommit(t *testing.T) {
 	// is used in each. The errors are not checked because one of the storage
 	// folders will succeed, but it's uncertain which one.
 	sfSize := modules.SectorSize * storageFolderGranularity * 8
-	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
+	err = cmt.addStorageFolder(storageFolderOne, sfSize)
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize*2)
+	err = cmt.addStorageFolder(storageFolderOne, sfSize*2)
 	if err != ErrRepeatFolder {
 		t.Fatal(err)
 	}
@@ -613,7 +690,7 @@ func TestAddStorageFolderFailedCommit(t *testing.T) {
 		t.Fatal(err)
 	}
 	sfSize := modules.SectorSize * storageFolderGranularity * 8
-	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
+	err = cmt.addStorageFolder(storageFolderOne, sfSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -692,7 +769,7 @@ func TestAddStorageFolderUnfinishedCreate(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Call AddStorageFolder, knowing that the changes will not be properly
-	// committed, and that the call itself will not actually complete.
+	// committed, and that the initialization will not actually complete.
 	sfSize := modules.SectorSize * storageFolderGranularity * 8
 	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
 	if err != nil {
@@ -767,15 +844,15 @@ func TestAddStorageFolderDoubleAddConcurrent(t *testing.T) {
 	sfSize := modules.SectorSize * storageFolderGranularity * 8
 	wg.Add(3)
 	go func() {
-		_ = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
+		_ = cmt.addStorageFolder(storageFolderOne, sfSize)
 		wg.Done()
 	}()
 	go func() {
-		_ = cmt.cm.AddStorageFolder(storageFolderOne, sfSize*2)
+		_ = cmt.addStorageFolder(storageFolderOne, sfSize*2)
 		wg.Done()
 	}()
 	go func() {
-		_ = cmt.cm.AddStorageFolder(storageFolderOne, sfSize*3)
+		_ = cmt.addStorageFolder(storageFolderOne, sfSize*3)
 		wg.Done()
 	}()
 	wg.Wait()
@@ -817,7 +894,7 @@ func TestAddStorageFolderReload(t *testing.T) {
 		t.Fatal(err)
 	}
 	sfSize := modules.SectorSize * storageFolderGranularity * 24
-	err = cmt.cm.AddStorageFolder(storageFolderOne, sfSize)
+	err = cmt.addStorageFolder(storageFolderOne, sfSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -30,7 +30,7 @@ func TestGrowStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -255,7 +255,7 @@ func TestGrowStorageFolderIncompleteWrite(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -378,7 +378,7 @@ func TestGrowStorageFolderShutdownAfterWrite(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -478,7 +478,7 @@ func TestGrowStorageFolderWAL(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
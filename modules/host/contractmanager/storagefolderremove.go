@@ -2,6 +2,7 @@ package contractmanager
 
 import (
 	"path/filepath"
+	"sync/atomic"
 )
 
 type (
@@ -59,6 +60,9 @@ func (cm *ContractManager) RemoveStorageFolder(index uint16, force bool) error {
 		return errStorageFolderNotFound
 	}
 	cm.wal.mu.Unlock()
+	if atomic.LoadUint64(&sf.atomicInitializing) == 1 {
+		return errStorageFolderInitializing
+	}
 
 	// Lock the storage folder for the duration of the operation.
 	sf.mu.Lock()
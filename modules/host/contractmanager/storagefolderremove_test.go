@@ -31,7 +31,7 @@ func TestRemoveStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -99,7 +99,7 @@ func TestRemoveStorageFolderWithSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -133,7 +133,7 @@ func TestRemoveStorageFolderWithSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*2)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -221,7 +221,7 @@ func TestRemoveStorageFolderConcurrentAddSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -229,7 +229,7 @@ func TestRemoveStorageFolderConcurrentAddSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*15)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*15)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -238,7 +238,7 @@ func TestRemoveStorageFolderConcurrentAddSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity*25)
+	err = cmt.addStorageFolder(storageFolderThree, modules.SectorSize*storageFolderGranularity*25)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -284,7 +284,7 @@ func TestRemoveStorageFolderConcurrentAddSector(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderFour, modules.SectorSize*storageFolderGranularity*50)
+	err = cmt.addStorageFolder(storageFolderFour, modules.SectorSize*storageFolderGranularity*50)
 	if err != nil {
 		t.Fatal(err)
 	}
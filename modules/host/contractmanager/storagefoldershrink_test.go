@@ -32,7 +32,7 @@ func TestShrinkStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -142,7 +142,7 @@ func TestShrinkStorageFolderWithSectors(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -205,7 +205,7 @@ func TestShrinkStorageFolderWithSectors(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -348,7 +348,7 @@ func TestShrinkStorageFolderIncompleteWrite(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -390,7 +390,7 @@ func TestShrinkStorageFolderIncompleteWrite(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -526,7 +526,7 @@ func TestShrinkStorageFolderIncompleteWriteForce(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -568,7 +568,7 @@ func TestShrinkStorageFolderIncompleteWriteForce(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -739,7 +739,7 @@ func TestShrinkStorageFolderShutdownAfterMove(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -802,7 +802,7 @@ func TestShrinkStorageFolderShutdownAfterMove(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -948,7 +948,7 @@ func TestShrinkStorageFolderWAL(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1011,7 +1011,7 @@ func TestShrinkStorageFolderWAL(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
+	err = cmt.addStorageFolder(storageFolderTwo, modules.SectorSize*storageFolderGranularity*3)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1162,7 +1162,7 @@ func TestShrinkSingleStorageFolder(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
+	err = cmt.addStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*8)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -7,11 +7,21 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/persist"
 )
 
+var (
+	// atomicRecoveryApplied and atomicRecoveryTotal track the progress of the
+	// most recent WAL recovery. They are kept at the package level so that
+	// progress can be reported while the contract manager is still starting
+	// up.
+	atomicRecoveryApplied uint64
+	atomicRecoveryTotal   uint64
+)
+
 type (
 	// sectorUpdate is an idempotent update to the sector metadata.
 	sectorUpdate struct {
@@ -220,20 +230,15 @@ func (wal *writeAheadLog) recoverWAL(walFile file) error {
 		return build.ExtendErr("walFile metadata mismatch", err)
 	}
 
-	// Read changes from the WAL one at a time and load them back into memory.
-	// A full list of changes is kept so that modifications to long running
-	// changes can be parsed properly.
-	var sc stateChange
+	// Read changes from the WAL one at a time and load them into memory. A
+	// full list of changes is kept so that modifications to long running
+	// changes can be parsed properly, and so that the changes can be applied
+	// in parallel once they have all been read.
 	var scs []stateChange
 	for err == nil {
+		var sc stateChange
 		err = decoder.Decode(&sc)
 		if err == nil {
-			// The uncommitted changes are loaded into memory using a simple
-			// append, because the tmp WAL file has not been created yet, and
-			// will not be created until the sync loop is spawned. The sync
-			// loop spawner will make sure that the uncommitted changes are
-			// written to the tmp WAL file.
-			wal.commitChange(sc)
 			scs = append(scs, sc)
 		}
 	}
@@ -242,6 +247,12 @@ func (wal *writeAheadLog) recoverWAL(walFile file) error {
 		return build.ExtendErr("error loading WAL json", err)
 	}
 
+	// Re-commit the changes. The uncommitted changes are applied directly,
+	// because the tmp WAL file has not been created yet, and will not be
+	// created until the sync loop is spawned. The sync loop spawner will make
+	// sure that the uncommitted changes are written to the tmp WAL file.
+	wal.recommitChanges(scs)
+
 	// Do any cleanup regarding long-running unfinished tasks. Long running
 	// task cleanup cannot be handled in the 'commitChange' loop because future
 	// state changes may indicate that the long running task has actually been
@@ -251,6 +262,99 @@ func (wal *writeAheadLog) recoverWAL(walFile file) error {
 	return nil
 }
 
+// recommitChanges will commit a list of changes that were recovered from the
+// WAL. Changes to storage folders are applied serially, in order. Sector
+// updates between two storage folder changes are partitioned by storage
+// folder and each partition is applied in its own thread, which is safe
+// because sector updates in different storage folders touch different files
+// and different usage fields. The order of the updates within a storage folder
+// is preserved.
+func (wal *writeAheadLog) recommitChanges(scs []stateChange) {
+	var total uint64
+	for _, sc := range scs {
+		total += uint64(len(sc.StorageFolderAdditions) + len(sc.StorageFolderExtensions) + len(sc.StorageFolderReductions) + len(sc.StorageFolderRemovals) + len(sc.SectorUpdates))
+	}
+	atomic.StoreUint64(&atomicRecoveryApplied, 0)
+	atomic.StoreUint64(&atomicRecoveryTotal, total)
+
+	var sus []sectorUpdate
+	for _, sc := range scs {
+		folderChanges := len(sc.StorageFolderAdditions) + len(sc.StorageFolderExtensions) + len(sc.StorageFolderReductions) + len(sc.StorageFolderRemovals)
+		if folderChanges > 0 {
+			// Storage folder changes can change the set of sectors that
+			// the pending sector updates refer to, so the pending sector
+			// updates must be applied first.
+			wal.recommitSectorUpdates(sus)
+			sus = nil
+
+			// commitChange applies storage folder changes before sector
+			// updates, so the sector updates of this change can be
+			// batched with the sector updates of the changes that follow.
+			folderChange := sc
+			folderChange.SectorUpdates = nil
+			wal.commitChange(folderChange)
+			atomic.AddUint64(&atomicRecoveryApplied, uint64(folderChanges))
+		}
+		sus = append(sus, sc.SectorUpdates...)
+	}
+	wal.recommitSectorUpdates(sus)
+}
+
+// recommitSectorUpdates will commit a list of sector updates, applying the
+// updates for each storage folder in parallel. Once all of the updates have
+// been applied, a consistency pass checks that the usage of every sector
+// matches the final update for that sector, reapplying the final update if it
+// does not.
+func (wal *writeAheadLog) recommitSectorUpdates(sus []sectorUpdate) {
+	// Partition the updates by storage folder, preserving order.
+	partitions := make(map[uint16][]sectorUpdate)
+	for _, su := range sus {
+		partitions[su.Folder] = append(partitions[su.Folder], su)
+	}
+
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		wg.Add(1)
+		go func(partition []sectorUpdate) {
+			defer wg.Done()
+			for _, su := range partition {
+				for i := uint64(0); i < wal.cm.dependencies.atLeastOne(); i++ {
+					wal.commitUpdateSector(su)
+				}
+				atomic.AddUint64(&atomicRecoveryApplied, 1)
+			}
+		}(partition)
+	}
+	wg.Wait()
+
+	// Consistency pass. Only the final update to each sector matters, as the
+	// updates are idempotent.
+	final := make(map[uint16]map[uint32]sectorUpdate)
+	for _, su := range sus {
+		if final[su.Folder] == nil {
+			final[su.Folder] = make(map[uint32]sectorUpdate)
+		}
+		final[su.Folder][su.Index] = su
+	}
+	var repaired int
+	for folder, updates := range final {
+		sf, exists := wal.cm.storageFolders[folder]
+		if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			continue
+		}
+		for index, su := range updates {
+			used := sf.usage[index/storageFolderGranularity]&(1<<(index%storageFolderGranularity)) != 0
+			if used != (su.Count > 0) {
+				wal.commitUpdateSector(su)
+				repaired++
+			}
+		}
+	}
+	if repaired > 0 {
+		wal.cm.log.Printf("WARN: consistency pass repaired %v sectors after WAL recovery\n", repaired)
+	}
+}
+
 // load will pull any changes from the uncommitted WAL into memory, decoding
 // them and doing any necessary preprocessing. In the most common case (any
 // time the previous shutdown was clean), there will not be a WAL file.
@@ -334,3 +438,10 @@ func (wal *writeAheadLog) load() error {
 	}
 	return nil
 }
+
+// RecoveryProgress returns the number of changes that have been applied and
+// the total number of changes found during the most recent WAL recovery. Both
+// values are zero if no recovery has been performed.
+func RecoveryProgress() (applied, total uint64) {
+	return atomic.LoadUint64(&atomicRecoveryApplied), atomic.LoadUint64(&atomicRecoveryTotal)
+}
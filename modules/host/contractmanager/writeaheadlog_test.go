@@ -0,0 +1,101 @@
+package contractmanager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestParallelWALRecovery checks that sector updates spread across multiple
+// storage folders are recovered correctly when the WAL is replayed after an
+// unclean shutdown.
+func TestParallelWALRecovery(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyNoSettingsSave)
+	cmt, err := newMockedContractManagerTester(d, "TestParallelWALRecovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add three storage folders to the contract manager tester.
+	for _, name := range []string{"sfOne", "sfTwo", "sfThree"} {
+		storageFolderDir := filepath.Join(cmt.persistDir, name)
+		err = os.MkdirAll(storageFolderDir, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cmt.addStorageFolder(storageFolderDir, modules.SectorSize*64)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Add enough sectors that every storage folder receives some of them.
+	sectors := make(map[crypto.Hash][]byte)
+	for i := 0; i < 24; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sectors[root] = data
+	}
+	// Remove one of the sectors, so that the WAL contains updates that clear
+	// a sector as well.
+	for root := range sectors {
+		err = cmt.cm.RemoveSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		delete(sectors, root)
+		break
+	}
+	// Prevent the settings from being saved, so that the changes are only
+	// recorded in the WAL.
+	d.mu.Lock()
+	d.triggered = true
+	d.mu.Unlock()
+
+	// Reload the contract manager, forcing a recovery from the WAL.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, total := RecoveryProgress()
+	if total == 0 || applied < total {
+		t.Error("recovery progress was not reported correctly:", applied, total)
+	}
+
+	// Check that the sectors were all recovered.
+	if len(cmt.cm.sectorLocations) != len(sectors) {
+		t.Fatal("wrong number of sectors after recovery:", len(cmt.cm.sectorLocations), len(sectors))
+	}
+	var used uint64
+	for _, sf := range cmt.cm.storageFolders {
+		used += sf.sectors
+	}
+	if used != uint64(len(sectors)) {
+		t.Error("usage was not recovered correctly:", used, len(sectors))
+	}
+	for root, data := range sectors {
+		sectorData, err := cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sectorData, data) {
+			t.Fatal("sector data does not match after recovery")
+		}
+	}
+}
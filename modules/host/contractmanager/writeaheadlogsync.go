@@ -53,8 +53,11 @@ func (wal *writeAheadLog) syncResources() {
 
 	// Sync all of the storage folders.
 	for _, sf := range wal.cm.storageFolders {
-		// Skip operation on unavailable storage folders.
-		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		// Skip operation on unavailable storage folders. Storage folders that
+		// are being initialized are also skipped, as syncing them would block
+		// the WAL until the allocation of their files has completed. They are
+		// synced once initialization completes.
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 || atomic.LoadUint64(&sf.atomicInitializing) == 1 {
 			continue
 		}
 
@@ -257,6 +260,7 @@ func (wal *writeAheadLog) spawnSyncLoop() (err error) {
 // transactions to the contract manager are batched automatically and
 // occasionally committed together.
 func (wal *writeAheadLog) threadedSyncLoop(threadsStopped chan struct{}, syncLoopStopped chan struct{}) {
+	defer build.CaptureCrash("host")
 	// Provide a place for the testing to disable the sync loop.
 	if wal.cm.dependencies.disrupt("threadedSyncLoopStart") {
 		close(syncLoopStopped)
@@ -0,0 +1,101 @@
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	errDowntimeRange  = errors.New("downtime must end after it starts")
+	errMissedFraction = errors.New("fraction of missed proofs must be between 0 and 1")
+)
+
+// downtimeLoss returns the collateral and revenue that the host forfeits if it
+// misses the storage proof of the obligation. The loss is the difference
+// between the host's valid and missed proof outputs, of which the risked
+// collateral is burned and the rest is revenue.
+func (so storageObligation) downtimeLoss() (collateral, revenue types.Currency) {
+	valid, missed := so.payouts()
+	if valid[1].Value.Cmp(missed[1].Value) <= 0 {
+		return types.ZeroCurrency, types.ZeroCurrency
+	}
+	lost := valid[1].Value.Sub(missed[1].Value)
+	collateral = so.RiskedCollateral
+	if collateral.Cmp(lost) > 0 {
+		collateral = lost
+	}
+	return collateral, lost.Sub(collateral)
+}
+
+// SimulateDowntime computes the worst-case cost of the host being offline
+// between the start and end heights. Every unresolved storage obligation whose
+// proof window overlaps the downtime is at risk, as the host may be unable to
+// submit its proof, and missedFraction of them are assumed to be missed,
+// starting with the obligations that would forfeit the most.
+func (h *Host) SimulateDowntime(start, end types.BlockHeight, missedFraction float64) (modules.HostDowntimeSimulation, error) {
+	if end <= start {
+		return modules.HostDowntimeSimulation{}, errDowntimeRange
+	}
+	if !(missedFraction >= 0 && missedFraction <= 1) {
+		return modules.HostDowntimeSimulation{}, errMissedFraction
+	}
+	if err := h.tg.Add(); err != nil {
+		return modules.HostDowntimeSimulation{}, err
+	}
+	defer h.tg.Done()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var atRisk []modules.HostDowntimeObligation
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return err
+			}
+			if so.ObligationStatus != obligationUnresolved || so.ProofConfirmed {
+				return nil
+			}
+			windowStart, windowEnd := so.expiration(), so.proofDeadline()
+			if windowStart >= end || windowEnd <= start {
+				return nil
+			}
+			collateral, revenue := so.downtimeLoss()
+			atRisk = append(atRisk, modules.HostDowntimeObligation{
+				ObligationID:     so.id(),
+				WindowStart:      windowStart,
+				WindowEnd:        windowEnd,
+				CollateralBurned: collateral,
+				RevenueLost:      revenue,
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return modules.HostDowntimeSimulation{}, err
+	}
+
+	// Assume that the most costly proofs are the ones missed.
+	sort.Slice(atRisk, func(i, j int) bool {
+		return atRisk[i].CollateralBurned.Add(atRisk[i].RevenueLost).Cmp(atRisk[j].CollateralBurned.Add(atRisk[j].RevenueLost)) > 0
+	})
+	sim := modules.HostDowntimeSimulation{
+		StartHeight:  start,
+		EndHeight:    end,
+		ProofsAtRisk: len(atRisk),
+		ProofsMissed: int(math.Ceil(missedFraction * float64(len(atRisk)))),
+	}
+	sim.Obligations = atRisk[:sim.ProofsMissed]
+	for _, o := range sim.Obligations {
+		sim.CollateralBurned = sim.CollateralBurned.Add(o.CollateralBurned)
+		sim.RevenueLost = sim.RevenueLost.Add(o.RevenueLost)
+	}
+	return sim, nil
+}
@@ -0,0 +1,104 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// downtimeTestObligation returns a storage obligation with the provided proof
+// window, in which the host earns revenue plus collateral for a valid proof
+// and loses both for a missed proof.
+func downtimeTestObligation(windowStart, windowEnd types.BlockHeight, revenue, collateral uint64) storageObligation {
+	fc := types.FileContract{
+		WindowStart: windowStart,
+		WindowEnd:   windowEnd,
+		ValidProofOutputs: []types.SiacoinOutput{
+			{Value: types.NewCurrency64(1)},
+			{Value: types.NewCurrency64(revenue + collateral + 10)},
+		},
+		MissedProofOutputs: []types.SiacoinOutput{
+			{Value: types.NewCurrency64(1)},
+			{Value: types.NewCurrency64(10)},
+		},
+		// Distinguish the ids of the obligations.
+		RevisionNumber: uint64(windowStart),
+	}
+	return storageObligation{
+		OriginTransactionSet: []types.Transaction{{FileContracts: []types.FileContract{fc}}},
+		RiskedCollateral:     types.NewCurrency64(collateral),
+	}
+}
+
+// TestSimulateDowntime checks that a downtime simulation counts the worst-case
+// losses of the storage proofs due during the downtime.
+func TestSimulateDowntime(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	early := downtimeTestObligation(10, 20, 100, 50)
+	late := downtimeTestObligation(40, 50, 300, 500)
+	after := downtimeTestObligation(70, 80, 1000, 1000)
+	proven := downtimeTestObligation(30, 40, 1000, 1000)
+	proven.ProofConfirmed = true
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		for _, so := range []storageObligation{early, late, after, proven} {
+			if err := putStorageObligation(tx, so); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Downtime from 15 to 45 puts the early and late proofs at risk.
+	sim, err := ht.host.SimulateDowntime(15, 45, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.ProofsAtRisk != 2 || sim.ProofsMissed != 2 {
+		t.Fatalf("expected 2 proofs at risk and missed, got %v and %v", sim.ProofsAtRisk, sim.ProofsMissed)
+	}
+	if !sim.CollateralBurned.Equals64(550) || !sim.RevenueLost.Equals64(400) {
+		t.Errorf("expected 550 collateral burned and 400 revenue lost, got %v and %v", sim.CollateralBurned, sim.RevenueLost)
+	}
+
+	// When half of the proofs are missed, the most costly one is missed.
+	sim, err = ht.host.SimulateDowntime(15, 45, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.ProofsMissed != 1 || sim.Obligations[0].ObligationID != late.id() {
+		t.Fatal("expected the late obligation to be missed, got", sim.Obligations)
+	}
+	if !sim.CollateralBurned.Equals64(500) || !sim.RevenueLost.Equals64(300) {
+		t.Errorf("expected 500 collateral burned and 300 revenue lost, got %v and %v", sim.CollateralBurned, sim.RevenueLost)
+	}
+
+	// Downtime that ends before a proof window starts does not risk it.
+	sim, err = ht.host.SimulateDowntime(50, 70, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.ProofsAtRisk != 0 || !sim.CollateralBurned.IsZero() {
+		t.Error("expected no proofs at risk, got", sim.ProofsAtRisk)
+	}
+
+	if _, err := ht.host.SimulateDowntime(45, 15, 1); err != errDowntimeRange {
+		t.Error("expected errDowntimeRange, got", err)
+	}
+	if _, err := ht.host.SimulateDowntime(15, 45, 1.5); err != errMissedFraction {
+		t.Error("expected errMissedFraction, got", err)
+	}
+}
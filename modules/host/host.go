@@ -155,11 +155,15 @@ type Host struct {
 	// otherwise are not critical to always be correct.
 	autoAddress          modules.NetAddress // Determined using automatic tooling in network.go
 	financialMetrics     modules.HostFinancialMetrics
+	revenueHistory       []modules.HostRevenueSnapshot
 	settings             modules.HostInternalSettings
 	revisionNumber       uint64
 	workingStatus        modules.HostWorkingStatus
 	connectabilityStatus modules.HostConnectabilityStatus
 
+	// consistency is the result of the most recent consistency check.
+	consistency modules.HostConsistency
+
 	// A map of storage obligations that are currently being modified. Locks on
 	// storage obligations can be long-running, and each storage obligation can
 	// be locked separately.
@@ -283,6 +287,10 @@ func newHost(dependencies dependencies, cs modules.ConsensusSet, tpool modules.T
 		}
 	})
 
+	// Cross-check the storage obligations against the stored sectors in the
+	// background.
+	go h.threadedCheckConsistency()
+
 	// Initialize the networking.
 	err = h.initNetworking(listenerAddress)
 	if err != nil {
@@ -346,6 +354,19 @@ func (h *Host) FinancialMetrics() modules.HostFinancialMetrics {
 	return h.financialMetrics
 }
 
+// RevenueHistory returns a daily snapshot of the host's total revenue, oldest
+// first.
+func (h *Host) RevenueHistory() []modules.HostRevenueSnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	err := h.tg.Add()
+	if err != nil {
+		build.Critical("Call to RevenueHistory after close")
+	}
+	defer h.tg.Done()
+	return append([]modules.HostRevenueSnapshot(nil), h.revenueHistory...)
+}
+
 // PublicKey returns the public key of the host that is used to facilitate
 // relationships between the host and renter.
 func (h *Host) PublicKey() types.SiaPublicKey {
@@ -206,6 +206,10 @@ func newMockHostTester(d dependencies, name string) (*hostTester, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = ht.host.managedWaitForStorageFolders()
+	if err != nil {
+		return nil, err
+	}
 	return ht, nil
 }
 
@@ -65,10 +65,10 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 	// for the renter.
 	existingRevision := so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0]
 	var payload [][]byte
+	var totalSize uint64
 	err = func() error {
 		// Check that the length of each file is in-bounds, and that the total
 		// size being requested is acceptable.
-		var totalSize uint64
 		for _, request := range requests {
 			if request.Length > modules.SectorSize || request.Offset+request.Length > modules.SectorSize {
 				return extendErr("download iteration request failed: ", errRequestOutOfBounds)
@@ -122,6 +122,7 @@ func (h *Host) managedDownloadIteration(conn net.Conn, so *storageObligation) er
 		FileContractRevisions: []types.FileContractRevision{paymentRevision},
 		TransactionSignatures: []types.TransactionSignature{renterSignature, txn.TransactionSignatures[1]},
 	}}
+	so.recordUsage(types.CurrentTimestamp(), 0, 0, totalSize, paymentTransfer)
 	err = h.modifyStorageObligation(*so, nil, nil, nil)
 	if err != nil {
 		return extendErr("failed to modify storage obligation: ", ErrorInternal(modules.WriteNegotiationRejection(conn, err).Error()))
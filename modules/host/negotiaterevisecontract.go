@@ -65,6 +65,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	var sectorsRemoved []crypto.Hash
 	var sectorsGained []crypto.Hash
 	var gainedSectorData [][]byte
+	var bytesAdded, bytesRemoved uint64
 	err = func() error {
 		for _, modification := range modifications {
 			// Check that the index points to an existing sector root. If the type
@@ -87,6 +88,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				// remove the sector.
 				sectorsRemoved = append(sectorsRemoved, so.SectorRoots[modification.SectorIndex])
 				so.SectorRoots = append(so.SectorRoots[0:modification.SectorIndex], so.SectorRoots[modification.SectorIndex+1:]...)
+				bytesRemoved += modules.SectorSize
 			case modules.ActionInsert:
 				// Check that the sector size is correct.
 				if uint64(len(modification.Data)) != modules.SectorSize {
@@ -105,6 +107,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				sectorsGained = append(sectorsGained, newRoot)
 				gainedSectorData = append(gainedSectorData, modification.Data)
 				so.SectorRoots = append(so.SectorRoots[:modification.SectorIndex], append([]crypto.Hash{newRoot}, so.SectorRoots[modification.SectorIndex:]...)...)
+				bytesAdded += modules.SectorSize
 			case modules.ActionModify:
 				// Check that the offset and length are okay. Length is already
 				// known to be appropriately small, but the offset needs to be
@@ -131,6 +134,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				sectorsGained = append(sectorsGained, newRoot)
 				gainedSectorData = append(gainedSectorData, sector)
 				so.SectorRoots[modification.SectorIndex] = newRoot
+				bytesAdded += uint64(len(modification.Data))
 			default:
 				return errUnknownModification
 			}
@@ -165,6 +169,7 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 	so.RiskedCollateral = so.RiskedCollateral.Add(newCollateral)
 	so.PotentialUploadRevenue = so.PotentialUploadRevenue.Add(bandwidthRevenue)
 	so.RevisionTransactionSet = []types.Transaction{txn}
+	so.recordUsage(types.CurrentTimestamp(), bytesAdded, bytesRemoved, 0, storageRevenue.Add(bandwidthRevenue))
 	h.mu.Lock()
 	err = h.modifyStorageObligation(*so, sectorsRemoved, sectorsGained, gainedSectorData)
 	h.mu.Unlock()
@@ -243,6 +243,7 @@ func (h *Host) initNetworking(address string) (err error) {
 // threadedHandleConn handles an incoming connection to the host, typically an
 // RPC.
 func (h *Host) threadedHandleConn(conn net.Conn) {
+	defer build.CaptureCrash("host")
 	err := h.tg.Add()
 	if err != nil {
 		return
@@ -319,7 +320,7 @@ func (h *Host) threadedListen(closeChan chan struct{}) {
 			return
 		}
 
-		go h.threadedHandleConn(conn)
+		go h.threadedHandleConn(modules.LimitConn(conn, modules.BandwidthHost))
 
 		// Soft-sleep to ratelimit the number of incoming connections.
 		select {
@@ -21,14 +21,15 @@ type persistence struct {
 	RecentChange modules.ConsensusChangeID `json:"recentchange"`
 
 	// Host Identity.
-	Announced        bool                         `json:"announced"`
-	AutoAddress      modules.NetAddress           `json:"autoaddress"`
-	FinancialMetrics modules.HostFinancialMetrics `json:"financialmetrics"`
-	PublicKey        types.SiaPublicKey           `json:"publickey"`
-	RevisionNumber   uint64                       `json:"revisionnumber"`
-	SecretKey        crypto.SecretKey             `json:"secretkey"`
-	Settings         modules.HostInternalSettings `json:"settings"`
-	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+	Announced        bool                          `json:"announced"`
+	AutoAddress      modules.NetAddress            `json:"autoaddress"`
+	FinancialMetrics modules.HostFinancialMetrics  `json:"financialmetrics"`
+	PublicKey        types.SiaPublicKey            `json:"publickey"`
+	RevenueHistory   []modules.HostRevenueSnapshot `json:"revenuehistory"`
+	RevisionNumber   uint64                        `json:"revisionnumber"`
+	SecretKey        crypto.SecretKey              `json:"secretkey"`
+	Settings         modules.HostInternalSettings  `json:"settings"`
+	UnlockHash       types.UnlockHash              `json:"unlockhash"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
@@ -43,6 +44,7 @@ func (h *Host) persistData() persistence {
 		AutoAddress:      h.autoAddress,
 		FinancialMetrics: h.financialMetrics,
 		PublicKey:        h.publicKey,
+		RevenueHistory:   h.revenueHistory,
 		RevisionNumber:   h.revisionNumber,
 		SecretKey:        h.secretKey,
 		Settings:         h.settings,
@@ -99,6 +101,7 @@ func (h *Host) loadPersistObject(p *persistence) {
 	}
 	h.financialMetrics = p.FinancialMetrics
 	h.publicKey = p.PublicKey
+	h.revenueHistory = p.RevenueHistory
 	h.revisionNumber = p.RevisionNumber
 	h.secretKey = p.SecretKey
 	h.settings = p.Settings
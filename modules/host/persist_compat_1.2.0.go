@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/bolt"
 
@@ -213,6 +214,27 @@ func (h *Host) readAndDeleteV112Sectors(oldPersist *v112StorageManagerPersist, o
 	return sectors, err
 }
 
+// managedWaitForStorageFolders blocks until none of the host's storage folders
+// are still being initialized.
+func (h *Host) managedWaitForStorageFolders() error {
+	for {
+		var initializing bool
+		for _, sf := range h.StorageFolders() {
+			if sf.Status == modules.StorageFolderStatusInitializing {
+				initializing = true
+			}
+		}
+		if !initializing {
+			return nil
+		}
+		select {
+		case <-h.tg.StopChan():
+			return errors.New("host shut down while waiting for storage folders to initialize")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
 // upgradeFromV112toV120 is an upgrade layer that migrates the host from
 // the old storage manager to the new contract manager. This particular upgrade
 // only handles migrating the sectors.
@@ -297,6 +319,13 @@ func (h *Host) upgradeFromV112ToV120() error {
 		}
 	}
 
+	// Storage folders are initialized in the background. Wait for them to be
+	// ready before moving any sectors into them.
+	err = h.managedWaitForStorageFolders()
+	if err != nil {
+		return err
+	}
+
 	// Add all of the preloaded sectors to the contract manager.
 	var wg sync.WaitGroup
 	for _, sector := range sectors {
@@ -0,0 +1,118 @@
+package host
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// Every attempt of the host to submit a storage proof is recorded in the
+// storage obligation, with the transactions involved. When the proof window
+// closes without a confirmed proof, the attempts are turned into a forensic
+// record of the failure, so that the operator can see whether the proof was
+// missed because the wallet was locked, the sector could not be read, the
+// transaction pool rejected the proof or a reorg reverted it.
+
+const (
+	// maxProofAttempts is the number of proof attempts that are kept per
+	// storage obligation. Older attempts are dropped.
+	maxProofAttempts = 20
+)
+
+var (
+	// errObligationNotFailed is returned when requesting the failure record
+	// of a storage obligation that has not failed.
+	errObligationNotFailed = errors.New("storage obligation has not failed")
+)
+
+// recordProofAttempt adds a proof attempt to the storage obligation.
+func (so *storageObligation) recordProofAttempt(height types.BlockHeight, reason modules.HostProofReason, err error, txns []types.Transaction) {
+	attempt := modules.HostProofAttempt{
+		Height:    height,
+		Timestamp: types.CurrentTimestamp(),
+		Reason:    reason,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	for _, txn := range txns {
+		attempt.TransactionIDs = append(attempt.TransactionIDs, txn.ID())
+	}
+	so.ProofAttempts = append(so.ProofAttempts, attempt)
+	if len(so.ProofAttempts) > maxProofAttempts {
+		so.ProofAttempts = so.ProofAttempts[len(so.ProofAttempts)-maxProofAttempts:]
+	}
+}
+
+// proofFailure returns the forensic record of the storage obligation, which
+// missed its storage proof at the provided height.
+func (so storageObligation) proofFailure(height types.BlockHeight) *modules.HostObligationFailure {
+	reason := modules.HostProofNoAttempt
+	if n := len(so.ProofAttempts); n > 0 {
+		reason = so.ProofAttempts[n-1].Reason
+	}
+	return &modules.HostObligationFailure{
+		ObligationID:     so.id(),
+		Reason:           reason,
+		ProofWindowStart: so.expiration(),
+		ProofDeadline:    so.proofDeadline(),
+		FailureHeight:    height,
+		FailureTimestamp: types.CurrentTimestamp(),
+		Attempts:         so.ProofAttempts,
+	}
+}
+
+// managedRecordProofAttempt records a proof attempt in the storage obligation
+// and saves it. If the attempt failed, another attempt is queued, no later
+// than the block after the proof deadline so that a missed proof is recorded.
+func (h *Host) managedRecordProofAttempt(so *storageObligation, height types.BlockHeight, reason modules.HostProofReason, err error, txns []types.Transaction) {
+	so.recordProofAttempt(height, reason, err, txns)
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		return putStorageObligation(tx, *so)
+	})
+	if err != nil {
+		h.log.Println("Error saving the storage proof attempt:", err)
+	}
+	if reason == modules.HostProofSubmitted {
+		return
+	}
+
+	retry := height + resubmissionTimeout
+	if retry > so.proofDeadline() {
+		retry = so.proofDeadline() + 1
+	}
+	h.mu.Lock()
+	err = h.queueActionItem(retry, so.id())
+	h.mu.Unlock()
+	if err != nil {
+		h.log.Println("Error queuing action item:", err)
+	}
+}
+
+// StorageObligationFailure returns the forensic record of the storage
+// obligation with the provided id, which must have missed its storage proof.
+func (h *Host) StorageObligationFailure(id types.FileContractID) (modules.HostObligationFailure, error) {
+	err := h.tg.Add()
+	if err != nil {
+		return modules.HostObligationFailure{}, err
+	}
+	defer h.tg.Done()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var so storageObligation
+	err = h.db.View(func(tx *bolt.Tx) (err error) {
+		so, err = getStorageObligation(tx, id)
+		return err
+	})
+	if err != nil {
+		return modules.HostObligationFailure{}, err
+	}
+	if so.ObligationStatus != obligationFailed || so.ProofFailure == nil {
+		return modules.HostObligationFailure{}, errObligationNotFailed
+	}
+	return *so.ProofFailure, nil
+}
@@ -0,0 +1,138 @@
+package host
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestProofFailure checks that the reason of a missed storage proof is the
+// reason of the last attempt, and that old attempts are dropped.
+func TestProofFailure(t *testing.T) {
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{WindowStart: 10, WindowEnd: 20}},
+		}},
+	}
+	failure := so.proofFailure(21)
+	if failure.Reason != modules.HostProofNoAttempt || failure.ObligationID != so.id() {
+		t.Fatal("wrong failure without attempts:", failure)
+	}
+	if failure.ProofWindowStart != 10 || failure.ProofDeadline != 20 || failure.FailureHeight != 21 {
+		t.Fatal("wrong proof window:", failure)
+	}
+
+	txn := types.Transaction{ArbitraryData: [][]byte{{1}}}
+	so.recordProofAttempt(12, modules.HostProofWalletLocked, errors.New("locked"), nil)
+	so.recordProofAttempt(13, modules.HostProofSubmitted, nil, []types.Transaction{txn})
+	failure = so.proofFailure(21)
+	if failure.Reason != modules.HostProofSubmitted || len(failure.Attempts) != 2 {
+		t.Fatal("wrong failure:", failure)
+	}
+	if failure.Attempts[0].Error != "locked" || failure.Attempts[1].TransactionIDs[0] != txn.ID() {
+		t.Fatal("attempts were not recorded correctly:", failure.Attempts)
+	}
+
+	for i := 0; i < maxProofAttempts; i++ {
+		so.recordProofAttempt(14, modules.HostProofReorged, nil, nil)
+	}
+	if len(so.ProofAttempts) != maxProofAttempts || so.ProofAttempts[0].Reason != modules.HostProofReorged {
+		t.Fatal("old attempts were not dropped:", len(so.ProofAttempts))
+	}
+}
+
+// TestMissedProofSectorRead checks that the host records that a storage proof
+// was missed because the sector could not be read.
+func TestMissedProofSectorRead(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	// Add a sector to the obligation, then remove it from disk.
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	validPayouts, missedPayouts := so.payouts()
+	revisionSet := []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:          so.id(),
+			UnlockConditions:  types.UnlockConditions{},
+			NewRevisionNumber: 1,
+
+			NewFileSize:           uint64(len(sectorData)),
+			NewFileMerkleRoot:     sectorRoot,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+		}},
+	}}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.tpool.AcceptTransactionSet(revisionSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.RemoveSector(sectorRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The obligation has not failed yet.
+	_, err = ht.host.StorageObligationFailure(so.id())
+	if err != errObligationNotFailed {
+		t.Fatal("expected errObligationNotFailed, got", err)
+	}
+
+	// Mine past the proof deadline.
+	for ht.host.blockHeight <= so.proofDeadline()+1 {
+		_, err := ht.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ht.host.tg.Flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failure, err := ht.host.StorageObligationFailure(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failure.Reason != modules.HostProofSectorReadFailed {
+		t.Fatal("wrong reason:", failure.Reason)
+	}
+	if failure.FailureHeight <= failure.ProofDeadline || len(failure.Attempts) == 0 {
+		t.Fatal("wrong failure:", failure)
+	}
+	for _, attempt := range failure.Attempts {
+		if attempt.Reason != modules.HostProofSectorReadFailed || attempt.Error == "" {
+			t.Fatal("wrong attempt:", attempt)
+		}
+	}
+}
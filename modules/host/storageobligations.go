@@ -145,6 +145,17 @@ type storageObligation struct {
 	ProofConstructed    bool
 	ProofConfirmed      bool
 	ObligationStatus    storageObligationStatus
+
+	// The usage history records the data transferred under the obligation
+	// in each snapshot period, so that the host can see which renters drive
+	// its load.
+	UsageHistory []modules.HostContractUsageSnapshot
+
+	// The proof attempts record every attempt of the host to submit the
+	// storage proof. If the proof is missed, they are kept in the proof
+	// failure, the forensic record of the failure.
+	ProofAttempts []modules.HostProofAttempt
+	ProofFailure  *modules.HostObligationFailure
 }
 
 // getStorageObligation fetches a storage obligation from the database tx.
@@ -275,6 +286,24 @@ func (so storageObligation) proofDeadline() types.BlockHeight {
 	return so.OriginTransactionSet[len(so.OriginTransactionSet)-1].FileContracts[0].WindowEnd
 }
 
+// recordUsage adds the provided transfers and the revenue that they earned to
+// the usage history of the period containing the timestamp.
+func (so *storageObligation) recordUsage(timestamp types.Timestamp, added, removed, downloaded uint64, revenue types.Currency) {
+	timestamp -= timestamp % modules.SnapshotPeriod
+	// The clock of the host may go backwards, in which case the transfers
+	// are counted towards the most recent period.
+	n := len(so.UsageHistory)
+	if n == 0 || timestamp > so.UsageHistory[n-1].Timestamp {
+		so.UsageHistory = append(so.UsageHistory, modules.HostContractUsageSnapshot{Timestamp: timestamp})
+		n++
+	}
+	snapshot := &so.UsageHistory[n-1]
+	snapshot.BytesAdded += added
+	snapshot.BytesRemoved += removed
+	snapshot.BytesDownloaded += downloaded
+	snapshot.Revenue = snapshot.Revenue.Add(revenue)
+}
+
 // value returns the value of fulfilling the storage obligation to the host.
 func (so storageObligation) value() types.Currency {
 	return so.ContractCost.Add(so.PotentialDownloadRevenue).Add(so.PotentialStorageRevenue).Add(so.PotentialUploadRevenue).Add(so.RiskedCollateral)
@@ -579,7 +608,10 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 		h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Sub(so.PotentialUploadRevenue)
 		h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Sub(so.RiskedCollateral)
 
-		// Add the obligation statistics as loss.
+		// Add the obligation statistics as loss, and record why the proof
+		// was missed.
+		so.ProofFailure = so.proofFailure(h.blockHeight)
+		h.log.Printf("Storage proof of obligation %v was missed, reason: %v\n", so.id(), so.ProofFailure.Reason)
 		h.financialMetrics.LostStorageCollateral = h.financialMetrics.LostStorageCollateral.Add(so.RiskedCollateral)
 		h.financialMetrics.LostRevenue = h.financialMetrics.LostRevenue.Add(so.ContractCost).Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue)
 	}
@@ -599,6 +631,7 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 // threadedHandleActionItem will look at a storage obligation and determine
 // which action is necessary for the storage obligation to succeed.
 func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
+	defer build.CaptureCrash("host")
 	err := h.tg.Add()
 	if err != nil {
 		return
@@ -747,6 +780,9 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		// be removed.
 		if so.proofDeadline() < blockHeight || len(so.SectorRoots) == 0 {
 			h.log.Debugln("storage proof not confirmed by deadline, id", so.id())
+			if len(so.SectorRoots) == 0 {
+				so.recordProofAttempt(blockHeight, modules.HostProofNoSectors, nil, nil)
+			}
 			h.mu.Lock()
 			err := h.removeStorageObligation(so, obligationFailed)
 			h.mu.Unlock()
@@ -761,6 +797,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		segmentIndex, err := h.cs.StorageProofSegment(so.id())
 		if err != nil {
 			h.log.Debugln("Host got an error when fetching a storage proof segment:", err)
+			h.managedRecordProofAttempt(&so, blockHeight, modules.HostProofSegmentUnavailable, err, nil)
 			return
 		}
 		sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
@@ -769,6 +806,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		sectorBytes, err := h.ReadSector(sectorRoot)
 		if err != nil {
 			h.log.Debugln(err)
+			h.managedRecordProofAttempt(&so, blockHeight, modules.HostProofSectorReadFailed, err, nil)
 			return
 		}
 
@@ -800,6 +838,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			// There's no sense submitting the storage proof if the fee is more
 			// than the anticipated revenue.
 			h.log.Debugln("Host not submitting storage proof due to a value that does not sufficiently exceed the fee cost")
+			h.managedRecordProofAttempt(&so, blockHeight, modules.HostProofFeeTooHigh, nil, nil)
 			return
 		}
 		txnSize := uint64(len(encoding.Marshal(sp)) + 300)
@@ -807,6 +846,11 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		err = builder.FundSiacoins(requiredFee)
 		if err != nil {
 			h.log.Println("Host error when funding a storage proof transaction fee:", err)
+			reason := modules.HostProofFundingFailed
+			if !h.wallet.Unlocked() {
+				reason = modules.HostProofWalletLocked
+			}
+			h.managedRecordProofAttempt(&so, blockHeight, reason, err, nil)
 			return
 		}
 		builder.AddMinerFee(requiredFee)
@@ -814,14 +858,17 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		storageProofSet, err := builder.Sign(true)
 		if err != nil {
 			h.log.Println("Host error when signing the storage proof transaction:", err)
+			h.managedRecordProofAttempt(&so, blockHeight, modules.HostProofSigningFailed, err, nil)
 			return
 		}
 		err = h.tpool.AcceptTransactionSet(storageProofSet)
 		if err != nil {
 			h.log.Println("Host unable to submit storage proof transaction to transaction pool:", err)
+			h.managedRecordProofAttempt(&so, blockHeight, modules.HostProofTransactionPoolRejected, err, storageProofSet)
 			return
 		}
 		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
+		so.recordProofAttempt(blockHeight, modules.HostProofSubmitted, nil, storageProofSet)
 
 		// Queue another action item to check whether the storage proof
 		// got confirmed.
@@ -870,6 +917,7 @@ func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 				return build.ExtendErr("unable to unmarshal storage obligation:", err)
 			}
 			mso := modules.StorageObligation{
+				ObligationID:      so.id(),
 				NegotiationHeight: so.NegotiationHeight,
 
 				OriginConfirmed:     so.OriginConfirmed,
@@ -893,3 +941,25 @@ func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 
 	return sos
 }
+
+// StorageObligationUsage returns the usage timeline of the storage obligation
+// with the provided id, oldest first.
+func (h *Host) StorageObligationUsage(id types.FileContractID) ([]modules.HostContractUsageSnapshot, error) {
+	err := h.tg.Add()
+	if err != nil {
+		return nil, err
+	}
+	defer h.tg.Done()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var so storageObligation
+	err = h.db.View(func(tx *bolt.Tx) (err error) {
+		so, err = getStorageObligation(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return so.UsageHistory, nil
+}
@@ -3,6 +3,7 @@ package host
 import (
 	"testing"
 
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -80,3 +81,31 @@ func TestStorageObligationID(t *testing.T) {
 		t.Error("id function of storage obligation incorrect for file contracts with dependencies")
 	}
 }
+
+// TestStorageObligationRecordUsage checks that transfers are added to the
+// usage snapshot of the period in which they happen.
+func TestStorageObligationRecordUsage(t *testing.T) {
+	t.Parallel()
+	var so storageObligation
+	day := modules.SnapshotPeriod
+	so.recordUsage(day+10, modules.SectorSize, 0, 0, types.NewCurrency64(5))
+	so.recordUsage(day+20, 0, modules.SectorSize, 100, types.NewCurrency64(3))
+	so.recordUsage(3*day+5, 0, 0, 200, types.NewCurrency64(1))
+	// A timestamp from the past is counted towards the latest period.
+	so.recordUsage(2*day, 10, 0, 0, types.ZeroCurrency)
+
+	expected := []modules.HostContractUsageSnapshot{
+		{Timestamp: day, BytesAdded: modules.SectorSize, BytesRemoved: modules.SectorSize, BytesDownloaded: 100, Revenue: types.NewCurrency64(8)},
+		{Timestamp: 3 * day, BytesAdded: 10, BytesDownloaded: 200, Revenue: types.NewCurrency64(1)},
+	}
+	if len(so.UsageHistory) != len(expected) {
+		t.Fatalf("expected %v snapshots, got %v", len(expected), len(so.UsageHistory))
+	}
+	for i, snapshot := range so.UsageHistory {
+		e := expected[i]
+		if snapshot.Timestamp != e.Timestamp || snapshot.BytesAdded != e.BytesAdded || snapshot.BytesRemoved != e.BytesRemoved ||
+			snapshot.BytesDownloaded != e.BytesDownloaded || !snapshot.Revenue.Equals(e.Revenue) {
+			t.Errorf("snapshot %v: expected %v, got %v", i, e, snapshot)
+		}
+	}
+}
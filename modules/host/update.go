@@ -6,6 +6,7 @@ package host
 import (
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
@@ -14,6 +15,12 @@ import (
 	"github.com/NebulousLabs/bolt"
 )
 
+// reindexAttempts is the number of times ReindexObligations walks the
+// blockchain before giving up because new blocks keep arriving.
+const reindexAttempts = 3
+
+var errReindexInterrupted = errors.New("the blockchain changed while the storage obligations were being reindexed")
+
 // initRescan is a helper function of initConsensusSubscribe, and is called when
 // the host and the consensus set have become desynchronized. Desynchronization
 // typically happens if the user is replacing or altering the persistent files
@@ -116,6 +123,105 @@ func (h *Host) initConsensusSubscription() error {
 	return nil
 }
 
+// ReindexObligations recomputes whether the file contract, revisions and
+// storage proof of each storage obligation appear in the blockchain, by
+// walking the blocks of the consensus set. Unlike a rescan, the host is not
+// resubscribed to the consensus set, so no action items are queued or handled
+// by the reindex.
+func (h *Host) ReindexObligations() error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	for i := 0; i < reindexAttempts; i++ {
+		// Walk the blockchain without holding the host lock, because the
+		// consensus set calls into the host while holding its own lock.
+		height := h.cs.Height()
+		var tip types.BlockID
+		origins := make(map[types.FileContractID]struct{})
+		revisions := make(map[types.FileContractID]struct{})
+		proofs := make(map[types.FileContractID]struct{})
+		for bh := types.BlockHeight(0); bh <= height; bh++ {
+			block, exists := h.cs.BlockAtHeight(bh)
+			if !exists {
+				break
+			}
+			tip = block.ID()
+			for _, txn := range block.Transactions {
+				for j := range txn.FileContracts {
+					origins[txn.FileContractID(uint64(j))] = struct{}{}
+				}
+				for _, fcr := range txn.FileContractRevisions {
+					revisions[fcr.ParentID] = struct{}{}
+				}
+				for _, sp := range txn.StorageProofs {
+					proofs[sp.ParentID] = struct{}{}
+				}
+			}
+		}
+		if tip != h.cs.CurrentBlock().ID() {
+			continue
+		}
+
+		// The flags are only correct if the host has processed the same
+		// blocks that were walked.
+		h.mu.Lock()
+		if h.blockHeight != height {
+			h.mu.Unlock()
+			continue
+		}
+		err = h.db.Update(func(tx *bolt.Tx) error {
+			bso := tx.Bucket(bucketStorageObligations)
+			c := bso.Cursor()
+			for k, soBytes := c.First(); soBytes != nil; k, soBytes = c.Next() {
+				var so storageObligation
+				err := json.Unmarshal(soBytes, &so)
+				if err != nil {
+					return err
+				}
+				soid := so.id()
+				_, so.OriginConfirmed = origins[soid]
+				_, so.RevisionConfirmed = revisions[soid]
+				_, so.ProofConfirmed = proofs[soid]
+				soBytes, err = json.Marshal(so)
+				if err != nil {
+					return err
+				}
+				err = bso.Put(k, soBytes)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		h.mu.Unlock()
+		if err == nil {
+			h.log.Println("INFO: reindexed storage obligations at height", height)
+		}
+		return err
+	}
+	return errReindexInterrupted
+}
+
+// updateRevenueHistory sets the revenue of the day containing the timestamp to
+// the host's current total revenue.
+func (h *Host) updateRevenueHistory(timestamp types.Timestamp) {
+	fm := h.financialMetrics
+	snapshot := modules.HostRevenueSnapshot{
+		Timestamp: timestamp - timestamp%modules.SnapshotPeriod,
+		Revenue:   fm.ContractCompensation.Add(fm.StorageRevenue).Add(fm.DownloadBandwidthRevenue).Add(fm.UploadBandwidthRevenue),
+	}
+	// Block timestamps are not strictly increasing, so a block that belongs
+	// to a previous day is counted towards the most recent day.
+	if n := len(h.revenueHistory); n > 0 && snapshot.Timestamp <= h.revenueHistory[n-1].Timestamp {
+		h.revenueHistory[n-1].Revenue = snapshot.Revenue
+		return
+	}
+	h.revenueHistory = append(h.revenueHistory, snapshot)
+}
+
 // ProcessConsensusChange will be called by the consensus set every time there
 // is a change to the blockchain.
 func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
@@ -185,6 +291,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 							continue
 						}
 						so.ProofConfirmed = false
+						so.recordProofAttempt(h.blockHeight, modules.HostProofReorged, nil, []types.Transaction{txn})
 						err = putStorageObligation(tx, so)
 						if err != nil {
 							continue
@@ -304,6 +411,13 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 	// change.
 	h.recentChange = cc.ID
 
+	// Record the host's revenue for the day of the most recent block. Only
+	// changes at the tip of the blockchain are recorded, so that a host that
+	// is catching up does not record a snapshot for every day it missed.
+	if cc.Synced && len(cc.AppliedBlocks) > 0 {
+		h.updateRevenueHistory(cc.AppliedBlocks[len(cc.AppliedBlocks)-1].Timestamp)
+	}
+
 	// Save the host.
 	err = h.saveSync()
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/bolt"
 	"github.com/NebulousLabs/fastrand"
 )
 
@@ -177,3 +178,123 @@ func TestIntegrationAutoRescan(t *testing.T) {
 	// cleanly.
 	ht.host = h
 }
+
+// TestRevenueHistory checks that the host records its revenue once per day.
+func TestRevenueHistory(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// The blocks mined by the host tester are all from today. The testing
+	// genesis block may be from an earlier day.
+	history := ht.host.RevenueHistory()
+	n := len(history)
+	today := types.CurrentTimestamp() - types.CurrentTimestamp()%modules.SnapshotPeriod
+	if n == 0 || history[n-1].Timestamp != today || !history[n-1].Revenue.IsZero() {
+		t.Fatal("expected an empty snapshot for today, got", history)
+	}
+
+	// Revenue earned during the day updates the snapshot of the day.
+	ht.host.mu.Lock()
+	ht.host.financialMetrics.StorageRevenue = types.NewCurrency64(10)
+	ht.host.financialMetrics.ContractCompensation = types.NewCurrency64(5)
+	ht.host.updateRevenueHistory(today + 100)
+	ht.host.mu.Unlock()
+	history = ht.host.RevenueHistory()
+	if len(history) != n || !history[n-1].Revenue.Equals64(15) {
+		t.Fatal("expected today's snapshot to be updated, got", history)
+	}
+
+	// A new day adds a snapshot, and a block from the previous day counts
+	// towards the new day.
+	tomorrow := today + modules.SnapshotPeriod
+	ht.host.mu.Lock()
+	ht.host.updateRevenueHistory(tomorrow)
+	ht.host.financialMetrics.DownloadBandwidthRevenue = types.NewCurrency64(1)
+	ht.host.updateRevenueHistory(today + 200)
+	ht.host.mu.Unlock()
+	history = ht.host.RevenueHistory()
+	if len(history) != n+1 || !history[n-1].Revenue.Equals64(15) || history[n].Timestamp != tomorrow || !history[n].Revenue.Equals64(16) {
+		t.Fatal("wrong history after a new day:", history)
+	}
+
+	// The history should persist.
+	if err := ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded := ht.host.RevenueHistory(); len(reloaded) != n+1 || !reloaded[n].Revenue.Equals64(16) {
+		t.Fatal("history was not persisted:", reloaded)
+	}
+}
+
+// TestReindexObligations checks that reindexing the storage obligations
+// restores their confirmation flags.
+func TestReindexObligations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Add a storage obligation and confirm its file contract.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+	_, err = ht.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clear the flags of the obligation, and mark its proof as confirmed.
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, so.id())
+		if err != nil {
+			return err
+		}
+		so.OriginConfirmed = false
+		so.ProofConfirmed = true
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ht.host.ReindexObligations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, so.id())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !so.OriginConfirmed {
+		t.Error("origin transaction should be confirmed after reindex")
+	}
+	if so.RevisionConfirmed || so.ProofConfirmed {
+		t.Error("revision and proof should not be confirmed after reindex")
+	}
+}
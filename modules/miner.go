@@ -2,6 +2,7 @@ package modules
 
 import (
 	"io"
+	"time"
 
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -27,6 +28,14 @@ type BlockManager interface {
 	// BlocksMined returns the number of blocks and stale blocks that have been
 	// mined using this miner.
 	BlocksMined() (goodBlocks, staleBlocks int)
+
+	// BlockWithholding returns the delay applied to solved blocks before they
+	// are submitted, and the number of blocks currently being withheld.
+	BlockWithholding() (time.Duration, int)
+
+	// SetBlockWithholding delays the submission of solved blocks, for testing
+	// reorgs and block propagation on dev and testing networks.
+	SetBlockWithholding(time.Duration) error
 }
 
 // CPUMiner provides access to a single-threaded cpu miner.
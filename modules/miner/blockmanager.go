@@ -41,7 +41,23 @@ func (m *Miner) blockForWork() types.Block {
 	randTxn := types.Transaction{
 		ArbitraryData: [][]byte{append(modules.PrefixNonSia[:], randBytes...)},
 	}
-	b.Transactions = append([]types.Transaction{randTxn}, b.Transactions...)
+	txns := []types.Transaction{randTxn}
+
+	// Signal support for every deployment that is within its signaling
+	// period. The signal is kept out of the first transaction, whose arbitrary
+	// data is replaced when headers are handed out for work.
+	var bits uint64
+	for _, d := range types.Deployments {
+		if height := m.persist.Height + 1; height >= d.StartHeight && height < d.TimeoutHeight {
+			bits |= 1 << d.Bit
+		}
+	}
+	if bits != 0 {
+		txns = append(txns, types.Transaction{
+			ArbitraryData: [][]byte{types.DeploymentSignal(bits)},
+		})
+	}
+	b.Transactions = append(txns, b.Transactions...)
 
 	return b
 }
@@ -124,8 +140,17 @@ func (m *Miner) HeaderForWork() (types.BlockHeader, types.Target, error) {
 	return header, m.persist.Target, nil
 }
 
-// managedSubmitBlock takes a solved block and submits it to the blockchain.
+// managedSubmitBlock takes a solved block and submits it to the blockchain,
+// unless the block is being withheld.
 func (m *Miner) managedSubmitBlock(b types.Block) error {
+	if m.managedWithholdBlock(b) {
+		return nil
+	}
+	return m.managedAcceptBlock(b)
+}
+
+// managedAcceptBlock gives a solved block to the consensus set.
+func (m *Miner) managedAcceptBlock(b types.Block) error {
 	// Give the block to the consensus set.
 	err := m.cs.AcceptBlock(b)
 	// Add the miner to the blocks list if the only problem is that it's stale.
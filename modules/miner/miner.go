@@ -100,6 +100,11 @@ type Miner struct {
 	mining   bool  // indicates if the miner is actually running
 	hashRate int64 // indicates hashes per second
 
+	// Block withholding variables, see withhold.go.
+	withholdDelay   time.Duration // how long solved blocks are withheld
+	withholdRelease chan struct{} // closed to release the withheld blocks
+	withheldBlocks  int           // number of blocks currently withheld
+
 	// Utils
 	log        *persist.Logger
 	mu         sync.RWMutex
@@ -0,0 +1,91 @@
+package miner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errWithholdingDisabled = errors.New("block withholding is only available on dev and testing networks")
+	errNegativeWithholding = errors.New("block withholding delay cannot be negative")
+)
+
+// Block withholding delays the release of solved blocks to the consensus set,
+// and through it to the rest of the network, so that reorgs and slow block
+// propagation can be tested against the real code paths of a daemon. Blocks
+// solved while they are withheld are built on the same parent, so withholding
+// produces competing blocks rather than a private chain.
+
+// BlockWithholding returns the delay applied to solved blocks and the number
+// of blocks that are currently being withheld.
+func (m *Miner) BlockWithholding() (time.Duration, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.withholdDelay, m.withheldBlocks
+}
+
+// SetBlockWithholding sets the delay applied to solved blocks before they are
+// given to the consensus set. A delay of zero disables withholding. Changing
+// the delay releases all blocks that are currently being withheld. Withholding
+// is unavailable on the standard network.
+func (m *Miner) SetBlockWithholding(delay time.Duration) error {
+	if build.Release == "standard" {
+		return errWithholdingDisabled
+	}
+	if delay < 0 {
+		return errNegativeWithholding
+	}
+	if err := m.tg.Add(); err != nil {
+		return err
+	}
+	defer m.tg.Done()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.withholdDelay = delay
+	if m.withholdRelease != nil {
+		close(m.withholdRelease)
+	}
+	m.withholdRelease = make(chan struct{})
+	m.log.Println("Block withholding delay set to", delay)
+	return nil
+}
+
+// managedWithholdBlock withholds a solved block if withholding is enabled,
+// returning false if the block should be submitted immediately.
+func (m *Miner) managedWithholdBlock(b types.Block) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.withholdDelay == 0 {
+		return false
+	}
+	m.withheldBlocks++
+	go m.threadedReleaseBlock(b, m.withholdDelay, m.withholdRelease)
+	return true
+}
+
+// threadedReleaseBlock submits a withheld block once its delay has passed or
+// the withholding delay is changed.
+func (m *Miner) threadedReleaseBlock(b types.Block, delay time.Duration, release chan struct{}) {
+	if err := m.tg.Add(); err != nil {
+		return
+	}
+	defer m.tg.Done()
+
+	select {
+	case <-time.After(delay):
+	case <-release:
+	case <-m.tg.StopChan():
+		return
+	}
+	m.mu.Lock()
+	m.withheldBlocks--
+	m.mu.Unlock()
+	m.log.Println("Releasing withheld block", b.ID())
+	if err := m.managedAcceptBlock(b); err != nil {
+		m.log.Println("ERROR: withheld block was not accepted:", err)
+	}
+}
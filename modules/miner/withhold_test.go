@@ -0,0 +1,75 @@
+package miner
+
+import (
+	"testing"
+	"time"
+)
+
+// TestIntegrationBlockWithholding checks that solved blocks are withheld from
+// the consensus set until their delay passes or the delay is changed.
+func TestIntegrationBlockWithholding(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mt, err := createMinerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mt.miner.Close()
+
+	if err := mt.miner.SetBlockWithholding(-time.Second); err != errNegativeWithholding {
+		t.Fatal("expected errNegativeWithholding, got", err)
+	}
+
+	// Withhold blocks for an hour and submit a solved header.
+	if err := mt.miner.SetBlockWithholding(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	height := mt.cs.Height()
+	header, target, err := mt.miner.HeaderForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mt.miner.SubmitHeader(solveHeader(header, target)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if mt.cs.Height() != height {
+		t.Fatal("withheld block was given to the consensus set")
+	}
+	if delay, withheld := mt.miner.BlockWithholding(); delay != time.Hour || withheld != 1 {
+		t.Fatalf("expected 1 block withheld for 1h, got %v withheld for %v", withheld, delay)
+	}
+
+	// Disabling withholding should release the block.
+	if err := mt.miner.SetBlockWithholding(0); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50 && mt.cs.Height() == height; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if mt.cs.Height() != height+1 {
+		t.Fatal("released block was not accepted")
+	}
+	if _, withheld := mt.miner.BlockWithholding(); withheld != 0 {
+		t.Fatal("expected no withheld blocks, got", withheld)
+	}
+
+	// A short delay should release the block by itself.
+	if err := mt.miner.SetBlockWithholding(200 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	header, target, err = mt.miner.HeaderForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mt.miner.SubmitHeader(solveHeader(header, target)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50 && mt.cs.Height() == height+1; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if mt.cs.Height() != height+2 {
+		t.Fatal("delayed block was not accepted")
+	}
+}
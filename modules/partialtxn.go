@@ -0,0 +1,389 @@
+package modules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// A partial transaction is a transaction that is signed by several parties,
+// such as the cosigners of a multisig address or a wallet and an offline
+// signer, each of which may use a different tool. It is passed around as JSON
+// until enough signatures have been collected:
+//
+//	{
+//	  "version":     1,
+//	  "transaction": { ... },  // the transaction, without signatures
+//	  "parents":     [ ... ],  // unconfirmed transactions that it spends
+//	  "inputs": [
+//	    {
+//	      "parentid":      "...",      // id of the output or contract
+//	      "type":          "siacoin",  // "siacoin", "siafund" or "revision"
+//	      "value":         "1000",     // value of the output, if known
+//	      "coveredfields": {"wholetransaction": true, ...},
+//	      "signatures": [
+//	        {"publickeyindex": 0, "signature": "..."}
+//	      ]
+//	    }
+//	  ],
+//	  "metadata": {"description": "..."}
+//	}
+//
+// There is one input for each siacoin input, siafund input and file contract
+// revision of the transaction, in that order. A signature of an input is made
+// by the public key at PublicKeyIndex of the input's unlock conditions, over
+// the SigHash of a transaction signature with the input's parent ID, public
+// key index and covered fields, and a timelock of zero. Only signatures that
+// cover the whole transaction are supported, which makes every signature
+// independent of the others, so that signers can work in any order and their
+// copies can be merged. A partial transaction is finalized by moving enough
+// signatures of each input into the transaction.
+
+const (
+	// PartialTransactionVersion is the version of the partial transaction
+	// format.
+	PartialTransactionVersion = 1
+
+	// The types of the inputs of a partial transaction.
+	PartialInputSiacoin  = "siacoin"
+	PartialInputSiafund  = "siafund"
+	PartialInputRevision = "revision"
+)
+
+var (
+	errPartialCoveredFields = errors.New("partial transactions only support signatures that cover the whole transaction")
+	errPartialMismatch      = errors.New("partial transactions do not spend the same transaction")
+	errPartialNone          = errors.New("no partial transactions were provided")
+	errPartialSigned        = errors.New("transaction of a partial transaction must not contain signatures")
+	errPartialVersion       = errors.New("unsupported partial transaction version")
+)
+
+type (
+	// A PartialTransaction is a transaction whose signatures are being
+	// collected from several signers.
+	PartialTransaction struct {
+		Version     uint64              `json:"version"`
+		Transaction types.Transaction   `json:"transaction"`
+		Parents     []types.Transaction `json:"parents,omitempty"`
+		Inputs      []PartialInput      `json:"inputs"`
+
+		// Metadata holds notes for the signers, such as a description of the
+		// payment. It is not covered by the signatures.
+		Metadata map[string]string `json:"metadata,omitempty"`
+	}
+
+	// A PartialInput holds the signatures collected for one input of a
+	// partial transaction. Value is zero if the creator of the partial
+	// transaction did not know the value of the input.
+	PartialInput struct {
+		ParentID      crypto.Hash         `json:"parentid"`
+		Type          string              `json:"type"`
+		Value         types.Currency      `json:"value"`
+		CoveredFields types.CoveredFields `json:"coveredfields"`
+		Signatures    []PartialSignature  `json:"signatures"`
+	}
+
+	// A PartialSignature is a signature of an input of a partial transaction
+	// by the public key at PublicKeyIndex of the input's unlock conditions.
+	PartialSignature struct {
+		PublicKeyIndex uint64 `json:"publickeyindex"`
+		Signature      []byte `json:"signature"`
+	}
+)
+
+// partialInputs returns the inputs of txn that need signatures, along with
+// their unlock conditions, in the order of a partial transaction.
+func partialInputs(txn types.Transaction) ([]PartialInput, []types.UnlockConditions) {
+	var inputs []PartialInput
+	var conds []types.UnlockConditions
+	add := func(id crypto.Hash, typ string, uc types.UnlockConditions) {
+		inputs = append(inputs, PartialInput{
+			ParentID:      id,
+			Type:          typ,
+			CoveredFields: types.FullCoveredFields,
+		})
+		conds = append(conds, uc)
+	}
+	for _, sci := range txn.SiacoinInputs {
+		add(crypto.Hash(sci.ParentID), PartialInputSiacoin, sci.UnlockConditions)
+	}
+	for _, sfi := range txn.SiafundInputs {
+		add(crypto.Hash(sfi.ParentID), PartialInputSiafund, sfi.UnlockConditions)
+	}
+	for _, fcr := range txn.FileContractRevisions {
+		add(crypto.Hash(fcr.ParentID), PartialInputRevision, fcr.UnlockConditions)
+	}
+	return inputs, conds
+}
+
+// NewPartialTransaction creates a partial transaction from a transaction set.
+// The last transaction of the set is the one to be signed, and the others are
+// its parents. Signatures that the transaction already has are moved into the
+// inputs of the partial transaction, and must cover the whole transaction.
+func NewPartialTransaction(txnSet []types.Transaction) (PartialTransaction, error) {
+	if len(txnSet) == 0 {
+		return PartialTransaction{}, errors.New("transaction set is empty")
+	}
+	txn := txnSet[len(txnSet)-1]
+	pt := PartialTransaction{
+		Version:     PartialTransactionVersion,
+		Transaction: txn,
+		Parents:     append([]types.Transaction(nil), txnSet[:len(txnSet)-1]...),
+	}
+	pt.Transaction.TransactionSignatures = nil
+	pt.Inputs, _ = partialInputs(txn)
+
+	for _, sig := range txn.TransactionSignatures {
+		if !sig.CoveredFields.WholeTransaction || len(sig.CoveredFields.TransactionSignatures) != 0 || sig.Timelock != 0 {
+			return PartialTransaction{}, errPartialCoveredFields
+		}
+		in := pt.input(sig.ParentID)
+		if in == nil {
+			return PartialTransaction{}, fmt.Errorf("signature of %v does not belong to an input", sig.ParentID)
+		}
+		in.Signatures = append(in.Signatures, PartialSignature{
+			PublicKeyIndex: sig.PublicKeyIndex,
+			Signature:      sig.Signature,
+		})
+	}
+	if err := pt.Validate(); err != nil {
+		return PartialTransaction{}, err
+	}
+	return pt, nil
+}
+
+// input returns the input of pt with the provided parent ID, or nil if there is
+// none.
+func (pt *PartialTransaction) input(id crypto.Hash) *PartialInput {
+	for i := range pt.Inputs {
+		if pt.Inputs[i].ParentID == id {
+			return &pt.Inputs[i]
+		}
+	}
+	return nil
+}
+
+// SigHash returns the hash that the public key at index 'keyIndex' of the
+// unlock conditions of input 'i' signs.
+func (pt PartialTransaction) SigHash(i int, keyIndex uint64) crypto.Hash {
+	txn := pt.Transaction
+	txn.TransactionSignatures = []types.TransactionSignature{{
+		ParentID:       pt.Inputs[i].ParentID,
+		PublicKeyIndex: keyIndex,
+		CoveredFields:  pt.Inputs[i].CoveredFields,
+	}}
+	return txn.SigHash(0)
+}
+
+// UnlockConditions returns the unlock conditions of input 'i' of pt.
+func (pt PartialTransaction) UnlockConditions(i int) types.UnlockConditions {
+	_, conds := partialInputs(pt.Transaction)
+	return conds[i]
+}
+
+// Complete reports whether every input of pt has enough signatures.
+func (pt PartialTransaction) Complete() bool {
+	_, conds := partialInputs(pt.Transaction)
+	if len(conds) != len(pt.Inputs) {
+		return false
+	}
+	for i, in := range pt.Inputs {
+		if uint64(len(in.Signatures)) < conds[i].SignaturesRequired {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that pt is well formed: its inputs must match the inputs of
+// its transaction, and every signature must be valid.
+func (pt PartialTransaction) Validate() error {
+	if pt.Version != PartialTransactionVersion {
+		return errPartialVersion
+	}
+	if len(pt.Transaction.TransactionSignatures) != 0 {
+		return errPartialSigned
+	}
+	inputs, conds := partialInputs(pt.Transaction)
+	if len(inputs) != len(pt.Inputs) {
+		return errors.New("inputs of the partial transaction do not match its transaction")
+	}
+	for i, in := range pt.Inputs {
+		if in.ParentID != inputs[i].ParentID || in.Type != inputs[i].Type {
+			return errors.New("inputs of the partial transaction do not match its transaction")
+		}
+		if !in.CoveredFields.WholeTransaction || len(in.CoveredFields.TransactionSignatures) != 0 {
+			return errPartialCoveredFields
+		}
+		signed := make(map[uint64]struct{})
+		for _, sig := range in.Signatures {
+			if _, exists := signed[sig.PublicKeyIndex]; exists {
+				return fmt.Errorf("input %v: public key signed twice", in.ParentID)
+			}
+			signed[sig.PublicKeyIndex] = struct{}{}
+			if err := verifyPartialSignature(conds[i], pt.SigHash(i, sig.PublicKeyIndex), sig); err != nil {
+				return fmt.Errorf("input %v: %v", in.ParentID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyPartialSignature checks that sig is a valid signature of sigHash by
+// the public key at its index of the unlock conditions.
+func verifyPartialSignature(uc types.UnlockConditions, sigHash crypto.Hash, sig PartialSignature) error {
+	if sig.PublicKeyIndex >= uint64(len(uc.PublicKeys)) {
+		return errors.New("signature public key index is out of range")
+	}
+	spk := uc.PublicKeys[sig.PublicKeyIndex]
+	if spk.Algorithm != types.SignatureEd25519 {
+		return errors.New("only ed25519 signatures are supported")
+	}
+	var pk crypto.PublicKey
+	var cs crypto.Signature
+	if len(spk.Key) != len(pk) || len(sig.Signature) != len(cs) {
+		return errors.New("invalid public key or signature length")
+	}
+	copy(pk[:], spk.Key)
+	copy(cs[:], sig.Signature)
+	return crypto.VerifyHash(sigHash, pk, cs)
+}
+
+// MergePartialTransactions combines the signatures and metadata of several
+// copies of the same partial transaction. If the copies disagree on a
+// metadata entry, the entry of the first copy is kept.
+func MergePartialTransactions(pts []PartialTransaction) (PartialTransaction, error) {
+	if len(pts) == 0 {
+		return PartialTransaction{}, errPartialNone
+	}
+	for _, pt := range pts {
+		if err := pt.Validate(); err != nil {
+			return PartialTransaction{}, err
+		}
+		if pt.Transaction.ID() != pts[0].Transaction.ID() {
+			return PartialTransaction{}, errPartialMismatch
+		}
+	}
+
+	merged := PartialTransaction{
+		Version:     PartialTransactionVersion,
+		Transaction: pts[0].Transaction,
+		Parents:     pts[0].Parents,
+		Inputs:      make([]PartialInput, len(pts[0].Inputs)),
+	}
+	for i := range merged.Inputs {
+		in := pts[0].Inputs[i]
+		in.Signatures = nil
+		signed := make(map[uint64]struct{})
+		for _, pt := range pts {
+			if in.Value.IsZero() {
+				in.Value = pt.Inputs[i].Value
+			}
+			for _, sig := range pt.Inputs[i].Signatures {
+				if _, exists := signed[sig.PublicKeyIndex]; exists {
+					continue
+				}
+				signed[sig.PublicKeyIndex] = struct{}{}
+				in.Signatures = append(in.Signatures, sig)
+			}
+		}
+		sort.Slice(in.Signatures, func(j, k int) bool {
+			return in.Signatures[j].PublicKeyIndex < in.Signatures[k].PublicKeyIndex
+		})
+		merged.Inputs[i] = in
+	}
+	for _, pt := range pts {
+		if len(pt.Parents) > len(merged.Parents) {
+			merged.Parents = pt.Parents
+		}
+		for k, v := range pt.Metadata {
+			if merged.Metadata == nil {
+				merged.Metadata = make(map[string]string)
+			}
+			if _, exists := merged.Metadata[k]; !exists {
+				merged.Metadata[k] = v
+			}
+		}
+	}
+	return merged, nil
+}
+
+// FinalizePartialTransaction moves the signatures of a complete partial
+// transaction into its transaction, and returns the transaction set, ending
+// with the signed transaction. Only as many signatures as each input requires
+// are used. The transaction is checked to be valid at 'height'.
+func FinalizePartialTransaction(pt PartialTransaction, height types.BlockHeight) ([]types.Transaction, error) {
+	if err := pt.Validate(); err != nil {
+		return nil, err
+	}
+	_, conds := partialInputs(pt.Transaction)
+	txn := pt.Transaction
+	for i, in := range pt.Inputs {
+		required := conds[i].SignaturesRequired
+		if uint64(len(in.Signatures)) < required {
+			return nil, fmt.Errorf("input %v has %v of %v signatures", in.ParentID, len(in.Signatures), required)
+		}
+		for _, sig := range in.Signatures[:required] {
+			txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+				ParentID:       in.ParentID,
+				PublicKeyIndex: sig.PublicKeyIndex,
+				CoveredFields:  in.CoveredFields,
+				Signature:      sig.Signature,
+			})
+		}
+	}
+	if err := txn.StandaloneValid(height); err != nil {
+		return nil, err
+	}
+	return append(append([]types.Transaction(nil), pt.Parents...), txn), nil
+}
+
+// hasPublicKey reports whether a public key of the unlock conditions is the
+// ed25519 key pk, and returns its index.
+func hasPublicKey(uc types.UnlockConditions, pk crypto.PublicKey) (uint64, bool) {
+	for i, spk := range uc.PublicKeys {
+		if spk.Algorithm == types.SignatureEd25519 && bytes.Equal(spk.Key, pk[:]) {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+// Sign adds signatures to the inputs of pt with the secret keys 'keys', until
+// each input has as many signatures as it requires. It returns the number of
+// signatures that were added.
+func (pt *PartialTransaction) Sign(keys []crypto.SecretKey) int {
+	_, conds := partialInputs(pt.Transaction)
+	var added int
+	for i := range pt.Inputs {
+		in := &pt.Inputs[i]
+		signed := make(map[uint64]struct{})
+		for _, sig := range in.Signatures {
+			signed[sig.PublicKeyIndex] = struct{}{}
+		}
+		for _, sk := range keys {
+			if uint64(len(in.Signatures)) >= conds[i].SignaturesRequired {
+				break
+			}
+			index, ok := hasPublicKey(conds[i], sk.PublicKey())
+			if _, done := signed[index]; !ok || done {
+				continue
+			}
+			sig := crypto.SignHash(pt.SigHash(i, index), sk)
+			in.Signatures = append(in.Signatures, PartialSignature{
+				PublicKeyIndex: index,
+				Signature:      sig[:],
+			})
+			signed[index] = struct{}{}
+			added++
+		}
+		sort.Slice(in.Signatures, func(j, k int) bool {
+			return in.Signatures[j].PublicKeyIndex < in.Signatures[k].PublicKeyIndex
+		})
+	}
+	return added
+}
@@ -0,0 +1,102 @@
+package modules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestPartialTransactionMultisig checks that the cosigners of a multisig
+// address can sign copies of a partial transaction independently, and that the
+// merged copies can be finalized into a valid transaction.
+func TestPartialTransactionMultisig(t *testing.T) {
+	var sks [3]crypto.SecretKey
+	uc := types.UnlockConditions{SignaturesRequired: 2}
+	for i := range sks {
+		var pk crypto.PublicKey
+		sks[i], pk = crypto.GenerateKeyPair()
+		uc.PublicKeys = append(uc.PublicKeys, types.Ed25519PublicKey(pk))
+	}
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         types.SiacoinOutputID{1},
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value:      types.SiacoinPrecision,
+			UnlockHash: types.UnlockHash{2},
+		}},
+	}
+
+	pt, err := NewPartialTransaction([]types.Transaction{txn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pt.Inputs) != 1 || pt.Inputs[0].Type != PartialInputSiacoin || pt.Complete() {
+		t.Fatal("unexpected partial transaction:", pt)
+	}
+
+	// Each cosigner signs a copy that has passed through JSON.
+	var copies []PartialTransaction
+	for _, sk := range []crypto.SecretKey{sks[0], sks[2]} {
+		data, err := json.Marshal(pt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cp PartialTransaction
+		if err := json.Unmarshal(data, &cp); err != nil {
+			t.Fatal(err)
+		}
+		if n := cp.Sign([]crypto.SecretKey{sk}); n != 1 {
+			t.Fatal("expected one signature, got", n)
+		}
+		if err := cp.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		copies = append(copies, cp)
+	}
+	if _, err := FinalizePartialTransaction(copies[0], 0); err == nil {
+		t.Fatal("partial transaction with one of two signatures was finalized")
+	}
+
+	merged, err := MergePartialTransactions(copies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged.Complete() || len(merged.Inputs[0].Signatures) != 2 {
+		t.Fatal("signatures were not merged:", merged.Inputs[0].Signatures)
+	}
+	txns, err := FinalizePartialTransaction(merged, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].ID() != txn.ID() || len(txns[0].TransactionSignatures) != 2 {
+		t.Fatal("unexpected finalized transaction:", txns)
+	}
+
+	// A finalized transaction can be turned back into a partial transaction.
+	back, err := NewPartialTransaction(txns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !back.Complete() {
+		t.Fatal("signatures were lost")
+	}
+
+	// Tampered signatures and copies of other transactions are rejected.
+	copies[1].Inputs[0].Signatures[0].Signature[0] ^= 1
+	if _, err := MergePartialTransactions(copies); err == nil {
+		t.Fatal("partial transaction with an invalid signature was merged")
+	}
+	other := pt
+	other.Transaction.SiacoinOutputs = []types.SiacoinOutput{{UnlockHash: types.UnlockHash{3}}}
+	if _, err := MergePartialTransactions([]PartialTransaction{pt, other}); err != errPartialMismatch {
+		t.Fatal("expected errPartialMismatch, got", err)
+	}
+	pt.Version = 2
+	if err := pt.Validate(); err != errPartialVersion {
+		t.Fatal("expected errPartialVersion, got", err)
+	}
+}
@@ -73,6 +73,33 @@ type FileUploadParams struct {
 	ErasureCode ErasureCoder
 }
 
+// RenterFileUpdate describes the changes made by updating an uploaded file.
+type RenterFileUpdate struct {
+	// Chunks is the number of chunks in the updated file, and ChangedChunks
+	// is the number of those chunks that must be re-uploaded.
+	Chunks        uint64 `json:"chunks"`
+	ChangedChunks uint64 `json:"changedchunks"`
+}
+
+// RenterPrefetchInfo describes the chunks of a file that were requested for
+// prefetching into the local cache.
+type RenterPrefetchInfo struct {
+	SiaPath string `json:"siapath"`
+
+	// Chunks is the number of chunks requested out of the TotalChunks of the
+	// file, and CachedChunks is the number of those that are resident in the
+	// cache, holding CachedBytes of data.
+	Chunks       uint64 `json:"chunks"`
+	TotalChunks  uint64 `json:"totalchunks"`
+	CachedChunks uint64 `json:"cachedchunks"`
+	CachedBytes  uint64 `json:"cachedbytes"`
+
+	// Active is true while chunks are being downloaded. Error is the reason
+	// prefetching stopped before every requested chunk was cached.
+	Active bool   `json:"active"`
+	Error  string `json:"error"`
+}
+
 // FileInfo provides information about a file.
 type FileInfo struct {
 	SiaPath        string            `json:"siapath"`
@@ -82,6 +109,45 @@ type FileInfo struct {
 	Redundancy     float64           `json:"redundancy"`
 	UploadProgress float64           `json:"uploadprogress"`
 	Expiration     types.BlockHeight `json:"expiration"`
+
+	// Recoverable indicates that a copy of the file's metadata is stored on
+	// at least one host, so that the file can be recovered with RecoverFiles
+	// if the renter's metadata is lost.
+	Recoverable bool `json:"recoverable"`
+
+	// Inline indicates that the file is small enough that its contents are
+	// stored in the renter's metadata rather than on hosts. Inline files are
+	// always available, and their redundancy is -1.
+	Inline bool `json:"inline"`
+}
+
+// A FileChunkHosts lists the hosts storing the pieces of one chunk of a file.
+type FileChunkHosts struct {
+	Chunk  uint64          `json:"chunk"`
+	Pieces []FilePieceHost `json:"pieces"`
+}
+
+// A FilePieceHost describes a piece of a file and the host storing it. A
+// piece may be stored on more than one host, in which case it appears once for
+// each host.
+type FilePieceHost struct {
+	Piece      uint64      `json:"piece"`
+	MerkleRoot crypto.Hash `json:"merkleroot"`
+
+	// The contract covering the piece, and the host it was formed with. The
+	// host's public key is empty if the contract is no longer known to the
+	// renter.
+	ContractID    types.FileContractID `json:"contractid"`
+	HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+	NetAddress    NetAddress           `json:"netaddress"`
+
+	// Online is false if the renter considers the host offline. Score is the
+	// host's current score in the hostdb, and Uptime is the fraction of the
+	// time the host was found online when scanned. Both are zero if the host
+	// is not in the hostdb.
+	Online bool           `json:"online"`
+	Score  types.Currency `json:"score"`
+	Uptime float64        `json:"uptime"`
 }
 
 // A HostDBEntry represents one host entry in the Renter's host DB. It
@@ -157,7 +223,37 @@ type RenterPriceEstimation struct {
 
 // RenterSettings control the behavior of the Renter.
 type RenterSettings struct {
-	Allowance Allowance `json:"allowance"`
+	Allowance Allowance      `json:"allowance"`
+	Schedule  RenterSchedule `json:"schedule"`
+}
+
+// RenterSchedule restricts when the renter uploads and repairs files, so that
+// its background traffic does not compete with other uses of the network.
+// Downloads are not affected. The zero value places no restrictions.
+type RenterSchedule struct {
+	// WindowStart and WindowEnd are the hours of the day, in local time,
+	// between which uploads and repairs run. The window wraps around midnight
+	// if WindowEnd is before WindowStart. If they are equal, uploads and
+	// repairs run at any time of day.
+	WindowStart int `json:"windowstart"`
+	WindowEnd   int `json:"windowend"`
+
+	// MaxSystemTraffic pauses uploads and repairs while the network traffic
+	// of the machine that is not caused by the renter exceeds this many bytes
+	// per second. Zero disables the limit.
+	MaxSystemTraffic uint64 `json:"maxsystemtraffic"`
+}
+
+// InWindow returns true if uploads and repairs may run at time t according to
+// the window of the schedule.
+func (rs RenterSchedule) InWindow(t time.Time) bool {
+	hour := t.Hour()
+	if rs.WindowStart == rs.WindowEnd {
+		return true
+	} else if rs.WindowStart < rs.WindowEnd {
+		return hour >= rs.WindowStart && hour < rs.WindowEnd
+	}
+	return hour >= rs.WindowStart || hour < rs.WindowEnd
 }
 
 // HostDBScans represents a sortable slice of scans.
@@ -257,6 +353,59 @@ type RenterContract struct {
 	PreviousContracts []RenterContract
 }
 
+// RenterContractRecovery reports the outcome of reattaching a contract to the
+// renter by its ID. Error explains why a contract could not be recovered.
+type RenterContractRecovery struct {
+	ID             types.FileContractID `json:"id"`
+	Recovered      bool                 `json:"recovered"`
+	NetAddress     NetAddress           `json:"netaddress"`
+	RevisionNumber uint64               `json:"revisionnumber"`
+	Error          string               `json:"error,omitempty"`
+}
+
+// RenterBandwidth records the data that the renter exchanged with a host,
+// alongside what it paid the host for bandwidth. Unlike the spending of a
+// contract, it includes payments for transfers that failed.
+type RenterBandwidth struct {
+	HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+	NetAddress    NetAddress         `json:"netaddress"`
+
+	// UploadBytes and DownloadBytes are the bytes sent to and received from
+	// the host, including protocol overhead.
+	UploadBytes   uint64 `json:"uploadbytes"`
+	DownloadBytes uint64 `json:"downloadbytes"`
+
+	// UploadSpending and DownloadSpending are the bandwidth payments signed
+	// by the renter, which paid for UploadPaidBytes and DownloadPaidBytes of
+	// sector data. UploadUsedBytes is the data that the host accepted, and
+	// DownloadUsedBytes the data that the host delivered and that passed
+	// verification.
+	UploadSpending    types.Currency `json:"uploadspending"`
+	DownloadSpending  types.Currency `json:"downloadspending"`
+	UploadPaidBytes   uint64         `json:"uploadpaidbytes"`
+	DownloadPaidBytes uint64         `json:"downloadpaidbytes"`
+	UploadUsedBytes   uint64         `json:"uploadusedbytes"`
+	DownloadUsedBytes uint64         `json:"downloadusedbytes"`
+}
+
+// UploadEfficiency returns the fraction of the upload data paid for that the
+// host accepted. It is 1 if nothing was paid for.
+func (rb RenterBandwidth) UploadEfficiency() float64 {
+	if rb.UploadPaidBytes == 0 {
+		return 1
+	}
+	return float64(rb.UploadUsedBytes) / float64(rb.UploadPaidBytes)
+}
+
+// DownloadEfficiency returns the fraction of the download data paid for that
+// was received. It is 1 if nothing was paid for.
+func (rb RenterBandwidth) DownloadEfficiency() float64 {
+	if rb.DownloadPaidBytes == 0 {
+		return 1
+	}
+	return float64(rb.DownloadUsedBytes) / float64(rb.DownloadPaidBytes)
+}
+
 // EndHeight returns the height at which the host is no longer obligated to
 // store contract data.
 func (rc *RenterContract) EndHeight() types.BlockHeight {
@@ -283,6 +432,10 @@ type Renter interface {
 	// AllHosts returns the full list of hosts known to the renter.
 	AllHosts() []HostDBEntry
 
+	// Bandwidth returns the data exchanged with each host that the renter
+	// has uploaded to or downloaded from.
+	Bandwidth() []RenterBandwidth
+
 	// Close closes the Renter.
 	Close() error
 
@@ -306,6 +459,10 @@ type Renter interface {
 	// FileList returns information on all of the files stored by the renter.
 	FileList() []FileInfo
 
+	// FileHosts returns the hosts storing each piece of a file, grouped by
+	// chunk.
+	FileHosts(siaPath string) ([]FileChunkHosts, error)
+
 	// Host provides the DB entry and score breakdown for the requested host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool)
 
@@ -317,10 +474,32 @@ type Renter interface {
 	// renter.
 	LoadSharedFilesAscii(asciiSia string) ([]string, error)
 
+	// Prefetch downloads chunks of a file into a local cache in the
+	// background, so that later downloads of them do not contact any hosts.
+	// All chunks are prefetched if chunks is empty.
+	Prefetch(siaPath string, chunks []uint64) error
+
+	// PrefetchInfo returns the progress of the files that have chunks
+	// requested for prefetching.
+	PrefetchInfo() []RenterPrefetchInfo
+
+	// EvictPrefetched removes the cached chunks of a file.
+	EvictPrefetched(siaPath string) error
+
 	// PriceEstimation estimates the cost in siacoins of performing various
 	// storage and data operations.
 	PriceEstimation() RenterPriceEstimation
 
+	// RecoverFiles rebuilds the file list from the copies of the file
+	// metadata stored on the hosts of the renter's contracts. The paths of the
+	// recovered files are returned.
+	RecoverFiles() ([]string, error)
+
+	// RecoverContracts reattaches contracts that the renter no longer uses,
+	// identified by their IDs, so that their data can be downloaded. The
+	// latest revision of each contract is fetched from its host.
+	RecoverContracts(ids []types.FileContractID) []RenterContractRecovery
+
 	// RenameFile changes the path of a file.
 	RenameFile(path, newPath string) error
 
@@ -346,6 +525,10 @@ type Renter interface {
 
 	// Upload uploads a file using the input parameters.
 	Upload(FileUploadParams) error
+
+	// UpdateFile replaces the contents of an uploaded file with a local
+	// file, re-uploading only the chunks that changed.
+	UpdateFile(siaPath, source string) (RenterFileUpdate, error)
 }
 
 // RenterDownloadParameters defines the parameters passed to the Renter's
@@ -29,6 +29,42 @@ var (
 		Testing:  3,
 	}).(int)
 
+	// inlineThreshold is the size in bytes of the largest file that is stored
+	// inline in the renter's metadata rather than uploaded to hosts. It is
+	// zero in testing so that the integration tests, which upload small
+	// files, exercise the hosts.
+	inlineThreshold = build.Select(build.Var{
+		Dev:      uint64(4096),
+		Standard: uint64(4096),
+		Testing:  uint64(0),
+	}).(uint64)
+
+	// metadataUpdateInterval is how often the renter checks whether the
+	// metadata sectors stored on its hosts need to be updated. Tests write
+	// the metadata sectors explicitly, so that the sectors do not change the
+	// contents of the contracts in other tests.
+	metadataUpdateInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute * 10,
+		Testing:  time.Hour,
+	}).(time.Duration)
+
+	// scheduleCheckInterval is how often the repair loop checks whether the
+	// renter's schedule allows uploads again while they are paused.
+	scheduleCheckInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: time.Minute,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// trafficSampleInterval is the minimum time between measurements of the
+	// network traffic of the machine.
+	trafficSampleInterval = build.Select(build.Var{
+		Dev:      5 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
 	repairQueueInterval = build.Select(build.Var{
 		Dev:      30 * time.Second,
 		Standard: time.Minute * 15,
@@ -3,6 +3,7 @@ package contractor
 import (
 	"errors"
 
+	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -11,12 +12,16 @@ var (
 	errAllowanceNoHosts    = errors.New("hosts must be non-zero")
 	errAllowanceZeroPeriod = errors.New("period must be non-zero")
 	errAllowanceWindowSize = errors.New("renew window must be less than period")
-	errAllowanceNotSynced  = errors.New("you must be synced to set an allowance")
+	errAllowanceNotSynced  = build.ExtendErr("cannot set an allowance", modules.ErrNotSynced)
 
 	// ErrAllowanceZeroWindow is returned when the caller requests a
 	// zero-length renewal window. This will happen if the caller sets the
 	// period to 1 block, since RenewWindow := period / 2.
 	ErrAllowanceZeroWindow = errors.New("renew window must be non-zero")
+
+	// ErrRecoveryMode is returned when an allowance is set on a contractor
+	// that was started in recovery mode.
+	ErrRecoveryMode = errors.New("contracts cannot be formed or renewed in recovery mode")
 )
 
 // SetAllowance sets the amount of money the Contractor is allowed to spend on
@@ -40,6 +45,9 @@ var (
 // NOTE: At this time, transaction fees are not counted towards the allowance.
 // This means the contractor may spend more than allowance.Funds.
 func (c *Contractor) SetAllowance(a modules.Allowance) error {
+	if c.recovery {
+		return ErrRecoveryMode
+	}
 	if a.Funds.IsZero() && a.Hosts == 0 && a.Period == 0 && a.RenewWindow == 0 {
 		return c.managedCancelAllowance(a)
 	}
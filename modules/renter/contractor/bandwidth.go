@@ -0,0 +1,58 @@
+package contractor
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// Bandwidth returns the data exchanged with each host that the contractor has
+// uploaded to or downloaded from, ordered by host public key.
+func (c *Contractor) Bandwidth() []modules.RenterBandwidth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.bandwidth))
+	for key := range c.bandwidth {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	bandwidth := make([]modules.RenterBandwidth, 0, len(keys))
+	for _, key := range keys {
+		bandwidth = append(bandwidth, c.bandwidth[key])
+	}
+	return bandwidth
+}
+
+// recordBandwidth returns a function that adds the bandwidth reports of a
+// Downloader or Editor to the bandwidth of a host. The records are not
+// journaled; they are saved whenever the rest of the contractor's persistence
+// is, which happens at least once per block.
+func (c *Contractor) recordBandwidth(host types.SiaPublicKey, addr modules.NetAddress) func(proto.BandwidthReport) {
+	key := host.String()
+	return func(r proto.BandwidthReport) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		rb := c.bandwidth[key]
+		rb.HostPublicKey = host
+		rb.NetAddress = addr
+		rb.UploadBytes += r.Sent
+		rb.DownloadBytes += r.Received
+		switch {
+		case r.Upload && r.Paid:
+			rb.UploadSpending = rb.UploadSpending.Add(r.Price)
+			rb.UploadPaidBytes += r.Data
+		case !r.Upload && r.Paid:
+			rb.DownloadSpending = rb.DownloadSpending.Add(r.Price)
+			rb.DownloadPaidBytes += r.Data
+		}
+		switch {
+		case r.Upload && r.Delivered:
+			rb.UploadUsedBytes += r.Data
+		case !r.Upload && r.Delivered:
+			rb.DownloadUsedBytes += r.Data
+		}
+		c.bandwidth[key] = rb
+	}
+}
@@ -49,11 +49,16 @@ type Contractor struct {
 	// Only one thread should be performing contract maintenance at a time.
 	maintenanceLock siasync.TryMutex
 
+	// recovery indicates that the contractor only uses its existing
+	// contracts, and never forms or renews contracts.
+	recovery bool
+
 	allowance     modules.Allowance
 	blockHeight   types.BlockHeight
 	currentPeriod types.BlockHeight
 	lastChange    modules.ConsensusChangeID
 
+	bandwidth   map[string]modules.RenterBandwidth // keyed by host public key
 	downloaders map[types.FileContractID]*hostDownloader
 	editors     map[types.FileContractID]*hostEditor
 	renewing    map[types.FileContractID]bool // prevent revising during renewal
@@ -158,6 +163,17 @@ func (c *Contractor) GoodForRenew(id types.FileContractID) bool {
 
 // New returns a new Contractor.
 func New(cs consensusSet, wallet walletShim, tpool transactionPool, hdb hostDB, persistDir string) (*Contractor, error) {
+	return newProductionContractor(cs, wallet, tpool, hdb, persistDir, false)
+}
+
+// NewRecovery returns a Contractor that only uses the contracts it already
+// has. It never forms or renews contracts, and refuses to set an allowance.
+func NewRecovery(cs consensusSet, wallet walletShim, tpool transactionPool, hdb hostDB, persistDir string) (*Contractor, error) {
+	return newProductionContractor(cs, wallet, tpool, hdb, persistDir, true)
+}
+
+// newProductionContractor creates a Contractor using production dependencies.
+func newProductionContractor(cs consensusSet, wallet walletShim, tpool transactionPool, hdb hostDB, persistDir string, recovery bool) (*Contractor, error) {
 	// Check for nil inputs.
 	if cs == nil {
 		return nil, errNilCS
@@ -179,22 +195,27 @@ func New(cs consensusSet, wallet walletShim, tpool transactionPool, hdb hostDB,
 	if err != nil {
 		return nil, err
 	}
+	if recovery {
+		logger.Println("INFO: contractor started in recovery mode")
+	}
 
 	// Create Contractor using production dependencies.
-	return newContractor(cs, &walletBridge{w: wallet}, tpool, hdb, newPersist(persistDir), logger)
+	return newContractor(cs, &walletBridge{w: wallet}, tpool, hdb, newPersist(persistDir), logger, recovery)
 }
 
 // newContractor creates a Contractor using the provided dependencies.
-func newContractor(cs consensusSet, w wallet, tp transactionPool, hdb hostDB, p persister, l *persist.Logger) (*Contractor, error) {
+func newContractor(cs consensusSet, w wallet, tp transactionPool, hdb hostDB, p persister, l *persist.Logger, recovery bool) (*Contractor, error) {
 	// Create the Contractor object.
 	c := &Contractor{
-		cs:      cs,
-		hdb:     hdb,
-		log:     l,
-		persist: p,
-		tpool:   tp,
-		wallet:  w,
-
+		cs:       cs,
+		hdb:      hdb,
+		log:      l,
+		persist:  p,
+		tpool:    tp,
+		wallet:   w,
+		recovery: recovery,
+
+		bandwidth:       make(map[string]modules.RenterBandwidth),
 		cachedRevisions: make(map[types.FileContractID]cachedRevision),
 		contracts:       make(map[types.FileContractID]modules.RenterContract),
 		downloaders:     make(map[types.FileContractID]*hostDownloader),
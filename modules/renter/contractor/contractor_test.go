@@ -8,6 +8,7 @@ import (
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -183,6 +184,42 @@ func TestAllowance(t *testing.T) {
 	}
 }
 
+// TestBandwidth tests that bandwidth reports are added to the records of
+// their host.
+func TestBandwidth(t *testing.T) {
+	c := &Contractor{
+		bandwidth: make(map[string]modules.RenterBandwidth),
+	}
+	host := types.SiaPublicKey{Key: []byte("foo")}
+	record := c.recordBandwidth(host, "foo:1234")
+	price := types.NewCurrency64(10)
+	// A successful upload, a successful download, and a download that was
+	// paid for but never delivered.
+	record(proto.BandwidthReport{Upload: true, Sent: 110, Received: 10, Price: price, Data: 100, Paid: true, Delivered: true})
+	record(proto.BandwidthReport{Sent: 10, Received: 110, Price: price, Data: 100, Paid: true, Delivered: true})
+	record(proto.BandwidthReport{Sent: 10, Received: 5, Price: price, Data: 100, Paid: true})
+	// A failed download that was not paid for.
+	record(proto.BandwidthReport{Sent: 10, Price: price, Data: 100})
+
+	bandwidth := c.Bandwidth()
+	if len(bandwidth) != 1 {
+		t.Fatal("expected one host, got", len(bandwidth))
+	}
+	rb := bandwidth[0]
+	if rb.NetAddress != "foo:1234" || rb.UploadBytes != 140 || rb.DownloadBytes != 125 {
+		t.Fatal("wrong bytes recorded:", rb)
+	}
+	if rb.UploadPaidBytes != 100 || rb.UploadUsedBytes != 100 || rb.UploadSpending.Cmp(price) != 0 {
+		t.Fatal("wrong upload recorded:", rb)
+	}
+	if rb.DownloadPaidBytes != 200 || rb.DownloadUsedBytes != 100 || rb.DownloadSpending.Cmp(price.Mul64(2)) != 0 {
+		t.Fatal("wrong download recorded:", rb)
+	}
+	if rb.UploadEfficiency() != 1 || rb.DownloadEfficiency() != 0.5 {
+		t.Fatal("wrong efficiencies:", rb.UploadEfficiency(), rb.DownloadEfficiency())
+	}
+}
+
 // stubHostDB mocks the hostDB dependency using zero-valued implementations of
 // its methods.
 type stubHostDB struct{}
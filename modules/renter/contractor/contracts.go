@@ -129,6 +129,14 @@ func (c *Contractor) managedMarkContractsUtility() {
 		contracts[i].GoodForUpload = true
 		contracts[i].GoodForRenew = true
 
+		// Contract has no utility if some of its Merkle roots are unknown, as
+		// is the case for recovered contracts; revising it would fail.
+		if uint64(len(contracts[i].MerkleRoots))*modules.SectorSize < contracts[i].LastRevision.NewFileSize {
+			contracts[i].GoodForUpload = false
+			contracts[i].GoodForRenew = false
+			continue
+		}
+
 		host, exists := c.hdb.Host(contracts[i].HostPublicKey)
 		// Contract has no utility if the host is not in the database.
 		if !exists {
@@ -315,11 +323,12 @@ func (c *Contractor) threadedContractMaintenance() {
 		return
 	}
 	defer c.tg.Done()
-	// Nohting to do if there are no hosts.
+	// Nohting to do if there are no hosts, or if the contractor is not
+	// allowed to touch its contracts.
 	c.mu.RLock()
 	wantedHosts := c.allowance.Hosts
 	c.mu.RUnlock()
-	if wantedHosts <= 0 {
+	if wantedHosts <= 0 || c.recovery {
 		return
 	}
 	// Only one instance of this thread should be running at a time. Under
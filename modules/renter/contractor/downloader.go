@@ -201,6 +201,7 @@ func (c *Contractor) Downloader(id types.FileContractID, cancel <-chan struct{})
 	// supply a SaveFn that saves the revision to the contractor's persist
 	// (the existing revision will be overwritten when SaveFn is called)
 	d.SaveFn = c.saveDownloadRevision(contract.ID)
+	d.BandwidthFn = c.recordBandwidth(host.PublicKey, host.NetAddress)
 
 	// cache downloader
 	hd := &hostDownloader{
@@ -136,6 +136,13 @@ func (he *hostEditor) Delete(root crypto.Hash) (err error) {
 	if he.invalid {
 		return errInvalidEditor
 	}
+	index := -1
+	for i, h := range he.contract.MerkleRoots {
+		if h == root {
+			index = i
+			break
+		}
+	}
 	contract, err := he.editor.Delete(root)
 	if err != nil {
 		return err
@@ -143,7 +150,10 @@ func (he *hostEditor) Delete(root crypto.Hash) (err error) {
 
 	he.contractor.mu.Lock()
 	he.contractor.contracts[contract.ID] = contract
-	he.contractor.saveSync()
+	he.contractor.persist.update(updateDeleteRevision{
+		NewRevisionTxn: contract.LastRevisionTxn,
+		SectorIndex:    index,
+	})
 	he.contractor.mu.Unlock()
 	he.contract = contract
 
@@ -269,6 +279,7 @@ func (c *Contractor) Editor(id types.FileContractID, cancel <-chan struct{}) (_
 	// supply a SaveFn that saves the revision to the contractor's persist
 	// (the existing revision will be overwritten when SaveFn is called)
 	e.SaveFn = c.saveUploadRevision(contract.ID)
+	e.BandwidthFn = c.recordBandwidth(host.PublicKey, host.NetAddress)
 
 	// cache editor
 	he := &hostEditor{
@@ -84,6 +84,18 @@ func newTestingHost(testdir string, cs modules.ConsensusSet, tp modules.Transact
 	if err != nil {
 		return nil, err
 	}
+	// wait for the storage folder to be initialized
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		for _, sf := range h.StorageFolders() {
+			if sf.Status == modules.StorageFolderStatusInitializing {
+				return errors.New("storage folder is still initializing")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return h, nil
 }
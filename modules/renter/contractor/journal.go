@@ -228,10 +228,14 @@ func (set updateSet) MarshalJSON() ([]byte, error) {
 			marshaledSet[i].Type = "uploadRevision"
 		case updateDownloadRevision:
 			marshaledSet[i].Type = "downloadRevision"
+		case updateDeleteRevision:
+			marshaledSet[i].Type = "deleteRevision"
 		case updateCachedUploadRevision:
 			marshaledSet[i].Type = "cachedUploadRevision"
 		case updateCachedDownloadRevision:
 			marshaledSet[i].Type = "cachedDownloadRevision"
+		case updateCachedDeleteRevision:
+			marshaledSet[i].Type = "cachedDeleteRevision"
 		}
 	}
 	return json.Marshal(marshaledSet)
@@ -254,6 +258,10 @@ func (set *updateSet) UnmarshalJSON(b []byte) error {
 			var ur updateUploadRevision
 			err = json.Unmarshal(u.Data, &ur)
 			*set = append(*set, ur)
+		case "deleteRevision":
+			var dr updateDeleteRevision
+			err = json.Unmarshal(u.Data, &dr)
+			*set = append(*set, dr)
 		case "downloadRevision":
 			var dr updateDownloadRevision
 			err = json.Unmarshal(u.Data, &dr)
@@ -266,6 +274,10 @@ func (set *updateSet) UnmarshalJSON(b []byte) error {
 			var cdr updateCachedDownloadRevision
 			err = json.Unmarshal(u.Data, &cdr)
 			*set = append(*set, cdr)
+		case "cachedDeleteRevision":
+			var cdr updateCachedDeleteRevision
+			err = json.Unmarshal(u.Data, &cdr)
+			*set = append(*set, cdr)
 		}
 		if err != nil {
 			return err
@@ -311,6 +323,32 @@ func (u updateUploadRevision) apply(data *contractorPersist) {
 	data.Contracts[rev.ParentID.String()] = c
 }
 
+// updateDeleteRevision is a journalUpdate that records the new data
+// associated with deleting a sector from a host.
+type updateDeleteRevision struct {
+	NewRevisionTxn types.Transaction `json:"newrevisiontxn"`
+	SectorIndex    int               `json:"sectorindex"`
+}
+
+// apply sets the LastRevision and LastRevisionTxn fields of the contract
+// being revised. It also removes the deleted Merkle root from the contract's
+// Merkle root set.
+func (u updateDeleteRevision) apply(data *contractorPersist) {
+	if len(u.NewRevisionTxn.FileContractRevisions) == 0 {
+		build.Critical("updateDeleteRevision is missing its FileContractRevision")
+		return
+	}
+
+	rev := u.NewRevisionTxn.FileContractRevisions[0]
+	c := data.Contracts[rev.ParentID.String()]
+	c.LastRevisionTxn = u.NewRevisionTxn
+	c.LastRevision = rev
+	if u.SectorIndex < len(c.MerkleRoots) {
+		c.MerkleRoots = append(c.MerkleRoots[:u.SectorIndex], c.MerkleRoots[u.SectorIndex+1:]...)
+	}
+	data.Contracts[rev.ParentID.String()] = c
+}
+
 // updateUploadRevision is a journalUpdate that records the new data
 // associated with downloading a sector from a host.
 type updateDownloadRevision struct {
@@ -370,3 +408,22 @@ func (u updateCachedDownloadRevision) apply(data *contractorPersist) {
 	c.Revision = u.Revision
 	data.CachedRevisions[u.Revision.ParentID.String()] = c
 }
+
+// updateCachedDeleteRevision is a journalUpdate that records the unsigned
+// revision sent to the host during a sector deletion, along with the index of
+// the deleted sector.
+type updateCachedDeleteRevision struct {
+	Revision    types.FileContractRevision `json:"revision"`
+	SectorIndex int                        `json:"sectorindex"`
+}
+
+// apply sets the Revision field of the cachedRevision associated with the
+// contract being revised, and removes the Merkle root of the deleted sector.
+func (u updateCachedDeleteRevision) apply(data *contractorPersist) {
+	c := data.CachedRevisions[u.Revision.ParentID.String()]
+	c.Revision = u.Revision
+	if u.SectorIndex < len(c.MerkleRoots) {
+		c.MerkleRoots = append(c.MerkleRoots[:u.SectorIndex], c.MerkleRoots[u.SectorIndex+1:]...)
+	}
+	data.CachedRevisions[u.Revision.ParentID.String()] = c
+}
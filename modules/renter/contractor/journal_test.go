@@ -11,6 +11,7 @@ import (
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -67,6 +68,56 @@ func TestJournal(t *testing.T) {
 	}
 }
 
+// TestJournalDeleteRevision tests that delete revisions survive a round trip
+// through the journal and remove the deleted Merkle roots.
+func TestJournalDeleteRevision(t *testing.T) {
+	j, cleanup := tempJournal(t)
+	defer cleanup()
+
+	roots := []crypto.Hash{{1}, {2}, {3}}
+	txn := types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{{}},
+	}
+	us := []journalUpdate{
+		updateCachedUploadRevision{SectorRoot: roots[0], SectorIndex: 0},
+		updateCachedUploadRevision{SectorRoot: roots[1], SectorIndex: 1},
+		updateCachedUploadRevision{SectorRoot: roots[2], SectorIndex: 2},
+		updateCachedDeleteRevision{SectorIndex: 1},
+		updateUploadRevision{NewRevisionTxn: txn, NewSectorRoot: roots[0], NewSectorIndex: 0},
+		updateUploadRevision{NewRevisionTxn: txn, NewSectorRoot: roots[1], NewSectorIndex: 1},
+		updateUploadRevision{NewRevisionTxn: txn, NewSectorRoot: roots[2], NewSectorIndex: 2},
+		updateDeleteRevision{NewRevisionTxn: txn, SectorIndex: 1},
+	}
+	if err := j.update(us); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var data contractorPersist
+	j2, err := openJournal(j.filename, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j2.Close()
+	c, ok := data.CachedRevisions[types.FileContractID{}.String()]
+	if !ok {
+		t.Fatal("openJournal applied updates incorrectly:", data)
+	}
+	exp := modules.MerkleRootSet{roots[0], roots[2]}
+	if !reflect.DeepEqual(c.MerkleRoots, exp) {
+		t.Fatalf("expected roots %v, got %v", exp, c.MerkleRoots)
+	}
+	contract, ok := data.Contracts[types.FileContractID{}.String()]
+	if !ok {
+		t.Fatal("openJournal applied updates incorrectly:", data)
+	}
+	if !reflect.DeepEqual(contract.MerkleRoots, exp) {
+		t.Fatalf("expected roots %v, got %v", exp, contract.MerkleRoots)
+	}
+}
+
 func TestJournalCheckpoint(t *testing.T) {
 	j, cleanup := tempJournal(t)
 	defer cleanup()
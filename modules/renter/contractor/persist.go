@@ -11,20 +11,22 @@ import (
 
 // contractorPersist defines what Contractor data persists across sessions.
 type contractorPersist struct {
-	Allowance       modules.Allowance                 `json:"allowance"`
-	BlockHeight     types.BlockHeight                 `json:"blockheight"`
-	CachedRevisions map[string]cachedRevision         `json:"cachedrevisions"`
-	Contracts       map[string]modules.RenterContract `json:"contracts"`
-	CurrentPeriod   types.BlockHeight                 `json:"currentperiod"`
-	LastChange      modules.ConsensusChangeID         `json:"lastchange"`
-	OldContracts    []modules.RenterContract          `json:"oldcontracts"`
-	RenewedIDs      map[string]string                 `json:"renewedids"`
+	Allowance       modules.Allowance                  `json:"allowance"`
+	Bandwidth       map[string]modules.RenterBandwidth `json:"bandwidth"`
+	BlockHeight     types.BlockHeight                  `json:"blockheight"`
+	CachedRevisions map[string]cachedRevision          `json:"cachedrevisions"`
+	Contracts       map[string]modules.RenterContract  `json:"contracts"`
+	CurrentPeriod   types.BlockHeight                  `json:"currentperiod"`
+	LastChange      modules.ConsensusChangeID          `json:"lastchange"`
+	OldContracts    []modules.RenterContract           `json:"oldcontracts"`
+	RenewedIDs      map[string]string                  `json:"renewedids"`
 }
 
 // persistData returns the data in the Contractor that will be saved to disk.
 func (c *Contractor) persistData() contractorPersist {
 	data := contractorPersist{
 		Allowance:       c.allowance,
+		Bandwidth:       make(map[string]modules.RenterBandwidth),
 		BlockHeight:     c.blockHeight,
 		CachedRevisions: make(map[string]cachedRevision),
 		Contracts:       make(map[string]modules.RenterContract),
@@ -32,6 +34,9 @@ func (c *Contractor) persistData() contractorPersist {
 		LastChange:      c.lastChange,
 		RenewedIDs:      make(map[string]string),
 	}
+	for key, rb := range c.bandwidth {
+		data.Bandwidth[key] = rb
+	}
 	for _, rev := range c.cachedRevisions {
 		data.CachedRevisions[rev.Revision.ParentID.String()] = rev
 	}
@@ -56,6 +61,9 @@ func (c *Contractor) load() error {
 		return err
 	}
 	c.allowance = data.Allowance
+	for key, rb := range data.Bandwidth {
+		c.bandwidth[key] = rb
+	}
 	c.blockHeight = data.BlockHeight
 	for _, rev := range data.CachedRevisions {
 		c.cachedRevisions[rev.Revision.ParentID] = rev
@@ -130,12 +138,31 @@ func (c *Contractor) saveSync() error {
 }
 
 // saveUploadRevision returns a function that saves an upload revision. It is
-// used by the Editor type to prevent desynchronizing with the host.
+// used by the Editor type to prevent desynchronizing with the host. Revisions
+// that delete a sector are saved as well.
 func (c *Contractor) saveUploadRevision(id types.FileContractID) func(types.FileContractRevision, []crypto.Hash) error {
 	return func(rev types.FileContractRevision, newRoots []crypto.Hash) error {
 		c.mu.Lock()
 		defer c.mu.Unlock()
+		oldRoots := c.contracts[id].MerkleRoots
+		if cr, ok := c.cachedRevisions[id]; ok {
+			oldRoots = cr.MerkleRoots
+		}
 		c.cachedRevisions[id] = cachedRevision{rev, newRoots}
+		if len(newRoots) < len(oldRoots) {
+			// a deletion removes one root; find the first root that differs
+			index := len(newRoots)
+			for i := range newRoots {
+				if newRoots[i] != oldRoots[i] {
+					index = i
+					break
+				}
+			}
+			return c.persist.update(updateCachedDeleteRevision{
+				Revision:    rev,
+				SectorIndex: index,
+			})
+		}
 		return c.persist.update(updateCachedUploadRevision{
 			Revision: rev,
 			// only the last root is new
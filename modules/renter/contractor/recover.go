@@ -0,0 +1,106 @@
+package contractor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// Contracts that the contractor stopped using, for example because the
+// allowance was cancelled, are kept as old contracts along with their secret
+// keys, but without their latest revision being tracked. They can be
+// reattached by their IDs, which the user may know from an explorer or from
+// the files of the renter. The latest revision is fetched from the host, which
+// also proves that the host still holds the contract. Because the Merkle roots
+// of old contracts are not kept, reattached contracts are only used to
+// download data, and are never uploaded to or renewed.
+
+var (
+	errRecoverActive  = errors.New("contract is already in use")
+	errRecoverExpired = errors.New("contract has expired")
+	errRecoverNoHost  = errors.New("host of the contract is not in the host database")
+	errRecoverUnknown = errors.New("renter does not have the secret key of the contract")
+)
+
+// managedRecoveryCandidate returns the old contract with the provided ID, if
+// it can be recovered.
+func (c *Contractor) managedRecoveryCandidate(id types.FileContractID) (modules.RenterContract, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, exists := c.contracts[id]; exists {
+		return modules.RenterContract{}, errRecoverActive
+	}
+	if newID, exists := c.renewedIDs[id]; exists {
+		return modules.RenterContract{}, fmt.Errorf("contract was renewed as %v", newID)
+	}
+	contract, exists := c.oldContracts[id]
+	if !exists || id == metricsContractID {
+		return modules.RenterContract{}, errRecoverUnknown
+	}
+	if c.blockHeight >= contract.EndHeight() {
+		return modules.RenterContract{}, errRecoverExpired
+	}
+	return contract, nil
+}
+
+// managedRecoverContract reattaches the old contract with the provided ID.
+func (c *Contractor) managedRecoverContract(id types.FileContractID) (modules.RenterContract, error) {
+	contract, err := c.managedRecoveryCandidate(id)
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+	host, ok := c.hdb.Host(contract.HostPublicKey)
+	if !ok {
+		return modules.RenterContract{}, errRecoverNoHost
+	}
+	txn, err := proto.FetchRevision(host, contract, c.tg.StopChan())
+	if err != nil {
+		return modules.RenterContract{}, errors.New("could not fetch the revision from the host: " + err.Error())
+	}
+	contract.LastRevision = txn.FileContractRevisions[0]
+	contract.LastRevisionTxn = txn
+	contract.NetAddress = host.NetAddress
+	contract.GoodForUpload = false
+	contract.GoodForRenew = false
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.contracts[id]; exists {
+		return modules.RenterContract{}, errRecoverActive
+	}
+	delete(c.oldContracts, id)
+	c.contracts[id] = contract
+	if err := c.saveSync(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	c.log.Printf("INFO: recovered contract %v with host %v at revision %v", id, host.NetAddress, contract.LastRevision.NewRevisionNumber)
+	return contract, nil
+}
+
+// RecoverContracts reattaches old contracts by their IDs, so that their data
+// can be downloaded. The latest revision of each contract is fetched from its
+// host. Contracts that are in use, have expired, or whose secret keys the
+// renter does not have cannot be recovered.
+func (c *Contractor) RecoverContracts(ids []types.FileContractID) []modules.RenterContractRecovery {
+	if err := c.tg.Add(); err != nil {
+		return nil
+	}
+	defer c.tg.Done()
+
+	results := make([]modules.RenterContractRecovery, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+		contract, err := c.managedRecoverContract(id)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Recovered = true
+		results[i].NetAddress = contract.NetAddress
+		results[i].RevisionNumber = contract.LastRevision.NewRevisionNumber
+	}
+	return results
+}
@@ -0,0 +1,89 @@
+package contractor
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestIntegrationRecoverContracts tests that an old contract can be
+// reattached by its ID, and that contracts that are in use or unknown cannot.
+func TestIntegrationRecoverContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// form a contract with the host
+	hostEntry, ok := c.hdb.Host(h.PublicKey())
+	if !ok {
+		t.Fatal("no entry for host in db")
+	}
+	contract, err := c.managedNewContract(hostEntry, types.SiacoinPrecision.Mul64(50), c.blockHeight+100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	c.contracts[contract.ID] = contract
+	c.mu.Unlock()
+
+	// a contract that is in use, or that the renter never had, cannot be
+	// recovered
+	results := c.RecoverContracts([]types.FileContractID{contract.ID, {1}})
+	if len(results) != 2 {
+		t.Fatal("expected 2 results, got", len(results))
+	}
+	if results[0].Recovered || results[0].Error != errRecoverActive.Error() {
+		t.Fatal("expected errRecoverActive, got", results[0].Error)
+	}
+	if results[1].Recovered || results[1].Error != errRecoverUnknown.Error() {
+		t.Fatal("expected errRecoverUnknown, got", results[1].Error)
+	}
+
+	// archive the contract, as cancelling the allowance would
+	c.mu.Lock()
+	delete(c.contracts, contract.ID)
+	c.oldContracts[contract.ID] = contract
+	c.mu.Unlock()
+
+	results = c.RecoverContracts([]types.FileContractID{contract.ID})
+	if !results[0].Recovered {
+		t.Fatal("contract was not recovered:", results[0].Error)
+	}
+	if results[0].NetAddress != hostEntry.NetAddress {
+		t.Fatal("wrong net address:", results[0].NetAddress)
+	}
+	recovered, ok := c.ContractByID(contract.ID)
+	if !ok {
+		t.Fatal("recovered contract is not in use")
+	}
+	if recovered.LastRevision.ParentID != contract.ID || recovered.LastRevision.NewRevisionNumber != results[0].RevisionNumber {
+		t.Fatal("recovered contract does not have the host's revision")
+	}
+	if recovered.GoodForUpload || recovered.GoodForRenew {
+		t.Fatal("recovered contract should only be used for downloads")
+	}
+	c.mu.RLock()
+	_, ok = c.oldContracts[contract.ID]
+	c.mu.RUnlock()
+	if ok {
+		t.Fatal("recovered contract is still an old contract")
+	}
+
+	// an expired contract cannot be recovered
+	c.mu.Lock()
+	delete(c.contracts, contract.ID)
+	c.oldContracts[contract.ID] = contract
+	c.blockHeight = contract.EndHeight()
+	c.mu.Unlock()
+	results = c.RecoverContracts([]types.FileContractID{contract.ID})
+	if results[0].Recovered || results[0].Error != errRecoverExpired.Error() {
+		t.Fatal("expected errRecoverExpired, got", results[0].Error)
+	}
+}
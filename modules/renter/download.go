@@ -151,6 +151,8 @@ func (r *Renter) newSectionDownload(f *file, destination modules.DownloadWriter,
 
 // newDownload creates a newly initialized download.
 func newDownload(f *file, destination modules.DownloadWriter) *download {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	return &download{
 		startTime:        time.Now(),
 		chunkSize:        f.chunkSize(),
@@ -619,6 +621,7 @@ func (r *Renter) managedWaitOnDownloadWork(ds *downloadState) {
 // threadedDownloadLoop utilizes the worker pool to make progress on any queued
 // downloads.
 func (r *Renter) threadedDownloadLoop() {
+	defer build.CaptureCrash("renter")
 	// Compile the set of available workers.
 	id := r.mu.RLock()
 	availableWorkers := make([]*worker, 0, len(r.workerPool))
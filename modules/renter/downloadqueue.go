@@ -34,16 +34,19 @@ func (r *Renter) Download(p modules.RenterDownloadParameters) error {
 	if p.Destination != "" && !filepath.IsAbs(p.Destination) {
 		return errors.New("destination must be an absolute path")
 	}
-	if p.Offset == file.size {
+	file.mu.RLock()
+	size := file.size
+	file.mu.RUnlock()
+	if p.Offset == size {
 		return errors.New("offset equals filesize")
 	}
 	// sentinel: if length == 0, download the entire file
 	if p.Length == 0 {
-		p.Length = file.size - p.Offset
+		p.Length = size - p.Offset
 	}
 	// Check whether offset and length is valid.
-	if p.Offset < 0 || p.Offset+p.Length > file.size {
-		return fmt.Errorf("offset and length combination invalid, max byte is at index %d", file.size-1)
+	if p.Offset < 0 || p.Offset+p.Length > size {
+		return fmt.Errorf("offset and length combination invalid, max byte is at index %d", size-1)
 	}
 
 	// Instantiate the correct DownloadWriter implementation
@@ -59,6 +62,19 @@ func (r *Renter) Download(p modules.RenterDownloadParameters) error {
 		dw = dfw
 	}
 
+	// Inline files are served from their metadata.
+	file.mu.RLock()
+	inline := file.isInline()
+	file.mu.RUnlock()
+	if inline {
+		return serveInline(file, dw, p.Offset, p.Length)
+	}
+
+	// Serve the download from the cache if every chunk has been prefetched.
+	if served, err := r.cache.managedServe(file, dw, p.Offset, p.Length); served {
+		return err
+	}
+
 	// Create the download object and add it to the queue.
 	d := r.newSectionDownload(file, dw, p.Offset, p.Length)
 
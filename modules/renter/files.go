@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
 	"github.com/NebulousLabs/Sia/build"
@@ -25,13 +26,18 @@ var (
 // contract covers many pieces.
 type file struct {
 	name        string
-	size        uint64 // Static - can be accessed without lock.
+	size        uint64 // Changed by UpdateFile - must be accessed under lock.
 	contracts   map[types.FileContractID]fileContract
 	masterKey   crypto.TwofishKey    // Static - can be accessed without lock.
 	erasureCode modules.ErasureCoder // Static - can be accessed without lock.
 	pieceSize   uint64               // Static - can be accessed without lock.
 	mode        uint32               // actually an os.FileMode
 
+	// inline holds the encrypted contents of a file that is small enough to
+	// be stored in its metadata rather than on hosts. It is empty for files
+	// that are stored on hosts.
+	inline crypto.Ciphertext
+
 	mu sync.RWMutex
 }
 
@@ -82,6 +88,9 @@ func (f *file) numChunks() uint64 {
 
 // available indicates whether the file is ready to be downloaded.
 func (f *file) available(isOffline func(types.FileContractID) bool) bool {
+	if f.isInline() {
+		return true
+	}
 	chunkPieces := make([]int, f.numChunks())
 	for _, fc := range f.contracts {
 		if isOffline(fc.ID) {
@@ -103,6 +112,9 @@ func (f *file) available(isOffline func(types.FileContractID) bool) bool {
 // been uploaded. Note that a file may be Available long before UploadProgress
 // reaches 100%, and UploadProgress may report a value greater than 100%.
 func (f *file) uploadProgress() float64 {
+	if f.isInline() {
+		return 100
+	}
 	var uploaded uint64
 	for _, fc := range f.contracts {
 		uploaded += uint64(len(fc.Pieces)) * f.pieceSize
@@ -114,10 +126,11 @@ func (f *file) uploadProgress() float64 {
 
 // redundancy returns the redundancy of the least redundant chunk. A file
 // becomes available when this redundancy is >= 1. Assumes that every piece is
-// unique within a file contract. -1 is returned if the file has size 0. It
-// takes one argument, a map of offline contracts for this file.
+// unique within a file contract. -1 is returned if the file has size 0 or is
+// stored inline. It takes one argument, a map of offline contracts for this
+// file.
 func (f *file) redundancy(isOffline func(types.FileContractID) bool) float64 {
-	if f.size == 0 {
+	if f.size == 0 || f.isInline() {
 		return -1
 	}
 	piecesPerChunk := make([]int, f.numChunks())
@@ -187,6 +200,7 @@ func (r *Renter) DeleteFile(nickname string) error {
 	}
 	delete(r.files, nickname)
 	delete(r.tracking, nickname)
+	r.cache.managedEvict(f.masterKey)
 	err := os.RemoveAll(filepath.Join(r.persistDir, f.name+ShareExtension))
 	if err != nil {
 		r.log.Println("WARN: couldn't remove .sia file during delete:", err)
@@ -210,6 +224,12 @@ func (r *Renter) FileList() []modules.FileInfo {
 	for _, f := range r.files {
 		files = append(files, f)
 	}
+	recoverable := make(map[crypto.Hash]struct{})
+	for _, ms := range r.metadataSectors {
+		for _, id := range ms.Files {
+			recoverable[id] = struct{}{}
+		}
+	}
 	r.mu.RUnlock(lockID)
 
 	isOffline := func(id types.FileContractID) bool {
@@ -226,6 +246,7 @@ func (r *Renter) FileList() []modules.FileInfo {
 	for _, f := range files {
 		f.mu.RLock()
 		renewing := true
+		_, isRecoverable := recoverable[metadataFileID(f)]
 		fileList = append(fileList, modules.FileInfo{
 			SiaPath:        f.name,
 			Filesize:       f.size,
@@ -234,12 +255,99 @@ func (r *Renter) FileList() []modules.FileInfo {
 			Redundancy:     f.redundancy(isOffline),
 			UploadProgress: f.uploadProgress(),
 			Expiration:     f.expiration(),
+			Recoverable:    isRecoverable,
+			Inline:         f.isInline(),
 		})
 		f.mu.RUnlock()
 	}
 	return fileList
 }
 
+// hostUptime returns the fraction of the measured time that the host was
+// online, based on its historic uptime and its recent scans.
+func hostUptime(entry modules.HostDBEntry) float64 {
+	uptime := entry.HistoricUptime
+	downtime := entry.HistoricDowntime
+	for i := 1; i < len(entry.ScanHistory); i++ {
+		prev, scan := entry.ScanHistory[i-1], entry.ScanHistory[i]
+		if !scan.Timestamp.After(prev.Timestamp) {
+			continue
+		}
+		if prev.Success {
+			uptime += scan.Timestamp.Sub(prev.Timestamp)
+		} else {
+			downtime += scan.Timestamp.Sub(prev.Timestamp)
+		}
+	}
+	if uptime+downtime == 0 {
+		return 0
+	}
+	return float64(uptime) / float64(uptime+downtime)
+}
+
+// pieceHost returns the host information shared by all of the pieces stored
+// under a file contract.
+func (r *Renter) pieceHost(fc fileContract) modules.FilePieceHost {
+	id := r.hostContractor.ResolveID(fc.ID)
+	ph := modules.FilePieceHost{
+		ContractID: id,
+		NetAddress: fc.IP,
+		Online:     !r.hostContractor.IsOffline(id),
+	}
+	contract, exists := r.hostContractor.ContractByID(id)
+	if !exists {
+		return ph
+	}
+	ph.HostPublicKey = contract.HostPublicKey
+	ph.NetAddress = contract.NetAddress
+	if entry, exists := r.hostDB.Host(contract.HostPublicKey); exists {
+		ph.Score = r.hostDB.ScoreBreakdown(entry).Score
+		ph.Uptime = hostUptime(entry)
+	}
+	return ph
+}
+
+// FileHosts returns the hosts storing each piece of a file, grouped by chunk.
+// Chunks are listed in order, and the pieces of each chunk are sorted by
+// piece index. Inline files have no chunks.
+func (r *Renter) FileHosts(siaPath string) ([]modules.FileChunkHosts, error) {
+	lockID := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	r.mu.RUnlock(lockID)
+	if !exists {
+		return nil, ErrUnknownPath
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.isInline() {
+		return []modules.FileChunkHosts{}, nil
+	}
+	chunks := make([]modules.FileChunkHosts, f.numChunks())
+	for i := range chunks {
+		chunks[i].Chunk = uint64(i)
+		chunks[i].Pieces = []modules.FilePieceHost{}
+	}
+	for _, fc := range f.contracts {
+		ph := r.pieceHost(fc)
+		for _, p := range fc.Pieces {
+			if p.Chunk >= uint64(len(chunks)) {
+				continue
+			}
+			ph.Piece = p.Piece
+			ph.MerkleRoot = p.MerkleRoot
+			chunks[p.Chunk].Pieces = append(chunks[p.Chunk].Pieces, ph)
+		}
+	}
+	for _, chunk := range chunks {
+		pieces := chunk.Pieces
+		sort.Slice(pieces, func(i, j int) bool {
+			return pieces[i].Piece < pieces[j].Piece
+		})
+	}
+	return chunks, nil
+}
+
 // RenameFile takes an existing file and changes the nickname. The original
 // file must exist, and there must not be any file that already has the
 // replacement nickname.
@@ -326,6 +326,54 @@ func TestRenterFileList(t *testing.T) {
 	}
 }
 
+// TestRenterFileHosts probes the FileHosts method of the renter.
+func TestRenterFileHosts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	if _, err := rt.renter.FileHosts("dne"); err != ErrUnknownPath {
+		t.Fatal("expected ErrUnknownPath, got", err)
+	}
+
+	// Put a file with two chunks in the renter, storing the pieces out of
+	// order on two contracts that the contractor does not know about.
+	rsc, _ := NewRSCode(1, 2)
+	f := &file{
+		name:        "one",
+		size:        2,
+		erasureCode: rsc,
+		pieceSize:   1,
+		contracts: map[types.FileContractID]fileContract{
+			{1}: {ID: types.FileContractID{1}, IP: "foo:1", Pieces: []pieceData{{Chunk: 1, Piece: 2}, {Chunk: 0, Piece: 1}}},
+			{2}: {ID: types.FileContractID{2}, IP: "bar:2", Pieces: []pieceData{{Chunk: 1, Piece: 0}}},
+		},
+	}
+	rt.renter.files[f.name] = f
+
+	chunks, err := rt.renter.FileHosts(f.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 || chunks[0].Chunk != 0 || chunks[1].Chunk != 1 {
+		t.Fatal("wrong chunks returned:", chunks)
+	}
+	if len(chunks[0].Pieces) != 1 || chunks[0].Pieces[0].Piece != 1 || chunks[0].Pieces[0].NetAddress != "foo:1" {
+		t.Fatal("wrong pieces for chunk 0:", chunks[0].Pieces)
+	}
+	if len(chunks[1].Pieces) != 2 || chunks[1].Pieces[0].Piece != 0 || chunks[1].Pieces[1].Piece != 2 {
+		t.Fatal("pieces of chunk 1 are not sorted:", chunks[1].Pieces)
+	}
+	if chunks[1].Pieces[0].ContractID != (types.FileContractID{2}) || chunks[1].Pieces[0].NetAddress != "bar:2" {
+		t.Fatal("wrong host for piece 0 of chunk 1:", chunks[1].Pieces[0])
+	}
+}
+
 // TestRenterRenameFile probes the rename method of the renter.
 func TestRenterRenameFile(t *testing.T) {
 	if testing.Short() {
@@ -375,7 +423,7 @@ func TestRenterRenameFile(t *testing.T) {
 	}
 
 	// Renaming should also update the tracking set
-	rt.renter.tracking["1"] = trackedFile{"foo"}
+	rt.renter.tracking["1"] = trackedFile{RepairPath: "foo"}
 	err = rt.renter.RenameFile("1", "1b")
 	if err != nil {
 		t.Fatal(err)
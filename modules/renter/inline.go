@@ -0,0 +1,98 @@
+package renter
+
+// Files that are no larger than the renter's inline threshold are not
+// uploaded to hosts. Instead, their contents are encrypted and stored inline in
+// the file's metadata: in its .sia file, in any .sia file it is shared in, and
+// in the metadata sector of every contract. Storing a few KB this way costs
+// nothing, where uploading them would pay for a full sector on every host,
+// and inline files are downloaded without contacting any hosts. Updating a
+// file moves it between inline and host storage as its size crosses the
+// threshold.
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// inlineSpecifier is used to derive the key that encrypts the contents of
+	// an inline file from the file's master key.
+	inlineSpecifier = types.Specifier{'I', 'n', 'l', 'i', 'n', 'e', ' ', 'F', 'i', 'l', 'e'}
+
+	errInlineSize     = errors.New("inline file contents do not match the file size")
+	errPrefetchInline = errors.New("inline files are stored locally and cannot be prefetched")
+)
+
+// inlineKey returns the key that encrypts the contents of an inline file.
+func inlineKey(masterKey crypto.TwofishKey) crypto.TwofishKey {
+	return crypto.TwofishKey(crypto.HashAll(inlineSpecifier, masterKey))
+}
+
+// isInline returns whether the contents of f are stored in its metadata.
+// Encrypted contents are never empty, even for an empty file.
+func (f *file) isInline() bool {
+	return len(f.inline) > 0
+}
+
+// setInline stores data as the contents of f, dropping any pieces of f that
+// are stored on hosts. The caller must hold the lock of f.
+func (f *file) setInline(data []byte) {
+	f.size = uint64(len(data))
+	f.inline = inlineKey(f.masterKey).EncryptBytes(data)
+	f.contracts = make(map[types.FileContractID]fileContract)
+}
+
+// inlineData returns the decrypted contents of an inline file.
+func (f *file) inlineData() ([]byte, error) {
+	data, err := inlineKey(f.masterKey).DecryptBytes(f.inline)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) != f.size {
+		return nil, errInlineSize
+	}
+	return data, nil
+}
+
+// readInlineSource returns the contents of the file at path if the file is
+// small enough to be stored inline. The file is read through a limited reader,
+// in case it has grown since it was last checked.
+func readInlineSource(path string, threshold uint64) ([]byte, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(f, int64(threshold)+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if uint64(len(data)) > threshold {
+		return nil, false, nil
+	}
+	return data, true, nil
+}
+
+// serveInline writes a section of an inline file to dw.
+func serveInline(f *file, dw modules.DownloadWriter, offset, length uint64) error {
+	f.mu.RLock()
+	data, err := f.inlineData()
+	f.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	// The file may have been updated since the section was checked.
+	if offset+length > uint64(len(data)) {
+		return errInlineSize
+	}
+	if _, err := dw.WriteAt(data[offset:offset+length], int64(offset)); err != nil {
+		return err
+	}
+	return dw.Close()
+}
@@ -0,0 +1,216 @@
+package renter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestInlineFiles checks that small files are stored inline rather than on
+// hosts, that they are downloaded from their metadata, and that updates move
+// files between inline and host storage.
+func TestInlineFiles(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+	r := rt.renter
+	r.inlineThreshold = 1000
+
+	source := filepath.Join(r.persistDir, "source")
+	data := fastrand.Bytes(500)
+	if err := ioutil.WriteFile(source, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Upload(modules.FileUploadParams{Source: source, SiaPath: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	id := r.mu.RLock()
+	f := r.files["foo"]
+	_, tracked := r.tracking["foo"]
+	r.mu.RUnlock(id)
+	if !f.isInline() || f.size != uint64(len(data)) || tracked {
+		t.Fatal("small file was not stored inline")
+	}
+	if bytes.Contains(f.inline, data) {
+		t.Fatal("inline contents are not encrypted")
+	}
+	files := r.FileList()
+	if len(files) != 1 || !files[0].Inline || !files[0].Available || files[0].Redundancy != -1 || files[0].UploadProgress != 100 {
+		t.Fatalf("unexpected file info: %+v", files)
+	}
+	if err := r.Prefetch("foo", nil); err != errPrefetchInline {
+		t.Fatal("expected errPrefetchInline, got", err)
+	}
+
+	// Download the whole file and a section of it.
+	dest := filepath.Join(r.persistDir, "dest")
+	if err := r.Download(modules.RenterDownloadParameters{Siapath: "foo", Destination: dest}); err != nil {
+		t.Fatal(err)
+	}
+	if downloaded, err := ioutil.ReadFile(dest); err != nil || !bytes.Equal(downloaded, data) {
+		t.Fatal("downloaded contents do not match the upload:", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := r.Download(modules.RenterDownloadParameters{Siapath: "foo", Httpwriter: buf, Offset: 100, Length: 50}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data[100:150]) {
+		t.Fatal("downloaded section does not match the upload")
+	}
+
+	// The inline contents are saved in the .sia file.
+	siaFile, err := os.Open(filepath.Join(r.persistDir, "foo"+ShareExtension))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := readSharedFiles(siaFile)
+	siaFile.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := saved[0].inlineData(); err != nil || !bytes.Equal(loaded, data) {
+		t.Fatal("inline contents were not persisted:", err)
+	}
+
+	// Growing the file beyond the threshold uploads it to hosts, and
+	// shrinking it stores it inline again.
+	data = fastrand.Bytes(2000)
+	if err := ioutil.WriteFile(source, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	update, err := r.UpdateFile("foo", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id = r.mu.RLock()
+	_, tracked = r.tracking["foo"]
+	r.mu.RUnlock(id)
+	if f.isInline() || !tracked || update.ChangedChunks != update.Chunks {
+		t.Fatal("large file was not moved to hosts:", update)
+	}
+	data = data[:10]
+	if err := ioutil.WriteFile(source, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.UpdateFile("foo", source); err != nil {
+		t.Fatal(err)
+	}
+	id = r.mu.RLock()
+	_, tracked = r.tracking["foo"]
+	r.mu.RUnlock(id)
+	if !f.isInline() || tracked || f.size != 10 {
+		t.Fatal("small file was not moved inline")
+	}
+}
+
+// TestShareInlineFiles checks that inline contents are shared along with the
+// file, and that files without inline contents are still shared in the
+// version older clients can read.
+func TestShareInlineFiles(t *testing.T) {
+	rsc, _ := NewRSCode(1, 1)
+	hosted := newFile("hosted", rsc, 100, 1000)
+	inline := newFile("inline", rsc, 100, 0)
+	data := fastrand.Bytes(100)
+	inline.setInline(data)
+
+	buf := new(bytes.Buffer)
+	if err := shareFiles([]*file{hosted}, buf); err != nil {
+		t.Fatal(err)
+	}
+	var header [15]byte
+	var version string
+	if err := encoding.NewDecoder(buf).DecodeAll(&header, &version); err != nil || version != shareVersion {
+		t.Fatal("files without inline contents were not shared as", shareVersion, err)
+	}
+
+	buf.Reset()
+	if err := shareFiles([]*file{hosted, inline}, buf); err != nil {
+		t.Fatal(err)
+	}
+	files, err := readSharedFiles(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].isInline() || !files[1].isInline() {
+		t.Fatal("inline contents were not shared")
+	}
+	if shared, err := files[1].inlineData(); err != nil || !bytes.Equal(shared, data) {
+		t.Fatal("shared inline contents do not match:", err)
+	}
+
+	// Inline files are also stored in metadata sectors.
+	sk, _ := crypto.GenerateKeyPair()
+	sector, _, err := encodeMetadataSector([]*file{hosted, inline}, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err = decodeMetadataSector(sector, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || !files[1].isInline() {
+		t.Fatal("inline contents were not stored in the metadata sector")
+	}
+}
+
+// TestConcurrentInlineUpdates checks that inline files can be read while they
+// are updated. It is meant to be run with the race detector.
+func TestConcurrentInlineUpdates(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+	r := rt.renter
+	r.inlineThreshold = 1000
+
+	sources := make([]string, 2)
+	for i := range sources {
+		sources[i] = filepath.Join(r.persistDir, "source"+strconv.Itoa(i))
+		if err := ioutil.WriteFile(sources[i], fastrand.Bytes(10+490*i), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Upload(modules.FileUploadParams{Source: sources[1], SiaPath: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Alternate the size of the file while it is downloaded, listed and
+	// shared. Downloads may fail when the file shrinks.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if _, err := r.UpdateFile("foo", sources[i%2]); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		r.Download(modules.RenterDownloadParameters{Siapath: "foo", Httpwriter: new(bytes.Buffer)})
+		r.FileList()
+		if _, err := r.ShareFilesAscii([]string{"foo", "foo"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,400 @@
+package renter
+
+// The renter stores an encrypted copy of its file metadata on the host of
+// each contract, in a single sector called the metadata sector. If the
+// renter's .sia files are lost, the file list can be rebuilt from the metadata
+// sectors of the active contracts, which are still known to the contractor.
+//
+// The metadata sector of a contract contains the full metadata of every file
+// that has pieces stored in the contract, so any one host that stores a piece
+// of a file is enough to recover the file. The sector is encrypted with a key
+// derived from the secret key of the contract, which the renter keeps across
+// renewals.
+//
+// Sectors are never modified in place. When the metadata changes, a new
+// metadata sector is appended to the contract and the old one is deleted
+// afterwards, so that the contract holds a metadata sector at all times.
+// Changes are batched: the metadata sectors are rewritten at most once per
+// metadataUpdateInterval, however many files changed in the meantime.
+//
+// Inline files are stored in the metadata sector of every contract, since
+// their metadata holds their contents. If a sector cannot fit them, they are
+// left out of it rather than the files stored on the host.
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// metadataSpecifier is written at the start of every metadata sector, and
+	// is used to derive the key that encrypts the sector.
+	metadataSpecifier = types.Specifier{'R', 'e', 'n', 't', 'e', 'r', ' ', 'M', 'e', 't', 'a', 'd', 'a', 't', 'a'}
+
+	errMetadataTooLarge = errors.New("file metadata does not fit in a single sector")
+	errNoMetadataSector = errors.New("contract does not contain a metadata sector")
+	errNotMetadata      = errors.New("sector is not a metadata sector")
+	errNoWorker         = errors.New("contract has no worker")
+)
+
+// A metadataSector describes the metadata sector that was last written to the
+// host of a contract.
+type metadataSector struct {
+	// Root is the Merkle root of the sector.
+	Root crypto.Hash
+
+	// Checksum identifies the metadata stored in the sector, so that the
+	// sector is only rewritten when the metadata changes.
+	Checksum crypto.Hash
+
+	// Files contains the IDs of the files stored in the sector.
+	Files []crypto.Hash
+
+	// Stale contains the roots of earlier metadata sectors of the contract
+	// that could not be deleted from the host yet. They are deleted along
+	// with the next write.
+	Stale []crypto.Hash
+}
+
+// metadataKey returns the key that encrypts the metadata sector of a contract.
+func metadataKey(sk crypto.SecretKey) crypto.TwofishKey {
+	return crypto.TwofishKey(crypto.HashAll(metadataSpecifier, sk))
+}
+
+// metadataFileID returns the ID of a file within a metadata sector. The ID
+// does not change when the file is renamed.
+func metadataFileID(f *file) crypto.Hash {
+	return crypto.HashObject(f.masterKey)
+}
+
+// encodeMetadataSector returns the encrypted metadata sector holding the
+// metadata of files, along with a checksum of the metadata. The files must be
+// sorted by name.
+func encodeMetadataSector(files []*file, sk crypto.SecretKey) ([]byte, crypto.Hash, error) {
+	for _, f := range files {
+		f.mu.RLock()
+		defer f.mu.RUnlock()
+	}
+
+	// The checksum is computed over the contracts in sorted order, since the
+	// encoded files list their contracts in random order.
+	type checksumEntry struct {
+		Name      string
+		MasterKey crypto.TwofishKey
+		Contracts []fileContract
+	}
+	entries := make([]checksumEntry, len(files))
+	for i, f := range files {
+		entries[i] = checksumEntry{Name: f.name, MasterKey: f.masterKey}
+		for _, fc := range f.contracts {
+			entries[i].Contracts = append(entries[i].Contracts, fc)
+		}
+		sort.Slice(entries[i].Contracts, func(j, k int) bool {
+			return bytes.Compare(entries[i].Contracts[j].ID[:], entries[i].Contracts[k].ID[:]) < 0
+		})
+	}
+	checksum := crypto.HashObject(entries)
+	// The contents of inline files are added separately, so that the
+	// checksums of sectors without inline files are unchanged.
+	for _, f := range files {
+		if f.isInline() {
+			checksum = crypto.HashAll(checksum, f.inline)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := encoding.NewEncoder(buf).Encode(metadataSpecifier); err != nil {
+		return nil, crypto.Hash{}, err
+	}
+	if err := shareFiles(files, buf); err != nil {
+		return nil, crypto.Hash{}, err
+	}
+	if uint64(buf.Len()) > modules.SectorSize-crypto.TwofishOverhead {
+		return nil, crypto.Hash{}, errMetadataTooLarge
+	}
+	plaintext := make([]byte, modules.SectorSize-crypto.TwofishOverhead)
+	copy(plaintext, buf.Bytes())
+	return metadataKey(sk).EncryptBytes(plaintext), checksum, nil
+}
+
+// decodeMetadataSector decrypts a metadata sector and returns the files it
+// holds.
+func decodeMetadataSector(sector []byte, sk crypto.SecretKey) ([]*file, error) {
+	plaintext, err := metadataKey(sk).DecryptBytes(sector)
+	if err != nil {
+		return nil, errNotMetadata
+	}
+	r := bytes.NewReader(plaintext)
+	var specifier types.Specifier
+	if err := encoding.NewDecoder(r).Decode(&specifier); err != nil {
+		return nil, err
+	} else if specifier != metadataSpecifier {
+		return nil, errNotMetadata
+	}
+	return readSharedFiles(r)
+}
+
+// managedWriteMetadataSector appends a metadata sector to a contract, and
+// then deletes the stale metadata sectors of the contract. It returns the stale
+// sectors that could not be deleted.
+func (r *Renter) managedWriteMetadataSector(c modules.RenterContract, stale []crypto.Hash, sector []byte) ([]crypto.Hash, error) {
+	e, err := r.hostContractor.Editor(c.ID, r.tg.StopChan())
+	if err != nil {
+		return stale, err
+	}
+	defer e.Close()
+
+	if _, err := e.Upload(sector); err != nil {
+		return stale, err
+	}
+	roots := make(map[crypto.Hash]struct{})
+	for _, root := range c.MerkleRoots {
+		roots[root] = struct{}{}
+	}
+	for i, root := range stale {
+		// Sectors that are no longer part of the contract do not need to be
+		// deleted.
+		if _, exists := roots[root]; !exists {
+			continue
+		}
+		if err := e.Delete(root); err != nil {
+			r.log.Debugln("could not delete stale metadata sector from", c.NetAddress, ":", err)
+			return stale[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+// managedScheduleMetadataWrite has the worker of a contract write the
+// metadata sector of the contract, so that the write does not compete with
+// the uploads and downloads of the worker. It returns the stale sectors that
+// could not be deleted.
+func (r *Renter) managedScheduleMetadataWrite(c modules.RenterContract, stale []crypto.Hash, sector []byte) ([]crypto.Hash, error) {
+	contracts := r.hostContractor.Contracts()
+	id := r.mu.Lock()
+	r.updateWorkerPool(contracts)
+	w, exists := r.workerPool[c.ID]
+	r.mu.Unlock(id)
+	if !exists {
+		return stale, errNoWorker
+	}
+
+	mw := metadataWork{
+		contract:   c,
+		sector:     sector,
+		stale:      stale,
+		resultChan: make(chan finishedMetadata, 1),
+	}
+	select {
+	case w.metadataChan <- mw:
+	case <-w.killChan:
+		return stale, errNoWorker
+	case <-r.tg.StopChan():
+		return stale, errors.New("metadata write interrupted by shutdown")
+	}
+	fm := <-mw.resultChan
+	return fm.stale, fm.err
+}
+
+// managedUpdateMetadataSectors rewrites the metadata sectors of all contracts
+// whose files have changed since their metadata sector was last written.
+func (r *Renter) managedUpdateMetadataSectors() {
+	// Group the files by the contracts that currently store their pieces.
+	stored := make(map[types.FileContractID]map[*file]struct{})
+	var inline []*file
+	id := r.mu.RLock()
+	for _, f := range r.files {
+		f.mu.RLock()
+		if f.isInline() {
+			inline = append(inline, f)
+		}
+		for fcid := range f.contracts {
+			resolved := r.hostContractor.ResolveID(fcid)
+			if stored[resolved] == nil {
+				stored[resolved] = make(map[*file]struct{})
+			}
+			stored[resolved][f] = struct{}{}
+		}
+		f.mu.RUnlock()
+	}
+	r.mu.RUnlock(id)
+
+	for _, c := range r.hostContractor.Contracts() {
+		if !c.GoodForUpload {
+			continue
+		}
+		hosted := make([]*file, 0, len(stored[c.ID]))
+		for f := range stored[c.ID] {
+			hosted = append(hosted, f)
+		}
+		files := append(append([]*file(nil), hosted...), inline...)
+		for _, fs := range [][]*file{hosted, files} {
+			sort.Slice(fs, func(i, j int) bool {
+				return fs[i].name < fs[j].name
+			})
+		}
+
+		// Find the metadata sector of the contract. The sector may have been
+		// written under an ID that the contract was renewed from.
+		id := r.mu.RLock()
+		oldID, ms, exists := c.ID, metadataSector{}, false
+		for msID, s := range r.metadataSectors {
+			if r.hostContractor.ResolveID(msID) == c.ID {
+				oldID, ms, exists = msID, s, true
+				break
+			}
+		}
+		r.mu.RUnlock(id)
+		if !exists && len(files) == 0 {
+			continue
+		}
+
+		sector, checksum, err := encodeMetadataSector(files, c.SecretKey)
+		if err == errMetadataTooLarge && len(inline) > 0 {
+			files = hosted
+			sector, checksum, err = encodeMetadataSector(files, c.SecretKey)
+		}
+		if err != nil {
+			r.log.Println("WARN: could not encode metadata sector for", c.NetAddress, ":", err)
+			continue
+		} else if exists && checksum == ms.Checksum {
+			continue
+		}
+		stale := append([]crypto.Hash(nil), ms.Stale...)
+		if exists {
+			stale = append(stale, ms.Root)
+		}
+		stale, err = r.managedScheduleMetadataWrite(c, stale, sector)
+		if err != nil {
+			r.log.Debugln("could not write metadata sector to", c.NetAddress, ":", err)
+			continue
+		}
+
+		ms = metadataSector{
+			Root:     crypto.MerkleRoot(sector),
+			Checksum: checksum,
+			Stale:    stale,
+		}
+		for _, f := range files {
+			ms.Files = append(ms.Files, metadataFileID(f))
+		}
+		id = r.mu.Lock()
+		delete(r.metadataSectors, oldID)
+		r.metadataSectors[c.ID] = ms
+		err = r.saveSync()
+		r.mu.Unlock(id)
+		if err != nil {
+			r.log.Println("ERROR: could not save renter after writing metadata sector:", err)
+		}
+	}
+}
+
+// threadedUpdateMetadataSectors periodically updates the metadata sectors
+// stored on the renter's hosts.
+func (r *Renter) threadedUpdateMetadataSectors() {
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(metadataUpdateInterval):
+		}
+		if r.tg.Add() != nil {
+			return
+		}
+		r.managedUpdateMetadataSectors()
+		r.tg.Done()
+	}
+}
+
+// managedDownloadMetadataSector finds and downloads the metadata sector of a
+// contract, returning the files that it holds.
+func (r *Renter) managedDownloadMetadataSector(c modules.RenterContract) ([]*file, error) {
+	d, err := r.hostContractor.Downloader(c.ID, r.tg.StopChan())
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	// The metadata sector is appended to the contract whenever the files
+	// stored in it change, so the sectors are searched from the end. This
+	// also finds the newest metadata sector if stale ones were not deleted.
+	for i := len(c.MerkleRoots) - 1; i >= 0; i-- {
+		sector, err := d.Sector(c.MerkleRoots[i])
+		if err != nil {
+			return nil, err
+		}
+		files, err := decodeMetadataSector(sector, c.SecretKey)
+		if err == nil {
+			return files, nil
+		}
+	}
+	return nil, errNoMetadataSector
+}
+
+// RecoverFiles rebuilds the renter's file list from the metadata sectors
+// stored on the hosts of its contracts. Files that the renter already knows
+// are skipped. The recovered files are tracked, so that they are repaired
+// using data downloaded from the hosts. It returns the names of the recovered
+// files.
+func (r *Renter) RecoverFiles() ([]string, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+
+	// Different hosts may hold copies of a file's metadata that were written
+	// at different times, so the pieces known to each copy are merged.
+	recovered := make(map[crypto.TwofishKey]*file)
+	var order []crypto.TwofishKey
+	for _, c := range r.hostContractor.Contracts() {
+		files, err := r.managedDownloadMetadataSector(c)
+		if err != nil {
+			r.log.Debugln("could not download metadata sector from", c.NetAddress, ":", err)
+			continue
+		}
+		for _, f := range files {
+			existing, exists := recovered[f.masterKey]
+			if !exists {
+				recovered[f.masterKey] = f
+				order = append(order, f.masterKey)
+				continue
+			}
+			for fcid, fc := range f.contracts {
+				if len(fc.Pieces) > len(existing.contracts[fcid].Pieces) {
+					existing.contracts[fcid] = fc
+				}
+			}
+		}
+	}
+
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	known := make(map[crypto.TwofishKey]struct{})
+	for _, f := range r.files {
+		known[f.masterKey] = struct{}{}
+	}
+	var names []string
+	for _, key := range order {
+		if _, exists := known[key]; exists {
+			continue
+		}
+		f := recovered[key]
+		f.name = r.uniqueName(f.name)
+		r.files[f.name] = f
+		if !f.isInline() {
+			r.tracking[f.name] = trackedFile{}
+		}
+		if err := r.saveFile(f); err != nil {
+			return names, err
+		}
+		names = append(names, f.name)
+	}
+	return names, r.saveSync()
+}
@@ -0,0 +1,236 @@
+package renter
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/contractor"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// memContractor is a hostContractor whose contracts store their sectors in
+// memory.
+type memContractor struct {
+	contracts  map[types.FileContractID]modules.RenterContract
+	sectors    map[crypto.Hash][]byte
+	failDelete bool
+	mu         sync.Mutex
+}
+
+// memEditor and memDownloader edit and download the sectors of a contract of
+// a memContractor.
+type (
+	memEditor struct {
+		id types.FileContractID
+		mc *memContractor
+	}
+	memDownloader struct {
+		mc *memContractor
+	}
+)
+
+func (mc *memContractor) SetAllowance(modules.Allowance) error { return nil }
+func (mc *memContractor) Allowance() modules.Allowance         { return modules.Allowance{} }
+func (mc *memContractor) Close() error                         { return nil }
+func (mc *memContractor) Contract(modules.NetAddress) (modules.RenterContract, bool) {
+	return modules.RenterContract{}, false
+}
+func (mc *memContractor) Contracts() (cs []modules.RenterContract) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	for _, c := range mc.contracts {
+		c.MerkleRoots = append(modules.MerkleRootSet(nil), c.MerkleRoots...)
+		cs = append(cs, c)
+	}
+	return cs
+}
+func (mc *memContractor) ContractByID(id types.FileContractID) (modules.RenterContract, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	c, ok := mc.contracts[id]
+	return c, ok
+}
+func (mc *memContractor) Bandwidth() []modules.RenterBandwidth                   { return nil }
+func (mc *memContractor) CurrentPeriod() types.BlockHeight                       { return 0 }
+func (mc *memContractor) GoodForRenew(types.FileContractID) bool                 { return true }
+func (mc *memContractor) IsOffline(types.FileContractID) bool                    { return false }
+func (mc *memContractor) ResolveID(id types.FileContractID) types.FileContractID { return id }
+func (mc *memContractor) RecoverContracts([]types.FileContractID) []modules.RenterContractRecovery {
+	return nil
+}
+func (mc *memContractor) Editor(id types.FileContractID, _ <-chan struct{}) (contractor.Editor, error) {
+	return &memEditor{id: id, mc: mc}, nil
+}
+func (mc *memContractor) Downloader(types.FileContractID, <-chan struct{}) (contractor.Downloader, error) {
+	return &memDownloader{mc: mc}, nil
+}
+
+func (me *memEditor) Upload(data []byte) (crypto.Hash, error) {
+	me.mc.mu.Lock()
+	defer me.mc.mu.Unlock()
+	root := crypto.MerkleRoot(data)
+	c := me.mc.contracts[me.id]
+	c.MerkleRoots = append(c.MerkleRoots, root)
+	me.mc.contracts[me.id] = c
+	me.mc.sectors[root] = data
+	return root, nil
+}
+func (me *memEditor) Delete(root crypto.Hash) error {
+	me.mc.mu.Lock()
+	defer me.mc.mu.Unlock()
+	if me.mc.failDelete {
+		return errors.New("host refused to delete the sector")
+	}
+	c := me.mc.contracts[me.id]
+	for i := range c.MerkleRoots {
+		if c.MerkleRoots[i] == root {
+			c.MerkleRoots = append(c.MerkleRoots[:i:i], c.MerkleRoots[i+1:]...)
+			me.mc.contracts[me.id] = c
+			delete(me.mc.sectors, root)
+			return nil
+		}
+	}
+	return errors.New("no record of that sector root")
+}
+func (me *memEditor) Modify(crypto.Hash, crypto.Hash, uint64, []byte) error {
+	return errors.New("not implemented")
+}
+func (me *memEditor) Address() modules.NetAddress      { return "" }
+func (me *memEditor) ContractID() types.FileContractID { return me.id }
+func (me *memEditor) EndHeight() types.BlockHeight     { return 0 }
+func (me *memEditor) Close() error                     { return nil }
+func (md *memDownloader) Close() error                 { return nil }
+func (md *memDownloader) Sector(root crypto.Hash) ([]byte, error) {
+	md.mc.mu.Lock()
+	defer md.mc.mu.Unlock()
+	return md.mc.sectors[root], nil
+}
+
+// TestMetadataSectors checks that the renter stores its file metadata on its
+// hosts, and that a renter that lost its metadata can recover its files.
+func TestMetadataSectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a contract that already stores a sector, so that the metadata
+	// sector is not the first sector of the contract.
+	mc := &memContractor{
+		contracts: make(map[types.FileContractID]modules.RenterContract),
+		sectors:   make(map[crypto.Hash][]byte),
+	}
+	var sk crypto.SecretKey
+	fastrand.Read(sk[:])
+	contract := modules.RenterContract{ID: types.FileContractID{1}, SecretKey: sk, GoodForUpload: true}
+	mc.contracts[contract.ID] = contract
+	pieceRoot, _ := (&memEditor{id: contract.ID, mc: mc}).Upload(fastrand.Bytes(int(modules.SectorSize)))
+
+	rt, err := newContractorTester(t.Name(), nil, mc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	rsc, _ := NewRSCode(1, 1)
+	f := newFile("foo", rsc, 10, 10)
+	f.contracts[contract.ID] = fileContract{
+		ID:     contract.ID,
+		Pieces: []pieceData{{Chunk: 0, Piece: 0, MerkleRoot: pieceRoot}},
+	}
+	id := rt.renter.mu.Lock()
+	rt.renter.files[f.name] = f
+	rt.renter.mu.Unlock(id)
+
+	// Write the metadata sector.
+	rt.renter.managedUpdateMetadataSectors()
+	if files := rt.renter.FileList(); len(files) != 1 || !files[0].Recoverable {
+		t.Fatal("file should be recoverable:", files)
+	}
+	if roots := mc.Contracts()[0].MerkleRoots; len(roots) != 2 {
+		t.Fatal("expected a metadata sector to be uploaded, got", len(roots), "sectors")
+	}
+
+	// A change to the file should replace the metadata sector with a new
+	// one.
+	oldRoot := mc.Contracts()[0].MerkleRoots[1]
+	f.mu.Lock()
+	fc := f.contracts[contract.ID]
+	fc.Pieces = append(fc.Pieces, pieceData{Chunk: 0, Piece: 1, MerkleRoot: pieceRoot})
+	f.contracts[contract.ID] = fc
+	f.mu.Unlock()
+	rt.renter.managedUpdateMetadataSectors()
+	roots := mc.Contracts()[0].MerkleRoots
+	if len(roots) != 2 {
+		t.Fatal("expected the metadata sector to be replaced, got", len(roots), "sectors")
+	}
+	if roots[0] != pieceRoot || roots[1] == oldRoot {
+		t.Fatal("the old metadata sector was not deleted")
+	}
+
+	// If the old metadata sector cannot be deleted, it is deleted with the
+	// next write.
+	oldRoot = roots[1]
+	mc.mu.Lock()
+	mc.failDelete = true
+	mc.mu.Unlock()
+	fc.Pieces = fc.Pieces[:1]
+	f.mu.Lock()
+	f.contracts[contract.ID] = fc
+	f.mu.Unlock()
+	rt.renter.managedUpdateMetadataSectors()
+	if roots := mc.Contracts()[0].MerkleRoots; len(roots) != 3 || roots[1] != oldRoot {
+		t.Fatal("expected the old metadata sector to be kept, got", len(roots), "sectors")
+	}
+	mc.mu.Lock()
+	mc.failDelete = false
+	mc.mu.Unlock()
+	f.mu.Lock()
+	fc.Pieces = append(fc.Pieces, pieceData{Chunk: 0, Piece: 1, MerkleRoot: pieceRoot})
+	f.contracts[contract.ID] = fc
+	f.mu.Unlock()
+	rt.renter.managedUpdateMetadataSectors()
+	if roots := mc.Contracts()[0].MerkleRoots; len(roots) != 2 || roots[0] != pieceRoot {
+		t.Fatal("expected the stale metadata sectors to be deleted, got", len(roots), "sectors")
+	}
+	id = rt.renter.mu.RLock()
+	stale := rt.renter.metadataSectors[contract.ID].Stale
+	rt.renter.mu.RUnlock(id)
+	if len(stale) != 0 {
+		t.Fatal("stale metadata sectors are still tracked:", stale)
+	}
+
+	// A renter without any files should recover the file from the contract.
+	rt2, err := newContractorTester(t.Name()+"-recovered", nil, mc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt2.Close()
+	names, err := rt2.renter.RecoverFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "foo" {
+		t.Fatal("expected to recover foo, got", names)
+	}
+	rf := rt2.renter.files["foo"]
+	if rf.masterKey != f.masterKey || rf.size != f.size || len(rf.contracts[contract.ID].Pieces) != 2 {
+		t.Fatal("recovered file does not match the original")
+	}
+	if _, tracked := rt2.renter.tracking["foo"]; !tracked {
+		t.Fatal("recovered file is not tracked")
+	}
+
+	// Recovering again should not duplicate the file.
+	names, err = rt2.renter.RecoverFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Fatal("recovered known files:", names)
+	}
+}
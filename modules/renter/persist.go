@@ -32,6 +32,12 @@ var (
 	shareHeader  = [15]byte{'S', 'i', 'a', ' ', 'S', 'h', 'a', 'r', 'e', 'd', ' ', 'F', 'i', 'l', 'e'}
 	shareVersion = "0.4"
 
+	// inlineShareVersion is the version of .sia files that contain inline
+	// files. In these, each file is followed by its inline contents, which
+	// older versions cannot read, so files without inline contents are still
+	// shared as shareVersion.
+	inlineShareVersion = "0.5"
+
 	saveMetadata = persist.Metadata{
 		Header:  "Renter Persistence",
 		Version: "0.4",
@@ -181,8 +187,10 @@ func (r *Renter) saveFile(f *file) error {
 // saveSync stores the current renter data to disk and then syncs to disk.
 func (r *Renter) saveSync() error {
 	data := struct {
-		Tracking map[string]trackedFile
-	}{r.tracking}
+		Tracking        map[string]trackedFile
+		MetadataSectors map[types.FileContractID]metadataSector
+		Schedule        modules.RenterSchedule
+	}{r.tracking, r.metadataSectors, r.schedule}
 
 	return persist.SaveJSON(saveMetadata, data, filepath.Join(r.persistDir, PersistFilename))
 }
@@ -226,8 +234,10 @@ func (r *Renter) load() error {
 
 	// Load contracts, repair set, and entropy.
 	data := struct {
-		Tracking  map[string]trackedFile
-		Repairing map[string]string // COMPATv0.4.8
+		Tracking        map[string]trackedFile
+		MetadataSectors map[types.FileContractID]metadataSector
+		Schedule        modules.RenterSchedule
+		Repairing       map[string]string // COMPATv0.4.8
 	}{}
 	err = persist.LoadJSON(saveMetadata, &data, filepath.Join(r.persistDir, PersistFilename))
 	if err != nil {
@@ -236,17 +246,46 @@ func (r *Renter) load() error {
 	if data.Tracking != nil {
 		r.tracking = data.Tracking
 	}
+	if data.MetadataSectors != nil {
+		r.metadataSectors = data.MetadataSectors
+	}
+	r.schedule = data.Schedule
 
 	return nil
 }
 
+// rlockFiles read-locks each of files once, and returns a function that
+// unlocks them. The files may contain duplicates.
+func rlockFiles(files []*file) func() {
+	locked := make(map[*file]struct{})
+	for _, f := range files {
+		if _, exists := locked[f]; !exists {
+			f.mu.RLock()
+			locked[f] = struct{}{}
+		}
+	}
+	return func() {
+		for f := range locked {
+			f.mu.RUnlock()
+		}
+	}
+}
+
 // shareFiles writes the specified files to w. First a header is written,
-// followed by the gzipped concatenation of each file.
+// followed by the gzipped concatenation of each file. The caller must hold the
+// lock of each file.
 func shareFiles(files []*file, w io.Writer) error {
+	version := shareVersion
+	for _, f := range files {
+		if f.isInline() {
+			version = inlineShareVersion
+		}
+	}
+
 	// Write header.
 	err := encoding.NewEncoder(w).EncodeAll(
 		shareHeader,
-		shareVersion,
+		version,
 		uint64(len(files)),
 	)
 	if err != nil {
@@ -263,6 +302,11 @@ func shareFiles(files []*file, w io.Writer) error {
 		if err != nil {
 			return err
 		}
+		if version == inlineShareVersion {
+			if err := enc.Encode(f.inline); err != nil {
+				return err
+			}
+		}
 	}
 
 	return zip.Close()
@@ -293,6 +337,7 @@ func (r *Renter) ShareFiles(nicknames []string, shareDest string) error {
 		}
 		files[i] = f
 	}
+	defer rlockFiles(files)()
 
 	err = shareFiles(files, handle)
 	if err != nil {
@@ -317,6 +362,7 @@ func (r *Renter) ShareFilesAscii(nicknames []string) (string, error) {
 		}
 		files[i] = f
 	}
+	defer rlockFiles(files)()
 
 	buf := new(bytes.Buffer)
 	err := shareFiles(files, base64.NewEncoder(base64.URLEncoding, buf))
@@ -327,9 +373,9 @@ func (r *Renter) ShareFilesAscii(nicknames []string) (string, error) {
 	return buf.String(), nil
 }
 
-// loadSharedFiles reads .sia data from reader and registers the contained
-// files in the renter. It returns the nicknames of the loaded files.
-func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
+// readSharedFiles reads .sia data from reader and returns the contained
+// files.
+func readSharedFiles(reader io.Reader) ([]*file, error) {
 	// read header
 	var header [15]byte
 	var version string
@@ -343,7 +389,7 @@ func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
 		return nil, err
 	} else if header != shareHeader {
 		return nil, ErrBadFile
-	} else if version != shareVersion {
+	} else if version != shareVersion && version != inlineShareVersion {
 		return nil, ErrIncompatible
 	}
 
@@ -362,22 +408,45 @@ func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-
-		// Make sure the file's name does not conflict with existing files.
-		dupCount := 0
-		origName := files[i].name
-		for {
-			_, exists := r.files[files[i].name]
-			if !exists {
-				break
+		if version == inlineShareVersion {
+			if err := dec.Decode(&files[i].inline); err != nil {
+				return nil, err
 			}
-			dupCount++
-			files[i].name = origName + "_" + strconv.Itoa(dupCount)
 		}
 	}
+	return files, nil
+}
+
+// uniqueName returns name, with a suffix added if a file with that name
+// already exists in the renter.
+func (r *Renter) uniqueName(name string) string {
+	dupCount := 0
+	uniqueName := name
+	for {
+		_, exists := r.files[uniqueName]
+		if !exists {
+			return uniqueName
+		}
+		dupCount++
+		uniqueName = name + "_" + strconv.Itoa(dupCount)
+	}
+}
+
+// loadSharedFiles reads .sia data from reader and registers the contained
+// files in the renter. It returns the nicknames of the loaded files.
+func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
+	files, err := readSharedFiles(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the files' names do not conflict with existing files.
+	for _, f := range files {
+		f.name = r.uniqueName(f.name)
+	}
 
 	// Add files to renter.
-	names := make([]string, numFiles)
+	names := make([]string, len(files))
 	for i, f := range files {
 		r.files[f.name] = f
 		names[i] = f.name
@@ -0,0 +1,336 @@
+package renter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// Prefetching downloads chunks of files into a cache on local disk ahead of
+// time, so that later downloads of those chunks are served without contacting
+// any hosts. Chunks stay in the cache until their file is evicted, updated or
+// deleted. The cache is cleared when the renter starts.
+
+const (
+	// cacheDir is the directory within the renter's persist directory that
+	// holds the cache files.
+	cacheDir = "cache"
+
+	// cacheExtension is the extension of cache files.
+	cacheExtension = ".cache"
+)
+
+var (
+	errChunkOutOfRange = errors.New("chunk index is beyond the end of the file")
+	errPrefetchEmpty   = errors.New("cannot prefetch an empty file")
+)
+
+type (
+	// chunkCache tracks the chunks of each file that are held in the cache
+	// directory, keyed by the master key of the file so that entries survive
+	// renames.
+	chunkCache struct {
+		dir   string
+		files map[crypto.TwofishKey]*cachedFile
+		mu    sync.Mutex
+	}
+
+	// cachedFile is a file with chunks that were requested for prefetching.
+	// requested maps each requested chunk to whether it is resident in the
+	// cache file.
+	cachedFile struct {
+		file        *file
+		path        string
+		requested   map[uint64]bool
+		cachedBytes uint64
+		active      bool
+		err         error
+	}
+
+	// cacheWriter is a DownloadWriter that writes chunks into the cache file
+	// of a cachedFile, marking them resident as they arrive. Chunks must be
+	// written whole, at the offset of the start of the chunk.
+	cacheWriter struct {
+		cache *chunkCache
+		cf    *cachedFile
+		f     *os.File
+	}
+)
+
+// newChunkCache returns an empty chunk cache stored in dir, removing any cache
+// files left in dir. Only cache files are removed, as dir may also hold the
+// .sia files of files uploaded under the same path.
+func newChunkCache(dir string) (*chunkCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	leftover, err := filepath.Glob(filepath.Join(dir, "*"+cacheExtension))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range leftover {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return &chunkCache{
+		dir:   dir,
+		files: make(map[crypto.TwofishKey]*cachedFile),
+	}, nil
+}
+
+// Destination implements the DownloadWriter interface.
+func (cw *cacheWriter) Destination() string {
+	return "cache"
+}
+
+// WriteAt writes a chunk to the cache file and marks it resident.
+func (cw *cacheWriter) WriteAt(b []byte, off int64) (int, error) {
+	n, err := cw.f.WriteAt(b, off)
+	if err != nil {
+		return n, err
+	}
+	cw.cache.mu.Lock()
+	index := uint64(off) / cw.cf.file.chunkSize()
+	if !cw.cf.requested[index] {
+		cw.cf.requested[index] = true
+		cw.cf.cachedBytes += uint64(n)
+	}
+	cw.cache.mu.Unlock()
+	return n, nil
+}
+
+// Close implements the DownloadWriter interface.
+func (cw *cacheWriter) Close() error {
+	return cw.f.Close()
+}
+
+// managedEvict removes the cached chunks of a file.
+func (cc *chunkCache) managedEvict(masterKey crypto.TwofishKey) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cf, exists := cc.files[masterKey]
+	if !exists {
+		return
+	}
+	delete(cc.files, masterKey)
+	os.Remove(cf.path)
+}
+
+// managedNextRun returns the next run of consecutive chunks of a file that are
+// requested but not resident. If there are none, the file is marked inactive.
+func (cc *chunkCache) managedNextRun(cf *cachedFile) (first, last uint64, ok bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	var missing []uint64
+	for index, resident := range cf.requested {
+		if !resident {
+			missing = append(missing, index)
+		}
+	}
+	// An evicted file has no further chunks to download.
+	if len(missing) == 0 || cc.files[cf.file.masterKey] != cf {
+		cf.active = false
+		return 0, 0, false
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	first, last = missing[0], missing[0]
+	for _, index := range missing[1:] {
+		if index != last+1 {
+			break
+		}
+		last = index
+	}
+	return first, last, true
+}
+
+// managedServe writes a section of a file to dw from the cache. It returns
+// false, leaving dw untouched, if any chunk of the section is not resident.
+func (cc *chunkCache) managedServe(f *file, dw modules.DownloadWriter, offset, length uint64) (bool, error) {
+	cc.mu.Lock()
+	cf, exists := cc.files[f.masterKey]
+	if !exists {
+		cc.mu.Unlock()
+		return false, nil
+	}
+	for i := offset / f.chunkSize(); i <= (offset+length-1)/f.chunkSize(); i++ {
+		if !cf.requested[i] {
+			cc.mu.Unlock()
+			return false, nil
+		}
+	}
+	path := cf.path
+	cc.mu.Unlock()
+
+	// If the cache file cannot be read, for example because the file was
+	// evicted in the meantime, the section is downloaded from the hosts.
+	cacheFile, err := os.Open(path)
+	if err != nil {
+		return false, nil
+	}
+	defer cacheFile.Close()
+	data := make([]byte, length)
+	if _, err := cacheFile.ReadAt(data, int64(offset)); err != nil {
+		return false, nil
+	}
+	if _, err := dw.WriteAt(data, int64(offset)); err != nil {
+		return true, err
+	}
+	return true, dw.Close()
+}
+
+// threadedPrefetch downloads the requested chunks of a file into the cache.
+func (r *Renter) threadedPrefetch(cf *cachedFile) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	f := cf.file
+	for {
+		first, last, ok := r.cache.managedNextRun(cf)
+		if !ok {
+			return
+		}
+		f.mu.RLock()
+		offset := first * f.chunkSize()
+		end := (last + 1) * f.chunkSize()
+		if end > f.size {
+			end = f.size
+		}
+		f.mu.RUnlock()
+		cacheFile, err := os.OpenFile(cf.path, os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			r.cache.managedFail(cf, err)
+			return
+		}
+		d := r.newSectionDownload(f, &cacheWriter{cache: r.cache, cf: cf, f: cacheFile}, offset, end-offset)
+		select {
+		case r.newDownloads <- d:
+		case <-r.tg.StopChan():
+			cacheFile.Close()
+			return
+		}
+		select {
+		case <-d.downloadFinished:
+		case <-r.tg.StopChan():
+			return
+		}
+		if err := d.Err(); err != nil {
+			r.cache.managedFail(cf, err)
+			return
+		}
+	}
+}
+
+// managedFail records the error that stopped the prefetching of a file.
+func (cc *chunkCache) managedFail(cf *cachedFile, err error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cf.active = false
+	cf.err = err
+}
+
+// Prefetch downloads chunks of a file into the local cache in the background.
+// All chunks of the file are prefetched if chunks is empty. Chunks that are
+// already cached are not downloaded again.
+func (r *Renter) Prefetch(siaPath string, chunks []uint64) error {
+	id := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	r.mu.RUnlock(id)
+	if !exists {
+		return ErrUnknownPath
+	}
+	f.mu.RLock()
+	size, numChunks, inline := f.size, f.numChunks(), f.isInline()
+	f.mu.RUnlock()
+	if inline {
+		return errPrefetchInline
+	}
+	if size == 0 {
+		return errPrefetchEmpty
+	}
+	if len(chunks) == 0 {
+		for i := uint64(0); i < numChunks; i++ {
+			chunks = append(chunks, i)
+		}
+	}
+	for _, index := range chunks {
+		if index >= numChunks {
+			return fmt.Errorf("%v: chunk %v of %v", errChunkOutOfRange, index, numChunks)
+		}
+	}
+
+	r.cache.mu.Lock()
+	cf, exists := r.cache.files[f.masterKey]
+	if !exists {
+		cf = &cachedFile{
+			file:      f,
+			path:      filepath.Join(r.cache.dir, crypto.HashBytes(f.masterKey[:]).String()+cacheExtension),
+			requested: make(map[uint64]bool),
+		}
+		r.cache.files[f.masterKey] = cf
+	}
+	for _, index := range chunks {
+		if _, requested := cf.requested[index]; !requested {
+			cf.requested[index] = false
+		}
+	}
+	cf.err = nil
+	start := !cf.active
+	cf.active = true
+	r.cache.mu.Unlock()
+
+	if start {
+		go r.threadedPrefetch(cf)
+	}
+	return nil
+}
+
+// PrefetchInfo returns the progress of every file that has chunks requested
+// for prefetching, sorted by path.
+func (r *Renter) PrefetchInfo() []modules.RenterPrefetchInfo {
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+	var infos []modules.RenterPrefetchInfo
+	for _, cf := range r.cache.files {
+		cf.file.mu.RLock()
+		info := modules.RenterPrefetchInfo{
+			SiaPath:     cf.file.name,
+			Chunks:      uint64(len(cf.requested)),
+			TotalChunks: cf.file.numChunks(),
+			CachedBytes: cf.cachedBytes,
+			Active:      cf.active,
+		}
+		cf.file.mu.RUnlock()
+		for _, resident := range cf.requested {
+			if resident {
+				info.CachedChunks++
+			}
+		}
+		if cf.err != nil {
+			info.Error = cf.err.Error()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].SiaPath < infos[j].SiaPath })
+	return infos
+}
+
+// EvictPrefetched removes the cached chunks of a file from the local cache.
+func (r *Renter) EvictPrefetched(siaPath string) error {
+	id := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	r.mu.RUnlock(id)
+	if !exists {
+		return ErrUnknownPath
+	}
+	r.cache.managedEvict(f.masterKey)
+	return nil
+}
@@ -0,0 +1,89 @@
+package renter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// TestChunkCacheNextRun checks that the chunks to prefetch are grouped into
+// runs of consecutive chunks.
+func TestChunkCacheNextRun(t *testing.T) {
+	cc, err := newChunkCache(build.TempDir("renter", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsc, _ := NewRSCode(1, 1)
+	f := newFile("foo", rsc, 100, 1000)
+	cf := &cachedFile{
+		file:      f,
+		requested: map[uint64]bool{1: false, 2: false, 3: true, 4: false, 7: false},
+		active:    true,
+	}
+	cc.files[f.masterKey] = cf
+
+	if first, last, ok := cc.managedNextRun(cf); !ok || first != 1 || last != 2 {
+		t.Fatalf("expected run 1-2, got %v-%v", first, last)
+	}
+	cf.requested[1], cf.requested[2] = true, true
+	if first, last, ok := cc.managedNextRun(cf); !ok || first != 4 || last != 4 {
+		t.Fatalf("expected run 4-4, got %v-%v", first, last)
+	}
+	cf.requested[4], cf.requested[7] = true, true
+	if _, _, ok := cc.managedNextRun(cf); ok || cf.active {
+		t.Fatal("expected no runs once every chunk is resident")
+	}
+
+	// An evicted file has no runs.
+	cf.requested[8] = false
+	cf.active = true
+	cc.managedEvict(f.masterKey)
+	if _, _, ok := cc.managedNextRun(cf); ok {
+		t.Fatal("expected no runs for an evicted file")
+	}
+}
+
+// TestChunkCacheServe checks that sections of a file are only served from the
+// cache when all of their chunks are resident.
+func TestChunkCacheServe(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	cc, err := newChunkCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsc, _ := NewRSCode(1, 1)
+	f := newFile("foo", rsc, 100, 250)
+	data := bytes.Repeat([]byte{1, 2, 3, 4, 5}, 50)
+	path := filepath.Join(dir, "foo"+cacheExtension)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cc.files[f.masterKey] = &cachedFile{
+		file:      f,
+		path:      path,
+		requested: map[uint64]bool{0: true, 1: true, 2: false},
+	}
+
+	dw := NewDownloadBufferWriter(150, 50)
+	if served, err := cc.managedServe(f, dw, 50, 150); !served || err != nil {
+		t.Fatal("section was not served from the cache:", err)
+	}
+	if !bytes.Equal(dw.Bytes(), data[50:200]) {
+		t.Error("section served from the cache does not match the file")
+	}
+	if served, _ := cc.managedServe(f, NewDownloadBufferWriter(100, 150), 150, 100); served {
+		t.Error("section with a chunk that is not resident was served from the cache")
+	}
+
+	// Cache files left by a previous run are removed.
+	if _, err := newChunkCache(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("leftover cache file was not removed")
+	}
+}
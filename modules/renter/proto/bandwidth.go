@@ -0,0 +1,74 @@
+package proto
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// A BandwidthReport describes the data exchanged with a host during one
+// revision of a Downloader or Editor, and what the renter paid the host for
+// the bandwidth.
+type BandwidthReport struct {
+	// Upload is true if the revision sent data to the host, and false if it
+	// retrieved data from the host.
+	Upload bool
+
+	// Sent and Received are the bytes written to and read from the
+	// connection since the previous report, including protocol overhead.
+	Sent     uint64
+	Received uint64
+
+	// Price is the bandwidth price of the revision, which pays for Data
+	// bytes of sector data. Paid is true if the renter's signature of the
+	// revision was sent to the host, after which the host may hold the
+	// payment even if the revision fails. Delivered is true if the data was
+	// transferred successfully.
+	Price     types.Currency
+	Data      uint64
+	Paid      bool
+	Delivered bool
+}
+
+// A bandwidthReporter is called after each revision of a Downloader or
+// Editor.
+type bandwidthReporter func(BandwidthReport)
+
+// A countingConn is a net.Conn that counts the bytes read from and written
+// to it.
+type countingConn struct {
+	net.Conn
+	read    uint64 // atomic
+	written uint64 // atomic
+
+	// reportedRead and reportedWritten are the counters at the time of the
+	// previous report. They are only accessed by the revision loop.
+	reportedRead    uint64
+	reportedWritten uint64
+}
+
+// Read implements io.Reader.
+func (cc *countingConn) Read(b []byte) (int, error) {
+	n, err := cc.Conn.Read(b)
+	atomic.AddUint64(&cc.read, uint64(n))
+	return n, err
+}
+
+// Write implements io.Writer.
+func (cc *countingConn) Write(b []byte) (int, error) {
+	n, err := cc.Conn.Write(b)
+	atomic.AddUint64(&cc.written, uint64(n))
+	return n, err
+}
+
+// report calls fn, if it is non-nil, with the bytes transferred since the
+// previous report filled into r.
+func (cc *countingConn) report(fn bandwidthReporter, r BandwidthReport) {
+	read, written := atomic.LoadUint64(&cc.read), atomic.LoadUint64(&cc.written)
+	r.Received, r.Sent = read-cc.reportedRead, written-cc.reportedWritten
+	cc.reportedRead, cc.reportedWritten = read, written
+	if fn != nil {
+		fn(r)
+	}
+}
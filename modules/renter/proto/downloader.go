@@ -17,12 +17,13 @@ import (
 type Downloader struct {
 	host      modules.HostDBEntry
 	contract  modules.RenterContract // updated after each revision
-	conn      net.Conn
+	conn      *countingConn
 	closeChan chan struct{}
 	once      sync.Once
 	hdb       hostDB
 
-	SaveFn revisionSaver
+	SaveFn      revisionSaver
+	BandwidthFn bandwidthReporter
 }
 
 // Sector retrieves the sector with the specified Merkle root, and revises
@@ -46,6 +47,17 @@ func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []by
 	// create the download revision
 	rev := newDownloadRevision(hd.contract.LastRevision, sectorPrice)
 
+	// Report the bandwidth used by the download.
+	var signed bool
+	defer func() {
+		hd.conn.report(hd.BandwidthFn, BandwidthReport{
+			Price:     sectorPrice,
+			Data:      modules.SectorSize,
+			Paid:      signed,
+			Delivered: err == nil,
+		})
+	}()
+
 	// initiate download by confirming host settings
 	extendDeadline(hd.conn, modules.NegotiateSettingsTime)
 	if err := startDownload(hd.conn, hd.host); err != nil {
@@ -85,7 +97,7 @@ func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []by
 
 	// send the revision to the host for approval
 	extendDeadline(hd.conn, 2*time.Minute)
-	signedTxn, err := negotiateRevision(hd.conn, rev, hd.contract.SecretKey)
+	signedTxn, signed, err := negotiateRevision(hd.conn, rev, hd.contract.SecretKey)
 	if err == modules.ErrStopResponse {
 		// if host gracefully closed, close our connection as well; this will
 		// cause the next download to fail. However, we must delay closing
@@ -159,13 +171,14 @@ func NewDownloader(host modules.HostDBEntry, contract modules.RenterContract, hd
 	}()
 
 	// initiate download loop
-	conn, err := (&net.Dialer{
+	rawConn, err := (&net.Dialer{
 		Cancel:  cancel,
 		Timeout: 15 * time.Second,
 	}).Dial("tcp", string(contract.NetAddress))
 	if err != nil {
 		return nil, err
 	}
+	conn := &countingConn{Conn: modules.LimitConn(rawConn, modules.BandwidthRenter)}
 
 	closeChan := make(chan struct{})
 	go func() {
@@ -43,7 +43,7 @@ func cachedMerkleRoot(roots []crypto.Hash) crypto.Hash {
 // A Editor modifies a Contract by calling the revise RPC on a host. It
 // Editors are NOT thread-safe; calls to Upload must happen in serial.
 type Editor struct {
-	conn      net.Conn
+	conn      *countingConn
 	closeChan chan struct{}
 	once      sync.Once
 	host      modules.HostDBEntry
@@ -52,7 +52,8 @@ type Editor struct {
 	height   types.BlockHeight
 	contract modules.RenterContract // updated after each revision
 
-	SaveFn revisionSaver
+	SaveFn      revisionSaver
+	BandwidthFn bandwidthReporter
 }
 
 // shutdown terminates the revision loop and signals the goroutine spawned in
@@ -75,9 +76,15 @@ func (he *Editor) Close() error {
 
 // runRevisionIteration submits actions and their accompanying revision to the
 // host for approval. If negotiation is successful, it updates the underlying
-// Contract.
-func (he *Editor) runRevisionIteration(actions []modules.RevisionAction, rev types.FileContractRevision, newRoots []crypto.Hash) (err error) {
+// Contract. The bandwidth paid for by the revision is reported to BandwidthFn.
+func (he *Editor) runRevisionIteration(actions []modules.RevisionAction, rev types.FileContractRevision, newRoots []crypto.Hash, bandwidth BandwidthReport) (err error) {
+	var signed bool
 	defer func() {
+		bandwidth.Upload = true
+		bandwidth.Paid = signed
+		bandwidth.Delivered = err == nil
+		he.conn.report(he.BandwidthFn, bandwidth)
+
 		// Increase Successful/Failed interactions accordingly
 		if err != nil {
 			he.hdb.IncrementFailedInteractions(he.contract.HostPublicKey)
@@ -114,7 +121,7 @@ func (he *Editor) runRevisionIteration(actions []modules.RevisionAction, rev typ
 
 	// send revision to host and exchange signatures
 	extendDeadline(he.conn, 2*time.Minute)
-	signedTxn, err := negotiateRevision(he.conn, rev, he.contract.SecretKey)
+	signedTxn, signed, err := negotiateRevision(he.conn, rev, he.contract.SecretKey)
 	if err == modules.ErrStopResponse {
 		// if host gracefully closed, close our connection as well; this will
 		// cause the next operation to fail
@@ -171,7 +178,8 @@ func (he *Editor) Upload(data []byte) (modules.RenterContract, crypto.Hash, erro
 	rev := newUploadRevision(he.contract.LastRevision, merkleRoot, sectorPrice, sectorCollateral)
 
 	// run the revision iteration
-	if err := he.runRevisionIteration(actions, rev, newRoots); err != nil {
+	bandwidth := BandwidthReport{Price: sectorBandwidthPrice, Data: modules.SectorSize}
+	if err := he.runRevisionIteration(actions, rev, newRoots, bandwidth); err != nil {
 		return modules.RenterContract{}, crypto.Hash{}, err
 	}
 
@@ -207,7 +215,7 @@ func (he *Editor) Delete(root crypto.Hash) (modules.RenterContract, error) {
 	rev := newDeleteRevision(he.contract.LastRevision, merkleRoot)
 
 	// run the revision iteration
-	if err := he.runRevisionIteration(actions, rev, newRoots); err != nil {
+	if err := he.runRevisionIteration(actions, rev, newRoots, BandwidthReport{}); err != nil {
 		return modules.RenterContract{}, err
 	}
 	return he.contract, nil
@@ -247,7 +255,8 @@ func (he *Editor) Modify(oldRoot, newRoot crypto.Hash, offset uint64, newData []
 	rev := newModifyRevision(he.contract.LastRevision, merkleRoot, sectorBandwidthPrice)
 
 	// run the revision iteration
-	if err := he.runRevisionIteration(actions, rev, newRoots); err != nil {
+	bandwidth := BandwidthReport{Price: sectorBandwidthPrice, Data: uint64(len(newData))}
+	if err := he.runRevisionIteration(actions, rev, newRoots, bandwidth); err != nil {
 		return modules.RenterContract{}, err
 	}
 
@@ -276,13 +285,14 @@ func NewEditor(host modules.HostDBEntry, contract modules.RenterContract, curren
 	}()
 
 	// initiate revision loop
-	conn, err := (&net.Dialer{
+	rawConn, err := (&net.Dialer{
 		Cancel:  cancel,
 		Timeout: 15 * time.Second,
 	}).Dial("tcp", string(contract.NetAddress))
 	if err != nil {
 		return nil, err
 	}
+	conn := &countingConn{Conn: modules.LimitConn(rawConn, modules.BandwidthRenter)}
 
 	closeChan := make(chan struct{})
 	go func() {
@@ -117,6 +117,7 @@ func FormContract(params ContractParams, txnBuilder transactionBuilder, tpool tr
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
+	conn = modules.LimitConn(conn, modules.BandwidthRenter)
 	defer func() { _ = conn.Close() }()
 
 	// Allot time for sending RPC ID + verifySettings.
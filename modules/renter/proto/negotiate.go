@@ -66,38 +66,49 @@ func verifySettings(conn net.Conn, host modules.HostDBEntry) (modules.HostDBEntr
 	return host, nil
 }
 
-// verifyRecentRevision confirms that the host and contractor agree upon the current
-// state of the contract being revised.
-func verifyRecentRevision(conn net.Conn, contract modules.RenterContract, hostVersion string) error {
+// readRecentRevision proves ownership of the contract 'id' to the host by
+// signing its challenge with the renter's secret key, and reads the host's
+// most recent revision of the contract along with its signatures.
+func readRecentRevision(conn net.Conn, id types.FileContractID, sk crypto.SecretKey, hostVersion string) (types.FileContractRevision, []types.TransactionSignature, error) {
 	// send contract ID
-	if err := encoding.WriteObject(conn, contract.ID); err != nil {
-		return errors.New("couldn't send contract ID: " + err.Error())
+	if err := encoding.WriteObject(conn, id); err != nil {
+		return types.FileContractRevision{}, nil, errors.New("couldn't send contract ID: " + err.Error())
 	}
 	// read challenge
 	var challenge crypto.Hash
 	if err := encoding.ReadObject(conn, &challenge, 32); err != nil {
-		return errors.New("couldn't read challenge: " + err.Error())
+		return types.FileContractRevision{}, nil, errors.New("couldn't read challenge: " + err.Error())
 	}
 	if build.VersionCmp(hostVersion, "1.3.0") >= 0 {
 		crypto.SecureWipe(challenge[:16])
 	}
 	// sign and return
-	sig := crypto.SignHash(challenge, contract.SecretKey)
+	sig := crypto.SignHash(challenge, sk)
 	if err := encoding.WriteObject(conn, sig); err != nil {
-		return errors.New("couldn't send challenge response: " + err.Error())
+		return types.FileContractRevision{}, nil, errors.New("couldn't send challenge response: " + err.Error())
 	}
 	// read acceptance
 	if err := modules.ReadNegotiationAcceptance(conn); err != nil {
-		return errors.New("host did not accept revision request: " + err.Error())
+		return types.FileContractRevision{}, nil, errors.New("host did not accept revision request: " + err.Error())
 	}
 	// read last revision and signatures
 	var lastRevision types.FileContractRevision
 	var hostSignatures []types.TransactionSignature
 	if err := encoding.ReadObject(conn, &lastRevision, 2048); err != nil {
-		return errors.New("couldn't read last revision: " + err.Error())
+		return types.FileContractRevision{}, nil, errors.New("couldn't read last revision: " + err.Error())
 	}
 	if err := encoding.ReadObject(conn, &hostSignatures, 2048); err != nil {
-		return errors.New("couldn't read host signatures: " + err.Error())
+		return types.FileContractRevision{}, nil, errors.New("couldn't read host signatures: " + err.Error())
+	}
+	return lastRevision, hostSignatures, nil
+}
+
+// verifyRecentRevision confirms that the host and contractor agree upon the current
+// state of the contract being revised.
+func verifyRecentRevision(conn net.Conn, contract modules.RenterContract, hostVersion string) error {
+	lastRevision, hostSignatures, err := readRecentRevision(conn, contract.ID, contract.SecretKey, hostVersion)
+	if err != nil {
+		return err
 	}
 	// Check that the unlock hashes match; if they do not, something is
 	// seriously wrong. Otherwise, check that the revision numbers match.
@@ -113,8 +124,10 @@ func verifyRecentRevision(conn net.Conn, contract modules.RenterContract, hostVe
 }
 
 // negotiateRevision sends a revision and actions to the host for approval,
-// completing one iteration of the revision loop.
-func negotiateRevision(conn net.Conn, rev types.FileContractRevision, secretKey crypto.SecretKey) (types.Transaction, error) {
+// completing one iteration of the revision loop. signed reports whether the
+// renter's signature was sent to the host, even if an error occurred
+// afterwards.
+func negotiateRevision(conn net.Conn, rev types.FileContractRevision, secretKey crypto.SecretKey) (_ types.Transaction, signed bool, _ error) {
 	// create transaction containing the revision
 	signedTxn := types.Transaction{
 		FileContractRevisions: []types.FileContractRevision{rev},
@@ -130,27 +143,27 @@ func negotiateRevision(conn net.Conn, rev types.FileContractRevision, secretKey
 
 	// send the revision
 	if err := encoding.WriteObject(conn, rev); err != nil {
-		return types.Transaction{}, errors.New("couldn't send revision: " + err.Error())
+		return types.Transaction{}, false, errors.New("couldn't send revision: " + err.Error())
 	}
 	// read acceptance
 	if err := modules.ReadNegotiationAcceptance(conn); err != nil {
-		return types.Transaction{}, errors.New("host did not accept revision: " + err.Error())
+		return types.Transaction{}, false, errors.New("host did not accept revision: " + err.Error())
 	}
 
 	// send the new transaction signature
 	if err := encoding.WriteObject(conn, signedTxn.TransactionSignatures[0]); err != nil {
-		return types.Transaction{}, errors.New("couldn't send transaction signature: " + err.Error())
+		return types.Transaction{}, false, errors.New("couldn't send transaction signature: " + err.Error())
 	}
 	// read the host's acceptance and transaction signature
 	// NOTE: if the host sends ErrStopResponse, we should continue processing
 	// the revision, but return the error anyway.
 	responseErr := modules.ReadNegotiationAcceptance(conn)
 	if responseErr != nil && responseErr != modules.ErrStopResponse {
-		return types.Transaction{}, errors.New("host did not accept transaction signature: " + responseErr.Error())
+		return types.Transaction{}, true, errors.New("host did not accept transaction signature: " + responseErr.Error())
 	}
 	var hostSig types.TransactionSignature
 	if err := encoding.ReadObject(conn, &hostSig, 16e3); err != nil {
-		return types.Transaction{}, errors.New("couldn't read host's signature: " + err.Error())
+		return types.Transaction{}, true, errors.New("couldn't read host's signature: " + err.Error())
 	}
 
 	// add the signature to the transaction and verify it
@@ -160,11 +173,11 @@ func negotiateRevision(conn net.Conn, rev types.FileContractRevision, secretKey
 	verificationHeight := rev.NewWindowStart - 1
 	signedTxn.TransactionSignatures = append(signedTxn.TransactionSignatures, hostSig)
 	if err := signedTxn.StandaloneValid(verificationHeight); err != nil {
-		return types.Transaction{}, err
+		return types.Transaction{}, true, err
 	}
 
 	// if the host sent ErrStopResponse, return it
-	return signedTxn, responseErr
+	return signedTxn, true, responseErr
 }
 
 // newRevision creates a copy of current with its revision number incremented,
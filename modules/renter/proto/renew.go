@@ -115,6 +115,7 @@ func Renew(contract modules.RenterContract, params ContractParams, txnBuilder tr
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
+	conn = modules.LimitConn(conn, modules.BandwidthRenter)
 	defer func() { _ = conn.Close() }()
 
 	// allot time for sending RPC ID, verifyRecentRevision, and verifySettings
@@ -0,0 +1,57 @@
+package proto
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// FetchRevision retrieves the most recent revision of a contract from its
+// host, proving ownership of the contract with the renter's secret key. It is
+// used to recover contracts whose revisions the renter no longer has. The
+// revision is returned in a transaction along with the signatures of the
+// renter and the host, which are checked.
+func FetchRevision(host modules.HostDBEntry, contract modules.RenterContract, cancel <-chan struct{}) (types.Transaction, error) {
+	rawConn, err := (&net.Dialer{
+		Cancel:  cancel,
+		Timeout: 15 * time.Second,
+	}).Dial("tcp", string(host.NetAddress))
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	conn := modules.LimitConn(rawConn, modules.BandwidthRenter)
+	defer conn.Close()
+
+	extendDeadline(conn, modules.NegotiateRecentRevisionTime)
+	if err := encoding.WriteObject(conn, modules.RPCReviseContract); err != nil {
+		return types.Transaction{}, errors.New("couldn't initiate RPC: " + err.Error())
+	}
+	rev, sigs, err := readRecentRevision(conn, contract.ID, contract.SecretKey, host.Version)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if rev.ParentID != contract.ID {
+		return types.Transaction{}, errors.New("host sent a revision of a different contract")
+	}
+	if rev.UnlockConditions.UnlockHash() != contract.FileContract.UnlockHash {
+		return types.Transaction{}, errors.New("unlock conditions do not match the contract")
+	}
+	// The height only needs to be below the end of the contract.
+	if err := modules.VerifyFileContractRevisionTransactionSignatures(rev, sigs, rev.NewWindowStart-1); err != nil {
+		return types.Transaction{}, err
+	}
+
+	// End the revision loop that the host has entered.
+	extendDeadline(conn, modules.NegotiateSettingsTime)
+	_, _ = verifySettings(conn, host)
+	_ = modules.WriteNegotiationStop(conn)
+
+	return types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{rev},
+		TransactionSignatures: sigs,
+	}, nil
+}
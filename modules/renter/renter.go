@@ -9,8 +9,10 @@ package renter
 
 import (
 	"errors"
+	"path/filepath"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/renter/contractor"
 	"github.com/NebulousLabs/Sia/modules/renter/hostdb"
@@ -24,6 +26,10 @@ var (
 	errNilCS         = errors.New("cannot create renter with nil consensus set")
 	errNilTpool      = errors.New("cannot create renter with nil transaction pool")
 	errNilHdb        = errors.New("cannot create renter with nil hostdb")
+
+	// ErrRecoveryUpload is returned when a file is uploaded to a renter that
+	// was started in recovery mode.
+	ErrRecoveryUpload = errors.New("files cannot be uploaded in recovery mode")
 )
 
 var (
@@ -90,6 +96,9 @@ type hostContractor interface {
 	// Contract returns the latest contract formed with the specified host.
 	Contract(modules.NetAddress) (modules.RenterContract, bool)
 
+	// Bandwidth returns the data exchanged with each host.
+	Bandwidth() []modules.RenterBandwidth
+
 	// Contracts returns the contracts formed by the contractor.
 	Contracts() []modules.RenterContract
 
@@ -117,6 +126,9 @@ type hostContractor interface {
 
 	// ResolveID returns the most recent renewal of the specified ID.
 	ResolveID(types.FileContractID) types.FileContractID
+
+	// RecoverContracts reattaches old contracts by their IDs.
+	RecoverContracts([]types.FileContractID) []modules.RenterContractRecovery
 }
 
 // A trackedFile contains metadata about files being tracked by the Renter.
@@ -126,6 +138,10 @@ type hostContractor interface {
 type trackedFile struct {
 	// location of original file on disk
 	RepairPath string
+
+	// hashes of the chunks of the file when it was uploaded, used to find
+	// the chunks that changed when the file is updated
+	ChunkHashes []crypto.Hash
 }
 
 // A Renter is responsible for tracking all of the files that a user has
@@ -138,6 +154,11 @@ type Renter struct {
 	files    map[string]*file
 	tracking map[string]trackedFile // map from nickname to metadata
 
+	// metadataSectors tracks the sectors that store a copy of the file
+	// metadata on the host of each contract, so that the file list can be
+	// recovered from the hosts.
+	metadataSectors map[types.FileContractID]metadataSector
+
 	// Work management.
 	//
 	// chunkQueue contains a list of incomplete work that the download loop acts
@@ -152,6 +173,25 @@ type Renter struct {
 	newRepairs    chan *file
 	workerPool    map[types.FileContractID]*worker
 
+	// updatedFiles contains the master keys of the files whose contents were
+	// replaced by UpdateFile. The repair loop discards the chunks of these
+	// files that it has cached before repairing them again.
+	updatedFiles map[crypto.TwofishKey]struct{}
+
+	// cache holds the chunks that were prefetched for fast local downloads.
+	cache *chunkCache
+
+	// schedule restricts when uploads and repairs run.
+	schedule modules.RenterSchedule
+
+	// recovery indicates that the renter only downloads files from its
+	// existing contracts. Files are neither uploaded nor repaired.
+	recovery bool
+
+	// inlineThreshold is the size of the largest file that is stored inline
+	// in the renter's metadata.
+	inlineThreshold uint64
+
 	// Utilities.
 	cs             modules.ConsensusSet
 	hostContractor hostContractor
@@ -174,11 +214,28 @@ func New(g modules.Gateway, cs modules.ConsensusSet, wallet modules.Wallet, tpoo
 		return nil, err
 	}
 
-	return newRenter(cs, tpool, hdb, hc, persistDir)
+	return newRenter(cs, tpool, hdb, hc, persistDir, false)
+}
+
+// NewRecovery returns a renter that can only download files from the
+// contracts it already has. It does not upload or repair files, and its
+// contractor never forms or renews contracts. It is meant for getting data
+// off of a node that cannot safely run the full renter.
+func NewRecovery(g modules.Gateway, cs modules.ConsensusSet, wallet modules.Wallet, tpool modules.TransactionPool, persistDir string) (*Renter, error) {
+	hdb, err := hostdb.New(g, cs, persistDir)
+	if err != nil {
+		return nil, err
+	}
+	hc, err := contractor.NewRecovery(cs, wallet, tpool, hdb, persistDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRenter(cs, tpool, hdb, hc, persistDir, true)
 }
 
 // newRenter initializes a renter and returns it.
-func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostDB, hc hostContractor, persistDir string) (*Renter, error) {
+func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostDB, hc hostContractor, persistDir string, recovery bool) (*Renter, error) {
 	if cs == nil {
 		return nil, errNilCS
 	}
@@ -194,12 +251,18 @@ func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostD
 	}
 
 	r := &Renter{
-		newRepairs: make(chan *file),
-		files:      make(map[string]*file),
-		tracking:   make(map[string]trackedFile),
+		newRepairs:   make(chan *file),
+		files:        make(map[string]*file),
+		tracking:     make(map[string]trackedFile),
+		updatedFiles: make(map[crypto.TwofishKey]struct{}),
+
+		metadataSectors: make(map[types.FileContractID]metadataSector),
 
 		newDownloads: make(chan *download),
 		workerPool:   make(map[types.FileContractID]*worker),
+		recovery:     recovery,
+
+		inlineThreshold: inlineThreshold,
 
 		cs:             cs,
 		hostDB:         hdb,
@@ -212,13 +275,21 @@ func newRenter(cs modules.ConsensusSet, tpool modules.TransactionPool, hdb hostD
 	if err := r.initPersist(); err != nil {
 		return nil, err
 	}
+	cache, err := newChunkCache(filepath.Join(persistDir, cacheDir))
+	if err != nil {
+		return nil, err
+	}
+	r.cache = cache
 
 	// Spin up the workers for the work pool.
 	contracts := r.hostContractor.Contracts()
 	r.updateWorkerPool(contracts)
-	go r.threadedRepairLoop()
 	go r.threadedDownloadLoop()
-	go r.threadedQueueRepairs()
+	if !recovery {
+		go r.threadedRepairLoop()
+		go r.threadedQueueRepairs()
+		go r.threadedUpdateMetadataSectors()
+	}
 
 	// Kill workers on shutdown.
 	r.tg.OnStop(func() {
@@ -298,13 +369,28 @@ func (r *Renter) PriceEstimation() modules.RenterPriceEstimation {
 
 // SetSettings will update the settings for the renter.
 func (r *Renter) SetSettings(s modules.RenterSettings) error {
-	err := r.hostContractor.SetAllowance(s.Allowance)
+	if err := validateSchedule(s.Schedule); err != nil {
+		return err
+	}
+	// Setting the allowance restarts contract maintenance, so it is skipped
+	// if only the schedule changed.
+	if a := r.hostContractor.Allowance(); !s.Allowance.Funds.Equals(a.Funds) || s.Allowance.Hosts != a.Hosts || s.Allowance.Period != a.Period || s.Allowance.RenewWindow != a.RenewWindow {
+		err := r.hostContractor.SetAllowance(s.Allowance)
+		if err != nil {
+			return err
+		}
+	}
+
+	id := r.mu.Lock()
+	r.schedule = s.Schedule
+	err := r.saveSync()
+	r.mu.Unlock(id)
 	if err != nil {
 		return err
 	}
 
 	contracts := r.hostContractor.Contracts()
-	id := r.mu.Lock()
+	id = r.mu.Lock()
 	r.updateWorkerPool(contracts)
 	r.mu.Unlock(id)
 	return nil
@@ -322,11 +408,19 @@ func (r *Renter) EstimateHostScore(e modules.HostDBEntry) modules.HostScoreBreak
 }
 
 // contractor passthroughs
-func (r *Renter) Contracts() []modules.RenterContract { return r.hostContractor.Contracts() }
-func (r *Renter) CurrentPeriod() types.BlockHeight    { return r.hostContractor.CurrentPeriod() }
+func (r *Renter) Bandwidth() []modules.RenterBandwidth { return r.hostContractor.Bandwidth() }
+func (r *Renter) Contracts() []modules.RenterContract  { return r.hostContractor.Contracts() }
+func (r *Renter) CurrentPeriod() types.BlockHeight     { return r.hostContractor.CurrentPeriod() }
+func (r *Renter) RecoverContracts(ids []types.FileContractID) []modules.RenterContractRecovery {
+	return r.hostContractor.RecoverContracts(ids)
+}
 func (r *Renter) Settings() modules.RenterSettings {
+	id := r.mu.RLock()
+	schedule := r.schedule
+	r.mu.RUnlock(id)
 	return modules.RenterSettings{
 		Allowance: r.hostContractor.Allowance(),
+		Schedule:  schedule,
 	}
 }
 func (r *Renter) AllContracts() []modules.RenterContract {
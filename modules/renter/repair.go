@@ -17,6 +17,10 @@ import (
 )
 
 var (
+	// errChunkRemoved indicates that a chunk which is trying to be repaired
+	// was removed from its file by an update.
+	errChunkRemoved = errors.New("cannot repair chunk as it is no longer part of the file")
+
 	// errFileDeleted indicates that a chunk which is trying to be repaired
 	// cannot be found in the renter.
 	errFileDeleted = errors.New("cannot repair chunk as the file is not being tracked by the renter")
@@ -70,6 +74,8 @@ type (
 		// from hosts.
 		//
 		// workerSet tracks the set of workers which can be used for uploading.
+		//
+		// schedule tracks whether the renter's schedule allows uploads.
 		activeWorkers     map[types.FileContractID]*worker
 		availableWorkers  map[types.FileContractID]*worker
 		gapCounts         map[int]int
@@ -77,6 +83,7 @@ type (
 		downloadingChunks map[chunkID]*downloadingChunk
 		cachedChunks      map[chunkID][]byte
 		resultChan        chan finishedUpload
+		schedule          scheduleState
 	}
 
 	// downloadingChunk tracks the download progress of a remote repair download
@@ -112,15 +119,33 @@ func (cs *chunkStatus) numGaps(rs *repairState) int {
 func (r *Renter) managedAddFileToRepairState(rs *repairState, file *file) {
 	// Check that the file is being tracked, and therefore candidate for
 	// repair.
-	id := r.mu.RLock()
+	id := r.mu.Lock()
 	file.mu.RLock()
 	_, exists := r.tracking[file.name]
 	file.mu.RUnlock()
-	r.mu.RUnlock(id)
+	_, updated := r.updatedFiles[file.masterKey]
+	delete(r.updatedFiles, file.masterKey)
+	r.mu.Unlock(id)
 	if !exists {
 		return
 	}
 
+	// If the contents of the file were replaced, the cached data and the
+	// status of its chunks are out of date.
+	if updated {
+		for cid, cs := range rs.incompleteChunks {
+			if cid.masterkey == file.masterKey {
+				rs.gapCounts[cs.recordedGaps]--
+				delete(rs.incompleteChunks, cid)
+			}
+		}
+		for cid := range rs.cachedChunks {
+			if cid.masterkey == file.masterKey {
+				delete(rs.cachedChunks, cid)
+			}
+		}
+	}
+
 	// Fetch the list of potential contracts from the repair state.
 	contracts := make([]types.FileContractID, 0)
 	for contract := range rs.activeWorkers {
@@ -216,6 +241,23 @@ func (r *Renter) managedRepairIteration(rs *repairState) {
 	default:
 	}
 
+	// While the schedule does not allow uploads, no new work is given to the
+	// workers, but uploads that are already running are allowed to finish
+	// and new files are still accepted.
+	if r.managedUploadsPaused(&rs.schedule) {
+		if len(rs.activeWorkers) > 0 {
+			r.managedWaitOnRepairWork(rs)
+			return
+		}
+		select {
+		case file := <-r.newRepairs:
+			r.managedAddFileToRepairState(rs, file)
+		case <-time.After(scheduleCheckInterval):
+		case <-r.tg.StopChan():
+		}
+		return
+	}
+
 	// Reset the available workers.
 	contracts := r.hostContractor.Contracts()
 	id := r.mu.Lock()
@@ -367,9 +409,16 @@ func (r *Renter) managedDownloadChunkData(rs *repairState, file *file, offset ui
 	}
 
 	// If the data is not yet downloaded initialize a new download
+	// The file may have shrunk since the chunk was scheduled.
+	file.mu.RLock()
+	size := file.size
+	file.mu.RUnlock()
+	if offset >= size {
+		return nil, errChunkRemoved
+	}
 	downloadSize := file.chunkSize()
-	if offset+downloadSize > file.size {
-		downloadSize = file.size - offset
+	if offset+downloadSize > size {
+		downloadSize = size - offset
 	}
 
 	// create a DownloadBufferWriter for the chunk
@@ -621,6 +670,7 @@ func (r *Renter) threadedQueueRepairs() {
 // reuploading their missing pieces. Multiple repair attempts may be necessary
 // before the file reaches full redundancy.
 func (r *Renter) threadedRepairLoop() {
+	defer build.CaptureCrash("renter")
 	rs := &repairState{
 		activeWorkers:     make(map[types.FileContractID]*worker),
 		availableWorkers:  make(map[types.FileContractID]*worker),
@@ -0,0 +1,142 @@
+package renter
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	errScheduleHour = errors.New("upload window hours must be between 0 and 23")
+
+	// errNoSystemTraffic is returned on systems where the network traffic of
+	// the machine cannot be measured.
+	errNoSystemTraffic = errors.New("system network traffic is not available")
+)
+
+// scheduleState tracks the network traffic of the machine, to determine
+// whether the other traffic exceeds the renter's MaxSystemTraffic. It is only
+// accessed by the repair loop.
+type scheduleState struct {
+	sampleTime    time.Time
+	systemTraffic uint64
+	renterTraffic uint64
+
+	// otherRate is the rate of the traffic not caused by the renter during
+	// the previous sample, in bytes per second.
+	otherRate uint64
+
+	// paused is the reason that uploads are paused, if they are.
+	paused string
+}
+
+// validateSchedule checks that the hours of a schedule are valid.
+func validateSchedule(s modules.RenterSchedule) error {
+	if s.WindowStart < 0 || s.WindowStart > 23 || s.WindowEnd < 0 || s.WindowEnd > 23 {
+		return errScheduleHour
+	}
+	return nil
+}
+
+// systemTraffic returns the total number of bytes sent and received by the
+// network interfaces of the machine, excluding the loopback interface. It is
+// only supported on Linux.
+func systemTraffic() (uint64, error) {
+	f, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return 0, errNoSystemTraffic
+	}
+	defer f.Close()
+
+	// Each interface is listed as "name: rxbytes rxpackets ... txbytes ...",
+	// with eight receive fields before the transmitted bytes.
+	var total uint64
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(strings.Replace(sc.Text(), ":", " ", 1))
+		if len(fields) < 10 || fields[0] == "lo" {
+			continue
+		}
+		rx, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		tx, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+		total += rx + tx
+	}
+	return total, sc.Err()
+}
+
+// renterTraffic returns the total number of bytes that the renter has
+// exchanged with hosts.
+func (r *Renter) renterTraffic() uint64 {
+	var total uint64
+	for _, rb := range r.hostContractor.Bandwidth() {
+		total += rb.UploadBytes + rb.DownloadBytes
+	}
+	return total
+}
+
+// managedUploadsPaused returns true if the schedule of the renter does not
+// allow uploads and repairs to run right now. Changes of the paused state are
+// logged.
+func (r *Renter) managedUploadsPaused(ss *scheduleState) bool {
+	id := r.mu.RLock()
+	schedule := r.schedule
+	r.mu.RUnlock(id)
+
+	var reason string
+	if !schedule.InWindow(time.Now()) {
+		reason = "outside of the upload window"
+	} else if schedule.MaxSystemTraffic != 0 {
+		r.sampleTraffic(ss)
+		if ss.otherRate > schedule.MaxSystemTraffic {
+			reason = "other network traffic is too high"
+		}
+	}
+
+	if reason != ss.paused {
+		if reason == "" {
+			r.log.Println("Resuming uploads and repairs")
+		} else {
+			r.log.Println("Pausing uploads and repairs:", reason)
+		}
+		ss.paused = reason
+	}
+	return reason != ""
+}
+
+// sampleTraffic updates the rate of the network traffic not caused by the
+// renter, if enough time has passed since the previous sample.
+func (r *Renter) sampleTraffic(ss *scheduleState) {
+	if time.Since(ss.sampleTime) < trafficSampleInterval {
+		return
+	}
+	system, err := systemTraffic()
+	if err != nil {
+		// Without a measurement, the limit cannot be enforced.
+		ss.otherRate = 0
+		return
+	}
+	renter := r.renterTraffic()
+	if !ss.sampleTime.IsZero() && system >= ss.systemTraffic {
+		other := system - ss.systemTraffic
+		if renter >= ss.renterTraffic {
+			if renterDelta := renter - ss.renterTraffic; renterDelta < other {
+				other -= renterDelta
+			} else {
+				other = 0
+			}
+		}
+		ss.otherRate = uint64(float64(other) / time.Since(ss.sampleTime).Seconds())
+	}
+	ss.sampleTime, ss.systemTraffic, ss.renterTraffic = time.Now(), system, renter
+}
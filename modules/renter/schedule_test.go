@@ -0,0 +1,70 @@
+package renter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestUploadSchedule checks that the schedule of the renter is saved, and
+// that it pauses uploads outside of the upload window.
+func TestUploadSchedule(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	var ss scheduleState
+	if rt.renter.managedUploadsPaused(&ss) {
+		t.Fatal("uploads paused without a schedule")
+	}
+
+	// A window that starts in an hour does not include the current time.
+	hour := time.Now().Hour()
+	settings := rt.renter.Settings()
+	settings.Schedule = modules.RenterSchedule{
+		WindowStart: (hour + 1) % 24,
+		WindowEnd:   (hour + 2) % 24,
+	}
+	if err := rt.renter.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if !rt.renter.managedUploadsPaused(&ss) {
+		t.Fatal("uploads not paused outside of the upload window")
+	}
+	if ss.paused == "" {
+		t.Fatal("paused state was not recorded")
+	}
+
+	// The schedule should be persisted.
+	id := rt.renter.mu.Lock()
+	rt.renter.schedule = modules.RenterSchedule{}
+	err = rt.renter.load()
+	rt.renter.mu.Unlock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := rt.renter.Settings().Schedule; s != settings.Schedule {
+		t.Fatalf("schedule was not persisted: expected %v, got %v", settings.Schedule, s)
+	}
+
+	// Invalid hours are rejected.
+	settings.Schedule.WindowEnd = 24
+	if err := rt.renter.SetSettings(settings); err != errScheduleHour {
+		t.Fatal("expected errScheduleHour, got", err)
+	}
+
+	// A window that includes the current time resumes uploads.
+	settings.Schedule = modules.RenterSchedule{WindowStart: hour, WindowEnd: (hour + 1) % 24}
+	if err := rt.renter.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if rt.renter.managedUploadsPaused(&ss) {
+		t.Fatal("uploads paused inside of the upload window")
+	}
+}
@@ -0,0 +1,194 @@
+package renter
+
+import (
+	"io"
+	"os"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// chunkHashes returns the hash of each chunk of the file at path, for use in
+// detecting which chunks of a file have changed. A partial final chunk is
+// hashed without padding, so that growing the file changes its hash.
+func chunkHashes(path string, chunkSize uint64) ([]crypto.Hash, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hashes []crypto.Hash
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			hashes = append(hashes, crypto.HashBytes(buf[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// UpdateFile replaces the contents of an uploaded file with the file at
+// source, re-uploading only the chunks whose contents have changed. Chunks are
+// compared against the hashes recorded when the file was last uploaded or
+// updated; files uploaded before hashes were recorded are re-uploaded in full.
+// A file that is small enough is stored inline instead, and a file that was
+// stored inline is uploaded in full. The sectors holding the replaced pieces
+// are deleted from the hosts in the background.
+func (r *Renter) UpdateFile(siaPath, source string) (modules.RenterFileUpdate, error) {
+	if r.recovery {
+		return modules.RenterFileUpdate{}, ErrRecoveryUpload
+	}
+	if err := validateSource(source); err != nil {
+		return modules.RenterFileUpdate{}, err
+	}
+	id := r.mu.RLock()
+	f, exists := r.files[siaPath]
+	tf := r.tracking[siaPath]
+	r.mu.RUnlock(id)
+	if !exists {
+		return modules.RenterFileUpdate{}, ErrUnknownPath
+	}
+
+	fi, err := os.Stat(source)
+	if err != nil {
+		return modules.RenterFileUpdate{}, err
+	}
+	if uint64(fi.Size()) <= r.inlineThreshold {
+		data, inline, err := readInlineSource(source, r.inlineThreshold)
+		if err != nil {
+			return modules.RenterFileUpdate{}, err
+		}
+		if inline {
+			return r.managedUpdateInline(f, siaPath, data, fi.Mode())
+		}
+	}
+	hashes, err := chunkHashes(source, f.chunkSize())
+	if err != nil {
+		return modules.RenterFileUpdate{}, err
+	}
+
+	// Drop the pieces of every chunk that has changed or no longer exists,
+	// which leaves the repair loop to upload the new contents.
+	f.mu.Lock()
+	f.size = uint64(fi.Size())
+	f.mode = uint32(fi.Mode())
+	f.inline = nil
+	numChunks := f.numChunks()
+	changed := make([]bool, numChunks)
+	update := modules.RenterFileUpdate{Chunks: numChunks}
+	for i := range changed {
+		if i >= len(hashes) || i >= len(tf.ChunkHashes) || hashes[i] != tf.ChunkHashes[i] {
+			changed[i] = true
+			update.ChangedChunks++
+		}
+	}
+	removed := make(map[types.FileContractID][]crypto.Hash)
+	for fcid, fc := range f.contracts {
+		var pieces []pieceData
+		for _, p := range fc.Pieces {
+			if p.Chunk < numChunks && !changed[p.Chunk] {
+				pieces = append(pieces, p)
+			} else {
+				removed[fcid] = append(removed[fcid], p.MerkleRoot)
+			}
+		}
+		fc.Pieces = pieces
+		f.contracts[fcid] = fc
+	}
+	f.mu.Unlock()
+
+	r.cache.managedEvict(f.masterKey)
+	id = r.mu.Lock()
+	r.updatedFiles[f.masterKey] = struct{}{}
+	r.tracking[siaPath] = trackedFile{
+		RepairPath:  source,
+		ChunkHashes: hashes,
+	}
+	err = r.saveSync()
+	if err == nil {
+		f.mu.RLock()
+		err = r.saveFile(f)
+		f.mu.RUnlock()
+	}
+	r.mu.Unlock(id)
+	if err != nil {
+		return modules.RenterFileUpdate{}, err
+	}
+
+	go r.threadedDeleteSectors(removed)
+
+	// Send the file to the repair loop to upload the changed chunks.
+	select {
+	case r.newRepairs <- f:
+	case <-r.tg.StopChan():
+	}
+	return update, nil
+}
+
+// threadedDeleteSectors deletes sectors that are no longer part of any file
+// from the contracts that store them. Sectors that cannot be deleted, e.g.
+// because their host is offline, are left on the host until the contract
+// expires.
+func (r *Renter) threadedDeleteSectors(sectors map[types.FileContractID][]crypto.Hash) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for fcid, roots := range sectors {
+		e, err := r.hostContractor.Editor(fcid, r.tg.StopChan())
+		if err != nil {
+			r.log.Debugln("could not delete", len(roots), "sectors from contract", fcid, ":", err)
+			continue
+		}
+		for i, root := range roots {
+			if err := e.Delete(root); err != nil {
+				r.log.Debugln("could not delete", len(roots)-i, "sectors from", e.Address(), ":", err)
+				break
+			}
+		}
+		e.Close()
+	}
+}
+
+// managedUpdateInline replaces the contents of a file with data, which is
+// stored inline. Any pieces of the file that are stored on hosts are dropped
+// and deleted from the hosts, and the file is no longer repaired.
+func (r *Renter) managedUpdateInline(f *file, siaPath string, data []byte, mode os.FileMode) (modules.RenterFileUpdate, error) {
+	f.mu.Lock()
+	removed := make(map[types.FileContractID][]crypto.Hash)
+	for fcid, fc := range f.contracts {
+		for _, p := range fc.Pieces {
+			removed[fcid] = append(removed[fcid], p.MerkleRoot)
+		}
+	}
+	f.setInline(data)
+	f.mode = uint32(mode)
+	update := modules.RenterFileUpdate{Chunks: f.numChunks()}
+	f.mu.Unlock()
+
+	r.cache.managedEvict(f.masterKey)
+	id := r.mu.Lock()
+	delete(r.tracking, siaPath)
+	err := r.saveSync()
+	if err == nil {
+		f.mu.RLock()
+		err = r.saveFile(f)
+		f.mu.RUnlock()
+	}
+	r.mu.Unlock(id)
+	if err != nil {
+		return modules.RenterFileUpdate{}, err
+	}
+
+	go r.threadedDeleteSectors(removed)
+	return update, nil
+}
@@ -0,0 +1,185 @@
+package renter
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestChunkHashes checks that chunkHashes hashes each chunk of a file,
+// including a partial final chunk.
+func TestChunkHashes(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "file")
+	data := bytes.Repeat([]byte{1, 2, 3}, 10)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	hashes, err := chunkHashes(path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 4 {
+		t.Fatal("expected 4 chunk hashes, got", len(hashes))
+	}
+	if hashes[0] != crypto.HashBytes(data[:8]) || hashes[3] != crypto.HashBytes(data[24:]) {
+		t.Error("chunk hashes do not match the chunks of the file")
+	}
+}
+
+// TestUpdateFile checks that updating a file only drops the pieces of the
+// chunks that changed.
+func TestUpdateFile(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	// Create a file of three chunks, each stored on one host.
+	source := filepath.Join(rt.renter.persistDir, "source")
+	data := bytes.Repeat([]byte{'a'}, 300)
+	if err := ioutil.WriteFile(source, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	rsc, _ := NewRSCode(1, 1)
+	f := newFile("foo", rsc, 100, uint64(len(data)))
+	fcid := types.FileContractID{1}
+	f.contracts[fcid] = fileContract{
+		ID:     fcid,
+		Pieces: []pieceData{{Chunk: 0}, {Chunk: 1}, {Chunk: 2}},
+	}
+	hashes, err := chunkHashes(source, f.chunkSize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := rt.renter.mu.Lock()
+	rt.renter.files["foo"] = f
+	rt.renter.tracking["foo"] = trackedFile{RepairPath: source, ChunkHashes: hashes}
+	rt.renter.mu.Unlock(id)
+
+	// Change the middle chunk and append a fourth.
+	data[150] = 'b'
+	data = append(data, 'c')
+	if err := ioutil.WriteFile(source, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	update, err := rt.renter.UpdateFile("foo", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.Chunks != 4 || update.ChangedChunks != 2 {
+		t.Fatalf("expected 2 of 4 chunks to change, got %v of %v", update.ChangedChunks, update.Chunks)
+	}
+	f.mu.RLock()
+	pieces := f.contracts[fcid].Pieces
+	size := f.size
+	f.mu.RUnlock()
+	if len(pieces) != 2 || pieces[0].Chunk != 0 || pieces[1].Chunk != 2 {
+		t.Error("wrong pieces kept after update:", pieces)
+	}
+	if size != uint64(len(data)) {
+		t.Error("file size was not updated:", size)
+	}
+
+	// Updating again without changes should not change any chunks.
+	update, err = rt.renter.UpdateFile("foo", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.ChangedChunks != 0 {
+		t.Error("expected no chunks to change, got", update.ChangedChunks)
+	}
+
+	if _, err := rt.renter.UpdateFile("bar", source); err != ErrUnknownPath {
+		t.Error("expected ErrUnknownPath, got", err)
+	}
+}
+
+// TestUpdateFileDeleteSectors checks that updating a file deletes the sectors
+// of the replaced pieces from the hosts.
+func TestUpdateFileDeleteSectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	mc := &memContractor{
+		contracts: make(map[types.FileContractID]modules.RenterContract),
+		sectors:   make(map[crypto.Hash][]byte),
+	}
+	contract := modules.RenterContract{ID: types.FileContractID{1}}
+	mc.contracts[contract.ID] = contract
+	e := &memEditor{id: contract.ID, mc: mc}
+	var roots []crypto.Hash
+	for i := 0; i < 2; i++ {
+		root, _ := e.Upload(fastrand.Bytes(int(modules.SectorSize)))
+		roots = append(roots, root)
+	}
+	rt, err := newContractorTester(t.Name(), nil, mc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	// Create a file of two chunks, and change the second one.
+	source := filepath.Join(rt.renter.persistDir, "source")
+	data := bytes.Repeat([]byte{'a'}, 200)
+	if err := ioutil.WriteFile(source, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	rsc, _ := NewRSCode(1, 1)
+	f := newFile("foo", rsc, 100, uint64(len(data)))
+	f.contracts[contract.ID] = fileContract{
+		ID:     contract.ID,
+		Pieces: []pieceData{{Chunk: 0, MerkleRoot: roots[0]}, {Chunk: 1, MerkleRoot: roots[1]}},
+	}
+	hashes, err := chunkHashes(source, f.chunkSize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := rt.renter.mu.Lock()
+	rt.renter.files["foo"] = f
+	rt.renter.tracking["foo"] = trackedFile{RepairPath: source, ChunkHashes: hashes}
+	rt.renter.mu.Unlock(id)
+	data[150] = 'b'
+	if err := ioutil.WriteFile(source, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.renter.UpdateFile("foo", source); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the sector of the second chunk should be deleted.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		mc.mu.Lock()
+		defer mc.mu.Unlock()
+		if _, exists := mc.sectors[roots[1]]; exists {
+			return errors.New("sector of the replaced piece was not deleted")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc.mu.Lock()
+	_, exists := mc.sectors[roots[0]]
+	mc.mu.Unlock()
+	if !exists {
+		t.Fatal("sector of an unchanged piece was deleted")
+	}
+}
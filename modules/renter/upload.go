@@ -86,6 +86,10 @@ func validateSource(sourcePath string) error {
 // Upload instructs the renter to start tracking a file. The renter will
 // automatically upload and repair tracked files using a background loop.
 func (r *Renter) Upload(up modules.FileUploadParams) error {
+	if r.recovery {
+		return ErrRecoveryUpload
+	}
+
 	// Enforce nickname rules.
 	if err := validateSiapath(up.SiaPath); err != nil {
 		return err
@@ -113,6 +117,25 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 		up.ErasureCode, _ = NewRSCode(defaultDataPieces, defaultParityPieces)
 	}
 
+	// Small files are stored inline, and need neither contracts nor the
+	// repair loop.
+	if uint64(fileInfo.Size()) <= r.inlineThreshold {
+		data, inline, err := readInlineSource(up.Source, r.inlineThreshold)
+		if err != nil {
+			return err
+		}
+		if inline {
+			f := newFile(up.SiaPath, up.ErasureCode, pieceSize, 0)
+			f.mode = uint32(fileInfo.Mode())
+			f.setInline(data)
+			lockID = r.mu.Lock()
+			r.files[up.SiaPath] = f
+			err = r.saveFile(f)
+			r.mu.Unlock(lockID)
+			return err
+		}
+	}
+
 	// Check that we have contracts to upload to. We need at least (data +
 	// parity/2) contracts; since NumPieces = data + parity, we arrive at the
 	// expression below.
@@ -124,11 +147,19 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 	f := newFile(up.SiaPath, up.ErasureCode, pieceSize, uint64(fileInfo.Size()))
 	f.mode = uint32(fileInfo.Mode())
 
+	// Record the chunk hashes so that later updates of the file only
+	// re-upload the chunks that changed.
+	hashes, err := chunkHashes(up.Source, f.chunkSize())
+	if err != nil {
+		return err
+	}
+
 	// Add file to renter.
 	lockID = r.mu.Lock()
 	r.files[up.SiaPath] = f
 	r.tracking[up.SiaPath] = trackedFile{
-		RepairPath: up.Source,
+		RepairPath:  up.Source,
+		ChunkHashes: hashes,
 	}
 	r.saveSync()
 	err = r.saveFile(f)
@@ -3,9 +3,13 @@ package renter
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/contractor"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 // TestRenterSiapathValidate verifies that the validateSiapath function correctly validates SiaPaths.
@@ -72,3 +76,48 @@ func TestRenterUploadInode(t *testing.T) {
 		t.Fatal("expected errUploadDirectory, got", err)
 	}
 }
+
+// TestRenterRecoveryUpload checks that a renter in recovery mode refuses to
+// upload files or set an allowance.
+func TestRenterRecoveryUpload(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	// Replace the renter with one in recovery mode.
+	if err := rt.renter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRecovery(rt.gateway, rt.cs, rt.wallet, rt.tpool, filepath.Join(build.TempDir("renter", t.Name()), modules.RenterDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	source, err := ioutil.TempFile("", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(source.Name())
+	source.Close()
+	err = r.Upload(modules.FileUploadParams{Source: source.Name(), SiaPath: "foo"})
+	if err != ErrRecoveryUpload {
+		t.Fatal("expected recovery upload error, got", err)
+	}
+
+	allowance := modules.Allowance{
+		Funds:       types.SiacoinPrecision,
+		Hosts:       1,
+		Period:      10,
+		RenewWindow: 5,
+	}
+	err = r.SetSettings(modules.RenterSettings{Allowance: allowance})
+	if err != contractor.ErrRecoveryMode {
+		t.Fatal("expected recovery mode error, got", err)
+	}
+}
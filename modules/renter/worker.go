@@ -7,6 +7,7 @@ package renter
 import (
 	"time"
 
+	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
@@ -47,6 +48,26 @@ type (
 		workerID   types.FileContractID
 	}
 
+	// finishedMetadata contains the error from writing a metadata sector, and
+	// the stale metadata sectors that could not be deleted.
+	finishedMetadata struct {
+		stale []crypto.Hash
+		err   error
+	}
+
+	// metadataWork contains instructions to write the metadata sector of a
+	// contract and delete its stale metadata sectors, and a channel for
+	// returning the result.
+	metadataWork struct {
+		contract modules.RenterContract
+		sector   []byte
+		stale    []crypto.Hash
+
+		// resultChan is a buffered channel that the worker will use to
+		// return the result of the write.
+		resultChan chan finishedMetadata
+	}
+
 	// uploadWork contains instructions to upload a piece to a host, and a
 	// channel for returning the results.
 	uploadWork struct {
@@ -68,14 +89,16 @@ type (
 		contract   modules.RenterContract
 		contractID types.FileContractID
 
-		// If there is work on all three channels, the worker will first do all
+		// If there is work on all four channels, the worker will first do all
 		// of the work in the priority download chan, then all of the work in the
-		// download chan, and finally all of the work in the upload chan.
+		// download chan, and then the work in the upload and metadata chans.
 		//
 		// A busy higher priority channel is able to entirely starve all of the
-		// channels with lower priority.
+		// channels with lower priority. Metadata writes go through the worker
+		// so that they do not compete with downloads for the contract.
 		downloadChan         chan downloadWork // higher priority than all uploads
 		killChan             chan struct{}     // highest priority
+		metadataChan         chan metadataWork // lowest priority
 		priorityDownloadChan chan downloadWork // higher priority than downloads (used for user-initiated downloads)
 		uploadChan           chan uploadWork   // lowest priority
 
@@ -179,6 +202,12 @@ func (w *worker) upload(uw uploadWork) {
 	}()
 }
 
+// writeMetadata will write the metadata sector of the worker's contract.
+func (w *worker) writeMetadata(mw metadataWork) {
+	stale, err := w.renter.managedWriteMetadataSector(mw.contract, mw.stale, mw.sector)
+	mw.resultChan <- finishedMetadata{stale, err}
+}
+
 // work will perform one unit of work, exiting early if there is a kill signal
 // given before work is completed.
 func (w *worker) work() {
@@ -213,6 +242,9 @@ func (w *worker) work() {
 	case u := <-w.uploadChan:
 		w.upload(u)
 		return
+	case mw := <-w.metadataChan:
+		w.writeMetadata(mw)
+		return
 	case <-w.renter.tg.StopChan():
 		return
 	}
@@ -221,6 +253,7 @@ func (w *worker) work() {
 // threadedWorkLoop repeatedly issues work to a worker, stopping when the
 // thread group is closed.
 func (w *worker) threadedWorkLoop() {
+	defer build.CaptureCrash("renter")
 	for {
 		// Check if the worker has been killed individually.
 		select {
@@ -257,6 +290,7 @@ func (r *Renter) updateWorkerPool(contracts []modules.RenterContract) {
 
 				downloadChan:         make(chan downloadWork, 1),
 				killChan:             make(chan struct{}),
+				metadataChan:         make(chan metadataWork),
 				priorityDownloadChan: make(chan downloadWork, 1),
 				uploadChan:           make(chan uploadWork, 1),
 
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -189,6 +190,35 @@ func BenchmarkMerkleRootSetSave(b *testing.B) {
 	}
 }
 
+// TestRenterScheduleInWindow checks that the upload window of a renter
+// schedule is applied correctly, including windows that wrap around midnight.
+func TestRenterScheduleInWindow(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2017, 10, 16, hour, 30, 0, 0, time.Local)
+	}
+	tests := []struct {
+		schedule RenterSchedule
+		hour     int
+		in       bool
+	}{
+		{RenterSchedule{}, 12, true},
+		{RenterSchedule{WindowStart: 5, WindowEnd: 5}, 12, true},
+		{RenterSchedule{WindowStart: 1, WindowEnd: 6}, 0, false},
+		{RenterSchedule{WindowStart: 1, WindowEnd: 6}, 1, true},
+		{RenterSchedule{WindowStart: 1, WindowEnd: 6}, 5, true},
+		{RenterSchedule{WindowStart: 1, WindowEnd: 6}, 6, false},
+		{RenterSchedule{WindowStart: 22, WindowEnd: 6}, 23, true},
+		{RenterSchedule{WindowStart: 22, WindowEnd: 6}, 3, true},
+		{RenterSchedule{WindowStart: 22, WindowEnd: 6}, 6, false},
+		{RenterSchedule{WindowStart: 22, WindowEnd: 6}, 18, false},
+	}
+	for i, test := range tests {
+		if in := test.schedule.InWindow(at(test.hour)); in != test.in {
+			t.Errorf("test %v: expected InWindow(%v) to be %v, got %v", i, test.hour, test.in, in)
+		}
+	}
+}
+
 // BenchmarkSliceCryptoHashSave clocks how fast large []crypto.Hashes can be
 // encoded and written to disk.
 func BenchmarkSliceCryptoHashSave(b *testing.B) {
@@ -0,0 +1,219 @@
+package modules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// SpecifierReserves is signed along with every output of a proof of
+	// reserves, so that the signatures cannot be mistaken for transaction
+	// signatures.
+	SpecifierReserves = types.Specifier{'p', 'r', 'o', 'o', 'f', ' ', 'r', 'e', 's', 'e', 'r', 'v', 'e', 's'}
+
+	errReservesBlockchain = errors.New("proof of reserves was not made on the current blockchain")
+	errReservesTotal      = errors.New("proof of reserves total does not match its outputs")
+)
+
+type (
+	// A ReservesProof proves that its signer controlled a set of siacoin
+	// outputs, worth Total, at the block Height. Each output carries the
+	// Merkle inclusion data of the block that created it, and is signed with
+	// the keys that can spend it.
+	ReservesProof struct {
+		Height  types.BlockHeight `json:"height"`
+		BlockID types.BlockID     `json:"blockid"`
+
+		// Challenge is an arbitrary message chosen by the verifier, such as
+		// a nonce or the name of the auditor. It is signed along with the
+		// outputs so that proofs cannot be replayed.
+		Challenge string `json:"challenge"`
+
+		Total   types.Currency  `json:"total"`
+		Outputs []ReserveOutput `json:"outputs"`
+	}
+
+	// A ReserveOutput is a siacoin output in a proof of reserves.
+	ReserveOutput struct {
+		ID     types.SiacoinOutputID `json:"id"`
+		Output types.SiacoinOutput   `json:"output"`
+
+		// UnlockConditions hash to the address of the output, and
+		// Signatures sign the output's SigHash with enough of their public
+		// keys to spend it.
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+		Signatures       []ReserveSignature     `json:"signatures"`
+
+		// BlockHeight and Header identify the block that created the output.
+		// Leaf is the encoded miner payout or transaction that created it,
+		// which MerkleProof proves to be leaf LeafIndex of the NumLeaves
+		// leaves of the header's Merkle root. OutputIndex is the index of
+		// the output within the transaction, and is unused for miner
+		// payouts.
+		BlockHeight types.BlockHeight `json:"blockheight"`
+		Header      types.BlockHeader `json:"header"`
+		MinerPayout bool              `json:"minerpayout"`
+		Leaf        []byte            `json:"leaf"`
+		LeafIndex   uint64            `json:"leafindex"`
+		NumLeaves   uint64            `json:"numleaves"`
+		MerkleProof []crypto.Hash     `json:"merkleproof"`
+		OutputIndex uint64            `json:"outputindex"`
+	}
+
+	// A ReserveSignature is a signature of a ReserveOutput by the public key
+	// at PublicKeyIndex of its unlock conditions.
+	ReserveSignature struct {
+		PublicKeyIndex uint64 `json:"publickeyindex"`
+		Signature      []byte `json:"signature"`
+	}
+)
+
+// SigHash returns the hash that the keys of the output sign in the proof p.
+func (ro ReserveOutput) SigHash(p ReservesProof) crypto.Hash {
+	return crypto.HashAll(SpecifierReserves, p.Height, p.BlockID, p.Challenge, ro.ID)
+}
+
+// verifyInclusion checks that the output was created by the leaf of its
+// header's Merkle tree.
+func (ro ReserveOutput) verifyInclusion() error {
+	if !crypto.VerifySegment(ro.Leaf, ro.MerkleProof, ro.NumLeaves, ro.LeafIndex, ro.Header.MerkleRoot) {
+		return errors.New("invalid Merkle proof")
+	}
+	var id types.SiacoinOutputID
+	var sco types.SiacoinOutput
+	var leaf []byte
+	if ro.MinerPayout {
+		if err := encoding.Unmarshal(ro.Leaf, &sco); err != nil {
+			return err
+		}
+		id = types.SiacoinOutputID(crypto.HashAll(ro.Header.ID(), ro.LeafIndex))
+		leaf = encoding.Marshal(sco)
+	} else {
+		var txn types.Transaction
+		if err := encoding.Unmarshal(ro.Leaf, &txn); err != nil {
+			return err
+		}
+		if ro.OutputIndex >= uint64(len(txn.SiacoinOutputs)) {
+			return errors.New("output index is out of range")
+		}
+		id = txn.SiacoinOutputID(ro.OutputIndex)
+		sco = txn.SiacoinOutputs[ro.OutputIndex]
+		leaf = encoding.Marshal(txn)
+	}
+	// Reject leaves with trailing data.
+	if !bytes.Equal(leaf, ro.Leaf) {
+		return errors.New("leaf is not a valid encoding")
+	}
+	if id != ro.ID || sco.UnlockHash != ro.Output.UnlockHash || sco.Value.Cmp(ro.Output.Value) != 0 {
+		return errors.New("leaf does not create the output")
+	}
+	return nil
+}
+
+// verifySignatures checks that the output is signed by enough of the keys of
+// its unlock conditions.
+func (ro ReserveOutput) verifySignatures(sigHash crypto.Hash) error {
+	if ro.UnlockConditions.UnlockHash() != ro.Output.UnlockHash {
+		return errors.New("unlock conditions do not match the output address")
+	}
+	signed := make(map[uint64]struct{})
+	for _, sig := range ro.Signatures {
+		if sig.PublicKeyIndex >= uint64(len(ro.UnlockConditions.PublicKeys)) {
+			return errors.New("signature public key index is out of range")
+		}
+		if _, exists := signed[sig.PublicKeyIndex]; exists {
+			return errors.New("public key signed the output twice")
+		}
+		spk := ro.UnlockConditions.PublicKeys[sig.PublicKeyIndex]
+		if spk.Algorithm != types.SignatureEd25519 {
+			return errors.New("only ed25519 signatures are supported")
+		}
+		var pk crypto.PublicKey
+		var cs crypto.Signature
+		if len(spk.Key) != len(pk) || len(sig.Signature) != len(cs) {
+			return errors.New("invalid public key or signature length")
+		}
+		copy(pk[:], spk.Key)
+		copy(cs[:], sig.Signature)
+		if err := crypto.VerifyHash(sigHash, pk, cs); err != nil {
+			return err
+		}
+		signed[sig.PublicKeyIndex] = struct{}{}
+	}
+	if uint64(len(signed)) < ro.UnlockConditions.SignaturesRequired {
+		return errors.New("output is missing signatures")
+	}
+	return nil
+}
+
+// VerifyReservesProof checks a proof of reserves against the blockchain of
+// the consensus set, returning the siacoins that the proof shows to be
+// controlled by its signer at the proof's height. Every output must have been
+// created by a block of the blockchain, be signed by its keys, and still be
+// unspent at the proof's height. Checking the latter requires scanning the
+// blocks since the oldest output was created.
+func VerifyReservesProof(cs ConsensusSet, p ReservesProof) (types.Currency, error) {
+	if p.Height > cs.Height() {
+		return types.Currency{}, errReservesBlockchain
+	}
+	if b, exists := cs.BlockAtHeight(p.Height); !exists || b.ID() != p.BlockID {
+		return types.Currency{}, errReservesBlockchain
+	}
+
+	total := types.ZeroCurrency
+	outputs := make(map[types.SiacoinOutputID]struct{})
+	oldest := p.Height
+	for _, ro := range p.Outputs {
+		if _, exists := outputs[ro.ID]; exists {
+			return types.Currency{}, fmt.Errorf("output %v appears twice", ro.ID)
+		}
+		outputs[ro.ID] = struct{}{}
+		if ro.BlockHeight > p.Height {
+			return types.Currency{}, fmt.Errorf("output %v was created after the proof's height", ro.ID)
+		}
+		if ro.MinerPayout && ro.BlockHeight+types.MaturityDelay > p.Height {
+			return types.Currency{}, fmt.Errorf("output %v is an immature miner payout", ro.ID)
+		}
+		b, exists := cs.BlockAtHeight(ro.BlockHeight)
+		if !exists || b.ID() != ro.Header.ID() {
+			return types.Currency{}, fmt.Errorf("output %v: block is not in the current blockchain", ro.ID)
+		}
+		if ro.MinerPayout != (ro.LeafIndex < uint64(len(b.MinerPayouts))) {
+			return types.Currency{}, fmt.Errorf("output %v: leaf is of the wrong type", ro.ID)
+		}
+		if err := ro.verifyInclusion(); err != nil {
+			return types.Currency{}, fmt.Errorf("output %v: %v", ro.ID, err)
+		}
+		if err := ro.verifySignatures(ro.SigHash(p)); err != nil {
+			return types.Currency{}, fmt.Errorf("output %v: %v", ro.ID, err)
+		}
+		if ro.BlockHeight < oldest {
+			oldest = ro.BlockHeight
+		}
+		total = total.Add(ro.Output.Value)
+	}
+	if total.Cmp(p.Total) != 0 {
+		return types.Currency{}, errReservesTotal
+	}
+
+	// Check that none of the outputs were spent by the proof's height.
+	for height := oldest; height <= p.Height && len(outputs) > 0; height++ {
+		b, exists := cs.BlockAtHeight(height)
+		if !exists {
+			return types.Currency{}, errReservesBlockchain
+		}
+		for _, txn := range b.Transactions {
+			for _, sci := range txn.SiacoinInputs {
+				if _, exists := outputs[sci.ParentID]; exists {
+					return types.Currency{}, fmt.Errorf("output %v was spent at height %v", sci.ParentID, height)
+				}
+			}
+		}
+	}
+	return total, nil
+}
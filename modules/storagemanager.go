@@ -14,6 +14,20 @@ const (
 	StorageManagerDir = "storagemanager"
 )
 
+const (
+	// StorageFolderStatusInitializing indicates that the files of a storage
+	// folder are still being allocated.
+	StorageFolderStatusInitializing = "initializing"
+
+	// StorageFolderStatusReady indicates that a storage folder is available to
+	// store sectors.
+	StorageFolderStatusReady = "ready"
+
+	// StorageFolderStatusUnavailable indicates that the files of a storage
+	// folder could not be found on disk.
+	StorageFolderStatusUnavailable = "unavailable"
+)
+
 type (
 	// StorageFolderMetadata contains metadata about a storage folder that is
 	// tracked by the storage folder manager.
@@ -23,6 +37,11 @@ type (
 		Index             uint16 `json:"index"`
 		Path              string `json:"path"`
 
+		// Status is the status of the storage folder. Storage folders that
+		// have just been added are initialized in the background, and only
+		// start receiving sectors once their status is ready.
+		Status string `json:"status"`
+
 		// Below are statistics about the filesystem. FailedReads and
 		// FailedWrites are only incremented if the filesystem is returning
 		// errors when operations are being performed. A large number of
@@ -42,6 +61,25 @@ type (
 		ProgressDenominator uint64
 	}
 
+	// A SectorCheck compares the sectors held by a storage manager with the
+	// sectors that it is expected to hold.
+	SectorCheck struct {
+		// Missing lists the expected sectors that are not held, or that are
+		// held in a storage folder that is unavailable.
+		Missing []crypto.Hash
+
+		// Undercounted lists the expected sectors that are held as fewer
+		// virtual sectors than expected. Removing one of their virtual
+		// sectors may delete data that is still needed.
+		Undercounted []crypto.Hash
+
+		// OrphanedSectors is the number of physical sectors that are held but
+		// not expected, and ExcessVirtualSectors is the number of virtual
+		// sectors held beyond the number expected for expected sectors.
+		OrphanedSectors      uint64
+		ExcessVirtualSectors uint64
+	}
+
 	// A StorageManager is responsible for managing storage folders and
 	// sectors. Sectors are the base unit of storage that gets moved between
 	// renters and hosts, and primarily is stored on the hosts.
@@ -69,6 +107,12 @@ type (
 		// gracefully handle running out of storage unexpectedly.
 		AddStorageFolder(path string, size uint64) error
 
+		// CheckSectors compares the sectors held by the manager with
+		// 'expected', which maps the root of each sector that the manager
+		// should hold to the number of virtual sectors it should hold for
+		// that root.
+		CheckSectors(expected map[crypto.Hash]uint16) (SectorCheck, error)
+
 		// The storage manager needs to be able to shut down.
 		Close() error
 
@@ -84,6 +128,14 @@ type (
 		// bytes that match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
 
+		// ReconcileSectors makes the sectors held by the manager match
+		// 'expected', as given to CheckSectors. Sectors that are not expected
+		// are deleted, and the number of virtual sectors held for each
+		// expected sector is corrected. Missing sectors cannot be restored.
+		// No sectors may be added or removed during the call. The number of
+		// physical sectors that were deleted is returned.
+		ReconcileSectors(expected map[crypto.Hash]uint16) (uint64, error)
+
 		// RemoveSector will remove a sector from the storage manager. The
 		// height at which the sector expires should be provided, so that the
 		// auto-expiry information for that sector can be properly updated.
@@ -21,6 +21,7 @@ var (
 	errFullTransactionPool = errors.New("transaction pool cannot accept more transactions")
 	errLowMinerFees        = errors.New("transaction set needs more miner fees to be accepted")
 	errEmptySet            = errors.New("transaction set is empty")
+	errLowReplacementFees  = errors.New("transaction set needs more miner fees to replace the transactions it double spends")
 )
 
 // relatedObjectIDs determines all of the object ids related to a transaction.
@@ -186,9 +187,14 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 		return errLowMinerFees
 	}
 
-	// Check that the transaction set is valid.
+	// Check that the transaction set is valid. If it is not, the set may be
+	// double spending transactions in the pool, which it is allowed to
+	// replace if it pays higher fees.
 	cc, err := txnFn(superset)
 	if err != nil {
+		if replaced, rerr := tp.replaceConflicts(dedupSet, supersetMap, txnFn); replaced {
+			return rerr
+		}
 		return modules.NewConsensusConflict("provided transaction set has prereqs, but is still invalid: " + err.Error())
 	}
 
@@ -228,6 +234,134 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []T
 	return nil
 }
 
+// spentObjectIDs returns the ids of all objects spent by a transaction set.
+func spentObjectIDs(ts []types.Transaction) map[ObjectID]struct{} {
+	spent := make(map[ObjectID]struct{})
+	for _, t := range ts {
+		for _, sci := range t.SiacoinInputs {
+			spent[ObjectID(sci.ParentID)] = struct{}{}
+		}
+		for _, fcr := range t.FileContractRevisions {
+			spent[ObjectID(fcr.ParentID)] = struct{}{}
+		}
+		for _, sp := range t.StorageProofs {
+			spent[ObjectID(sp.ParentID)] = struct{}{}
+		}
+		for _, sfi := range t.SiafundInputs {
+			spent[ObjectID(sfi.ParentID)] = struct{}{}
+		}
+	}
+	return spent
+}
+
+// replaceConflicts replaces the transactions of the conflicting sets that
+// double spend the objects spent by ts, along with all transactions that
+// depend on them. The replacement is only allowed if ts pays more fees per byte
+// than the transactions it replaces, and if its total fees exceed theirs by at
+// least the minimum relay fee for the size of ts. The first return
+// value indicates whether ts double spends any transactions in the pool; if it
+// does not, nothing is replaced and the caller should report the original
+// conflict.
+func (tp *TransactionPool) replaceConflicts(ts []types.Transaction, conflicts map[TransactionSetID]struct{}, txnFn func([]types.Transaction) (modules.ConsensusChange, error)) (bool, error) {
+	// Split the transactions of the conflicting sets into the ones that are
+	// replaced and the ones that are kept. Each set is in dependency order,
+	// so the dependents of a replaced transaction are found after it.
+	spent := spentObjectIDs(ts)
+	replacedOutputs := make(map[ObjectID]struct{})
+	var replaced, kept []types.Transaction
+	for conflict := range conflicts {
+		for _, t := range tp.transactionSets[conflict] {
+			isReplaced := false
+			for oid := range spentObjectIDs([]types.Transaction{t}) {
+				_, doubleSpent := spent[oid]
+				_, dependent := replacedOutputs[oid]
+				if doubleSpent || dependent {
+					isReplaced = true
+					break
+				}
+			}
+			if !isReplaced {
+				kept = append(kept, t)
+				continue
+			}
+			replaced = append(replaced, t)
+			for _, oid := range relatedObjectIDs([]types.Transaction{t}) {
+				replacedOutputs[oid] = struct{}{}
+			}
+		}
+	}
+	if len(replaced) == 0 {
+		return false, nil
+	}
+
+	// The replacement must pay more fees than the replaced transactions, so
+	// that miners prefer it, and must pay for its own relay on top of their
+	// fees, so that it cannot be used to flood the network with cheap
+	// replacements.
+	var newFees, oldFees types.Currency
+	for _, t := range ts {
+		for _, fee := range t.MinerFees {
+			newFees = newFees.Add(fee)
+		}
+	}
+	for _, t := range replaced {
+		for _, fee := range t.MinerFees {
+			oldFees = oldFees.Add(fee)
+		}
+	}
+	minFees := oldFees.Add(minReplacementFee.Mul64(uint64(len(encoding.Marshal(ts)))))
+	if newFees.Cmp(minFees) < 0 || modules.CalculateFee(ts).Cmp(modules.CalculateFee(replaced)) <= 0 {
+		return true, errLowReplacementFees
+	}
+
+	newSet := append(kept, ts...)
+	if _, err := tp.checkTransactionSetComposition(newSet); err != nil {
+		return true, err
+	}
+	cc, err := txnFn(newSet)
+	if err != nil {
+		return true, modules.NewConsensusConflict("provided transaction set double spends transactions in the pool, but is still invalid: " + err.Error())
+	}
+
+	// Remove the conflicts from the transaction pool, including the objects
+	// that are no longer known.
+	for conflict := range conflicts {
+		tp.transactionListSize -= len(encoding.Marshal(tp.transactionSets[conflict]))
+		delete(tp.transactionSets, conflict)
+		delete(tp.transactionSetDiffs, conflict)
+	}
+	for oid, setID := range tp.knownObjects {
+		if _, exists := conflicts[setID]; exists {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	txids := make(map[types.TransactionID]struct{})
+	for _, txn := range ts {
+		txids[txn.ID()] = struct{}{}
+	}
+	for _, txn := range replaced {
+		if _, exists := txids[txn.ID()]; !exists {
+			delete(tp.transactionHeights, txn.ID())
+		}
+	}
+
+	// Add the new set to the pool.
+	setID := TransactionSetID(crypto.HashObject(newSet))
+	tp.transactionSets[setID] = newSet
+	for _, oid := range relatedObjectIDs(newSet) {
+		tp.knownObjects[oid] = setID
+	}
+	tp.transactionSetDiffs[setID] = &cc
+	tp.transactionListSize += len(encoding.Marshal(newSet))
+	for _, txn := range ts {
+		if _, exists := tp.transactionHeights[txn.ID()]; !exists {
+			tp.transactionHeights[txn.ID()] = tp.blockHeight
+		}
+	}
+	tp.log.Debugf("transaction set %v replaced %v transactions paying %v in fees\n", setID, len(replaced), oldFees)
+	return true, nil
+}
+
 // acceptTransactionSet verifies that a transaction set is allowed to be in the
 // transaction pool, and then adds it to the transaction pool.
 func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn func([]types.Transaction) (modules.ConsensusChange, error)) error {
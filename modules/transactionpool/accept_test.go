@@ -69,8 +69,9 @@ func TestConflictingTransactionSets(t *testing.T) {
 	}
 	defer tpt.Close()
 
-	// Fund a partial transaction.
-	fund := types.NewCurrency64(30e6)
+	// Fund a partial transaction. The fund is spent as a fee, which must be
+	// large enough to replace the other set.
+	fund := types.SiacoinPrecision
 	txnBuilder := tpt.wallet.StartTransaction()
 	err = txnBuilder.FundSiacoins(fund)
 	if err != nil {
@@ -102,15 +103,111 @@ func TestConflictingTransactionSets(t *testing.T) {
 		t.Error("transaction should not have passed inspection")
 	}
 
-	// Purge and try the sets in the reverse order.
+	// Purge and try the sets in the reverse order. The set paying the fee
+	// should replace the set that does not pay a fee.
 	tpt.tpool.PurgeTransactionPool()
 	err = tpt.tpool.AcceptTransactionSet(txnSetDoubleSpend)
 	if err != nil {
 		t.Error(err)
 	}
 	err = tpt.tpool.AcceptTransactionSet(txnSet)
-	if err == nil {
-		t.Error("transaction should not have passed inspection")
+	if err != nil {
+		t.Error(err)
+	}
+	if _, _, exists := tpt.tpool.Transaction(txnSetDoubleSpend[txnIndex].ID()); exists {
+		t.Error("replaced transaction is still in the pool")
+	}
+	if _, _, exists := tpt.tpool.Transaction(txnSet[txnIndex].ID()); !exists {
+		t.Error("replacement transaction is not in the pool")
+	}
+}
+
+// TestReplaceTransactionSet checks that a transaction set can replace the
+// transactions it double spends only if it pays higher fees, and that the
+// dependents of the replaced transactions are removed as well.
+func TestReplaceTransactionSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create a parent with an output, a child that spends the output without
+	// a fee, and a grandchild that depends on the child.
+	fund := types.SiacoinPrecision
+	txnBuilder := tpt.wallet.StartTransaction()
+	if err := txnBuilder.FundSiacoins(fund); err != nil {
+		t.Fatal(err)
+	}
+	parents, err := txnBuilder.Sign(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last transaction of the set spends the funded input and is signed
+	// without covering its outputs, so it can be used to create double spends.
+	base := parents[len(parents)-1]
+	child := base
+	child.SiacoinOutputs = []types.SiacoinOutput{{Value: fund, UnlockHash: types.UnlockConditions{}.UnlockHash()}}
+	grandchild := types.Transaction{
+		SiacoinInputs:  []types.SiacoinInput{{ParentID: child.SiacoinOutputID(0)}},
+		SiacoinOutputs: []types.SiacoinOutput{{Value: fund}},
+	}
+	set := append(append([]types.Transaction{}, parents[:len(parents)-1]...), child, grandchild)
+	if err := tpt.tpool.AcceptTransactionSet(set); err != nil {
+		t.Fatal(err)
+	}
+
+	// A double spend of the child that pays no fee should be rejected.
+	lowFee := base
+	lowFee.SiacoinOutputs = []types.SiacoinOutput{{Value: fund, UnlockHash: types.UnlockHash{1}}}
+	err = tpt.tpool.AcceptTransactionSet(append(parents[:len(parents)-1:len(parents)-1], lowFee))
+	if err != errLowReplacementFees {
+		t.Fatal("expected errLowReplacementFees, got", err)
+	}
+
+	// A double spend whose fee does not cover the minimum relay fee for its
+	// size should be rejected as well.
+	lowFee.MinerFees = []types.Currency{types.NewCurrency64(1)}
+	lowFee.SiacoinOutputs[0].Value = fund.Sub(lowFee.MinerFees[0])
+	err = tpt.tpool.AcceptTransactionSet(append(parents[:len(parents)-1:len(parents)-1], lowFee))
+	if err != errLowReplacementFees {
+		t.Fatal("expected errLowReplacementFees, got", err)
+	}
+
+	// A double spend that pays a fee should replace the child and the
+	// grandchild, but keep the parents.
+	highFee := base
+	highFee.MinerFees = []types.Currency{fund.Div64(2)}
+	highFee.SiacoinOutputs = []types.SiacoinOutput{{Value: fund.Div64(2), UnlockHash: types.UnlockHash{1}}}
+	if err := tpt.tpool.AcceptTransactionSet(append(parents[:len(parents)-1:len(parents)-1], highFee)); err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range []types.Transaction{child, grandchild} {
+		if _, _, exists := tpt.tpool.Transaction(txn.ID()); exists {
+			t.Error("replaced transaction is still in the pool")
+		}
+		if _, exists := tpt.tpool.transactionHeights[txn.ID()]; exists {
+			t.Error("height of replaced transaction is still tracked")
+		}
+	}
+	for _, txn := range append(parents[:len(parents)-1:len(parents)-1], highFee) {
+		if _, _, exists := tpt.tpool.Transaction(txn.ID()); !exists {
+			t.Error("transaction is missing from the pool")
+		}
+	}
+	if len(tpt.tpool.TransactionList()) != len(parents) {
+		t.Error("wrong number of transactions in the pool:", len(tpt.tpool.TransactionList()))
+	}
+
+	// The replacement should be minable.
+	if _, err := tpt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Error("transactions were not confirmed")
 	}
 }
 
@@ -69,6 +69,12 @@ var (
 	// minEstimation defines a sane minimum fee per byte for transactions.  This
 	// will typically be only suggested as a fee in the absense of congestion.
 	minEstimation = types.SiacoinPrecision.Div64(100).Div64(1e3)
+
+	// minReplacementFee is the fee per byte that a transaction set must pay on
+	// top of the fees of the transactions it double spends in order to replace
+	// them. It is the minimum relay fee, so that every replacement pays for
+	// being relayed.
+	minReplacementFee = minEstimation
 )
 
 // Variables related to propagating transactions through the network.
@@ -3,6 +3,7 @@ package modules
 import (
 	"bytes"
 	"errors"
+	"time"
 
 	"github.com/NebulousLabs/entropy-mnemonics"
 
@@ -14,6 +15,27 @@ const (
 	// WalletDir is the directory that contains the wallet persistence.
 	WalletDir = "wallet"
 
+	// NamedWalletsDir is the directory that contains the persistence of any
+	// additional named wallets, each in a subdirectory of the same name.
+	NamedWalletsDir = "wallets"
+
+	// ChangePolicyFresh sends the change of every transaction to a fresh
+	// address, which is better for privacy.
+	ChangePolicyFresh = "fresh"
+
+	// ChangePolicyFixed sends the change of every transaction back to a single
+	// fixed address, which simplifies accounting.
+	ChangePolicyFixed = "fixed"
+
+	// CoinSelectionLargest funds sends with the largest siacoin outputs
+	// first, which keeps transactions small.
+	CoinSelectionLargest = "largest"
+
+	// CoinSelectionOldest funds sends with the most deeply confirmed siacoin
+	// outputs first, so that a send is not invalidated by a shallow reorg
+	// that reverts a recently received output.
+	CoinSelectionOldest = "oldest"
+
 	// SeedChecksumSize is the number of bytes that are used to checksum
 	// addresses to prevent accidental spending.
 	SeedChecksumSize = 6
@@ -21,6 +43,41 @@ const (
 	// PublicKeysPerSeed define the number of public keys that get pregenerated
 	// for a seed at startup when searching for balances in the blockchain.
 	PublicKeysPerSeed = 2500
+
+	// SnapshotPeriod is the length of the period covered by each balance and
+	// revenue snapshot, in seconds. Periods start at midnight UTC.
+	SnapshotPeriod = types.Timestamp(86400)
+
+	// MaxMemoSize is the largest memo, in bytes, that can be attached to a
+	// send.
+	MaxMemoSize = 256
+
+	// WalletEventPayment is reported when a transaction paying the wallet
+	// enters the transaction pool. One event is reported for each output
+	// paying the wallet. Change outputs are not reported.
+	WalletEventPayment = "payment"
+
+	// WalletEventConfirmation is reported when a transaction relevant to the
+	// wallet is confirmed in a block.
+	WalletEventConfirmation = "confirmation"
+
+	// WalletEventSpent is reported when an output owned by the wallet is
+	// spent by a confirmed transaction.
+	WalletEventSpent = "spent"
+
+	// WalletEventReverted is reported when a confirmed transaction relevant
+	// to the wallet is removed from the blockchain by a reorg.
+	WalletEventReverted = "reverted"
+
+	// WalletEventRequestFulfilled is reported when the confirmed payments to
+	// the address of a payment request reach the requested amount.
+	WalletEventRequestFulfilled = "requestfulfilled"
+
+	// PaymentRequestPending, PaymentRequestFulfilled and
+	// PaymentRequestExpired are the statuses of a payment request.
+	PaymentRequestPending   = "pending"
+	PaymentRequestFulfilled = "fulfilled"
+	PaymentRequestExpired   = "expired"
 )
 
 var (
@@ -41,6 +98,13 @@ var (
 	// ErrLockedWallet is returned when an action cannot be performed due to
 	// the wallet being locked.
 	ErrLockedWallet = errors.New("wallet must be unlocked before it can be used")
+
+	// ErrUnencryptedWallet is returned when an action cannot be performed
+	// because the wallet has not been created yet.
+	ErrUnencryptedWallet = errors.New("wallet has not been encrypted yet")
+
+	// ErrMemoTooLarge is returned when a memo is larger than MaxMemoSize.
+	ErrMemoTooLarge = errors.New("memo is larger than the maximum memo size")
 )
 
 type (
@@ -100,6 +164,207 @@ type (
 		Outputs []ProcessedOutput `json:"outputs"`
 	}
 
+	// A PendingTransaction is an unconfirmed transaction that is relevant to
+	// the wallet, along with the time at which the wallet first saw it in the
+	// transaction pool.
+	PendingTransaction struct {
+		ProcessedTransaction
+		FirstSeen time.Time `json:"firstseen"`
+
+		// ConfirmationTarget is the number of blocks within which the
+		// transaction should be confirmed, if the wallet raises its fee when
+		// it misses the target. It is zero otherwise.
+		ConfirmationTarget types.BlockHeight `json:"confirmationtarget"`
+	}
+
+	// WalletSettings control the behavior of the wallet.
+	WalletSettings struct {
+		// ChangePolicy is either ChangePolicyFresh or ChangePolicyFixed.
+		ChangePolicy string `json:"changepolicy"`
+
+		// ChangeAddress is the address that receives all change when the
+		// change policy is ChangePolicyFixed. It must be owned by the
+		// wallet.
+		ChangeAddress types.UnlockHash `json:"changeaddress"`
+
+		// GapLimit is the number of consecutive unused addresses that a seed
+		// scan looks past the last address found in the blockchain. Raising
+		// it finds funds sent to addresses that were generated long after
+		// the last used one.
+		GapLimit uint64 `json:"gaplimit"`
+
+		// AutoClaimSiafunds causes the wallet to periodically send its
+		// siafunds to itself, which moves the siacoin claim balance of its
+		// siafund outputs into its spendable balance.
+		AutoClaimSiafunds bool `json:"autoclaimsiafunds"`
+
+		// DustThreshold is the smallest siacoin output that the wallet
+		// considers worth creating. Zero selects a threshold based on the
+		// current transaction fees.
+		DustThreshold types.Currency `json:"dustthreshold"`
+
+		// RejectDustOutputs causes the wallet to refuse to send siacoin
+		// outputs below the dust threshold, and to add change below the
+		// threshold to the miner fee instead of creating a change output.
+		// Otherwise, the wallet only logs a warning when sending dust.
+		RejectDustOutputs bool `json:"rejectdustoutputs"`
+
+		// ExcludeDustInputs prevents the wallet from funding transactions
+		// with outputs below the dust threshold, which keeps transactions
+		// small. Outputs that are worth less than the fee of spending them
+		// are never used.
+		ExcludeDustInputs bool `json:"excludedustinputs"`
+
+		// ConfirmedInputsOnly prevents the wallet from funding transactions
+		// with unconfirmed outputs, such as the change of a send that has
+		// not been confirmed yet. Otherwise, sends can be chained within a
+		// block, but a send fails if its unconfirmed parents are dropped.
+		ConfirmedInputsOnly bool `json:"confirmedinputsonly"`
+
+		// CoinSelection is the order in which the wallet spends its siacoin
+		// outputs, either CoinSelectionLargest or CoinSelectionOldest.
+		CoinSelection string `json:"coinselection"`
+
+		// OldestSelectionThreshold is the smallest send that
+		// CoinSelectionOldest applies to. Smaller sends spend the largest
+		// outputs first. Zero applies it to every send.
+		OldestSelectionThreshold types.Currency `json:"oldestselectionthreshold"`
+
+		// MinConfirmations is the number of confirmations that a siacoin
+		// output needs before the wallet spends it. Zero spends unconfirmed
+		// outputs unless ConfirmedInputsOnly is set.
+		MinConfirmations types.BlockHeight `json:"minconfirmations"`
+	}
+
+	// SeedScanProgress reports the progress of a scan of the blockchain for
+	// the addresses of a seed, which happens when a wallet is restored from
+	// a seed or a seed is loaded or swept.
+	SeedScanProgress struct {
+		// Scanning is true while a seed scan is in progress. The other
+		// fields are only meaningful during a scan.
+		Scanning bool `json:"scanning"`
+
+		// BlocksScanned is the number of blocks processed in the current
+		// pass over the blockchain. A scan that keeps finding addresses
+		// makes several passes, generating more keys each time.
+		BlocksScanned types.BlockHeight `json:"blocksscanned"`
+
+		// KeysGenerated is the number of addresses being looked for.
+		KeysGenerated uint64 `json:"keysgenerated"`
+
+		// LargestIndexSeen is the largest seed index that has been found
+		// in the blockchain so far.
+		LargestIndexSeen uint64 `json:"largestindexseen"`
+
+		// GapLimit is the gap limit used by the scan.
+		GapLimit uint64 `json:"gaplimit"`
+	}
+
+	// An AddressBalance reports the confirmed balances of a single wallet
+	// address, along with the total amount that the address has ever
+	// received in confirmed transactions.
+	AddressBalance struct {
+		Address          types.UnlockHash `json:"address"`
+		SiacoinBalance   types.Currency   `json:"siacoinbalance"`
+		SiafundBalance   types.Currency   `json:"siafundbalance"`
+		SiacoinsReceived types.Currency   `json:"siacoinsreceived"`
+		SiafundsReceived types.Currency   `json:"siafundsreceived"`
+	}
+
+	// A SiagKeyfileResult reports the outcome of loading one siag keyfile of
+	// a directory. Error is empty if the key of the file was loaded.
+	SiagKeyfileResult struct {
+		Keyfile string           `json:"keyfile"`
+		Address types.UnlockHash `json:"address"`
+		Error   string           `json:"error,omitempty"`
+	}
+
+	// A BalanceSnapshot records the confirmed balances of the wallet as of
+	// the last block of a day. Unlike ConfirmedBalance, dust is included in
+	// the siacoin balance.
+	BalanceSnapshot struct {
+		// Timestamp is the start of the day, in UTC. Blocks are assigned to
+		// days by their timestamp.
+		Timestamp types.Timestamp `json:"timestamp"`
+
+		// Height is the height of the last block of the day. The snapshot of
+		// the current day is updated with every new block.
+		Height types.BlockHeight `json:"height"`
+
+		ConfirmedSiacoinBalance types.Currency `json:"confirmedsiacoinbalance"`
+		SiafundBalance          types.Currency `json:"siafundbalance"`
+		SiacoinClaimBalance     types.Currency `json:"siacoinclaimbalance"`
+	}
+
+	// A WalletEvent notifies a client of a change to the wallet, such as an
+	// incoming payment. Events are numbered in the order they happened,
+	// starting at 1.
+	WalletEvent struct {
+		ID        uint64          `json:"id"`
+		Type      string          `json:"type"`
+		Timestamp types.Timestamp `json:"timestamp"`
+
+		// TransactionID is the transaction that caused the event. For miner
+		// payouts, it is the ID of the block.
+		TransactionID types.TransactionID `json:"transactionid"`
+
+		// ConfirmationHeight is the height of the block that confirmed or
+		// reverted the transaction. It is zero for payment events.
+		ConfirmationHeight types.BlockHeight `json:"confirmationheight"`
+
+		// OutputID, FundType, Address and Value describe the output that was
+		// received or spent, and are only set for payment and spent events.
+		OutputID types.OutputID   `json:"outputid"`
+		FundType types.Specifier  `json:"fundtype"`
+		Address  types.UnlockHash `json:"address"`
+		Value    types.Currency   `json:"value"`
+	}
+
+	// A PaymentRequest is a request for a payment to a fresh address of the
+	// wallet. The wallet matches the confirmed siacoin outputs sent to the
+	// address against the request, and marks it fulfilled once they add up to
+	// the requested amount. A zero Amount is fulfilled by any payment, and a
+	// zero Expires never expires.
+	PaymentRequest struct {
+		Address types.UnlockHash `json:"address"`
+		Amount  types.Currency   `json:"amount"`
+		Label   string           `json:"label"`
+		Message string           `json:"message"`
+		Created types.Timestamp  `json:"created"`
+		Expires types.Timestamp  `json:"expires"`
+
+		// Received is the total of the confirmed payments to the address,
+		// made by the transactions in TransactionIDs.
+		Received       types.Currency        `json:"received"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+
+		// FulfilledHeight is the height of the block that completed the
+		// payment, if the request is fulfilled.
+		FulfilledHeight types.BlockHeight `json:"fulfilledheight"`
+
+		// Status is one of PaymentRequestPending, PaymentRequestFulfilled or
+		// PaymentRequestExpired, as of the time the request was returned.
+		Status string `json:"status"`
+	}
+
+	// TransactionQuery specifies the criteria used when searching the
+	// wallet's transaction history. Zero-valued fields are ignored, and a
+	// transaction must match all of the remaining criteria to be returned.
+	TransactionQuery struct {
+		// Label matches transactions whose label contains Label, ignoring
+		// case.
+		Label string
+
+		// Address matches transactions that have an input or output related
+		// to Address.
+		Address types.UnlockHash
+
+		// MinAmount and MaxAmount bound the net number of siacoins that the
+		// transaction moved into or out of the wallet.
+		MinAmount types.Currency
+		MaxAmount types.Currency
+	}
+
 	// TransactionBuilder is used to construct custom transactions. A transaction
 	// builder is initialized via 'RegisterTransaction' and then can be modified by
 	// adding funds or other fields. The transaction is completed by calling
@@ -284,10 +549,16 @@ type (
 		// filepath. The backup will have all seeds and keys.
 		CreateBackup(string) error
 
-		// LoadBackup will load a backup of the wallet from the provided
-		// address. The backup wallet will be added as an auxiliary seed, not
-		// as a primary seed.
-		// LoadBackup(masterKey, backupMasterKey crypto.TwofishKey, string) error
+		// ExportBackup writes an encrypted, versioned snapshot of the
+		// wallet's seeds, unseeded keys, transaction labels and settings to
+		// the provided filepath. The snapshot is encrypted with the master
+		// key.
+		ExportBackup(masterKey crypto.TwofishKey, backupFilepath string) error
+
+		// RestoreBackup restores a snapshot created by ExportBackup into a
+		// wallet that has not been encrypted yet, encrypting the wallet with
+		// the master key of the snapshot.
+		RestoreBackup(masterKey crypto.TwofishKey, backupFilepath string) error
 
 		// Load033xWallet will load a version 0.3.3.x wallet from disk and add all of
 		// the keys in the wallet as unseeded keys.
@@ -304,10 +575,27 @@ type (
 		// become spendable.
 		LoadSiagKeys(crypto.TwofishKey, []string) error
 
+		// LoadSiagKeyDir loads every siag key in a directory into the wallet,
+		// grouping the keyfiles of multisig addresses by address, and
+		// rescans the blockchain once. The outcome of each keyfile is
+		// reported separately.
+		LoadSiagKeyDir(masterKey crypto.TwofishKey, dir string) ([]SiagKeyfileResult, error)
+
+		// WatchSiagAddress loads a set of siag keys into the wallet like
+		// LoadSiagKeys, but only scans the blockchain for the outputs of
+		// their address instead of rescanning the whole wallet. The
+		// address's earlier transaction history is not recovered.
+		WatchSiagAddress(crypto.TwofishKey, []string) error
+
 		// NextAddress returns a new coin addresses generated from the
 		// primary seed.
 		NextAddress() (types.UnlockConditions, error)
 
+		// DeriveAddress returns the address at the given index of the
+		// primary seed, generating it and all preceding addresses if the
+		// wallet has not generated them yet.
+		DeriveAddress(index uint64) (types.UnlockConditions, error)
+
 		// PrimarySeed returns the unencrypted primary seed of the wallet,
 		// along with a uint64 indicating how many addresses may be safely
 		// generated from the seed.
@@ -321,12 +609,52 @@ type (
 		SweepSeed(seed Seed) (coins, funds types.Currency, err error)
 	}
 
+	// A WalletSender sends siacoins and siafunds from a wallet.
+	WalletSender interface {
+		// SendSiacoins is a tool for sending siacoins from the wallet to an
+		// address. Sending money usually results in multiple transactions. The
+		// transactions are automatically given to the transaction pool, and
+		// are also returned to the caller.
+		SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
+
+		// SendAllSiacoins sends the entire spendable balance of the wallet,
+		// minus the miner fee, to a single address. The amount that was
+		// sent is returned along with the transactions.
+		SendAllSiacoins(dest types.UnlockHash) ([]types.Transaction, types.Currency, error)
+
+		// SendSiacoinsMulti sends coins to multiple addresses.
+		SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error)
+
+		// SendSiacoinsMemo sends coins to multiple addresses in a
+		// transaction that carries the provided memo. The memo is public.
+		SendSiacoinsMemo(outputs []types.SiacoinOutput, memo []byte) ([]types.Transaction, error)
+
+		// SendSiacoinsTarget sends coins to multiple addresses, paying the
+		// fee that is estimated to get the transaction confirmed within
+		// 'target' blocks. If replaceable is true, the wallet bumps the fee
+		// of the transaction whenever it misses the target. A non-empty memo
+		// is attached to the transaction.
+		SendSiacoinsTarget(outputs []types.SiacoinOutput, target types.BlockHeight, replaceable bool, memo []byte) ([]types.Transaction, error)
+
+		// SendSiafunds is a tool for sending siafunds from the wallet to an
+		// address. Sending money usually results in multiple transactions. The
+		// transactions are automatically given to the transaction pool, and
+		// are also returned to the caller.
+		SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
+
+		// SendSiafundsMulti sends siafunds to multiple addresses in one
+		// transaction. The siacoin claims of the spent siafunds are paid to
+		// the wallet.
+		SendSiafundsMulti(outputs []types.SiafundOutput) ([]types.Transaction, error)
+	}
+
 	// Wallet stores and manages siacoins and siafunds. The wallet file is
 	// encrypted using a user-specified password. Common addresses are all
 	// derived from a single address seed.
 	Wallet interface {
 		EncryptionManager
 		KeyManager
+		WalletSender
 
 		// Close permits clean shutdown during testing and serving.
 		Close() error
@@ -347,6 +675,11 @@ type (
 		// to a given address.
 		AddressTransactions(types.UnlockHash) []ProcessedTransaction
 
+		// AddressBalances returns the confirmed balances of the given
+		// addresses and the totals they have received, in the order the
+		// addresses were given.
+		AddressBalances([]types.UnlockHash) ([]AddressBalance, error)
+
 		// AddressUnconfirmedHistory returns all of the unconfirmed
 		// transactions related to a given address.
 		AddressUnconfirmedTransactions(types.UnlockHash) []ProcessedTransaction
@@ -365,6 +698,44 @@ type (
 		// relative to the wallet.
 		UnconfirmedTransactions() []ProcessedTransaction
 
+		// PendingTransactions returns the unconfirmed transactions relevant to
+		// the wallet along with how long they have been in the transaction
+		// pool, oldest first.
+		PendingTransactions() []PendingTransaction
+
+		// SearchTransactions returns all of the confirmed and unconfirmed
+		// transactions that match the query.
+		SearchTransactions(TransactionQuery) (confirmed []ProcessedTransaction, unconfirmed []ProcessedTransaction)
+
+		// SetTransactionLabel attaches a label to a transaction in the
+		// wallet's history. Labels are only stored locally. An empty label
+		// removes any existing label from the transaction.
+		SetTransactionLabel(txid types.TransactionID, label string) error
+
+		// TransactionLabels returns all of the labels that have been attached
+		// to transactions in the wallet's history.
+		TransactionLabels() map[types.TransactionID]string
+
+		// NewPaymentRequest creates a request for a payment of amount
+		// siacoins to a fresh address of the wallet.
+		NewPaymentRequest(amount types.Currency, label, message string, expires types.Timestamp) (PaymentRequest, error)
+
+		// PaymentRequest returns the payment request for an address.
+		PaymentRequest(addr types.UnlockHash) (PaymentRequest, error)
+
+		// PaymentRequests returns all of the wallet's payment requests,
+		// oldest first.
+		PaymentRequests() ([]PaymentRequest, error)
+
+		// Settings returns the wallet's settings.
+		Settings() (WalletSettings, error)
+
+		// SetSettings changes the wallet's settings. If the change policy is
+		// ChangePolicyFixed and no change address is provided, a fresh
+		// address is generated and used for all future change. A gap limit
+		// of zero selects the default gap limit.
+		SetSettings(WalletSettings) error
+
 		// RegisterTransaction takes a transaction and its parents and returns
 		// a TransactionBuilder which can be used to expand the transaction.
 		RegisterTransaction(t types.Transaction, parents []types.Transaction) TransactionBuilder
@@ -373,24 +744,82 @@ type (
 		// blockchain.
 		Rescanning() bool
 
+		// ScanProgress reports the progress of the seed scan that is
+		// currently running, if any.
+		ScanProgress() SeedScanProgress
+
+		// Reindex discards the wallet's outputs, transaction history and
+		// balance history, and rebuilds them by rescanning the blockchain.
+		Reindex() error
+
+		// BalanceHistory returns a snapshot of the wallet's confirmed
+		// balances for every day since the wallet first received funds,
+		// oldest first.
+		BalanceHistory() ([]BalanceSnapshot, error)
+
+		// Events returns the wallet events with an ID greater than since,
+		// blocking until there is at least one such event or cancel is
+		// closed. Only the most recent events are kept.
+		Events(since uint64, cancel <-chan struct{}) []WalletEvent
+
 		// StartTransaction is a convenience method that calls
 		// RegisterTransaction(types.Transaction{}, nil)
 		StartTransaction() TransactionBuilder
 
-		// SendSiacoins is a tool for sending siacoins from the wallet to an
-		// address. Sending money usually results in multiple transactions. The
-		// transactions are automatically given to the transaction pool, and
-		// are also returned to the caller.
-		SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
-
-		// SendSiacoinsMulti sends coins to multiple addresses.
-		SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error)
-
-		// SendSiafunds is a tool for sending siafunds from the wallet to an
-		// address. Sending money usually results in multiple transactions. The
-		// transactions are automatically given to the transaction pool, and
-		// are also returned to the caller.
-		SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
+		// DryRun returns a WalletSender whose sends build and sign the same
+		// transactions as the wallet's, so that their inputs, outputs and
+		// fees can be checked, but discard them instead of broadcasting
+		// them. The outputs they would spend remain available.
+		DryRun() WalletSender
+
+		// BumpTransactionFee replaces an unconfirmed transaction of the
+		// wallet with one that makes the same payments but pays the provided
+		// miner fee. If the fee is zero, a fee is picked from the current fee
+		// estimate. The replacement is given to the transaction pool and is
+		// also returned.
+		BumpTransactionFee(txid types.TransactionID, fee types.Currency) ([]types.Transaction, error)
+
+		// CancelTransaction replaces an unconfirmed transaction of the wallet
+		// with one that returns its funds to the wallet, paying the provided
+		// miner fee.
+		CancelTransaction(txid types.TransactionID, fee types.Currency) ([]types.Transaction, error)
+
+		// ProveReserves creates a proof that the wallet controls at least
+		// 'amount' siacoins at the current height, signing the challenge
+		// along with the outputs. The largest confirmed outputs are used; if
+		// amount is zero, all of them are. The proof can be checked with
+		// VerifyReservesProof.
+		ProveReserves(amount types.Currency, challenge string) (ReservesProof, error)
+
+		// PrepareSiafundTransaction creates a transaction that sends
+		// siafunds from an address whose keys are kept offline. The wallet
+		// pays and signs the miner fee, while the siafund inputs are left
+		// unsigned. The transaction set is returned without being broadcast.
+		PrepareSiafundTransaction(amount types.Currency, dest, source types.UnlockHash) ([]types.Transaction, error)
+
+		// BroadcastSiafundTransaction signs the wallet's inputs of a prepared
+		// siafund transaction whose siafund inputs have been signed offline,
+		// and gives it to the transaction pool.
+		BroadcastSiafundTransaction(txnSet []types.Transaction) ([]types.Transaction, error)
+
+		// ClaimSiafunds sends all of the wallet's siafunds to one of its own
+		// addresses, which pays out the siacoin claim balance of the siafund
+		// outputs to the wallet.
+		ClaimSiafunds() ([]types.Transaction, error)
+
+		// CreatePartialTransaction creates a partial transaction from a
+		// transaction set, filling in the values of the inputs that the
+		// wallet knows.
+		CreatePartialTransaction(txnSet []types.Transaction) (PartialTransaction, error)
+
+		// SignPartialTransaction adds the signatures of the wallet's keys to
+		// a partial transaction.
+		SignPartialTransaction(PartialTransaction) (PartialTransaction, error)
+
+		// FinalizePartialTransaction turns a partial transaction with
+		// enough signatures into a valid transaction set, without
+		// broadcasting it.
+		FinalizePartialTransaction(PartialTransaction) ([]types.Transaction, error)
 	}
 )
 
@@ -400,6 +829,39 @@ func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) W
 	return WalletTransactionID(crypto.HashAll(tid, oid))
 }
 
+// URI returns the payment URI of a payment request.
+func (pr PaymentRequest) URI() types.PaymentURI {
+	return types.PaymentURI{
+		Address: pr.Address,
+		Amount:  pr.Amount,
+		Label:   pr.Label,
+		Message: pr.Message,
+		Expires: pr.Expires,
+	}
+}
+
+// MemoArbitraryData returns the arbitrary data that attaches a memo to a
+// transaction. The memo is prefixed with PrefixNonSia, so that transactions
+// carrying it are standard.
+func MemoArbitraryData(memo []byte) []byte {
+	arb := make([]byte, types.SpecifierLen+len(memo))
+	copy(arb, PrefixNonSia[:])
+	copy(arb[types.SpecifierLen:], memo)
+	return arb
+}
+
+// TransactionMemo returns the memo attached to a transaction, which is the
+// first arbitrary data prefixed with PrefixNonSia. The bool indicates whether
+// the transaction carries a memo.
+func TransactionMemo(t types.Transaction) ([]byte, bool) {
+	for _, arb := range t.ArbitraryData {
+		if len(arb) >= types.SpecifierLen && bytes.Equal(arb[:types.SpecifierLen], PrefixNonSia[:]) {
+			return arb[types.SpecifierLen:], true
+		}
+	}
+	return nil, false
+}
+
 // SeedToString converts a wallet seed to a human friendly string.
 func SeedToString(seed Seed, did mnemonics.DictionaryID) (string, error) {
 	fullChecksum := crypto.HashObject(seed)
@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	siasync "github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// addressScanSyncInterval is how long the wallet waits between attempts
+	// to line up an addressScanner with its own view of the consensus set.
+	addressScanSyncInterval = 50 * time.Millisecond
+)
+
+// An addressScanner follows the consensus set and tracks the unspent outputs
+// belonging to a single address. It is used to add an address to the wallet
+// without rescanning the blockchain for every address the wallet owns.
+type addressScanner struct {
+	addr           types.UnlockHash
+	siacoinOutputs map[types.SiacoinOutputID]types.SiacoinOutput
+	siafundOutputs map[types.SiafundOutputID]types.SiafundOutput
+	lastChange     modules.ConsensusChangeID
+	mu             sync.Mutex
+}
+
+// newAddressScanner returns an addressScanner for the given address.
+func newAddressScanner(addr types.UnlockHash) *addressScanner {
+	return &addressScanner{
+		addr:           addr,
+		siacoinOutputs: make(map[types.SiacoinOutputID]types.SiacoinOutput),
+		siafundOutputs: make(map[types.SiafundOutputID]types.SiafundOutput),
+	}
+}
+
+// ProcessConsensusChange updates the outputs tracked by the scanner.
+func (as *addressScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	for _, diff := range cc.SiacoinOutputDiffs {
+		if diff.SiacoinOutput.UnlockHash != as.addr {
+			continue
+		}
+		if diff.Direction == modules.DiffApply {
+			as.siacoinOutputs[diff.ID] = diff.SiacoinOutput
+		} else {
+			delete(as.siacoinOutputs, diff.ID)
+		}
+	}
+	for _, diff := range cc.SiafundOutputDiffs {
+		if diff.SiafundOutput.UnlockHash != as.addr {
+			continue
+		}
+		if diff.Direction == modules.DiffApply {
+			as.siafundOutputs[diff.ID] = diff.SiafundOutput
+		} else {
+			delete(as.siafundOutputs, diff.ID)
+		}
+	}
+	as.lastChange = cc.ID
+}
+
+// managedIntegrateScannedKey waits until the scanner has seen exactly the
+// same consensus changes as the wallet, and then adds the key and the outputs
+// found by the scanner to the wallet. From then on, the wallet tracks the
+// address through its own consensus subscription.
+func (w *Wallet) managedIntegrateScannedKey(masterKey crypto.TwofishKey, sk spendableKey, as *addressScanner) error {
+	for {
+		done, err := func() (bool, error) {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			as.mu.Lock()
+			defer as.mu.Unlock()
+
+			if as.lastChange != dbGetConsensusChangeID(w.dbTx) {
+				return false, nil
+			}
+			err := w.loadSpendableKey(masterKey, sk)
+			if err != nil {
+				return false, err
+			}
+			w.integrateSpendableKey(masterKey, sk)
+			for id, sco := range as.siacoinOutputs {
+				if err := dbPutSiacoinOutput(w.dbTx, id, sco); err != nil {
+					return false, err
+				}
+			}
+			for id, sfo := range as.siafundOutputs {
+				if err := dbPutSiafundOutput(w.dbTx, id, sfo); err != nil {
+					return false, err
+				}
+			}
+			w.syncDB()
+			return true, nil
+		}()
+		if err != nil || done {
+			return err
+		}
+
+		select {
+		case <-w.tg.StopChan():
+			return siasync.ErrStopped
+		case <-time.After(addressScanSyncInterval):
+		}
+	}
+}
+
+// WatchSiagAddress loads a set of siag-generated keys into the wallet and
+// starts tracking their address immediately. Unlike LoadSiagKeys, only the
+// outputs of the new address are scanned for, leaving the rest of the wallet
+// untouched. Transactions that happened before the keys were loaded are not
+// added to the wallet's transaction history.
+func (w *Wallet) WatchSiagAddress(masterKey crypto.TwofishKey, keyfiles []string) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	sk, err := readSiagKeys(keyfiles)
+	if err != nil {
+		return err
+	}
+
+	// Check the key before scanning, so that a bad password or a duplicate
+	// key does not cost a scan of the blockchain.
+	err = func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+		if _, exists := w.keys[sk.UnlockConditions.UnlockHash()]; exists {
+			return errDuplicateSpendableKey
+		}
+		return checkMasterKey(w.dbTx, masterKey)
+	}()
+	if err != nil {
+		return err
+	}
+
+	as := newAddressScanner(sk.UnlockConditions.UnlockHash())
+	err = w.cs.ConsensusSetSubscribe(as, modules.ConsensusChangeBeginning, w.tg.StopChan())
+	if err != nil {
+		return err
+	}
+	defer w.cs.Unsubscribe(as)
+	return w.managedIntegrateScannedKey(masterKey, sk, as)
+}
@@ -0,0 +1,212 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+)
+
+var (
+	// backupMetadata is the header and version of wallet backup files. The
+	// version must be changed whenever the layout of walletBackup changes.
+	backupMetadata = persist.Metadata{
+		Header:  "Sia Wallet Backup",
+		Version: "1.0",
+	}
+
+	errRestoreEncrypted = errors.New("a backup can only be restored into a wallet that has not been encrypted yet")
+)
+
+type (
+	// backupFile is the on-disk form of a wallet backup. The backup is
+	// encrypted with a key derived from the wallet's master key, in the same
+	// way as the seed files in the wallet database.
+	backupFile struct {
+		UID                    uniqueID          `json:"uid"`
+		EncryptionVerification crypto.Ciphertext `json:"encryptionverification"`
+		Backup                 crypto.Ciphertext `json:"backup"`
+	}
+
+	// walletBackup is the decrypted content of a wallet backup. The settings
+	// are encoded by encodeWalletSettings.
+	walletBackup struct {
+		PrimarySeed         modules.Seed
+		PrimarySeedProgress uint64
+		AuxiliarySeeds      []modules.Seed
+		SpendableKeys       []spendableKey
+		Labels              []backupLabel
+		Settings            []byte
+	}
+
+	// backupLabel is a transaction label stored in a wallet backup.
+	backupLabel struct {
+		ID    types.TransactionID
+		Label string
+	}
+)
+
+// managedReadBackup reads the seeds, keys, labels and settings of the wallet
+// out of the database, decrypting them with the master key.
+func (w *Wallet) managedReadBackup(masterKey crypto.TwofishKey) (wb walletBackup, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.encrypted {
+		return walletBackup{}, errUnencryptedWallet
+	}
+	if err = checkMasterKey(w.dbTx, masterKey); err != nil {
+		return walletBackup{}, err
+	}
+
+	bucket := w.dbTx.Bucket(bucketWallet)
+	var primarySeedFile seedFile
+	if err = encoding.Unmarshal(bucket.Get(keyPrimarySeedFile), &primarySeedFile); err != nil {
+		return walletBackup{}, err
+	}
+	if wb.PrimarySeed, err = decryptSeedFile(masterKey, primarySeedFile); err != nil {
+		return walletBackup{}, err
+	}
+	if wb.PrimarySeedProgress, err = dbGetPrimarySeedProgress(w.dbTx); err != nil {
+		return walletBackup{}, err
+	}
+
+	var auxiliarySeedFiles []seedFile
+	if err = encoding.Unmarshal(bucket.Get(keyAuxiliarySeedFiles), &auxiliarySeedFiles); err != nil {
+		return walletBackup{}, err
+	}
+	for _, sf := range auxiliarySeedFiles {
+		seed, err := decryptSeedFile(masterKey, sf)
+		if err != nil {
+			return walletBackup{}, err
+		}
+		wb.AuxiliarySeeds = append(wb.AuxiliarySeeds, seed)
+	}
+
+	var spendableKeyFiles []spendableKeyFile
+	if err = encoding.Unmarshal(bucket.Get(keySpendableKeyFiles), &spendableKeyFiles); err != nil {
+		return walletBackup{}, err
+	}
+	for _, skf := range spendableKeyFiles {
+		sk, err := decryptSpendableKeyFile(masterKey, skf)
+		if err != nil {
+			return walletBackup{}, err
+		}
+		wb.SpendableKeys = append(wb.SpendableKeys, sk)
+	}
+
+	err = dbForEachTransactionLabel(w.dbTx, func(id types.TransactionID, label string) {
+		wb.Labels = append(wb.Labels, backupLabel{ID: id, Label: label})
+	})
+	if err != nil {
+		return walletBackup{}, err
+	}
+	settings, err := dbGetWalletSettings(w.dbTx)
+	if err != nil {
+		return walletBackup{}, err
+	}
+	wb.Settings = encodeWalletSettings(settings)
+	return wb, nil
+}
+
+// ExportBackup writes an encrypted snapshot of the wallet to the provided
+// filepath. The snapshot holds the primary seed and its progress, the
+// auxiliary seeds, the unseeded keys such as siag keys, the transaction labels
+// and the wallet settings. It is encrypted with the master key, so it can
+// only be restored with the same key. Unlike a copy of the wallet database,
+// the snapshot is taken from a consistent view of the wallet, so it is safe to
+// create while siad is running.
+func (w *Wallet) ExportBackup(masterKey crypto.TwofishKey, backupFilepath string) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	wb, err := w.managedReadBackup(masterKey)
+	if err != nil {
+		return err
+	}
+	var bf backupFile
+	fastrand.Read(bf.UID[:])
+	key := uidEncryptionKey(masterKey, bf.UID)
+	bf.EncryptionVerification = key.EncryptBytes(verificationPlaintext)
+	bf.Backup = key.EncryptBytes(encoding.Marshal(wb))
+	return persist.SaveJSON(backupMetadata, bf, backupFilepath)
+}
+
+// RestoreBackup restores a backup created by ExportBackup into a wallet that
+// has not been encrypted yet. The wallet is encrypted with the master key that
+// was used to create the backup. The wallet must be unlocked afterwards, at
+// which point it rescans the blockchain to rebuild its balance and history.
+func (w *Wallet) RestoreBackup(masterKey crypto.TwofishKey, backupFilepath string) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	var bf backupFile
+	if err := persist.LoadJSON(backupMetadata, &bf, backupFilepath); err != nil {
+		return err
+	}
+	key := uidEncryptionKey(masterKey, bf.UID)
+	if err := verifyEncryption(key, bf.EncryptionVerification); err != nil {
+		return err
+	}
+	plaintext, err := key.DecryptBytes(bf.Backup)
+	if err != nil {
+		return err
+	}
+	var wb walletBackup
+	if err := encoding.Unmarshal(plaintext, &wb); err != nil {
+		return err
+	}
+	settings, err := decodeWalletSettings(wb.Settings)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.encrypted {
+		return errRestoreEncrypted
+	}
+	// Write everything except the encryption verification first, so that a
+	// failure part way through leaves the wallet unencrypted and the restore
+	// can be retried.
+	bucket := w.dbTx.Bucket(bucketWallet)
+	auxiliarySeedFiles := make([]seedFile, 0, len(wb.AuxiliarySeeds))
+	for _, seed := range wb.AuxiliarySeeds {
+		auxiliarySeedFiles = append(auxiliarySeedFiles, createSeedFile(masterKey, seed))
+	}
+	if err := bucket.Put(keyAuxiliarySeedFiles, encoding.Marshal(auxiliarySeedFiles)); err != nil {
+		return err
+	}
+	spendableKeyFiles := make([]spendableKeyFile, 0, len(wb.SpendableKeys))
+	for _, sk := range wb.SpendableKeys {
+		var skf spendableKeyFile
+		fastrand.Read(skf.UID[:])
+		skKey := uidEncryptionKey(masterKey, skf.UID)
+		skf.EncryptionVerification = skKey.EncryptBytes(verificationPlaintext)
+		skf.SpendableKey = skKey.EncryptBytes(encoding.Marshal(sk))
+		spendableKeyFiles = append(spendableKeyFiles, skf)
+	}
+	if err := bucket.Put(keySpendableKeyFiles, encoding.Marshal(spendableKeyFiles)); err != nil {
+		return err
+	}
+	for _, l := range wb.Labels {
+		if err := dbPutTransactionLabel(w.dbTx, l.ID, l.Label); err != nil {
+			return err
+		}
+	}
+	if err := dbPutWalletSettings(w.dbTx, settings); err != nil {
+		return err
+	}
+	if _, err := w.initEncryption(masterKey, wb.PrimarySeed, wb.PrimarySeedProgress); err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
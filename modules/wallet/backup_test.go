@@ -0,0 +1,116 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestExportRestoreBackup checks that a backup restores the seeds, keys,
+// labels and settings of a wallet into a fresh wallet.
+func TestExportRestoreBackup(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Give the wallet an auxiliary seed, a siag key, a label and custom
+	// settings.
+	if err := wt.wallet.LoadSeed(wt.walletMasterKey, modules.Seed{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	err = wt.wallet.LoadSiagKeys(wt.walletMasterKey, []string{"../../types/siag0of1of1.siakey"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	if err := wt.wallet.SetTransactionLabel(txid, "backup"); err != nil {
+		t.Fatal(err)
+	}
+	wt.addBlockNoPayout()
+	settings, err := wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.GapLimit = 123
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Exporting requires the master key.
+	backupPath := filepath.Join(wt.persistDir, "wallet.backup")
+	if err := wt.wallet.ExportBackup(crypto.TwofishKey{}, backupPath); err != modules.ErrBadEncryptionKey {
+		t.Fatal("expected ErrBadEncryptionKey, got", err)
+	}
+	if err := wt.wallet.ExportBackup(wt.walletMasterKey, backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restore the backup into a fresh wallet.
+	w2, err := New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, "wallet2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	if err := w2.RestoreBackup(crypto.TwofishKey{}, backupPath); err != modules.ErrBadEncryptionKey {
+		t.Fatal("expected ErrBadEncryptionKey, got", err)
+	}
+	if err := w2.RestoreBackup(wt.walletMasterKey, backupPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.RestoreBackup(wt.walletMasterKey, backupPath); err != errRestoreEncrypted {
+		t.Fatal("expected errRestoreEncrypted, got", err)
+	}
+	if err := w2.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	seed1, progress1, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed2, progress2, err := w2.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed1 != seed2 || progress1 != progress2 {
+		t.Fatal("primary seed was not restored")
+	}
+	seeds, err := w2.AllSeeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seeds) != 2 || seeds[1] != (modules.Seed{1, 2, 3}) {
+		t.Fatal("auxiliary seed was not restored")
+	}
+	if w2.TransactionLabels()[txid] != "backup" {
+		t.Fatal("label was not restored")
+	}
+	if s, err := w2.Settings(); err != nil || s.GapLimit != 123 {
+		t.Fatal("settings were not restored", err)
+	}
+
+	// The restored wallet should see the same funds, including the
+	// siafunds of the siag key.
+	sc1, sf1, _ := wt.wallet.ConfirmedBalance()
+	sc2, sf2, _ := w2.ConfirmedBalance()
+	if !sc1.Equals(sc2) || !sf1.Equals(sf2) || sf2.IsZero() {
+		t.Fatalf("restored wallet has balance %v/%v, expected %v/%v", sc2, sf2, sc1, sf1)
+	}
+}
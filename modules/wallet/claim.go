@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errNoSiafunds = errors.New("wallet has no siafunds to claim")
+)
+
+// ClaimSiafunds sends all of the wallet's siafunds to a new address owned by
+// the wallet. Spending a siafund output pays out its siacoin claim balance to
+// the claim address chosen by the wallet, so the claim balance becomes part of
+// the wallet's spendable balance once the transaction is confirmed.
+func (w *Wallet) ClaimSiafunds() ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	unlocked := w.unlocked
+	w.mu.RUnlock()
+	if !unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+
+	_, siafunds, _ := w.ConfirmedBalance()
+	if siafunds.IsZero() {
+		return nil, errNoSiafunds
+	}
+	uc, err := w.NextAddress()
+	if err != nil {
+		return nil, err
+	}
+	return w.SendSiafunds(siafunds, uc.UnlockHash())
+}
+
+// threadedAutoClaimSiafunds claims the wallet's siacoin claim balance if
+// automatic claiming is enabled, at most once every autoClaimInterval blocks.
+// The balance is only claimed once it is large enough to justify the fee of
+// the siafund transaction.
+func (w *Wallet) threadedAutoClaimSiafunds() {
+	err := w.tg.Add()
+	if err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	// Check whether a claim is due. The height of the check is recorded
+	// immediately, so that the goroutines started by consecutive blocks do
+	// not claim twice.
+	due, err := func() (bool, error) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return false, nil
+		}
+		settings, err := dbGetWalletSettings(w.dbTx)
+		if err != nil || !settings.AutoClaimSiafunds {
+			return false, err
+		}
+		height, err := dbGetConsensusHeight(w.dbTx)
+		if err != nil {
+			return false, err
+		}
+		if w.lastAutoClaim != 0 && height < w.lastAutoClaim+autoClaimInterval {
+			return false, nil
+		}
+		w.lastAutoClaim = height
+		return true, nil
+	}()
+	if err != nil {
+		w.log.Println("WARN: couldn't check whether siafunds should be claimed:", err)
+		return
+	} else if !due {
+		return
+	}
+
+	_, siafunds, claim := w.ConfirmedBalance()
+	if siafunds.IsZero() || claim.Cmp(w.siafundTransactionFee().Mul64(autoClaimFeeMultiple)) < 0 {
+		return
+	}
+	txnSet, err := w.ClaimSiafunds()
+	if err != nil {
+		w.log.Println("WARN: couldn't claim siafund claim balance:", err)
+		return
+	}
+	w.log.Println("Automatically claimed a siafund claim balance of", claim.HumanString(), "IDs:")
+	for _, txn := range txnSet {
+		w.log.Println("\t", txn.ID())
+	}
+}
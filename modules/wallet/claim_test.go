@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestClaimSiafunds checks that claiming siafunds sends them back to the
+// wallet.
+func TestClaimSiafunds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// A wallet without siafunds has nothing to claim.
+	if _, err := wt.wallet.ClaimSiafunds(); err != errNoSiafunds {
+		t.Fatal("expected errNoSiafunds, got", err)
+	}
+
+	err = wt.wallet.LoadSiagKeys(wt.walletMasterKey, []string{"../../types/siag0of1of1.siakey"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns, err := wt.wallet.ClaimSiafunds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) == 0 {
+		t.Fatal("expected a claim transaction")
+	}
+	wt.addBlockNoPayout()
+
+	// The siafunds should still belong to the wallet.
+	_, siafundBal, _ := wt.wallet.ConfirmedBalance()
+	if siafundBal.Cmp(types.NewCurrency64(2000)) != 0 {
+		t.Error("expecting a siafund balance of 2000 after the claim, got", siafundBal)
+	}
+	wt.wallet.mu.RLock()
+	defer wt.wallet.mu.RUnlock()
+	for _, txn := range txns {
+		for _, sfo := range txn.SiafundOutputs {
+			if !wt.wallet.isWalletAddress(sfo.UnlockHash) {
+				t.Error("siafunds were sent to an address not owned by the wallet")
+			}
+		}
+	}
+}
+
+// TestAutoClaimSiafunds checks that automatic claims only run when enabled,
+// and at most once every autoClaimInterval blocks.
+func TestAutoClaimSiafunds(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	lastAutoClaim := func() types.BlockHeight {
+		wt.wallet.mu.RLock()
+		defer wt.wallet.mu.RUnlock()
+		return wt.wallet.lastAutoClaim
+	}
+
+	// Automatic claims are disabled by default.
+	wt.wallet.threadedAutoClaimSiafunds()
+	if lastAutoClaim() != 0 {
+		t.Fatal("automatic claim was checked while disabled")
+	}
+
+	settings, err := wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.AutoClaimSiafunds = true
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.threadedAutoClaimSiafunds()
+	height := lastAutoClaim()
+	if height != wt.cs.Height() {
+		t.Fatalf("expected a claim check at height %v, got %v", wt.cs.Height(), height)
+	}
+
+	// The next check is only due after autoClaimInterval blocks.
+	for i := types.BlockHeight(0); i < autoClaimInterval-1; i++ {
+		wt.addBlockNoPayout()
+		wt.wallet.threadedAutoClaimSiafunds()
+		if lastAutoClaim() != height {
+			t.Fatal("claim was checked before the interval passed")
+		}
+	}
+	wt.addBlockNoPayout()
+	wt.wallet.threadedAutoClaimSiafunds()
+	if lastAutoClaim() != height+autoClaimInterval {
+		t.Fatal("claim was not checked after the interval passed")
+	}
+}
@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"errors"
+	"math"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// An output received in a recent block is lost if a reorg reverts that block,
+// and with it every send that spent the output. The wallet can be set to
+// spend its most deeply confirmed outputs first, and to leave outputs alone
+// until they have a minimum number of confirmations. The height at which an
+// output was confirmed is taken from the wallet's processed transactions;
+// outputs that are not found there, such as file contract payouts, are
+// treated as deeply confirmed.
+
+const (
+	// unconfirmedHeight is the height given to unconfirmed outputs, so that
+	// they are spent last when the oldest outputs are spent first.
+	unconfirmedHeight = types.BlockHeight(math.MaxUint64)
+)
+
+var (
+	// errShallowFunds indicates that a transaction can only be funded by
+	// spending outputs with fewer confirmations than the wallet requires.
+	errShallowFunds = errors.New("wallet is set to spend only outputs with a minimum number of confirmations - not enough confirmed coins")
+)
+
+// outputsByAge sorts siacoin outputs by the height at which they were
+// confirmed, oldest first, and then by value, largest first.
+type outputsByAge struct {
+	sortedOutputs
+	heights []types.BlockHeight
+}
+
+// Less returns whether element 'i' should be spent before element 'j'.
+func (oa outputsByAge) Less(i, j int) bool {
+	if oa.heights[i] != oa.heights[j] {
+		return oa.heights[i] < oa.heights[j]
+	}
+	return oa.outputs[i].Value.Cmp(oa.outputs[j].Value) > 0
+}
+
+// Swap swaps two elements in the outputsByAge set.
+func (oa outputsByAge) Swap(i, j int) {
+	oa.sortedOutputs.Swap(i, j)
+	oa.heights[i], oa.heights[j] = oa.heights[j], oa.heights[i]
+}
+
+// selectOldest returns whether a send of 'amount' spends the oldest outputs
+// first.
+func selectOldest(settings modules.WalletSettings, amount types.Currency) bool {
+	return settings.CoinSelection == modules.CoinSelectionOldest && amount.Cmp(settings.OldestSelectionThreshold) >= 0
+}
+
+// confirmations returns the number of confirmations of an output that was
+// confirmed at 'height'.
+func confirmations(consensusHeight, height types.BlockHeight) types.BlockHeight {
+	if height > consensusHeight {
+		return 0
+	}
+	return consensusHeight - height + 1
+}
+
+// dbSiacoinOutputHeights returns the heights at which the wallet's siacoin
+// outputs were confirmed.
+func dbSiacoinOutputHeights(tx *bolt.Tx) (map[types.SiacoinOutputID]types.BlockHeight, error) {
+	heights := make(map[types.SiacoinOutputID]types.BlockHeight)
+	err := dbForEachProcessedTransaction(tx, func(pt modules.ProcessedTransaction) {
+		for _, output := range pt.Outputs {
+			if !output.WalletAddress {
+				continue
+			}
+			switch output.FundType {
+			case types.SpecifierSiacoinOutput, types.SpecifierMinerPayout, types.SpecifierClaimOutput:
+				heights[types.SiacoinOutputID(output.ID)] = pt.ConfirmationHeight
+			}
+		}
+	})
+	return heights, err
+}
@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestOutputsByAge checks that outputs are sorted oldest first, and by value
+// within a block.
+func TestOutputsByAge(t *testing.T) {
+	oa := outputsByAge{
+		sortedOutputs: sortedOutputs{
+			ids: []types.SiacoinOutputID{{1}, {2}, {3}, {4}},
+			outputs: []types.SiacoinOutput{
+				{Value: types.NewCurrency64(10)},
+				{Value: types.NewCurrency64(20)},
+				{Value: types.NewCurrency64(30)},
+				{Value: types.NewCurrency64(40)},
+			},
+		},
+		heights: []types.BlockHeight{5, unconfirmedHeight, 3, 5},
+	}
+	sort.Sort(oa)
+	exp := []types.SiacoinOutputID{{3}, {4}, {1}, {2}}
+	for i, id := range oa.ids {
+		if id != exp[i] {
+			t.Fatalf("output %v: expected %v, got %v", i, exp[i], id)
+		}
+	}
+	if oa.heights[0] != 3 || oa.heights[3] != unconfirmedHeight {
+		t.Fatal("heights were not sorted with the outputs:", oa.heights)
+	}
+}
+
+// TestCoinSelection checks that the wallet spends its oldest outputs first
+// when set to, and refuses to spend outputs without enough confirmations.
+func TestCoinSelection(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	settings, err := wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.CoinSelection != modules.CoinSelectionLargest {
+		t.Fatal("default coin selection should be largest, got", settings.CoinSelection)
+	}
+	settings.CoinSelection = "foo"
+	if err := wt.wallet.SetSettings(settings); err != errUnknownCoinSelection {
+		t.Fatal("expected errUnknownCoinSelection, got", err)
+	}
+
+	// Find the height of the oldest output.
+	wt.wallet.mu.Lock()
+	consensusHeight, err := dbGetConsensusHeight(wt.wallet.dbTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heights, err := dbSiacoinOutputHeights(wt.wallet.dbTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldest := consensusHeight
+	dbForEachSiacoinOutput(wt.wallet.dbTx, func(scoid types.SiacoinOutputID, _ types.SiacoinOutput) {
+		if height, ok := heights[scoid]; !ok {
+			t.Error("missing height of output", scoid)
+		} else if height < oldest {
+			oldest = height
+		}
+	})
+	wt.wallet.mu.Unlock()
+
+	// Spend the oldest output first.
+	settings.CoinSelection = modules.CoinSelectionOldest
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	tb := wt.wallet.StartTransaction()
+	if err := tb.FundSiacoins(types.SiacoinPrecision); err != nil {
+		t.Fatal(err)
+	}
+	parent := tb.(*transactionBuilder).parents[0]
+	if height := heights[parent.SiacoinInputs[0].ParentID]; height != oldest {
+		t.Fatalf("expected an output confirmed at height %v to be spent, got %v", oldest, height)
+	}
+	tb.Drop()
+
+	// Outputs without enough confirmations are not spent.
+	settings.MinConfirmations = confirmations(consensusHeight, oldest) + 1
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	tb = wt.wallet.StartTransaction()
+	if err := tb.FundSiacoins(types.SiacoinPrecision); err != errShallowFunds {
+		t.Fatal("expected errShallowFunds, got", err)
+	}
+	tb.Drop()
+	if fund, _, err := wt.wallet.managedSpendableSiacoins(); err != nil || !fund.IsZero() {
+		t.Fatal("expected no spendable siacoins, got", fund, err)
+	}
+
+	settings.MinConfirmations = confirmations(consensusHeight, oldest)
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	tb = wt.wallet.StartTransaction()
+	if err := tb.FundSiacoins(types.SiacoinPrecision); err != nil {
+		t.Fatal(err)
+	}
+	tb.Drop()
+}
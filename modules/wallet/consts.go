@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 const (
@@ -15,6 +16,15 @@ const (
 	// defragStartIndex is the number of outputs to skip over when performing a
 	// defrag.
 	defragStartIndex = 10
+
+	// maxTransactionLabelSize is the maximum number of bytes that a label
+	// attached to a transaction may contain.
+	maxTransactionLabelSize = 1024
+
+	// autoClaimFeeMultiple is how many times larger than the transaction fee
+	// the siacoin claim balance must be before the wallet claims it
+	// automatically.
+	autoClaimFeeMultiple = 10
 )
 
 var (
@@ -33,6 +43,20 @@ var (
 		Standard: uint64(4000),
 		Testing:  uint64(40),
 	}).(uint64)
+
+	// autoClaimInterval is the minimum number of blocks between two
+	// automatic siafund claims.
+	autoClaimInterval = build.Select(build.Var{
+		Dev:      types.BlockHeight(12),
+		Standard: types.BlockHeight(144),
+		Testing:  types.BlockHeight(3),
+	}).(types.BlockHeight)
+
+	// defaultGapLimit is the gap limit used by seed scans until the user
+	// changes it. It matches the size of the wallet's lookahead, so a
+	// restored wallet finds the same addresses that the original wallet was
+	// watching.
+	defaultGapLimit = lookaheadRescanThreshold + lookaheadBuffer
 )
 
 func init() {
@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"reflect"
 	"time"
@@ -15,6 +16,12 @@ import (
 )
 
 var (
+	// bucketBalanceHistory maps the start of a day to the BalanceSnapshot of
+	// the wallet at the end of that day.
+	bucketBalanceHistory = []byte("bucketBalanceHistory")
+	// bucketPaymentRequests stores PaymentRequests in the order they were
+	// created. The key of this bucket is an autoincrementing integer.
+	bucketPaymentRequests = []byte("bucketPaymentRequests")
 	// bucketProcessedTransactions stores ProcessedTransactions in
 	// chronological order. Only transactions relevant to the wallet are
 	// stored. The key of this bucket is an autoincrementing integer.
@@ -32,15 +39,22 @@ var (
 	// these outputs so that it can reuse them if they are not confirmed on
 	// the blockchain.
 	bucketSpentOutputs = []byte("bucketSpentOutputs")
+	// bucketTransactionLabels maps a TransactionID to the label that the
+	// user attached to it. Labels are kept separate from the processed
+	// transactions so that they survive a rescan of the blockchain.
+	bucketTransactionLabels = []byte("bucketTransactionLabels")
 	// bucketWallet contains various fields needed by the wallet, such as its
 	// UID, EncryptionVerification, and PrimarySeedFile.
 	bucketWallet = []byte("bucketWallet")
 
 	dbBuckets = [][]byte{
+		bucketBalanceHistory,
+		bucketPaymentRequests,
 		bucketProcessedTransactions,
 		bucketSiacoinOutputs,
 		bucketSiafundOutputs,
 		bucketSpentOutputs,
+		bucketTransactionLabels,
 		bucketWallet,
 	}
 
@@ -54,6 +68,8 @@ var (
 	keySpendableKeyFiles      = []byte("keySpendableKeyFiles")
 	keyAuxiliarySeedFiles     = []byte("keyAuxiliarySeedFiles")
 	keySiafundPool            = []byte("keySiafundPool")
+	keyWalletSettings         = []byte("keyWalletSettings")
+	keyBalanceHistoryDay      = []byte("keyBalanceHistoryDay")
 
 	errNoKey = errors.New("key does not exist")
 )
@@ -204,6 +220,62 @@ func dbDeleteSpentOutput(tx *bolt.Tx, id types.OutputID) error {
 	return dbDelete(tx.Bucket(bucketSpentOutputs), id)
 }
 
+func dbPutTransactionLabel(tx *bolt.Tx, id types.TransactionID, label string) error {
+	return dbPut(tx.Bucket(bucketTransactionLabels), id, label)
+}
+func dbDeleteTransactionLabel(tx *bolt.Tx, id types.TransactionID) error {
+	return dbDelete(tx.Bucket(bucketTransactionLabels), id)
+}
+func dbForEachTransactionLabel(tx *bolt.Tx, fn func(types.TransactionID, string)) error {
+	return dbForEach(tx.Bucket(bucketTransactionLabels), fn)
+}
+
+func dbAppendPaymentRequest(tx *bolt.Tx, pr modules.PaymentRequest) error {
+	b := tx.Bucket(bucketPaymentRequests)
+	key, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	// big-endian is used so that the keys are properly sorted
+	keyBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(keyBytes, key)
+	return b.Put(keyBytes, encoding.Marshal(pr))
+}
+func dbPutPaymentRequest(tx *bolt.Tx, key []byte, pr modules.PaymentRequest) error {
+	return tx.Bucket(bucketPaymentRequests).Put(key, encoding.Marshal(pr))
+}
+func dbForEachPaymentRequest(tx *bolt.Tx, fn func([]byte, modules.PaymentRequest)) error {
+	return tx.Bucket(bucketPaymentRequests).ForEach(func(key, val []byte) error {
+		var pr modules.PaymentRequest
+		if err := encoding.Unmarshal(val, &pr); err != nil {
+			return err
+		}
+		fn(key, pr)
+		return nil
+	})
+}
+
+// dbGetPaymentRequest returns the payment request for an address, along with
+// its key.
+func dbGetPaymentRequest(tx *bolt.Tx, addr types.UnlockHash) (key []byte, pr modules.PaymentRequest, err error) {
+	err = dbForEachPaymentRequest(tx, func(k []byte, p modules.PaymentRequest) {
+		if p.Address == addr {
+			key, pr = append([]byte(nil), k...), p
+		}
+	})
+	if err == nil && key == nil {
+		err = errNoKey
+	}
+	return
+}
+
+func dbPutBalanceSnapshot(tx *bolt.Tx, snapshot modules.BalanceSnapshot) error {
+	return dbPut(tx.Bucket(bucketBalanceHistory), snapshot.Timestamp, snapshot)
+}
+func dbForEachBalanceSnapshot(tx *bolt.Tx, fn func(types.Timestamp, modules.BalanceSnapshot)) error {
+	return dbForEach(tx.Bucket(bucketBalanceHistory), fn)
+}
+
 // bucketProcessedTransactions works a little differently: the key is
 // meaningless, only used to order the transactions chronologically.
 
@@ -333,6 +405,66 @@ func dbPutSiafundPool(tx *bolt.Tx, pool types.Currency) error {
 	return tx.Bucket(bucketWallet).Put(keySiafundPool, encoding.Marshal(pool))
 }
 
+// dbGetBalanceHistoryDay returns the start of the day of the most recent block
+// processed by the wallet.
+func dbGetBalanceHistoryDay(tx *bolt.Tx) (day types.Timestamp, err error) {
+	dayBytes := tx.Bucket(bucketWallet).Get(keyBalanceHistoryDay)
+	if dayBytes == nil {
+		return 0, errNoKey
+	}
+	err = encoding.Unmarshal(dayBytes, &day)
+	return
+}
+
+// dbPutBalanceHistoryDay stores the start of the day of the most recent block
+// processed by the wallet.
+func dbPutBalanceHistoryDay(tx *bolt.Tx, day types.Timestamp) error {
+	return tx.Bucket(bucketWallet).Put(keyBalanceHistoryDay, encoding.Marshal(day))
+}
+
+// defaultWalletSettings returns the settings of a wallet that has not stored
+// any settings.
+func defaultWalletSettings() modules.WalletSettings {
+	return modules.WalletSettings{
+		ChangePolicy:  modules.ChangePolicyFresh,
+		GapLimit:      defaultGapLimit,
+		CoinSelection: modules.CoinSelectionLargest,
+	}
+}
+
+// encodeWalletSettings encodes the wallet's settings as JSON, so that fields
+// added later can be given their defaults when older settings are decoded.
+func encodeWalletSettings(settings modules.WalletSettings) []byte {
+	b, err := json.Marshal(settings)
+	if err != nil {
+		panic(err) // WalletSettings always marshals
+	}
+	return b
+}
+
+// decodeWalletSettings decodes settings encoded by encodeWalletSettings.
+// Fields that are missing keep their default values.
+func decodeWalletSettings(b []byte) (modules.WalletSettings, error) {
+	settings := defaultWalletSettings()
+	err := json.Unmarshal(b, &settings)
+	return settings, err
+}
+
+// dbGetWalletSettings returns the wallet's settings. If no settings have been
+// stored, the default settings are returned.
+func dbGetWalletSettings(tx *bolt.Tx) (modules.WalletSettings, error) {
+	settingsBytes := tx.Bucket(bucketWallet).Get(keyWalletSettings)
+	if settingsBytes == nil {
+		return defaultWalletSettings(), nil
+	}
+	return decodeWalletSettings(settingsBytes)
+}
+
+// dbPutWalletSettings stores the wallet's settings.
+func dbPutWalletSettings(tx *bolt.Tx, settings modules.WalletSettings) error {
+	return tx.Bucket(bucketWallet).Put(keyWalletSettings, encodeWalletSettings(settings))
+}
+
 // COMPATv121: these types were stored in the db in v1.2.2 and earlier.
 type (
 	v121ProcessedInput struct {
@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errDustOutputRejected is returned when a send would create an output
+	// below the dust threshold while the wallet is set to reject dust.
+	errDustOutputRejected = errors.New("output value is below the wallet's dust threshold")
+)
+
+// outputDustThreshold returns the value below which the wallet considers a
+// new siacoin output to be dust. feeDust is the threshold based on the current
+// transaction fees, as returned by managedDustThreshold.
+func outputDustThreshold(settings modules.WalletSettings, feeDust types.Currency) types.Currency {
+	if settings.DustThreshold.IsZero() {
+		return feeDust
+	}
+	return settings.DustThreshold
+}
+
+// inputDustThreshold returns the value below which the wallet does not use an
+// output to fund a transaction.
+func inputDustThreshold(settings modules.WalletSettings, feeDust types.Currency) types.Currency {
+	if !settings.ExcludeDustInputs {
+		return feeDust
+	}
+	if threshold := outputDustThreshold(settings, feeDust); threshold.Cmp(feeDust) > 0 {
+		return threshold
+	}
+	return feeDust
+}
+
+// managedCheckDustOutputs checks the outputs of a send against the wallet's
+// dust threshold. Dust outputs are rejected if the wallet is set to reject
+// them, and logged otherwise.
+func (w *Wallet) managedCheckDustOutputs(outputs []types.SiacoinOutput) error {
+	feeDust := w.managedDustThreshold()
+	w.mu.Lock()
+	settings, err := dbGetWalletSettings(w.dbTx)
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	threshold := outputDustThreshold(settings, feeDust)
+	for _, sco := range outputs {
+		if sco.Value.Cmp(threshold) >= 0 {
+			continue
+		}
+		if settings.RejectDustOutputs {
+			return errDustOutputRejected
+		}
+		w.log.Println("WARN: sending an output of", sco.Value.HumanString(), "which is below the dust threshold of", threshold.HumanString())
+	}
+	return nil
+}
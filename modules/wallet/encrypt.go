@@ -18,7 +18,7 @@ import (
 var (
 	errAlreadyUnlocked   = errors.New("wallet has already been unlocked")
 	errReencrypt         = errors.New("wallet is already encrypted, cannot encrypt again")
-	errUnencryptedWallet = errors.New("wallet has not been encrypted yet")
+	errUnencryptedWallet = modules.ErrUnencryptedWallet
 	errScanInProgress    = errors.New("another wallet rescan is already underway")
 
 	// verificationPlaintext is the plaintext used to verify encryption keys.
@@ -357,7 +357,7 @@ func (w *Wallet) InitFromSeed(masterKey crypto.TwofishKey, seed modules.Seed) er
 	defer w.tg.Done()
 
 	if !w.cs.Synced() {
-		return errors.New("cannot init from seed until blockchain is synced")
+		return build.ExtendErr("cannot init from seed", modules.ErrNotSynced)
 	}
 
 	// If masterKey is blank, use the hash of the seed.
@@ -371,8 +371,8 @@ func (w *Wallet) InitFromSeed(masterKey crypto.TwofishKey, seed modules.Seed) er
 	defer w.scanLock.Unlock()
 
 	// estimate the primarySeedProgress by scanning the blockchain
-	s := newSeedScanner(seed, w.log)
-	if err := s.scan(w.cs, w.tg.StopChan()); err != nil {
+	s, err := w.managedScanSeed(seed, types.ZeroCurrency)
+	if err != nil {
 		return err
 	}
 	// NOTE: each time the wallet generates a key for index n, it sets its
@@ -386,7 +386,7 @@ func (w *Wallet) InitFromSeed(masterKey crypto.TwofishKey, seed modules.Seed) er
 	// initialize the wallet with the appropriate seed progress
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	_, err := w.initEncryption(masterKey, seed, progress)
+	_, err = w.initEncryption(masterKey, seed, progress)
 	return err
 }
 
@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// maxWalletEvents is the number of recent events kept in memory. Clients
+	// that fall further behind than this miss events.
+	maxWalletEvents = 1000
+)
+
+// queueEvents adds events generated by the consensus change being processed.
+// The queued events are published once the change has been processed.
+func (w *Wallet) queueEvents(events ...modules.WalletEvent) {
+	w.queuedEvents = append(w.queuedEvents, events...)
+}
+
+// publishEvents numbers the events, adds them to the set of recent events and
+// wakes any callers of Events.
+func (w *Wallet) publishEvents(events []modules.WalletEvent) {
+	if len(events) == 0 {
+		return
+	}
+	for _, e := range events {
+		w.lastEventID++
+		e.ID = w.lastEventID
+		e.Timestamp = types.CurrentTimestamp()
+		w.events = append(w.events, e)
+	}
+	if len(w.events) > maxWalletEvents {
+		w.events = append([]modules.WalletEvent(nil), w.events[len(w.events)-maxWalletEvents:]...)
+	}
+	close(w.eventsChan)
+	w.eventsChan = make(chan struct{})
+}
+
+// confirmedEvents returns the events for a transaction that has been
+// confirmed in a block.
+func confirmedEvents(pt modules.ProcessedTransaction) []modules.WalletEvent {
+	events := []modules.WalletEvent{{
+		Type:               modules.WalletEventConfirmation,
+		TransactionID:      pt.TransactionID,
+		ConfirmationHeight: pt.ConfirmationHeight,
+	}}
+	for _, pi := range pt.Inputs {
+		if !pi.WalletAddress {
+			continue
+		}
+		events = append(events, modules.WalletEvent{
+			Type:               modules.WalletEventSpent,
+			TransactionID:      pt.TransactionID,
+			ConfirmationHeight: pt.ConfirmationHeight,
+			OutputID:           pi.ParentID,
+			FundType:           pi.FundType,
+			Address:            pi.RelatedAddress,
+			Value:              pi.Value,
+		})
+	}
+	return events
+}
+
+// paymentEvents returns the events for an unconfirmed transaction. Only
+// transactions that are not funded by the wallet are payments, so that change
+// outputs are not reported.
+func paymentEvents(pt modules.ProcessedTransaction) []modules.WalletEvent {
+	for _, pi := range pt.Inputs {
+		if pi.WalletAddress {
+			return nil
+		}
+	}
+	var events []modules.WalletEvent
+	for _, po := range pt.Outputs {
+		if !po.WalletAddress {
+			continue
+		}
+		events = append(events, modules.WalletEvent{
+			Type:          modules.WalletEventPayment,
+			TransactionID: pt.TransactionID,
+			OutputID:      po.ID,
+			FundType:      po.FundType,
+			Address:       po.RelatedAddress,
+			Value:         po.Value,
+		})
+	}
+	return events
+}
+
+// Events returns the wallet events with an ID greater than since, blocking
+// until there is at least one such event or cancel is closed.
+func (w *Wallet) Events(since uint64, cancel <-chan struct{}) []modules.WalletEvent {
+	if err := w.tg.Add(); err != nil {
+		return nil
+	}
+	defer w.tg.Done()
+
+	for {
+		w.mu.RLock()
+		var events []modules.WalletEvent
+		for _, e := range w.events {
+			if e.ID > since {
+				events = append(events, e)
+			}
+		}
+		wait := w.eventsChan
+		w.mu.RUnlock()
+		if len(events) > 0 {
+			return events
+		}
+
+		select {
+		case <-wait:
+		case <-cancel:
+			return nil
+		case <-w.tg.StopChan():
+			return nil
+		}
+	}
+}
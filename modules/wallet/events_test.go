@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestWalletEvents checks that payments, confirmations and spent outputs are
+// reported to callers of Events.
+func TestWalletEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Events are only reported once the wallet is synced. Mine a block that
+	// pays the wallet.
+	if err := wt.waitForSync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a second wallet to receive a payment.
+	w2, err := New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, "wallet2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+	key := crypto.GenerateTwofishKey()
+	if _, err := w2.Encrypt(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.Unlock(key); err != nil {
+		t.Fatal(err)
+	}
+	uc, err := w2.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A closed cancel channel should stop the call immediately.
+	cancel := make(chan struct{})
+	close(cancel)
+	if events := w2.Events(0, cancel); len(events) != 0 {
+		t.Fatal("expected no events for an unused wallet, got", events)
+	}
+	var since uint64
+	if events := wt.wallet.Events(0, cancel); len(events) == 0 {
+		t.Fatal("expected events for the mined block")
+	} else {
+		since = events[len(events)-1].ID
+	}
+
+	// Wait for a payment to the second wallet in the background.
+	eventsChan := make(chan []modules.WalletEvent)
+	go func() {
+		eventsChan <- w2.Events(0, nil)
+	}()
+	value := types.SiacoinPrecision.Mul64(100)
+	txns, err := wt.wallet.SendSiacoins(value, uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	var events []modules.WalletEvent
+	select {
+	case events = <-eventsChan:
+	case <-time.After(10 * time.Second):
+		t.Fatal("payment was not reported")
+	}
+	if len(events) != 1 {
+		t.Fatal("expected one payment event, got", events)
+	}
+	e := events[0]
+	if e.ID != 1 || e.Type != modules.WalletEventPayment || e.TransactionID != txid ||
+		e.Address != uc.UnlockHash() || !e.Value.Equals(value) || e.FundType != types.SpecifierSiacoinOutput {
+		t.Fatal("wrong payment event:", e)
+	}
+	// The sending wallet should not report its change as a payment.
+	if events := wt.wallet.Events(since, cancel); len(events) != 0 {
+		t.Fatal("sending wallet reported events for an unconfirmed send:", events)
+	}
+
+	// Confirm the payment.
+	wt.addBlockNoPayout()
+	height := wt.cs.Height()
+	events = w2.Events(e.ID, cancel)
+	if len(events) != 1 || events[0].Type != modules.WalletEventConfirmation ||
+		events[0].TransactionID != txid || events[0].ConfirmationHeight != height {
+		t.Fatal("expected a confirmation event, got", events)
+	}
+
+	// The sending wallet should report the confirmation of its transactions
+	// and the outputs that they spent.
+	var confirmed, spent int
+	for _, e := range wt.wallet.Events(since, cancel) {
+		switch e.Type {
+		case modules.WalletEventConfirmation:
+			confirmed++
+		case modules.WalletEventSpent:
+			if e.Value.IsZero() || e.ConfirmationHeight != height {
+				t.Error("wrong spent event:", e)
+			}
+			spent++
+		default:
+			t.Error("unexpected event:", e)
+		}
+	}
+	if confirmed != len(txns) || spent == 0 {
+		t.Fatalf("expected %v confirmations and some spent outputs, got %v and %v", len(txns), confirmed, spent)
+	}
+}
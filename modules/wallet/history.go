@@ -0,0 +1,122 @@
+package wallet
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+// snapshotDay returns the start of the day containing the timestamp.
+func snapshotDay(ts types.Timestamp) types.Timestamp {
+	return ts - ts%modules.SnapshotPeriod
+}
+
+// dbBalanceSnapshot returns a snapshot of the wallet's current confirmed
+// balances for the given day.
+func dbBalanceSnapshot(tx *bolt.Tx, day types.Timestamp) (modules.BalanceSnapshot, error) {
+	snapshot := modules.BalanceSnapshot{Timestamp: day}
+	height, err := dbGetConsensusHeight(tx)
+	if err != nil {
+		return modules.BalanceSnapshot{}, err
+	}
+	snapshot.Height = height
+	siafundPool, err := dbGetSiafundPool(tx)
+	if err != nil {
+		return modules.BalanceSnapshot{}, err
+	}
+	err = dbForEachSiacoinOutput(tx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		snapshot.ConfirmedSiacoinBalance = snapshot.ConfirmedSiacoinBalance.Add(sco.Value)
+	})
+	if err != nil {
+		return modules.BalanceSnapshot{}, err
+	}
+	err = dbForEachSiafundOutput(tx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		snapshot.SiafundBalance = snapshot.SiafundBalance.Add(sfo.Value)
+		if sfo.ClaimStart.Cmp(siafundPool) <= 0 {
+			snapshot.SiacoinClaimBalance = snapshot.SiacoinClaimBalance.Add(siafundPool.Sub(sfo.ClaimStart).Mul(sfo.Value).Div(types.SiafundCount))
+		}
+	})
+	if err != nil {
+		return modules.BalanceSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// dbPutBalanceSnapshotIfFunded stores a snapshot of the wallet's current
+// balances for the given day. Empty snapshots are skipped until the wallet
+// has received funds, so that a wallet does not store a snapshot for every
+// day since the genesis block.
+func dbPutBalanceSnapshotIfFunded(tx *bolt.Tx, day types.Timestamp) error {
+	snapshot, err := dbBalanceSnapshot(tx, day)
+	if err != nil {
+		return err
+	}
+	empty := snapshot.ConfirmedSiacoinBalance.IsZero() && snapshot.SiafundBalance.IsZero()
+	if k, _ := tx.Bucket(bucketBalanceHistory).Cursor().First(); empty && k == nil {
+		return nil
+	}
+	return dbPutBalanceSnapshot(tx, snapshot)
+}
+
+// closeBalanceDay is called before a consensus change is applied. If the
+// change moves the wallet into a new day, the balances at the end of the
+// previous day are recorded.
+func (w *Wallet) closeBalanceDay(tx *bolt.Tx, cc modules.ConsensusChange) error {
+	if len(cc.AppliedBlocks) == 0 {
+		return nil
+	}
+	day, err := dbGetBalanceHistoryDay(tx)
+	if err == errNoKey {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if snapshotDay(cc.AppliedBlocks[len(cc.AppliedBlocks)-1].Timestamp) <= day {
+		return nil
+	}
+	return dbPutBalanceSnapshotIfFunded(tx, day)
+}
+
+// openBalanceDay is called after a consensus change is applied. It records
+// the day of the most recent block, and updates the snapshot of that day if
+// the wallet is synced, so that the current day is always included in the
+// history.
+func (w *Wallet) openBalanceDay(tx *bolt.Tx, cc modules.ConsensusChange) error {
+	if len(cc.AppliedBlocks) == 0 {
+		return nil
+	}
+	day := snapshotDay(cc.AppliedBlocks[len(cc.AppliedBlocks)-1].Timestamp)
+	if err := dbPutBalanceHistoryDay(tx, day); err != nil {
+		return err
+	}
+	if !cc.Synced {
+		return nil
+	}
+	return dbPutBalanceSnapshotIfFunded(tx, day)
+}
+
+// BalanceHistory returns a snapshot of the wallet's confirmed balances for
+// every day since the wallet first received funds, oldest first.
+func (w *Wallet) BalanceHistory() ([]modules.BalanceSnapshot, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var history []modules.BalanceSnapshot
+	err := dbForEachBalanceSnapshot(w.dbTx, func(_ types.Timestamp, snapshot modules.BalanceSnapshot) {
+		history = append(history, snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Timestamp < history[j].Timestamp
+	})
+	return history, nil
+}
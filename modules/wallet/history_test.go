@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestBalanceHistory checks that the wallet records its balances at the end
+// of every day.
+func TestBalanceHistory(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// The wallet was funded by the blocks mined today. Snapshots of the
+	// current day are only taken once the wallet is synced.
+	if err := wt.waitForSync(); err != nil {
+		t.Fatal(err)
+	}
+	wt.addBlockNoPayout()
+	history, err := wt.wallet.BalanceHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := snapshotDay(types.CurrentTimestamp())
+	n := len(history)
+	if n == 0 {
+		t.Fatal("expected a snapshot for today")
+	}
+	snapshot := history[n-1]
+	if snapshot.Timestamp != today || snapshot.Height != wt.cs.Height() || snapshot.ConfirmedSiacoinBalance.IsZero() {
+		t.Fatal("wrong snapshot for today:", snapshot)
+	}
+
+	// Spending money should update today's snapshot. The new block also
+	// matures a miner payout, so only the change in balance is checked.
+	value := types.SiacoinPrecision.Mul64(1e3)
+	if _, err := wt.wallet.SendSiacoins(value, types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+	wt.addBlockNoPayout()
+	history, err = wt.wallet.BalanceHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != n || history[n-1].Height != snapshot.Height+1 || history[n-1].ConfirmedSiacoinBalance.Equals(snapshot.ConfirmedSiacoinBalance) {
+		t.Fatal("today's snapshot was not updated:", history[n-1])
+	}
+	snapshot = history[n-1]
+
+	// Simulate a change that moves the wallet into the next day while it is
+	// catching up with the blockchain. Today's snapshot should be kept, and
+	// no snapshot should be recorded for tomorrow until the wallet is synced.
+	tomorrow := today + modules.SnapshotPeriod
+	cc := modules.ConsensusChange{
+		AppliedBlocks: []types.Block{{Timestamp: tomorrow + 10}},
+	}
+	wt.wallet.mu.Lock()
+	if err := wt.wallet.closeBalanceDay(wt.wallet.dbTx, cc); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.openBalanceDay(wt.wallet.dbTx, cc); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.Unlock()
+	history, err = wt.wallet.BalanceHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != n || history[n-1].Height != snapshot.Height || !history[n-1].ConfirmedSiacoinBalance.Equals(snapshot.ConfirmedSiacoinBalance) {
+		t.Fatal("today's snapshot should not have changed:", history)
+	}
+
+	cc.Synced = true
+	wt.wallet.mu.Lock()
+	if err := wt.wallet.openBalanceDay(wt.wallet.dbTx, cc); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.Unlock()
+	history, err = wt.wallet.BalanceHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != n+1 || history[n].Timestamp != tomorrow || !history[n].ConfirmedSiacoinBalance.Equals(snapshot.ConfirmedSiacoinBalance) {
+		t.Fatal("expected a snapshot for tomorrow, got", history)
+	}
+}
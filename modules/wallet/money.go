@@ -1,11 +1,18 @@
 package wallet
 
 import (
+	"errors"
+
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
+var (
+	errNoSiafundOutputs  = errors.New("no siafund outputs were specified")
+	errZeroSiafundOutput = errors.New("cannot send zero siafunds to an output")
+)
+
 // sortedOutputs is a struct containing a slice of siacoin outputs and their
 // corresponding ids. sortedOutputs can be sorted using the sort package.
 type sortedOutputs struct {
@@ -54,6 +61,59 @@ func (w *Wallet) ConfirmedBalance() (siacoinBalance types.Currency, siafundBalan
 	return
 }
 
+// AddressBalances returns the confirmed balances of the given addresses and
+// the totals they have received. Unlike ConfirmedBalance, dust is included in
+// the siacoin balances. The received totals include every confirmed output
+// sent to an address, including change and miner payouts.
+func (w *Wallet) AddressBalances(addrs []types.UnlockHash) ([]modules.AddressBalance, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	balances := make([]modules.AddressBalance, len(addrs))
+	indices := make(map[types.UnlockHash]int, len(addrs))
+	for i, addr := range addrs {
+		balances[i].Address = addr
+		indices[addr] = i
+	}
+	err := dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if i, ok := indices[sco.UnlockHash]; ok {
+			balances[i].SiacoinBalance = balances[i].SiacoinBalance.Add(sco.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		if i, ok := indices[sfo.UnlockHash]; ok {
+			balances[i].SiafundBalance = balances[i].SiafundBalance.Add(sfo.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = dbForEachProcessedTransaction(w.dbTx, func(pt modules.ProcessedTransaction) {
+		for _, po := range pt.Outputs {
+			i, ok := indices[po.RelatedAddress]
+			if !ok || po.FundType == types.SpecifierMinerFee {
+				continue
+			}
+			if po.FundType == types.SpecifierSiafundOutput {
+				balances[i].SiafundsReceived = balances[i].SiafundsReceived.Add(po.Value)
+			} else {
+				balances[i].SiacoinsReceived = balances[i].SiacoinsReceived.Add(po.Value)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
 // UnconfirmedBalance returns the number of outgoing and incoming siacoins in
 // the unconfirmed transaction set. Refund outputs are included in this
 // reporting.
@@ -81,7 +141,7 @@ func (w *Wallet) UnconfirmedBalance() (outgoingSiacoins types.Currency, incoming
 
 // SendSiacoins creates a transaction sending 'amount' to 'dest'. The transaction
 // is submitted to the transaction pool and is also returned.
-func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
+func (w walletSender) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
 	if err := w.tg.Add(); err != nil {
 		return nil, err
 	}
@@ -97,6 +157,10 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]t
 		Value:      amount,
 		UnlockHash: dest,
 	}
+	if err := w.managedCheckDustOutputs([]types.SiacoinOutput{output}); err != nil {
+		w.log.Println("Attempt to send coins has failed:", err)
+		return nil, err
+	}
 
 	txnBuilder := w.StartTransaction()
 	err := txnBuilder.FundSiacoins(amount.Add(tpoolFee))
@@ -111,22 +175,125 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]t
 		w.log.Println("Attempt to send coins has failed - failed to sign transaction:", err)
 		return nil, build.ExtendErr("unable to sign transaction", err)
 	}
-	err = w.tpool.AcceptTransactionSet(txnSet)
+	err = w.submit(txnBuilder, txnSet)
 	if err != nil {
 		w.log.Println("Attempt to send coins has failed - transaction pool rejected transaction:", err)
 		return nil, build.ExtendErr("unable to get transaction accepted", err)
 	}
-	w.log.Println("Submitted a siacoin transfer transaction set for value", amount.HumanString(), "with fees", tpoolFee.HumanString(), "IDs:")
-	for _, txn := range txnSet {
-		w.log.Println("\t", txn.ID())
+	if !w.dryRun {
+		w.log.Println("Submitted a siacoin transfer transaction set for value", amount.HumanString(), "with fees", tpoolFee.HumanString(), "IDs:")
+		for _, txn := range txnSet {
+			w.log.Println("\t", txn.ID())
+		}
 	}
 	return txnSet, nil
 }
 
+// managedSpendableSiacoins returns the total value and number of the siacoin
+// outputs that FundSiacoins would be able to spend.
+func (w *Wallet) managedSpendableSiacoins() (fund types.Currency, inputs int, err error) {
+	// dustThreshold has to be obtained separate from the lock
+	dustThreshold := w.managedDustThreshold()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.ZeroCurrency, 0, err
+	}
+	settings, err := dbGetWalletSettings(w.dbTx)
+	if err != nil {
+		return types.ZeroCurrency, 0, err
+	}
+	dustThreshold = inputDustThreshold(settings, dustThreshold)
+	var heights map[types.SiacoinOutputID]types.BlockHeight
+	if settings.MinConfirmations > 1 {
+		heights, err = dbSiacoinOutputHeights(w.dbTx)
+		if err != nil {
+			return types.ZeroCurrency, 0, err
+		}
+	}
+
+	spend := func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if w.checkOutput(w.dbTx, consensusHeight, scoid, sco, dustThreshold) == nil {
+			fund = fund.Add(sco.Value)
+			inputs++
+		}
+	}
+	err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if confirmations(consensusHeight, heights[scoid]) >= settings.MinConfirmations {
+			spend(scoid, sco)
+		}
+	})
+	if err != nil {
+		return types.ZeroCurrency, 0, err
+	}
+	if !settings.ConfirmedInputsOnly && settings.MinConfirmations == 0 {
+		for _, upt := range w.unconfirmedProcessedTransactions {
+			for i, sco := range upt.Transaction.SiacoinOutputs {
+				if _, exists := w.keys[sco.UnlockHash]; exists {
+					spend(upt.Transaction.SiacoinOutputID(uint64(i)), sco)
+				}
+			}
+		}
+	}
+	return fund, inputs, nil
+}
+
+// SendAllSiacoins creates a transaction that sends the entire spendable
+// balance of the wallet to 'dest', minus the miner fee, leaving no change
+// behind. The transaction is submitted to the transaction pool and is also
+// returned, along with the amount that was sent.
+func (w walletSender) SendAllSiacoins(dest types.UnlockHash) ([]types.Transaction, types.Currency, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, types.ZeroCurrency, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		w.log.Println("Attempt to send coins has failed - wallet is locked")
+		return nil, types.ZeroCurrency, modules.ErrLockedWallet
+	}
+
+	fund, inputs, err := w.managedSpendableSiacoins()
+	if err != nil {
+		return nil, types.ZeroCurrency, err
+	}
+	// Every output of the wallet is spent by the parent transaction, so the
+	// size of the transaction set grows with the number of inputs.
+	_, tpoolFee := w.tpool.FeeEstimation()
+	tpoolFee = tpoolFee.Mul64(750 + 250*uint64(inputs)) // Estimated transaction size in bytes
+	if fund.Cmp(tpoolFee) <= 0 {
+		return nil, types.ZeroCurrency, modules.ErrLowBalance
+	}
+	amount := fund.Sub(tpoolFee)
+	output := types.SiacoinOutput{
+		Value:      amount,
+		UnlockHash: dest,
+	}
+	if err := w.managedCheckDustOutputs([]types.SiacoinOutput{output}); err != nil {
+		w.log.Println("Attempt to send coins has failed:", err)
+		return nil, types.ZeroCurrency, err
+	}
+
+	txnSet, err := w.managedSendOutputs([]types.SiacoinOutput{output}, tpoolFee, nil)
+	if err != nil {
+		w.log.Println("Attempt to send all coins has failed:", err)
+		return nil, types.ZeroCurrency, err
+	}
+	if !w.dryRun {
+		w.log.Println("Submitted a transaction set sending the entire balance of", amount.HumanString(), "with fees", tpoolFee.HumanString(), "IDs:")
+		for _, txn := range txnSet {
+			w.log.Println("\t", txn.ID())
+		}
+	}
+	return txnSet, amount, nil
+}
+
 // SendSiacoinsMulti creates a transaction that includes the specified
 // outputs. The transaction is submitted to the transaction pool and is also
 // returned.
-func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error) {
+func (w walletSender) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error) {
 	if err := w.tg.Add(); err != nil {
 		return nil, err
 	}
@@ -135,20 +302,61 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Trans
 		w.log.Println("Attempt to send coins has failed - wallet is locked")
 		return nil, modules.ErrLockedWallet
 	}
-
-	txnBuilder := w.StartTransaction()
+	if err := w.managedCheckDustOutputs(outputs); err != nil {
+		w.log.Println("Attempt to send coins has failed:", err)
+		return nil, err
+	}
 
 	// Add estimated transaction fee.
 	_, tpoolFee := w.tpool.FeeEstimation()
 	tpoolFee = tpoolFee.Mul64(2)                              // We don't want send-to-many transactions to fail.
 	tpoolFee = tpoolFee.Mul64(1000 + 60*uint64(len(outputs))) // Estimated transaction size in bytes
-	txnBuilder.AddMinerFee(tpoolFee)
+	return w.managedSendOutputs(outputs, tpoolFee, nil)
+}
+
+// SendSiacoinsMemo creates a transaction that includes the specified outputs
+// and carries the provided memo, such as an order ID or an invoice reference.
+// The memo is stored in the blockchain and is visible to anyone. The
+// transaction is submitted to the transaction pool and is also returned.
+func (w walletSender) SendSiacoinsMemo(outputs []types.SiacoinOutput, memo []byte) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		w.log.Println("Attempt to send coins has failed - wallet is locked")
+		return nil, modules.ErrLockedWallet
+	}
+	if len(memo) > modules.MaxMemoSize {
+		return nil, modules.ErrMemoTooLarge
+	}
+	if err := w.managedCheckDustOutputs(outputs); err != nil {
+		w.log.Println("Attempt to send coins has failed:", err)
+		return nil, err
+	}
+
+	_, tpoolFee := w.tpool.FeeEstimation()
+	tpoolFee = tpoolFee.Mul64(2)                                                  // We don't want send-to-many transactions to fail.
+	tpoolFee = tpoolFee.Mul64(1000 + 60*uint64(len(outputs)) + uint64(len(memo))) // Estimated transaction size in bytes
+	return w.managedSendOutputs(outputs, tpoolFee, memo)
+}
+
+// managedSendOutputs creates a transaction that includes the specified
+// outputs and pays the specified miner fee. A non-empty memo is attached to
+// the transaction. The transaction is submitted to the transaction pool and is
+// also returned.
+func (w walletSender) managedSendOutputs(outputs []types.SiacoinOutput, fee types.Currency, memo []byte) ([]types.Transaction, error) {
+	txnBuilder := w.StartTransaction()
+	txnBuilder.AddMinerFee(fee)
+	if len(memo) > 0 {
+		txnBuilder.AddArbitraryData(modules.MemoArbitraryData(memo))
+	}
 
 	// Calculate total cost to wallet.
 	// NOTE: we only want to call FundSiacoins once; that way, it will
 	// (ideally) fund the entire transaction with a single input, instead of
 	// many smaller ones.
-	totalCost := tpoolFee
+	totalCost := fee
 	for _, sco := range outputs {
 		totalCost = totalCost.Add(sco.Value)
 	}
@@ -166,7 +374,7 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Trans
 		w.log.Println("Attempt to send coins has failed - failed to sign transaction:", err)
 		return nil, build.ExtendErr("unable to sign transaction", err)
 	}
-	err = w.tpool.AcceptTransactionSet(txnSet)
+	err = w.submit(txnBuilder, txnSet)
 	if err != nil {
 		w.log.Println("Attempt to send coins has failed - transaction pool rejected transaction:", err)
 		return nil, build.ExtendErr("unable to get transaction accepted", err)
@@ -176,7 +384,7 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Trans
 
 // SendSiafunds creates a transaction sending 'amount' to 'dest'. The transaction
 // is submitted to the transaction pool and is also returned.
-func (w *Wallet) SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
+func (w walletSender) SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
 	if err := w.tg.Add(); err != nil {
 		return nil, err
 	}
@@ -185,9 +393,7 @@ func (w *Wallet) SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]t
 		return nil, modules.ErrLockedWallet
 	}
 
-	_, tpoolFee := w.tpool.FeeEstimation()
-	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
-	tpoolFee = tpoolFee.Mul64(5)   // use large fee to ensure siafund transactions are selected by miners
+	tpoolFee := w.siafundTransactionFee()
 	output := types.SiafundOutput{
 		Value:      amount,
 		UnlockHash: dest,
@@ -208,17 +414,88 @@ func (w *Wallet) SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]t
 	if err != nil {
 		return nil, err
 	}
-	err = w.tpool.AcceptTransactionSet(txnSet)
+	err = w.submit(txnBuilder, txnSet)
 	if err != nil {
 		return nil, err
 	}
-	w.log.Println("Submitted a siafund transfer transaction set for value", amount.HumanString(), "with fees", tpoolFee.HumanString(), "IDs:")
-	for _, txn := range txnSet {
-		w.log.Println("\t", txn.ID())
+	if !w.dryRun {
+		w.log.Println("Submitted a siafund transfer transaction set for value", amount.HumanString(), "with fees", tpoolFee.HumanString(), "IDs:")
+		for _, txn := range txnSet {
+			w.log.Println("\t", txn.ID())
+		}
 	}
 	return txnSet, nil
 }
 
+// SendSiafundsMulti creates a transaction sending siafunds to each of the
+// specified outputs. Siafund outputs are spent only as far as needed and the
+// remaining siafunds are returned to the wallet. The siacoin claims of the
+// spent siafunds are paid to the wallet. The transaction is submitted to the
+// transaction pool and is also returned.
+func (w walletSender) SendSiafundsMulti(outputs []types.SiafundOutput) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+	if len(outputs) == 0 {
+		return nil, errNoSiafundOutputs
+	}
+	var amount types.Currency
+	for _, sfo := range outputs {
+		if sfo.Value.IsZero() {
+			return nil, errZeroSiafundOutput
+		}
+		amount = amount.Add(sfo.Value)
+	}
+
+	// Add estimated transaction fee.
+	_, tpoolFee := w.tpool.FeeEstimation()
+	tpoolFee = tpoolFee.Mul64(1000 + 60*uint64(len(outputs))) // Estimated transaction size in bytes
+	tpoolFee = tpoolFee.Mul64(5)                              // use large fee to ensure siafund transactions are selected by miners
+
+	txnBuilder := w.StartTransaction()
+	err := txnBuilder.FundSiacoins(tpoolFee)
+	if err != nil {
+		txnBuilder.Drop()
+		return nil, err
+	}
+	err = txnBuilder.FundSiafunds(amount)
+	if err != nil {
+		txnBuilder.Drop()
+		return nil, err
+	}
+	txnBuilder.AddMinerFee(tpoolFee)
+	for _, sfo := range outputs {
+		txnBuilder.AddSiafundOutput(sfo)
+	}
+	txnSet, err := txnBuilder.Sign(true)
+	if err != nil {
+		return nil, err
+	}
+	err = w.submit(txnBuilder, txnSet)
+	if err != nil {
+		return nil, err
+	}
+	if !w.dryRun {
+		w.log.Println("Submitted a siafund transfer transaction set to", len(outputs), "outputs for value", amount.HumanString(), "with fees", tpoolFee.HumanString(), "IDs:")
+		for _, txn := range txnSet {
+			w.log.Println("\t", txn.ID())
+		}
+	}
+	return txnSet, nil
+}
+
+// siafundTransactionFee returns the miner fee paid by siafund transactions.
+func (w *Wallet) siafundTransactionFee() types.Currency {
+	_, tpoolFee := w.tpool.FeeEstimation()
+	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
+	tpoolFee = tpoolFee.Mul64(5)   // use large fee to ensure siafund transactions are selected by miners
+	return tpoolFee
+}
+
 // Len returns the number of elements in the sortedOutputs struct.
 func (so sortedOutputs) Len() int {
 	if build.DEBUG && len(so.ids) != len(so.outputs) {
@@ -4,6 +4,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -183,3 +184,188 @@ func TestIntegrationSortedOutputsSorting(t *testing.T) {
 		}
 	}
 }
+
+// TestAddressBalances checks that the wallet reports the balance and the
+// total received of individual addresses.
+func TestAddressBalances(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := uc.UnlockHash()
+	value := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(value, addr); err != nil {
+		t.Fatal(err)
+	}
+	wt.addBlockNoPayout()
+
+	balances, err := wt.wallet.AddressBalances([]types.UnlockHash{addr, {1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 {
+		t.Fatal("expected two balances, got", len(balances))
+	}
+	b := balances[0]
+	if b.Address != addr || !b.SiacoinBalance.Equals(value) || !b.SiacoinsReceived.Equals(value) ||
+		!b.SiafundBalance.IsZero() || !b.SiafundsReceived.IsZero() {
+		t.Fatal("wrong balance for the new address:", b)
+	}
+	if b := balances[1]; !b.SiacoinBalance.IsZero() || !b.SiacoinsReceived.IsZero() {
+		t.Fatal("an address not owned by the wallet should have no balance:", b)
+	}
+
+	// The balances of all addresses should add up to the wallet's balance.
+	balances, err = wt.wallet.AddressBalances(wt.wallet.AllAddresses())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total types.Currency
+	for _, b := range balances {
+		total = total.Add(b.SiacoinBalance)
+	}
+	if confirmed, _, _ := wt.wallet.ConfirmedBalance(); !total.Equals(confirmed) {
+		t.Fatalf("address balances add up to %v, but the wallet has %v", total, confirmed)
+	}
+}
+
+// TestSendSiacoinsMemo checks that a memo attached to a send is stored in the
+// transaction, and that it is kept when the fee of the send is bumped.
+func TestSendSiacoinsMemo(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	outputs := []types.SiacoinOutput{{Value: types.SiacoinPrecision, UnlockHash: types.UnlockHash{1}}}
+	if _, err := wt.wallet.SendSiacoinsMemo(outputs, make([]byte, modules.MaxMemoSize+1)); err != modules.ErrMemoTooLarge {
+		t.Fatal("expected ErrMemoTooLarge, got", err)
+	}
+	txns, err := wt.wallet.SendSiacoinsMemo(outputs, []byte("order 42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := txns[len(txns)-1]
+	if memo, ok := modules.TransactionMemo(send); !ok || string(memo) != "order 42" {
+		t.Fatalf("send carries the wrong memo: %q", memo)
+	}
+
+	bumped, err := wt.wallet.BumpTransactionFee(send.ID(), types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memo, ok := modules.TransactionMemo(bumped[len(bumped)-1]); !ok || string(memo) != "order 42" {
+		t.Fatalf("replacement carries the wrong memo: %q", memo)
+	}
+
+	// The memo should be part of the confirmed transaction.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	pt, exists := wt.wallet.Transaction(bumped[len(bumped)-1].ID())
+	if !exists {
+		t.Fatal("send was not confirmed")
+	}
+	if memo, _ := modules.TransactionMemo(pt.Transaction); string(memo) != "order 42" {
+		t.Fatalf("confirmed transaction carries the wrong memo: %q", memo)
+	}
+}
+
+// TestSendAllSiacoins checks that SendAllSiacoins spends the entire balance of
+// the wallet without leaving change behind.
+func TestSendAllSiacoins(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	fund, _, err := wt.wallet.managedSpendableSiacoins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txns, amount, err := wt.wallet.SendAllSiacoins(types.UnlockHash{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := txns[len(txns)-1]
+	if len(txn.SiacoinOutputs) != 1 || txn.SiacoinOutputs[0].Value.Cmp(amount) != 0 {
+		t.Fatal("transaction does not send the amount to the destination:", txn.SiacoinOutputs)
+	}
+	if len(txn.MinerFees) != 1 || !amount.Add(txn.MinerFees[0]).Equals(fund) {
+		t.Fatal("amount and fee do not add up to the balance of the wallet")
+	}
+	for _, parent := range txns[:len(txns)-1] {
+		if len(parent.SiacoinOutputs) != 1 {
+			t.Fatal("parent transaction created change:", parent.SiacoinOutputs)
+		}
+	}
+
+	// Nothing should be left to spend.
+	if fund, _, err := wt.wallet.managedSpendableSiacoins(); err != nil || !fund.IsZero() {
+		t.Fatal("wallet still has spendable siacoins:", fund, err)
+	}
+	if _, _, err := wt.wallet.SendAllSiacoins(types.UnlockHash{1}); err != modules.ErrLowBalance {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+}
+
+// TestDryRunSend checks that a dry run builds the transactions of a send
+// without submitting them or spending the wallet's outputs.
+func TestDryRunSend(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	sendValue := types.SiacoinPrecision.Mul64(3)
+	txns, err := wt.wallet.DryRun().SendSiacoins(sendValue, types.UnlockHash{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) == 0 || len(txns[len(txns)-1].SiacoinInputs) == 0 || len(txns[len(txns)-1].MinerFees) == 0 {
+		t.Fatal("dry run did not build a funded transaction:", txns)
+	}
+	if len(wt.tpool.TransactionList()) != 0 {
+		t.Fatal("dry run submitted transactions to the transaction pool")
+	}
+	if out, in := wt.wallet.UnconfirmedBalance(); !out.IsZero() || !in.IsZero() {
+		t.Fatal("dry run changed the unconfirmed balance:", out, in)
+	}
+
+	// The wallet can still spend its entire balance.
+	fund, _, err := wt.wallet.managedSpendableSiacoins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fund.Equals(types.CalculateCoinbase(1)) {
+		t.Fatal("dry run left outputs marked as spent:", fund)
+	}
+	if _, err := wt.wallet.SendSiacoins(sendValue, types.UnlockHash{1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(wt.tpool.TransactionList()) == 0 {
+		t.Fatal("send did not submit transactions to the transaction pool")
+	}
+}
@@ -0,0 +1,206 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// Siafunds that are kept offline are spent in three steps. The online wallet
+// prepares a transaction that spends the siafund outputs of the offline
+// address and pays the miner fee, the siag keys sign the siafund inputs on
+// the offline machine, and the online wallet signs its own inputs and
+// broadcasts the transaction. The siag keys never need to be loaded into the
+// online wallet.
+
+var (
+	errNoSiafundInputs     = errors.New("transaction does not spend any siafunds")
+	errNoUnsignedInputs    = errors.New("transaction has no siafund inputs that the keys can sign")
+	errSiafundTxnSpends    = errors.New("siafund transaction may only pay siacoins to the wallet")
+	errUnsignedSiafundTxns = errors.New("siafund transaction contains inputs that have not been signed")
+)
+
+// managedScanSiafundOutputs scans the blockchain for the siafund outputs of
+// an address.
+func (w *Wallet) managedScanSiafundOutputs(addr types.UnlockHash) (map[types.SiafundOutputID]types.SiafundOutput, error) {
+	as := newAddressScanner(addr)
+	err := w.cs.ConsensusSetSubscribe(as, modules.ConsensusChangeBeginning, w.tg.StopChan())
+	if err != nil {
+		return nil, err
+	}
+	w.cs.Unsubscribe(as)
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	return as.siafundOutputs, nil
+}
+
+// PrepareSiafundTransaction creates a transaction that sends 'amount'
+// siafunds from the address 'source' to 'dest'. The source address does not
+// need to be known to the wallet; the blockchain is scanned for its siafund
+// outputs. The wallet funds and signs the miner fee, and receives the siacoin
+// claim of the spent siafunds, while the siafund inputs are left unsigned and
+// without unlock conditions, to be completed by SignSiagTransaction. The
+// remaining siafunds are returned to the source address. The transaction set
+// is returned without being broadcast; the siacoins that fund it are reserved
+// for a few hours.
+func (w *Wallet) PrepareSiafundTransaction(amount types.Currency, dest, source types.UnlockHash) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+	if amount.IsZero() {
+		return nil, errors.New("cannot send zero siafunds")
+	}
+
+	outputs, err := w.managedScanSiafundOutputs(source)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]types.SiafundOutputID, 0, len(outputs))
+	for id := range outputs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return outputs[ids[i]].Value.Cmp(outputs[ids[j]].Value) > 0
+	})
+	var selected []types.SiafundOutputID
+	fund := types.ZeroCurrency
+	for _, id := range ids {
+		if fund.Cmp(amount) >= 0 {
+			break
+		}
+		selected = append(selected, id)
+		fund = fund.Add(outputs[id].Value)
+	}
+	if fund.Cmp(amount) < 0 {
+		return nil, modules.ErrLowBalance
+	}
+
+	claimAddress, err := w.NextAddress()
+	if err != nil {
+		return nil, err
+	}
+	fee := w.siafundTransactionFee()
+	txnBuilder := w.StartTransaction()
+	if err := txnBuilder.FundSiacoins(fee); err != nil {
+		txnBuilder.Drop()
+		return nil, err
+	}
+	txnBuilder.AddMinerFee(fee)
+	for _, id := range selected {
+		txnBuilder.AddSiafundInput(types.SiafundInput{
+			ParentID:        id,
+			ClaimUnlockHash: claimAddress.UnlockHash(),
+		})
+	}
+	txnBuilder.AddSiafundOutput(types.SiafundOutput{Value: amount, UnlockHash: dest})
+	if change := fund.Sub(amount); !change.IsZero() {
+		txnBuilder.AddSiafundOutput(types.SiafundOutput{Value: change, UnlockHash: source})
+	}
+	txn, parents := txnBuilder.View()
+	w.log.Println("Prepared a transaction sending", amount, "siafunds from", source, "with fees", fee.HumanString())
+	return append(parents, txn), nil
+}
+
+// BroadcastSiafundTransaction signs the siacoin inputs of the wallet in a
+// siafund transaction prepared by PrepareSiafundTransaction, after its
+// siafund inputs have been signed with SignSiagTransaction, and submits the
+// transaction set to the transaction pool. To keep the call from being used
+// to spend the wallet's siacoins, the transaction may not create siacoin
+// outputs or file contracts that the wallet does not own.
+func (w *Wallet) BroadcastSiafundTransaction(txnSet []types.Transaction) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+	if len(txnSet) == 0 {
+		return nil, errNoSiafundInputs
+	}
+	txnSet = append([]types.Transaction(nil), txnSet...)
+	txn := &txnSet[len(txnSet)-1]
+	if len(txn.SiafundInputs) == 0 {
+		return nil, errNoSiafundInputs
+	}
+	if len(txn.FileContracts) != 0 || len(txn.FileContractRevisions) != 0 {
+		return nil, errSiafundTxnSpends
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if len(sfi.UnlockConditions.PublicKeys) == 0 {
+			return nil, errUnsignedSiafundTxns
+		}
+	}
+
+	w.mu.RLock()
+	for _, sco := range txn.SiacoinOutputs {
+		if _, exists := w.keys[sco.UnlockHash]; !exists {
+			w.mu.RUnlock()
+			return nil, errSiafundTxnSpends
+		}
+	}
+	signed := make(map[crypto.Hash]struct{})
+	for _, sig := range txn.TransactionSignatures {
+		signed[sig.ParentID] = struct{}{}
+	}
+	for _, sci := range txn.SiacoinInputs {
+		key, exists := w.keys[sci.UnlockConditions.UnlockHash()]
+		if _, done := signed[crypto.Hash(sci.ParentID)]; done || !exists {
+			continue
+		}
+		addSignatures(txn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), key)
+	}
+	w.mu.RUnlock()
+
+	if err := w.tpool.AcceptTransactionSet(txnSet); err != nil {
+		w.log.Println("Attempt to broadcast a siafund transaction has failed - transaction pool rejected transaction:", err)
+		return nil, build.ExtendErr("unable to get transaction accepted", err)
+	}
+	w.log.Println("Broadcast siafund transaction", txn.ID())
+	return txnSet, nil
+}
+
+// SignSiagTransaction signs the siafund inputs of a transaction prepared by
+// PrepareSiafundTransaction with a set of siag keyfiles. It does not need a
+// wallet, so that the keyfiles can stay on an offline machine. The unlock
+// conditions of the keys are filled into the siafund inputs that have none.
+func SignSiagTransaction(txn *types.Transaction, keyfiles []string) error {
+	sk, err := readSiagKeys(keyfiles)
+	if err != nil {
+		return err
+	}
+	addr := sk.UnlockConditions.UnlockHash()
+	signed := make(map[crypto.Hash]struct{})
+	for _, sig := range txn.TransactionSignatures {
+		signed[sig.ParentID] = struct{}{}
+	}
+	var inputs []types.SiafundInput
+	for i, sfi := range txn.SiafundInputs {
+		if _, done := signed[crypto.Hash(sfi.ParentID)]; done {
+			continue
+		}
+		if len(sfi.UnlockConditions.PublicKeys) == 0 {
+			txn.SiafundInputs[i].UnlockConditions = sk.UnlockConditions
+		} else if sfi.UnlockConditions.UnlockHash() != addr {
+			continue
+		}
+		inputs = append(inputs, txn.SiafundInputs[i])
+	}
+	if len(inputs) == 0 {
+		return errNoUnsignedInputs
+	}
+	// The unlock conditions are covered by the signatures, so they must all
+	// be filled in before signing.
+	for _, sfi := range inputs {
+		addSignatures(txn, types.FullCoveredFields, sfi.UnlockConditions, crypto.Hash(sfi.ParentID), sk)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestOfflineSiafundTransaction checks that siafunds of siag keys that were
+// never loaded into the wallet can be sent by preparing the transaction in
+// the wallet, signing it with the keyfiles, and broadcasting it.
+func TestOfflineSiafundTransaction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	for _, keyfiles := range [][]string{
+		{"../../types/siag0of1of1.siakey"},
+		{"../../types/siag0of2of3.siakey", "../../types/siag2of2of3.siakey"},
+	} {
+		sk, err := readSiagKeys(keyfiles)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source := sk.UnlockConditions.UnlockHash()
+		dest := types.UnlockHash{1}
+		siafundBalance := func() types.Currency {
+			outputs, err := wt.wallet.managedScanSiafundOutputs(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			balance := types.ZeroCurrency
+			for _, sfo := range outputs {
+				balance = balance.Add(sfo.Value)
+			}
+			return balance
+		}
+		initial := siafundBalance()
+
+		if _, err := wt.wallet.PrepareSiafundTransaction(types.NewCurrency64(1e6), dest, source); err != modules.ErrLowBalance {
+			t.Fatal("expected ErrLowBalance, got", err)
+		}
+		txnSet, err := wt.wallet.PrepareSiafundTransaction(types.NewCurrency64(10), dest, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.wallet.BroadcastSiafundTransaction(txnSet); err != errUnsignedSiafundTxns {
+			t.Fatal("expected errUnsignedSiafundTxns, got", err)
+		}
+		txn := &txnSet[len(txnSet)-1]
+		if err := SignSiagTransaction(txn, keyfiles); err != nil {
+			t.Fatal(err)
+		}
+		if err := SignSiagTransaction(txn, keyfiles); err != errNoUnsignedInputs {
+			t.Fatal("expected errNoUnsignedInputs when signing twice, got", err)
+		}
+
+		// The wallet refuses to sign transactions that pay siacoins to
+		// others.
+		stolen := append([]types.Transaction(nil), txnSet...)
+		stolen[len(stolen)-1].SiacoinOutputs = append(stolen[len(stolen)-1].SiacoinOutputs, types.SiacoinOutput{Value: types.SiacoinPrecision, UnlockHash: dest})
+		if _, err := wt.wallet.BroadcastSiafundTransaction(stolen); err != errSiafundTxnSpends {
+			t.Fatal("expected errSiafundTxnSpends, got", err)
+		}
+
+		if _, err := wt.wallet.BroadcastSiafundTransaction(txnSet); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.miner.AddBlock(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The change was returned to the source address.
+		if remaining := siafundBalance(); !remaining.Equals(initial.Sub(types.NewCurrency64(10))) {
+			t.Fatalf("expected %v siafunds to remain, got %v", initial.Sub(types.NewCurrency64(10)), remaining)
+		}
+		// The siag keys were never loaded into the wallet.
+		if _, siafunds, _ := wt.wallet.ConfirmedBalance(); !siafunds.IsZero() {
+			t.Fatal("wallet should not own any siafunds, has", siafunds)
+		}
+	}
+}
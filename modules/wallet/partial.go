@@ -0,0 +1,105 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var errNoPartialKeys = errors.New("wallet has no keys that can sign the partial transaction")
+
+// CreatePartialTransaction creates a partial transaction from a transaction
+// set, to be signed by the wallet and other signers. The values of the inputs
+// that spend outputs of the wallet or of the parent transactions are filled
+// in, so that signers can check what they are spending.
+func (w *Wallet) CreatePartialTransaction(txnSet []types.Transaction) (modules.PartialTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartialTransaction{}, err
+	}
+	defer w.tg.Done()
+
+	pt, err := modules.NewPartialTransaction(txnSet)
+	if err != nil {
+		return modules.PartialTransaction{}, err
+	}
+	parentOutputs := make(map[crypto.Hash]types.Currency)
+	for _, parent := range pt.Parents {
+		for i, sco := range parent.SiacoinOutputs {
+			parentOutputs[crypto.Hash(parent.SiacoinOutputID(uint64(i)))] = sco.Value
+		}
+		for i, sfo := range parent.SiafundOutputs {
+			parentOutputs[crypto.Hash(parent.SiafundOutputID(uint64(i)))] = sfo.Value
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range pt.Inputs {
+		in := &pt.Inputs[i]
+		if value, exists := parentOutputs[in.ParentID]; exists {
+			in.Value = value
+			continue
+		}
+		switch in.Type {
+		case modules.PartialInputSiacoin:
+			if sco, err := dbGetSiacoinOutput(w.dbTx, types.SiacoinOutputID(in.ParentID)); err == nil {
+				in.Value = sco.Value
+			}
+		case modules.PartialInputSiafund:
+			if sfo, err := dbGetSiafundOutput(w.dbTx, types.SiafundOutputID(in.ParentID)); err == nil {
+				in.Value = sfo.Value
+			}
+		}
+	}
+	return pt, nil
+}
+
+// SignPartialTransaction adds the wallet's signatures to a partial
+// transaction. Every input whose unlock conditions include a public key of
+// the wallet is signed, including the inputs of multisig addresses that the
+// wallet only holds some of the keys of.
+func (w *Wallet) SignPartialTransaction(pt modules.PartialTransaction) (modules.PartialTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartialTransaction{}, err
+	}
+	defer w.tg.Done()
+	if err := pt.Validate(); err != nil {
+		return modules.PartialTransaction{}, err
+	}
+
+	w.mu.RLock()
+	if !w.unlocked {
+		w.mu.RUnlock()
+		return modules.PartialTransaction{}, modules.ErrLockedWallet
+	}
+	var keys []crypto.SecretKey
+	for _, sk := range w.keys {
+		keys = append(keys, sk.SecretKeys...)
+	}
+	w.mu.RUnlock()
+
+	// Copy the inputs so that the caller's partial transaction is left
+	// untouched.
+	pt.Inputs = append([]modules.PartialInput(nil), pt.Inputs...)
+	for i := range pt.Inputs {
+		pt.Inputs[i].Signatures = append([]modules.PartialSignature(nil), pt.Inputs[i].Signatures...)
+	}
+	if pt.Sign(keys) == 0 {
+		return modules.PartialTransaction{}, errNoPartialKeys
+	}
+	return pt, nil
+}
+
+// FinalizePartialTransaction moves the collected signatures of a complete
+// partial transaction into its transaction, and returns the transaction set,
+// which is checked to be valid at the current height. The transaction set is
+// not broadcast.
+func (w *Wallet) FinalizePartialTransaction(pt modules.PartialTransaction) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	return modules.FinalizePartialTransaction(pt, w.cs.Height())
+}
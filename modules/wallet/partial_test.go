@@ -0,0 +1,65 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestPartialTransaction checks that the wallet can create, sign and finalize
+// a partial transaction that spends its outputs.
+func TestPartialTransaction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Build a transaction without signing it.
+	amount := types.SiacoinPrecision.Mul64(100)
+	txnBuilder := wt.wallet.StartTransaction()
+	if err := txnBuilder.FundSiacoins(amount); err != nil {
+		t.Fatal(err)
+	}
+	txnBuilder.AddSiacoinOutput(types.SiacoinOutput{Value: amount, UnlockHash: types.UnlockHash{1}})
+	txn, parents := txnBuilder.View()
+	defer txnBuilder.Drop()
+
+	pt, err := wt.wallet.CreatePartialTransaction(append(parents, txn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range pt.Inputs {
+		if in.Value.IsZero() {
+			t.Fatal("value of a wallet input was not filled in")
+		}
+	}
+	if pt.Complete() {
+		t.Fatal("unsigned partial transaction is complete")
+	}
+	if _, err := wt.wallet.FinalizePartialTransaction(pt); err == nil {
+		t.Fatal("unsigned partial transaction was finalized")
+	}
+
+	signed, err := wt.wallet.SignPartialTransaction(pt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signed.Complete() || pt.Inputs[0].Signatures != nil {
+		t.Fatal("partial transaction was not signed, or the original was modified")
+	}
+	if _, err := wt.wallet.SignPartialTransaction(signed); err != errNoPartialKeys {
+		t.Fatal("expected errNoPartialKeys when signing twice, got", err)
+	}
+	txns, err := wt.wallet.FinalizePartialTransaction(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(txns); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -191,27 +191,3 @@ func (w *Wallet) convertPersistFrom112To120(dbFilename, compatFilename string) e
 	w.encrypted = true
 	return err
 }
-
-/*
-// LoadBackup loads a backup file from the provided filepath. The backup file
-// primary seed is loaded as an auxiliary seed.
-func (w *Wallet) LoadBackup(masterKey, backupMasterKey crypto.TwofishKey, backupFilepath string) error {
-	if err := w.tg.Add(); err != nil {
-		return err
-	}
-	defer w.tg.Done()
-
-	lockID := w.mu.Lock()
-	defer w.mu.Unlock(lockID)
-
-	// Load all of the seed files, check for duplicates, re-encrypt them (but
-	// keep the UID), and add them to the walletPersist object)
-	var backupPersist walletPersist
-	err := persist.LoadFile(settingsMetadata, &backupPersist, backupFilepath)
-	if err != nil {
-		return err
-	}
-	backupSeeds := append(backupPersist.AuxiliarySeedFiles, backupPersist.PrimarySeedFile)
-	TODO: more
-}
-*/
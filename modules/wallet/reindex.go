@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// Reindex discards the wallet's outputs, transaction history and balance
+// history, and rebuilds them by rescanning the blockchain. Keys, seeds,
+// settings and transaction labels are kept. Unlike LoadSeed and InitFromSeed,
+// no keys are generated, so the rescan only finds the outputs of addresses
+// that the wallet already knows.
+func (w *Wallet) Reindex() error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	if !w.scanLock.TryLock() {
+		return errScanInProgress
+	}
+	defer w.scanLock.Unlock()
+
+	err := func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+
+		// delete the state that is derived from the blockchain; it will be
+		// recreated when we rescan
+		for _, bucket := range [][]byte{
+			bucketBalanceHistory,
+			bucketProcessedTransactions,
+			bucketSiacoinOutputs,
+			bucketSiafundOutputs,
+		} {
+			if err := w.dbTx.DeleteBucket(bucket); err != nil {
+				return err
+			}
+			if _, err := w.dbTx.CreateBucket(bucket); err != nil {
+				return err
+			}
+		}
+		wb := w.dbTx.Bucket(bucketWallet)
+		if err := wb.Delete(keyBalanceHistoryDay); err != nil {
+			return err
+		}
+		if err := wb.Put(keySiafundPool, encoding.Marshal(types.ZeroCurrency)); err != nil {
+			return err
+		}
+		w.unconfirmedProcessedTransactions = nil
+		w.unconfirmedSets = make(map[modules.TransactionSetID][]types.TransactionID)
+
+		// reset the consensus change ID and height in preparation for rescan
+		err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
+		if err != nil {
+			return err
+		}
+		return dbPutConsensusHeight(w.dbTx, 0)
+	}()
+	if err != nil {
+		return err
+	}
+	w.log.Println("INFO: reindexing wallet")
+
+	// rescan the blockchain
+	w.cs.Unsubscribe(w)
+	w.tpool.Unsubscribe(w)
+
+	done := make(chan struct{})
+	go w.rescanMessage(done)
+	defer close(done)
+
+	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
+	if err != nil {
+		return err
+	}
+	w.tpool.TransactionPoolSubscribe(w)
+	return nil
+}
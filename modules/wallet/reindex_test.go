@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestReindex checks that reindexing the wallet rebuilds its balance and
+// transaction history, while keeping its labels and unconfirmed transactions.
+func TestReindex(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendID := txns[len(txns)-1].ID()
+	if err := wt.wallet.SetTransactionLabel(sendID, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	balance, _, _ := wt.wallet.ConfirmedBalance()
+	confirmed, err := wt.wallet.Transactions(0, wt.cs.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wipe the wallet's outputs, so that the reindex must restore them.
+	wt.wallet.mu.Lock()
+	if err := wt.wallet.dbTx.DeleteBucket(bucketSiacoinOutputs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.dbTx.CreateBucket(bucketSiacoinOutputs); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.Unlock()
+	if b, _, _ := wt.wallet.ConfirmedBalance(); !b.IsZero() {
+		t.Fatal("balance should be zero after wiping the outputs:", b)
+	}
+
+	if err := wt.wallet.Reindex(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _, _ := wt.wallet.ConfirmedBalance(); !b.Equals(balance) {
+		t.Fatalf("balance was not restored: expected %v, got %v", balance, b)
+	}
+	reindexed, err := wt.wallet.Transactions(0, wt.cs.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reindexed) != len(confirmed) {
+		t.Fatalf("expected %v confirmed transactions, got %v", len(confirmed), len(reindexed))
+	}
+	found := false
+	for _, pt := range wt.wallet.UnconfirmedTransactions() {
+		if pt.TransactionID == sendID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("unconfirmed send is missing after reindex")
+	}
+	if label := wt.wallet.TransactionLabels()[sendID]; label != "foo" {
+		t.Fatal("label was not kept:", label)
+	}
+}
@@ -0,0 +1,260 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	errNotUnconfirmed    = errors.New("transaction is not an unconfirmed transaction of the wallet")
+	errNotReplaceable    = errors.New("only siacoin transfers funded by the wallet can be replaced")
+	errLowReplacementFee = errors.New("replacement fee must be higher than the fees of the original transaction")
+)
+
+// A replacement describes the transactions that are replaced when an
+// unconfirmed transaction is bumped or cancelled.
+type replacement struct {
+	// inputs are the inputs spent by the replaced transactions that were not
+	// created by the replaced transactions. They are all owned by the wallet.
+	inputs []types.SiacoinInput
+
+	// payments are the outputs of the replaced transactions that are not
+	// owned by the wallet.
+	payments []types.SiacoinOutput
+
+	// value is the total value of the inputs, and fees is the total miner fee
+	// paid by the replaced transactions.
+	value types.Currency
+	fees  types.Currency
+
+	// parents are the unconfirmed transactions that are not replaced, but
+	// that the replacement depends on.
+	parents []types.Transaction
+}
+
+// isSiacoinTransfer returns true if the transaction only spends and creates
+// siacoin outputs.
+func isSiacoinTransfer(t types.Transaction) bool {
+	return len(t.FileContracts) == 0 && len(t.FileContractRevisions) == 0 &&
+		len(t.StorageProofs) == 0 && len(t.SiafundInputs) == 0 && len(t.SiafundOutputs) == 0
+}
+
+// buildReplacement determines which of the transactions in a set are
+// replaced along with txn. The replaced transactions are txn itself and the
+// parents that only create outputs for the wallet, such as the parents that
+// the wallet creates to fund a transaction with an exact input.
+func (w *Wallet) buildReplacement(txn types.Transaction, parents []types.Transaction) (r replacement, err error) {
+	ownsOutputs := func(t types.Transaction) bool {
+		if !isSiacoinTransfer(t) || len(t.SiacoinOutputs) == 0 {
+			return false
+		}
+		for _, sco := range t.SiacoinOutputs {
+			if _, exists := w.keys[sco.UnlockHash]; !exists {
+				return false
+			}
+		}
+		return true
+	}
+	creates := func(t types.Transaction, ids map[types.SiacoinOutputID]struct{}) bool {
+		for i := range t.SiacoinOutputs {
+			if _, exists := ids[t.SiacoinOutputID(uint64(i))]; exists {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Walk the parents backwards, so that the children of a parent are
+	// classified before the parent itself. A parent that is needed by a kept
+	// parent must be kept as well.
+	group := []types.Transaction{txn}
+	groupSpends := make(map[types.SiacoinOutputID]struct{})
+	keptSpends := make(map[types.SiacoinOutputID]struct{})
+	for _, sci := range txn.SiacoinInputs {
+		groupSpends[sci.ParentID] = struct{}{}
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		p := parents[i]
+		if creates(p, groupSpends) && !creates(p, keptSpends) && ownsOutputs(p) {
+			group = append([]types.Transaction{p}, group...)
+			for _, sci := range p.SiacoinInputs {
+				groupSpends[sci.ParentID] = struct{}{}
+			}
+			continue
+		}
+		r.parents = append([]types.Transaction{p}, r.parents...)
+		for _, sci := range p.SiacoinInputs {
+			keptSpends[sci.ParentID] = struct{}{}
+		}
+	}
+
+	// Find the outputs that are created and spent within the group.
+	created := make(map[types.SiacoinOutputID]types.SiacoinOutput)
+	for _, t := range group {
+		if !isSiacoinTransfer(t) {
+			return replacement{}, errNotReplaceable
+		}
+		for i, sco := range t.SiacoinOutputs {
+			created[t.SiacoinOutputID(uint64(i))] = sco
+		}
+		for _, fee := range t.MinerFees {
+			r.fees = r.fees.Add(fee)
+		}
+	}
+	for _, t := range group {
+		for _, sci := range t.SiacoinInputs {
+			if _, exists := created[sci.ParentID]; exists {
+				delete(created, sci.ParentID)
+				continue
+			}
+			if _, exists := w.keys[sci.UnlockConditions.UnlockHash()]; !exists {
+				return replacement{}, errNotReplaceable
+			}
+			r.inputs = append(r.inputs, sci)
+		}
+	}
+
+	// The inputs of a valid set are worth exactly the outputs that leave the
+	// group plus the fees.
+	r.value = r.fees
+	for _, t := range group {
+		for i, sco := range t.SiacoinOutputs {
+			if _, exists := created[t.SiacoinOutputID(uint64(i))]; !exists {
+				continue
+			}
+			r.value = r.value.Add(sco.Value)
+			if _, exists := w.keys[sco.UnlockHash]; !exists {
+				r.payments = append(r.payments, sco)
+			}
+		}
+	}
+	return r, nil
+}
+
+// managedSignReplacement builds and signs the transaction that replaces the
+// unconfirmed transaction txn, whose unconfirmed parents are provided.
+// defaultFee is added to the fee of the original if fee is zero.
+func (w *Wallet) managedSignReplacement(txn types.Transaction, parents []types.Transaction, fee, defaultFee types.Currency, cancel bool) (types.Transaction, replacement, error) {
+	feeDust := w.managedDustThreshold()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	found := false
+	for _, upt := range w.unconfirmedProcessedTransactions {
+		if upt.TransactionID == txn.ID() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return types.Transaction{}, replacement{}, errNotUnconfirmed
+	}
+	r, err := w.buildReplacement(txn, parents)
+	if err != nil {
+		return types.Transaction{}, replacement{}, err
+	}
+
+	if fee.IsZero() {
+		fee = r.fees.Add(defaultFee)
+	}
+	if fee.Cmp(r.fees) <= 0 {
+		return types.Transaction{}, replacement{}, errLowReplacementFee
+	}
+	newTxn := types.Transaction{SiacoinInputs: r.inputs}
+	spend := fee
+	if !cancel {
+		newTxn.SiacoinOutputs = r.payments
+		newTxn.ArbitraryData = txn.ArbitraryData
+		for _, sco := range r.payments {
+			spend = spend.Add(sco.Value)
+		}
+	}
+	if r.value.Cmp(spend) < 0 {
+		return types.Transaction{}, replacement{}, modules.ErrLowBalance
+	}
+
+	// Return the remaining value to the wallet. If the wallet rejects dust,
+	// change below the dust threshold is paid to the miners instead.
+	settings, err := dbGetWalletSettings(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, replacement{}, err
+	}
+	change := r.value.Sub(spend)
+	if settings.RejectDustOutputs && !change.IsZero() && change.Cmp(outputDustThreshold(settings, feeDust)) < 0 {
+		fee = fee.Add(change)
+	} else if !change.IsZero() {
+		changeAddress, err := w.nextChangeAddress(w.dbTx)
+		if err != nil {
+			return types.Transaction{}, replacement{}, err
+		}
+		newTxn.SiacoinOutputs = append(newTxn.SiacoinOutputs, types.SiacoinOutput{
+			Value:      change,
+			UnlockHash: changeAddress,
+		})
+	}
+	newTxn.MinerFees = []types.Currency{fee}
+	for _, sci := range newTxn.SiacoinInputs {
+		addSignatures(&newTxn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), w.keys[sci.UnlockConditions.UnlockHash()])
+	}
+	return newTxn, r, nil
+}
+
+// managedReplaceTransaction replaces an unconfirmed transaction of the wallet
+// with a transaction that spends the same inputs and pays the provided fee. If
+// cancel is false, the replacement makes the same payments as the original,
+// otherwise all funds are returned to the wallet. If fee is zero, the fee of
+// the original is raised by the current fee estimate.
+func (w *Wallet) managedReplaceTransaction(txid types.TransactionID, fee types.Currency, cancel bool) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+
+	// The transaction pool must not be called while the wallet is locked, as
+	// it calls into the wallet when its transactions change.
+	txn, parents, exists := w.tpool.Transaction(txid)
+	if !exists {
+		return nil, errNotUnconfirmed
+	}
+	_, tpoolFee := w.tpool.FeeEstimation()
+	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
+	newTxn, r, err := w.managedSignReplacement(txn, parents, fee, tpoolFee, cancel)
+	if err != nil {
+		w.log.Println("Attempt to replace transaction", txid, "has failed:", err)
+		return nil, err
+	}
+
+	txnSet := append(r.parents, newTxn)
+	if err := w.tpool.AcceptTransactionSet(txnSet); err != nil {
+		w.log.Println("Attempt to replace transaction", txid, "has failed - transaction pool rejected transaction:", err)
+		return nil, build.ExtendErr("unable to get transaction accepted", err)
+	}
+	w.log.Println("Replaced transaction", txid, "with", newTxn.ID())
+	return txnSet, nil
+}
+
+// BumpTransactionFee replaces an unconfirmed transaction of the wallet with a
+// transaction that makes the same payments and carries the same memo, but pays
+// the provided miner fee. The fee must be higher than the fee of the original
+// transaction; if it is zero, the original fee is raised by the current fee
+// estimate. Transactions that depend on the original are dropped from the
+// transaction pool. The replacement is submitted to the transaction pool and
+// is also returned.
+func (w *Wallet) BumpTransactionFee(txid types.TransactionID, fee types.Currency) ([]types.Transaction, error) {
+	return w.managedReplaceTransaction(txid, fee, false)
+}
+
+// CancelTransaction replaces an unconfirmed transaction of the wallet with a
+// transaction that returns its funds to the wallet, paying the provided miner
+// fee. The fee rules are the same as for BumpTransactionFee. The replacement
+// is submitted to the transaction pool and is also returned.
+func (w *Wallet) CancelTransaction(txid types.TransactionID, fee types.Currency) ([]types.Transaction, error) {
+	return w.managedReplaceTransaction(txid, fee, true)
+}
@@ -0,0 +1,102 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestReplaceTransaction checks that an unconfirmed send can be bumped to a
+// higher fee and then cancelled, and that only the final replacement is
+// confirmed.
+func TestReplaceTransaction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	dest := types.UnlockHash{1}
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendID := txns[len(txns)-1].ID()
+	var sendFees types.Currency
+	for _, txn := range txns {
+		for _, fee := range txn.MinerFees {
+			sendFees = sendFees.Add(fee)
+		}
+	}
+
+	// Bump the fee of the send. The replacement should make the same payment
+	// and pay a higher fee.
+	bumped, err := wt.wallet.BumpTransactionFee(sendID, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bump := bumped[len(bumped)-1]
+	if _, _, exists := wt.tpool.Transaction(sendID); exists {
+		t.Fatal("original transaction is still in the pool")
+	}
+	if _, _, exists := wt.tpool.Transaction(bump.ID()); !exists {
+		t.Fatal("replacement is not in the pool")
+	}
+	if len(bump.MinerFees) != 1 || bump.MinerFees[0].Cmp(sendFees) <= 0 {
+		t.Fatal("replacement does not pay a higher fee:", bump.MinerFees, sendFees)
+	}
+	paid := false
+	for _, sco := range bump.SiacoinOutputs {
+		if sco.UnlockHash == dest && sco.Value.Equals(types.SiacoinPrecision) {
+			paid = true
+		}
+	}
+	if !paid {
+		t.Fatal("replacement does not make the original payment")
+	}
+
+	// Replacing with the same fee should fail.
+	if _, err := wt.wallet.BumpTransactionFee(bump.ID(), bump.MinerFees[0]); err != errLowReplacementFee {
+		t.Fatal("expected errLowReplacementFee, got", err)
+	}
+
+	// Cancel the bumped send. Only the fee should leave the wallet.
+	cancelled, err := wt.wallet.CancelTransaction(bump.ID(), bump.MinerFees[0].Mul64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel := cancelled[len(cancelled)-1]
+	for _, sco := range cancel.SiacoinOutputs {
+		if sco.UnlockHash == dest {
+			t.Fatal("cancellation pays the original destination")
+		}
+	}
+	if _, _, exists := wt.tpool.Transaction(bump.ID()); exists {
+		t.Fatal("bumped transaction is still in the pool")
+	}
+	if _, err := wt.wallet.CancelTransaction(bump.ID(), types.ZeroCurrency); err != errNotUnconfirmed {
+		t.Fatal("expected errNotUnconfirmed, got", err)
+	}
+
+	outgoing, incoming := wt.wallet.UnconfirmedBalance()
+	if fee := bump.MinerFees[0].Mul64(2); !outgoing.Sub(incoming).Equals(fee) {
+		t.Fatalf("expected the cancellation to cost %v, got %v", fee, outgoing.Sub(incoming))
+	}
+
+	// Only the cancellation should be confirmed.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := wt.wallet.Transaction(cancel.ID()); !exists {
+		t.Fatal("cancellation was not confirmed")
+	}
+	for _, id := range []types.TransactionID{sendID, bump.ID()} {
+		if _, exists := wt.wallet.Transaction(id); exists {
+			t.Fatal("replaced transaction was confirmed")
+		}
+	}
+}
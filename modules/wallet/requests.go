@@ -0,0 +1,205 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	errPaymentRequestExpired  = errors.New("payment request would expire before it is created")
+	errPaymentRequestTooLarge = errors.New("payment request label and message must each be at most 1024 bytes")
+	errUnknownPaymentRequest  = errors.New("no payment request for that address")
+)
+
+// paymentRequestFulfilled returns whether the payments to the address of a
+// payment request add up to the requested amount.
+func paymentRequestFulfilled(pr modules.PaymentRequest) bool {
+	return len(pr.TransactionIDs) > 0 && pr.Received.Cmp(pr.Amount) >= 0
+}
+
+// paymentRequestStatus returns the status of a payment request at time now.
+func paymentRequestStatus(pr modules.PaymentRequest, now types.Timestamp) string {
+	switch {
+	case paymentRequestFulfilled(pr):
+		return modules.PaymentRequestFulfilled
+	case pr.URI().Expired(now):
+		return modules.PaymentRequestExpired
+	default:
+		return modules.PaymentRequestPending
+	}
+}
+
+// requestPayments returns the total value of the siacoin outputs that a
+// transaction sends to each of the wallet's addresses, in the order the
+// addresses first appear in the transaction.
+func requestPayments(pt modules.ProcessedTransaction) ([]types.UnlockHash, map[types.UnlockHash]types.Currency) {
+	var addrs []types.UnlockHash
+	values := make(map[types.UnlockHash]types.Currency)
+	for _, po := range pt.Outputs {
+		if po.FundType != types.SpecifierSiacoinOutput || !po.WalletAddress {
+			continue
+		}
+		if _, ok := values[po.RelatedAddress]; !ok {
+			addrs = append(addrs, po.RelatedAddress)
+		}
+		values[po.RelatedAddress] = values[po.RelatedAddress].Add(po.Value)
+	}
+	return addrs, values
+}
+
+// matchPaymentRequests records the payments that a confirmed transaction makes
+// to the addresses of payment requests, and queues an event for each request
+// that the transaction fulfills. Transactions that were already matched, as
+// happens when the wallet rescans the blockchain, are skipped.
+func (w *Wallet) matchPaymentRequests(tx *bolt.Tx, pt modules.ProcessedTransaction) error {
+	addrs, values := requestPayments(pt)
+	for _, addr := range addrs {
+		key, pr, err := dbGetPaymentRequest(tx, addr)
+		if err == errNoKey {
+			continue
+		} else if err != nil {
+			return err
+		}
+		matched := false
+		for _, txid := range pr.TransactionIDs {
+			matched = matched || txid == pt.TransactionID
+		}
+		if matched {
+			continue
+		}
+
+		wasFulfilled := paymentRequestFulfilled(pr)
+		pr.Received = pr.Received.Add(values[addr])
+		pr.TransactionIDs = append(pr.TransactionIDs, pt.TransactionID)
+		if !wasFulfilled && paymentRequestFulfilled(pr) {
+			pr.FulfilledHeight = pt.ConfirmationHeight
+			w.log.Println("Payment request fulfilled:", addr, "::", pr.Received.HumanString())
+			w.queueEvents(modules.WalletEvent{
+				Type:               modules.WalletEventRequestFulfilled,
+				TransactionID:      pt.TransactionID,
+				ConfirmationHeight: pt.ConfirmationHeight,
+				FundType:           types.SpecifierSiacoinOutput,
+				Address:            addr,
+				Value:              pr.Received,
+			})
+		}
+		if err := dbPutPaymentRequest(tx, key, pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmatchPaymentRequests removes the payments of a transaction that has been
+// reverted from the payment requests it paid.
+func (w *Wallet) unmatchPaymentRequests(tx *bolt.Tx, pt modules.ProcessedTransaction) error {
+	addrs, values := requestPayments(pt)
+	for _, addr := range addrs {
+		key, pr, err := dbGetPaymentRequest(tx, addr)
+		if err == errNoKey {
+			continue
+		} else if err != nil {
+			return err
+		}
+		for i, txid := range pr.TransactionIDs {
+			if txid != pt.TransactionID {
+				continue
+			}
+			pr.TransactionIDs = append(pr.TransactionIDs[:i], pr.TransactionIDs[i+1:]...)
+			pr.Received = pr.Received.Sub(values[addr])
+			if !paymentRequestFulfilled(pr) {
+				pr.FulfilledHeight = 0
+			}
+			if err := dbPutPaymentRequest(tx, key, pr); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// NewPaymentRequest creates a request for a payment of amount siacoins to a
+// fresh address of the wallet. A zero amount is fulfilled by any payment, and
+// a zero expiry never expires.
+func (w *Wallet) NewPaymentRequest(amount types.Currency, label, message string, expires types.Timestamp) (modules.PaymentRequest, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PaymentRequest{}, err
+	}
+	defer w.tg.Done()
+	if len(label) > maxTransactionLabelSize || len(message) > maxTransactionLabelSize {
+		return modules.PaymentRequest{}, errPaymentRequestTooLarge
+	}
+	now := types.CurrentTimestamp()
+	if expires != 0 && expires <= now {
+		return modules.PaymentRequest{}, errPaymentRequestExpired
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.PaymentRequest{}, modules.ErrLockedWallet
+	}
+	uc, err := w.nextPrimarySeedAddress(w.dbTx)
+	if err != nil {
+		return modules.PaymentRequest{}, err
+	}
+	pr := modules.PaymentRequest{
+		Address: uc.UnlockHash(),
+		Amount:  amount,
+		Label:   label,
+		Message: message,
+		Created: now,
+		Expires: expires,
+	}
+	if err := dbAppendPaymentRequest(w.dbTx, pr); err != nil {
+		return modules.PaymentRequest{}, err
+	}
+	w.syncDB() // ensure durability of reported address
+	pr.Status = paymentRequestStatus(pr, now)
+	return pr, nil
+}
+
+// PaymentRequest returns the payment request for an address.
+func (w *Wallet) PaymentRequest(addr types.UnlockHash) (modules.PaymentRequest, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PaymentRequest{}, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, pr, err := dbGetPaymentRequest(w.dbTx, addr)
+	if err == errNoKey {
+		return modules.PaymentRequest{}, errUnknownPaymentRequest
+	} else if err != nil {
+		return modules.PaymentRequest{}, err
+	}
+	pr.Status = paymentRequestStatus(pr, types.CurrentTimestamp())
+	return pr, nil
+}
+
+// PaymentRequests returns all of the wallet's payment requests, oldest first.
+func (w *Wallet) PaymentRequests() ([]modules.PaymentRequest, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var prs []modules.PaymentRequest
+	now := types.CurrentTimestamp()
+	err := dbForEachPaymentRequest(w.dbTx, func(_ []byte, pr modules.PaymentRequest) {
+		pr.Status = paymentRequestStatus(pr, now)
+		prs = append(prs, pr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestPaymentRequests checks that payments to the address of a payment request
+// are matched to the request, which is marked fulfilled once they reach the
+// requested amount.
+func TestPaymentRequests(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+	if err := wt.waitForSync(); err != nil {
+		t.Fatal(err)
+	}
+
+	amount := types.SiacoinPrecision.Mul64(100)
+	expires := types.CurrentTimestamp() + 3600
+	pr, err := wt.wallet.NewPaymentRequest(amount, "Coffee", "Order 42", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Status != modules.PaymentRequestPending || pr.Label != "Coffee" || pr.Expires != expires {
+		t.Fatalf("unexpected payment request: %+v", pr)
+	}
+	if uri := pr.URI(); uri.Address != pr.Address || !uri.Amount.Equals(amount) {
+		t.Error("payment URI does not match the request:", uri)
+	}
+
+	// A partial payment does not fulfill the request.
+	if _, err := wt.wallet.SendSiacoins(amount.Div64(4), pr.Address); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	pr, err = wt.wallet.PaymentRequest(pr.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Status != modules.PaymentRequestPending || !pr.Received.Equals(amount.Div64(4)) || len(pr.TransactionIDs) != 1 {
+		t.Fatalf("expected a pending request with a partial payment, got %+v", pr)
+	}
+
+	// The rest of the payment fulfills it.
+	txns, err := wt.wallet.SendSiacoins(amount, pr.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	height := wt.cs.Height()
+	pr, err = wt.wallet.PaymentRequest(pr.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Status != modules.PaymentRequestFulfilled || pr.FulfilledHeight != height {
+		t.Fatalf("expected the request to be fulfilled at height %v, got %+v", height, pr)
+	}
+	if pr.TransactionIDs[1] != txns[len(txns)-1].ID() {
+		t.Error("the fulfilling transaction was not recorded")
+	}
+	var fulfilled bool
+	for _, e := range wt.wallet.Events(0, nil) {
+		fulfilled = fulfilled || (e.Type == modules.WalletEventRequestFulfilled && e.Address == pr.Address)
+	}
+	if !fulfilled {
+		t.Error("no event was reported for the fulfilled request")
+	}
+
+	// Matching a transaction again, as a rescan does, does not count its
+	// payment twice, and reverting it makes the request pending again.
+	pt, ok := wt.wallet.Transaction(pr.TransactionIDs[1])
+	if !ok {
+		t.Fatal("fulfilling transaction not found")
+	}
+	wt.wallet.mu.Lock()
+	err = wt.wallet.matchPaymentRequests(wt.wallet.dbTx, pt)
+	if err == nil {
+		err = wt.wallet.unmatchPaymentRequests(wt.wallet.dbTx, pt)
+	}
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reverted, err := wt.wallet.PaymentRequest(pr.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted.Status != modules.PaymentRequestPending || !reverted.Received.Equals(amount.Div64(4)) || reverted.FulfilledHeight != 0 {
+		t.Fatalf("expected a pending request after the revert, got %+v", reverted)
+	}
+	wt.wallet.mu.Lock()
+	err = wt.wallet.matchPaymentRequests(wt.wallet.dbTx, pt)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Requests are listed oldest first, and unknown requests are reported.
+	if _, err := wt.wallet.NewPaymentRequest(types.ZeroCurrency, "", "", 0); err != nil {
+		t.Fatal(err)
+	}
+	prs, err := wt.wallet.PaymentRequests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 2 || prs[0].Address != pr.Address || prs[1].Status != modules.PaymentRequestPending {
+		t.Fatalf("unexpected payment requests: %+v", prs)
+	}
+	if _, err := wt.wallet.PaymentRequest(types.UnlockHash{}); err != errUnknownPaymentRequest {
+		t.Error("expected errUnknownPaymentRequest, got", err)
+	}
+	if _, err := wt.wallet.NewPaymentRequest(amount, "", "", types.CurrentTimestamp()-1); err != errPaymentRequestExpired {
+		t.Error("expected errPaymentRequestExpired, got", err)
+	}
+}
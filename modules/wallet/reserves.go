@@ -0,0 +1,210 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var errReservesReorg = errors.New("blockchain changed while the proof of reserves was created; try again")
+
+// A reserveCandidate is a confirmed output of the wallet that can be included
+// in a proof of reserves.
+type reserveCandidate struct {
+	id          types.SiacoinOutputID
+	output      types.SiacoinOutput
+	key         spendableKey
+	height      types.BlockHeight
+	minerPayout bool
+}
+
+// managedReserveCandidates returns the confirmed siacoin outputs of the
+// wallet that can be included in a proof of reserves, largest first, along
+// with the height of the wallet. Outputs that were not created by a
+// transaction or a miner payout, such as siafund claims, are not included.
+func (w *Wallet) managedReserveCandidates() ([]reserveCandidate, types.BlockHeight, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return nil, 0, err
+	}
+	type creation struct {
+		height      types.BlockHeight
+		minerPayout bool
+	}
+	created := make(map[types.SiacoinOutputID]creation)
+	err = dbForEachProcessedTransaction(w.dbTx, func(pt modules.ProcessedTransaction) {
+		for _, po := range pt.Outputs {
+			if po.WalletAddress && (po.FundType == types.SpecifierSiacoinOutput || po.FundType == types.SpecifierMinerPayout) {
+				created[types.SiacoinOutputID(po.ID)] = creation{
+					height:      pt.ConfirmationHeight,
+					minerPayout: po.FundType == types.SpecifierMinerPayout,
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var candidates []reserveCandidate
+	err = dbForEachSiacoinOutput(w.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+		c, exists := created[id]
+		key, owned := w.keys[sco.UnlockHash]
+		if !exists || !owned {
+			return
+		}
+		candidates = append(candidates, reserveCandidate{
+			id:          id,
+			output:      sco,
+			key:         key,
+			height:      c.height,
+			minerPayout: c.minerPayout,
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].output.Value.Cmp(candidates[j].output.Value) > 0
+	})
+	return candidates, height, nil
+}
+
+// ProveReserves creates a proof that the wallet controls at least 'amount'
+// siacoins at the current height. The largest confirmed outputs of the wallet
+// are included until they are worth at least 'amount'; if amount is zero,
+// every output is included. Each output is signed together with the
+// challenge, and carries the Merkle proof of its inclusion in the block that
+// created it.
+func (w *Wallet) ProveReserves(amount types.Currency, challenge string) (modules.ReservesProof, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.ReservesProof{}, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		return modules.ReservesProof{}, modules.ErrLockedWallet
+	}
+
+	candidates, height, err := w.managedReserveCandidates()
+	if err != nil {
+		return modules.ReservesProof{}, err
+	}
+	// Immature miner payouts are not spendable yet.
+	var selected []reserveCandidate
+	total := types.ZeroCurrency
+	for _, c := range candidates {
+		if c.minerPayout && c.height+types.MaturityDelay > height {
+			continue
+		}
+		if !amount.IsZero() && total.Cmp(amount) >= 0 {
+			break
+		}
+		selected = append(selected, c)
+		total = total.Add(c.output.Value)
+	}
+	if total.Cmp(amount) < 0 {
+		return modules.ReservesProof{}, modules.ErrLowBalance
+	}
+
+	// The consensus set must not be called while the wallet is locked.
+	b, exists := w.cs.BlockAtHeight(height)
+	if !exists {
+		return modules.ReservesProof{}, errReservesReorg
+	}
+	proof := modules.ReservesProof{
+		Height:    height,
+		BlockID:   b.ID(),
+		Challenge: challenge,
+		Total:     total,
+	}
+	for _, c := range selected {
+		ro, err := w.managedReserveOutput(c)
+		if err != nil {
+			return modules.ReservesProof{}, err
+		}
+		ro.Signatures = signReserveOutput(ro.SigHash(proof), c.key)
+		proof.Outputs = append(proof.Outputs, ro)
+	}
+	if b, exists := w.cs.BlockAtHeight(height); !exists || b.ID() != proof.BlockID {
+		return modules.ReservesProof{}, errReservesReorg
+	}
+	w.log.Println("Created a proof of reserves for", total.HumanString(), "at height", height)
+	return proof, nil
+}
+
+// managedReserveOutput fills in the inclusion data of an output in a proof of
+// reserves.
+func (w *Wallet) managedReserveOutput(c reserveCandidate) (modules.ReserveOutput, error) {
+	b, exists := w.cs.BlockAtHeight(c.height)
+	if !exists {
+		return modules.ReserveOutput{}, errReservesReorg
+	}
+	ro := modules.ReserveOutput{
+		ID:               c.id,
+		Output:           c.output,
+		UnlockConditions: c.key.UnlockConditions,
+		BlockHeight:      c.height,
+		Header:           b.Header(),
+		MinerPayout:      c.minerPayout,
+		NumLeaves:        uint64(len(b.MinerPayouts) + len(b.Transactions)),
+	}
+	found := false
+	if c.minerPayout {
+		for i := range b.MinerPayouts {
+			if b.MinerPayoutID(uint64(i)) == c.id {
+				ro.LeafIndex = uint64(i)
+				found = true
+				break
+			}
+		}
+	} else {
+		for i, txn := range b.Transactions {
+			for j := range txn.SiacoinOutputs {
+				if txn.SiacoinOutputID(uint64(j)) == c.id {
+					ro.LeafIndex = uint64(len(b.MinerPayouts) + i)
+					ro.OutputIndex = uint64(j)
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+	}
+	if !found {
+		return modules.ReserveOutput{}, errReservesReorg
+	}
+	ro.Leaf, ro.MerkleProof = b.MerkleProof(ro.LeafIndex)
+	return ro, nil
+}
+
+// signReserveOutput signs the hash of an output in a proof of reserves with
+// each of the keys that can spend it.
+func signReserveOutput(sigHash crypto.Hash, key spendableKey) []modules.ReserveSignature {
+	var sigs []modules.ReserveSignature
+	for i, spk := range key.UnlockConditions.PublicKeys {
+		for _, sk := range key.SecretKeys {
+			pk := sk.PublicKey()
+			if string(spk.Key) != string(pk[:]) {
+				continue
+			}
+			sig := crypto.SignHash(sigHash, sk)
+			sigs = append(sigs, modules.ReserveSignature{
+				PublicKeyIndex: uint64(i),
+				Signature:      sig[:],
+			})
+			break
+		}
+		if uint64(len(sigs)) == key.UnlockConditions.SignaturesRequired {
+			break
+		}
+	}
+	return sigs
+}
@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestProveReserves checks that the wallet creates proofs of reserves that
+// verify, and that tampered proofs are rejected.
+func TestProveReserves(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Send coins to the wallet, so that it has an output created by a
+	// transaction as well as miner payouts.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := wt.wallet.ProveReserves(types.ZeroCurrency, "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, err := modules.VerifyReservesProof(wt.cs, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _, _ := wt.wallet.ConfirmedBalance()
+	if !total.Equals(balance) {
+		t.Fatalf("proof covers %v, but the confirmed balance is %v", total, balance)
+	}
+	var payouts, transfers int
+	for _, ro := range proof.Outputs {
+		if ro.MinerPayout {
+			payouts++
+		} else {
+			transfers++
+		}
+	}
+	if payouts == 0 || transfers == 0 {
+		t.Fatalf("expected both miner payouts and transaction outputs, got %v and %v", payouts, transfers)
+	}
+
+	// A proof for a smaller amount only includes the outputs it needs.
+	small, err := wt.wallet.ProveReserves(types.SiacoinPrecision, "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(small.Outputs) != 1 || small.Total.Cmp(types.SiacoinPrecision) < 0 {
+		t.Fatal("expected a single output worth at least 1 SC, got", len(small.Outputs), small.Total)
+	}
+	if _, err := wt.wallet.ProveReserves(balance.Add(types.NewCurrency64(1)), "audit"); err != modules.ErrLowBalance {
+		t.Fatal("expected ErrLowBalance, got", err)
+	}
+
+	// Tampered proofs must be rejected.
+	tamper := []func(p *modules.ReservesProof){
+		func(p *modules.ReservesProof) { p.Challenge = "other audit" },
+		func(p *modules.ReservesProof) { p.Total = p.Total.Add(types.NewCurrency64(1)) },
+		func(p *modules.ReservesProof) { p.Height-- },
+		func(p *modules.ReservesProof) { p.Outputs[0].Signatures = nil },
+		func(p *modules.ReservesProof) { p.Outputs[0].MerkleProof = p.Outputs[0].MerkleProof[1:] },
+		func(p *modules.ReservesProof) { p.Outputs[0].Leaf = append(p.Outputs[0].Leaf, 0) },
+		func(p *modules.ReservesProof) {
+			p.Outputs[0].Output.Value = p.Outputs[0].Output.Value.Add(types.NewCurrency64(1))
+			p.Total = p.Total.Add(types.NewCurrency64(1))
+		},
+		func(p *modules.ReservesProof) {
+			p.Outputs = append(p.Outputs, p.Outputs[0])
+			p.Total = p.Total.Add(p.Outputs[0].Output.Value)
+		},
+	}
+	for i, fn := range tamper {
+		p := small
+		p.Outputs = append([]modules.ReserveOutput(nil), small.Outputs...)
+		fn(&p)
+		if _, err := modules.VerifyReservesProof(wt.cs, p); err == nil {
+			t.Error("tampered proof", i, "was accepted")
+		}
+	}
+
+	// Spending the outputs does not invalidate the proof, which is about an
+	// earlier height, but a proof of the new height would not include them.
+	if _, err := wt.wallet.SendSiacoins(balance.Sub(types.SiacoinPrecision), types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := modules.VerifyReservesProof(wt.cs, proof); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.ProveReserves(balance.Div64(2), "audit"); err != modules.ErrLowBalance {
+		t.Fatal("expected ErrLowBalance after spending, got", err)
+	}
+}
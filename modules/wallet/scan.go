@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -41,6 +42,11 @@ var maxScanKeys = func() uint64 {
 	}
 }()
 
+// maxGapLimit is the largest gap limit that the wallet accepts. A scan must
+// be able to generate gapLimit keys past a used address without exceeding
+// maxScanKeys.
+var maxGapLimit = maxScanKeys / 2
+
 var errMaxKeys = fmt.Errorf("refused to generate more than %v keys from seed", maxScanKeys)
 
 // A scannedOutput is an output found in the blockchain that was generated
@@ -55,12 +61,18 @@ type scannedOutput struct {
 // seed.
 type seedScanner struct {
 	dustThreshold    types.Currency              // minimum value of outputs to be included
+	gapLimit         uint64                      // number of unused keys to scan past the largest index seen
 	keys             map[types.UnlockHash]uint64 // map address to seed index
 	largestIndexSeen uint64                      // largest index that has appeared in the blockchain
 	seed             modules.Seed
 	siacoinOutputs   map[types.SiacoinOutputID]scannedOutput
 	siafundOutputs   map[types.SiafundOutputID]scannedOutput
 
+	// progress is read by the API while the scan is running, so it is kept
+	// separately from the fields above and protected by mu.
+	progress modules.SeedScanProgress
+	mu       sync.Mutex
+
 	log *persist.Logger
 }
 
@@ -74,6 +86,23 @@ func (s *seedScanner) generateKeys(n uint64) {
 	for i, k := range generateKeys(s.seed, initialProgress, n) {
 		s.keys[k.UnlockConditions.UnlockHash()] = initialProgress + uint64(i)
 	}
+	s.mu.Lock()
+	s.progress.KeysGenerated = s.numKeys()
+	s.mu.Unlock()
+}
+
+// scanProgress returns the progress of the scan.
+func (s *seedScanner) scanProgress() modules.SeedScanProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.progress
+}
+
+// done returns true if enough unused keys follow the largest index seen.
+// Besides the gap limit, at least the upper half of the generated keys must be
+// unused.
+func (s *seedScanner) done() bool {
+	return s.largestIndexSeen < s.numKeys()/2 && s.largestIndexSeen+s.gapLimit < s.numKeys()
 }
 
 // ProcessConsensusChange scans the blockchain for information relevant to the
@@ -136,6 +165,12 @@ func (s *seedScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
 			}
 		}
 	}
+
+	s.mu.Lock()
+	s.progress.BlocksScanned += types.BlockHeight(len(cc.AppliedBlocks))
+	s.progress.BlocksScanned -= types.BlockHeight(len(cc.RevertedBlocks))
+	s.progress.LargestIndexSeen = s.largestIndexSeen
+	s.mu.Unlock()
 }
 
 // scan subscribes s to cs and scans the blockchain for addresses that belong
@@ -143,25 +178,35 @@ func (s *seedScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
 // generated to find all the addresses.
 func (s *seedScanner) scan(cs modules.ConsensusSet, cancel <-chan struct{}) error {
 	// generate a bunch of keys and scan the blockchain looking for them. If
-	// none of the 'upper' half of the generated keys are found, we are done;
-	// otherwise, generate more keys and try again (bounded by a sane
-	// default).
+	// none of the 'upper' half of the generated keys are found and at least
+	// gapLimit keys follow the largest index found, we are done; otherwise,
+	// generate more keys and try again (bounded by a sane default).
 	//
 	// NOTE: since scanning is very slow, we aim to only scan once, which
 	// means generating many keys.
 	var numKeys uint64 = numInitialKeys
+	if numKeys < s.gapLimit+1 {
+		numKeys = s.gapLimit + 1
+	}
 	for s.numKeys() < maxScanKeys {
 		s.generateKeys(numKeys)
+		s.mu.Lock()
+		s.progress.BlocksScanned = 0
+		s.mu.Unlock()
 		if err := cs.ConsensusSetSubscribe(s, modules.ConsensusChangeBeginning, cancel); err != nil {
 			return err
 		}
 		cs.Unsubscribe(s)
-		if s.largestIndexSeen < s.numKeys()/2 {
+		if s.done() {
 			return nil
 		}
-		// increase number of keys generated each iteration, capping so that
-		// we do not exceed maxScanKeys
+		// increase number of keys generated each iteration, making sure that
+		// the gap limit is covered and capping so that we do not exceed
+		// maxScanKeys
 		numKeys *= scanMultiplier
+		if gap := s.largestIndexSeen + s.gapLimit + 1; gap > s.numKeys() && numKeys < gap-s.numKeys() {
+			numKeys = gap - s.numKeys()
+		}
 		if numKeys > maxScanKeys-s.numKeys() {
 			numKeys = maxScanKeys - s.numKeys()
 		}
@@ -170,13 +215,47 @@ func (s *seedScanner) scan(cs modules.ConsensusSet, cancel <-chan struct{}) erro
 }
 
 // newSeedScanner returns a new seedScanner.
-func newSeedScanner(seed modules.Seed, log *persist.Logger) *seedScanner {
+func newSeedScanner(seed modules.Seed, gapLimit uint64, log *persist.Logger) *seedScanner {
 	return &seedScanner{
+		gapLimit:       gapLimit,
 		seed:           seed,
 		keys:           make(map[types.UnlockHash]uint64, numInitialKeys),
 		siacoinOutputs: make(map[types.SiacoinOutputID]scannedOutput),
 		siafundOutputs: make(map[types.SiafundOutputID]scannedOutput),
 
+		progress: modules.SeedScanProgress{
+			Scanning: true,
+			GapLimit: gapLimit,
+		},
+
 		log: log,
 	}
 }
+
+// managedScanSeed scans the blockchain for the addresses of a seed, using the
+// gap limit from the wallet's settings. Outputs worth less than dustThreshold
+// are ignored. The progress of the scan is reported by ScanProgress.
+func (w *Wallet) managedScanSeed(seed modules.Seed, dustThreshold types.Currency) (*seedScanner, error) {
+	w.mu.Lock()
+	settings, err := dbGetWalletSettings(w.dbTx)
+	if err != nil {
+		w.mu.Unlock()
+		return nil, err
+	}
+	s := newSeedScanner(seed, settings.GapLimit, w.log)
+	s.dustThreshold = dustThreshold
+	w.scanner = s
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		if w.scanner == s {
+			w.scanner = nil
+		}
+		w.mu.Unlock()
+	}()
+	if err := s.scan(w.cs, w.tg.StopChan()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
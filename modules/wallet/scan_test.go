@@ -5,6 +5,7 @@ import (
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/fastrand"
 )
@@ -64,7 +65,7 @@ func TestScanLargeIndex(t *testing.T) {
 
 	// create seed scanner and scan the block
 	seed, _, _ := wt.wallet.PrimarySeed()
-	ss := newSeedScanner(seed, wt.wallet.log)
+	ss := newSeedScanner(seed, defaultGapLimit, wt.wallet.log)
 	err = ss.scan(wt.cs, wt.wallet.tg.StopChan())
 	if err != nil {
 		t.Fatal(err)
@@ -123,7 +124,7 @@ func TestScanLoop(t *testing.T) {
 
 	// create seed scanner and scan the block
 	seed, _, _ := wt.wallet.PrimarySeed()
-	ss := newSeedScanner(seed, wt.wallet.log)
+	ss := newSeedScanner(seed, defaultGapLimit, wt.wallet.log)
 	err = ss.scan(wt.cs, wt.wallet.tg.StopChan())
 	if err != nil {
 		t.Fatal(err)
@@ -141,3 +142,80 @@ func TestScanLoop(t *testing.T) {
 		t.Errorf("expected largest index to be %v, got %v", indices[len(indices)-2]+2, ss.largestIndexSeen)
 	}
 }
+
+// TestScanGapLimit checks that the seed scanner finds addresses past the
+// generated keys when the gap limit is raised, and that the progress of the
+// scan is reported.
+func TestScanGapLimit(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// send money to an address well past the keys generated by the first
+	// pass of a scan with the default gap limit.
+	index := numInitialKeys + numInitialKeys/2
+	wt.wallet.mu.Lock()
+	dbPutPrimarySeedProgress(wt.wallet.dbTx, index)
+	wt.wallet.mu.Unlock()
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision, uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the default gap limit should not reach the address.
+	ss, err := wt.wallet.managedScanSeed(seed, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss.largestIndexSeen >= index {
+		t.Fatal("default gap limit should not find index", index)
+	}
+
+	// raise the gap limit and scan again.
+	if err := wt.wallet.SetSettings(modules.WalletSettings{ChangePolicy: modules.ChangePolicyFresh, GapLimit: maxGapLimit + 1}); err != errGapLimitTooLarge {
+		t.Fatal("expected errGapLimitTooLarge, got", err)
+	}
+	gapLimit := numInitialKeys * 2
+	if err := wt.wallet.SetSettings(modules.WalletSettings{ChangePolicy: modules.ChangePolicyFresh, GapLimit: gapLimit}); err != nil {
+		t.Fatal(err)
+	}
+	ss, err = wt.wallet.managedScanSeed(seed, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss.largestIndexSeen < index {
+		t.Fatalf("expected to find index %v, largest index seen was %v", index, ss.largestIndexSeen)
+	}
+	if ss.largestIndexSeen+gapLimit >= ss.numKeys() {
+		t.Fatalf("scan stopped with %v keys, which does not cover the gap past index %v", ss.numKeys(), ss.largestIndexSeen)
+	}
+	progress := ss.scanProgress()
+	if progress.LargestIndexSeen != ss.largestIndexSeen || progress.KeysGenerated != ss.numKeys() || progress.GapLimit != gapLimit {
+		t.Fatal("scan progress does not match the scanner:", progress)
+	}
+	if progress.BlocksScanned != wt.cs.Height()+1 {
+		t.Fatalf("expected %v blocks to be scanned, got %v", wt.cs.Height()+1, progress.BlocksScanned)
+	}
+
+	// the wallet should not report a scan once it is done.
+	if wt.wallet.ScanProgress().Scanning {
+		t.Fatal("wallet should not be scanning")
+	}
+}
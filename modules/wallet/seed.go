@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"sync"
 
+	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
@@ -15,6 +16,12 @@ import (
 
 var (
 	errKnownSeed = errors.New("seed is already known")
+
+	// errAddressBeyondGapLimit is returned when an address is requested
+	// whose index is too far past the addresses that the wallet has
+	// generated. A seed scan would stop before reaching the address, so
+	// payments to it could not be recovered from the seed.
+	errAddressBeyondGapLimit = errors.New("address index is more than the gap limit past the last generated address")
 )
 
 type (
@@ -193,6 +200,52 @@ func (w *Wallet) NextAddress() (types.UnlockConditions, error) {
 	return uc, err
 }
 
+// DeriveAddress returns the address at the given index of the primary seed.
+// Addresses are derived deterministically, so the same index always yields the
+// same address. If the address has not been generated by the wallet yet, the
+// wallet generates all addresses up to and including the index, so that it
+// recognizes payments to the address and never hands it out again through
+// NextAddress. The index may be at most the gap limit past the last generated
+// address.
+func (w *Wallet) DeriveAddress(index uint64) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.UnlockConditions{}, modules.ErrLockedWallet
+	}
+
+	progress, err := dbGetPrimarySeedProgress(w.dbTx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	if index >= progress {
+		settings, err := dbGetWalletSettings(w.dbTx)
+		if err != nil {
+			return types.UnlockConditions{}, err
+		}
+		gapLimit := settings.GapLimit
+		if gapLimit == 0 {
+			gapLimit = defaultGapLimit
+		}
+		if index-progress >= gapLimit {
+			return types.UnlockConditions{}, errAddressBeyondGapLimit
+		}
+		needRescan, err := w.advanceSeedLookahead(index)
+		if err != nil {
+			return types.UnlockConditions{}, err
+		}
+		if needRescan {
+			go w.threadedResetSubscriptions()
+		}
+		w.syncDB() // ensure durability of reported address
+	}
+	return generateSpendableKey(w.primarySeed, index).UnlockConditions, nil
+}
+
 // LoadSeed will track all of the addresses generated by the input seed,
 // reclaiming any funds that were lost due to a deleted file or lost encryption
 // key. An error will be returned if the seed has already been integrated with
@@ -204,7 +257,7 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 	defer w.tg.Done()
 
 	if !w.cs.Synced() {
-		return errors.New("cannot load seed until blockchain is synced")
+		return build.ExtendErr("cannot load seed", modules.ErrNotSynced)
 	}
 
 	if !w.scanLock.TryLock() {
@@ -229,8 +282,8 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 	w.mu.RUnlock()
 
 	// scan blockchain to determine how many keys to generate for the seed
-	s := newSeedScanner(seed, w.log)
-	if err := s.scan(w.cs, w.tg.StopChan()); err != nil {
+	s, err := w.managedScanSeed(seed, types.ZeroCurrency)
+	if err != nil {
 		return err
 	}
 	// Add 4% as a buffer because the seed may have addresses in the wild
@@ -239,7 +292,7 @@ func (w *Wallet) LoadSeed(masterKey crypto.TwofishKey, seed modules.Seed) error
 	seedProgress += seedProgress / 25
 	w.log.Printf("INFO: found key index %v in blockchain. Setting auxiliary seed progress to %v", s.largestIndexSeen, seedProgress)
 
-	err := func() error {
+	err = func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
 
@@ -326,7 +379,7 @@ func (w *Wallet) SweepSeed(seed modules.Seed) (coins, funds types.Currency, err
 	}
 
 	if !w.cs.Synced() {
-		return types.Currency{}, types.Currency{}, errors.New("cannot sweep until blockchain is synced")
+		return types.Currency{}, types.Currency{}, build.ExtendErr("cannot sweep", modules.ErrNotSynced)
 	}
 
 	// get an address to spend into
@@ -339,12 +392,11 @@ func (w *Wallet) SweepSeed(seed modules.Seed) (coins, funds types.Currency, err
 
 	// scan blockchain for outputs, filtering out 'dust' (outputs that cost
 	// more in fees than they are worth)
-	s := newSeedScanner(seed, w.log)
 	_, maxFee := w.tpool.FeeEstimation()
 	const outputSize = 350 // approx. size in bytes of an output and accompanying signature
 	const maxOutputs = 50  // approx. number of outputs that a transaction can handle
-	s.dustThreshold = maxFee.Mul64(outputSize)
-	if err = s.scan(w.cs, w.tg.StopChan()); err != nil {
+	s, err := w.managedScanSeed(seed, maxFee.Mul64(outputSize))
+	if err != nil {
 		return
 	}
 
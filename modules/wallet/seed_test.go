@@ -84,6 +84,79 @@ func TestPrimarySeed(t *testing.T) {
 	}
 }
 
+// TestDeriveAddress checks that addresses are derived deterministically from
+// the primary seed, and that deriving an address ahead of the wallet's
+// progress makes the wallet track it.
+func TestDeriveAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	_, remaining, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	progress := maxScanKeys - remaining
+
+	// Deriving an address that was already generated should not advance the
+	// seed.
+	uc, err := wt.wallet.DeriveAddress(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.UnlockHash() != generateSpendableKey(wt.wallet.primarySeed, 0).UnlockConditions.UnlockHash() {
+		t.Fatal("derived address does not match the seed")
+	}
+	if _, r, _ := wt.wallet.PrimarySeed(); r != remaining {
+		t.Fatal("deriving a generated address advanced the seed")
+	}
+
+	// Deriving an address ahead of the wallet's progress should advance the
+	// seed past it, so the address is tracked and never returned by
+	// NextAddress.
+	index := progress + 5
+	uc, err = wt.wallet.DeriveAddress(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.UnlockHash() != generateSpendableKey(wt.wallet.primarySeed, index).UnlockConditions.UnlockHash() {
+		t.Fatal("derived address does not match the seed")
+	}
+	wt.wallet.mu.RLock()
+	tracked := wt.wallet.isWalletAddress(uc.UnlockHash())
+	wt.wallet.mu.RUnlock()
+	if !tracked {
+		t.Fatal("derived address is not tracked by the wallet")
+	}
+	next, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.UnlockHash() != generateSpendableKey(wt.wallet.primarySeed, index+1).UnlockConditions.UnlockHash() {
+		t.Fatal("NextAddress did not continue after the derived address")
+	}
+
+	// Addresses more than the gap limit ahead cannot be derived.
+	_, err = wt.wallet.DeriveAddress(index + 2 + defaultGapLimit)
+	if err != errAddressBeyondGapLimit {
+		t.Fatal("expected errAddressBeyondGapLimit, got", err)
+	}
+
+	// A locked wallet cannot derive addresses.
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.DeriveAddress(0); err != modules.ErrLockedWallet {
+		t.Fatal("expected ErrLockedWallet, got", err)
+	}
+}
+
 // TestLoadSeed checks that a seed can be successfully recovered from a wallet,
 // and then remain available on subsequent loads of the wallet.
 func TestLoadSeed(t *testing.T) {
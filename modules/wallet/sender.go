@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// walletSender implements the wallet's sends. A dry run builds and signs the
+// same transactions as a send, so that the inputs, outputs and fees it reports
+// are exact, but discards them instead of giving them to the transaction pool.
+type walletSender struct {
+	*Wallet
+	dryRun bool
+}
+
+// submit gives a signed transaction set to the transaction pool. In a dry run
+// the set is discarded, and the outputs it spends are available again.
+func (w walletSender) submit(txnBuilder modules.TransactionBuilder, txnSet []types.Transaction) error {
+	if w.dryRun {
+		txnBuilder.Drop()
+		return nil
+	}
+	return w.tpool.AcceptTransactionSet(txnSet)
+}
+
+// DryRun returns a sender whose sends build and sign the same transactions as
+// the wallet's, but discard them instead of broadcasting them.
+func (w *Wallet) DryRun() modules.WalletSender {
+	return walletSender{Wallet: w, dryRun: true}
+}
+
+// SendSiacoins creates a transaction sending 'amount' to 'dest'. The
+// transaction is submitted to the transaction pool and is also returned.
+func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
+	return walletSender{Wallet: w}.SendSiacoins(amount, dest)
+}
+
+// SendAllSiacoins creates a transaction that sends the entire spendable
+// balance of the wallet to 'dest', minus the miner fee.
+func (w *Wallet) SendAllSiacoins(dest types.UnlockHash) ([]types.Transaction, types.Currency, error) {
+	return walletSender{Wallet: w}.SendAllSiacoins(dest)
+}
+
+// SendSiacoinsMulti creates a transaction that includes the specified
+// outputs.
+func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error) {
+	return walletSender{Wallet: w}.SendSiacoinsMulti(outputs)
+}
+
+// SendSiacoinsMemo creates a transaction that includes the specified outputs
+// and carries the provided memo.
+func (w *Wallet) SendSiacoinsMemo(outputs []types.SiacoinOutput, memo []byte) ([]types.Transaction, error) {
+	return walletSender{Wallet: w}.SendSiacoinsMemo(outputs, memo)
+}
+
+// SendSiacoinsTarget creates a transaction that includes the specified
+// outputs, paying the fee needed for confirmation within 'target' blocks.
+func (w *Wallet) SendSiacoinsTarget(outputs []types.SiacoinOutput, target types.BlockHeight, replaceable bool, memo []byte) ([]types.Transaction, error) {
+	return walletSender{Wallet: w}.SendSiacoinsTarget(outputs, target, replaceable, memo)
+}
+
+// SendSiafunds creates a transaction sending 'amount' to 'dest'.
+func (w *Wallet) SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error) {
+	return walletSender{Wallet: w}.SendSiafunds(amount, dest)
+}
+
+// SendSiafundsMulti creates a transaction sending siafunds to each of the
+// specified outputs.
+func (w *Wallet) SendSiafundsMulti(outputs []types.SiafundOutput) ([]types.Transaction, error) {
+	return walletSender{Wallet: w}.SendSiafundsMulti(outputs)
+}
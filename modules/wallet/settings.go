@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/bolt"
+)
+
+var (
+	errUnknownChangePolicy  = errors.New("change policy must be either '" + modules.ChangePolicyFresh + "' or '" + modules.ChangePolicyFixed + "'")
+	errUnownedChangeAddress = errors.New("change address is not owned by the wallet")
+	errGapLimitTooLarge     = fmt.Errorf("gap limit must not exceed %v", maxGapLimit)
+	errUnknownCoinSelection = errors.New("coin selection must be either '" + modules.CoinSelectionLargest + "' or '" + modules.CoinSelectionOldest + "'")
+)
+
+// nextChangeAddress returns the address that should receive the change of a
+// transaction, according to the wallet's change policy.
+func (w *Wallet) nextChangeAddress(tx *bolt.Tx) (types.UnlockHash, error) {
+	settings, err := dbGetWalletSettings(tx)
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	if settings.ChangePolicy == modules.ChangePolicyFixed {
+		return settings.ChangeAddress, nil
+	}
+	uc, err := w.nextPrimarySeedAddress(tx)
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	return uc.UnlockHash(), nil
+}
+
+// Settings returns the wallet's settings.
+func (w *Wallet) Settings() (modules.WalletSettings, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.WalletSettings{}, err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return dbGetWalletSettings(w.dbTx)
+}
+
+// SetSettings changes the wallet's settings. A fixed change address must be
+// owned by the wallet, which requires the wallet to be unlocked. A gap limit of
+// zero selects the default gap limit, and an empty coin selection selects
+// CoinSelectionLargest.
+func (w *Wallet) SetSettings(settings modules.WalletSettings) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+
+	if settings.GapLimit == 0 {
+		settings.GapLimit = defaultGapLimit
+	} else if settings.GapLimit > maxGapLimit {
+		return errGapLimitTooLarge
+	}
+	switch settings.CoinSelection {
+	case "":
+		settings.CoinSelection = modules.CoinSelectionLargest
+	case modules.CoinSelectionLargest, modules.CoinSelectionOldest:
+	default:
+		return errUnknownCoinSelection
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch settings.ChangePolicy {
+	case modules.ChangePolicyFresh:
+		// The change address is only meaningful for the fixed policy.
+		settings.ChangeAddress = types.UnlockHash{}
+	case modules.ChangePolicyFixed:
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+		if settings.ChangeAddress == (types.UnlockHash{}) {
+			uc, err := w.nextPrimarySeedAddress(w.dbTx)
+			if err != nil {
+				return err
+			}
+			settings.ChangeAddress = uc.UnlockHash()
+		} else if _, exists := w.keys[settings.ChangeAddress]; !exists {
+			return errUnownedChangeAddress
+		}
+	default:
+		return errUnknownChangePolicy
+	}
+
+	err := dbPutWalletSettings(w.dbTx, settings)
+	if err != nil {
+		return err
+	}
+	w.syncDB()
+	return nil
+}
@@ -0,0 +1,252 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestChangePolicy checks that the change of a transaction is sent to the
+// address dictated by the wallet's change policy.
+func TestChangePolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// The default policy sends change to fresh addresses.
+	settings, err := wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.ChangePolicy != modules.ChangePolicyFresh {
+		t.Fatal("default change policy should be fresh, got", settings.ChangePolicy)
+	}
+
+	// Invalid settings should be rejected.
+	err = wt.wallet.SetSettings(modules.WalletSettings{ChangePolicy: "foo"})
+	if err != errUnknownChangePolicy {
+		t.Fatal("expected errUnknownChangePolicy, got", err)
+	}
+	err = wt.wallet.SetSettings(modules.WalletSettings{
+		ChangePolicy:  modules.ChangePolicyFixed,
+		ChangeAddress: types.UnlockHash{1},
+	})
+	if err != errUnownedChangeAddress {
+		t.Fatal("expected errUnownedChangeAddress, got", err)
+	}
+
+	// Switch to a fixed change address chosen by the wallet.
+	err = wt.wallet.SetSettings(modules.WalletSettings{ChangePolicy: modules.ChangePolicyFixed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err = wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.ChangePolicy != modules.ChangePolicyFixed || settings.ChangeAddress == (types.UnlockHash{}) {
+		t.Fatal("fixed change address was not set:", settings)
+	}
+	changeAddr := settings.ChangeAddress
+
+	// Send coins twice; both refund outputs should go to the change address.
+	for i := 0; i < 2; i++ {
+		txns, err := wt.wallet.SendSiacoins(types.NewCurrency64(5000), types.UnlockHash{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent := txns[0]
+		if len(parent.SiacoinOutputs) != 2 {
+			t.Fatal("expected the parent transaction to have a refund output")
+		}
+		if parent.SiacoinOutputs[1].UnlockHash != changeAddr {
+			t.Fatal("refund output was not sent to the fixed change address")
+		}
+	}
+
+	// The setting should persist across restarts.
+	err = wt.wallet.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(wt.cs, wt.tpool, wt.wallet.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet = w
+	settings, err = wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.ChangePolicy != modules.ChangePolicyFixed || settings.ChangeAddress != changeAddr {
+		t.Fatal("settings did not persist:", settings)
+	}
+
+	// Switching back to fresh addresses clears the change address.
+	err = wt.wallet.SetSettings(modules.WalletSettings{ChangePolicy: modules.ChangePolicyFresh, ChangeAddress: changeAddr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err = wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.ChangeAddress != (types.UnlockHash{}) {
+		t.Fatal("change address should be cleared for the fresh policy")
+	}
+}
+
+// TestDustSettings checks that the wallet honors its dust settings when
+// sending siacoins and funding transactions.
+func TestDustSettings(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	settings, err := wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.DustThreshold = types.SiacoinPrecision.Mul64(10)
+	settings.RejectDustOutputs = true
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Funding a transaction that leaves dust change should pay the change to
+	// the miners instead of creating a change output. The largest output is
+	// used first.
+	var largest types.Currency
+	wt.wallet.mu.Lock()
+	dbForEachSiacoinOutput(wt.wallet.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.Value.Cmp(largest) > 0 {
+			largest = sco.Value
+		}
+	})
+	wt.wallet.mu.Unlock()
+	tb := wt.wallet.StartTransaction()
+	if err := tb.FundSiacoins(largest.Sub(types.SiacoinPrecision)); err != nil {
+		t.Fatal(err)
+	}
+	_, parents := tb.View()
+	parent := parents[len(parents)-1]
+	if len(parent.SiacoinOutputs) != 1 || len(parent.MinerFees) != 1 || !parent.MinerFees[0].Equals(types.SiacoinPrecision) {
+		t.Fatal("dust change should have been paid to the miners:", parent.SiacoinOutputs, parent.MinerFees)
+	}
+	tb.Drop()
+
+	// Sends that create dust should be rejected.
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{}); err != errDustOutputRejected {
+		t.Fatal("expected errDustOutputRejected, got", err)
+	}
+	outputs := []types.SiacoinOutput{
+		{Value: types.SiacoinPrecision.Mul64(100), UnlockHash: types.UnlockHash{}},
+		{Value: types.SiacoinPrecision, UnlockHash: types.UnlockHash{}},
+	}
+	if _, err := wt.wallet.SendSiacoinsMulti(outputs); err != errDustOutputRejected {
+		t.Fatal("expected errDustOutputRejected, got", err)
+	}
+
+	// Without RejectDustOutputs, sending dust is only logged.
+	settings.RejectDustOutputs = false
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDustThresholds probes the inputDustThreshold and outputDustThreshold
+// functions.
+func TestDustThresholds(t *testing.T) {
+	feeDust := types.NewCurrency64(100)
+	tests := []struct {
+		settings      modules.WalletSettings
+		input, output uint64
+	}{
+		{modules.WalletSettings{}, 100, 100},
+		{modules.WalletSettings{ExcludeDustInputs: true}, 100, 100},
+		{modules.WalletSettings{DustThreshold: types.NewCurrency64(500)}, 100, 500},
+		{modules.WalletSettings{DustThreshold: types.NewCurrency64(500), ExcludeDustInputs: true}, 500, 500},
+		{modules.WalletSettings{DustThreshold: types.NewCurrency64(50), ExcludeDustInputs: true}, 100, 50},
+	}
+	for _, test := range tests {
+		if in := inputDustThreshold(test.settings, feeDust); in.Cmp64(test.input) != 0 {
+			t.Errorf("expected an input threshold of %v for %v, got %v", test.input, test.settings, in)
+		}
+		if out := outputDustThreshold(test.settings, feeDust); out.Cmp64(test.output) != 0 {
+			t.Errorf("expected an output threshold of %v for %v, got %v", test.output, test.settings, out)
+		}
+	}
+}
+
+// TestConfirmedInputsOnly checks that a wallet that only spends confirmed
+// outputs does not fund transactions with unconfirmed change.
+func TestConfirmedInputsOnly(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Create unconfirmed change by sending a small amount.
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sum the confirmed outputs that can still be spent.
+	var confirmed types.Currency
+	dustThreshold := wt.wallet.managedDustThreshold()
+	wt.wallet.mu.Lock()
+	height, err := dbGetConsensusHeight(wt.wallet.dbTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbForEachSiacoinOutput(wt.wallet.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if wt.wallet.checkOutput(wt.wallet.dbTx, height, scoid, sco, dustThreshold) == nil {
+			confirmed = confirmed.Add(sco.Value)
+		}
+	})
+	wt.wallet.mu.Unlock()
+	amount := confirmed.Add(types.SiacoinPrecision)
+
+	settings, err := wt.wallet.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.ConfirmedInputsOnly = true
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	tb := wt.wallet.StartTransaction()
+	if err := tb.FundSiacoins(amount); err != errUnconfirmedFunds {
+		t.Fatal("expected errUnconfirmedFunds, got", err)
+	}
+	tb.Drop()
+
+	// By default, the unconfirmed change can be spent.
+	settings.ConfirmedInputsOnly = false
+	if err := wt.wallet.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	tb = wt.wallet.StartTransaction()
+	if err := tb.FundSiacoins(amount); err != nil {
+		t.Fatal(err)
+	}
+	tb.Drop()
+}
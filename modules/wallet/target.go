@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var errZeroTarget = errors.New("confirmation target must be at least one block")
+
+// A replaceableTransaction is an unconfirmed send that the wallet replaces
+// with a higher fee if it is not confirmed in time.
+type replaceableTransaction struct {
+	// target is the number of blocks within which the send should be
+	// confirmed, and deadline is the height at which it is bumped.
+	target   types.BlockHeight
+	deadline types.BlockHeight
+}
+
+// feeForTarget returns the fee per byte that targets confirmation within the
+// provided number of blocks, given the minimum and maximum estimates of the
+// transaction pool. The maximum estimate targets the next block, the average
+// of the estimates has a moderate chance of being confirmed in the next block,
+// and the minimum targets confirmation within about three blocks.
+func feeForTarget(min, max types.Currency, target types.BlockHeight) types.Currency {
+	switch {
+	case target <= 1:
+		return max
+	case target == 2:
+		return min.Add(max).Div64(2)
+	default:
+		return min
+	}
+}
+
+// SendSiacoinsTarget creates a transaction that includes the specified
+// outputs, paying the fee that the transaction pool estimates is needed for
+// confirmation within 'target' blocks. If replaceable is set and the
+// transaction is still unconfirmed after 'target' blocks, the wallet replaces
+// it with a transaction that pays the current fee estimate on top of the
+// original fee, and keeps doing so until the send is confirmed. A non-empty
+// memo is attached to the transaction. The transaction is submitted to the
+// transaction pool and is also returned.
+func (w walletSender) SendSiacoinsTarget(outputs []types.SiacoinOutput, target types.BlockHeight, replaceable bool, memo []byte) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	if !w.unlocked {
+		w.log.Println("Attempt to send coins has failed - wallet is locked")
+		return nil, modules.ErrLockedWallet
+	}
+	if target == 0 {
+		return nil, errZeroTarget
+	}
+	if len(memo) > modules.MaxMemoSize {
+		return nil, modules.ErrMemoTooLarge
+	}
+	if err := w.managedCheckDustOutputs(outputs); err != nil {
+		w.log.Println("Attempt to send coins has failed:", err)
+		return nil, err
+	}
+
+	min, max := w.tpool.FeeEstimation()
+	fee := feeForTarget(min, max, target)
+	fee = fee.Mul64(750 + 60*uint64(len(outputs)) + uint64(len(memo))) // Estimated transaction size in bytes
+	txnSet, err := w.managedSendOutputs(outputs, fee, memo)
+	if err != nil {
+		return nil, err
+	}
+	if w.dryRun {
+		return txnSet, nil
+	}
+	w.log.Println("Submitted a siacoin transaction targeting confirmation within", target, "blocks with fees", fee.HumanString())
+
+	if replaceable {
+		w.mu.Lock()
+		height, err := dbGetConsensusHeight(w.dbTx)
+		if err == nil {
+			w.replaceable[txnSet[len(txnSet)-1].ID()] = replaceableTransaction{
+				target:   target,
+				deadline: height + target,
+			}
+		}
+		w.mu.Unlock()
+		if err != nil {
+			w.log.Println("WARN: could not mark transaction as replaceable:", err)
+		}
+	}
+	return txnSet, nil
+}
+
+// threadedBumpReplaceable replaces the replaceable sends that missed their
+// confirmation target with transactions that pay a higher fee.
+func (w *Wallet) threadedBumpReplaceable() {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	// Take the overdue sends out of the set, so that the goroutines started
+	// by consecutive blocks do not bump the same send twice.
+	w.mu.Lock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	overdue := make(map[types.TransactionID]replaceableTransaction)
+	if err == nil {
+		for txid, rt := range w.replaceable {
+			if height >= rt.deadline {
+				overdue[txid] = rt
+				delete(w.replaceable, txid)
+			}
+		}
+	}
+	w.mu.Unlock()
+	if err != nil {
+		w.log.Println("WARN: couldn't check for replaceable transactions:", err)
+		return
+	}
+
+	for txid, rt := range overdue {
+		// Sends that are no longer in the pool were either confirmed or
+		// dropped, and are forgotten.
+		if _, _, exists := w.tpool.Transaction(txid); !exists {
+			continue
+		}
+		txns, err := w.BumpTransactionFee(txid, types.ZeroCurrency)
+		if err != nil {
+			w.log.Println("WARN: could not bump the fee of transaction", txid, ":", err)
+			continue
+		}
+		w.mu.Lock()
+		w.replaceable[txns[len(txns)-1].ID()] = replaceableTransaction{
+			target:   rt.target,
+			deadline: height + rt.target,
+		}
+		w.mu.Unlock()
+	}
+}
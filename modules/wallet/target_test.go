@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestFeeForTarget checks that shorter confirmation targets never pay lower
+// fees.
+func TestFeeForTarget(t *testing.T) {
+	min, max := types.NewCurrency64(10), types.NewCurrency64(30)
+	tests := []struct {
+		target types.BlockHeight
+		fee    types.Currency
+	}{
+		{0, max},
+		{1, max},
+		{2, types.NewCurrency64(20)},
+		{3, min},
+		{100, min},
+	}
+	for _, test := range tests {
+		if fee := feeForTarget(min, max, test.target); !fee.Equals(test.fee) {
+			t.Errorf("target %v: expected fee %v, got %v", test.target, test.fee, fee)
+		}
+	}
+}
+
+// TestSendSiacoinsTargetReplaceable checks that a replaceable send that misses
+// its confirmation target is replaced with one that pays a higher fee.
+func TestSendSiacoinsTargetReplaceable(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if _, err := wt.wallet.SendSiacoinsTarget(nil, 0, false, nil); err != errZeroTarget {
+		t.Fatal("expected errZeroTarget, got", err)
+	}
+	outputs := []types.SiacoinOutput{{Value: types.SiacoinPrecision, UnlockHash: types.UnlockHash{1}}}
+	txns, err := wt.wallet.SendSiacoinsTarget(outputs, 1, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendID := txns[len(txns)-1].ID()
+
+	// Sends are only bumped by a synced wallet.
+	if err := wt.waitForSync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mine a block that does not confirm the send.
+	block, target, err := wt.miner.BlockForWork()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Transactions = nil
+	block.MinerPayouts = []types.SiacoinOutput{{Value: block.CalculateSubsidy(wt.cs.Height() + 1)}}
+	solvedBlock, _ := wt.miner.SolveBlock(block, target)
+	if err := wt.cs.AcceptBlock(solvedBlock); err != nil {
+		t.Fatal(err)
+	}
+
+	// The send should be replaced, and the replacement should be tracked.
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		if _, _, exists := wt.tpool.Transaction(sendID); exists {
+			return errors.New("send was not replaced")
+		}
+		wt.wallet.mu.Lock()
+		defer wt.wallet.mu.Unlock()
+		if _, tracked := wt.wallet.replaceable[sendID]; tracked || len(wt.wallet.replaceable) != 1 {
+			return errors.New("replacement is not tracked")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
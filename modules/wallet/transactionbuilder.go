@@ -28,6 +28,10 @@ var (
 
 	// errDustOutput indicates an output is not spendable because it is dust.
 	errDustOutput = errors.New("output is too small")
+
+	// errUnconfirmedFunds indicates that a transaction can only be funded by
+	// spending unconfirmed outputs, which the wallet is set not to do.
+	errUnconfirmedFunds = errors.New("wallet is set to spend only confirmed outputs - not enough confirmed coins")
 )
 
 // transactionBuilder allows transactions to be manually constructed, including
@@ -127,17 +131,45 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	if err != nil {
 		return err
 	}
+	settings, err := dbGetWalletSettings(tb.wallet.dbTx)
+	if err != nil {
+		return err
+	}
+	feeDust := dustThreshold
+	dustThreshold = inputDustThreshold(settings, feeDust)
 
-	// Collect a value-sorted set of siacoin outputs.
+	// The confirmation heights of the outputs are only needed to spend the
+	// oldest outputs first or to enforce a minimum number of confirmations.
+	oldestFirst := selectOldest(settings, amount)
+	var heights map[types.SiacoinOutputID]types.BlockHeight
+	if oldestFirst || settings.MinConfirmations > 1 {
+		heights, err = dbSiacoinOutputHeights(tb.wallet.dbTx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Collect the siacoin outputs that have enough confirmations.
 	var so sortedOutputs
+	var soHeights []types.BlockHeight
+	var unconfirmedFund, shallowFund types.Currency
 	err = dbForEachSiacoinOutput(tb.wallet.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		height := heights[scoid]
+		if confirmations(consensusHeight, height) < settings.MinConfirmations {
+			if tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold) == nil {
+				shallowFund = shallowFund.Add(sco.Value)
+			}
+			return
+		}
 		so.ids = append(so.ids, scoid)
 		so.outputs = append(so.outputs, sco)
+		soHeights = append(soHeights, height)
 	})
 	if err != nil {
 		return err
 	}
-	// Add all of the unconfirmed outputs as well.
+	// Add all of the unconfirmed outputs as well, such as the change of
+	// recent sends, unless the wallet only spends confirmed outputs.
 	for _, upt := range tb.wallet.unconfirmedProcessedTransactions {
 		for i, sco := range upt.Transaction.SiacoinOutputs {
 			// Determine if the output belongs to the wallet.
@@ -145,11 +177,28 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 			if !exists {
 				continue
 			}
-			so.ids = append(so.ids, upt.Transaction.SiacoinOutputID(uint64(i)))
+			scoid := upt.Transaction.SiacoinOutputID(uint64(i))
+			if settings.ConfirmedInputsOnly || settings.MinConfirmations > 0 {
+				if tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold) != nil {
+					continue
+				}
+				if settings.ConfirmedInputsOnly {
+					unconfirmedFund = unconfirmedFund.Add(sco.Value)
+				} else {
+					shallowFund = shallowFund.Add(sco.Value)
+				}
+				continue
+			}
+			so.ids = append(so.ids, scoid)
 			so.outputs = append(so.outputs, sco)
+			soHeights = append(soHeights, unconfirmedHeight)
 		}
 	}
-	sort.Sort(sort.Reverse(so))
+	if oldestFirst {
+		sort.Sort(outputsByAge{so, soHeights})
+	} else {
+		sort.Sort(sort.Reverse(so))
+	}
 
 	// Create and fund a parent transaction that will add the correct amount of
 	// siacoins to the transaction.
@@ -187,6 +236,12 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 			break
 		}
 	}
+	if fund.Cmp(amount) < 0 && fund.Add(unconfirmedFund).Cmp(amount) >= 0 {
+		return errUnconfirmedFunds
+	}
+	if fund.Cmp(amount) < 0 && fund.Add(unconfirmedFund).Add(shallowFund).Cmp(amount) >= 0 {
+		return errShallowFunds
+	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
 		return modules.ErrIncompleteTransactions
 	}
@@ -207,15 +262,19 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	}
 	parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, exactOutput)
 
-	// Create a refund output if needed.
-	if !amount.Equals(fund) {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	// Create a refund output if needed. If the wallet rejects dust, change
+	// below the dust threshold is paid to the miners instead.
+	refund := fund.Sub(amount)
+	if settings.RejectDustOutputs && !refund.IsZero() && refund.Cmp(outputDustThreshold(settings, feeDust)) < 0 {
+		parentTxn.MinerFees = append(parentTxn.MinerFees, refund)
+	} else if !refund.IsZero() {
+		refundAddress, err := tb.wallet.nextChangeAddress(tb.wallet.dbTx)
 		if err != nil {
 			return err
 		}
 		refundOutput := types.SiacoinOutput{
-			Value:      fund.Sub(amount),
-			UnlockHash: refundUnlockConditions.UnlockHash(),
+			Value:      refund,
+			UnlockHash: refundAddress,
 		}
 		parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, refundOutput)
 	}
@@ -341,13 +400,13 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+		refundAddress, err := tb.wallet.nextChangeAddress(tb.wallet.dbTx)
 		if err != nil {
 			return err
 		}
 		refundOutput := types.SiafundOutput{
 			Value:      fund.Sub(amount),
-			UnlockHash: refundUnlockConditions.UnlockHash(),
+			UnlockHash: refundAddress,
 		}
 		parentTxn.SiafundOutputs = append(parentTxn.SiafundOutputs, refundOutput)
 	}
@@ -476,6 +535,9 @@ func (tb *transactionBuilder) Drop() {
 		for _, sci := range txn.SiacoinInputs {
 			dbDeleteSpentOutput(tb.wallet.dbTx, types.OutputID(sci.ParentID))
 		}
+		for _, sfi := range txn.SiafundInputs {
+			dbDeleteSpentOutput(tb.wallet.dbTx, types.OutputID(sfi.ParentID))
+		}
 	}
 
 	tb.parents = nil
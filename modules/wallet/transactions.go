@@ -2,15 +2,72 @@ package wallet
 
 import (
 	"errors"
+	"sort"
+	"strings"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
 var (
-	errOutOfBounds = errors.New("requesting transactions at unknown confirmation heights")
+	errOutOfBounds        = errors.New("requesting transactions at unknown confirmation heights")
+	errLabelTooLarge      = errors.New("transaction label exceeds the maximum label size")
+	errUnknownTransaction = errors.New("transaction is not in the wallet's history")
 )
 
+// netSiacoinFlow returns the net number of siacoins that a transaction moved
+// into or out of the wallet.
+func netSiacoinFlow(pt modules.ProcessedTransaction) types.Currency {
+	var incoming, outgoing types.Currency
+	for _, input := range pt.Inputs {
+		if input.FundType == types.SpecifierSiacoinInput && input.WalletAddress {
+			outgoing = outgoing.Add(input.Value)
+		}
+	}
+	for _, output := range pt.Outputs {
+		if output.FundType == types.SpecifierMinerFee || output.FundType == types.SpecifierSiafundOutput {
+			continue
+		}
+		if output.WalletAddress {
+			incoming = incoming.Add(output.Value)
+		}
+	}
+	if incoming.Cmp(outgoing) >= 0 {
+		return incoming.Sub(outgoing)
+	}
+	return outgoing.Sub(incoming)
+}
+
+// matchesQuery returns true if the transaction, which has been given the
+// provided label, satisfies every criteria of the query.
+func matchesQuery(pt modules.ProcessedTransaction, label string, q modules.TransactionQuery) bool {
+	if q.Label != "" && !strings.Contains(strings.ToLower(label), strings.ToLower(q.Label)) {
+		return false
+	}
+	if q.Address != (types.UnlockHash{}) {
+		related := false
+		for _, input := range pt.Inputs {
+			related = related || input.RelatedAddress == q.Address
+		}
+		for _, output := range pt.Outputs {
+			related = related || output.RelatedAddress == q.Address
+		}
+		if !related {
+			return false
+		}
+	}
+	if !q.MinAmount.IsZero() || !q.MaxAmount.IsZero() {
+		amount := netSiacoinFlow(pt)
+		if amount.Cmp(q.MinAmount) < 0 {
+			return false
+		}
+		if !q.MaxAmount.IsZero() && amount.Cmp(q.MaxAmount) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // AddressTransactions returns all of the wallet transactions associated with a
 // single unlock hash.
 func (w *Wallet) AddressTransactions(uh types.UnlockHash) (pts []modules.ProcessedTransaction) {
@@ -124,3 +181,95 @@ func (w *Wallet) UnconfirmedTransactions() []modules.ProcessedTransaction {
 	defer w.mu.RUnlock()
 	return w.unconfirmedProcessedTransactions
 }
+
+// PendingTransactions returns the unconfirmed transactions relevant to the
+// wallet, along with the time each was first seen in the transaction pool.
+func (w *Wallet) PendingTransactions() []modules.PendingTransaction {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	pending := make([]modules.PendingTransaction, 0, len(w.unconfirmedProcessedTransactions))
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		pending = append(pending, modules.PendingTransaction{
+			ProcessedTransaction: pt,
+			FirstSeen:            w.unconfirmedSeen[pt.TransactionID],
+			ConfirmationTarget:   w.replaceable[pt.TransactionID].target,
+		})
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].FirstSeen.Before(pending[j].FirstSeen)
+	})
+	return pending
+}
+
+// SearchTransactions returns all of the confirmed and unconfirmed transactions
+// relevant to the wallet that match the query.
+func (w *Wallet) SearchTransactions(q modules.TransactionQuery) (confirmed, unconfirmed []modules.ProcessedTransaction) {
+	// ensure durability of reported transactions
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.syncDB()
+
+	labels := make(map[types.TransactionID]string)
+	dbForEachTransactionLabel(w.dbTx, func(txid types.TransactionID, label string) {
+		labels[txid] = label
+	})
+
+	it := dbProcessedTransactionsIterator(w.dbTx)
+	for it.next() {
+		pt := it.value()
+		if matchesQuery(pt, labels[pt.TransactionID], q) {
+			confirmed = append(confirmed, pt)
+		}
+	}
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		if matchesQuery(pt, labels[pt.TransactionID], q) {
+			unconfirmed = append(unconfirmed, pt)
+		}
+	}
+	return confirmed, unconfirmed
+}
+
+// SetTransactionLabel attaches a label to a transaction in the wallet's
+// history, replacing any existing label. An empty label removes the label.
+func (w *Wallet) SetTransactionLabel(txid types.TransactionID, label string) error {
+	if err := w.tg.Add(); err != nil {
+		return err
+	}
+	defer w.tg.Done()
+	if len(label) > maxTransactionLabelSize {
+		return errLabelTooLarge
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if label == "" {
+		return dbDeleteTransactionLabel(w.dbTx, txid)
+	}
+
+	// Only transactions that the wallet knows about can be labeled.
+	known := false
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		known = known || pt.TransactionID == txid
+	}
+	it := dbProcessedTransactionsIterator(w.dbTx)
+	for !known && it.next() {
+		known = it.value().TransactionID == txid
+	}
+	if !known {
+		return errUnknownTransaction
+	}
+	return dbPutTransactionLabel(w.dbTx, txid, label)
+}
+
+// TransactionLabels returns all of the labels that have been attached to
+// transactions in the wallet's history.
+func (w *Wallet) TransactionLabels() map[types.TransactionID]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	labels := make(map[types.TransactionID]string)
+	dbForEachTransactionLabel(w.dbTx, func(txid types.TransactionID, label string) {
+		labels[txid] = label
+	})
+	return labels
+}
@@ -2,6 +2,9 @@ package wallet
 
 import (
 	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
 
 	"github.com/NebulousLabs/Sia/types"
 )
@@ -191,6 +194,66 @@ func TestIntegrationAddressTransactions(t *testing.T) {
 	}
 }
 
+// TestIntegrationPendingTransactions checks that pending transactions report
+// when they entered the transaction pool, and keep that time while they stay
+// in it.
+func TestIntegrationPendingTransactions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if len(wt.wallet.PendingTransactions()) != 0 {
+		t.Fatal("wallet should have no pending transactions")
+	}
+	before := time.Now()
+	_, err = wt.wallet.SendSiacoins(types.NewCurrency64(5005), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := wt.wallet.PendingTransactions()
+	if len(pending) == 0 {
+		t.Fatal("send should have created pending transactions")
+	}
+	firstSeen := pending[0].FirstSeen
+	if firstSeen.Before(before) || firstSeen.After(time.Now()) {
+		t.Error("wrong first seen time:", firstSeen)
+	}
+
+	// A second send must not change the time of the first.
+	_, err = wt.wallet.SendSiacoins(types.NewCurrency64(5005), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending = wt.wallet.PendingTransactions()
+	if !pending[0].FirstSeen.Equal(firstSeen) {
+		t.Error("first seen time of a pending transaction changed")
+	}
+	for i := 1; i < len(pending); i++ {
+		if pending[i].FirstSeen.Before(pending[i-1].FirstSeen) {
+			t.Error("pending transactions are not sorted oldest first")
+		}
+	}
+
+	// Once confirmed, the transactions are no longer pending.
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if len(wt.wallet.PendingTransactions()) != 0 {
+		t.Error("wallet should have no pending transactions after a block")
+	}
+	wt.wallet.mu.RLock()
+	seen := len(wt.wallet.unconfirmedSeen)
+	wt.wallet.mu.RUnlock()
+	if seen != 0 {
+		t.Error("times of confirmed transactions were not forgotten:", seen)
+	}
+}
+
 // TestTransactionInputOutputIDs verifies that ProcessedTransaction's inputs
 // and outputs have a valid ID field.
 func TestTransactionInputOutputIDs(t *testing.T) {
@@ -255,3 +318,98 @@ func TestTransactionInputOutputIDs(t *testing.T) {
 		}
 	}
 }
+
+// TestIntegrationTransactionLabels checks that transactions can be labeled and
+// searched by label, address, and amount.
+func TestIntegrationTransactionLabels(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Labeling an unknown transaction should fail.
+	err = wt.wallet.SetTransactionLabel(types.TransactionID{}, "foo")
+	if err != errUnknownTransaction {
+		t.Fatal("expected errUnknownTransaction, got", err)
+	}
+
+	// Send money to an address in the wallet and label the transaction.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := uc.UnlockHash()
+	sentValue := types.NewCurrency64(5005)
+	sendTxns, err := wt.wallet.SendSiacoins(sentValue, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := sendTxns[1].ID()
+	err = wt.wallet.SetTransactionLabel(txid, "Rent for October")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.wallet.SetTransactionLabel(txid, string(make([]byte, maxTransactionLabelSize+1)))
+	if err != errLabelTooLarge {
+		t.Fatal("expected errLabelTooLarge, got", err)
+	}
+	if wt.wallet.TransactionLabels()[txid] != "Rent for October" {
+		t.Fatal("label was not stored")
+	}
+
+	// Search by label while the transaction is unconfirmed.
+	confirmed, unconfirmed := wt.wallet.SearchTransactions(modules.TransactionQuery{Label: "rent"})
+	if len(confirmed) != 0 || len(unconfirmed) != 1 || unconfirmed[0].TransactionID != txid {
+		t.Fatal("label search returned the wrong transactions", len(confirmed), len(unconfirmed))
+	}
+
+	// Confirm the transaction; the label should persist.
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed, unconfirmed = wt.wallet.SearchTransactions(modules.TransactionQuery{Label: "RENT"})
+	if len(confirmed) != 1 || len(unconfirmed) != 0 || confirmed[0].TransactionID != txid {
+		t.Fatal("label search returned the wrong transactions", len(confirmed), len(unconfirmed))
+	}
+
+	// Search by address.
+	confirmed, _ = wt.wallet.SearchTransactions(modules.TransactionQuery{Address: addr})
+	if len(confirmed) != 1 || confirmed[0].TransactionID != txid {
+		t.Fatal("address search returned the wrong transactions", len(confirmed))
+	}
+
+	// Search by amount. The send to our own address only moves the miner fee
+	// out of the wallet, which is larger than the amount that was sent.
+	confirmed, _ = wt.wallet.SearchTransactions(modules.TransactionQuery{
+		Address:   addr,
+		MaxAmount: sentValue,
+	})
+	if len(confirmed) != 0 {
+		t.Fatal("amount search returned the wrong transactions", len(confirmed))
+	}
+	confirmed, _ = wt.wallet.SearchTransactions(modules.TransactionQuery{
+		Address:   addr,
+		MinAmount: sentValue,
+	})
+	if len(confirmed) != 1 {
+		t.Fatal("amount search returned the wrong transactions", len(confirmed))
+	}
+	confirmed, _ = wt.wallet.SearchTransactions(modules.TransactionQuery{MinAmount: types.NewCurrency64(1)})
+	if len(confirmed) == 0 {
+		t.Fatal("expected miner payouts to match the amount search")
+	}
+
+	// Clearing the label removes it.
+	err = wt.wallet.SetTransactionLabel(txid, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := wt.wallet.TransactionLabels()[txid]; ok {
+		t.Fatal("label was not removed")
+	}
+}
@@ -2,6 +2,8 @@ package wallet
 
 import (
 	"errors"
+	"io/ioutil"
+	"path/filepath"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
@@ -113,25 +115,25 @@ func (w *Wallet) loadSpendableKey(masterKey crypto.TwofishKey, sk spendableKey)
 	// solution.
 }
 
-// loadSiagKeys loads a set of siag keyfiles into the wallet, so that the
-// wallet may spend the siafunds.
-func (w *Wallet) loadSiagKeys(masterKey crypto.TwofishKey, keyfiles []string) error {
+// readSiagKeys reads a set of siag keyfiles from disk and merges them into a
+// single spendableKey.
+func readSiagKeys(keyfiles []string) (spendableKey, error) {
 	// Load the keyfiles from disk.
 	if len(keyfiles) < 1 {
-		return ErrNoKeyfile
+		return spendableKey{}, ErrNoKeyfile
 	}
 	skps := make([]siagKeyPair, len(keyfiles))
 	for i, keyfile := range keyfiles {
 		err := encoding.ReadFile(keyfile, &skps[i])
 		if err != nil {
-			return err
+			return spendableKey{}, err
 		}
 
 		if skps[i].Header != SiagFileHeader {
-			return ErrUnknownHeader
+			return spendableKey{}, ErrUnknownHeader
 		}
 		if skps[i].Version != SiagFileVersion {
-			return ErrUnknownVersion
+			return spendableKey{}, ErrUnknownVersion
 		}
 	}
 
@@ -140,22 +142,32 @@ func (w *Wallet) loadSiagKeys(masterKey crypto.TwofishKey, keyfiles []string) er
 	baseUnlockHash := skps[0].UnlockConditions.UnlockHash()
 	for _, skp := range skps {
 		if skp.UnlockConditions.UnlockHash() != baseUnlockHash {
-			return ErrInconsistentKeys
+			return spendableKey{}, ErrInconsistentKeys
 		}
 	}
 	if uint64(len(skps)) < skps[0].UnlockConditions.SignaturesRequired {
-		return ErrInsufficientKeys
+		return spendableKey{}, ErrInsufficientKeys
 	}
 	// Drop all unneeded keys.
 	skps = skps[0:skps[0].UnlockConditions.SignaturesRequired]
 
-	// Merge the keys into a single spendableKey and save it to the wallet.
+	// Merge the keys into a single spendableKey.
 	var sk spendableKey
 	sk.UnlockConditions = skps[0].UnlockConditions
 	for _, skp := range skps {
 		sk.SecretKeys = append(sk.SecretKeys, skp.SecretKey)
 	}
-	err := w.loadSpendableKey(masterKey, sk)
+	return sk, nil
+}
+
+// loadSiagKeys loads a set of siag keyfiles into the wallet, so that the
+// wallet may spend the siafunds.
+func (w *Wallet) loadSiagKeys(masterKey crypto.TwofishKey, keyfiles []string) error {
+	sk, err := readSiagKeys(keyfiles)
+	if err != nil {
+		return err
+	}
+	err = w.loadSpendableKey(masterKey, sk)
 	if err != nil {
 		return err
 	}
@@ -212,6 +224,155 @@ func (w *Wallet) LoadSiagKeys(masterKey crypto.TwofishKey, keyfiles []string) er
 	return nil
 }
 
+// readSiagKeyDir reads the siag keyfiles of a directory, ordered by name, and
+// groups them by address. Each keyfile is reported in results; the keyfiles
+// that could not be read, or whose address does not have enough keyfiles to
+// be spent, have their error set, and the index of each group's first
+// keyfile in results is returned along with its key.
+func readSiagKeyDir(dir string) (results []modules.SiagKeyfileResult, keys map[int]spendableKey, err error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups := make(map[types.UnlockHash][]int)
+	var order []types.UnlockHash
+	for _, fi := range fis {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != SiagFileExtension {
+			continue
+		}
+		keyfile := filepath.Join(dir, fi.Name())
+		var skp siagKeyPair
+		err := encoding.ReadFile(keyfile, &skp)
+		if err == nil && skp.Header != SiagFileHeader {
+			err = ErrUnknownHeader
+		} else if err == nil && skp.Version != SiagFileVersion {
+			err = ErrUnknownVersion
+		}
+		result := modules.SiagKeyfileResult{Keyfile: keyfile}
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Address = skp.UnlockConditions.UnlockHash()
+		if _, exists := groups[result.Address]; !exists {
+			order = append(order, result.Address)
+		}
+		groups[result.Address] = append(groups[result.Address], len(results))
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return nil, nil, ErrNoKeyfile
+	}
+
+	keys = make(map[int]spendableKey)
+	for _, addr := range order {
+		var keyfiles []string
+		for _, i := range groups[addr] {
+			keyfiles = append(keyfiles, results[i].Keyfile)
+		}
+		sk, err := readSiagKeys(keyfiles)
+		if err != nil {
+			for _, i := range groups[addr] {
+				results[i].Error = err.Error()
+			}
+			continue
+		}
+		keys[groups[addr][0]] = sk
+	}
+	return results, keys, nil
+}
+
+// LoadSiagKeyDir loads every siag key in a directory into the wallet. The
+// keyfiles of multisig addresses are grouped by address, and the blockchain
+// is rescanned once after all of the keys are loaded, instead of once per
+// key. The outcome of each keyfile is reported separately; an error is only
+// returned if the directory could not be read or nothing could be loaded
+// because of the wallet.
+func (w *Wallet) LoadSiagKeyDir(masterKey crypto.TwofishKey, dir string) ([]modules.SiagKeyfileResult, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+
+	results, keys, err := readSiagKeyDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the keys and reset the consensus change ID and height in preparation for rescan
+	var loaded int
+	err = func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.unlocked {
+			return modules.ErrLockedWallet
+		}
+		if err := checkMasterKey(w.dbTx, masterKey); err != nil {
+			return err
+		}
+		for i := range results {
+			sk, exists := keys[i]
+			if !exists {
+				continue
+			}
+			err := w.loadSpendableKey(masterKey, sk)
+			if err != nil {
+				addr := sk.UnlockConditions.UnlockHash()
+				for j := range results {
+					if results[j].Address == addr {
+						results[j].Error = err.Error()
+					}
+				}
+				if err != errDuplicateSpendableKey {
+					w.log.Println("Unable to load siag key from", results[i].Keyfile, "-", err)
+				}
+				continue
+			}
+			w.integrateSpendableKey(masterKey, sk)
+			loaded++
+		}
+		if loaded == 0 {
+			return nil
+		}
+
+		if err = w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
+			return err
+		}
+		if _, err = w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
+			return err
+		}
+		w.unconfirmedProcessedTransactions = nil
+		err = dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
+		if err != nil {
+			return err
+		}
+		return dbPutConsensusHeight(w.dbTx, 0)
+	}()
+	if err != nil {
+		return nil, err
+	}
+	if loaded == 0 {
+		return results, nil
+	}
+	w.log.Println("Loaded", loaded, "siag keys from", dir)
+
+	// rescan the blockchain
+	w.cs.Unsubscribe(w)
+	w.tpool.Unsubscribe(w)
+
+	done := make(chan struct{})
+	go w.rescanMessage(done)
+	defer close(done)
+
+	err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
+	if err != nil {
+		return nil, err
+	}
+	w.tpool.TransactionPoolSubscribe(w)
+	return results, nil
+}
+
 // Load033xWallet loads a v0.3.3.x wallet as an unseeded key, such that the
 // funds become spendable to the current wallet.
 func (w *Wallet) Load033xWallet(masterKey crypto.TwofishKey, filepath033x string) error {
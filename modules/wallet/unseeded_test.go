@@ -1,8 +1,14 @@
 package wallet
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -45,6 +51,78 @@ func TestIntegrationLoad1of1Siag(t *testing.T) {
 	}
 }
 
+// TestIntegrationLoadSiagKeyDir loads a directory of siag keyfiles, which
+// contains a 1 of 1 key, two keyfiles of a 2 of 3 key, and a corrupt keyfile.
+func TestIntegrationLoadSiagKeyDir(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	dir := build.TempDir(modules.WalletDir, t.Name(), "siagkeys")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"siag0of1of1.siakey", "siag0of2of3.siakey", "siag1of2of3.siakey"} {
+		data, err := ioutil.ReadFile(filepath.Join("../../types", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "corrupt.siakey"), []byte("foo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("foo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A bad password should be rejected.
+	_, err = wt.wallet.LoadSiagKeyDir(crypto.TwofishKey{}, dir)
+	if err != modules.ErrBadEncryptionKey {
+		t.Fatal("expected bad encryption key error, got", err)
+	}
+
+	results, err := wt.wallet.LoadSiagKeyDir(wt.walletMasterKey, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatal("expected a result for each of the 4 keyfiles, got", len(results))
+	}
+	for _, r := range results {
+		corrupt := filepath.Base(r.Keyfile) == "corrupt.siakey"
+		if corrupt != (r.Error != "") {
+			t.Error("unexpected result:", r)
+		}
+	}
+	_, siafundBal, _ := wt.wallet.ConfirmedBalance()
+	if !siafundBal.Equals64(9000) {
+		t.Error("expecting a siafund balance of 9000 from both keys, got", siafundBal)
+	}
+
+	// Loading the directory again should report every key as a duplicate.
+	results, err = wt.wallet.LoadSiagKeyDir(wt.walletMasterKey, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Error == "" {
+			t.Error("key was loaded twice:", r)
+		}
+	}
+	_, siafundBal, _ = wt.wallet.ConfirmedBalance()
+	if !siafundBal.Equals64(9000) {
+		t.Error("expecting the siafund balance to be unchanged, got", siafundBal)
+	}
+}
+
 // TestIntegrationLoad2of3Siag loads a 2 of 3 unseeded key generated by siag
 // and then tries to spend the siafunds contained within. The key is taken from
 // the testing keys.
@@ -83,3 +161,67 @@ func TestIntegrationLoad2of3Siag(t *testing.T) {
 		t.Error("expecting balance of 6988 after sending siafunds to the void")
 	}
 }
+
+// TestIntegrationWatchSiagAddress loads a 1 of 1 siag key without rescanning
+// the wallet and checks that the siafunds are tracked immediately.
+func TestIntegrationWatchSiagAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	historyBefore, err := wt.wallet.Transactions(0, wt.cs.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A bad password should be rejected before any scanning happens.
+	keyfiles := []string{"../../types/siag0of1of1.siakey"}
+	err = wt.wallet.WatchSiagAddress(crypto.TwofishKey{}, keyfiles)
+	if err != modules.ErrBadEncryptionKey {
+		t.Fatal("expected bad encryption key error, got", err)
+	}
+
+	// Load the key into the wallet.
+	err = wt.wallet.WatchSiagAddress(wt.walletMasterKey, keyfiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, siafundBal, _ := wt.wallet.ConfirmedBalance()
+	if !siafundBal.Equals64(2000) {
+		t.Error("expecting a siafund balance of 2000 from the 1of1 key, got", siafundBal)
+	}
+
+	// The existing history should be untouched.
+	historyAfter, err := wt.wallet.Transactions(0, wt.cs.Height())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(historyAfter) != len(historyBefore) {
+		t.Errorf("expected %v transactions in history, got %v", len(historyBefore), len(historyAfter))
+	}
+
+	// Loading the key a second time should fail.
+	err = wt.wallet.WatchSiagAddress(wt.walletMasterKey, keyfiles)
+	if err != errDuplicateSpendableKey {
+		t.Fatal("expected duplicate key error, got", err)
+	}
+
+	// The wallet should keep tracking the address as new blocks arrive.
+	_, err = wt.wallet.SendSiafunds(types.NewCurrency64(12), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, siafundBal, _ = wt.wallet.ConfirmedBalance()
+	if !siafundBal.Equals64(1988) {
+		t.Error("expecting balance of 1988 after sending siafunds to the void, got", siafundBal)
+	}
+}
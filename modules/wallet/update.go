@@ -3,6 +3,7 @@ package wallet
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
@@ -168,6 +169,14 @@ func (w *Wallet) revertHistory(tx *bolt.Tx, reverted []types.Block) error {
 				if err := dbDeleteLastProcessedTransaction(tx); err != nil {
 					w.log.Severe("Could not revert transaction:", err)
 				}
+				if err := w.unmatchPaymentRequests(tx, pt); err != nil {
+					w.log.Println("ERROR: failed to revert payment request:", err)
+				}
+				w.queueEvents(modules.WalletEvent{
+					Type:               modules.WalletEventReverted,
+					TransactionID:      txid,
+					ConfirmationHeight: pt.ConfirmationHeight,
+				})
 			}
 		}
 
@@ -175,6 +184,13 @@ func (w *Wallet) revertHistory(tx *bolt.Tx, reverted []types.Block) error {
 		for i, mp := range block.MinerPayouts {
 			if w.isWalletAddress(mp.UnlockHash) {
 				w.log.Println("Miner payout has been reverted due to a reorg:", block.MinerPayoutID(uint64(i)), "::", mp.Value.HumanString())
+				if pt, err := dbGetLastProcessedTransaction(tx); err == nil {
+					w.queueEvents(modules.WalletEvent{
+						Type:               modules.WalletEventReverted,
+						TransactionID:      pt.TransactionID,
+						ConfirmationHeight: pt.ConfirmationHeight,
+					})
+				}
 				if err := dbDeleteLastProcessedTransaction(tx); err != nil {
 					w.log.Severe("Could not revert transaction:", err)
 				}
@@ -258,6 +274,7 @@ func (w *Wallet) applyHistory(tx *bolt.Tx, cc modules.ConsensusChange) error {
 			if err != nil {
 				return fmt.Errorf("could not put processed miner transaction: %v", err)
 			}
+			w.queueEvents(confirmedEvents(minerPT)...)
 		}
 		for _, txn := range block.Transactions {
 			// determine if transaction is relevant
@@ -384,6 +401,10 @@ func (w *Wallet) applyHistory(tx *bolt.Tx, cc modules.ConsensusChange) error {
 			if err != nil {
 				return fmt.Errorf("could not put processed transaction: %v", err)
 			}
+			w.queueEvents(confirmedEvents(pt)...)
+			if err := w.matchPaymentRequests(tx, pt); err != nil {
+				return fmt.Errorf("could not match payment requests: %v", err)
+			}
 		}
 	}
 
@@ -406,6 +427,11 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 	} else if needRescan {
 		go w.threadedResetSubscriptions()
 	}
+	// The balances at the end of the previous day have to be recorded before
+	// the change is applied.
+	if err := w.closeBalanceDay(w.dbTx, cc); err != nil {
+		w.log.Println("ERROR: failed to update balance history:", err)
+	}
 	if err := w.updateConfirmedSet(w.dbTx, cc); err != nil {
 		w.log.Println("ERROR: failed to update confirmed set:", err)
 	}
@@ -418,9 +444,24 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 	if err := dbPutConsensusChangeID(w.dbTx, cc.ID); err != nil {
 		w.log.Println("ERROR: failed to update consensus change ID:", err)
 	}
+	if err := w.openBalanceDay(w.dbTx, cc); err != nil {
+		w.log.Println("ERROR: failed to update balance history:", err)
+	}
+
+	// Events are only published for changes at the tip of the blockchain, so
+	// that catching up with the blockchain or rescanning it does not report
+	// old transactions again.
+	if cc.Synced {
+		w.publishEvents(w.queuedEvents)
+	}
+	w.queuedEvents = nil
 
 	if cc.Synced {
 		go w.threadedDefragWallet()
+		go w.threadedAutoClaimSiafunds()
+		if len(w.replaceable) > 0 {
+			go w.threadedBumpReplaceable()
+		}
 	}
 }
 
@@ -534,6 +575,25 @@ func (w *Wallet) ReceiveUpdatedUnconfirmedTransactions(diff *modules.Transaction
 				})
 			}
 			w.unconfirmedProcessedTransactions = append(w.unconfirmedProcessedTransactions, pt)
+			if _, exists := w.unconfirmedSeen[pt.TransactionID]; !exists {
+				w.unconfirmedSeen[pt.TransactionID] = time.Now()
+			}
+			w.publishEvents(paymentEvents(pt))
+		}
+	}
+
+	// Forget the transactions that have left the pool. This is done after the
+	// new transactions are added, so that a transaction that the pool
+	// re-adds keeps the time it was first seen.
+	if len(droppedTransactions) != 0 {
+		current := make(map[types.TransactionID]struct{}, len(w.unconfirmedProcessedTransactions))
+		for _, pt := range w.unconfirmedProcessedTransactions {
+			current[pt.TransactionID] = struct{}{}
+		}
+		for txid := range w.unconfirmedSeen {
+			if _, exists := current[txid]; !exists {
+				delete(w.unconfirmedSeen, txid)
+			}
 		}
 	}
 }
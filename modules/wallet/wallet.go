@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/bolt"
 
@@ -81,6 +82,31 @@ type Wallet struct {
 	unconfirmedSets                  map[modules.TransactionSetID][]types.TransactionID
 	unconfirmedProcessedTransactions []modules.ProcessedTransaction
 
+	// unconfirmedSeen records when each unconfirmed transaction was first
+	// seen in the transaction pool. A transaction keeps its time when the
+	// pool re-adds it along with other transactions.
+	unconfirmedSeen map[types.TransactionID]time.Time
+
+	// events holds the most recent wallet events, and eventsChan is closed
+	// and replaced whenever events are added. queuedEvents holds the events
+	// generated by the consensus change being processed, which are only
+	// published if the change brings the wallet up to date with the
+	// blockchain.
+	events       []modules.WalletEvent
+	eventsChan   chan struct{}
+	lastEventID  uint64
+	queuedEvents []modules.WalletEvent
+
+	// replaceable holds the unconfirmed sends whose fee is bumped if they are
+	// not confirmed within their confirmation target. It is not persisted,
+	// since the transaction pool does not keep unconfirmed transactions
+	// across restarts either.
+	replaceable map[types.TransactionID]replaceableTransaction
+
+	// lastAutoClaim is the height at which the wallet last claimed its
+	// siafund claim balance automatically.
+	lastAutoClaim types.BlockHeight
+
 	// The wallet's database tracks its seeds, keys, outputs, and
 	// transactions. A global db transaction is maintained in memory to avoid
 	// excessive disk writes. Any operations involving dbTx must hold an
@@ -96,6 +122,10 @@ type Wallet struct {
 	// initialization.
 	scanLock siasync.TryMutex
 
+	// scanner is the seedScanner of the seed scan in progress, if any. It is
+	// used to report the progress of the scan.
+	scanner *seedScanner
+
 	// The wallet's ThreadGroup tells tracked functions to shut down and
 	// blocks until they have all exited before returning from Close.
 	tg siasync.ThreadGroup
@@ -123,6 +153,10 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir stri
 		lookahead: make(map[types.UnlockHash]uint64),
 
 		unconfirmedSets: make(map[modules.TransactionSetID][]types.TransactionID),
+		unconfirmedSeen: make(map[types.TransactionID]time.Time),
+		replaceable:     make(map[types.TransactionID]replaceableTransaction),
+
+		eventsChan: make(chan struct{}),
 
 		persistDir: persistDir,
 	}
@@ -201,3 +235,15 @@ func (w *Wallet) Rescanning() bool {
 	}
 	return rescanning
 }
+
+// ScanProgress reports the progress of the seed scan that is currently
+// running, if any.
+func (w *Wallet) ScanProgress() modules.SeedScanProgress {
+	w.mu.RLock()
+	s := w.scanner
+	w.mu.RUnlock()
+	if s == nil {
+		return modules.SeedScanProgress{}
+	}
+	return s.scanProgress()
+}
@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
@@ -128,6 +129,18 @@ func createBlankWalletTester(name string) (*walletTester, error) {
 	return wt, nil
 }
 
+// waitForSync waits for the consensus set of the wallet tester to finish its
+// initial blockchain download. Only consensus changes that arrive after that
+// are marked as synced.
+func (wt *walletTester) waitForSync() error {
+	return build.Retry(100, 100*time.Millisecond, func() error {
+		if !wt.cs.Synced() {
+			return errors.New("consensus set is not synced")
+		}
+		return nil
+	})
+}
+
 // closeWt closes all of the modules in the wallet tester.
 func (wt *walletTester) closeWt() error {
 	errs := []error{
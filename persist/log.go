@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/NebulousLabs/Sia/build"
@@ -12,16 +14,26 @@ import (
 
 // Logger is a wrapper for the standard library logger that enforces logging
 // with the Sia-standard settings. It also supports a Close method, which
-// attempts to close the underlying io.Writer.
+// attempts to close the underlying io.Writer. Messages below the log level of
+// the logger's module are discarded.
 type Logger struct {
 	*log.Logger
-	w io.Writer
+	w      io.Writer
+	module string
+}
+
+// enabled returns true if messages of the given level are written.
+func (l *Logger) enabled(level LogLevel) bool {
+	return level >= ModuleLogLevel(l.module)
 }
 
 // Close logs a shutdown message and closes the Logger's underlying io.Writer,
 // if it is also an io.Closer.
 func (l *Logger) Close() error {
 	l.Output(2, "SHUTDOWN: Logging has terminated.")
+	if l.module != "" {
+		unregisterLogger(l.module)
+	}
 	if c, ok := l.w.(io.Closer); ok {
 		return c.Close()
 	}
@@ -37,30 +49,54 @@ func (l *Logger) Critical(v ...interface{}) {
 	build.Critical(v...)
 }
 
-// Debug is equivalent to Logger.Print when build.DEBUG is true. Otherwise it
-// is a no-op.
+// Debug is equivalent to Logger.Print when the log level is debug. Otherwise
+// it is a no-op.
 func (l *Logger) Debug(v ...interface{}) {
-	if build.DEBUG {
+	if l.enabled(LogLevelDebug) {
 		l.Output(2, fmt.Sprint(v...))
 	}
 }
 
-// Debugf is equivalent to Logger.Printf when build.DEBUG is true. Otherwise it
-// is a no-op.
+// Debugf is equivalent to Logger.Printf when the log level is debug.
+// Otherwise it is a no-op.
 func (l *Logger) Debugf(format string, v ...interface{}) {
-	if build.DEBUG {
+	if l.enabled(LogLevelDebug) {
 		l.Output(2, fmt.Sprintf(format, v...))
 	}
 }
 
-// Debugln is equivalent to Logger.Println when build.DEBUG is true. Otherwise
-// it is a no-op.
+// Debugln is equivalent to Logger.Println when the log level is debug.
+// Otherwise it is a no-op.
 func (l *Logger) Debugln(v ...interface{}) {
-	if build.DEBUG {
+	if l.enabled(LogLevelDebug) {
 		l.Output(2, "[DEBUG] "+fmt.Sprintln(v...))
 	}
 }
 
+// Print logs a message, if its level, given by its prefix, is not below the
+// log level. Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Print(v ...interface{}) {
+	if msg := fmt.Sprint(v...); l.enabled(messageLevel(msg)) {
+		l.Output(2, msg)
+	}
+}
+
+// Printf logs a message, if its level, given by its prefix, is not below the
+// log level. Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Printf(format string, v ...interface{}) {
+	if msg := fmt.Sprintf(format, v...); l.enabled(messageLevel(msg)) {
+		l.Output(2, msg)
+	}
+}
+
+// Println logs a message, if its level, given by its prefix, is not below the
+// log level. Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Println(v ...interface{}) {
+	if msg := fmt.Sprintln(v...); l.enabled(messageLevel(msg)) {
+		l.Output(2, msg)
+	}
+}
+
 // Severe logs a message with a SEVERE prefix. If debug mode is enabled, it
 // will also write the message to os.Stderr and panic. Severe should be called
 // if there is a severe problem with the user's machine or setup that should be
@@ -76,16 +112,54 @@ func (l *Logger) Severe(v ...interface{}) {
 func NewLogger(w io.Writer) *Logger {
 	l := log.New(w, "", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile|log.LUTC)
 	l.Output(3, "STARTUP: Logging has started. Siad Version "+build.Version) // Call depth is 3 because NewLogger is usually called by NewFileLogger
-	return &Logger{l, w}
+	return &Logger{Logger: l, w: w}
+}
+
+// logRotation holds the size past which the log files are rotated, and the
+// number of rotated files that are kept. A maxSize of 0 turns rotation off.
+var logRotation = struct {
+	maxSize    int64
+	maxBackups int
+	mu         sync.Mutex
+}{}
+
+// SetLogRotation makes the file loggers rotate their file once it grows past
+// maxSize bytes. The rotated files are named after the log file with the
+// suffixes .1 through .maxBackups, newest first, and older files are deleted.
+// A maxSize of 0 turns rotation off.
+func SetLogRotation(maxSize int64, maxBackups int) {
+	logRotation.mu.Lock()
+	defer logRotation.mu.Unlock()
+	logRotation.maxSize = maxSize
+	logRotation.maxBackups = maxBackups
+}
+
+// LogRotation returns the size past which the log files are rotated, and the
+// number of rotated files that are kept.
+func LogRotation() (maxSize int64, maxBackups int) {
+	logRotation.mu.Lock()
+	defer logRotation.mu.Unlock()
+	return logRotation.maxSize, logRotation.maxBackups
 }
 
+// rotationErrors is where the failures to rotate a log file are reported, as
+// they cannot be written to the log itself.
+var rotationErrors io.Writer = os.Stderr
+
 // closeableFile wraps an os.File to perform sanity checks on its Write and
 // Close methods. When the checks are enabled, calls to Write or Close will
-// panic if they are called after the file has already been closed.
+// panic if they are called after the file has already been closed. The file
+// is rotated when it grows past the size set by SetLogRotation.
 type closeableFile struct {
 	*os.File
+	size   int64
 	closed bool
-	mu     sync.RWMutex
+
+	// rotateFailed is set when rotating the file fails, so that only the
+	// first of consecutive failures is reported.
+	rotateFailed bool
+
+	mu sync.Mutex
 }
 
 // Close closes the file and sets the closed flag.
@@ -105,24 +179,98 @@ func (cf *closeableFile) Close() error {
 	return cf.File.Close()
 }
 
-// Write takes the input data and writes it to the file.
+// rotate moves the log file to the first rotated file, shifting the older
+// rotated files, and starts a new log file. If the file cannot be moved, e.g.
+// because the OS does not allow open files to be renamed, logging continues
+// in the current file and the error is returned.
+func (cf *closeableFile) rotate(maxBackups int) error {
+	name := cf.Name()
+	backup := func(i int) string { return fmt.Sprintf("%v.%d", name, i) }
+	os.Remove(backup(maxBackups))
+	for i := maxBackups - 1; i >= 1; i-- {
+		os.Rename(backup(i), backup(i+1))
+	}
+	if err := os.Rename(name, backup(1)); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+	if err != nil {
+		return err
+	}
+	old := cf.File
+	cf.File, cf.size = file, 0
+	old.Sync()
+	old.Close()
+	if maxBackups == 0 {
+		os.Remove(backup(1))
+	}
+	return nil
+}
+
+// Write takes the input data and writes it to the file, rotating the file
+// first if the data would grow it past the maximum size.
 func (cf *closeableFile) Write(b []byte) (int, error) {
-	cf.mu.RLock()
-	defer cf.mu.RUnlock()
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
 	// Sanity check - close should not have been called yet.
 	if cf.closed {
 		build.Critical("cannot write to the file after it has been closed")
 	}
-	return cf.File.Write(b)
+	if maxSize, maxBackups := LogRotation(); maxSize > 0 && cf.size > 0 && cf.size+int64(len(b)) > maxSize {
+		if err := cf.rotate(maxBackups); err != nil {
+			// Back off until another maxSize bytes have been written, rather
+			// than retrying on every write.
+			if !cf.rotateFailed {
+				fmt.Fprintf(rotationErrors, "unable to rotate log file %v, retrying every %v bytes: %v\n", cf.Name(), maxSize, err)
+			}
+			cf.rotateFailed, cf.size = true, 0
+		} else {
+			cf.rotateFailed = false
+		}
+	}
+	n, err := cf.File.Write(b)
+	cf.size += int64(n)
+	return n, err
+}
+
+// openLogFile opens a log file in append mode, creating it if it does not
+// exist.
+func openLogFile(logFilename string) (*closeableFile, error) {
+	logFile, err := os.OpenFile(logFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+	if err != nil {
+		return nil, err
+	}
+	info, err := logFile.Stat()
+	if err != nil {
+		logFile.Close()
+		return nil, err
+	}
+	return &closeableFile{File: logFile, size: info.Size()}, nil
 }
 
 // NewFileLogger returns a logger that logs to logFilename. The file is opened
-// in append mode, and created if it does not exist.
+// in append mode, and created if it does not exist. The logger belongs to the
+// module named after the file, e.g. "host" for host.log.
 func NewFileLogger(logFilename string) (*Logger, error) {
-	logFile, err := os.OpenFile(logFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+	cf, err := openLogFile(logFilename)
+	if err != nil {
+		return nil, err
+	}
+	l := NewLogger(cf)
+	l.module = strings.TrimSuffix(filepath.Base(logFilename), filepath.Ext(logFilename))
+	registerLogger(l.module)
+	return l, nil
+}
+
+// NewModuleFileLogger returns a logger of the given module that logs to
+// logFilename, as NewFileLogger does.
+func NewModuleFileLogger(module, logFilename string) (*Logger, error) {
+	cf, err := openLogFile(logFilename)
 	if err != nil {
 		return nil, err
 	}
-	cf := &closeableFile{File: logFile}
-	return NewLogger(cf), nil
+	l := NewLogger(cf)
+	l.module = module
+	registerLogger(l.module)
+	return l, nil
 }
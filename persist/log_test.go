@@ -1,6 +1,7 @@
 package persist
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -84,3 +85,171 @@ func TestLoggerCritical(t *testing.T) {
 	}()
 	fl.Critical("a critical message")
 }
+
+// TestLoggerLevels checks that a logger discards the messages below the log
+// level of its module.
+func TestLoggerLevels(t *testing.T) {
+	testdir := build.TempDir(persistDir, t.Name())
+	err := os.MkdirAll(testdir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logFilename := filepath.Join(testdir, "leveltest.log")
+	fl, err := NewFileLogger(logFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ResetLogLevel("leveltest")
+
+	found := false
+	for _, module := range LogModules() {
+		found = found || module == "leveltest"
+	}
+	if !found {
+		t.Error("the module of the logger is not listed:", LogModules())
+	}
+
+	logAll := func(tag string) {
+		fl.Debugln(tag, "debug")
+		fl.Println(tag, "info")
+		fl.Printf("WARN: %v warn", tag)
+		fl.Println("ERROR:", tag, "error")
+	}
+	SetLogLevel("leveltest", LogLevelDebug)
+	logAll("A")
+	SetLogLevel("leveltest", LogLevelWarn)
+	logAll("B")
+	SetLogLevel("leveltest", LogLevelError)
+	logAll("C")
+	if err := fl.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fileData, err := ioutil.ReadFile(logFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]bool{
+		"A debug": true, "A info": true, "A warn": true, "A error": true,
+		"B debug": false, "B info": false, "B warn": true, "B error": true,
+		"C debug": false, "C info": false, "C warn": false, "C error": true,
+	}
+	for msg, logged := range expected {
+		if strings.Contains(string(fileData), msg) != logged {
+			t.Errorf("expected %q to be logged: %v", msg, logged)
+		}
+	}
+	if !strings.Contains(string(fileData), "SHUTDOWN") {
+		t.Error("the shutdown message was not logged")
+	}
+}
+
+// TestParseLogLevel probes ParseLogLevel.
+func TestParseLogLevel(t *testing.T) {
+	for _, level := range []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError} {
+		parsed, err := ParseLogLevel(strings.ToUpper(level.String()))
+		if err != nil || parsed != level {
+			t.Errorf("expected %v, got %v (%v)", level, parsed, err)
+		}
+	}
+	if level, err := ParseLogLevel("warning"); err != nil || level != LogLevelWarn {
+		t.Error("expected warning to be parsed as warn, got", level, err)
+	}
+	if _, err := ParseLogLevel("verbose"); err != errUnknownLogLevel {
+		t.Error("expected errUnknownLogLevel, got", err)
+	}
+}
+
+// TestLoggerRotation checks that the log file is rotated once it grows past
+// the maximum size, and that only the newest rotated files are kept.
+func TestLoggerRotation(t *testing.T) {
+	testdir := build.TempDir(persistDir, t.Name())
+	err := os.MkdirAll(testdir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLogRotation(1000, 2)
+	defer SetLogRotation(0, 0)
+
+	logFilename := filepath.Join(testdir, "test.log")
+	fl, err := NewFileLogger(logFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		fl.Printf("message %03d %v", i, strings.Repeat("x", 50))
+	}
+	if err := fl.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{logFilename, logFilename + ".1", logFilename + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 1000 {
+			t.Errorf("%v is %v bytes, larger than the maximum size", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(logFilename + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only 2 rotated files to be kept")
+	}
+	current, err := ioutil.ReadFile(logFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := ioutil.ReadFile(logFilename + ".1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(current), "message 099") || !strings.Contains(string(current), "SHUTDOWN") {
+		t.Error("the newest messages are not in the log file")
+	}
+	if strings.Contains(string(rotated), "message 099") || !strings.Contains(string(rotated), "message 0") {
+		t.Error("the first rotated file does not hold the previous messages")
+	}
+}
+
+// TestLoggerRotationFailure checks that a log file that cannot be rotated
+// keeps being written, and that the failure is reported once.
+func TestLoggerRotationFailure(t *testing.T) {
+	testdir := build.TempDir(persistDir, t.Name())
+	err := os.MkdirAll(testdir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLogRotation(1000, 1)
+	defer SetLogRotation(0, 0)
+	var reported bytes.Buffer
+	rotationErrors = &reported
+	defer func() { rotationErrors = os.Stderr }()
+
+	// A non-empty directory in the place of the rotated file can be neither
+	// removed nor replaced.
+	logFilename := filepath.Join(testdir, "test.log")
+	if err := os.MkdirAll(filepath.Join(logFilename+".1", "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	fl, err := NewFileLogger(logFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		fl.Printf("message %03d %v", i, strings.Repeat("x", 50))
+	}
+	if err := fl.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(reported.String(), "unable to rotate"); n != 1 {
+		t.Errorf("expected the failure to be reported once, got %v reports: %v", n, reported.String())
+	}
+	current, err := ioutil.ReadFile(logFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(current), "message 000") || !strings.Contains(string(current), "message 099") {
+		t.Error("messages were lost when the rotation failed")
+	}
+}
@@ -0,0 +1,170 @@
+package persist
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// Every logger belongs to a module, named after its log file, and writes the
+// messages at or above the level of its module. The level of a message is
+// given by its prefix: messages starting with "ERROR:", "SEVERE:" or
+// "CRITICAL:" are errors, messages starting with "WARN:" are warnings,
+// messages written with Debug, Debugf or Debugln are debug messages, and all
+// other messages are info. Modules without a level of their own follow the
+// default level, which is debug in debug builds and info otherwise. Levels
+// can be changed while the loggers are open.
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+// The log levels, from the most to the least verbose.
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+var (
+	// logLevelNames are the names of the log levels.
+	logLevelNames = []string{"debug", "info", "warn", "error"}
+
+	// errUnknownLogLevel is returned by ParseLogLevel for unknown names.
+	errUnknownLogLevel = errors.New("log level must be debug, info, warn or error")
+)
+
+// logLevels holds the default log level, the levels of the modules that have
+// a level of their own, and the number of open loggers of each module.
+var logLevels = struct {
+	def     LogLevel
+	modules map[string]LogLevel
+	loggers map[string]int
+	mu      sync.RWMutex
+}{
+	def:     defaultLogLevel(),
+	modules: make(map[string]LogLevel),
+	loggers: make(map[string]int),
+}
+
+// defaultLogLevel returns the default log level, which is debug when
+// build.DEBUG is true.
+func defaultLogLevel() LogLevel {
+	if build.DEBUG {
+		return LogLevelDebug
+	}
+	return LogLevelInfo
+}
+
+// String returns the name of the log level.
+func (l LogLevel) String() string {
+	if l < LogLevelDebug || l > LogLevelError {
+		return "unknown"
+	}
+	return logLevelNames[l]
+}
+
+// ParseLogLevel returns the log level with the given name.
+func ParseLogLevel(name string) (LogLevel, error) {
+	name = strings.ToLower(name)
+	if name == "warning" {
+		name = "warn"
+	}
+	for l, n := range logLevelNames {
+		if n == name {
+			return LogLevel(l), nil
+		}
+	}
+	return 0, errUnknownLogLevel
+}
+
+// messageLevel returns the level of a log message, given by its prefix.
+func messageLevel(msg string) LogLevel {
+	switch {
+	case strings.HasPrefix(msg, "ERROR:"), strings.HasPrefix(msg, "SEVERE:"), strings.HasPrefix(msg, "CRITICAL:"):
+		return LogLevelError
+	case strings.HasPrefix(msg, "WARN:"):
+		return LogLevelWarn
+	case strings.HasPrefix(msg, "[DEBUG]"):
+		return LogLevelDebug
+	}
+	return LogLevelInfo
+}
+
+// DefaultLogLevel returns the level of the modules without a level of their
+// own.
+func DefaultLogLevel() LogLevel {
+	logLevels.mu.RLock()
+	defer logLevels.mu.RUnlock()
+	return logLevels.def
+}
+
+// SetDefaultLogLevel sets the level of the modules without a level of their
+// own.
+func SetDefaultLogLevel(level LogLevel) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	logLevels.def = level
+}
+
+// SetLogLevel sets the level of a module.
+func SetLogLevel(module string, level LogLevel) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	logLevels.modules[module] = level
+}
+
+// ResetLogLevel makes a module follow the default level again.
+func ResetLogLevel(module string) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	delete(logLevels.modules, module)
+}
+
+// ModuleLogLevel returns the level of a module.
+func ModuleLogLevel(module string) LogLevel {
+	logLevels.mu.RLock()
+	defer logLevels.mu.RUnlock()
+	if level, ok := logLevels.modules[module]; ok {
+		return level
+	}
+	return logLevels.def
+}
+
+// LogModules returns the modules that have an open logger or a level of
+// their own, sorted by name.
+func LogModules() []string {
+	logLevels.mu.RLock()
+	defer logLevels.mu.RUnlock()
+	var modules []string
+	for module := range logLevels.loggers {
+		modules = append(modules, module)
+	}
+	for module := range logLevels.modules {
+		if _, ok := logLevels.loggers[module]; !ok {
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// registerLogger records that a logger of a module was opened.
+func registerLogger(module string) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	logLevels.loggers[module]++
+}
+
+// unregisterLogger records that a logger of a module was closed.
+func unregisterLogger(module string) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	logLevels.loggers[module]--
+	if logLevels.loggers[module] <= 0 {
+		delete(logLevels.loggers, module)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Common commands have short forms, such as 'siac w s' for 'siac wallet
+// balance'. More can be added in the config file with "alias.<command> =
+// <aliases>" lines, where <command> is the path of a command with its parts
+// separated by dots, and <aliases> is a comma-separated list of names:
+//
+//	alias.wallet.send = sn
+//	alias.renter.list = l, files
+//
+// Aliases are read before the command line is parsed, so they follow the
+// --config and --profile flags, but not the settings of the config file.
+
+// aliasPrefix is the prefix of the config file keys that define aliases.
+const aliasPrefix = "alias."
+
+// configFlags returns the config file path and profile selected by the
+// command line and the environment, before the command line is parsed by
+// cobra.
+func configFlags(args []string) (path, prof string) {
+	fs := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	fs.ParseErrorsWhitelist.UnknownFlags = true
+	fs.SetOutput(ioutil.Discard)
+	fs.StringVar(&path, "config", defaultConfigPath(), "")
+	fs.StringVar(&prof, "profile", os.Getenv("SIA_PROFILE"), "")
+	fs.Parse(args)
+	return path, prof
+}
+
+// addAliases adds the aliases of the config file settings to the commands
+// under root.
+func addAliases(root *cobra.Command, settings map[string]string) error {
+	for key, value := range settings {
+		if !strings.HasPrefix(key, aliasPrefix) {
+			continue
+		}
+		cmd := root
+		for _, name := range strings.Split(strings.TrimPrefix(key, aliasPrefix), ".") {
+			var sub *cobra.Command
+			for _, c := range cmd.Commands() {
+				if c.Name() == name {
+					sub = c
+					break
+				}
+			}
+			if sub == nil {
+				return fmt.Errorf("%v: unknown command %q", key, strings.TrimSpace(cmd.CommandPath()+" "+name))
+			}
+			cmd = sub
+		}
+		for _, alias := range strings.Split(value, ",") {
+			alias = strings.TrimSpace(alias)
+			if alias == "" || strings.ContainsAny(alias, " \t") {
+				return fmt.Errorf("%v: invalid alias %q", key, alias)
+			}
+			for _, sibling := range cmd.Parent().Commands() {
+				if sibling != cmd && (sibling.Name() == alias || sibling.HasAlias(alias)) {
+					return fmt.Errorf("%v: alias %q is already used by %q", key, alias, sibling.CommandPath())
+				}
+			}
+			if !cmd.HasAlias(alias) && cmd.Name() != alias {
+				cmd.Aliases = append(cmd.Aliases, alias)
+			}
+		}
+	}
+	return nil
+}
+
+// loadAliases adds the aliases of the config file to the commands under root.
+// A config file that cannot be read is reported when the command runs.
+func loadAliases(root *cobra.Command, args []string) error {
+	settings, err := readConfig(configFlags(args))
+	if err != nil {
+		return nil
+	}
+	return addAliases(root, settings)
+}
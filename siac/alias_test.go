@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestAddAliases checks that aliases from the config file are added to the
+// commands they name, and that conflicting aliases are rejected.
+func TestAddAliases(t *testing.T) {
+	newTree := func() (root, send *cobra.Command) {
+		root = &cobra.Command{Use: "siac"}
+		wallet := &cobra.Command{Use: "wallet", Aliases: []string{"w"}}
+		send = &cobra.Command{Use: "send"}
+		balance := &cobra.Command{Use: "balance", Aliases: []string{"s"}}
+		wallet.AddCommand(send, balance)
+		root.AddCommand(wallet)
+		return root, send
+	}
+
+	root, send := newTree()
+	if err := addAliases(root, map[string]string{"alias.wallet.send": "sn, snd", "addr": "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(send.Aliases, []string{"sn", "snd"}) {
+		t.Fatal("expected the aliases to be added, got", send.Aliases)
+	}
+	if cmd, _, err := root.Find([]string{"w", "snd"}); err != nil || cmd != send {
+		t.Fatal("expected 'w snd' to find the send command, got", cmd, err)
+	}
+
+	for _, settings := range []map[string]string{
+		{"alias.wallet.sned": "sn"},
+		{"alias.wallet.send": "s"},
+		{"alias.wallet.send": "balance"},
+		{"alias.wallet.send": "a b"},
+		{"alias.wallet.send": "sn,"},
+	} {
+		root, _ := newTree()
+		if err := addAliases(root, settings); err == nil {
+			t.Errorf("expected an error adding %v", settings)
+		}
+	}
+}
+
+// TestConfigFlags checks that the config file and profile are found on the
+// command line before it is parsed.
+func TestConfigFlags(t *testing.T) {
+	path, prof := configFlags([]string{"-a", "localhost:1", "--config", "/tmp/siac.conf", "w", "--profile=host2", "s", "--unknown"})
+	if path != "/tmp/siac.conf" || prof != "host2" {
+		t.Fatalf("expected /tmp/siac.conf and host2, got %q and %q", path, prof)
+	}
+	if !validConfigKey("alias.wallet") || validConfigKey("alias.") {
+		t.Fatal("expected alias keys to be valid config keys")
+	}
+}
@@ -16,7 +16,8 @@ var (
 			"Once created, the file has to be moved to the bash " +
 			"completion script folder - usually " +
 			"`/etc/bash_completion.d/`.",
-		Run: wrap(bashcomplcmd),
+		Deprecated: "use 'completion bash' instead, which also completes arguments such as renter file paths.",
+		Run:        wrap(bashcomplcmd),
 	}
 )
 
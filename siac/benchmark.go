@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// The benchmark uploads a file of random data through the renter's real
+// pipeline, downloads it again and checks that it is intact, then deletes
+// it. The data sent to and received from each host is taken from the
+// difference between two snapshots of /renter/bandwidth, so transfers of
+// other files in the meantime are counted as well.
+
+var (
+	renterBenchmarkCmd = &cobra.Command{
+		Use:   "benchmark",
+		Short: "Measure upload and download speed",
+		Long: `Upload a file of random data with the current contracts, download it again,
+and report the throughput, the time to the first byte of the download, and the
+data exchanged with each host. The file is deleted afterwards. Uploading uses
+the allowance like any other file.`,
+		Run: wrap(renterbenchmarkcmd),
+	}
+)
+
+// A hostContribution is the data that a host accepted and delivered during
+// the benchmark.
+type hostContribution struct {
+	NetAddress string
+	Uploaded   uint64
+	Downloaded uint64
+}
+
+// bandwidthContributions returns the data exchanged with each host between
+// two snapshots of the renter's bandwidth, largest uploads first. Hosts that
+// exchanged no data are left out.
+func bandwidthContributions(before, after []api.RenterHostBandwidth) []hostContribution {
+	prev := make(map[string]api.RenterHostBandwidth)
+	for _, h := range before {
+		prev[h.HostPublicKey.String()] = h
+	}
+	var contribs []hostContribution
+	for _, h := range after {
+		p := prev[h.HostPublicKey.String()]
+		var c hostContribution
+		c.NetAddress = string(h.NetAddress)
+		if h.UploadBytes > p.UploadBytes {
+			c.Uploaded = h.UploadBytes - p.UploadBytes
+		}
+		if h.DownloadBytes > p.DownloadBytes {
+			c.Downloaded = h.DownloadBytes - p.DownloadBytes
+		}
+		if c.Uploaded > 0 || c.Downloaded > 0 {
+			contribs = append(contribs, c)
+		}
+	}
+	sort.Slice(contribs, func(i, j int) bool {
+		if contribs[i].Uploaded != contribs[j].Uploaded {
+			return contribs[i].Uploaded > contribs[j].Uploaded
+		}
+		return contribs[i].Downloaded > contribs[j].Downloaded
+	})
+	return contribs
+}
+
+// throughput formats the speed of transferring size bytes in d.
+func throughput(size uint64, d time.Duration) string {
+	if d <= 0 {
+		return "--"
+	}
+	return filesizeUnits(int64(float64(size)/d.Seconds())) + "/s"
+}
+
+// writeBenchmarkFile writes size bytes of random data to a temporary file, and
+// returns its path and the hash of its contents.
+func writeBenchmarkFile(size uint64) (string, crypto.Hash, error) {
+	f, err := ioutil.TempFile("", "siac-benchmark-")
+	if err != nil {
+		return "", crypto.Hash{}, err
+	}
+	defer f.Close()
+	h := crypto.NewHash()
+	w := io.MultiWriter(f, h)
+	buf := make([]byte, 1<<20)
+	for written := uint64(0); written < size; {
+		n := uint64(len(buf))
+		if size-written < n {
+			n = size - written
+		}
+		fastrand.Read(buf[:n])
+		if _, err := w.Write(buf[:n]); err != nil {
+			os.Remove(f.Name())
+			return "", crypto.Hash{}, err
+		}
+		written += n
+	}
+	var sum crypto.Hash
+	copy(sum[:], h.Sum(nil))
+	return f.Name(), sum, f.Sync()
+}
+
+// renterbenchmarkcmd is the handler for the command `siac renter benchmark`.
+// It measures the speed of an upload and a download of a test file.
+func renterbenchmarkcmd() {
+	sizeStr, err := parseFilesize(renterBenchmarkSize)
+	if err != nil {
+		dieUsage("Could not parse size:", err)
+	}
+	size, err := strconv.ParseUint(sizeStr, 10, 64)
+	if err != nil || size == 0 {
+		dieUsage("The size must be positive.")
+	}
+
+	var rc api.RenterContracts
+	err = getAPI("/renter/contracts", &rc)
+	if err != nil {
+		die("Could not get contracts:", err)
+	}
+	if len(rc.Contracts) == 0 {
+		die("The renter has no contracts. Set an allowance with 'siac renter setallowance' first.")
+	}
+	var before api.RenterBandwidth
+	err = getAPI("/renter/bandwidth", &before)
+	if err != nil {
+		die("Could not get bandwidth:", err)
+	}
+
+	source, sum, err := writeBenchmarkFile(size)
+	if err != nil {
+		die("Could not create test file:", err)
+	}
+	siapath := "siac-benchmark-" + hex.EncodeToString(fastrand.Bytes(4))
+
+	// Clean up when the benchmark ends, fails, or is interrupted.
+	uploaded := false
+	cleanup := func() {
+		os.Remove(source)
+		if uploaded {
+			if err := post("/renter/delete/"+siapath, ""); err != nil {
+				fmt.Fprintf(os.Stderr, "Could not delete %v: %v\n", siapath, err)
+			}
+		}
+	}
+	fail := func(args ...interface{}) {
+		cleanup()
+		die(args...)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		fmt.Println()
+		fail("Benchmark interrupted.")
+	}()
+
+	fmt.Printf("Benchmarking %v with %v contracts.\n", filesizeUnits(int64(size)), len(rc.Contracts))
+
+	// Upload the file and wait until it is fully redundant.
+	uploadStart := time.Now()
+	err = post("/renter/upload/"+siapath, "source="+source)
+	if err != nil {
+		fail("Could not upload test file:", err)
+	}
+	uploaded = true
+	paths := map[string]bool{siapath: true}
+	var rm rateMeter
+	for range time.Tick(progressInterval) {
+		var rf api.RenterFiles
+		if err := getAPI("/renter/files", &rf); err != nil {
+			continue // benign
+		}
+		done, total, finished := uploadsDone(rf.Files, paths)
+		if showProgress() {
+			drawProgress("Uploading... " + progressLine(done, total, rm.update(done, time.Now())))
+		}
+		if finished {
+			break
+		}
+	}
+	uploadTime := time.Since(uploadStart)
+	if showProgress() {
+		fmt.Println()
+	}
+
+	// Download the file, streaming it back to siac.
+	downloadStart := time.Now()
+	resp, err := apiGet("/renter/download/" + siapath + "?httpresp=true")
+	if err != nil {
+		fail("Could not download test file:", err)
+	}
+	h := crypto.NewHash()
+	first := make([]byte, 1)
+	_, err = io.ReadFull(resp.Body, first)
+	ttfb := time.Since(downloadStart)
+	var received int64
+	if err == nil {
+		h.Write(first)
+		received, err = io.Copy(h, resp.Body)
+		received++
+	}
+	resp.Body.Close()
+	downloadTime := time.Since(downloadStart)
+	if err != nil {
+		fail("Could not download test file:", err)
+	}
+	if !bytes.Equal(h.Sum(nil), sum[:]) || uint64(received) != size {
+		fail("The downloaded file does not match the uploaded file.")
+	}
+
+	var after api.RenterBandwidth
+	err = getAPI("/renter/bandwidth", &after)
+	signal.Stop(interrupt)
+	cleanup()
+	if err != nil {
+		die("Could not get bandwidth:", err)
+	}
+
+	fmt.Printf(`Upload:              %v in %v (%v)
+Download:            %v in %v (%v)
+Time to first byte:  %v
+The downloaded data matches the uploaded data.
+`, filesizeUnits(int64(size)), uploadTime.Round(time.Millisecond), throughput(size, uploadTime),
+		filesizeUnits(int64(size)), downloadTime.Round(time.Millisecond), throughput(size, downloadTime),
+		ttfb.Round(time.Millisecond))
+
+	contribs := bandwidthContributions(before.Hosts, after.Hosts)
+	if len(contribs) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Data exchanged with each host, including redundancy and protocol overhead:")
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Host\tUploaded\tDownloaded")
+	for _, c := range contribs {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", c.NetAddress, filesizeUnits(int64(c.Uploaded)), filesizeUnits(int64(c.Downloaded)))
+	}
+	w.Flush()
+}
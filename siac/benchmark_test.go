@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestBandwidthContributions checks that the data exchanged with each host is
+// the difference between two snapshots of the renter's bandwidth.
+func TestBandwidthContributions(t *testing.T) {
+	host := func(key byte, addr string, up, down uint64) api.RenterHostBandwidth {
+		return api.RenterHostBandwidth{RenterBandwidth: modules.RenterBandwidth{
+			HostPublicKey: types.SiaPublicKey{Key: []byte{key}},
+			NetAddress:    modules.NetAddress(addr),
+			UploadBytes:   up,
+			DownloadBytes: down,
+		}}
+	}
+	before := []api.RenterHostBandwidth{
+		host(1, "a:1", 100, 100),
+		host(2, "b:1", 100, 100),
+	}
+	after := []api.RenterHostBandwidth{
+		host(1, "a:1", 150, 300),
+		host(2, "b:1", 100, 100),
+		host(3, "c:1", 500, 0),
+	}
+	contribs := bandwidthContributions(before, after)
+	if len(contribs) != 2 {
+		t.Fatal("expected 2 contributions, got", contribs)
+	}
+	if c := contribs[0]; c.NetAddress != "c:1" || c.Uploaded != 500 || c.Downloaded != 0 {
+		t.Error("wrong contribution of the new host:", c)
+	}
+	if c := contribs[1]; c.NetAddress != "a:1" || c.Uploaded != 50 || c.Downloaded != 200 {
+		t.Error("wrong contribution of the existing host:", c)
+	}
+}
+
+// TestWriteBenchmarkFile checks that the test file has the requested size and
+// that its hash matches its contents.
+func TestWriteBenchmarkFile(t *testing.T) {
+	path, sum, err := writeBenchmarkFile(3<<20 + 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3<<20+5 {
+		t.Fatal("wrong file size:", len(data))
+	}
+	h := crypto.NewHash()
+	h.Write(data)
+	var expected crypto.Hash
+	copy(expected[:], h.Sum(nil))
+	if sum != expected {
+		t.Fatal("hash does not match the contents of the file")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// The API calls of siac have no time limit by default, so that long
+// operations such as adding a storage folder are not cut short. --timeout
+// limits how long a call may take, so that status queries fail fast when siad
+// hangs, and --retries retries calls that fail to reach siad, for example
+// while it restarts. Only GET requests are retried after they were sent;
+// other requests are retried only if siad could not be connected to, so that
+// a send is never made twice.
+
+var (
+	// apiTimeout is the time limit of each API call, including its retries,
+	// set by --timeout. Zero means no limit.
+	apiTimeout time.Duration
+
+	// apiRetries is the number of times a failed API call is retried, set by
+	// --retries.
+	apiRetries uint64
+
+	// retryDelay is the delay before the first retry of an API call. It
+	// doubles with each retry.
+	retryDelay = 500 * time.Millisecond
+)
+
+// retryTransport is an http.RoundTripper that retries requests that fail to
+// reach siad.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries uint64
+}
+
+// retryable returns whether a request that failed with err can be retried.
+func retryable(req *http.Request, err error) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := retryDelay
+	for attempt := uint64(0); ; attempt++ {
+		resp, err := rt.next.RoundTrip(req)
+		if err == nil || attempt == rt.retries || !retryable(req, err) {
+			return resp, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, err
+		}
+		delay *= 2
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// configureHTTPClient applies the timeout, retry and logging settings to the
+// HTTP client used for API calls.
+func configureHTTPClient() {
+	var transport http.RoundTripper = http.DefaultTransport
+	if verbose {
+		transport = verboseTransport{}
+	}
+	if apiRetries > 0 {
+		transport = retryTransport{next: transport, retries: apiRetries}
+	}
+	if transport == http.DefaultTransport {
+		transport = nil
+	}
+	http.DefaultClient.Transport = transport
+	http.DefaultClient.Timeout = apiTimeout
+}
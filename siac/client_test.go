@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countTransport is an http.RoundTripper that fails a number of times before
+// passing requests to http.DefaultTransport.
+type countTransport struct {
+	failures int
+	err      error
+	calls    int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (ct *countTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.calls++
+	if ct.calls <= ct.failures {
+		return nil, ct.err
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestRetryTransport checks which requests are retried, and how many times.
+func TestRetryTransport(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		bodies = append(bodies, req.PostForm.Encode())
+	}))
+	defer srv.Close()
+
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset")}
+	tests := []struct {
+		method   string
+		err      error
+		failures int
+		calls    int
+		success  bool
+	}{
+		{"GET", readErr, 2, 3, true},
+		{"GET", readErr, 3, 3, false},
+		{"POST", dialErr, 2, 3, true},
+		{"POST", readErr, 2, 1, false},
+	}
+	for _, test := range tests {
+		bodies = nil
+		ct := &countTransport{failures: test.failures, err: test.err}
+		client := &http.Client{Transport: retryTransport{next: ct, retries: 2}}
+		req, err := http.NewRequest(test.method, srv.URL, strings.NewReader("amount=1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := client.Do(req)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if (err == nil) != test.success || ct.calls != test.calls {
+			t.Errorf("%v after %v failures: expected %v calls and success %v, got %v calls and %v", test.method, test.failures, test.calls, test.success, ct.calls, err)
+		}
+		if test.method == "POST" && test.success && (len(bodies) != 1 || bodies[0] != "amount=1") {
+			t.Errorf("expected the body to be sent again on retry, got %q", bodies)
+		}
+	}
+}
+
+// TestTimeout checks that a call that exceeds --timeout is reported as such.
+func TestTimeout(t *testing.T) {
+	defer func(a string, d time.Duration) {
+		addr, apiTimeout = a, d
+		configureHTTPClient()
+	}(addr, apiTimeout)
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+	addr = srv.Listener.Addr().String()
+	apiTimeout = 50 * time.Millisecond
+	configureHTTPClient()
+
+	var cg struct{ Height int }
+	err := getAPI("/consensus", &cg)
+	var ce connectionError
+	if !errors.As(err, &ce) || !strings.Contains(err.Error(), "did not respond within 50ms") {
+		t.Fatal("expected a timeout, got", err)
+	}
+}
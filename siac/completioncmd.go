@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	completionCmd = &cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Print a shell completion script",
+		Long: `Print a completion script for bash, zsh or fish to stdout.
+
+Besides commands and flags, the script completes the arguments of commands
+from siad, such as renter file paths, contract IDs, pending transactions and
+the addresses that the wallet has sent to, described by their transaction
+labels.
+
+To load completions in the current bash session:
+
+	source <(siac completion bash)
+
+To load them for every session, write the script to your shell's completion
+directory, e.g.:
+
+	siac completion bash > /etc/bash_completion.d/siac
+	siac completion zsh > "${fpath[1]}/_siac"
+	siac completion fish > ~/.config/fish/completions/siac.fish`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Run:       wrap(completioncmd),
+	}
+)
+
+// completioncmd prints the completion script of a shell.
+func completioncmd(shell string) {
+	// The script completes the name that siac was invoked with.
+	rootCmd.Use = filepath.Base(os.Args[0])
+	var err error
+	switch shell {
+	case "bash":
+		err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		err = rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		err = rootCmd.GenFishCompletion(os.Stdout, true)
+	default:
+		dieUsage("Unsupported shell:", shell, "- must be bash, zsh or fish")
+	}
+	if err != nil {
+		die("Could not generate completion script:", err)
+	}
+}
+
+// isCompletionRequest returns whether cmd is the hidden command that the
+// completion scripts run to complete a command line.
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+// completionGet makes a GET request to the API and decodes the response into
+// obj, returning whether the call succeeded. Unlike getAPI, it never prompts
+// for a password and its response is not part of the JSON output, as a failed
+// completion should offer no candidates rather than interrupt the shell.
+func completionGet(call string, obj interface{}) bool {
+	url, err := apiURL(call)
+	if err != nil {
+		return false
+	}
+	var resp *http.Response
+	if apiPassword != "" {
+		resp, err = api.HttpGETAuthenticated(url, apiPassword)
+	} else {
+		resp, err = api.HttpGET(url)
+	}
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(obj) == nil
+}
+
+// completeArgs returns a completion function that completes the arguments at
+// the given positions with the candidates returned by fn, and the other
+// arguments with file names. A negative position completes every argument.
+func completeArgs(fn func() []string, positions ...int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		for _, pos := range positions {
+			if pos < 0 || pos == len(args) {
+				return fn(), cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+}
+
+// completeSiapaths returns the paths of the renter's files.
+func completeSiapaths() []string {
+	var rf api.RenterFiles
+	if !completionGet("/renter/files", &rf) {
+		return nil
+	}
+	paths := make([]string, 0, len(rf.Files))
+	for _, f := range rf.Files {
+		paths = append(paths, f.SiaPath)
+	}
+	return paths
+}
+
+// completeRenterContracts returns the IDs of the renter's contracts, described
+// by the address of their host.
+func completeRenterContracts() []string {
+	var rc api.RenterContracts
+	if !completionGet("/renter/contracts", &rc) {
+		return nil
+	}
+	ids := make([]string, 0, len(rc.Contracts))
+	for _, c := range rc.Contracts {
+		ids = append(ids, fmt.Sprintf("%v\t%v", c.ID, c.NetAddress))
+	}
+	return ids
+}
+
+// completeHostContracts returns the IDs of the host's storage obligations.
+func completeHostContracts() []string {
+	var hc api.HostContractsGET
+	if !completionGet("/host/contracts", &hc) {
+		return nil
+	}
+	ids := make([]string, 0, len(hc.Contracts))
+	for _, so := range hc.Contracts {
+		ids = append(ids, so.ObligationID.String())
+	}
+	return ids
+}
+
+// completeHostPubkeys returns the public keys of the hosts in the hostdb,
+// described by their addresses.
+func completeHostPubkeys() []string {
+	var ha api.HostdbAllGET
+	if !completionGet("/hostdb/all", &ha) {
+		return nil
+	}
+	keys := make([]string, 0, len(ha.Hosts))
+	for _, h := range ha.Hosts {
+		keys = append(keys, fmt.Sprintf("%v\t%v", h.PublicKeyString, h.NetAddress))
+	}
+	return keys
+}
+
+// completePeers returns the addresses of the gateway's peers.
+func completePeers() []string {
+	var gg api.GatewayGET
+	if !completionGet("/gateway", &gg) {
+		return nil
+	}
+	peers := make([]string, 0, len(gg.Peers))
+	for _, p := range gg.Peers {
+		peers = append(peers, string(p.NetAddress))
+	}
+	return peers
+}
+
+// completeBannedHosts returns the IP addresses banned by the gateway.
+func completeBannedHosts() []string {
+	var gg api.GatewayGET
+	if !completionGet("/gateway", &gg) {
+		return nil
+	}
+	return gg.BannedHosts
+}
+
+// completeWallets returns the names of the wallets loaded by siad.
+func completeWallets(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	var wg api.WalletsGET
+	if !completionGet("/wallets", &wg) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return wg.Wallets, cobra.ShellCompDirectiveNoFileComp
+}
+
+// transactionLabel returns the label of a transaction, or its memo if it has
+// no label, for use as the description of a completion.
+func transactionLabel(txid string, labels, memos map[string]string) string {
+	label := labels[txid]
+	if label == "" {
+		label = memos[txid]
+	}
+	return strings.Join(strings.Fields(label), " ")
+}
+
+// completePendingTxids returns the IDs of the wallet's unconfirmed
+// transactions, described by their labels.
+func completePendingTxids() []string {
+	var wtug api.WalletTransactionsUnconfirmedGET
+	if !completionGet(walletCall("/wallet/unconfirmed"), &wtug) {
+		return nil
+	}
+	txids := make([]string, 0, len(wtug.Transactions))
+	for _, txn := range wtug.Transactions {
+		txid := txn.TransactionID.String()
+		if label := transactionLabel(txid, wtug.Labels, wtug.Memos); label != "" {
+			txid += "\t" + label
+		}
+		txids = append(txids, txid)
+	}
+	return txids
+}
+
+// completeTpoolTxids returns the IDs of the transactions in the transaction
+// pool, highest fee per byte first.
+func completeTpoolTxids() []string {
+	var ttg api.TpoolTransactionsGET
+	if !completionGet("/tpool/transactions", &ttg) {
+		return nil
+	}
+	txids := make([]string, 0, len(ttg.Transactions))
+	for _, txn := range ttg.Transactions {
+		txids = append(txids, txn.ID.String())
+	}
+	return txids
+}
+
+// completeDestinations returns a function that returns the addresses outside
+// the wallet that it has sent outputs of the given type to, most recent
+// first. Each address is described by the label of the transaction that last
+// sent to it.
+func completeDestinations(fundType types.Specifier) func() []string {
+	return func() []string {
+		var cg api.ConsensusGET
+		if !completionGet("/consensus", &cg) {
+			return nil
+		}
+		var wtg api.WalletTransactionsGET
+		if !completionGet(walletCall(fmt.Sprintf("/wallet/transactions?startheight=0&endheight=%v", cg.Height)), &wtg) {
+			return nil
+		}
+		txns := append(wtg.ConfirmedTransactions, wtg.UnconfirmedTransactions...)
+		seen := make(map[types.UnlockHash]bool)
+		var dests []string
+		for i := len(txns) - 1; i >= 0; i-- {
+			txid := txns[i].TransactionID.String()
+			label := transactionLabel(txid, wtg.Labels, wtg.Memos)
+			for _, output := range txns[i].Outputs {
+				if output.FundType != fundType || output.WalletAddress || seen[output.RelatedAddress] {
+					continue
+				}
+				seen[output.RelatedAddress] = true
+				dest := output.RelatedAddress.String()
+				if label != "" {
+					dest += "\t" + label
+				}
+				dests = append(dests, dest)
+			}
+		}
+		return dests
+	}
+}
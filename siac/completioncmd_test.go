@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/spf13/cobra"
+)
+
+// TestCompletions checks that arguments are completed with the renter's files
+// and the addresses that the wallet has sent to.
+func TestCompletions(t *testing.T) {
+	defer func(a string) { addr = a }(addr)
+
+	dest1 := types.UnlockHash{1}
+	dest2 := types.UnlockHash{2}
+	txn := func(id byte, outputs ...modules.ProcessedOutput) modules.ProcessedTransaction {
+		return modules.ProcessedTransaction{TransactionID: types.TransactionID{id}, Outputs: outputs}
+	}
+	responses := map[string]interface{}{
+		"/renter/files": api.RenterFiles{Files: []modules.FileInfo{{SiaPath: "foo"}, {SiaPath: "dir/bar"}}},
+		"/consensus":    api.ConsensusGET{Height: 10},
+		"/wallet/transactions": api.WalletTransactionsGET{
+			ConfirmedTransactions: []modules.ProcessedTransaction{
+				txn(1, modules.ProcessedOutput{FundType: types.SpecifierSiacoinOutput, RelatedAddress: dest1}),
+				txn(2, modules.ProcessedOutput{FundType: types.SpecifierSiacoinOutput, RelatedAddress: dest2},
+					modules.ProcessedOutput{FundType: types.SpecifierSiacoinOutput, RelatedAddress: types.UnlockHash{3}, WalletAddress: true}),
+			},
+			UnconfirmedTransactions: []modules.ProcessedTransaction{
+				txn(3, modules.ProcessedOutput{FundType: types.SpecifierSiacoinOutput, RelatedAddress: dest1}),
+			},
+			Labels: map[string]string{types.TransactionID{3}.String(): "rent\tmarch"},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp, ok := responses[req.URL.Path]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		if req.URL.Path == "/wallet/transactions" && req.URL.Query().Get("endheight") != "10" {
+			t.Error("wrong endheight:", req.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+	addr = srv.Listener.Addr().String()
+
+	complete := completeArgs(completeSiapaths, 0)
+	paths, directive := complete(nil, nil, "")
+	if !reflect.DeepEqual(paths, []string{"foo", "dir/bar"}) || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Error("wrong siapath completions:", paths, directive)
+	}
+	// The second argument is a local file.
+	if paths, directive = complete(nil, []string{"foo"}, ""); paths != nil || directive != cobra.ShellCompDirectiveDefault {
+		t.Error("expected file completion for the second argument, got", paths, directive)
+	}
+
+	// Destinations are listed most recent first, once each, described by the
+	// label of the most recent transaction. Outputs to the wallet itself are
+	// not destinations.
+	dests := completeDestinations(types.SpecifierSiacoinOutput)()
+	exp := []string{dest1.String() + "\trent march", dest2.String()}
+	if !reflect.DeepEqual(dests, exp) {
+		t.Errorf("expected destinations %v, got %v", exp, dests)
+	}
+	if dests := completeDestinations(types.SpecifierSiafundOutput)(); len(dests) != 0 {
+		t.Error("expected no siafund destinations, got", dests)
+	}
+
+	// A failed call offers no candidates.
+	if ids := completeRenterContracts(); ids != nil {
+		t.Error("expected no contract completions, got", ids)
+	}
+}
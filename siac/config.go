@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// siac reads its settings from flags, environment variables and a config
+// file, in that order of precedence. The config file, ~/.siac/config by
+// default, holds "key = value" lines. Lines before the first [section] apply
+// to every profile, and the lines of a [profile] section apply when that
+// profile is selected with --profile or SIA_PROFILE. Blank lines and lines
+// starting with '#' are ignored. For example:
+//
+//	unit = SC
+//
+//	[host2]
+//	addr = 10.0.0.2:9980
+//	password = foo
+
+var (
+	// configPath is the path of the config file, set by --config.
+	configPath string
+
+	// profile selects a section of the config file, set by --profile.
+	profile string
+)
+
+// configSettings are the settings that can be read from the environment and
+// the config file. Each setting is overridden by its flag.
+var configSettings = []struct {
+	key  string // key in the config file
+	flag string // flag that overrides the setting
+	env  string // environment variable that overrides the config file
+	set  func(string) error
+}{
+	{"addr", "addr", "SIA_API_ADDR", func(v string) error { addr = v; return nil }},
+	{"password", "", "SIA_API_PASSWORD", func(v string) error { apiPassword = v; return nil }},
+	{"cacert", "ca-cert", "SIA_API_CA_CERT", func(v string) error { caCertPath = v; return nil }},
+	{"fingerprint", "cert-fingerprint", "SIA_API_CERT_FINGERPRINT", func(v string) error { certFingerprint = v; return nil }},
+	{"unit", "unit", "SIA_CURRENCY_UNIT", func(v string) error { currencyUnit = v; return nil }},
+	{"numberformat", "number-format", "SIA_NUMBER_FORMAT", func(v string) error { numberFormat = v; return nil }},
+	{"timeout", "timeout", "SIA_API_TIMEOUT", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return fmt.Errorf("timeout must be a duration such as 30s, not %q", v)
+		}
+		apiTimeout = d
+		return nil
+	}},
+	{"retries", "retries", "SIA_API_RETRIES", func(v string) error {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("retries must be a number, not %q", v)
+		}
+		apiRetries = n
+		return nil
+	}},
+	{"verbose", "verbose", "SIA_VERBOSE", func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("verbose must be true or false, not %q", v)
+		}
+		verbose = b
+		return nil
+	}},
+	{"output", "json", "SIA_OUTPUT", func(v string) error {
+		switch v {
+		case "text":
+			jsonOutput = false
+		case "json":
+			jsonOutput = true
+		default:
+			return fmt.Errorf("output must be text or json, not %q", v)
+		}
+		return nil
+	}},
+}
+
+// defaultConfigPath returns the default path of the config file.
+func defaultConfigPath() string {
+	if path := os.Getenv("SIAC_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".siac", "config")
+}
+
+// readConfig reads the settings of a profile from a config file. The settings
+// that apply to every profile are returned when profile is empty. A missing
+// config file has no settings, unless a profile is requested.
+func readConfig(path, profile string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && profile == "" {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	settings := make(map[string]string)
+	section := ""
+	foundProfile := false
+	s := bufio.NewScanner(f)
+	for lineNum := 1; s.Scan(); lineNum++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == profile {
+				foundProfile = true
+			}
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("%v:%v: expected key = value", path, lineNum)
+		}
+		if section != "" && section != profile {
+			continue
+		}
+		settings[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if profile != "" && !foundProfile {
+		return nil, fmt.Errorf("profile %q not found in %v", profile, path)
+	}
+	for key := range settings {
+		if !validConfigKey(key) {
+			return nil, fmt.Errorf("%v: unknown setting %q", path, key)
+		}
+	}
+	return settings, nil
+}
+
+// validConfigKey returns whether key is a setting of the config file.
+func validConfigKey(key string) bool {
+	if strings.HasPrefix(key, aliasPrefix) && len(key) > len(aliasPrefix) {
+		return true
+	}
+	for _, cs := range configSettings {
+		if cs.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// loadConfig applies the settings of the environment and the config file that
+// are not overridden by flags of cmd.
+func loadConfig(cmd *cobra.Command) error {
+	if profile == "" {
+		profile = os.Getenv("SIA_PROFILE")
+	}
+	settings, err := readConfig(configPath, profile)
+	if err != nil {
+		return err
+	}
+	for _, cs := range configSettings {
+		if cs.flag != "" && cmd.Flags().Changed(cs.flag) {
+			continue
+		}
+		v, ok := os.LookupEnv(cs.env)
+		if !ok || v == "" {
+			v, ok = settings[cs.key]
+		}
+		if !ok {
+			continue
+		}
+		if err := cs.set(v); err != nil {
+			return fmt.Errorf("invalid %v: %v", cs.key, err)
+		}
+	}
+	return nil
+}
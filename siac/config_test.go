@@ -0,0 +1,120 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+
+	"github.com/spf13/cobra"
+)
+
+const testConfig = `# shared settings
+unit = SC
+
+[host2]
+addr = 10.0.0.2:9980
+password = foo
+
+[host3]
+addr = 10.0.0.3:9980
+output = json
+`
+
+// writeTestConfig writes a config file for a test and returns its path.
+func writeTestConfig(t *testing.T, contents string) string {
+	dir := build.TempDir("siac", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestReadConfig checks that the settings of a profile are read from a config
+// file.
+func TestReadConfig(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+
+	settings, err := readConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 1 || settings["unit"] != "SC" {
+		t.Error("wrong shared settings:", settings)
+	}
+	settings, err = readConfig(path, "host2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 3 || settings["addr"] != "10.0.0.2:9980" || settings["password"] != "foo" || settings["unit"] != "SC" {
+		t.Error("wrong host2 settings:", settings)
+	}
+	if _, err := readConfig(path, "host4"); err == nil {
+		t.Error("expected an error for a missing profile")
+	}
+
+	// A missing config file is only an error if a profile is requested.
+	missing := filepath.Join(filepath.Dir(path), "missing")
+	if settings, err := readConfig(missing, ""); err != nil || len(settings) != 0 {
+		t.Error("expected no settings for a missing config file:", settings, err)
+	}
+	if _, err := readConfig(missing, "host2"); err == nil {
+		t.Error("expected an error for a profile of a missing config file")
+	}
+
+	for _, contents := range []string{"addr", "foo = bar"} {
+		if _, err := readConfig(writeTestConfig(t, contents), ""); err == nil {
+			t.Errorf("expected an error for config %q", contents)
+		}
+	}
+}
+
+// TestLoadConfig checks that flags override environment variables, which
+// override the config file.
+func TestLoadConfig(t *testing.T) {
+	defer func(a, u string, j bool) {
+		addr, currencyUnit, jsonOutput = a, u, j
+		apiPassword, profile, configPath = "", "", ""
+	}(addr, currencyUnit, jsonOutput)
+	os.Unsetenv("SIA_API_ADDR")
+	os.Unsetenv("SIA_API_PASSWORD")
+	os.Unsetenv("SIA_OUTPUT")
+	os.Unsetenv("SIA_PROFILE")
+	defer os.Unsetenv("SIA_API_ADDR")
+	defer os.Unsetenv("SIA_OUTPUT")
+
+	configPath = writeTestConfig(t, testConfig)
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVarP(&addr, "addr", "a", "localhost:9980", "")
+	cmd.Flags().StringVarP(&currencyUnit, "unit", "", "auto", "")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "", false, "")
+	if err := cmd.ParseFlags([]string{"--unit", "H"}); err != nil {
+		t.Fatal(err)
+	}
+
+	profile = "host3"
+	os.Setenv("SIA_API_ADDR", "10.0.0.4:9980")
+	if err := loadConfig(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if addr != "10.0.0.4:9980" {
+		t.Error("environment did not override config file:", addr)
+	}
+	if currencyUnit != "H" {
+		t.Error("flag was overridden:", currencyUnit)
+	}
+	if !jsonOutput {
+		t.Error("output was not read from the config file")
+	}
+
+	os.Setenv("SIA_OUTPUT", "yaml")
+	if err := loadConfig(cmd); err == nil {
+		t.Error("expected an error for an invalid output")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// Sends cannot be undone, so siac prints what it is about to send and waits
+// for the user to type "yes" before submitting the transaction. The --yes flag
+// skips the confirmation for scripts.
+
+// walletYes is set by the --yes flag.
+var walletYes bool
+
+// sendDetail is a line of the summary shown before a send.
+type sendDetail struct {
+	name, value string
+}
+
+// estimatedFee returns the fee that siad is expected to pay for a transaction
+// of the given size in bytes. The call is made with apiGet rather than getAPI
+// so that the response is not part of the command's JSON output.
+func estimatedFee(size uint64) string {
+	resp, err := apiGet("/tpool/fee")
+	if err != nil {
+		return "unknown"
+	}
+	defer resp.Body.Close()
+	var tfg api.TpoolFeeGET
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&tfg) != nil {
+		return "unknown"
+	}
+	return currencyUnits(tfg.Maximum.Mul64(size))
+}
+
+// siacoinAmount describes an amount of hastings in SC, along with the exact
+// number of hastings.
+func siacoinAmount(hastings string) string {
+	var c types.Currency
+	if _, err := fmt.Sscan(hastings, &c); err != nil {
+		return hastings + " H"
+	}
+	return fmt.Sprintf("%v (%v)", fixedCurrencyUnits(c, "SC"), fixedCurrencyUnits(c, "H"))
+}
+
+// readConfirmation writes the details of a send to w and reads the user's
+// answer from r. The send is confirmed only if the answer is "yes".
+func readConfirmation(r io.Reader, w io.Writer, details []sendDetail) bool {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, d := range details {
+		fmt.Fprintf(tw, "%v:\t%v\n", d.name, d.value)
+	}
+	tw.Flush()
+	fmt.Fprint(w, "Type 'yes' to send: ")
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(answer), "yes")
+}
+
+// confirmSend asks the user to confirm a send, unless --yes was given, and
+// exits if the send is not confirmed. In JSON and quiet mode the details are
+// written to stderr, as stdout only carries the output of the command.
+func confirmSend(details ...sendDetail) {
+	if walletYes {
+		return
+	}
+	if !readConfirmation(os.Stdin, promptOutput(), details) {
+		die("Send cancelled. Use --yes to send without confirmation.")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestReadConfirmation checks that a send is only confirmed by typing yes.
+func TestReadConfirmation(t *testing.T) {
+	details := []sendDetail{{"Amount", "1 SC"}, {"Destination", "foo"}}
+	tests := []struct {
+		input     string
+		confirmed bool
+	}{
+		{"yes\n", true},
+		{" YES \n", true},
+		{"yes", true},
+		{"y\n", false},
+		{"no\n", false},
+		{"\n", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		if confirmed := readConfirmation(strings.NewReader(test.input), &out, details); confirmed != test.confirmed {
+			t.Errorf("%q: expected %v, got %v", test.input, test.confirmed, confirmed)
+		}
+		if !strings.Contains(out.String(), "Destination:  foo") {
+			t.Errorf("details were not printed: %q", out.String())
+		}
+	}
+}
+
+// TestSiacoinAmount checks that amounts are shown in SC and hastings.
+func TestSiacoinAmount(t *testing.T) {
+	if s := siacoinAmount("1500000000000000000000000"); s != "1.5 SC (1500000000000000000000000 H)" {
+		t.Error("unexpected amount:", s)
+	}
+}
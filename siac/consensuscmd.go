@@ -12,39 +12,64 @@ import (
 
 var (
 	consensusCmd = &cobra.Command{
-		Use:   "consensus",
-		Short: "Print the current state of consensus",
-		Long:  "Print the current state of consensus such as current block, block height, and target.",
-		Run:   wrap(consensuscmd),
+		Use:     "consensus",
+		Aliases: []string{"c"},
+		Short:   "Print the current state of consensus",
+		Long: `Print the current state of consensus such as current block, block height, and
+target, along with the estimated height of the network, the progress of the
+sync, and whether the node is on the heaviest fork it knows of.`,
+		Run: wrap(consensuscmd),
 	}
 )
 
 // consensuscmd is the handler for the command `siac consensus`.
-// Prints the current state of consensus.
+// Prints the current state of consensus and the progress of the sync.
 func consensuscmd() {
 	var cg api.ConsensusGET
 	err := getAPI("/consensus", &cg)
 	if err != nil {
 		die("Could not get current consensus state:", err)
 	}
-	if cg.Synced {
-		fmt.Printf(`Synced: %v
-Block:      %v
-Height:     %v
-Target:     %v
-Difficulty: %v
-`, yesNo(cg.Synced), cg.CurrentBlock, cg.Height, cg.Target, cg.Difficulty)
-	} else {
-		estimatedHeight := estimatedHeightAt(time.Now())
-		estimatedProgress := float64(cg.Height) / float64(estimatedHeight) * 100
-		if estimatedProgress > 100 {
-			estimatedProgress = 100
+	targetHeight := syncTargetHeight(cg, time.Now())
+	progress := 100.0
+	if cg.Height < targetHeight {
+		progress = float64(cg.Height) / float64(targetHeight) * 100
+	}
+	fork := "Yes"
+	if !cg.OnHeaviestFork {
+		fork = fmt.Sprintf("No, a heavier fork reaches height %v", cg.HeaviestKnownHeight)
+	}
+	fmt.Printf(`Synced:         %v
+Block:          %v
+Height:         %v
+Target Height:  %v (estimated)
+Progress:       %.1f%%
+Target:         %v
+Difficulty:     %v
+Heaviest Fork:  %v
+`, yesNo(cg.Synced), cg.CurrentBlock, cg.Height, targetHeight, progress, cg.Target, cg.Difficulty, fork)
+	printQuiet(cg.Height)
+	if !cg.Synced {
+		fmt.Println("\nsiad is downloading the blockchain. If the height does not increase over a few")
+		fmt.Println("minutes, check that siad has peers with 'siac gateway'.")
+	}
+}
+
+// syncTargetHeight estimates the height of the network. While the node is
+// syncing, the height is estimated from the current time; otherwise the
+// current height is used. The height of the heaviest block that the node has
+// seen is used if it is greater.
+func syncTargetHeight(cg api.ConsensusGET, now time.Time) types.BlockHeight {
+	target := cg.Height
+	if !cg.Synced {
+		if estimated := estimatedHeightAt(now); estimated > target {
+			target = estimated
 		}
-		fmt.Printf(`Synced: %v
-Height: %v
-Progress (estimated): %.1f%%
-`, yesNo(cg.Synced), cg.Height, estimatedProgress)
 	}
+	if cg.HeaviestKnownHeight > target {
+		target = cg.HeaviestKnownHeight
+	}
+	return target
 }
 
 // estimatedHeightAt returns the estimated block height for the given time.
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/NebulousLabs/Sia/api"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -47,3 +48,23 @@ func TestEstimatedHeightAt(t *testing.T) {
 		}
 	}
 }
+
+// TestSyncTargetHeight checks that the estimated height of the network
+// accounts for the time while syncing, and for heavier forks.
+func TestSyncTargetHeight(t *testing.T) {
+	now := time.Date(2017, time.April, 14, 23, 29, 49, 0, time.UTC)
+	tests := []struct {
+		cg     api.ConsensusGET
+		target types.BlockHeight
+	}{
+		{api.ConsensusGET{Synced: false, Height: 1000}, 100e3 + 160},
+		{api.ConsensusGET{Synced: false, Height: 1000, HeaviestKnownHeight: 200e3}, 200e3},
+		{api.ConsensusGET{Synced: true, Height: 1000, HeaviestKnownHeight: 1000}, 1000},
+		{api.ConsensusGET{Synced: true, Height: 1000, HeaviestKnownHeight: 1002}, 1002},
+	}
+	for i, test := range tests {
+		if target := syncTargetHeight(test.cg, now); target != test.target {
+			t.Errorf("test %v: expected %v, got %v", i, test.target, target)
+		}
+	}
+}
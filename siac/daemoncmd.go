@@ -2,17 +2,91 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/spf13/cobra"
 )
 
 var (
+	daemonCmd = &cobra.Command{
+		Use:   "daemon",
+		Short: "Perform daemon actions",
+		Long:  "Diagnose the Sia daemon.",
+	}
+
+	daemonConfigCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Show the settings of the Sia daemon",
+		Long: `Show the effective settings of the Sia daemon, and whether each setting is the
+default or was set by a flag, the environment or the config file of siad. The
+API password is redacted. The host settings of the config file, which are
+applied when siad starts, are listed as well.`,
+		Run: wrap(daemonconfigcmd),
+	}
+
+	daemonLogCmd = &cobra.Command{
+		Use:   "log",
+		Short: "Show the log levels of the Sia daemon",
+		Long: `Show the log level of each module of the Sia daemon, the default level of the
+modules without a level of their own, and the rotation of the log files.`,
+		Run: wrap(daemonlogcmd),
+	}
+
+	daemonLogLevelCmd = &cobra.Command{
+		Use:   "loglevel [level] [module]",
+		Short: "Change the log level of the Sia daemon",
+		Long: `Change the log level of a module of the Sia daemon, or the default level of
+the modules without a level of their own if no module is given. The level is
+debug, info, warn or error; the level 'default' makes a module follow the
+default level again. The levels are reset when siad restarts, see the
+--log-level flag of siad.`,
+		Run: daemonloglevelcmd,
+	}
+
+	daemonProfileCmd = &cobra.Command{
+		Use:   "profile [name] [duration]",
+		Short: "Save a runtime profile of the Sia daemon",
+		Long: `Save a runtime profile of the Sia daemon to disk, for 'go tool pprof', or an
+execution trace for 'go tool trace'. The name is one of the profiles listed by
+'siac daemon profiling', such as cpu, heap, goroutine, mutex, block or trace.
+The cpu profile and the trace are collected for the given duration, 30s by
+default. Profiling must be on, see 'siac daemon profiling'.`,
+		Run: daemonprofilecmd,
+	}
+
+	daemonProfilingCmd = &cobra.Command{
+		Use:   "profiling [on|off]",
+		Short: "Turn profiling of the Sia daemon on or off",
+		Long: `Turn the serving of runtime profiles by the Sia daemon on or off, or show
+whether it is on. While profiling is on, the daemon records mutex contention
+and blocking events, which slows it down a little. siad can also be started
+with profiling on with --profile p.`,
+		Run: daemonprofilingcmd,
+	}
+
+	crashesCmd = &cobra.Command{
+		Use:   "crashes [id]",
+		Short: "List the crash reports of the Sia daemon",
+		Long: `List the panics and critical errors recorded by the Sia daemon, newest
+first. If an id is given, the full report of that crash is printed, including
+its stack trace.`,
+		Run: crashescmd,
+	}
+
 	stopCmd = &cobra.Command{
 		Use:   "stop",
 		Short: "Stop the Sia daemon",
-		Long:  "Stop the Sia daemon.",
-		Run:   wrap(stopcmd),
+		Long: `Stop the Sia daemon. siad finishes the API requests in flight and closes its
+modules one by one; the progress is shown until siad has stopped.`,
+		Run: wrap(stopcmd),
 	}
 
 	updateCmd = &cobra.Command{
@@ -37,6 +111,18 @@ var (
 	}
 )
 
+const (
+	// stopPollInterval is how often siac stop checks the progress of the
+	// shutdown.
+	stopPollInterval = 250 * time.Millisecond
+)
+
+var (
+	// daemonProfileOutput is the file that a profile is saved to, set by
+	// --output.
+	daemonProfileOutput string
+)
+
 type updateInfo struct {
 	Available bool   `json:"available"`
 	Version   string `json:"version"`
@@ -52,19 +138,85 @@ func versioncmd() {
 	var versioninfo daemonVersion
 	err := getAPI("/daemon/version", &versioninfo)
 	if err != nil {
-		fmt.Println("Could not get daemon version:", err)
-		return
+		die("Could not get daemon version:", err)
 	}
 	fmt.Println("Sia Daemon v" + versioninfo.Version)
 }
 
+// crashescmd is the handler for the command `siac crashes [id]`.
+// Lists the crash reports recorded by the daemon.
+func crashescmd(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	var dc struct {
+		Crashes []build.CrashReport `json:"crashes"`
+	}
+	err := getAPI("/daemon/crashes", &dc)
+	if err != nil {
+		die("Could not get crash reports:", err)
+	}
+	if len(args) == 1 {
+		for _, cr := range dc.Crashes {
+			if cr.ID != args[0] {
+				continue
+			}
+			fmt.Printf(`ID:      %v
+Time:    %v
+Kind:    %v
+Module:  %v
+Version: %v (%v, %v/%v)
+Message: %v
+
+%v`, cr.ID, cr.Time, cr.Kind, cr.Module, cr.Version, cr.GoVersion, cr.OS, cr.Arch, cr.Message, cr.Stack)
+			return
+		}
+		die("No crash report with id", args[0])
+	}
+	if len(dc.Crashes) == 0 {
+		fmt.Println("No crashes have been recorded.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTime\tModule\tKind\tMessage")
+	for _, cr := range dc.Crashes {
+		msg := strings.SplitN(cr.Message, "\n", 2)[0]
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", cr.ID, cr.Time.Format("2006-01-02 15:04:05"), cr.Module, cr.Kind, msg)
+	}
+	w.Flush()
+}
+
 // stopcmd is the handler for the command `siac stop`.
-// Stops the daemon.
+// Stops the daemon, and shows the progress until it has stopped.
 func stopcmd() {
 	err := get("/daemon/stop")
 	if err != nil {
 		die("Could not stop daemon:", err)
 	}
+	var draining bool
+	var closing string
+	for {
+		var ds struct {
+			InFlightRequests int    `json:"inflightrequests"`
+			Closing          string `json:"closing"`
+			ModulesClosed    int    `json:"modulesclosed"`
+			ModulesTotal     int    `json:"modulestotal"`
+		}
+		if err := getAPI("/daemon/shutdown", &ds); err != nil {
+			// siad has stopped serving the API.
+			break
+		}
+		if ds.Closing == "" && ds.InFlightRequests > 0 && !draining {
+			fmt.Printf("Waiting for %v API requests to finish...\n", ds.InFlightRequests)
+			draining = true
+		}
+		if ds.Closing != "" && ds.Closing != closing {
+			fmt.Printf("(%d/%d) Closing %v...\n", ds.ModulesClosed+1, ds.ModulesTotal, ds.Closing)
+			closing = ds.Closing
+		}
+		time.Sleep(stopPollInterval)
+	}
 	fmt.Println("Sia daemon stopped.")
 }
 
@@ -72,8 +224,7 @@ func updatecmd() {
 	var update updateInfo
 	err := getAPI("/daemon/update", &update)
 	if err != nil {
-		fmt.Println("Could not check for update:", err)
-		return
+		die("Could not check for update:", err)
 	}
 	if !update.Available {
 		fmt.Println("Already up to date.")
@@ -82,8 +233,7 @@ func updatecmd() {
 
 	err = post("/daemon/update", "")
 	if err != nil {
-		fmt.Println("Could not apply update:", err)
-		return
+		die("Could not apply update:", err)
 	}
 	fmt.Printf("Updated to version %s! Restart siad now.\n", update.Version)
 }
@@ -92,8 +242,7 @@ func updatecheckcmd() {
 	var update updateInfo
 	err := getAPI("/daemon/update", &update)
 	if err != nil {
-		fmt.Println("Could not check for update:", err)
-		return
+		die("Could not check for update:", err)
 	}
 	if update.Available {
 		fmt.Printf("A new release (v%s) is available! Run 'siac update' to install it.\n", update.Version)
@@ -101,3 +250,195 @@ func updatecheckcmd() {
 		fmt.Println("Up to date.")
 	}
 }
+
+// daemonprofilecmd is the handler for the command `siac daemon profile [name]
+// [duration]`. Saves a profile of the daemon to disk.
+func daemonprofilecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	name := args[0]
+	call := "/daemon/profile/" + name
+	if len(args) == 2 {
+		if name != "cpu" && name != "trace" {
+			dieUsage("Only the cpu profile and the trace take a duration")
+		}
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d < time.Second {
+			dieUsage("Could not parse duration:", args[1])
+		}
+		call += "?seconds=" + strconv.Itoa(int(d/time.Second))
+	} else if name == "cpu" || name == "trace" {
+		call += "?seconds=30"
+	}
+	output := daemonProfileOutput
+	if output == "" {
+		output = name + ".pprof"
+		if name == "trace" {
+			output = "trace.out"
+		}
+	}
+	output = abs(output)
+
+	resp, err := apiGet(call)
+	if err != nil {
+		die("Could not get profile:", err)
+	}
+	defer resp.Body.Close()
+	file, err := os.Create(output)
+	if err != nil {
+		die("Could not save profile:", err)
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		die("Could not save profile:", err)
+	}
+	if err := file.Close(); err != nil {
+		die("Could not save profile:", err)
+	}
+	tool := "pprof"
+	if name == "trace" {
+		tool = "trace"
+	}
+	fmt.Printf("Saved the %v profile to %v. View it with 'go tool %v %v'.\n", name, output, tool, output)
+}
+
+// daemonprofilingcmd is the handler for the command `siac daemon profiling
+// [on|off]`. Turns profiling on or off, or shows whether it is on.
+func daemonprofilingcmd(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	if len(args) == 1 {
+		var enable bool
+		switch args[0] {
+		case "on":
+			enable = true
+		case "off":
+		default:
+			dieUsage("Expected on or off, got", args[0])
+		}
+		err := post("/daemon/profile", "enable="+strconv.FormatBool(enable))
+		if err != nil {
+			die("Could not turn profiling "+args[0]+":", err)
+		}
+		fmt.Println("Profiling is " + args[0] + ".")
+		return
+	}
+	var dp struct {
+		Enabled  bool     `json:"enabled"`
+		Profiles []string `json:"profiles"`
+	}
+	err := getAPI("/daemon/profile", &dp)
+	if err != nil {
+		die("Could not get profiling status:", err)
+	}
+	if dp.Enabled {
+		fmt.Println("Profiling is on.")
+	} else {
+		fmt.Println("Profiling is off.")
+	}
+	fmt.Println("Profiles:", strings.Join(dp.Profiles, ", "))
+}
+
+// daemonconfigcmd is the handler for the command `siac daemon config`.
+// Shows the effective settings of the daemon.
+func daemonconfigcmd() {
+	var dc struct {
+		File     string `json:"file"`
+		Settings []struct {
+			Name   string `json:"name"`
+			Value  string `json:"value"`
+			Source string `json:"source"`
+		} `json:"settings"`
+		Host map[string]string `json:"host"`
+	}
+	err := getAPI("/daemon/config", &dc)
+	if err != nil {
+		die("Could not get daemon config:", err)
+	}
+	if dc.File == "" {
+		fmt.Println("Config file: none")
+	} else {
+		fmt.Println("Config file:", dc.File)
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Setting\tValue\tSource")
+	for _, s := range dc.Settings {
+		fmt.Fprintf(w, "%v\t%q\t%v\n", s.Name, s.Value, s.Source)
+	}
+	w.Flush()
+	if len(dc.Host) == 0 {
+		return
+	}
+	names := make([]string, 0, len(dc.Host))
+	for name := range dc.Host {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println()
+	fmt.Println("Host settings of the config file:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %v\t%v\n", name, dc.Host[name])
+	}
+	w.Flush()
+}
+
+// daemonlogcmd is the handler for the command `siac daemon log`.
+// Shows the log levels of the daemon.
+func daemonlogcmd() {
+	var dl struct {
+		Default    string            `json:"default"`
+		Modules    map[string]string `json:"modules"`
+		MaxSize    int64             `json:"maxsize"`
+		MaxBackups int               `json:"maxbackups"`
+	}
+	err := getAPI("/daemon/log", &dl)
+	if err != nil {
+		die("Could not get log levels:", err)
+	}
+	fmt.Println("Default level:", dl.Default)
+	if dl.MaxSize == 0 {
+		fmt.Println("Rotation:      off")
+	} else {
+		fmt.Printf("Rotation:      at %v, keeping %v files\n", filesizeUnits(dl.MaxSize), dl.MaxBackups)
+	}
+	modules := make([]string, 0, len(dl.Modules))
+	for module := range dl.Modules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Module\tLevel")
+	for _, module := range modules {
+		fmt.Fprintf(w, "%v\t%v\n", module, dl.Modules[module])
+	}
+	w.Flush()
+}
+
+// daemonloglevelcmd is the handler for the command `siac daemon loglevel
+// [level] [module]`. Changes the log level of a module, or the default level.
+func daemonloglevelcmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	values := url.Values{"level": {args[0]}}
+	if len(args) == 2 {
+		values.Set("module", args[1])
+	}
+	err := post("/daemon/log", values.Encode())
+	if err != nil {
+		die("Could not change the log level:", err)
+	}
+	if len(args) == 2 {
+		fmt.Printf("The log level of %v is %v.\n", args[1], args[0])
+	} else {
+		fmt.Printf("The default log level is %v.\n", args[0])
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// With --dry-run, the send commands ask siad to build and sign the
+// transactions of a send without broadcasting them, and print their inputs,
+// outputs and fees instead. Nothing is sent, so no confirmation is needed.
+
+// walletAddresses returns the addresses of the wallet, so that outputs to the
+// wallet, such as change, can be marked. The call is made with apiGet rather
+// than getAPI so that the response is not part of the command's JSON output.
+func walletAddresses() map[types.UnlockHash]bool {
+	addrs := make(map[types.UnlockHash]bool)
+	resp, err := apiGet(walletCall("/wallet/addresses"))
+	if err != nil {
+		return addrs
+	}
+	defer resp.Body.Close()
+	var wag api.WalletAddressesGET
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&wag) != nil {
+		return addrs
+	}
+	for _, addr := range wag.Addresses {
+		addrs[addr] = true
+	}
+	return addrs
+}
+
+// printDryRun prints the inputs, outputs and fees of the transactions built by
+// a dry run. The value of each input is not known to siac, but the inputs of a
+// transaction add up to its outputs and fees.
+func printDryRun(txns []types.Transaction, wallet map[types.UnlockHash]bool) {
+	address := func(uh types.UnlockHash) string {
+		if wallet[uh] {
+			return uh.String() + " (wallet)"
+		}
+		return uh.String()
+	}
+
+	fmt.Println("Dry run: the transactions were built but not sent.")
+	var totalFee types.Currency
+	for i, txn := range txns {
+		var coinsOut, fundsOut, fee types.Currency
+		for _, sco := range txn.SiacoinOutputs {
+			coinsOut = coinsOut.Add(sco.Value)
+		}
+		for _, sfo := range txn.SiafundOutputs {
+			fundsOut = fundsOut.Add(sfo.Value)
+		}
+		for _, mf := range txn.MinerFees {
+			fee = fee.Add(mf)
+		}
+		totalFee = totalFee.Add(fee)
+
+		fmt.Printf("\nTransaction %v of %v: %v\n", i+1, len(txns), txn.ID())
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if len(txn.SiacoinInputs) > 0 {
+			fmt.Fprintf(w, "  Siacoin inputs:\t%v\ttotal %v\n", len(txn.SiacoinInputs), currencyUnits(coinsOut.Add(fee)))
+		}
+		for _, sci := range txn.SiacoinInputs {
+			fmt.Fprintf(w, "    %v\tfrom %v\n", sci.ParentID, address(sci.UnlockConditions.UnlockHash()))
+		}
+		if len(txn.SiafundInputs) > 0 {
+			fmt.Fprintf(w, "  Siafund inputs:\t%v\ttotal %v\n", len(txn.SiafundInputs), siafundUnits(fundsOut))
+		}
+		for _, sfi := range txn.SiafundInputs {
+			fmt.Fprintf(w, "    %v\tfrom %v\n", sfi.ParentID, address(sfi.UnlockConditions.UnlockHash()))
+		}
+		for _, sco := range txn.SiacoinOutputs {
+			fmt.Fprintf(w, "  Output:\t%v\tto %v\n", currencyUnits(sco.Value), address(sco.UnlockHash))
+		}
+		for _, sfo := range txn.SiafundOutputs {
+			fmt.Fprintf(w, "  Output:\t%v\tto %v\n", siafundUnits(sfo.Value), address(sfo.UnlockHash))
+		}
+		fmt.Fprintf(w, "  Fee:\t%v\n", currencyUnits(fee))
+		w.Flush()
+	}
+	fmt.Println("\nTotal fee:", currencyUnits(totalFee))
+}
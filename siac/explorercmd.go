@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	explorerCmd = &cobra.Command{
+		Use:     "explorer",
+		Aliases: []string{"e"},
+		Short:   "Query the block explorer",
+		Long: `Print statistics about the blockchain, and look up blocks, transactions and
+addresses. The explorer module must be enabled in siad, for example with
+'siad -M cgte'.`,
+		Run: wrap(explorercmd),
+	}
+
+	explorerBlockCmd = &cobra.Command{
+		Use:   "block [height|id]",
+		Short: "Show a block",
+		Long:  "Show the block at a height, or the block with an ID, and its transactions.",
+		Run:   wrap(explorerblockcmd),
+	}
+
+	explorerTransactionCmd = &cobra.Command{
+		Use:     "tx [id]",
+		Aliases: []string{"transaction"},
+		Short:   "Show a confirmed transaction",
+		Long:    "Show the inputs, outputs and fees of a confirmed transaction, and the block that contains it.",
+		Run:     wrap(explorertransactioncmd),
+	}
+
+	explorerAddressCmd = &cobra.Command{
+		Use:   "address [address]",
+		Short: "Show the history of an address",
+		Long: `List the confirmed transactions and miner payouts involving an address, with
+the siacoins and siafunds that it received and sent in each, and its balance.`,
+		Run: wrap(exploreraddresscmd),
+	}
+)
+
+// explorerGet fetches the response of an explorer route, explaining how to
+// enable the explorer if siad has not loaded it.
+func explorerGet(call string, obj interface{}) {
+	err := getAPI(call, obj)
+	if err != nil && strings.HasPrefix(err.Error(), "API call not recognized") {
+		die("The explorer module is not enabled. Start siad with the explorer module, for example with 'siad -M cgte'.")
+	} else if err != nil {
+		die("Could not query the explorer:", err)
+	}
+}
+
+// hashTypeNames describes the hash types returned by /explorer/hashes.
+var hashTypeNames = map[string]string{
+	"blockid":         "block ID",
+	"transactionid":   "transaction ID",
+	"siacoinoutputid": "siacoin output ID",
+	"filecontractid":  "file contract ID",
+	"siafundoutputid": "siafund output ID",
+	"unlockhash":      "address",
+}
+
+// explorerHash looks up a hash or address with the explorer, and dies unless
+// it is of the expected type.
+func explorerHash(hash, hashType string) api.ExplorerHashGET {
+	var ehg api.ExplorerHashGET
+	explorerGet("/explorer/hashes/"+hash, &ehg)
+	if ehg.HashType != hashType {
+		die(fmt.Sprintf("%v is not a %v, but a %v.", hash, hashTypeNames[hashType], hashTypeNames[ehg.HashType]))
+	}
+	return ehg
+}
+
+// An addressEntry is the change in the balance of an address caused by a
+// transaction or a block's miner payouts.
+type addressEntry struct {
+	Height       types.BlockHeight
+	ID           string
+	CoinsIn      types.Currency
+	CoinsOut     types.Currency
+	FundsIn      types.Currency
+	FundsOut     types.Currency
+	MinerPayouts bool
+}
+
+// addressHistory returns the changes in the balance of addr caused by the
+// provided transactions and blocks, oldest first.
+func addressHistory(addr types.UnlockHash, txns []api.ExplorerTransaction, blocks []api.ExplorerBlock) []addressEntry {
+	var entries []addressEntry
+	for _, txn := range txns {
+		e := addressEntry{Height: txn.Height, ID: txn.ID.String()}
+		for _, sco := range txn.SiacoinInputOutputs {
+			if sco.UnlockHash == addr {
+				e.CoinsOut = e.CoinsOut.Add(sco.Value)
+			}
+		}
+		for _, sco := range txn.RawTransaction.SiacoinOutputs {
+			if sco.UnlockHash == addr {
+				e.CoinsIn = e.CoinsIn.Add(sco.Value)
+			}
+		}
+		for _, sfo := range txn.SiafundInputOutputs {
+			if sfo.UnlockHash == addr {
+				e.FundsOut = e.FundsOut.Add(sfo.Value)
+			}
+		}
+		for _, sfo := range txn.RawTransaction.SiafundOutputs {
+			if sfo.UnlockHash == addr {
+				e.FundsIn = e.FundsIn.Add(sfo.Value)
+			}
+		}
+		entries = append(entries, e)
+	}
+	for _, block := range blocks {
+		e := addressEntry{Height: block.Height, ID: block.BlockID.String(), MinerPayouts: true}
+		for _, mp := range block.RawBlock.MinerPayouts {
+			if mp.UnlockHash == addr {
+				e.CoinsIn = e.CoinsIn.Add(mp.Value)
+			}
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Height < entries[j].Height
+	})
+	return entries
+}
+
+// explorercmd is the handler for the command `siac explorer`.
+// Prints statistics about the blockchain.
+func explorercmd() {
+	var eg api.ExplorerGET
+	explorerGet("/explorer", &eg)
+	fmt.Printf(`Height:              %v
+Block ID:            %v
+Difficulty:          %v
+Estimated Hashrate:  %v H/s
+Total Coins:         %v
+
+Transactions:        %v
+File Contracts:      %v
+Storage Proofs:      %v
+
+Active Contracts:    %v
+Active Cost:         %v
+Active Size:         %v
+`, eg.Height, eg.BlockID, eg.Difficulty, eg.EstimatedHashrate, currencyUnits(eg.TotalCoins),
+		eg.TransactionCount, eg.FileContractCount, eg.StorageProofCount,
+		eg.ActiveContractCount, currencyUnits(eg.ActiveContractCost), filesizeUnits(int64(eg.ActiveContractSize.Big().Uint64())))
+	printQuiet(eg.Height)
+}
+
+// explorerblockcmd is the handler for the command `siac explorer block
+// [height|id]`. Shows a block and its transactions.
+func explorerblockcmd(arg string) {
+	var block api.ExplorerBlock
+	if _, err := strconv.ParseUint(arg, 10, 64); err == nil {
+		var ebg api.ExplorerBlockGET
+		explorerGet("/explorer/blocks/"+arg, &ebg)
+		block = ebg.Block
+	} else {
+		block = explorerHash(arg, "blockid").Block
+	}
+
+	var payouts types.Currency
+	for _, mp := range block.RawBlock.MinerPayouts {
+		payouts = payouts.Add(mp.Value)
+	}
+	fmt.Printf(`Height:         %v
+Block ID:       %v
+Parent ID:      %v
+Timestamp:      %v
+Miner Payouts:  %v
+Transactions:   %v
+`, block.Height, block.BlockID, block.RawBlock.ParentID,
+		time.Unix(int64(block.RawBlock.Timestamp), 0).Format(time.RFC822), currencyUnits(payouts), len(block.Transactions))
+	printQuiet(block.BlockID)
+	if len(block.Transactions) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Transaction ID\tSiacoins Sent\tSiafunds Sent\tFees")
+	for _, txn := range block.Transactions {
+		var coins, funds, fees types.Currency
+		for _, sco := range txn.RawTransaction.SiacoinOutputs {
+			coins = coins.Add(sco.Value)
+		}
+		for _, sfo := range txn.RawTransaction.SiafundOutputs {
+			funds = funds.Add(sfo.Value)
+		}
+		for _, fee := range txn.RawTransaction.MinerFees {
+			fees = fees.Add(fee)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v SF\t%v\n", txn.ID, currencyUnits(coins), funds, currencyUnits(fees))
+	}
+	w.Flush()
+}
+
+// explorertransactioncmd is the handler for the command `siac explorer tx
+// [id]`. Shows the inputs, outputs and fees of a confirmed transaction.
+func explorertransactioncmd(id string) {
+	txn := explorerHash(id, "transactionid").Transaction
+	raw := txn.RawTransaction
+	fmt.Printf(`Transaction ID:  %v
+Height:          %v
+Block ID:        %v
+`, txn.ID, txn.Height, txn.Parent)
+	printQuiet(txn.Height)
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	for _, sco := range txn.SiacoinInputOutputs {
+		fmt.Fprintf(w, "Siacoin Input:\t%v\tfrom %v\n", currencyUnits(sco.Value), sco.UnlockHash)
+	}
+	for _, sco := range raw.SiacoinOutputs {
+		fmt.Fprintf(w, "Siacoin Output:\t%v\tto %v\n", currencyUnits(sco.Value), sco.UnlockHash)
+	}
+	for _, sfo := range txn.SiafundInputOutputs {
+		fmt.Fprintf(w, "Siafund Input:\t%v SF\tfrom %v\n", sfo.Value, sfo.UnlockHash)
+	}
+	for _, sfo := range raw.SiafundOutputs {
+		fmt.Fprintf(w, "Siafund Output:\t%v SF\tto %v\n", sfo.Value, sfo.UnlockHash)
+	}
+	for i, fc := range raw.FileContracts {
+		fmt.Fprintf(w, "File Contract:\t%v\t%v, proof window %v-%v\n", txn.FileContractIDs[i], filesizeUnits(int64(fc.FileSize)), fc.WindowStart, fc.WindowEnd)
+	}
+	for _, fcr := range raw.FileContractRevisions {
+		fmt.Fprintf(w, "Revision:\t%v\trevision %v, %v\n", fcr.ParentID, fcr.NewRevisionNumber, filesizeUnits(int64(fcr.NewFileSize)))
+	}
+	for _, sp := range raw.StorageProofs {
+		fmt.Fprintf(w, "Storage Proof:\t%v\t\n", sp.ParentID)
+	}
+	for _, fee := range raw.MinerFees {
+		fmt.Fprintf(w, "Miner Fee:\t%v\t\n", currencyUnits(fee))
+	}
+	if len(raw.ArbitraryData) > 0 {
+		fmt.Fprintf(w, "Arbitrary Data:\t%v items\t\n", len(raw.ArbitraryData))
+	}
+	w.Flush()
+}
+
+// exploreraddresscmd is the handler for the command `siac explorer address
+// [address]`. Lists the confirmed activity of an address.
+func exploreraddresscmd(addrStr string) {
+	var addr types.UnlockHash
+	if err := addr.LoadString(addrStr); err != nil {
+		dieUsage("Could not parse address:", err)
+	}
+	ehg := explorerHash(addrStr, "unlockhash")
+	entries := addressHistory(addr, ehg.Transactions, ehg.Blocks)
+
+	var coinsIn, coinsOut, fundsIn, fundsOut types.Currency
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Height\tTransaction ID\tSiacoins\tSiafunds")
+	for _, e := range entries {
+		id := e.ID
+		if e.MinerPayouts {
+			id += " (miner payouts)"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", e.Height, id, balanceChange(currencyUnits, e.CoinsIn, e.CoinsOut),
+			balanceChange(func(c types.Currency) string { return c.String() + " SF" }, e.FundsIn, e.FundsOut))
+		coinsIn, coinsOut = coinsIn.Add(e.CoinsIn), coinsOut.Add(e.CoinsOut)
+		fundsIn, fundsOut = fundsIn.Add(e.FundsIn), fundsOut.Add(e.FundsOut)
+	}
+	w.Flush()
+
+	// Miner payouts that have not matured yet are included in the balance.
+	var coins, funds types.Currency
+	if coinsIn.Cmp(coinsOut) > 0 {
+		coins = coinsIn.Sub(coinsOut)
+	}
+	if fundsIn.Cmp(fundsOut) > 0 {
+		funds = fundsIn.Sub(fundsOut)
+	}
+	fmt.Printf(`
+Received:  %v, %v SF
+Sent:      %v, %v SF
+Balance:   %v, %v SF
+`, currencyUnits(coinsIn), fundsIn, currencyUnits(coinsOut), fundsOut, currencyUnits(coins), funds)
+	printQuiet(coins)
+}
+
+// balanceChange formats the change in a balance from received and sent
+// amounts.
+func balanceChange(format func(types.Currency) string, in, out types.Currency) string {
+	switch in.Cmp(out) {
+	case 1:
+		return "+" + format(in.Sub(out))
+	case -1:
+		return "-" + format(out.Sub(in))
+	default:
+		return "0"
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestAddressHistory checks that the siacoins and siafunds received and sent
+// by an address are summed per transaction and block, oldest first.
+func TestAddressHistory(t *testing.T) {
+	addr := types.UnlockHash{1}
+	other := types.UnlockHash{2}
+	txns := []api.ExplorerTransaction{
+		{
+			ID:     types.TransactionID{1},
+			Height: 20,
+			SiacoinInputOutputs: []types.SiacoinOutput{
+				{Value: types.NewCurrency64(100), UnlockHash: addr},
+			},
+			SiafundInputOutputs: []types.SiafundOutput{
+				{Value: types.NewCurrency64(5), UnlockHash: other},
+			},
+			RawTransaction: types.Transaction{
+				SiacoinOutputs: []types.SiacoinOutput{
+					{Value: types.NewCurrency64(60), UnlockHash: other},
+					{Value: types.NewCurrency64(30), UnlockHash: addr},
+				},
+				SiafundOutputs: []types.SiafundOutput{
+					{Value: types.NewCurrency64(5), UnlockHash: addr},
+				},
+			},
+		},
+	}
+	var block api.ExplorerBlock
+	block.Height = 10
+	block.RawBlock.MinerPayouts = []types.SiacoinOutput{
+		{Value: types.NewCurrency64(100), UnlockHash: addr},
+		{Value: types.NewCurrency64(7), UnlockHash: other},
+	}
+
+	entries := addressHistory(addr, txns, []api.ExplorerBlock{block})
+	if len(entries) != 2 {
+		t.Fatal("expected 2 entries, got", len(entries))
+	}
+	payout, send := entries[0], entries[1]
+	if !payout.MinerPayouts || payout.Height != 10 || !payout.CoinsIn.Equals64(100) || !payout.CoinsOut.IsZero() {
+		t.Fatal("wrong miner payout entry:", payout)
+	}
+	if send.MinerPayouts || send.ID != txns[0].ID.String() {
+		t.Fatal("wrong transaction entry:", send)
+	}
+	if !send.CoinsIn.Equals64(30) || !send.CoinsOut.Equals64(100) || !send.FundsIn.Equals64(5) || !send.FundsOut.IsZero() {
+		t.Fatal("wrong amounts:", send)
+	}
+}
+
+// TestBalanceChange checks the formatting of changes in a balance.
+func TestBalanceChange(t *testing.T) {
+	format := func(c types.Currency) string { return c.String() }
+	tests := []struct {
+		in, out uint64
+		exp     string
+	}{
+		{30, 100, "-70"},
+		{100, 30, "+70"},
+		{5, 5, "0"},
+	}
+	for _, test := range tests {
+		if s := balanceChange(format, types.NewCurrency64(test.in), types.NewCurrency64(test.out)); s != test.exp {
+			t.Errorf("balanceChange(%v, %v): expected %q, got %q", test.in, test.out, test.exp, s)
+		}
+	}
+}
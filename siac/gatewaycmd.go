@@ -8,14 +8,16 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
 )
 
 var (
 	gatewayCmd = &cobra.Command{
-		Use:   "gateway",
-		Short: "Perform gateway actions",
-		Long:  "View and manage the gateway's connected peers.",
-		Run:   wrap(gatewaycmd),
+		Use:     "gateway",
+		Aliases: []string{"g"},
+		Short:   "Perform gateway actions",
+		Long:    "View and manage the gateway's connected peers.",
+		Run:     wrap(gatewaycmd),
 	}
 
 	gatewayConnectCmd = &cobra.Command{
@@ -42,12 +44,51 @@ var (
 	gatewayListCmd = &cobra.Command{
 		Use:   "list",
 		Short: "View a list of peers",
-		Long:  "View the current peer list.",
+		Long:  "View the current peer list and the banned addresses.",
 		Run:   wrap(gatewaylistcmd),
 	}
+
+	gatewayAddCmd = &cobra.Command{
+		Use:   "add [address]",
+		Short: "Add a node to the node list",
+		Long: `Add a node to the node list without connecting to it. The gateway may
+connect to the node when it looks for new peers.`,
+		Run: wrap(gatewayaddcmd),
+	}
+
+	gatewayRemoveCmd = &cobra.Command{
+		Use:   "remove [address]",
+		Short: "Remove a node from the node list",
+		Long: `Remove a node from the node list, disconnecting from it if it is a peer.
+Other peers may share the node again; use 'siac gateway ban' to keep it away.`,
+		Run: wrap(gatewayremovecmd),
+	}
+
+	gatewayBanCmd = &cobra.Command{
+		Use:   "ban [ip]",
+		Short: "Ban an IP address",
+		Long: `Ban an IP address. The gateway disconnects from the peers on the address
+and refuses connections to and from it, on any port, until it is unbanned.`,
+		Run: wrap(gatewaybancmd),
+	}
+
+	gatewayUnbanCmd = &cobra.Command{
+		Use:   "unban [ip]",
+		Short: "Lift the ban of an IP address",
+		Long:  "Lift the ban of an IP address.",
+		Run:   wrap(gatewayunbancmd),
+	}
+
+	gatewaySummaryCmd = &cobra.Command{
+		Use:   "summary",
+		Short: "View the peers by subnet",
+		Long: `View how the peers are spread across subnets, and the limits on inbound
+connections from a single IP address or subnet.`,
+		Run: wrap(gatewaysummarycmd),
+	}
 )
 
-// gatewayconnectcmd is the handler for the command `siac gateway add [address]`.
+// gatewayconnectcmd is the handler for the command `siac gateway connect [address]`.
 // Adds a new peer to the peer list.
 func gatewayconnectcmd(addr string) {
 	err := post("/gateway/connect/"+addr, "")
@@ -57,7 +98,7 @@ func gatewayconnectcmd(addr string) {
 	fmt.Println("Added", addr, "to peer list.")
 }
 
-// gatewaydisconnectcmd is the handler for the command `siac gateway remove [address]`.
+// gatewaydisconnectcmd is the handler for the command `siac gateway disconnect [address]`.
 // Removes a peer from the peer list.
 func gatewaydisconnectcmd(addr string) {
 	err := post("/gateway/disconnect/"+addr, "")
@@ -67,6 +108,46 @@ func gatewaydisconnectcmd(addr string) {
 	fmt.Println("Removed", addr, "from peer list.")
 }
 
+// gatewayaddcmd is the handler for the command `siac gateway add [address]`.
+// Adds a node to the node list.
+func gatewayaddcmd(addr string) {
+	err := post("/gateway/add/"+addr, "")
+	if err != nil {
+		die("Could not add node:", err)
+	}
+	fmt.Println("Added", addr, "to node list.")
+}
+
+// gatewayremovecmd is the handler for the command `siac gateway remove
+// [address]`. Removes a node from the node list.
+func gatewayremovecmd(addr string) {
+	err := post("/gateway/remove/"+addr, "")
+	if err != nil {
+		die("Could not remove node:", err)
+	}
+	fmt.Println("Removed", addr, "from node list.")
+}
+
+// gatewaybancmd is the handler for the command `siac gateway ban [ip]`.
+// Bans an IP address.
+func gatewaybancmd(addr string) {
+	err := post("/gateway/ban/"+addr, "")
+	if err != nil {
+		die("Could not ban address:", err)
+	}
+	fmt.Println("Banned", addr+".")
+}
+
+// gatewayunbancmd is the handler for the command `siac gateway unban [ip]`.
+// Lifts the ban of an IP address.
+func gatewayunbancmd(addr string) {
+	err := post("/gateway/unban/"+addr, "")
+	if err != nil {
+		die("Could not unban address:", err)
+	}
+	fmt.Println("Unbanned", addr+".")
+}
+
 // gatewayaddresscmd is the handler for the command `siac gateway address`.
 // Prints the gateway's network address.
 func gatewayaddresscmd() {
@@ -76,6 +157,7 @@ func gatewayaddresscmd() {
 		die("Could not get gateway address:", err)
 	}
 	fmt.Println("Address:", info.NetAddress)
+	printQuiet(info.NetAddress)
 }
 
 // gatewaycmd is the handler for the command `siac gateway`.
@@ -100,13 +182,53 @@ func gatewaylistcmd() {
 	}
 	if len(info.Peers) == 0 {
 		fmt.Println("No peers to show.")
+	} else {
+		fmt.Println(len(info.Peers), "active peers:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Version\tOutbound\tAddress")
+		for _, peer := range info.Peers {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", peer.Version, yesNo(!peer.Inbound), peer.NetAddress)
+		}
+		w.Flush()
+	}
+	if len(info.BannedHosts) > 0 {
+		fmt.Println()
+		fmt.Println(len(info.BannedHosts), "banned addresses:")
+		for _, host := range info.BannedHosts {
+			fmt.Println("  " + host)
+		}
+	}
+}
+
+// gatewaysummarycmd is the handler for the command `siac gateway summary`.
+// Prints the number of peers in each subnet.
+func gatewaysummarycmd() {
+	var summary modules.GatewayPeerSummary
+	err := getAPI("/gateway/peers/summary", &summary)
+	if err != nil {
+		die("Could not get peer summary:", err)
+	}
+	fmt.Printf(`Inbound peers:  %v
+Outbound peers: %v
+Local peers:    %v
+Inbound limits: %v per IP, %v per subnet
+`, summary.Inbound, summary.Outbound, summary.Local, peerLimit(summary.Limits.MaxInboundPerIP), peerLimit(summary.Limits.MaxInboundPerSubnet))
+	if len(summary.Subnets) == 0 {
 		return
 	}
-	fmt.Println(len(info.Peers), "active peers:")
+	fmt.Println()
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Version\tOutbound\tAddress")
-	for _, peer := range info.Peers {
-		fmt.Fprintf(w, "%v\t%v\t%v\n", peer.Version, yesNo(!peer.Inbound), peer.NetAddress)
+	fmt.Fprintln(w, "Subnet\tInbound\tOutbound")
+	for _, sn := range summary.Subnets {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", sn.Subnet, sn.Inbound, sn.Outbound)
 	}
 	w.Flush()
 }
+
+// peerLimit formats a limit on inbound peers, where zero means no limit.
+func peerLimit(n int) string {
+	if n == 0 {
+		return "no limit"
+	}
+	return fmt.Sprint(n)
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"fmt"
 	"math/big"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/NebulousLabs/Sia/api"
 	"github.com/NebulousLabs/Sia/modules"
@@ -17,10 +19,11 @@ import (
 
 var (
 	hostCmd = &cobra.Command{
-		Use:   "host",
-		Short: "Perform host actions",
-		Long:  "View or modify host settings.",
-		Run:   wrap(hostcmd),
+		Use:     "host",
+		Aliases: []string{"h"},
+		Short:   "Perform host actions",
+		Long:    "View or modify host settings.",
+		Run:     wrap(hostcmd),
 	}
 
 	hostConfigCmd = &cobra.Command{
@@ -72,6 +75,64 @@ Doing so will override the standard connectivity checks.`,
 		Run: hostannouncecmd,
 	}
 
+	hostContractsCmd = &cobra.Command{
+		Use:   "contracts",
+		Short: "List the host's storage obligations",
+		Long: `List the storage obligations of the host, including obligations that have
+ended. The usage of an obligation can be viewed with:
+	siac host usage [id]`,
+		Run: wrap(hostcontractscmd),
+	}
+
+	hostUsageCmd = &cobra.Command{
+		Use:   "usage [id]",
+		Short: "View the usage timeline of a storage obligation",
+		Long: `View the data uploaded, deleted and downloaded under a storage obligation per
+day, along with the revenue that the transfers earned.`,
+		Run: wrap(hostusagecmd),
+	}
+
+	hostStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show an overview of the host",
+		Long: `Show the connectability, storage folder utilization, storage obligations and
+financials of the host on one screen, followed by warnings about problems that
+need attention, such as failing storage folders, missing sectors and failed
+storage proofs. Use --watch to keep the overview up to date.`,
+		Run: wrap(hoststatuscmd),
+	}
+
+	hostConsistencyCmd = &cobra.Command{
+		Use:   "consistency",
+		Short: "Check the stored sectors against the storage obligations",
+		Long: `Show the result of the most recent cross-check of the sectors referenced by the
+host's storage obligations against the sectors held by the host. Orphaned
+sectors take up space that can be reclaimed, and missing sectors put the
+contracts that reference them at risk. The host runs a check on startup; use
+--check to run a new one, or --cleanup to delete orphaned sectors and correct
+reference counts before checking.`,
+		Run: wrap(hostconsistencycmd),
+	}
+
+	hostReindexCmd = &cobra.Command{
+		Use:   "reindex",
+		Short: "Recompute the confirmation status of storage obligations",
+		Long: `Recompute whether the contracts, revisions and storage proofs of the host's
+storage obligations have been confirmed, without rescanning the host.`,
+		Run: wrap(hostreindexcmd),
+	}
+
+	hostSimulateDowntimeCmd = &cobra.Command{
+		Use:   "simulate-downtime [days]",
+		Short: "Estimate the collateral lost to a period of downtime",
+		Long: `Estimate the collateral and revenue that the host would lose if it were
+offline for the given number of days, assuming that the most costly storage
+proofs due during the downtime are missed. Use --start to simulate downtime
+that begins in the future, and --missed-percent to assume that only some of
+the proofs are missed.`,
+		Run: wrap(hostsimulatedowntimecmd),
+	}
+
 	hostFolderCmd = &cobra.Command{
 		Use:   "folder",
 		Short: "Add, remove, or resize a storage folder",
@@ -119,6 +180,19 @@ sector may impact host revenue.`,
 	}
 )
 
+// connectability describes the working and connectability status of the
+// host.
+func connectability(hg *api.HostGET) string {
+	if hg.WorkingStatus == "working" {
+		return "Host appears to be working."
+	} else if hg.WorkingStatus == "not working" && hg.ConnectabilityStatus == "connectable" {
+		return "Nobody is connecting to host. Try re-announcing."
+	} else if hg.WorkingStatus == "checking" || hg.ConnectabilityStatus == "checking" {
+		return "Host is checking status (takes a few minues)."
+	}
+	return "Host is not connectable (re-checks every few minutes)."
+}
+
 // hostcmd is the handler for the command `siac host`.
 // Prints info about the host and its storage folders.
 func hostcmd() {
@@ -164,16 +238,7 @@ func hostcmd() {
 		netaddr += " (manually specified)"
 	}
 
-	var connectabilityString string
-	if hg.WorkingStatus == "working" {
-		connectabilityString = "Host appears to be working."
-	} else if hg.WorkingStatus == "not working" && hg.ConnectabilityStatus == "connectable" {
-		connectabilityString = "Nobody is connecting to host. Try re-announcing."
-	} else if hg.WorkingStatus == "checking" || hg.ConnectabilityStatus == "checking" {
-		connectabilityString = "Host is checking status (takes a few minues)."
-	} else {
-		connectabilityString = "Host is not connectable (re-checks every few minutes)."
-	}
+	connectabilityString := connectability(hg)
 
 	if hostVerbose {
 		// describe net address
@@ -294,13 +359,163 @@ RPC Stats:
 		return
 	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
-	fmt.Fprintf(w, "\tUsed\tCapacity\t%% Used\tPath\n")
+	fmt.Fprintf(w, "\tUsed\tCapacity\t%% Used\tStatus\tPath\n")
 	for _, folder := range sg.Folders {
 		curSize := int64(folder.Capacity - folder.CapacityRemaining)
 		pctUsed := 100 * (float64(curSize) / float64(folder.Capacity))
-		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", filesizeUnits(curSize), filesizeUnits(int64(folder.Capacity)), pctUsed, folder.Path)
+		status := folder.Status
+		if status == modules.StorageFolderStatusInitializing && folder.ProgressDenominator != 0 {
+			status = fmt.Sprintf("%s (%.0f%%)", status, 100*float64(folder.ProgressNumerator)/float64(folder.ProgressDenominator))
+		}
+		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\t%s\n", filesizeUnits(curSize), filesizeUnits(int64(folder.Capacity)), pctUsed, status, folder.Path)
+	}
+	w.Flush()
+}
+
+// obligationCounts counts the storage obligations of the host by status. Of
+// the unresolved obligations, unconfirmed counts those whose contract has not
+// been confirmed yet.
+func obligationCounts(obligations []modules.StorageObligation) (counts [4]int, unconfirmed int) {
+	for _, so := range obligations {
+		if so.ObligationStatus < uint64(len(counts)) {
+			counts[so.ObligationStatus]++
+		}
+		if so.ObligationStatus == 0 && !so.OriginConfirmed {
+			unconfirmed++
+		}
+	}
+	return counts, unconfirmed
+}
+
+// hostWarnings returns the problems of the host that need the attention of
+// its operator.
+func hostWarnings(hg *api.HostGET, sg *api.StorageGET, hcg *api.HostContractsGET, hcons *api.HostConsistencyGET) []string {
+	var warnings []string
+	if hg.WorkingStatus != "working" && hg.WorkingStatus != "checking" {
+		warnings = append(warnings, connectability(hg))
+	}
+	if !hg.InternalSettings.AcceptingContracts {
+		warnings = append(warnings, "Host is not accepting new contracts.")
+	}
+
+	var capacity, remaining uint64
+	for _, folder := range sg.Folders {
+		capacity += folder.Capacity
+		remaining += folder.CapacityRemaining
+		if folder.FailedReads > 0 || folder.FailedWrites > 0 {
+			warnings = append(warnings, fmt.Sprintf("Storage folder %v has %v failed reads and %v failed writes.", folder.Path, folder.FailedReads, folder.FailedWrites))
+		}
+	}
+	if len(sg.Folders) == 0 {
+		warnings = append(warnings, "No storage folders are configured.")
+	} else if remaining < capacity/10 {
+		warnings = append(warnings, fmt.Sprintf("Storage is almost full: %v remaining.", filesizeUnits(int64(remaining))))
+	}
+
+	fm := hg.FinancialMetrics
+	budget := hg.InternalSettings.CollateralBudget
+	if !budget.IsZero() && fm.LockedStorageCollateral.Mul64(10).Cmp(budget.Mul64(9)) >= 0 {
+		warnings = append(warnings, fmt.Sprintf("Locked collateral %v is close to the collateral budget of %v.", currencyUnits(fm.LockedStorageCollateral), currencyUnits(budget)))
+	}
+
+	if counts, _ := obligationCounts(hcg.Contracts); counts[3] > 0 {
+		warnings = append(warnings, fmt.Sprintf("%v storage obligations failed; %v of collateral was lost.", counts[3], currencyUnits(fm.LostStorageCollateral)))
+	}
+	if hcons.MissingSectors > 0 {
+		warnings = append(warnings, fmt.Sprintf("%v sectors are missing, putting %v contracts at risk; see 'siac host consistency'.", hcons.MissingSectors, len(hcons.AtRiskContracts)))
+	}
+	if hg.NetworkMetrics.ErrorCalls > 0 {
+		warnings = append(warnings, fmt.Sprintf("%v RPC calls from renters ended in an error.", hg.NetworkMetrics.ErrorCalls))
+	}
+	return warnings
+}
+
+// hoststatuscmd is the handler for the command `siac host status`. It prints
+// an overview of the host, combining several API calls.
+func hoststatuscmd() {
+	hg := new(api.HostGET)
+	if err := getAPI("/host", hg); err != nil {
+		die("Could not fetch host settings:", err)
+	}
+	sg := new(api.StorageGET)
+	if err := getAPI("/host/storage", sg); err != nil {
+		die("Could not fetch storage info:", err)
+	}
+	hcg := new(api.HostContractsGET)
+	if err := getAPI("/host/contracts", hcg); err != nil {
+		die("Could not get storage obligations:", err)
+	}
+	hcons := new(api.HostConsistencyGET)
+	if err := getAPI("/host/consistency", hcons); err != nil {
+		die("Could not get consistency check:", err)
+	}
+	fm := hg.FinancialMetrics
+
+	fmt.Printf(`Host:
+	Connectability:      %v
+	Accepting Contracts: %v
+	Net Address:         %v
+`, connectability(hg), yesNo(hg.InternalSettings.AcceptingContracts), hg.ExternalSettings.NetAddress)
+
+	var capacity, remaining uint64
+	for _, folder := range sg.Folders {
+		capacity += folder.Capacity
+		remaining += folder.CapacityRemaining
+	}
+	fmt.Printf("\nStorage: %v of %v used\n", filesizeUnits(int64(capacity-remaining)), filesizeUnits(int64(capacity)))
+	sort.Slice(sg.Folders, func(i, j int) bool {
+		return sg.Folders[i].Path < sg.Folders[j].Path
+	})
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, folder := range sg.Folders {
+		used := folder.Capacity - folder.CapacityRemaining
+		var pct float64
+		if folder.Capacity > 0 {
+			pct = 100 * float64(used) / float64(folder.Capacity)
+		}
+		fmt.Fprintf(w, "\t%s %5.1f%%\t%s\t%s\t%s\n", progressBar(used, folder.Capacity, queueBarWidth), pct,
+			filesizeUnits(int64(folder.Capacity)), folder.Status, folder.Path)
 	}
 	w.Flush()
+
+	counts, unconfirmed := obligationCounts(hcg.Contracts)
+	fmt.Printf(`
+Obligations:
+	Active:    %v (%v awaiting confirmation)
+	Succeeded: %v
+	Failed:    %v
+	Rejected:  %v
+`, counts[0], unconfirmed, counts[2], counts[3], counts[1])
+
+	expectedRevenue := fm.PotentialContractCompensation.
+		Add(fm.PotentialStorageRevenue).
+		Add(fm.PotentialDownloadBandwidthRevenue).
+		Add(fm.PotentialUploadBandwidthRevenue)
+	revenue := fm.ContractCompensation.
+		Add(fm.StorageRevenue).
+		Add(fm.DownloadBandwidthRevenue).
+		Add(fm.UploadBandwidthRevenue)
+	fmt.Printf(`
+Financials:
+	Locked Collateral: %v (budget %v)
+	Risked Collateral: %v
+	Expected Revenue:  %v
+	Revenue:           %v
+	Lost Revenue:      %v
+	Lost Collateral:   %v
+`, currencyUnits(fm.LockedStorageCollateral), currencyUnits(hg.InternalSettings.CollateralBudget),
+		currencyUnits(fm.RiskedStorageCollateral), currencyUnits(expectedRevenue),
+		currencyUnits(revenue), currencyUnits(fm.LostRevenue), currencyUnits(fm.LostStorageCollateral))
+
+	warnings := hostWarnings(hg, sg, hcg, hcons)
+	if len(warnings) == 0 {
+		fmt.Println("\nNo problems found.")
+		return
+	}
+	fmt.Println("\nWarnings:")
+	for _, warning := range warnings {
+		fmt.Println("\t" + warning)
+	}
 }
 
 // hostconfigcmd is the handler for the command `siac host config [setting] [value]`.
@@ -312,14 +527,14 @@ func hostconfigcmd(param, value string) {
 	case "collateralbudget", "maxcollateral", "mincontractprice":
 		value, err = parseCurrency(value)
 		if err != nil {
-			die("Could not parse "+param+":", err)
+			dieUsage("Could not parse "+param+":", err)
 		}
 
 	// currency/TB (convert to hastings/byte)
 	case "mindownloadbandwidthprice", "minuploadbandwidthprice":
 		hastings, err := parseCurrency(value)
 		if err != nil {
-			die("Could not parse "+param+":", err)
+			dieUsage("Could not parse "+param+":", err)
 		}
 		i, _ := new(big.Int).SetString(hastings, 10)
 		c := types.NewCurrency(i).Div(modules.BytesPerTerabyte)
@@ -329,7 +544,7 @@ func hostconfigcmd(param, value string) {
 	case "collateral", "minstorageprice":
 		hastings, err := parseCurrency(value)
 		if err != nil {
-			die("Could not parse "+param+":", err)
+			dieUsage("Could not parse "+param+":", err)
 		}
 		i, _ := new(big.Int).SetString(hastings, 10)
 		c := types.NewCurrency(i).Div(modules.BlockBytesPerMonthTerabyte)
@@ -348,7 +563,7 @@ func hostconfigcmd(param, value string) {
 	case "maxduration", "windowsize":
 		value, err = parsePeriod(value)
 		if err != nil {
-			die("Could not parse "+param+":", err)
+			dieUsage("Could not parse "+param+":", err)
 		}
 
 	// other valid settings
@@ -356,7 +571,7 @@ func hostconfigcmd(param, value string) {
 
 	// invalid settings
 	default:
-		die("\"" + param + "\" is not a host setting")
+		dieUsage("\"" + param + "\" is not a host setting")
 	}
 	err = post("/host", param+"="+value)
 	if err != nil {
@@ -371,6 +586,136 @@ func hostconfigcmd(param, value string) {
 	fmt.Println("Host settings updated.")
 }
 
+// obligationStatusNames are the names of the statuses of a storage
+// obligation, indexed by status.
+var obligationStatusNames = []string{"unresolved", "rejected", "succeeded", "failed"}
+
+// hostcontractscmd lists the storage obligations of the host.
+func hostcontractscmd() {
+	var hcg api.HostContractsGET
+	err := getAPI("/host/contracts", &hcg)
+	if err != nil {
+		die("Could not get storage obligations:", err)
+	}
+	if len(hcg.Contracts) == 0 {
+		fmt.Println("No storage obligations.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNegotiated\tConfirmed\tStatus")
+	for _, so := range hcg.Contracts {
+		status := "unknown"
+		if so.ObligationStatus < uint64(len(obligationStatusNames)) {
+			status = obligationStatusNames[so.ObligationStatus]
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", so.ObligationID, so.NegotiationHeight, so.OriginConfirmed, status)
+	}
+	w.Flush()
+}
+
+// hostusagecmd prints the usage timeline of a storage obligation.
+func hostusagecmd(id string) {
+	var hcug api.HostContractUsageGET
+	err := getAPI("/host/contracts/"+id+"/usage", &hcug)
+	if err != nil {
+		die("Could not get usage:", err)
+	}
+	if len(hcug.Usage) == 0 {
+		fmt.Println("No data has been transferred under this storage obligation.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Day\tAdded\tRemoved\tDownloaded\tRevenue")
+	for _, snapshot := range hcug.Usage {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", time.Unix(int64(snapshot.Timestamp), 0).UTC().Format("2006-01-02"),
+			filesizeUnits(int64(snapshot.BytesAdded)), filesizeUnits(int64(snapshot.BytesRemoved)),
+			filesizeUnits(int64(snapshot.BytesDownloaded)), currencyUnits(snapshot.Revenue))
+	}
+	w.Flush()
+}
+
+// hostconsistencycmd prints the result of a consistency check of the host's
+// stored sectors, running a new check if requested.
+func hostconsistencycmd() {
+	var hcg api.HostConsistencyGET
+	var err error
+	if hostConsistencyCleanup {
+		err = postResp("/host/consistency", "cleanup=true", &hcg)
+	} else if hostConsistencyCheck {
+		err = postResp("/host/consistency", "", &hcg)
+	} else {
+		err = getAPI("/host/consistency", &hcg)
+	}
+	if err != nil {
+		die("Could not check consistency:", err)
+	}
+	if hcg.CheckTime == 0 {
+		fmt.Println("The startup consistency check has not finished yet.")
+		return
+	}
+	fmt.Printf(`Checked:               %v
+Obligations:           %v
+Orphaned sectors:      %v (%v reclaimable)
+Excess references:     %v
+Missing sectors:       %v
+Undercounted sectors:  %v
+`, time.Unix(int64(hcg.CheckTime), 0).Format(time.RFC822), hcg.Obligations,
+		hcg.OrphanedSectors, filesizeUnits(int64(hcg.ReclaimableStorage)), hcg.ExcessVirtualSectors,
+		hcg.MissingSectors, hcg.UndercountedSectors)
+	if hostConsistencyCleanup {
+		fmt.Printf("Sectors cleaned up:    %v\n", hcg.CleanedSectors)
+	}
+	if len(hcg.AtRiskContracts) == 0 {
+		return
+	}
+	fmt.Println("\nContracts at risk:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tExpiration\tMissing Sectors")
+	for _, c := range hcg.AtRiskContracts {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", c.ObligationID, c.Expiration, c.MissingSectors)
+	}
+	w.Flush()
+}
+
+// hostreindexcmd recomputes the confirmation status of the host's storage
+// obligations.
+func hostreindexcmd() {
+	err := post("/host/reindexobligations", "")
+	if err != nil {
+		die("Could not reindex storage obligations:", err)
+	}
+	fmt.Println("Storage obligations reindexed.")
+}
+
+// hostsimulatedowntimecmd estimates the collateral and revenue that the host
+// would lose to a period of downtime.
+func hostsimulatedowntimecmd(days string) {
+	var hsdg api.HostSimulateDowntimeGET
+	query := fmt.Sprintf("?days=%s&startdays=%v&missedpercent=%v", url.QueryEscape(days), hostDowntimeStart, hostDowntimeMissed)
+	err := getAPI("/host/simulatedowntime"+query, &hsdg)
+	if err != nil {
+		die("Could not simulate downtime:", err)
+	}
+	fmt.Printf(`Downtime:          blocks %v to %v
+Proofs at risk:    %v
+Proofs missed:     %v
+Collateral burned: %v
+Revenue lost:      %v
+`, hsdg.StartHeight, hsdg.EndHeight, hsdg.ProofsAtRisk, hsdg.ProofsMissed,
+		currencyUnits(hsdg.CollateralBurned), currencyUnits(hsdg.RevenueLost))
+	if len(hsdg.Obligations) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tProof Window\tCollateral Burned\tRevenue Lost")
+	for _, o := range hsdg.Obligations {
+		fmt.Fprintf(w, "%v\t%v-%v\t%v\t%v\n", o.ObligationID, o.WindowStart, o.WindowEnd,
+			currencyUnits(o.CollateralBurned), currencyUnits(o.RevenueLost))
+	}
+	w.Flush()
+}
+
 // hostannouncecmd is the handler for the command `siac host announce`.
 // Announces yourself as a host to the network. Optionally takes an address to
 // announce as.
@@ -383,7 +728,7 @@ func hostannouncecmd(cmd *cobra.Command, args []string) {
 		err = post("/host/announce", "netaddress="+args[0])
 	default:
 		cmd.UsageFunc()(cmd)
-		os.Exit(exitCodeUsage)
+		exit(exitCodeUsage)
 	}
 	if err != nil {
 		die("Could not announce host:", err)
@@ -406,7 +751,7 @@ To revert this, run:
 func hostfolderaddcmd(path, size string) {
 	size, err := parseFilesize(size)
 	if err != nil {
-		die("Could not parse size:", err)
+		dieUsage("Could not parse size:", err)
 	}
 	// round size down to nearest multiple of 256MiB
 	var sizeUint64 uint64
@@ -419,7 +764,7 @@ func hostfolderaddcmd(path, size string) {
 	if err != nil {
 		die("Could not add folder:", err)
 	}
-	fmt.Println("Added folder", path)
+	fmt.Println("Added folder", path+". The folder will be initialized in the background.")
 }
 
 // hostfolderremovecmd removes a folder from the host.
@@ -435,7 +780,7 @@ func hostfolderremovecmd(path string) {
 func hostfolderresizecmd(path, newsize string) {
 	newsize, err := parseFilesize(newsize)
 	if err != nil {
-		die("Could not parse size:", err)
+		dieUsage("Could not parse size:", err)
 	}
 	// round size down to nearest multiple of 256MiB
 	var sizeUint64 uint64
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestHostWarnings checks that the problems of a host are reported by
+// `siac host status`, and that a healthy host has none.
+func TestHostWarnings(t *testing.T) {
+	hg := &api.HostGET{WorkingStatus: "working"}
+	hg.InternalSettings.AcceptingContracts = true
+	hg.InternalSettings.CollateralBudget = types.NewCurrency64(1000)
+	hg.FinancialMetrics.LockedStorageCollateral = types.NewCurrency64(100)
+	sg := &api.StorageGET{Folders: []modules.StorageFolderMetadata{{Path: "/a", Capacity: 1000, CapacityRemaining: 100}}}
+	hcg := &api.HostContractsGET{Contracts: []modules.StorageObligation{{ObligationStatus: 0}, {ObligationStatus: 2}}}
+	hcons := &api.HostConsistencyGET{}
+	if warnings := hostWarnings(hg, sg, hcg, hcons); len(warnings) != 0 {
+		t.Fatal("expected no warnings for a healthy host, got", warnings)
+	}
+
+	hg.WorkingStatus = "not working"
+	hg.FinancialMetrics.LockedStorageCollateral = types.NewCurrency64(950)
+	sg.Folders = append(sg.Folders, modules.StorageFolderMetadata{Path: "/b", Capacity: 1000, FailedWrites: 3})
+	hcg.Contracts = append(hcg.Contracts, modules.StorageObligation{ObligationStatus: 3})
+	hcons.MissingSectors = 2
+	warnings := strings.Join(hostWarnings(hg, sg, hcg, hcons), "\n")
+	for _, want := range []string{"not connectable", "/b has 0 failed reads and 3 failed writes", "Storage is almost full", "close to the collateral budget", "1 storage obligations failed", "2 sectors are missing"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("expected a warning containing %q, got:\n%v", want, warnings)
+		}
+	}
+}
+
+// TestObligationCounts checks that storage obligations are counted by
+// status.
+func TestObligationCounts(t *testing.T) {
+	counts, unconfirmed := obligationCounts([]modules.StorageObligation{
+		{ObligationStatus: 0, OriginConfirmed: true},
+		{ObligationStatus: 0},
+		{ObligationStatus: 1},
+		{ObligationStatus: 3},
+		{ObligationStatus: 7},
+	})
+	if counts != [4]int{2, 1, 0, 1} || unconfirmed != 1 {
+		t.Fatal("wrong counts:", counts, unconfirmed)
+	}
+}
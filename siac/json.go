@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bgentry/speakeasy"
+)
+
+// In JSON mode, enabled by the --json flag, the formatted output of a command
+// is discarded and the API responses that the command received are printed
+// instead, so that scripts do not depend on the wording of the output. A
+// command that makes a single call prints its response as is; a command that
+// makes several prints an object keyed by the path of each call. A command
+// whose calls return no data prints {"success": true}.
+
+var (
+	// jsonOutput is set by the --json flag.
+	jsonOutput bool
+
+	// jsonResponses are the responses received by the command, in order.
+	jsonResponses []jsonResponse
+
+	// jsonStdout is the real stdout while the formatted output is discarded.
+	jsonStdout *os.File
+)
+
+// jsonResponse is the body of an API response and the call that returned it.
+type jsonResponse struct {
+	call string
+	body json.RawMessage
+}
+
+// recordResponse records the body of an API response for JSON mode.
+func recordResponse(call string, body []byte) {
+	if !jsonOutput {
+		return
+	}
+	if i := strings.IndexByte(call, '?'); i >= 0 {
+		call = call[:i]
+	}
+	jsonResponses = append(jsonResponses, jsonResponse{call: call, body: body})
+}
+
+// startJSONOutput discards the formatted output of the command if JSON mode is
+// enabled.
+func startJSONOutput() {
+	if !jsonOutput {
+		return
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		die("Could not open", os.DevNull+":", err)
+	}
+	jsonStdout, os.Stdout = os.Stdout, devNull
+	jsonResponses = nil
+}
+
+// finishJSONOutput prints the recorded API responses if JSON mode is enabled.
+func finishJSONOutput() {
+	if !jsonOutput || jsonStdout == nil {
+		return
+	}
+	os.Stdout.Close()
+	os.Stdout, jsonStdout = jsonStdout, nil
+
+	var out interface{}
+	switch len(jsonResponses) {
+	case 0:
+		out = map[string]bool{"success": true}
+	case 1:
+		out = jsonResponses[0].body
+	default:
+		calls := make(map[string]json.RawMessage)
+		for _, r := range jsonResponses {
+			calls[r.call] = r.body
+		}
+		out = calls
+	}
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		die("Could not encode JSON output:", err)
+	}
+	fmt.Println(string(b))
+}
+
+// dieJSON prints an error as JSON to the real stdout, then exits with the
+// provided exit code. The code of an API error is included, so that scripts
+// can branch on it.
+func dieJSON(code int, args ...interface{}) {
+	os.Stdout.Close()
+	os.Stdout, jsonStdout = jsonStdout, nil
+	out := map[string]string{"error": strings.TrimSpace(fmt.Sprintln(args...))}
+	if errCode := apiErrorCode(args); errCode != "" {
+		out["code"] = errCode
+	}
+	b, _ := json.MarshalIndent(out, "", "  ")
+	fmt.Println(string(b))
+	exit(code)
+}
+
+// ask prompts for a secret without echoing it. In JSON and quiet mode the
+// prompt is written to stderr, as stdout only carries the output of the
+// command.
+func ask(prompt string) (string, error) {
+	return speakeasy.FAsk(promptOutput(), prompt)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestJSONOutput checks how the recorded API responses are printed in JSON
+// mode.
+func TestJSONOutput(t *testing.T) {
+	defer func(stdout *os.File) {
+		os.Stdout = stdout
+		jsonOutput = false
+		jsonResponses = nil
+		jsonStdout = nil
+	}(os.Stdout)
+
+	tests := []struct {
+		responses []jsonResponse
+		out       string
+	}{
+		{nil, `{"success":true}`},
+		{[]jsonResponse{{"/wallet", json.RawMessage(`{"unlocked":true}`)}}, `{"unlocked":true}`},
+		{[]jsonResponse{
+			{"/wallet", json.RawMessage(`{"unlocked":true}`)},
+			{"/consensus", json.RawMessage(`{"height":1}`)},
+		}, `{"/consensus":{"height":1},"/wallet":{"unlocked":true}}`},
+	}
+	for _, test := range tests {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout = w
+		jsonOutput = true
+		startJSONOutput()
+		jsonResponses = test.responses
+		finishJSONOutput()
+		w.Close()
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got, want interface{}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		json.Unmarshal([]byte(test.out), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %s", test.out, b)
+		}
+	}
+}
+
+// TestRecordResponse checks that responses are only recorded in JSON mode, and
+// are recorded without the query string of the call.
+func TestRecordResponse(t *testing.T) {
+	defer func() {
+		jsonOutput = false
+		jsonResponses = nil
+	}()
+
+	recordResponse("/wallet", []byte("{}"))
+	if len(jsonResponses) != 0 {
+		t.Fatal("response recorded outside of JSON mode")
+	}
+	jsonOutput = true
+	recordResponse("/wallet/transactions?startheight=0", []byte("{}"))
+	if len(jsonResponses) != 1 || jsonResponses[0].call != "/wallet/transactions" {
+		t.Fatal("response not recorded correctly:", jsonResponses)
+	}
+}
@@ -4,26 +4,53 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"reflect"
 
-	"github.com/bgentry/speakeasy"
 	"github.com/spf13/cobra"
 
 	"github.com/NebulousLabs/Sia/api"
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 var (
 	// Flags.
-	addr              string // override default API address
-	initPassword      bool   // supply a custom password when creating a wallet
-	initForce         bool   // destroy and reencrypt the wallet on init if it already exists
-	hostVerbose       bool   // display additional host info
-	renterShowHistory bool   // Show download history in addition to download queue.
-	renterListVerbose bool   // Show additional info about uploaded files.
+	addr                    string  // override default API address
+	currencyUnit            string  // unit used to display siacoin amounts
+	initPassword            bool    // supply a custom password when creating a wallet
+	initForce               bool    // destroy and reencrypt the wallet on init if it already exists
+	hostVerbose             bool    // display additional host info
+	hostConsistencyCheck    bool    // run a new consistency check
+	hostConsistencyCleanup  bool    // clean up the stored sectors before a consistency check
+	hostDowntimeStart       float64 // days until a simulated downtime starts
+	hostDowntimeMissed      float64 // percentage of proofs missed during a simulated downtime
+	renterBenchmarkSize     string  // size of the benchmark test file
+	renterShowHistory       bool    // Show download history in addition to download queue.
+	renterListVerbose       bool    // Show additional info about uploaded files.
+	renterMaxSystemTraffic  uint64  // other network traffic above which uploads pause
+	renterPrefetchChunks    string  // chunks of the files to prefetch
+	renterRecoverContracts  string  // IDs of the contracts to recover
+	renterUploadBackground  bool    // return once an upload has started
+	runContinueOnError      bool    // run the remaining commands of a script after one fails
+	walletAddressQR         bool    // print the new address as a QR code
+	walletName              string  // select a named wallet for wallet commands
+	walletConfirmTarget     uint64  // number of blocks within which a send should confirm
+	walletDryRun            bool    // build a send without broadcasting it
+	walletMemo              string  // memo attached to a send
+	walletPartialBroadcast  bool    // broadcast a finalized partial transaction
+	walletPartialDesc       string  // description of a partial transaction
+	walletReplaceable       bool    // raise the fee of a send that misses its confirmation target
+	walletReplaceFee        string  // fee of a replacement transaction
+	walletRequestExpires    string  // time until a payment request expires
+	walletRequestLabel      string  // label of a payment request
+	walletRequestMessage    string  // message of a payment request
+	walletReservesAmount    string  // amount proven by a proof of reserves
+	walletReservesChallenge string  // challenge signed by a proof of reserves
+	walletSendURI           string  // payment URI to pay
+	walletWatchSiag         bool    // track siag keys without rescanning the whole wallet
 
 	// Globals.
 	rootCmd *cobra.Command // Root command cobra object, used by bash completion cmd.
@@ -36,24 +63,80 @@ var (
 // Exit codes.
 // inspired by sysexits.h
 const (
-	exitCodeGeneral = 1  // Not in sysexits.h, but is standard practice.
-	exitCodeUsage   = 64 // EX_USAGE in sysexits.h
+	exitCodeGeneral     = 1  // Not in sysexits.h, but is standard practice.
+	exitCodeUsage       = 64 // EX_USAGE in sysexits.h
+	exitCodeAPI         = 65 // EX_DATAERR in sysexits.h; siad rejected the request.
+	exitCodeUnavailable = 69 // EX_UNAVAILABLE in sysexits.h; siad could not be reached.
+	exitCodeAuth        = 77 // EX_NOPERM in sysexits.h; siad rejected the API password.
 )
 
+// errAuthFailed is returned when siad rejects the API password.
+var errAuthFailed = errors.New("API authentication failed; check the password")
+
+// A connectionError is an error reaching siad, as opposed to an error
+// returned by siad.
+type connectionError struct {
+	error
+}
+
+// Unwrap returns the underlying error.
+func (ce connectionError) Unwrap() error {
+	return ce.error
+}
+
+// exitCode returns the exit code for a failure described by args, based on
+// the errors among them.
+func exitCode(args []interface{}) int {
+	for _, arg := range args {
+		err, ok := arg.(error)
+		if !ok {
+			continue
+		}
+		var ce connectionError
+		var ae api.Error
+		switch {
+		case errors.Is(err, errAuthFailed):
+			return exitCodeAuth
+		case errors.As(err, &ce):
+			return exitCodeUnavailable
+		case errors.As(err, &ae):
+			return exitCodeAPI
+		}
+	}
+	return exitCodeGeneral
+}
+
+// errorHints suggest how to resolve the errors of some API error codes.
+var errorHints = map[string]string{
+	api.ErrCodeWalletLocked:         "Unlock the wallet with 'siac wallet unlock'.",
+	api.ErrCodeWalletNotInitialized: "Create a wallet with 'siac wallet init'.",
+	api.ErrCodeNotSynced:            "Wait for siad to sync; 'siac consensus' shows its progress.",
+}
+
+// apiErrorCode returns the code of the first api.Error among args, if any.
+func apiErrorCode(args []interface{}) string {
+	for _, arg := range args {
+		var ae api.Error
+		if err, ok := arg.(error); ok && errors.As(err, &ae) {
+			return ae.Code
+		}
+	}
+	return ""
+}
+
 // non2xx returns true for non-success HTTP status codes.
 func non2xx(code int) bool {
 	return code < 200 || code > 299
 }
 
 // decodeError returns the api.Error from a API response. This method should
-// only be called if the response's status code is non-2xx. The error returned
-// may not be of type api.Error in the event of an error unmarshalling the
-// JSON.
+// only be called if the response's status code is non-2xx. If the response is
+// not an api.Error, an api.Error describing the status code is returned.
 func decodeError(resp *http.Response) error {
 	var apiErr api.Error
 	err := json.NewDecoder(resp.Body).Decode(&apiErr)
 	if err != nil {
-		return err
+		return api.Error{Message: "siad returned " + resp.Status}
 	}
 	return apiErr
 }
@@ -62,12 +145,18 @@ func decodeError(resp *http.Response) error {
 // not return 2xx, the error will be read and returned. The response body is
 // not closed.
 func apiGet(call string) (*http.Response, error) {
-	if host, port, _ := net.SplitHostPort(addr); host == "" {
-		addr = net.JoinHostPort("localhost", port)
+	url, err := apiURL(call)
+	if err != nil {
+		return nil, err
+	}
+	var resp *http.Response
+	if apiPassword != "" {
+		resp, err = api.HttpGETAuthenticated(url, apiPassword)
+	} else {
+		resp, err = api.HttpGET(url)
 	}
-	resp, err := api.HttpGET("http://" + addr + call)
 	if err != nil {
-		return nil, errors.New("no response from daemon")
+		return nil, daemonError(err)
 	}
 	// check error code
 	if resp.StatusCode == http.StatusUnauthorized {
@@ -76,19 +165,23 @@ func apiGet(call string) (*http.Response, error) {
 		if apiPassword == "" {
 			// prompt for password and store it in a global var for subsequent
 			// calls
-			apiPassword, err = speakeasy.Ask("API password: ")
+			apiPassword, err = ask("API password: ")
 			if err != nil {
 				return nil, err
 			}
 		}
-		resp, err = api.HttpGETAuthenticated("http://"+addr+call, apiPassword)
+		resp, err = api.HttpGETAuthenticated(url, apiPassword)
 		if err != nil {
-			return nil, errors.New("no response from daemon - authentication failed")
+			return nil, daemonError(err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			resp.Body.Close()
+			return nil, errAuthFailed
 		}
 	}
 	if resp.StatusCode == http.StatusNotFound {
 		resp.Body.Close()
-		return nil, errors.New("API call not recognized: " + call)
+		return nil, api.Error{Message: "API call not recognized: " + call}
 	}
 	if non2xx(resp.StatusCode) {
 		err := decodeError(resp)
@@ -111,11 +204,12 @@ func getAPI(call string, obj interface{}) error {
 		return errors.New("expecting a response, but API returned status code 204 No Content")
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(obj)
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	return nil
+	recordResponse(call, body)
+	return json.Unmarshal(body, obj)
 }
 
 // get makes an API call and discards the response. An error is returned if the
@@ -133,30 +227,41 @@ func get(call string) error {
 // does not return 2xx, the error will be read and returned. The response body
 // is not closed.
 func apiPost(call, vals string) (*http.Response, error) {
-	if host, port, _ := net.SplitHostPort(addr); host == "" {
-		addr = net.JoinHostPort("localhost", port)
+	url, err := apiURL(call)
+	if err != nil {
+		return nil, err
+	}
+	var resp *http.Response
+	if apiPassword != "" {
+		resp, err = api.HttpPOSTAuthenticated(url, vals, apiPassword)
+	} else {
+		resp, err = api.HttpPOST(url, vals)
 	}
-
-	resp, err := api.HttpPOST("http://"+addr+call, vals)
 	if err != nil {
-		return nil, errors.New("no response from daemon")
+		return nil, daemonError(err)
 	}
 	// check error code
 	if resp.StatusCode == http.StatusUnauthorized {
 		resp.Body.Close()
 		// Prompt for password and retry request with authentication.
-		password, err := speakeasy.Ask("API password: ")
-		if err != nil {
-			return nil, err
+		if apiPassword == "" {
+			apiPassword, err = ask("API password: ")
+			if err != nil {
+				return nil, err
+			}
 		}
-		resp, err = api.HttpPOSTAuthenticated("http://"+addr+call, vals, password)
+		resp, err = api.HttpPOSTAuthenticated(url, vals, apiPassword)
 		if err != nil {
-			return nil, errors.New("no response from daemon - authentication failed")
+			return nil, daemonError(err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			resp.Body.Close()
+			return nil, errAuthFailed
 		}
 	}
 	if resp.StatusCode == http.StatusNotFound {
 		resp.Body.Close()
-		return nil, errors.New("API call not recognized: " + call)
+		return nil, api.Error{Message: "API call not recognized: " + call}
 	}
 	if non2xx(resp.StatusCode) {
 		err := decodeError(resp)
@@ -179,11 +284,12 @@ func postResp(call, vals string, obj interface{}) error {
 		return errors.New("expecting a response, but API returned status code 204 No Content")
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(obj)
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	return nil
+	recordResponse(call, body)
+	return json.Unmarshal(body, obj)
 }
 
 // post makes an API call and discards the response. An error is returned if
@@ -214,7 +320,7 @@ func wrap(fn interface{}) func(*cobra.Command, []string) {
 	return func(cmd *cobra.Command, args []string) {
 		if len(args) != fnType.NumIn() {
 			cmd.UsageFunc()(cmd)
-			os.Exit(exitCodeUsage)
+			exit(exitCodeUsage)
 		}
 		argVals := make([]reflect.Value, fnType.NumIn())
 		for i := range args {
@@ -224,11 +330,44 @@ func wrap(fn interface{}) func(*cobra.Command, []string) {
 	}
 }
 
-// die prints its arguments to stderr, then exits the program with the default
-// error code.
+// die prints its arguments to stderr, then exits the program. The exit code
+// tells whether siad could not be reached, rejected the API password or
+// returned an error, based on the errors among args. In JSON mode, the error
+// is printed to stdout as JSON. While a command is watched, the error is shown
+// as its output instead.
 func die(args ...interface{}) {
+	dieCode(exitCode(args), args...)
+}
+
+// dieUsage is like die, but exits with exitCodeUsage. It is called when the
+// arguments or flags of a command are invalid.
+func dieUsage(args ...interface{}) {
+	dieCode(exitCodeUsage, args...)
+}
+
+// dieCode prints its arguments to stderr, then exits the program with the
+// provided exit code.
+func dieCode(code int, args ...interface{}) {
+	if watching {
+		panic(watchError(fmt.Sprintln(args...)))
+	}
+	if jsonStdout != nil {
+		dieJSON(code, args...)
+	}
 	fmt.Fprintln(os.Stderr, args...)
-	os.Exit(exitCodeGeneral)
+	if hint, ok := errorHints[apiErrorCode(args)]; ok {
+		fmt.Fprintln(os.Stderr, hint)
+	}
+	exit(code)
+}
+
+// exit exits the program with the provided exit code. While a script is run,
+// only the current command of the script is ended.
+func exit(code int) {
+	if runningScript {
+		panic(scriptExit(code))
+	}
+	os.Exit(code)
 }
 
 func main() {
@@ -244,15 +383,24 @@ func main() {
 	// create command tree
 	root.AddCommand(versionCmd)
 	root.AddCommand(stopCmd)
+	root.AddCommand(crashesCmd)
+
+	root.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonConfigCmd, daemonLogCmd, daemonLogLevelCmd, daemonProfileCmd, daemonProfilingCmd)
+	daemonProfileCmd.Flags().StringVarP(&daemonProfileOutput, "output", "o", "", "file to save the profile to; defaults to <name>.pprof, or trace.out for the trace")
 
 	root.AddCommand(updateCmd)
 	updateCmd.AddCommand(updateCheckCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostFolderCmd, hostSectorCmd)
+	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostConsistencyCmd, hostContractsCmd, hostFolderCmd, hostReindexCmd, hostSectorCmd, hostSimulateDowntimeCmd, hostStatusCmd, hostUsageCmd)
 	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
 	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
 	hostCmd.Flags().BoolVarP(&hostVerbose, "verbose", "v", false, "Display detailed host info")
+	hostConsistencyCmd.Flags().BoolVar(&hostConsistencyCheck, "check", false, "Run a new consistency check")
+	hostConsistencyCmd.Flags().BoolVar(&hostConsistencyCleanup, "cleanup", false, "Delete orphaned sectors and correct reference counts, then run a new check")
+	hostSimulateDowntimeCmd.Flags().Float64Var(&hostDowntimeStart, "start", 0, "Days from now until the downtime starts")
+	hostSimulateDowntimeCmd.Flags().Float64Var(&hostDowntimeMissed, "missed-percent", 100, "Percentage of the storage proofs due during the downtime that are missed")
 
 	root.AddCommand(hostdbCmd)
 	hostdbCmd.AddCommand(hostdbViewCmd)
@@ -260,44 +408,162 @@ func main() {
 	hostdbCmd.Flags().BoolVarP(&hostdbVerbose, "verbose", "v", false, "Display full hostdb information")
 
 	root.AddCommand(minerCmd)
-	minerCmd.AddCommand(minerStartCmd, minerStopCmd)
+	minerCmd.AddCommand(minerStartCmd, minerStatusCmd, minerStopCmd)
 
 	root.AddCommand(walletCmd)
-	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletChangepasswordCmd, walletInitCmd, walletInitSeedCmd,
-		walletLoadCmd, walletLockCmd, walletSeedsCmd, walletSendCmd, walletSweepCmd,
-		walletBalanceCmd, walletTransactionsCmd, walletUnlockCmd)
+	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletBackupCmd, walletBumpFeeCmd, walletCancelCmd, walletChangepasswordCmd, walletDeriveCmd, walletInitCmd, walletInitSeedCmd,
+		walletLoadCmd, walletLockCmd, walletPartialCmd, walletPendingCmd, walletReindexCmd, walletRequestCmd, walletRequestsCmd, walletReservesCmd, walletRestoreCmd, walletSeedsCmd, walletSendCmd, walletSiafundsCmd, walletSweepCmd,
+		walletBalanceCmd, walletTransactionsCmd, walletUnlockCmd, walletVerifyReservesCmd)
+	walletAddressCmd.Flags().BoolVarP(&walletAddressQR, "qr", "", false, "also print the address as a QR code for scanning with a mobile wallet")
+	walletCmd.PersistentFlags().StringVarP(&walletName, "wallet", "w", "", "name of the wallet to use, as loaded by siad's --wallets flag")
 	walletInitCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Prompt for a custom password")
 	walletInitCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet and re-encrypt")
 	walletInitSeedCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet")
+	walletBumpFeeCmd.Flags().StringVarP(&walletReplaceFee, "fee", "", "", "total fee of the replacement, e.g. 1SC")
+	walletCancelCmd.Flags().StringVarP(&walletReplaceFee, "fee", "", "", "total fee of the replacement, e.g. 1SC")
 	walletLoadCmd.AddCommand(walletLoad033xCmd, walletLoadSeedCmd, walletLoadSiagCmd)
-	walletSendCmd.AddCommand(walletSendSiacoinsCmd, walletSendSiafundsCmd)
+	walletLoadSiagCmd.Flags().BoolVarP(&walletWatchSiag, "watch", "", false, "Only scan for the outputs of the siag address instead of rescanning the whole wallet; earlier transaction history is not recovered")
+	walletPartialCmd.AddCommand(walletPartialCreateCmd, walletPartialFinalizeCmd, walletPartialMergeCmd, walletPartialShowCmd, walletPartialSignCmd)
+	walletPartialCreateCmd.Flags().StringVarP(&walletPartialDesc, "description", "", "", "description of the transaction for the other signers")
+	walletPartialFinalizeCmd.Flags().BoolVarP(&walletPartialBroadcast, "broadcast", "", false, "give the signed transaction set to the transaction pool")
+	walletReservesCmd.Flags().StringVarP(&walletReservesAmount, "amount", "", "", "amount to prove, e.g. 1000SC; defaults to the whole confirmed balance")
+	walletReservesCmd.Flags().StringVarP(&walletReservesChallenge, "challenge", "", "", "message signed by the proof, such as a nonce chosen by the auditor")
+	walletSendCmd.AddCommand(walletSendAllCmd, walletSendSiacoinsCmd, walletSendSiafundsCmd)
+	walletRequestCmd.Flags().StringVarP(&walletRequestExpires, "expires", "", "", "time until the payment request expires, e.g. 24h; the request never expires by default")
+	walletRequestCmd.Flags().StringVarP(&walletRequestLabel, "label", "", "", "name of the recipient to include in the payment URI")
+	walletRequestCmd.Flags().StringVarP(&walletRequestMessage, "message", "", "", "description of the payment to include in the payment URI")
+	walletSendCmd.Flags().StringVarP(&walletSendURI, "uri", "", "", "pay a sia: payment URI")
+	walletSendCmd.PersistentFlags().BoolVarP(&walletYes, "yes", "y", false, "send without asking for confirmation")
+	walletSendCmd.PersistentFlags().BoolVarP(&walletDryRun, "dry-run", "", false, "show the inputs, outputs and fee of the transaction without sending it")
+	walletSiafundsSendManyCmd.Flags().BoolVarP(&walletYes, "yes", "y", false, "send without asking for confirmation")
+	walletSendSiacoinsCmd.Flags().Uint64VarP(&walletConfirmTarget, "confirm-target", "", 0, "pick the fee so that the transaction is confirmed within this many blocks")
+	walletSendSiacoinsCmd.Flags().BoolVarP(&walletReplaceable, "replaceable", "", false, "raise the fee if the transaction misses its confirmation target")
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletMemo, "data", "", "", "public memo to attach to the transaction, such as an order ID")
+	walletSiafundsCmd.AddCommand(walletSiafundsClaimCmd, walletSiafundsAutoClaimCmd, walletSiafundsPrepareCmd, walletSiafundsSendManyCmd, walletSiafundsSignCmd, walletSiafundsBroadcastCmd, walletSiafundsTrackDirCmd)
 	walletUnlockCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Display interactive password prompt even if SIA_WALLET_PASSWORD is set")
 
 	root.AddCommand(renterCmd)
 	renterCmd.AddCommand(renterFilesDeleteCmd, renterFilesDownloadCmd,
-		renterDownloadsCmd, renterAllowanceCmd, renterSetAllowanceCmd,
-		renterContractsCmd, renterFilesListCmd, renterFilesRenameCmd,
-		renterFilesUploadCmd, renterUploadsCmd, renterExportCmd,
-		renterPricesCmd)
+		renterDownloadsCmd, renterAllowanceCmd, renterSetAllowanceCmd, renterBandwidthCmd,
+		renterContractsCmd, renterFilesListCmd, renterFilesRecoverCmd, renterFilesRenameCmd,
+		renterFilesUploadCmd, renterUpdateCmd, renterUploadsCmd, renterExportCmd,
+		renterPricesCmd, renterScheduleCmd, renterPrefetchCmd, renterRecoverCmd, renterBenchmarkCmd)
 
 	renterContractsCmd.AddCommand(renterContractsViewCmd)
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
+	renterScheduleCmd.AddCommand(renterScheduleSetCmd)
+	renterPrefetchCmd.AddCommand(renterPrefetchEvictCmd)
+	renterPrefetchCmd.Flags().StringVarP(&renterPrefetchChunks, "chunks", "", "", "chunks to prefetch, such as \"0,2,5-9\"; all chunks by default")
+	renterRecoverCmd.Flags().StringVarP(&renterRecoverContracts, "contracts", "", "", "comma-separated IDs of the contracts to recover")
+	renterScheduleSetCmd.Flags().Uint64VarP(&renterMaxSystemTraffic, "max-system-traffic", "", 0, "pause uploads while other network traffic exceeds this many bytes per second; 0 disables the limit")
 
 	renterCmd.Flags().BoolVarP(&renterListVerbose, "verbose", "v", false, "Show additional file info such as redundancy")
 	renterDownloadsCmd.Flags().BoolVarP(&renterShowHistory, "history", "H", false, "Show download history in addition to the download queue")
+	renterBenchmarkCmd.Flags().StringVar(&renterBenchmarkSize, "size", "40MiB", "size of the test file, such as 100MiB")
+	renterFilesUploadCmd.Flags().BoolVarP(&renterUploadBackground, "background", "b", false, "return once the upload has started instead of showing its progress")
 	renterFilesListCmd.Flags().BoolVarP(&renterListVerbose, "verbose", "v", false, "Show additional file info such as redundancy")
 	renterExportCmd.AddCommand(renterExportContractTxnsCmd)
 
 	root.AddCommand(gatewayCmd)
-	gatewayCmd.AddCommand(gatewayConnectCmd, gatewayDisconnectCmd, gatewayAddressCmd, gatewayListCmd)
+	gatewayCmd.AddCommand(gatewayConnectCmd, gatewayDisconnectCmd, gatewayAddressCmd, gatewayListCmd, gatewaySummaryCmd,
+		gatewayAddCmd, gatewayRemoveCmd, gatewayBanCmd, gatewayUnbanCmd)
 
 	root.AddCommand(consensusCmd)
 
+	root.AddCommand(explorerCmd)
+	explorerCmd.AddCommand(explorerBlockCmd, explorerTransactionCmd, explorerAddressCmd)
+
+	root.AddCommand(tpoolCmd)
+	tpoolCmd.AddCommand(tpoolTransactionsCmd, tpoolTransactionCmd)
+
+	root.AddCommand(runCmd)
+	runCmd.Flags().BoolVarP(&runContinueOnError, "continue-on-error", "", false, "run the remaining commands of the script after a command fails")
+
+	root.AddCommand(completionCmd)
 	root.AddCommand(bashcomplCmd)
 	root.AddCommand(mangenCmd)
 
 	// parse flags
-	root.PersistentFlags().StringVarP(&addr, "addr", "a", "localhost:9980", "which host/port to communicate with (i.e. the host/port siad is listening on)")
+	root.PersistentFlags().StringVarP(&addr, "addr", "a", "localhost:9980", "which host/port to communicate with (i.e. the host/port siad is listening on); prefix with https:// for a remote siad serving TLS, or use unix:///path/to/socket for a siad serving --api-socket")
+	root.PersistentFlags().StringVarP(&currencyUnit, "unit", "", defaultCurrencyUnit(), "unit used to display siacoin amounts: auto, H, or one of the units listed in 'wallet --help'; defaults to SIA_CURRENCY_UNIT if set")
+	root.PersistentFlags().StringVarP(&numberFormat, "number-format", "", numberFormatPlain, "digit grouping of amounts: plain, grouped (12,345.678), locale for the locale of the environment, or a locale such as de_DE")
+	root.PersistentFlags().BoolVarP(&jsonOutput, "json", "", false, "print the API responses as JSON instead of formatted output")
+	root.PersistentFlags().DurationVarP(&apiTimeout, "timeout", "", 0, "time limit of each API call, including retries, e.g. 30s; no limit by default")
+	root.PersistentFlags().Uint64VarP(&apiRetries, "retries", "", 0, "number of times an API call that fails to reach siad is retried")
+	root.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "log the API calls made to siad, with their status and timing, to stderr")
+	root.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", false, "print only the primary value of the command, such as an address or transaction ID")
+	root.PersistentFlags().StringVarP(&caCertPath, "ca-cert", "", "", "PEM file of CA certificates used to verify the certificate of an https:// siad")
+	root.PersistentFlags().StringVarP(&certFingerprint, "cert-fingerprint", "", "", "SHA-256 fingerprint of the certificate of an https:// siad, for self-signed certificates")
+	root.PersistentFlags().StringVarP(&profile, "profile", "", "", "profile of the config file to use; defaults to SIA_PROFILE if set")
+	root.PersistentFlags().StringVarP(&configPath, "config", "", defaultConfigPath(), "path of the config file; defaults to SIAC_CONFIG if set")
+	addWatchFlag(root, consensusCmd, explorerCmd, gatewayCmd, gatewayListCmd, gatewaySummaryCmd, hostCmd, hostContractsCmd, hostStatusCmd, hostdbCmd, minerCmd, minerStatusCmd,
+		renterCmd, renterBandwidthCmd, renterContractsCmd, renterDownloadsCmd, renterUploadsCmd, tpoolCmd, tpoolTransactionsCmd,
+		walletCmd, walletBalanceCmd, walletPendingCmd, walletRequestsCmd)
+
+	// complete arguments from siad
+	renterFilesDeleteCmd.ValidArgsFunction = completeArgs(completeSiapaths, 0)
+	renterFilesDownloadCmd.ValidArgsFunction = completeArgs(completeSiapaths, 0)
+	renterFilesRenameCmd.ValidArgsFunction = completeArgs(completeSiapaths, 0)
+	renterUpdateCmd.ValidArgsFunction = completeArgs(completeSiapaths, 1)
+	renterPrefetchCmd.ValidArgsFunction = completeArgs(completeSiapaths, -1)
+	renterPrefetchEvictCmd.ValidArgsFunction = completeArgs(completeSiapaths, 0)
+	renterContractsViewCmd.ValidArgsFunction = completeArgs(completeRenterContracts, 0)
+	hostUsageCmd.ValidArgsFunction = completeArgs(completeHostContracts, 0)
+	hostdbViewCmd.ValidArgsFunction = completeArgs(completeHostPubkeys, 0)
+	gatewayDisconnectCmd.ValidArgsFunction = completeArgs(completePeers, 0)
+	gatewayRemoveCmd.ValidArgsFunction = completeArgs(completePeers, 0)
+	gatewayBanCmd.ValidArgsFunction = completeArgs(completePeers, 0)
+	gatewayUnbanCmd.ValidArgsFunction = completeArgs(completeBannedHosts, 0)
+	tpoolTransactionCmd.ValidArgsFunction = completeArgs(completeTpoolTxids, 0)
+	walletBumpFeeCmd.ValidArgsFunction = completeArgs(completePendingTxids, 0)
+	walletCancelCmd.ValidArgsFunction = completeArgs(completePendingTxids, 0)
+	walletSendAllCmd.ValidArgsFunction = completeArgs(completeDestinations(types.SpecifierSiacoinOutput), 0)
+	walletSendSiacoinsCmd.ValidArgsFunction = completeArgs(completeDestinations(types.SpecifierSiacoinOutput), 1)
+	walletSendSiafundsCmd.ValidArgsFunction = completeArgs(completeDestinations(types.SpecifierSiafundOutput), 1)
+	walletCmd.RegisterFlagCompletionFunc("wallet", completeWallets)
+
+	root.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if isCompletionRequest(cmd) {
+			// Completions are best-effort: they use whatever settings can be
+			// loaded, and print candidates rather than JSON.
+			loadConfig(cmd)
+			configureTLS()
+			configureSocket()
+			return
+		}
+		if err := loadConfig(cmd); err != nil {
+			die("Could not load config:", err)
+		}
+		if err := configureTLS(); err != nil {
+			die("Could not configure TLS:", err)
+		}
+		configureSocket()
+		if !validCurrencyUnit(currencyUnit) {
+			dieUsage("Invalid unit:", currencyUnit)
+		}
+		if !validNumberFormat(numberFormat) {
+			dieUsage("Invalid number format:", numberFormat)
+		}
+		if quietOutput && jsonOutput {
+			// --quiet takes precedence over JSON output from the config
+			// file or environment, but not over --json.
+			if cmd.Flags().Changed("json") {
+				dieUsage("--quiet cannot be used with --json.")
+			}
+			jsonOutput = false
+		}
+		startJSONOutput()
+		startQuietOutput()
+		configureHTTPClient()
+	}
+	root.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		finishJSONOutput()
+		finishQuietOutput()
+	}
+
+	if err := loadAliases(root, os.Args[1:]); err != nil {
+		die("Could not load aliases:", err)
+	}
 
 	// run
 	if err := root.Execute(); err != nil {
@@ -10,10 +10,11 @@ import (
 
 var (
 	minerCmd = &cobra.Command{
-		Use:   "miner",
-		Short: "Perform miner actions",
-		Long:  "Perform miner actions and view miner status.",
-		Run:   wrap(minercmd),
+		Use:     "miner",
+		Aliases: []string{"m"},
+		Short:   "Perform miner actions",
+		Long:    "Perform miner actions and view miner status.",
+		Run:     wrap(minercmd),
 	}
 
 	minerStartCmd = &cobra.Command{
@@ -23,6 +24,13 @@ var (
 		Run:   wrap(minerstartcmd),
 	}
 
+	minerStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "View miner status",
+		Long:  "View whether the cpu miner is running, its hashrate, the number of threads it is hashing on, and the number of blocks it has found.",
+		Run:   wrap(minercmd),
+	}
+
 	minerStopCmd = &cobra.Command{
 		Use:   "stop",
 		Short: "Stop mining",
@@ -41,8 +49,24 @@ func minerstartcmd() {
 	fmt.Println("CPU Miner is now running.")
 }
 
-// minercmd is the handler for the command `siac miner`.
-// Prints the status of the miner.
+// hashrateString formats a hashrate in hashes per second with the largest
+// fitting unit.
+func hashrateString(hashrate int) string {
+	units := []string{"H/s", "KH/s", "MH/s", "GH/s", "TH/s"}
+	rate := float64(hashrate)
+	i := 0
+	for rate >= 1000 && i < len(units)-1 {
+		rate /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d %s", hashrate, units[0])
+	}
+	return fmt.Sprintf("%.2f %s", rate, units[i])
+}
+
+// minercmd is the handler for the commands `siac miner` and `siac miner
+// status`. Prints the status of the miner.
 func minercmd() {
 	status := new(api.MinerGET)
 	err := getAPI("/miner", status)
@@ -56,9 +80,10 @@ func minercmd() {
 	}
 	fmt.Printf(`Miner status:
 CPU Mining:   %s
-CPU Hashrate: %v KH/s
-Blocks Mined: %d (%d stale)
-`, miningStr, status.CPUHashrate/1000, status.BlocksMined, status.StaleBlocksMined)
+CPU Hashrate: %v
+CPU Threads:  %d
+Blocks Found: %d (%d stale)
+`, miningStr, hashrateString(status.CPUHashrate), status.CPUThreads, status.BlocksMined, status.StaleBlocksMined)
 }
 
 // minerstopcmd is the handler for the command `siac miner stop`.
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+// TestHashrateString checks that hashrates are formatted with the largest
+// fitting unit.
+func TestHashrateString(t *testing.T) {
+	tests := []struct {
+		hashrate int
+		exp      string
+	}{
+		{0, "0 H/s"},
+		{999, "999 H/s"},
+		{1000, "1.00 KH/s"},
+		{1337, "1.34 KH/s"},
+		{2500000, "2.50 MH/s"},
+		{7e12, "7.00 TH/s"},
+		{7e15, "7000.00 TH/s"},
+	}
+	for _, test := range tests {
+		if s := hashrateString(test.hashrate); s != test.exp {
+			t.Errorf("hashrateString(%v): expected %q, got %q", test.hashrate, test.exp, s)
+		}
+	}
+}
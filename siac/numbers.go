@@ -0,0 +1,163 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// Amounts are printed without digit grouping by default, which makes large
+// balances, especially in hastings, hard to read. --number-format grouped
+// prints them with grouped digits, as in 12,345.678 SC, and a locale name such
+// as de_DE, or "locale" for the locale of the environment, uses the
+// separators of that locale instead, as in 12.345,678 SC. Only the formatted
+// output is affected; amounts are always parsed and sent to siad without
+// separators.
+
+const (
+	// numberFormatPlain prints numbers without digit grouping.
+	numberFormatPlain = "plain"
+
+	// numberFormatGrouped groups digits with commas.
+	numberFormatGrouped = "grouped"
+
+	// numberFormatLocale uses the separators of the locale set by LC_ALL,
+	// LC_NUMERIC or LANG.
+	numberFormatLocale = "locale"
+)
+
+var (
+	// numberFormat is set by the --number-format flag.
+	numberFormat = numberFormatPlain
+
+	// groupedSeparators are the separators of numberFormatGrouped.
+	groupedSeparators = numberSeparators{group: ",", decimal: "."}
+
+	// localeSeparators are the separators of locales, by language or by
+	// language and territory. Locales that group digits with spaces use
+	// no-break spaces, so that numbers are not split across lines.
+	localeSeparators = map[string]numberSeparators{
+		"en": groupedSeparators,
+		"ja": groupedSeparators,
+		"ko": groupedSeparators,
+		"zh": groupedSeparators,
+		"he": groupedSeparators,
+		"th": groupedSeparators,
+
+		"da": {group: ".", decimal: ","},
+		"de": {group: ".", decimal: ","},
+		"el": {group: ".", decimal: ","},
+		"es": {group: ".", decimal: ","},
+		"id": {group: ".", decimal: ","},
+		"it": {group: ".", decimal: ","},
+		"nl": {group: ".", decimal: ","},
+		"pt": {group: ".", decimal: ","},
+		"ro": {group: ".", decimal: ","},
+		"tr": {group: ".", decimal: ","},
+
+		"cs":    {group: "\u00a0", decimal: ","},
+		"fi":    {group: "\u00a0", decimal: ","},
+		"fr":    {group: "\u00a0", decimal: ","},
+		"hu":    {group: "\u00a0", decimal: ","},
+		"nb":    {group: "\u00a0", decimal: ","},
+		"pl":    {group: "\u00a0", decimal: ","},
+		"pt_PT": {group: "\u00a0", decimal: ","},
+		"ru":    {group: "\u00a0", decimal: ","},
+		"sk":    {group: "\u00a0", decimal: ","},
+		"sv":    {group: "\u00a0", decimal: ","},
+		"uk":    {group: "\u00a0", decimal: ","},
+
+		"de_CH": {group: "'", decimal: "."},
+		"es_MX": groupedSeparators,
+		"it_CH": {group: "'", decimal: "."},
+	}
+)
+
+// numberSeparators are the digit group and decimal separators of a number
+// format. A format without a group separator does not group digits.
+type numberSeparators struct {
+	group   string
+	decimal string
+}
+
+// localeName returns the language and territory of a locale such as
+// "de_DE.UTF-8@euro", i.e. "de_DE".
+func localeName(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.Replace(locale, "-", "_", -1)
+}
+
+// lookupLocale returns the separators of a locale.
+func lookupLocale(locale string) (numberSeparators, bool) {
+	name := localeName(locale)
+	if s, ok := localeSeparators[name]; ok {
+		return s, true
+	}
+	if i := strings.IndexByte(name, '_'); i >= 0 {
+		name = name[:i]
+	}
+	s, ok := localeSeparators[name]
+	return s, ok
+}
+
+// environmentLocale returns the locale used for numbers by the environment.
+func environmentLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if locale := os.Getenv(env); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// formatSeparators returns the separators of a number format, and whether the
+// format is valid. Locales of the environment that are not known group digits
+// with commas.
+func formatSeparators(format string) (numberSeparators, bool) {
+	switch format {
+	case "", numberFormatPlain:
+		return numberSeparators{decimal: "."}, true
+	case numberFormatGrouped:
+		return groupedSeparators, true
+	case numberFormatLocale:
+		if s, ok := lookupLocale(environmentLocale()); ok {
+			return s, true
+		}
+		return groupedSeparators, true
+	}
+	return lookupLocale(format)
+}
+
+// validNumberFormat returns whether format can be used to print numbers.
+func validNumberFormat(format string) bool {
+	_, ok := formatSeparators(format)
+	return ok
+}
+
+// formatNumber formats a decimal number, such as "-12345.678", with the
+// separators of the number format selected by --number-format. Numbers in
+// scientific notation only have their decimal separator replaced.
+func formatNumber(number string) string {
+	seps, _ := formatSeparators(numberFormat)
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	digits := len(number) - len(strings.TrimLeft(number, "0123456789"))
+	intPart, rest := number[:digits], number[digits:]
+	if strings.HasPrefix(rest, ".") {
+		rest = seps.decimal + rest[1:]
+	}
+	if seps.group == "" || len(intPart) <= 3 {
+		return sign + intPart + rest
+	}
+	var b strings.Builder
+	for i, d := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(seps.group)
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String() + rest
+}
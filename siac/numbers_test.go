@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestFormatNumber probes formatNumber with several number formats.
+func TestFormatNumber(t *testing.T) {
+	defer func(f string) { numberFormat = f }(numberFormat)
+	tests := []struct {
+		format, number, exp string
+	}{
+		{"plain", "12345.678", "12345.678"},
+		{"grouped", "12345.678", "12,345.678"},
+		{"grouped", "-1234567", "-1,234,567"},
+		{"grouped", "999.5", "999.5"},
+		{"grouped", "1.235e+04", "1.235e+04"},
+		{"de_DE", "12345.678", "12.345,678"},
+		{"de_DE.UTF-8", "1000000", "1.000.000"},
+		{"de_CH", "12345.678", "12'345.678"},
+		{"fr", "-12345.5", "-12\u00a0345,5"},
+		{"pt_BR", "1234.5", "1.234,5"},
+		{"pt_PT", "1234.5", "1\u00a0234,5"},
+	}
+	for _, test := range tests {
+		numberFormat = test.format
+		if s := formatNumber(test.number); s != test.exp {
+			t.Errorf("%v %v: expected %q, got %q", test.format, test.number, test.exp, s)
+		}
+	}
+}
+
+// TestFormatSeparators checks which number formats are valid, and that the
+// locale format follows the environment.
+func TestFormatSeparators(t *testing.T) {
+	for _, format := range []string{"", "plain", "grouped", "locale", "en_US", "de", "nl_NL.UTF-8"} {
+		if !validNumberFormat(format) {
+			t.Errorf("expected %q to be valid", format)
+		}
+	}
+	for _, format := range []string{"xx_XX", "commas", "Grouped"} {
+		if validNumberFormat(format) {
+			t.Errorf("expected %q to be invalid", format)
+		}
+	}
+
+	defer func(lcAll, lcNumeric, lang string) {
+		os.Setenv("LC_ALL", lcAll)
+		os.Setenv("LC_NUMERIC", lcNumeric)
+		os.Setenv("LANG", lang)
+	}(os.Getenv("LC_ALL"), os.Getenv("LC_NUMERIC"), os.Getenv("LANG"))
+	os.Setenv("LC_ALL", "")
+	os.Setenv("LC_NUMERIC", "sv_SE.UTF-8")
+	os.Setenv("LANG", "en_US.UTF-8")
+	if s, _ := formatSeparators("locale"); s != localeSeparators["sv"] {
+		t.Error("expected the separators of LC_NUMERIC, got", s)
+	}
+	os.Setenv("LC_NUMERIC", "C")
+	if s, _ := formatSeparators("locale"); s != groupedSeparators {
+		t.Error("expected unknown locales to group digits with commas, got", s)
+	}
+}
+
+// TestGroupedCurrencyUnits checks that amounts are printed with grouped
+// digits.
+func TestGroupedCurrencyUnits(t *testing.T) {
+	defer func(f, u string) { numberFormat, currencyUnit = f, u }(numberFormat, currencyUnit)
+	numberFormat = "grouped"
+	c := types.SiacoinPrecision.Mul64(12345678).Div64(1000)
+	currencyUnit = "SC"
+	if s := currencyUnits(c); s != "12,345.678 SC" {
+		t.Error("expected 12,345.678 SC, got", s)
+	}
+	currencyUnit = "H"
+	if s := currencyUnits(c); s != "12,345,678,000,000,000,000,000,000,000 H" {
+		t.Error("expected grouped hastings, got", s)
+	}
+	currencyUnit = unitAuto
+	if s := currencyUnits(types.SiacoinPrecision.Mul64(1234).Mul64(1e12)); s != "1,234 TS" {
+		t.Error("expected 1,234 TS, got", s)
+	}
+	if s := siafundUnits(types.NewCurrency64(10000)); s != "10,000 SF" {
+		t.Error("expected 10,000 SF, got", s)
+	}
+}
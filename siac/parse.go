@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/NebulousLabs/Sia/types"
@@ -101,19 +104,86 @@ func parsePeriod(period string) (string, error) {
 	return "", errUnableToParseSize
 }
 
-// currencyUnits converts a types.Currency to a string with human-readable
+var (
+	// amountRegexp matches a non-negative decimal number, optionally in
+	// scientific notation. The exponent is captured.
+	amountRegexp = regexp.MustCompile(`^\+?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE]([+-]?[0-9]+))?$`)
+
+	// maxCurrency is the largest amount of currency that can be sent over
+	// the wire, which limits the encoding of a types.Currency to 255 bytes.
+	maxCurrency = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 8*255), big.NewInt(1))
+)
+
+// maxAmountExponent is the largest exponent accepted in an amount written in
+// scientific notation. Amounts with larger exponents cannot be represented.
+const maxAmountExponent = 1000
+
+// currencyUnitNames lists the siacoin units, smallest first. Each unit is
+// 1000 times larger than the previous one, and "SC" is 10^24 hastings.
+var currencyUnitNames = []string{"pS", "nS", "uS", "mS", "SC", "KS", "MS", "GS", "TS"}
+
+// unitAuto selects the largest unit that displays an amount as a value of at
+// least 1.
+const unitAuto = "auto"
+
+// defaultCurrencyUnit returns the unit used to display siacoin amounts when
+// the --unit flag is not supplied. It can be set with the SIA_CURRENCY_UNIT
+// environment variable.
+func defaultCurrencyUnit() string {
+	if unit := os.Getenv("SIA_CURRENCY_UNIT"); unit != "" {
+		return unit
+	}
+	return unitAuto
+}
+
+// validCurrencyUnit returns whether unit can be used to display siacoin
+// amounts.
+func validCurrencyUnit(unit string) bool {
+	if unit == unitAuto || unit == "H" {
+		return true
+	}
+	for _, name := range currencyUnitNames {
+		if unit == name {
+			return true
+		}
+	}
+	return false
+}
+
+// currencyUnitExponent returns the power of ten of hastings that makes up one
+// of the given unit.
+func currencyUnitExponent(unit string) int {
+	for i, name := range currencyUnitNames {
+		if unit == name {
+			return 24 + 3*(i-4)
+		}
+	}
+	return 0
+}
+
+// currencyUnits converts a types.Currency to a string in the unit selected by
+// the --unit flag. By default, the amount is displayed in human-readable
+// units.
+func currencyUnits(c types.Currency) string {
+	if currencyUnit == "" || currencyUnit == unitAuto {
+		return humanCurrencyUnits(c)
+	}
+	return fixedCurrencyUnits(c, currencyUnit)
+}
+
+// humanCurrencyUnits converts a types.Currency to a string with human-readable
 // units. The unit used will be the largest unit that results in a value
 // greater than 1. The value is rounded to 4 significant digits.
-func currencyUnits(c types.Currency) string {
+func humanCurrencyUnits(c types.Currency) string {
 	pico := types.SiacoinPrecision.Div64(1e12)
 	if c.Cmp(pico) < 0 {
-		return c.String() + " H"
+		return formatNumber(c.String()) + " H"
 	}
 
 	// iterate until we find a unit greater than c
 	mag := pico
 	unit := ""
-	for _, unit = range []string{"pS", "nS", "uS", "mS", "SC", "KS", "MS", "GS", "TS"} {
+	for _, unit = range currencyUnitNames {
 		if c.Cmp(mag.Mul64(1e3)) < 0 {
 			break
 		} else if unit != "TS" {
@@ -127,36 +197,137 @@ func currencyUnits(c types.Currency) string {
 	denom := new(big.Rat).SetInt(mag.Big())
 	res, _ := new(big.Rat).Mul(num, denom.Inv(denom)).Float64()
 
-	return fmt.Sprintf("%.4g %s", res, unit)
+	return formatNumber(fmt.Sprintf("%.4g", res)) + " " + unit
+}
+
+// fixedCurrencyUnits converts a types.Currency to a string in the given unit.
+// Unlike humanCurrencyUnits, the value is exact, so that amounts can be
+// compared and copied without losing precision.
+func fixedCurrencyUnits(c types.Currency, unit string) string {
+	if unit == "H" {
+		return formatNumber(c.String()) + " H"
+	}
+	exp := currencyUnitExponent(unit)
+	mag := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+	res := new(big.Rat).SetFrac(c.Big(), mag).FloatString(exp)
+	if strings.Contains(res, ".") {
+		res = strings.TrimRight(strings.TrimRight(res, "0"), ".")
+	}
+	return formatNumber(res) + " " + unit
+}
+
+// hastingsUnits converts a decimal number of hastings, as returned by the API
+// or by parseCurrency, to a string in the unit selected by the --unit flag. If
+// the number cannot be parsed, it is displayed as a number of hastings.
+func hastingsUnits(hastings string) string {
+	i, ok := new(big.Int).SetString(hastings, 10)
+	if !ok || i.Sign() < 0 {
+		return hastings + " H"
+	}
+	return currencyUnits(types.NewCurrency(i))
+}
+
+// siafundUnits converts an amount of siafunds to a string. Siafunds are
+// indivisible, so they are always displayed as a whole number of SF.
+func siafundUnits(sf types.Currency) string {
+	return formatNumber(sf.String()) + " SF"
+}
+
+// netCurrencyUnits formats the difference between incoming and outgoing
+// siacoins, which may be negative. With the default unit, the difference is
+// displayed in SC with two decimals, so that columns of amounts line up.
+func netCurrencyUnits(incoming, outgoing types.Currency) string {
+	if currencyUnit == "" || currencyUnit == unitAuto {
+		in, _ := new(big.Rat).SetFrac(incoming.Big(), types.SiacoinPrecision.Big()).Float64()
+		out, _ := new(big.Rat).SetFrac(outgoing.Big(), types.SiacoinPrecision.Big()).Float64()
+		return formatNumber(fmt.Sprintf("%.2f", in-out)) + " SC"
+	}
+	if incoming.Cmp(outgoing) >= 0 {
+		return currencyUnits(incoming.Sub(outgoing))
+	}
+	return "-" + currencyUnits(outgoing.Sub(incoming))
 }
 
-// parseCurrency converts a siacoin amount to base units.
+// parseCurrency converts a siacoin amount to base units. The amount is a
+// non-negative decimal number, optionally in scientific notation, followed by
+// a unit, e.g. "1.5KS", "1e3SC" or "100H".
 func parseCurrency(amount string) (string, error) {
-	units := []string{"pS", "nS", "uS", "mS", "SC", "KS", "MS", "GS", "TS"}
-	for i, unit := range units {
-		if strings.HasSuffix(amount, unit) {
-			// scan into big.Rat
-			r, ok := new(big.Rat).SetString(strings.TrimSuffix(amount, unit))
-			if !ok {
-				return "", errors.New("malformed amount")
-			}
-			// convert units
-			exp := 24 + 3*(int64(i)-4)
-			mag := new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)
-			r.Mul(r, new(big.Rat).SetInt(mag))
-			// r must be an integer at this point
-			if !r.IsInt() {
-				return "", errors.New("non-integer number of hastings")
-			}
-			return r.RatString(), nil
+	amount = strings.TrimSpace(amount)
+	unit := ""
+	for _, name := range append(currencyUnitNames, "H") {
+		if strings.HasSuffix(amount, name) {
+			unit = name
+			break
 		}
 	}
-	// check for hastings separately
-	if strings.HasSuffix(amount, "H") {
-		return strings.TrimSuffix(amount, "H"), nil
+	if unit == "" {
+		return "", errors.New("amount is missing units; run 'wallet --help' for a list of units")
+	}
+	number := strings.TrimSpace(strings.TrimSuffix(amount, unit))
+	if number == "" {
+		return "", errors.New("amount is missing a number")
+	}
+	if strings.HasPrefix(number, "-") {
+		return "", errors.New("amount cannot be negative")
+	}
+	match := amountRegexp.FindStringSubmatch(number)
+	if match == nil {
+		return "", fmt.Errorf("malformed amount %q", number)
 	}
 
-	return "", errors.New("amount is missing units; run 'wallet --help' for a list of units")
+	// Check the exponent before converting the number, as a huge exponent
+	// would take a long time to apply.
+	exp := currencyUnitExponent(unit)
+	if match[1] != "" {
+		e, err := strconv.Atoi(match[1])
+		if err != nil || e > maxAmountExponent || e < -maxAmountExponent {
+			return "", fmt.Errorf("exponent of %q is out of range", number)
+		}
+		exp += e
+	}
+	r, ok := new(big.Rat).SetString(strings.SplitN(strings.ToLower(number), "e", 2)[0])
+	if !ok {
+		return "", fmt.Errorf("malformed amount %q", number)
+	}
+	if exp >= 0 {
+		r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)))
+	} else {
+		r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil)))
+	}
+	if !r.IsInt() {
+		return "", errors.New("non-integer number of hastings")
+	}
+	if r.Num().Cmp(maxCurrency) > 0 {
+		return "", errors.New("amount exceeds the largest representable amount of currency")
+	}
+	return r.Num().String(), nil
+}
+
+// parseSiafunds converts a siafund amount, optionally followed by "SF", to a
+// number of siafunds. Siafunds are indivisible, so the amount must be a whole
+// number, and siacoin units are rejected rather than converted.
+func parseSiafunds(amount string) (types.Currency, error) {
+	amount = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(amount), "SF"))
+	for _, unit := range append(currencyUnitNames, "H") {
+		if strings.HasSuffix(amount, unit) {
+			return types.Currency{}, fmt.Errorf("%v is a siacoin unit; siafund amounts are a whole number of SF", unit)
+		}
+	}
+	i, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		if _, isNumber := new(big.Rat).SetString(amount); isNumber {
+			return types.Currency{}, errors.New("siafund amounts must be whole numbers")
+		}
+		return types.Currency{}, errors.New("malformed amount")
+	}
+	if i.Sign() <= 0 {
+		return types.Currency{}, errors.New("siafund amounts must be greater than zero")
+	}
+	sf := types.NewCurrency(i)
+	if sf.Cmp(types.SiafundCount) > 0 {
+		return types.Currency{}, fmt.Errorf("there are only %v siafunds", types.SiafundCount)
+	}
+	return sf, nil
 }
 
 // yesNo returns "Yes" if b is true, and "No" if b is false.
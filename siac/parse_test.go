@@ -2,6 +2,7 @@ package main
 
 import (
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/types"
@@ -122,3 +123,135 @@ func TestCurrencyUnits(t *testing.T) {
 		}
 	}
 }
+
+// TestHastingsUnits probes the hastingsUnits function.
+func TestHastingsUnits(t *testing.T) {
+	defer func(unit string) { currencyUnit = unit }(currencyUnit)
+	currencyUnit = unitAuto
+	tests := []struct {
+		in, out string
+	}{
+		{"0", "0 H"},
+		{"1234000000000000000000000000", "1.234 KS"},
+		{"1000000000000000000000000", "1 SC"},
+		{"-1", "-1 H"},
+		{"1e24", "1e24 H"},
+	}
+	for _, test := range tests {
+		if out := hastingsUnits(test.in); out != test.out {
+			t.Errorf("hastingsUnits(%v): expected %v, got %v", test.in, test.out, out)
+		}
+	}
+	currencyUnit = "SC"
+	if out := hastingsUnits("1500000000000000000000000"); out != "1.5 SC" {
+		t.Error("hastingsUnits should use the selected unit, got", out)
+	}
+}
+
+// TestParseCurrency probes the parseCurrency function.
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"1SC", "1000000000000000000000000"},
+		{"1.5KS", "1500000000000000000000000000"},
+		{".5SC", "500000000000000000000000"},
+		{"1.SC", "1000000000000000000000000"},
+		{"+2SC", "2000000000000000000000000"},
+		{"1 SC", "1000000000000000000000000"},
+		{"1e3SC", "1000000000000000000000000000"},
+		{"1.5E-3SC", "1500000000000000000000"},
+		{"2e-24SC", "2"},
+		{"100H", "100"},
+		{"1e2H", "100"},
+		{"0SC", "0"},
+	}
+	for _, test := range tests {
+		out, err := parseCurrency(test.in)
+		if err != nil || out != test.out {
+			t.Errorf("parseCurrency(%v): expected %v, got %v (%v)", test.in, test.out, out, err)
+		}
+	}
+
+	errTests := []struct {
+		in, err string
+	}{
+		{"1", "missing units"},
+		{"SC", "missing a number"},
+		{"-1SC", "negative"},
+		{"-1H", "negative"},
+		{"1.2.3KS", "malformed"},
+		{"1.5H", "non-integer"},
+		{"1e-25SC", "non-integer"},
+		{"1/2SC", "malformed"},
+		{"0x10SC", "malformed"},
+		{"1e1000000000SC", "out of range"},
+		{"1e700SC", "exceeds"},
+		{"1ee3SC", "malformed"},
+	}
+	for _, test := range errTests {
+		_, err := parseCurrency(test.in)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("parseCurrency(%v): expected an error containing %q, got %v", test.in, test.err, err)
+		}
+	}
+}
+
+// TestParseSiafunds probes the parseSiafunds function.
+func TestParseSiafunds(t *testing.T) {
+	tests := []struct {
+		in  string
+		out uint64
+	}{
+		{"1", 1},
+		{"10SF", 10},
+		{"10 SF", 10},
+		{"10000", 10000},
+	}
+	for _, test := range tests {
+		sf, err := parseSiafunds(test.in)
+		if err != nil || sf.Cmp64(test.out) != 0 {
+			t.Errorf("parseSiafunds(%v): expected %v, got %v (%v)", test.in, test.out, sf, err)
+		}
+	}
+	for _, in := range []string{"", "SF", "1.5", "1.5SF", "10SC", "1KS", "100H", "0", "-1", "10001", "ten"} {
+		if _, err := parseSiafunds(in); err == nil {
+			t.Errorf("parseSiafunds(%v): expected an error", in)
+		}
+	}
+}
+
+// TestFixedCurrencyUnits probes the fixedCurrencyUnits function.
+func TestFixedCurrencyUnits(t *testing.T) {
+	tests := []struct {
+		in, unit, out string
+	}{
+		{"0", "SC", "0 SC"},
+		{"1", "H", "1 H"},
+		{"1000000000000000000000000", "H", "1000000000000000000000000 H"},
+		{"1000000000000000000000000", "SC", "1 SC"},
+		{"1000000000000000000000000", "mS", "1000 mS"},
+		{"1234567000000000000000000000", "SC", "1234.567 SC"},
+		{"1", "SC", "0.000000000000000000000001 SC"},
+		{"1500000000000000000000", "mS", "1.5 mS"},
+		{"1234560000000000000000000000000000000000", "TS", "1234.56 TS"},
+	}
+	for _, test := range tests {
+		i, _ := new(big.Int).SetString(test.in, 10)
+		out := fixedCurrencyUnits(types.NewCurrency(i), test.unit)
+		if out != test.out {
+			t.Errorf("fixedCurrencyUnits(%v, %v): expected %v, got %v", test.in, test.unit, test.out, out)
+		}
+	}
+
+	for _, unit := range []string{"auto", "H", "pS", "SC", "TS"} {
+		if !validCurrencyUnit(unit) {
+			t.Error("unit should be valid:", unit)
+		}
+	}
+	for _, unit := range []string{"", "sc", "hastings", "PS"} {
+		if validCurrencyUnit(unit) {
+			t.Error("unit should be invalid:", unit)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// Uploads and downloads poll the renter for the status of their transfers and
+// redraw a progress bar on a single line of the terminal, with the transfer
+// speed and the estimated time remaining. The renter reports the time at which
+// a download started, so its speed is the average since then. Uploads do not
+// report one, so their speed is measured between polls and smoothed.
+
+const (
+	// progressBarWidth is the number of characters between the brackets of a
+	// progress bar.
+	progressBarWidth = 30
+
+	// queueBarWidth is the width of the progress bars of the upload and
+	// download queues.
+	queueBarWidth = 20
+
+	// progressInterval is the interval at which transfers are polled.
+	progressInterval = time.Second
+
+	// rateSmoothing is the weight of the latest sample in the measured speed
+	// of a transfer.
+	rateSmoothing = 0.3
+)
+
+// showProgress returns true if progress should be drawn, which requires
+// stdout to be a terminal that is not used for JSON output.
+func showProgress() bool {
+	if jsonOutput {
+		return false
+	}
+	stat, err := os.Stdout.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// progressBar returns a bar of width characters, filled in proportion to
+// done out of total.
+func progressBar(done, total uint64, width int) string {
+	filled := width
+	if total > 0 && done < total {
+		filled = int(uint64(width) * done / total)
+	}
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	return "[" + bar + "]"
+}
+
+// formatETA returns the time needed to transfer the remaining bytes at rate
+// bytes per second, rounded to the second.
+func formatETA(remaining uint64, rate float64) string {
+	if remaining == 0 {
+		return "0s"
+	}
+	if rate <= 0 {
+		return "--"
+	}
+	eta := time.Duration(float64(remaining) / rate * float64(time.Second))
+	return (eta - eta%time.Second + time.Second).String()
+}
+
+// progressLine returns the progress of a transfer of total bytes, with its
+// speed in bytes per second and its estimated time remaining.
+func progressLine(done, total uint64, rate float64) string {
+	if done > total {
+		done = total
+	}
+	pct := 100.0
+	if total > 0 {
+		pct = 100 * float64(done) / float64(total)
+	}
+	return fmt.Sprintf("%s %5.1f%% of %s, %s/s, ETA %s", progressBar(done, total, progressBarWidth),
+		pct, filesizeUnits(int64(total)), filesizeUnits(int64(rate)), formatETA(total-done, rate))
+}
+
+// drawProgress replaces the current line of the terminal with line.
+func drawProgress(line string) {
+	// Clear to the end of the line, in case the previous line was longer.
+	fmt.Printf("\r%s\033[K", line)
+}
+
+// A rateMeter measures the speed of a transfer from samples of the number of
+// bytes transferred so far.
+type rateMeter struct {
+	bytes uint64
+	time  time.Time
+	rate  float64
+}
+
+// update records that bytes were transferred by now, and returns the speed
+// of the transfer in bytes per second.
+func (rm *rateMeter) update(bytes uint64, now time.Time) float64 {
+	if !rm.time.IsZero() && now.After(rm.time) && bytes >= rm.bytes {
+		sample := float64(bytes-rm.bytes) / now.Sub(rm.time).Seconds()
+		if rm.rate == 0 {
+			rm.rate = sample
+		} else {
+			rm.rate = rateSmoothing*sample + (1-rateSmoothing)*rm.rate
+		}
+	}
+	rm.bytes, rm.time = bytes, now
+	return rm.rate
+}
+
+// uploadedBytes returns the number of bytes of the file that have been
+// uploaded at full redundancy.
+func uploadedBytes(fi modules.FileInfo) uint64 {
+	if fi.UploadProgress <= 0 {
+		return 0
+	} else if fi.UploadProgress >= 100 {
+		return fi.Filesize
+	}
+	return uint64(float64(fi.Filesize) * fi.UploadProgress / 100)
+}
+
+// uploadsDone returns the bytes uploaded and the total size of the files at
+// the provided siapaths, and whether all of their uploads have completed.
+func uploadsDone(files []modules.FileInfo, siapaths map[string]bool) (done, total uint64, finished bool) {
+	var found int
+	finished = true
+	for _, fi := range files {
+		if !siapaths[fi.SiaPath] {
+			continue
+		}
+		found++
+		done += uploadedBytes(fi)
+		total += fi.Filesize
+		if fi.UploadProgress < 100 {
+			finished = false
+		}
+	}
+	return done, total, finished && found == len(siapaths)
+}
+
+// uploadprogress draws the progress of the uploads of the files at the
+// provided siapaths until they have completed.
+func uploadprogress(siapaths []string) {
+	paths := make(map[string]bool)
+	for _, path := range siapaths {
+		paths[path] = true
+	}
+	var rm rateMeter
+	for range time.Tick(progressInterval) {
+		var rf api.RenterFiles
+		if err := getAPI("/renter/files", &rf); err != nil {
+			continue // benign
+		}
+		done, total, finished := uploadsDone(rf.Files, paths)
+		rate := rm.update(done, time.Now())
+		drawProgress("Uploading... " + progressLine(done, total, rate))
+		if finished {
+			fmt.Println()
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestProgressBar checks that progress bars are filled in proportion to the
+// progress of a transfer.
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		done, total uint64
+		bar         string
+	}{
+		{0, 100, "[>         ]"},
+		{55, 100, "[=====>    ]"},
+		{100, 100, "[==========]"},
+		{150, 100, "[==========]"},
+		{0, 0, "[==========]"},
+	}
+	for _, test := range tests {
+		if bar := progressBar(test.done, test.total, 10); bar != test.bar {
+			t.Errorf("progressBar(%v, %v): expected %q, got %q", test.done, test.total, test.bar, bar)
+		}
+	}
+}
+
+// TestFormatETA checks the estimated time remaining of transfers.
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		remaining uint64
+		rate      float64
+		eta       string
+	}{
+		{0, 0, "0s"},
+		{100, 0, "--"},
+		{1000, 10, "1m41s"},
+		{1e9, 1e6, "16m41s"},
+	}
+	for _, test := range tests {
+		if eta := formatETA(test.remaining, test.rate); eta != test.eta {
+			t.Errorf("formatETA(%v, %v): expected %v, got %v", test.remaining, test.rate, test.eta, eta)
+		}
+	}
+}
+
+// TestRateMeter checks that the speed of a transfer is measured between
+// samples and smoothed.
+func TestRateMeter(t *testing.T) {
+	var rm rateMeter
+	start := time.Unix(1000, 0)
+	if rate := rm.update(0, start); rate != 0 {
+		t.Fatal("expected no speed after one sample, got", rate)
+	}
+	if rate := rm.update(1000, start.Add(time.Second)); rate != 1000 {
+		t.Fatal("expected the speed of the first interval, got", rate)
+	}
+	if rate := rm.update(1000, start.Add(2*time.Second)); rate != 700 {
+		t.Fatal("expected the speed to be smoothed, got", rate)
+	}
+}
+
+// TestUploadsDone checks that the progress of several uploads is combined,
+// and that they are only finished once all of them are fully uploaded.
+func TestUploadsDone(t *testing.T) {
+	files := []modules.FileInfo{
+		{SiaPath: "a", Filesize: 100, UploadProgress: 100},
+		{SiaPath: "b", Filesize: 300, UploadProgress: 50},
+		{SiaPath: "c", Filesize: 1000, UploadProgress: 0},
+	}
+	done, total, finished := uploadsDone(files, map[string]bool{"a": true, "b": true})
+	if done != 250 || total != 400 || finished {
+		t.Fatal("wrong progress:", done, total, finished)
+	}
+	files[1].UploadProgress = 120
+	if done, _, finished = uploadsDone(files, map[string]bool{"a": true, "b": true}); done != 400 || !finished {
+		t.Fatal("uploads should be finished:", done, finished)
+	}
+	if _, _, finished = uploadsDone(files, map[string]bool{"a": true, "d": true}); finished {
+		t.Fatal("uploads of missing files should not be finished")
+	}
+}
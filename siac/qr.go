@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Addresses are printed as QR codes so that they can be scanned by mobile
+// wallets instead of being typed by hand. The code is drawn with Unicode half
+// blocks, two rows of modules per line, in black on white ANSI colors so that
+// it scans on dark and light terminals alike.
+
+const (
+	// qrColors sets black text on a white background.
+	qrColors = "\x1b[30;47m"
+
+	// qrReset restores the terminal's colors.
+	qrReset = "\x1b[0m"
+)
+
+// qrString renders content as a QR code for the terminal.
+func qrString(content string) (string, error) {
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	return qrBitmapString(qr.Bitmap()), nil
+}
+
+// qrBitmapString draws a QR code bitmap, in which true is a dark module, with
+// half blocks.
+func qrBitmapString(bits [][]bool) string {
+	var b strings.Builder
+	for y := 0; y < len(bits); y += 2 {
+		b.WriteString(qrColors)
+		for x := range bits[y] {
+			top := bits[y][x]
+			bottom := y+1 < len(bits) && bits[y+1][x]
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(qrReset + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestQRBitmapString checks that two rows of modules are drawn per line.
+func TestQRBitmapString(t *testing.T) {
+	bits := [][]bool{
+		{true, true, false, false},
+		{true, false, true, false},
+		{false, true, true, false},
+	}
+	exp := qrColors + "█▀▄ " + qrReset + "\n" + qrColors + " ▀▀ " + qrReset + "\n"
+	if s := qrBitmapString(bits); s != exp {
+		t.Fatalf("expected %q, got %q", exp, s)
+	}
+}
+
+// TestQRString checks that an address renders as a square QR code.
+func TestQRString(t *testing.T) {
+	s, err := qrString(strings.Repeat("ab", 38))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	width := len([]rune(strings.TrimSuffix(strings.TrimPrefix(lines[0], qrColors), qrReset)))
+	if exp := (width + 1) / 2; len(lines) != exp {
+		t.Fatalf("expected %v lines for a code %v modules wide, got %v", exp, width, len(lines))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// In quiet mode, enabled by the --quiet flag, the formatted output of a
+// command is discarded and only its primary value is printed, such as the
+// address created by `siac wallet address` or the ID of the transaction sent
+// by `siac wallet send`, so that it can be captured by a shell without
+// parsing. Commands without a primary value print nothing. Errors, prompts
+// and confirmations are written to stderr.
+
+var (
+	// quietOutput is set by the --quiet flag.
+	quietOutput bool
+
+	// quietStdout is the real stdout while the formatted output is
+	// discarded.
+	quietStdout *os.File
+)
+
+// startQuietOutput discards the formatted output of the command if quiet mode
+// is enabled.
+func startQuietOutput() {
+	if !quietOutput {
+		return
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		die("Could not open", os.DevNull+":", err)
+	}
+	quietStdout, os.Stdout = os.Stdout, devNull
+}
+
+// finishQuietOutput restores stdout if quiet mode is enabled.
+func finishQuietOutput() {
+	if quietStdout == nil {
+		return
+	}
+	os.Stdout.Close()
+	os.Stdout = quietStdout
+	quietStdout = nil
+}
+
+// printQuiet prints the primary value of a command in quiet mode.
+func printQuiet(value interface{}) {
+	if quietStdout == nil {
+		return
+	}
+	fmt.Fprintln(quietStdout, value)
+}
+
+// printQuietTransaction prints the ID of the last transaction of a set sent by
+// the wallet in quiet mode. The earlier transactions of the set, if any,
+// create the outputs that the last one spends.
+func printQuietTransaction(txids []types.TransactionID) {
+	if len(txids) > 0 {
+		printQuiet(txids[len(txids)-1])
+	}
+}
+
+// promptOutput returns the file that prompts are written to. In JSON and
+// quiet mode, stdout only carries the output of the command.
+func promptOutput() *os.File {
+	if jsonOutput || quietOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
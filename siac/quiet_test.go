@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestQuietOutput checks that quiet mode discards the formatted output of a
+// command and prints only its primary value.
+func TestQuietOutput(t *testing.T) {
+	defer func(stdout *os.File) {
+		os.Stdout = stdout
+		quietOutput = false
+		quietStdout = nil
+	}(os.Stdout)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	quietOutput = true
+	startQuietOutput()
+	if promptOutput() != os.Stderr {
+		t.Error("prompts should be written to stderr in quiet mode")
+	}
+	fmt.Println("Created new address:", "abc")
+	printQuiet("abc")
+	printQuietTransaction(nil)
+	printQuietTransaction([]types.TransactionID{{1}, {2}})
+	finishQuietOutput()
+	w.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "abc\n" + types.TransactionID{2}.String() + "\n"
+	if string(b) != exp {
+		t.Fatalf("expected %q, got %q", exp, b)
+	}
+	if os.Stdout != w {
+		t.Fatal("stdout was not restored")
+	}
+}
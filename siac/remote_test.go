@@ -0,0 +1,230 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestAPIURL probes apiURL.
+func TestAPIURL(t *testing.T) {
+	defer func(a string) { addr = a }(addr)
+	tests := []struct {
+		addr, url string
+	}{
+		{":9980", "http://localhost:9980/wallet"},
+		{"10.0.0.2:9980", "http://10.0.0.2:9980/wallet"},
+		{"http://:9980", "http://localhost:9980/wallet"},
+		{"https://vps.example.com:9980/", "https://vps.example.com:9980/wallet"},
+		{"unix:///run/siad.sock", "http://siad/wallet"},
+	}
+	for _, test := range tests {
+		addr = test.addr
+		url, err := apiURL("/wallet")
+		if err != nil || url != test.url {
+			t.Errorf("%v: expected %v, got %v (%v)", test.addr, test.url, url, err)
+		}
+	}
+	addr = "ftp://localhost:9980"
+	if _, err := apiURL("/wallet"); err != errBadScheme {
+		t.Error("expected errBadScheme, got", err)
+	}
+}
+
+// TestRemoteTLS checks that API calls to an https:// siad verify its
+// certificate, and send the API password.
+func TestRemoteTLS(t *testing.T) {
+	defer func(a, p string, tr http.RoundTripper) {
+		addr, apiPassword = a, p
+		caCertPath, certFingerprint = "", ""
+		http.DefaultTransport = tr
+	}(addr, apiPassword, http.DefaultTransport)
+	http.DefaultTransport = http.DefaultTransport.(*http.Transport).Clone()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, password, _ := req.BasicAuth(); password != "foo" {
+			http.Error(w, `{"message": "API authentication failed."}`, http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"height": 7}`))
+	}))
+	defer srv.Close()
+	addr = "https://" + srv.Listener.Addr().String()
+	apiPassword = "foo"
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	// Without a CA or fingerprint, the self-signed certificate is rejected.
+	var cg struct{ Height int }
+	if err := getAPI("/consensus", &cg); err == nil || !strings.Contains(err.Error(), "could not verify") {
+		t.Fatal("expected a certificate verification error, got", err)
+	}
+
+	// A wrong fingerprint is rejected.
+	certFingerprint = strings.Repeat("00", sha256.Size)
+	if err := configureTLS(); err != nil {
+		t.Fatal(err)
+	}
+	if err := getAPI("/consensus", &cg); !errors.Is(err, errCertFingerprint) {
+		t.Fatal("expected errCertFingerprint, got", err)
+	}
+
+	// The pinned certificate is accepted.
+	certFingerprint = fingerprint
+	if err := configureTLS(); err != nil {
+		t.Fatal(err)
+	}
+	if err := getAPI("/consensus", &cg); err != nil || cg.Height != 7 {
+		t.Fatal("call with a pinned certificate failed:", err)
+	}
+
+	// The certificate is accepted when signed by a CA in the CA file.
+	dir := build.TempDir("siac", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	certFingerprint, caCertPath = "", caFile
+	if err := configureTLS(); err != nil {
+		t.Fatal(err)
+	}
+	cg.Height = 0
+	if err := getAPI("/consensus", &cg); err != nil || cg.Height != 7 {
+		t.Fatal("call with a CA file failed:", err)
+	}
+
+	// TLS options require an https:// address.
+	addr = srv.Listener.Addr().String()
+	if err := configureTLS(); err != errTLSOptionsHTTP {
+		t.Fatal("expected errTLSOptionsHTTP, got", err)
+	}
+}
+
+// TestRemoteSocket checks that API calls to a unix:// address are made over
+// the Unix socket.
+func TestRemoteSocket(t *testing.T) {
+	defer func(a string, tr http.RoundTripper) {
+		addr = a
+		http.DefaultTransport = tr
+	}(addr, http.DefaultTransport)
+	http.DefaultTransport = http.DefaultTransport.(*http.Transport).Clone()
+
+	dir := build.TempDir("siac", t.Name())
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "siad.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"height": 7}`))
+	}))
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+
+	addr = "unix://" + path
+	configureSocket()
+	var cg struct{ Height int }
+	if err := getAPI("/consensus", &cg); err != nil || cg.Height != 7 {
+		t.Fatal("call over the socket failed:", err)
+	}
+}
+
+// TestAPIErrorCode checks that siac reads the code of API errors.
+func TestAPIErrorCode(t *testing.T) {
+	defer func(a string) { addr = a }(addr)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		api.WriteError(w, api.Error{Code: api.ErrCodeWalletLocked, Message: "error when calling /wallet/siacoins: " + modules.ErrLockedWallet.Error()}, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	addr = srv.Listener.Addr().String()
+
+	err := post("/wallet/siacoins", "")
+	if code := apiErrorCode([]interface{}{"Could not send siacoins:", err}); code != api.ErrCodeWalletLocked {
+		t.Fatal("expected the wallet_locked code, got", code, err)
+	}
+	if errorHints[api.ErrCodeWalletLocked] == "" {
+		t.Fatal("expected a hint for a locked wallet")
+	}
+	if code := apiErrorCode([]interface{}{errors.New("malformed amount")}); code != "" {
+		t.Fatal("expected no code, got", code)
+	}
+}
+
+// TestExitCode checks that failed API calls are classified by the exit code
+// that siac reports them with.
+func TestExitCode(t *testing.T) {
+	defer func(a, p string) { addr, apiPassword = a, p }(addr, apiPassword)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/wallet":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{}"))
+		case "/wallet/unlock":
+			http.Error(w, "", http.StatusUnauthorized)
+		case "/wallet/sweep/seed":
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		case "/wallet/siacoins":
+			api.WriteError(w, api.Error{Message: "insufficient balance"}, http.StatusBadRequest)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	addr = srv.Listener.Addr().String()
+	apiPassword = "foo"
+
+	var obj struct{}
+	tests := []struct {
+		call string
+		code int
+	}{
+		{"/wallet", 0},
+		{"/wallet/unlock", exitCodeAuth},
+		{"/wallet/sweep/seed", exitCodeAPI},
+		{"/wallet/siacoins", exitCodeAPI},
+		{"/foo", exitCodeAPI},
+	}
+	for _, test := range tests {
+		err := getAPI(test.call, &obj)
+		if test.code == 0 {
+			if err != nil {
+				t.Errorf("%v: unexpected error: %v", test.call, err)
+			}
+			continue
+		}
+		if code := exitCode([]interface{}{"Could not call siad:", err}); code != test.code {
+			t.Errorf("%v: expected exit code %v, got %v (%v)", test.call, test.code, code, err)
+		}
+	}
+
+	// Once siad is gone, calls fail to connect.
+	srv.Close()
+	err := getAPI("/wallet", &obj)
+	if code := exitCode([]interface{}{err}); code != exitCodeUnavailable {
+		t.Errorf("expected exit code %v, got %v (%v)", exitCodeUnavailable, code, err)
+	}
+	if code := exitCode([]interface{}{"Could not parse amount:", errors.New("malformed amount")}); code != exitCodeGeneral {
+		t.Errorf("expected exit code %v, got %v", exitCodeGeneral, code)
+	}
+}
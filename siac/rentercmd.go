@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"text/tabwriter"
 	"time"
 
@@ -16,10 +18,11 @@ import (
 
 var (
 	renterCmd = &cobra.Command{
-		Use:   "renter",
-		Short: "Perform renter actions",
-		Long:  "Upload, download, rename, delete, load, or share files.",
-		Run:   wrap(rentercmd),
+		Use:     "renter",
+		Aliases: []string{"r"},
+		Short:   "Perform renter actions",
+		Long:    "Upload, download, rename, delete, load, or share files.",
+		Run:     wrap(rentercmd),
 	}
 
 	renterUploadsCmd = &cobra.Command{
@@ -67,6 +70,34 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 		Run: wrap(rentersetallowancecmd),
 	}
 
+	renterBandwidthCmd = &cobra.Command{
+		Use:   "bandwidth",
+		Short: "View the data exchanged with each host",
+		Long: `View the data that the Renter has sent to and received from each host, and
+how much of the data paid for was transferred successfully. A host with an
+efficiency below 100% was paid for bandwidth that the Renter did not receive.`,
+		Run: wrap(renterbandwidthcmd),
+	}
+
+	renterScheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "View the upload schedule",
+		Long:  "View the hours during which the Renter uploads and repairs files.",
+		Run:   wrap(renterschedulecmd),
+	}
+
+	renterScheduleSetCmd = &cobra.Command{
+		Use:   "set [start] [end]",
+		Short: "Set the upload schedule",
+		Long: `Restrict uploads and repairs to the hours between start and end, in the local
+time of the machine running siad. Both are hours between 0 and 23, and the
+window may wrap past midnight: "set 22 6" uploads overnight. Setting start
+equal to end allows uploads at any time.
+
+Uploads that are queued outside of the window wait until it opens.`,
+		Run: wrap(renterschedulesetcmd),
+	}
+
 	renterContractsCmd = &cobra.Command{
 		Use:   "contracts",
 		Short: "View the Renter's contracts",
@@ -90,10 +121,12 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 	}
 
 	renterFilesDownloadCmd = &cobra.Command{
-		Use:   "download [path] [destination]",
-		Short: "Download a file",
-		Long:  "Download a previously-uploaded file to a specified destination.",
-		Run:   wrap(renterfilesdownloadcmd),
+		Use:     "download [path] [destination]",
+		Aliases: []string{"dl"},
+		Short:   "Download a file",
+		Long: `Download a previously-uploaded file to a specified destination. When stdout is
+a terminal, siac shows the progress of the download.`,
+		Run: wrap(renterfilesdownloadcmd),
 	}
 
 	renterFilesListCmd = &cobra.Command{
@@ -104,6 +137,28 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 		Run:     wrap(renterfileslistcmd),
 	}
 
+	renterFilesRecoverCmd = &cobra.Command{
+		Use:   "recover",
+		Short: "Recover the file list from the renter's hosts",
+		Long: `Rebuild the list of files from the copies of the file metadata that the renter
+stores on its hosts. Use this after the renter's local metadata was lost while
+its contracts were kept. Files that the renter already knows are skipped.`,
+		Run: wrap(renterfilesrecovercmd),
+	}
+
+	renterRecoverCmd = &cobra.Command{
+		Use:   "recover",
+		Short: "Reattach old contracts by their IDs",
+		Long: `Reattach contracts that the renter no longer uses, such as the contracts of a
+cancelled allowance, given their IDs with --contracts. The IDs can be found on
+an explorer. The latest revision of each contract is fetched from its host, so
+that the data stored under the contract can be downloaded again. The renter
+must still have the secret keys of the contracts, and the contracts must not
+have expired. Recovered contracts are only used for downloads.`,
+		Example: "siac renter recover --contracts [id1],[id2]",
+		Run:     wrap(renterrecovercmd),
+	}
+
 	renterFilesRenameCmd = &cobra.Command{
 		Use:     "rename [path] [newpath]",
 		Aliases: []string{"mv"},
@@ -113,10 +168,42 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 	}
 
 	renterFilesUploadCmd = &cobra.Command{
-		Use:   "upload [source] [path]",
-		Short: "Upload a file",
-		Long:  "Upload a file to [path] on the Sia network.",
-		Run:   wrap(renterfilesuploadcmd),
+		Use:     "upload [source] [path]",
+		Aliases: []string{"up"},
+		Short:   "Upload a file",
+		Long: `Upload a file, or the files in a folder, to [path] on the Sia network. When
+stdout is a terminal, siac shows the progress of the upload until the files are
+fully uploaded; the upload continues in siad if siac is interrupted.`,
+		Run: wrap(renterfilesuploadcmd),
+	}
+
+	renterUpdateCmd = &cobra.Command{
+		Use:   "update [source] [path]",
+		Short: "Re-upload the changed parts of a file",
+		Long: `Replace the contents of the file at [path] on the Sia network with [source],
+re-uploading only the chunks that changed since the file was uploaded. The
+file keeps its path, erasure coding and hosts.`,
+		Run: wrap(renterupdatecmd),
+	}
+
+	renterPrefetchCmd = &cobra.Command{
+		Use:   "prefetch [path...]",
+		Short: "Cache files locally ahead of time",
+		Long: `Download the files at the given paths into a local cache on the machine running
+siad, so that later downloads of them are served without contacting any hosts,
+for example before going offline. The --chunks flag restricts prefetching to
+some chunks of the files, such as "0-9".
+
+Without arguments, shows the progress of prefetching and the contents of the
+cache.`,
+		Run: renterprefetchcmd,
+	}
+
+	renterPrefetchEvictCmd = &cobra.Command{
+		Use:   "evict [path]",
+		Short: "Remove a file from the local cache",
+		Long:  "Remove the prefetched chunks of a file from the local cache.",
+		Run:   wrap(renterprefetchevictcmd),
 	}
 
 	renterPricesCmd = &cobra.Command{
@@ -190,7 +277,7 @@ func renteruploadscmd() {
 	}
 	fmt.Println("Uploading", len(filteredFiles), "files:")
 	for _, file := range filteredFiles {
-		fmt.Printf("%13s  %s (uploading, %0.2f%%)\n", filesizeUnits(int64(file.Filesize)), file.SiaPath, file.UploadProgress)
+		fmt.Printf("%13s  %s %6.2f%%  %s\n", filesizeUnits(int64(file.Filesize)), progressBar(uploadedBytes(file), file.Filesize, queueBarWidth), file.UploadProgress, file.SiaPath)
 	}
 }
 
@@ -215,7 +302,7 @@ func renterdownloadscmd() {
 	} else {
 		fmt.Println("Downloading", len(downloading), "files:")
 		for _, file := range downloading {
-			fmt.Printf("%s: %5.1f%% %s -> %s\n", file.StartTime.Format("Jan 02 03:04 PM"), 100*float64(file.Received)/float64(file.Filesize), file.SiaPath, file.Destination)
+			fmt.Printf("%s: %s %5.1f%% %s -> %s\n", file.StartTime.Format("Jan 02 03:04 PM"), progressBar(file.Received, file.Filesize, queueBarWidth), 100*float64(file.Received)/float64(file.Filesize), file.SiaPath, file.Destination)
 		}
 	}
 	if !renterShowHistory {
@@ -268,11 +355,11 @@ func renterallowancecancelcmd() {
 func rentersetallowancecmd(amount, period string) {
 	hastings, err := parseCurrency(amount)
 	if err != nil {
-		die("Could not parse amount:", err)
+		dieUsage("Could not parse amount:", err)
 	}
 	blocks, err := parsePeriod(period)
 	if err != nil {
-		die("Could not parse period")
+		dieUsage("Could not parse period")
 	}
 	err = post("/renter", fmt.Sprintf("funds=%s&period=%s", hastings, blocks))
 	if err != nil {
@@ -281,6 +368,46 @@ func rentersetallowancecmd(amount, period string) {
 	fmt.Println("Allowance updated.")
 }
 
+// renterschedulecmd displays the current upload schedule.
+func renterschedulecmd() {
+	var rg api.RenterGET
+	err := getAPI("/renter", &rg)
+	if err != nil {
+		die("Could not get schedule:", err)
+	}
+	schedule := rg.Settings.Schedule
+
+	window := "any time"
+	if schedule.WindowStart != schedule.WindowEnd {
+		window = fmt.Sprintf("%02d:00 - %02d:00", schedule.WindowStart, schedule.WindowEnd)
+	}
+	traffic := "none"
+	if schedule.MaxSystemTraffic != 0 {
+		traffic = fmt.Sprintf("%v/s", filesizeUnits(int64(schedule.MaxSystemTraffic)))
+	}
+	fmt.Printf(`Schedule:
+	Upload Window:      %v
+	Max System Traffic: %v
+`, window, traffic)
+}
+
+// renterschedulesetcmd sets the upload schedule.
+func renterschedulesetcmd(start, end string) {
+	startHour, err := strconv.Atoi(start)
+	if err != nil {
+		dieUsage("Could not parse start:", err)
+	}
+	endHour, err := strconv.Atoi(end)
+	if err != nil {
+		dieUsage("Could not parse end:", err)
+	}
+	err = post("/renter", fmt.Sprintf("uploadwindowstart=%d&uploadwindowend=%d&maxsystemtraffic=%d", startHour, endHour, renterMaxSystemTraffic))
+	if err != nil {
+		die("Could not set schedule:", err)
+	}
+	fmt.Println("Schedule updated.")
+}
+
 // byValue sorts contracts by their value in siacoins, high to low. If two
 // contracts have the same value, they are sorted by their host's address.
 type byValue []api.RenterContract
@@ -324,6 +451,33 @@ func rentercontractscmd() {
 	w.Flush()
 }
 
+// renterbandwidthcmd is the handler for the command `siac renter bandwidth`.
+// It lists the data exchanged with each host.
+func renterbandwidthcmd() {
+	var rb api.RenterBandwidth
+	err := getAPI("/renter/bandwidth", &rb)
+	if err != nil {
+		die("Could not get bandwidth:", err)
+	}
+	if len(rb.Hosts) == 0 {
+		fmt.Println("No data has been exchanged with hosts.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Host\tSent\tReceived\tUpload Spending\tUpload Efficiency\tDownload Spending\tDownload Efficiency")
+	for _, h := range rb.Hosts {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%8s\t%.1f%%\t%8s\t%.1f%%\n",
+			h.NetAddress,
+			filesizeUnits(int64(h.UploadBytes)),
+			filesizeUnits(int64(h.DownloadBytes)),
+			currencyUnits(h.UploadSpending),
+			h.UploadEfficiency*100,
+			currencyUnits(h.DownloadSpending),
+			h.DownloadEfficiency*100)
+	}
+	w.Flush()
+}
+
 // rentercontractsviewcmd is the handler for the command `siac renter contracts <id>`.
 // It lists details of a specific contract.
 func rentercontractsviewcmd(cid string) {
@@ -388,7 +542,9 @@ func renterfilesdeletecmd(path string) {
 func renterfilesdownloadcmd(path, destination string) {
 	destination = abs(destination)
 	done := make(chan struct{})
-	go downloadprogress(done, path)
+	if showProgress() {
+		go downloadprogress(done, path)
+	}
 
 	err := get("/renter/download/" + path + "?destination=" + destination)
 	close(done)
@@ -405,7 +561,7 @@ func downloadprogress(done chan struct{}, siapath string) {
 		case <-done:
 			return
 
-		case <-time.Tick(time.Second):
+		case <-time.Tick(progressInterval):
 			// get download progress of file
 			var queue api.RenterDownloadQueue
 			err := getAPI("/renter/downloads", &queue)
@@ -421,14 +577,10 @@ func downloadprogress(done chan struct{}, siapath string) {
 			if d.Filesize == 0 {
 				continue // file hasn't appeared in queue yet
 			}
-			pct := 100 * float64(d.Received) / float64(d.Filesize)
-			elapsed := time.Since(d.StartTime)
-			elapsed -= elapsed % time.Second // round to nearest second
-			mbps := (float64(d.Received*8) / 1e6) / time.Since(d.StartTime).Seconds()
-			fmt.Printf("\rDownloading... %5.1f%% of %v, %v elapsed, %.2f Mbps    ", pct, filesizeUnits(int64(d.Filesize)), elapsed, mbps)
+			rate := float64(d.Received) / time.Since(d.StartTime).Seconds()
+			drawProgress("Downloading... " + progressLine(d.Received, d.Filesize, rate))
 		}
 	}
-
 }
 
 // bySiaPath implements sort.Interface for [] modules.FileInfo based on the
@@ -463,7 +615,9 @@ func renterfileslistcmd() {
 			availableStr := yesNo(file.Available)
 			renewingStr := yesNo(file.Renewing)
 			redundancyStr := fmt.Sprintf("%.2f", file.Redundancy)
-			if file.Redundancy == -1 {
+			if file.Inline {
+				redundancyStr = "inline"
+			} else if file.Redundancy == -1 {
 				redundancyStr = "-"
 			}
 			uploadProgressStr := fmt.Sprintf("%.2f%%", file.UploadProgress)
@@ -491,6 +645,46 @@ func renterfilesrenamecmd(path, newpath string) {
 	fmt.Printf("Renamed %s to %s\n", path, newpath)
 }
 
+// renterfilesrecovercmd is the handler for the command `siac renter
+// recover`. It rebuilds the file list from the metadata stored on the hosts.
+func renterfilesrecovercmd() {
+	var rl api.RenterLoad
+	err := postResp("/renter/recoverfiles", "", &rl)
+	if err != nil {
+		die("Could not recover files:", err)
+	}
+	if len(rl.FilesAdded) == 0 {
+		fmt.Println("No new files were recovered.")
+		return
+	}
+	fmt.Printf("Recovered %v files:\n", len(rl.FilesAdded))
+	for _, name := range rl.FilesAdded {
+		fmt.Println("  " + name)
+	}
+}
+
+// renterrecovercmd reattaches old contracts by their IDs.
+func renterrecovercmd() {
+	if renterRecoverContracts == "" {
+		dieUsage("No contract IDs were provided; use --contracts")
+	}
+	var rcrp api.RenterContractsRecoverPOST
+	err := postResp("/renter/contracts/recover", "contracts="+url.QueryEscape(renterRecoverContracts), &rcrp)
+	if err != nil {
+		die("Could not recover contracts:", err)
+	}
+	var recovered int
+	for _, rec := range rcrp.Contracts {
+		if rec.Recovered {
+			recovered++
+			fmt.Printf("  %v: recovered from %v at revision %v\n", rec.ID, rec.NetAddress, rec.RevisionNumber)
+		} else {
+			fmt.Printf("  %v: %v\n", rec.ID, rec.Error)
+		}
+	}
+	fmt.Printf("Recovered %v of %v contracts.\n", recovered, len(rcrp.Contracts))
+}
+
 // renterfilesuploadcmd is the handler for the command `siac renter upload
 // [source] [path]`. Uploads the [source] file to [path] on the Sia network.
 // If [source] is a directory, all files inside it will be uploaded and named
@@ -520,6 +714,7 @@ func renterfilesuploadcmd(source, path string) {
 		} else if len(files) == 0 {
 			die("Nothing to upload.")
 		}
+		var siapaths []string
 		for _, file := range files {
 			fpath, _ := filepath.Rel(source, file)
 			fpath = filepath.Join(path, fpath)
@@ -528,7 +723,9 @@ func renterfilesuploadcmd(source, path string) {
 			if err != nil {
 				die("Could not upload file:", err)
 			}
+			siapaths = append(siapaths, fpath)
 		}
+		waitForUploads(siapaths)
 		fmt.Printf("Uploaded %d files into '%s'.\n", len(files), path)
 	} else {
 		// single file
@@ -536,10 +733,82 @@ func renterfilesuploadcmd(source, path string) {
 		if err != nil {
 			die("Could not upload file:", err)
 		}
+		waitForUploads([]string{path})
 		fmt.Printf("Uploaded '%s' as %s.\n", abs(source), path)
 	}
 }
 
+// waitForUploads draws the progress of the uploads of the files at siapaths
+// until they have completed, unless --background was given or stdout is not a
+// terminal.
+func waitForUploads(siapaths []string) {
+	if renterUploadBackground || !showProgress() {
+		return
+	}
+	fmt.Println("Uploads continue in the background if siac is interrupted.")
+	uploadprogress(siapaths)
+}
+
+// renterupdatecmd is the handler for the command `siac renter update [source]
+// [path]`. Replaces the contents of an uploaded file.
+func renterupdatecmd(source, path string) {
+	var update modules.RenterFileUpdate
+	err := postResp("/renter/update/"+path, "source="+abs(source), &update)
+	if err != nil {
+		die("Could not update file:", err)
+	}
+	fmt.Printf("Updated %s from '%s': re-uploading %d of %d chunks.\n", path, abs(source), update.ChangedChunks, update.Chunks)
+}
+
+// renterprefetchcmd is the handler for the command `siac renter prefetch`.
+// It prefetches files into the local cache, or shows the progress of
+// prefetching.
+func renterprefetchcmd(cmd *cobra.Command, paths []string) {
+	for _, path := range paths {
+		err := post("/renter/prefetch/"+path, "chunks="+url.QueryEscape(renterPrefetchChunks))
+		if err != nil {
+			die("Could not prefetch "+path+":", err)
+		}
+		fmt.Printf("Prefetching %s.\n", path)
+	}
+	if len(paths) != 0 {
+		return
+	}
+
+	var rpg api.RenterPrefetchGET
+	err := getAPI("/renter/prefetch", &rpg)
+	if err != nil {
+		die("Could not get prefetch status:", err)
+	}
+	if len(rpg.Files) == 0 {
+		fmt.Println("No files are cached.")
+		return
+	}
+	fmt.Printf("Cached: %v in %v files\n", filesizeUnits(int64(rpg.CachedBytes)), len(rpg.Files))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Path\tCached Chunks\tSize\tStatus")
+	for _, f := range rpg.Files {
+		status := "cached"
+		if f.Error != "" {
+			status = "failed: " + f.Error
+		} else if f.Active {
+			status = "prefetching"
+		}
+		fmt.Fprintf(w, "%v\t%v / %v\t%v\t%v\n", f.SiaPath, f.CachedChunks, f.Chunks, filesizeUnits(int64(f.CachedBytes)), status)
+	}
+	w.Flush()
+}
+
+// renterprefetchevictcmd is the handler for the command
+// `siac renter prefetch evict [path]`. It removes a file from the local cache.
+func renterprefetchevictcmd(path string) {
+	err := post("/renter/prefetch/"+path, "evict=true")
+	if err != nil {
+		die("Could not evict file:", err)
+	}
+	fmt.Printf("Removed %s from the cache.\n", path)
+}
+
 // renterpricescmd is the handler for the command `siac renter prices`, which
 // displays the prices of various storage operations.
 func renterpricescmd() {
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// 'siac run [file]' runs the siac commands listed in a file, one per line, in
+// a single process, so that a sequence such as the setup of a new host can be
+// replayed without typing each command. The API password is only asked for
+// once, and connections to siad are reused. The flags given to 'run', such as
+// --addr, apply to every command of the script. Blank lines and lines starting
+// with '#' are ignored, the leading "siac" of a command is optional, and
+// arguments can be quoted as in a shell. The script stops at the first command
+// that fails, unless --continue-on-error is given.
+
+var (
+	runCmd = &cobra.Command{
+		Use:   "run [file]",
+		Short: "Run the siac commands in a file",
+		Long: `Run the siac commands listed in a file, one per line, in a single process.
+The leading "siac" of each command is optional, arguments can be quoted as in
+a shell, and blank lines and lines starting with '#' are ignored. The flags
+given to run, such as --addr, apply to every command.
+
+The script stops at the first command that fails, and siac exits with the
+status of that command. With --continue-on-error, the remaining commands are
+run, and siac exits with the status of the last command that failed.`,
+		Run: wrap(runcmd),
+		// The output settings are applied by each command of the script, so
+		// they are not applied to the script as a whole.
+		PersistentPreRun:  func(*cobra.Command, []string) {},
+		PersistentPostRun: func(*cobra.Command, []string) {},
+	}
+)
+
+var (
+	// runningScript is set while the commands of a script are run, so that
+	// exit ends the current command instead of siac.
+	runningScript bool
+
+	// errUnterminatedQuote is returned when a line of a script has an
+	// unterminated quote.
+	errUnterminatedQuote = errors.New("unterminated quote")
+)
+
+// scriptExit is the panic value used by exit while a script is run.
+type scriptExit int
+
+// A scriptCommand is a command of a script, and the line it is on.
+type scriptCommand struct {
+	line int
+	args []string
+}
+
+// splitCommandLine splits a line into arguments like a shell: arguments are
+// separated by whitespace, and single quotes, double quotes and backslashes
+// keep whitespace within an argument.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == '\'':
+			arg.WriteRune(r)
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errUnterminatedQuote
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// readScript reads the commands of a script.
+func readScript(path string) ([]scriptCommand, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cmds []scriptCommand
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		args, err := splitCommandLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if args[0] == "siac" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "run" {
+			return nil, fmt.Errorf("line %d: scripts cannot run other scripts", line)
+		}
+		cmds = append(cmds, scriptCommand{line: line, args: args})
+	}
+	return cmds, scanner.Err()
+}
+
+// A flagState is the value of a flag and whether it was set.
+type flagState struct {
+	value   string
+	changed bool
+}
+
+// visitFlags calls fn for each flag of cmd and its subcommands.
+func visitFlags(cmd *cobra.Command, fn func(*pflag.Flag)) {
+	cmd.Flags().VisitAll(fn)
+	cmd.PersistentFlags().VisitAll(fn)
+	for _, sub := range cmd.Commands() {
+		visitFlags(sub, fn)
+	}
+}
+
+// saveFlags returns the state of the flags of cmd and its subcommands.
+func saveFlags(cmd *cobra.Command) map[*pflag.Flag]flagState {
+	state := make(map[*pflag.Flag]flagState)
+	visitFlags(cmd, func(f *pflag.Flag) {
+		state[f] = flagState{f.Value.String(), f.Changed}
+	})
+	return state
+}
+
+// restoreFlags sets the flags of cmd and its subcommands to a saved state, so
+// that the flags of one command of a script do not apply to the next. Flags
+// added since the state was saved, such as --help, are reset to their
+// defaults.
+func restoreFlags(cmd *cobra.Command, state map[*pflag.Flag]flagState) {
+	visitFlags(cmd, func(f *pflag.Flag) {
+		s, ok := state[f]
+		if !ok {
+			s = flagState{f.DefValue, false}
+		}
+		f.Value.Set(s.value)
+		f.Changed = s.changed
+	})
+}
+
+// runScriptCommand runs a command of a script and returns its exit code.
+func runScriptCommand(root *cobra.Command, args []string) (code int) {
+	defer func() {
+		if e := recover(); e != nil {
+			se, ok := e.(scriptExit)
+			if !ok {
+				panic(e)
+			}
+			finishQuietOutput()
+			code = int(se)
+		}
+	}()
+	root.SetArgs(args)
+	if err := root.Execute(); err != nil {
+		return exitCodeUsage
+	}
+	return 0
+}
+
+// runcmd is the handler for the command `siac run [file]`. It runs the
+// commands of a script, and exits with the status of the command that failed.
+func runcmd(path string) {
+	if runningScript {
+		dieUsage("Scripts cannot run other scripts.")
+	}
+	cmds, err := readScript(path)
+	if err != nil {
+		dieUsage("Could not read script:", err)
+	}
+
+	state := saveFlags(rootCmd)
+	runningScript = true
+	failed, code := 0, 0
+	for _, c := range cmds {
+		restoreFlags(rootCmd, state)
+		fmt.Fprintf(os.Stderr, "+ siac %v\n", strings.Join(c.args, " "))
+		if c := runScriptCommand(rootCmd, c.args); c != 0 {
+			failed, code = failed+1, c
+			if !runContinueOnError {
+				break
+			}
+		}
+	}
+	runningScript = false
+	restoreFlags(rootCmd, state)
+
+	if failed == 0 {
+		return
+	}
+	if runContinueOnError {
+		fmt.Fprintf(os.Stderr, "%d of %d commands failed.\n", failed, len(cmds))
+	} else {
+		fmt.Fprintln(os.Stderr, "Script stopped at the first command that failed. Use --continue-on-error to run the remaining commands.")
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+
+	"github.com/spf13/cobra"
+)
+
+// TestSplitCommandLine probes splitCommandLine.
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		args []string
+	}{
+		{"wallet balance", []string{"wallet", "balance"}},
+		{"  host config  minstorageprice 100SC ", []string{"host", "config", "minstorageprice", "100SC"}},
+		{`renter upload "my file.txt" 'a b'`, []string{"renter", "upload", "my file.txt", "a b"}},
+		{`host announce my\ host`, []string{"host", "announce", "my host"}},
+		{`a "" 'it"s' "x\"y"`, []string{"a", "", `it"s`, `x"y`}},
+	}
+	for _, test := range tests {
+		args, err := splitCommandLine(test.line)
+		if err != nil || !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%q: expected %q, got %q (%v)", test.line, test.args, args, err)
+		}
+	}
+	for _, line := range []string{`a "b`, `a 'b`, `a b\`} {
+		if _, err := splitCommandLine(line); err != errUnterminatedQuote {
+			t.Errorf("%q: expected errUnterminatedQuote, got %v", line, err)
+		}
+	}
+}
+
+// TestReadScript checks that comments and the leading "siac" are skipped.
+func TestReadScript(t *testing.T) {
+	dir := build.TempDir("siac", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "setup")
+	script := "# provision a host\n\nsiac wallet unlock\nhost config minstorageprice 100SC\n"
+	if err := ioutil.WriteFile(path, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := readScript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []scriptCommand{
+		{3, []string{"wallet", "unlock"}},
+		{4, []string{"host", "config", "minstorageprice", "100SC"}},
+	}
+	if !reflect.DeepEqual(cmds, exp) {
+		t.Fatalf("expected %v, got %v", exp, cmds)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("wallet\nsiac run other\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readScript(path); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatal("expected nested scripts to be rejected, got", err)
+	}
+}
+
+// TestRunScriptCommand checks that a command that fails ends only itself, and
+// that the flags of a command do not apply to the next.
+func TestRunScriptCommand(t *testing.T) {
+	defer func() { runningScript = false }()
+	var name string
+	var ran []string
+	root := &cobra.Command{Use: "siac"}
+	root.SilenceErrors, root.SilenceUsage = true, true
+	greet := &cobra.Command{
+		Use: "greet",
+		Run: wrap(func() { ran = append(ran, name) }),
+	}
+	greet.Flags().StringVar(&name, "name", "world", "name to greet")
+	fail := &cobra.Command{
+		Use: "fail",
+		Run: wrap(func() { die("failed") }),
+	}
+	root.AddCommand(greet, fail)
+
+	state := saveFlags(root)
+	runningScript = true
+	for _, test := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"greet", "--name", "sia"}, 0},
+		{[]string{"fail"}, exitCodeGeneral},
+		{[]string{"greet"}, 0},
+		{[]string{"greet", "extra"}, exitCodeUsage},
+		{[]string{"unknown"}, exitCodeUsage},
+	} {
+		restoreFlags(root, state)
+		if code := runScriptCommand(root, test.args); code != test.code {
+			t.Errorf("%v: expected exit code %v, got %v", test.args, test.code, code)
+		}
+	}
+	if exp := []string{"sia", "world"}; !reflect.DeepEqual(ran, exp) {
+		t.Fatalf("expected greetings %v, got %v", exp, ran)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// 'wallet send -' reads siacoin sends from stdin, so that payouts generated by
+// a script can be piped to siac. Each line holds an amount and a destination
+// separated by whitespace or a comma, or a JSON object with "amount" and
+// "destination" fields; the input may also be a single JSON array of such
+// objects. Blank lines and lines starting with '#' are ignored. Every line is
+// checked before anything is sent, and the sends are then made one at a time.
+
+// A batchSend is a siacoin send read from stdin.
+type batchSend struct {
+	Line        int    `json:"-"`
+	Amount      string `json:"amount"`
+	Destination string `json:"destination"`
+
+	hastings string
+}
+
+// parseBatchSend parses the amount and destination of a send, and sets its
+// amount in hastings.
+func parseBatchSend(s *batchSend) error {
+	hastings, err := parseCurrency(s.Amount)
+	if err != nil {
+		return fmt.Errorf("could not parse amount: %v", err)
+	}
+	var dest types.UnlockHash
+	if err := dest.LoadString(s.Destination); err != nil {
+		return fmt.Errorf("could not parse destination: %v", err)
+	}
+	s.hastings = hastings
+	return nil
+}
+
+// parseBatchSends reads the sends of 'wallet send -'. An error names the line
+// that could not be parsed.
+func parseBatchSends(r io.Reader) ([]batchSend, error) {
+	input, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := strings.TrimSpace(string(input)); strings.HasPrefix(trimmed, "[") {
+		var sends []batchSend
+		if err := json.Unmarshal([]byte(trimmed), &sends); err != nil {
+			return nil, fmt.Errorf("could not parse JSON array: %v", err)
+		}
+		for i := range sends {
+			sends[i].Line = i + 1
+			if err := parseBatchSend(&sends[i]); err != nil {
+				return nil, fmt.Errorf("send %d: %v", i+1, err)
+			}
+		}
+		return sends, nil
+	}
+
+	var sends []batchSend
+	scanner := bufio.NewScanner(strings.NewReader(string(input)))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		s := batchSend{Line: line}
+		if strings.HasPrefix(text, "{") {
+			if err := json.Unmarshal([]byte(text), &s); err != nil {
+				return nil, fmt.Errorf("line %d: could not parse JSON: %v", line, err)
+			}
+		} else {
+			fields := strings.FieldsFunc(text, func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: expected an amount and a destination, got %q", line, text)
+			}
+			s.Amount, s.Destination = fields[0], fields[1]
+		}
+		if err := parseBatchSend(&s); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		sends = append(sends, s)
+	}
+	return sends, scanner.Err()
+}
+
+// walletsendbatchcmd sends the siacoins read from stdin, and reports the
+// result of each send. Stdin carries the sends, so they cannot be confirmed
+// interactively and --yes is required unless --dry-run is given.
+func walletsendbatchcmd() {
+	if !walletYes && !walletDryRun {
+		dieUsage("Sends read from stdin cannot be confirmed interactively; use --yes to send them, or --dry-run to check them.")
+	}
+	sends, err := parseBatchSends(os.Stdin)
+	if err != nil {
+		dieUsage("Could not read sends from stdin:", err)
+	}
+	if len(sends) == 0 {
+		dieUsage("No sends were read from stdin.")
+	}
+
+	var failed int
+	for _, s := range sends {
+		vals := siacoinSendValues(s.hastings, s.Destination)
+		if walletDryRun {
+			vals += "&dryrun=true"
+		}
+		var wsp api.WalletSiacoinsPOST
+		if err := postResp(walletCall("/wallet/siacoins"), vals, &wsp); err != nil {
+			failed++
+			fmt.Printf("line %d: could not send %s to %s: %v\n", s.Line, hastingsUnits(s.hastings), s.Destination, err)
+			continue
+		}
+		if walletDryRun {
+			fmt.Printf("line %d: would send %s to %s\n", s.Line, hastingsUnits(s.hastings), s.Destination)
+			continue
+		}
+		txid := ""
+		if len(wsp.TransactionIDs) > 0 {
+			txid = wsp.TransactionIDs[len(wsp.TransactionIDs)-1].String()
+		}
+		fmt.Printf("line %d: sent %s to %s in transaction %s\n", s.Line, hastingsUnits(s.hastings), s.Destination, txid)
+		printQuietTransaction(wsp.TransactionIDs)
+	}
+	if failed > 0 {
+		die(fmt.Sprintf("%d of %d sends failed.", failed, len(sends)))
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestParseBatchSends checks that sends are read from plain lines, JSON lines
+// and JSON arrays.
+func TestParseBatchSends(t *testing.T) {
+	validAddr := types.UnlockHash{1}.String()
+	input := "# payouts\n" +
+		"1KS " + validAddr + "\n" +
+		"\n" +
+		"2.5SC," + validAddr + "\n" +
+		`{"amount": "3H", "destination": "` + validAddr + `"}` + "\n"
+	sends, err := parseBatchSends(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []struct {
+		line     int
+		hastings string
+	}{
+		{2, "1000000000000000000000000000"},
+		{4, "2500000000000000000000000"},
+		{5, "3"},
+	}
+	if len(sends) != len(exp) {
+		t.Fatalf("expected %v sends, got %v", len(exp), len(sends))
+	}
+	for i, s := range sends {
+		if s.Line != exp[i].line || s.hastings != exp[i].hastings || s.Destination != validAddr {
+			t.Errorf("send %v: expected line %v and %v H, got line %v and %v H", i, exp[i].line, exp[i].hastings, s.Line, s.hastings)
+		}
+	}
+
+	sends, err = parseBatchSends(strings.NewReader(`[{"amount": "1SC", "destination": "` + validAddr + `"}]`))
+	if err != nil || len(sends) != 1 || sends[0].Line != 1 {
+		t.Fatal("could not parse JSON array:", sends, err)
+	}
+
+	bad := []string{
+		"1SC",
+		"1SC " + validAddr + " extra",
+		"1 " + validAddr,
+		"1SC 1234",
+		`{"amount": 1}`,
+		`[{"amount": "1SC", "destination": "1234"}]`,
+	}
+	for _, input := range bad {
+		if _, err := parseBatchSends(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error parsing %q", input)
+		}
+	}
+	if _, err := parseBatchSends(strings.NewReader("1SC " + validAddr + "\n1XX " + validAddr)); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Error("expected an error on line 2, got", err)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	tpoolCmd = &cobra.Command{
+		Use:     "tpool",
+		Aliases: []string{"tp"},
+		Short:   "Print the state of the transaction pool",
+		Long: `Print the number of transactions waiting in the transaction pool, their total
+size, and the distribution of the fees that they pay.`,
+		Run: wrap(tpoolcmd),
+	}
+
+	tpoolTransactionsCmd = &cobra.Command{
+		Use:   "transactions",
+		Short: "List the transactions in the transaction pool",
+		Long:  "List the transactions in the transaction pool, highest fee per byte first.",
+		Run:   wrap(tpooltransactionscmd),
+	}
+
+	tpoolTransactionCmd = &cobra.Command{
+		Use:   "transaction [id]",
+		Short: "Show a transaction in the transaction pool",
+		Long: `Show the size and fees of a transaction in the transaction pool and of its
+unconfirmed parents, and estimate how many blocks it will take to be confirmed.`,
+		Run: wrap(tpooltransactioncmd),
+	}
+)
+
+// feePerByte formats a fee per byte.
+func feePerByte(c types.Currency) string {
+	return currencyUnits(c) + "/byte"
+}
+
+// confirmationEstimate describes the number of blocks that a transaction is
+// expected to wait before it is confirmed.
+func confirmationEstimate(blocks uint64) string {
+	wait := time.Duration(blocks) * time.Duration(types.BlockFrequency) * time.Second
+	if blocks == 1 {
+		return fmt.Sprintf("next block (about %v)", wait)
+	}
+	return fmt.Sprintf("%v blocks (about %v)", blocks, wait)
+}
+
+// tpoolcmd is the handler for the command `siac tpool`.
+// Prints the size of the transaction pool and the distribution of its fees.
+func tpoolcmd() {
+	var ttg api.TpoolTransactionsGET
+	err := getAPI("/tpool/transactions", &ttg)
+	if err != nil {
+		die("Could not get transaction pool:", err)
+	}
+	var fees api.TpoolFeeGET
+	err = getAPI("/tpool/fee", &fees)
+	if err != nil {
+		die("Could not get fee estimation:", err)
+	}
+	fmt.Printf(`Transactions:     %v
+Size:             %v
+Total Fees:       %v
+Recommended Fee:  %v
+`, ttg.Count, filesizeUnits(int64(ttg.Size)), currencyUnits(ttg.TotalFees), feePerByte(fees.Maximum))
+	printQuiet(ttg.Count)
+	if ttg.Count == 0 {
+		return
+	}
+	fd := ttg.FeeDistribution
+	fmt.Printf(`
+Fee Distribution:
+  Minimum:         %v
+  Lower Quartile:  %v
+  Median:          %v
+  Upper Quartile:  %v
+  Maximum:         %v
+`, feePerByte(fd.Minimum), feePerByte(fd.LowerQuartile), feePerByte(fd.Median), feePerByte(fd.UpperQuartile), feePerByte(fd.Maximum))
+}
+
+// tpooltransactionscmd is the handler for the command `siac tpool
+// transactions`. Lists the transactions in the transaction pool.
+func tpooltransactionscmd() {
+	var ttg api.TpoolTransactionsGET
+	err := getAPI("/tpool/transactions", &ttg)
+	if err != nil {
+		die("Could not get transaction pool:", err)
+	}
+	if len(ttg.Transactions) == 0 {
+		fmt.Println("The transaction pool is empty.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Transaction ID\tSize\tFee\tFee per Byte")
+	for _, txn := range ttg.Transactions {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", txn.ID, filesizeUnits(int64(txn.Size)), currencyUnits(txn.Fee), feePerByte(txn.FeePerByte))
+	}
+	w.Flush()
+}
+
+// tpooltransactioncmd is the handler for the command `siac tpool transaction
+// [id]`. Shows a transaction in the transaction pool and its prospects of
+// being confirmed.
+func tpooltransactioncmd(id string) {
+	var ttg api.TpoolTransactionGET
+	err := getAPI("/tpool/transactions/"+id, &ttg)
+	if err != nil {
+		die("Could not get transaction:", err)
+	}
+	fmt.Printf(`Transaction ID:   %v
+Size:             %v
+Fee:              %v
+Fee per Byte:     %v
+`, ttg.ID, filesizeUnits(int64(ttg.Size)), currencyUnits(ttg.Fee), feePerByte(ttg.FeePerByte))
+	if len(ttg.Parents) > 0 {
+		fmt.Printf("\nUnconfirmed Parents:\n")
+		w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+		for _, parent := range ttg.Parents {
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", parent.ID, filesizeUnits(int64(parent.Size)), currencyUnits(parent.Fee), feePerByte(parent.FeePerByte))
+		}
+		w.Flush()
+		fmt.Printf("\nFee per Byte of the Set:  %v\n", feePerByte(ttg.SetFeePerByte))
+	}
+	fmt.Printf(`
+Recommended Fee:  %v
+Bytes Ahead:      %v
+Confirmation:     %v
+`, feePerByte(ttg.RecommendedFeePerByte), filesizeUnits(int64(ttg.BytesAhead)), confirmationEstimate(ttg.EstimatedBlocks))
+	if ttg.SetFeePerByte.Cmp(ttg.RecommendedFeePerByte) < 0 {
+		fmt.Println("\nThe transaction pays less than the recommended fee, and miners may ignore it")
+		fmt.Println("while the pool is busy. If it was sent by this wallet, 'siac wallet bumpfee'")
+		fmt.Println("can raise its fee.")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestConfirmationEstimate checks that the wait for a transaction is
+// described in blocks and time.
+func TestConfirmationEstimate(t *testing.T) {
+	block := time.Duration(types.BlockFrequency) * time.Second
+	tests := []struct {
+		blocks uint64
+		exp    string
+	}{
+		{1, fmt.Sprintf("next block (about %v)", block)},
+		{3, fmt.Sprintf("3 blocks (about %v)", 3*block)},
+	}
+	for _, test := range tests {
+		if s := confirmationEstimate(test.blocks); s != test.exp {
+			t.Errorf("confirmationEstimate(%v): expected %q, got %q", test.blocks, test.exp, s)
+		}
+	}
+}
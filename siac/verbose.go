@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// With --verbose, every request that siac makes to siad is logged to stderr
+// with its body, the status of the response and how long it took, so that a
+// terse error can be traced back to the call that caused it. Secrets such as
+// passwords and seeds are redacted from the log.
+
+var (
+	// verbose enables the logging of API calls, set by --verbose.
+	verbose bool
+
+	// verboseOutput is where API calls are logged.
+	verboseOutput io.Writer = os.Stderr
+)
+
+// verboseTransport is an http.RoundTripper that logs each API call before
+// passing it to http.DefaultTransport.
+type verboseTransport struct{}
+
+// redactedParams are the parameters whose values are not logged. A parameter
+// is redacted if its name contains any of them.
+var redactedParams = []string{"password", "seed", "key"}
+
+// redactParams returns an encoded query string or form body with the values of
+// secret parameters replaced. Bodies that are not form encoded are returned
+// unchanged.
+func redactParams(params string) string {
+	vals, err := url.ParseQuery(params)
+	if err != nil {
+		return params
+	}
+	redacted := false
+	for name := range vals {
+		for _, secret := range redactedParams {
+			if strings.Contains(strings.ToLower(name), secret) {
+				vals.Set(name, "REDACTED")
+				redacted = true
+				break
+			}
+		}
+	}
+	if !redacted {
+		return params
+	}
+	return vals.Encode()
+}
+
+// RoundTrip implements http.RoundTripper.
+func (verboseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.RawQuery = redactParams(u.RawQuery)
+	fmt.Fprintf(verboseOutput, "> %s %s\n", req.Method, u.String())
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := ioutil.ReadAll(body)
+			body.Close()
+			if len(b) > 0 {
+				fmt.Fprintf(verboseOutput, "> %s\n", redactParams(string(b)))
+			}
+		}
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(verboseOutput, "< error after %v: %v\n", elapsed, err)
+		return nil, err
+	}
+	fmt.Fprintf(verboseOutput, "< %s (%v)\n", resp.Status, elapsed)
+	return resp, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRedactParams checks that secrets are removed from logged parameters.
+func TestRedactParams(t *testing.T) {
+	tests := []struct {
+		params, exp string
+	}{
+		{"", ""},
+		{"amount=1&destination=abc", "amount=1&destination=abc"},
+		{"encryptionpassword=foo&seed=bar", "encryptionpassword=REDACTED&seed=REDACTED"},
+		{"keyfiles=a&amount=1", "amount=1&keyfiles=REDACTED"},
+		{"%zz", "%zz"},
+	}
+	for _, test := range tests {
+		if s := redactParams(test.params); s != test.exp {
+			t.Errorf("redactParams(%q): expected %q, got %q", test.params, test.exp, s)
+		}
+	}
+}
+
+// TestVerboseTransport checks that API calls are logged with their body and
+// status.
+func TestVerboseTransport(t *testing.T) {
+	defer func(a string, c *http.Client, w io.Writer) {
+		addr = a
+		http.DefaultClient = c
+		verboseOutput = w
+	}(addr, http.DefaultClient, verboseOutput)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, `{"message": "insufficient balance"}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	addr = srv.Listener.Addr().String()
+
+	var buf bytes.Buffer
+	verboseOutput = &buf
+	http.DefaultClient = &http.Client{Transport: verboseTransport{}}
+	if err := post("/wallet/unlock", "encryptionpassword=hunter2"); err == nil || !strings.Contains(err.Error(), "insufficient balance") {
+		t.Fatal("expected the API error, got", err)
+	}
+	log := buf.String()
+	for _, exp := range []string{"> POST http://" + addr + "/wallet/unlock\n", "> encryptionpassword=REDACTED\n", "< 400 Bad Request ("} {
+		if !strings.Contains(log, exp) {
+			t.Errorf("expected %q in the log:\n%v", exp, log)
+		}
+	}
+	if strings.Contains(log, "hunter2") {
+		t.Error("the password was logged:", log)
+	}
+}
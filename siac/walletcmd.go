@@ -1,21 +1,30 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
-	"math/big"
+	"io/ioutil"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/bgentry/speakeasy"
 	"github.com/spf13/cobra"
 
 	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/wallet"
 	"github.com/NebulousLabs/Sia/types"
 )
 
 var (
 	walletCmd = &cobra.Command{
-		Use:   "wallet",
-		Short: "Perform wallet actions",
+		Use:     "wallet",
+		Aliases: []string{"w"},
+		Short:   "Perform wallet actions",
 		Long: `Generate a new address, send coins to another wallet, or view info about the wallet.
 
 Units:
@@ -28,15 +37,28 @@ The smallest unit of siacoins is the hasting. One siacoin is 10^24 hastings. Oth
   KS (kilo, 10^3 SC)
   MS (mega, 10^6 SC)
   GS (giga, 10^9 SC)
-  TS (tera, 10^12 SC)`,
+  TS (tera, 10^12 SC)
+
+Amounts are written as a number followed by a unit, e.g. "1.5KS", "100H" or, in
+scientific notation, "1e3SC".`,
 		Run: wrap(walletbalancecmd),
 	}
 
 	walletAddressCmd = &cobra.Command{
-		Use:   "address",
-		Short: "Get a new wallet address",
-		Long:  "Generate a new wallet address from the wallet's primary seed.",
-		Run:   wrap(walletaddresscmd),
+		Use:     "address",
+		Aliases: []string{"addr"},
+		Short:   "Get a new wallet address",
+		Long:    "Generate a new wallet address from the wallet's primary seed.",
+		Run:     wrap(walletaddresscmd),
+	}
+
+	walletDeriveCmd = &cobra.Command{
+		Use:   "derive [index]",
+		Short: "Get the wallet address at an index",
+		Long: `Get the address at the given index of the wallet's primary seed. The same
+index always returns the same address, and the wallet tracks the address even
+if it had not generated it before.`,
+		Run: wrap(walletderivecmd),
 	}
 
 	walletAddressesCmd = &cobra.Command{
@@ -46,6 +68,34 @@ The smallest unit of siacoins is the hasting. One siacoin is 10^24 hastings. Oth
 		Run:   wrap(walletaddressescmd),
 	}
 
+	walletBackupCmd = &cobra.Command{
+		Use:   "backup [filepath]",
+		Short: "Write an encrypted backup of the wallet",
+		Long: `Write an encrypted backup of the wallet's seeds, siag keys, transaction labels
+and settings to a file. The backup is encrypted with the wallet password and can
+be restored into a fresh wallet with 'siac wallet restore'.`,
+		Run: wrap(walletbackupcmd),
+	}
+
+	walletBumpFeeCmd = &cobra.Command{
+		Use:   "bumpfee [txid]",
+		Short: "Raise the fee of an unconfirmed transaction",
+		Long: `Replace an unconfirmed transaction with one that makes the same payments but
+pays a higher miner fee. 'txid' is the ID of the last transaction created by a
+send. The fee can be specified in units with --fee, e.g. 1SC; by default the fee
+is raised by the current fee estimate.`,
+		Run: wrap(walletbumpfeecmd),
+	}
+
+	walletCancelCmd = &cobra.Command{
+		Use:   "cancel [txid]",
+		Short: "Cancel an unconfirmed transaction",
+		Long: `Replace an unconfirmed transaction with one that returns its funds to the
+wallet. The replacement pays a higher miner fee than the original, which can be
+specified in units with --fee, e.g. 1SC.`,
+		Run: wrap(walletcancelcmd),
+	}
+
 	walletChangepasswordCmd = &cobra.Command{
 		Use:   "change-password",
 		Short: "Change the wallet password",
@@ -104,6 +154,95 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletlockcmd),
 	}
 
+	walletReindexCmd = &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the wallet's balance and history",
+		Long: `Discard the wallet's outputs and transaction history and rebuild them by
+rescanning the blockchain. Keys, settings and transaction labels are kept, and
+other modules are not affected.`,
+		Run: wrap(walletreindexcmd),
+	}
+
+	walletPartialCmd = &cobra.Command{
+		Use:   "partial",
+		Short: "Sign transactions together with other signers",
+		Long: `Create, sign, merge and finalize partial transactions. A partial transaction
+is a JSON file holding an unsigned transaction and the signatures collected for
+each of its inputs, so that the cosigners of a multisig address, or a wallet and
+an offline signer, can each add their signatures with their own tools. Copies
+signed by different signers are combined with 'merge', and the transaction is
+completed with 'finalize' once every input has enough signatures.`,
+		// Run field is not set, as the partial command itself is not a valid
+		// command. A subcommand must be provided.
+	}
+
+	walletPartialCreateCmd = &cobra.Command{
+		Use:   "create [transactionfile] [partialfile]",
+		Short: "Create a partial transaction from a transaction set",
+		Long: `Read a JSON transaction set, such as one written by 'siac wallet siafunds
+prepare', and write it as a partial transaction. The last transaction of the set
+is the one to be signed. The values of the inputs known to the wallet are
+filled in so that the other signers can check what is being spent.`,
+		Run: wrap(walletpartialcreatecmd),
+	}
+
+	walletPartialFinalizeCmd = &cobra.Command{
+		Use:   "finalize [partialfile] [transactionfile]",
+		Short: "Finalize a partial transaction with enough signatures",
+		Long: `Move the signatures of a partial transaction into its transaction, and write
+the signed transaction set to a file. With --broadcast the transaction set is
+also given to the transaction pool.`,
+		Run: wrap(walletpartialfinalizecmd),
+	}
+
+	walletPartialMergeCmd = &cobra.Command{
+		Use:   "merge [outfile] [partialfile]...",
+		Short: "Combine copies of a partial transaction",
+		Long: `Combine the signatures of several copies of the same partial transaction,
+each signed by a different signer, and write the result to 'outfile'.`,
+		Example: "siac wallet partial merge merged.json alice.json bob.json",
+		Run:     walletpartialmergecmd,
+	}
+
+	walletPartialSignCmd = &cobra.Command{
+		Use:   "sign [partialfile]",
+		Short: "Sign a partial transaction with the wallet",
+		Long: `Add the wallet's signatures to a partial transaction, and update the file.
+Inputs of multisig addresses are signed with whichever of their keys the wallet
+holds.`,
+		Run: wrap(walletpartialsigncmd),
+	}
+
+	walletPartialShowCmd = &cobra.Command{
+		Use:   "show [partialfile]",
+		Short: "Show the inputs and signatures of a partial transaction",
+		Long: `Show the inputs and outputs of a partial transaction and how many of the
+required signatures each input has. The command does not contact siad.`,
+		Run: wrap(walletpartialshowcmd),
+	}
+
+	walletReservesCmd = &cobra.Command{
+		Use:   "reserves [filepath]",
+		Short: "Prove that the wallet controls an amount of siacoins",
+		Long: `Write a proof of reserves to a file. The proof shows that the wallet controls
+at least --amount siacoins (all of its confirmed siacoins by default) at the
+current block height. It contains the outputs of the wallet, the Merkle proofs
+of the blocks that created them, and signatures by their keys over the
+--challenge, which is usually a message chosen by the auditor. Anyone can check
+the proof with 'siac wallet verify-reserves'.
+
+The proof reveals the addresses and outputs it includes.`,
+		Run: wrap(walletreservescmd),
+	}
+
+	walletRestoreCmd = &cobra.Command{
+		Use:   "restore [filepath]",
+		Short: "Restore an encrypted wallet backup",
+		Long: `Restore a backup created by 'siac wallet backup' into a wallet that has not been
+initialized yet. Afterwards, unlock the wallet with the password of the backup.`,
+		Run: wrap(walletrestorecmd),
+	}
+
 	walletSeedsCmd = &cobra.Command{
 		Use:   "seeds",
 		Short: "View information about your seeds",
@@ -112,32 +251,172 @@ By default the wallet encryption / unlock password is the same as the generated
 	}
 
 	walletSendCmd = &cobra.Command{
-		Use:   "send",
+		Use:   "send [-]",
 		Short: "Send either siacoins or siafunds to an address",
-		Long:  "Send either siacoins or siafunds to an address",
-		// Run field is not set, as the send command itself is not a valid command.
-		// A subcommand must be provided.
+		Long: `Send either siacoins or siafunds to an address.
+
+With --uri, pay a sia: payment URI, such as one produced by 'wallet request' or
+by a web shop. The URI must specify an amount.
+
+With '-', read siacoin sends from stdin, for example from a payout script, and
+make them one at a time. Each line holds an amount and a destination separated
+by whitespace or a comma, e.g. "1.5KS 0123...", or a JSON object such as
+{"amount": "1.5KS", "destination": "0123..."}; a JSON array of such objects is
+also accepted. Every line is checked before anything is sent, and the result of
+each send is reported by line number. --yes is required, as stdin cannot be
+used to confirm the sends.
+
+Sends cannot be undone, so the amount, fee and destination are shown before
+sending, and the send must be confirmed by typing 'yes'. Use --yes to send
+without confirmation.
+
+With --dry-run, siad builds and signs the transaction but does not send it, and
+its inputs, outputs and fee are shown instead, for example to check the units
+of an amount before sending it.`,
+		Run: walletsendcmd,
+	}
+
+	walletRequestCmd = &cobra.Command{
+		Use:   "request [amount]",
+		Short: "Generate a payment URI for a new address",
+		Long: `Generate a new address and print a sia: payment URI that requests a payment to
+it. The URI can be opened by other wallets or embedded in an invoice. 'amount'
+can be specified in units, e.g. 1.23KS; if it is omitted, the payer chooses the
+amount.
+
+siad remembers the request, and marks it fulfilled once the payments to the
+address are confirmed and add up to the amount. Use 'wallet requests' to check
+the status of your requests.`,
+		Run: walletrequestcmd,
+	}
+
+	walletRequestsCmd = &cobra.Command{
+		Use:   "requests",
+		Short: "List payment requests",
+		Long:  "List the payment requests created by 'wallet request', along with the payments received for them.",
+		Run:   wrap(walletrequestscmd),
+	}
+
+	walletSendAllCmd = &cobra.Command{
+		Use:   "all [dest]",
+		Short: "Send the entire siacoin balance to an address",
+		Long: `Send the entire spendable siacoin balance of the wallet to an address,
+minus the miner fee. The fee is computed by siad, so no change or dust is left
+in the wallet. Immature miner payouts are not sent.`,
+		Run: wrap(walletsendallcmd),
 	}
 
 	walletSendSiacoinsCmd = &cobra.Command{
-		Use:   "siacoins [amount] [dest]",
-		Short: "Send siacoins to an address",
+		Use:     "siacoins [amount] [dest]",
+		Aliases: []string{"sc"},
+		Short:   "Send siacoins to an address",
 		Long: `Send siacoins to an address. 'dest' must be a 76-byte hexadecimal address.
 'amount' can be specified in units, e.g. 1.23KS. Run 'wallet --help' for a list of units.
 If no unit is supplied, hastings will be assumed.
 
-A miner fee of 10 SC is levied on all transactions.`,
+A miner fee of 10 SC is levied on all transactions.
+
+With --confirm-target, the fee is picked from the transaction pool's fee
+estimate so that the transaction is confirmed within the given number of
+blocks. With --replaceable as well, the wallet raises the fee whenever the
+transaction misses the target.
+
+With --data, a memo such as an order ID or invoice reference is attached to the
+transaction. The memo is stored in the blockchain and can be read by anyone.`,
 		Run: wrap(walletsendsiacoinscmd),
 	}
 
 	walletSendSiafundsCmd = &cobra.Command{
-		Use:   "siafunds [amount] [dest]",
-		Short: "Send siafunds",
+		Use:     "siafunds [amount] [dest]",
+		Aliases: []string{"sf"},
+		Short:   "Send siafunds",
 		Long: `Send siafunds to an address, and transfer the claim siacoins to your wallet.
-Run 'wallet send --help' to see a list of available units.`,
+The amount is a whole number of siafunds, optionally followed by "SF", e.g.
+"10" or "10SF". Siacoin units are not accepted.`,
 		Run: wrap(walletsendsiafundscmd),
 	}
 
+	walletSiafundsCmd = &cobra.Command{
+		Use:   "siafunds",
+		Short: "Manage siafunds and their siacoin claim balance",
+		Long: `Manage the siacoin claim balance of your siafunds, and spend siafunds whose
+siag keys are kept offline.`,
+		// Run field is not set, as the siafunds command itself is not a valid
+		// command. A subcommand must be provided.
+	}
+
+	walletSiafundsClaimCmd = &cobra.Command{
+		Use:   "claim",
+		Short: "Claim the siacoin claim balance of your siafunds",
+		Long: `Send all of your siafunds to your own wallet, which moves the siacoin claim
+balance of the siafunds into your spendable balance. The siafund balance is
+unchanged, apart from the transaction fee paid in siacoins.`,
+		Run: wrap(walletsiafundsclaimcmd),
+	}
+
+	walletSiafundsAutoClaimCmd = &cobra.Command{
+		Use:   "autoclaim [true|false]",
+		Short: "Enable or disable automatic claims",
+		Long: `Enable or disable automatic claims of the siacoin claim balance of your
+siafunds. When enabled, the wallet periodically claims the balance once it is
+large enough to justify the transaction fee.`,
+		Run: wrap(walletsiafundsautoclaimcmd),
+	}
+
+	walletSiafundsBroadcastCmd = &cobra.Command{
+		Use:   "broadcast [filepath]",
+		Short: "Broadcast a signed siafund transaction",
+		Long: `Sign the wallet's inputs of a siafund transaction that was prepared by
+'siac wallet siafunds prepare' and signed by 'siac wallet siafunds sign', and
+broadcast it.`,
+		Run: wrap(walletsiafundsbroadcastcmd),
+	}
+
+	walletSiafundsPrepareCmd = &cobra.Command{
+		Use:   "prepare [amount] [dest] [source] [filepath]",
+		Short: "Prepare a siafund transaction to be signed offline",
+		Long: `Prepare a transaction that sends 'amount' siafunds from the address 'source'
+to 'dest', and write it to a file. The siag keys of 'source' do not need to be
+loaded into the wallet: the wallet pays the transaction fee and receives the
+siacoin claim, while the siafund inputs are left to be signed by
+'siac wallet siafunds sign', which can run on an offline machine. The remaining
+siafunds are sent back to 'source'.`,
+		Run: wrap(walletsiafundspreparecmd),
+	}
+
+	walletSiafundsSendManyCmd = &cobra.Command{
+		Use:   "send-many [amount] [dest] [amount] [dest]...",
+		Short: "Send siafunds to several addresses",
+		Long: `Send siafunds to several addresses in a single transaction. Each 'amount' is
+a whole number of siafunds and each 'dest' a 76-byte hexadecimal address. Only
+the siafund outputs needed to cover the total are spent, and the rest is
+returned to the wallet. The siacoin claims of the spent siafunds are paid to
+the wallet, so sending siafunds never forfeits the claim balance. The send must
+be confirmed by typing 'yes', unless --yes is given.`,
+		Example: "siac wallet siafunds send-many 100 [address1] 250 [address2]",
+		Run:     walletsiafundssendmanycmd,
+	}
+
+	walletSiafundsSignCmd = &cobra.Command{
+		Use:   "sign [filepath] [keyfile,...]",
+		Short: "Sign a siafund transaction with siag keys",
+		Long: `Sign the siafund inputs of a transaction prepared by 'siac wallet siafunds
+prepare' with siag keyfiles, and update the file. The command does not contact
+siad, so it can run on a machine that is not connected to the network.`,
+		Example: "siac wallet siafunds sign txn.json key1.siakey,key2.siakey",
+		Run:     wrap(walletsiafundssigncmd),
+	}
+
+	walletSiafundsTrackDirCmd = &cobra.Command{
+		Use:   "trackdir [dir]",
+		Short: "Load every siag key in a directory",
+		Long: `Load all of the siag keyfiles (*.siakey) in a directory into the wallet. The
+keyfiles of a multisig address are combined, and the wallet rescans the
+blockchain once for all of the keys. The outcome of each keyfile is printed;
+keyfiles that fail to load do not stop the others from being loaded.`,
+		Run: wrap(walletsiafundstrackdircmd),
+	}
+
 	walletSweepCmd = &cobra.Command{
 		Use:   "sweep",
 		Short: "Sweep siacoins and siafunds from a seed.",
@@ -147,17 +426,37 @@ will be sent to your wallet.`,
 	}
 
 	walletBalanceCmd = &cobra.Command{
-		Use:   "balance",
-		Short: "View wallet balance",
-		Long:  "View wallet balance, including confirmed and unconfirmed siacoins and siafunds.",
-		Run:   wrap(walletbalancecmd),
+		Use:     "balance",
+		Aliases: []string{"status", "s", "bal"},
+		Short:   "View wallet balance",
+		Long:    "View wallet balance, including confirmed and unconfirmed siacoins and siafunds.",
+		Run:     wrap(walletbalancecmd),
 	}
 
 	walletTransactionsCmd = &cobra.Command{
-		Use:   "transactions",
-		Short: "View transactions",
-		Long:  "View transactions related to addresses spendable by the wallet, providing a net flow of siacoins and siafunds for each transaction",
-		Run:   wrap(wallettransactionscmd),
+		Use:     "transactions",
+		Aliases: []string{"txns"},
+		Short:   "View transactions",
+		Long:    "View transactions related to addresses spendable by the wallet, providing a net flow of siacoins and siafunds for each transaction",
+		Run:     wrap(wallettransactionscmd),
+	}
+
+	walletPendingCmd = &cobra.Command{
+		Use:   "pending",
+		Short: "View unconfirmed transactions",
+		Long: `View the wallet's transactions that are waiting in the transaction pool, oldest
+first, with how long each has been waiting, its fee, and its confirmation
+target if the wallet raises the fee when the target is missed.`,
+		Run: wrap(walletpendingcmd),
+	}
+
+	walletVerifyReservesCmd = &cobra.Command{
+		Use:   "verify-reserves [filepath]",
+		Short: "Verify a proof of reserves",
+		Long: `Check a proof of reserves created by 'siac wallet reserves' against the
+blockchain, and print the siacoins it proves to be controlled at its height. The
+wallet does not need to be unlocked.`,
+		Run: wrap(walletverifyreservescmd),
 	}
 
 	walletUnlockCmd = &cobra.Command{
@@ -176,21 +475,131 @@ const askPasswordText = "We need to encrypt the new data using the current walle
 const currentPasswordText = "Current Password: "
 const newPasswordText = "New Password: "
 
+// walletCall returns the API call with the wallet selected by the --wallet
+// flag, if any, added to its query string.
+func walletCall(call string) string {
+	if walletName == "" {
+		return call
+	}
+	sep := "?"
+	if strings.Contains(call, "?") {
+		sep = "&"
+	}
+	return call + sep + "wallet=" + url.QueryEscape(walletName)
+}
+
 // walletaddresscmd fetches a new address from the wallet that will be able to
 // receive coins.
 func walletaddresscmd() {
 	addr := new(api.WalletAddressGET)
-	err := getAPI("/wallet/address", addr)
+	err := getAPI(walletCall("/wallet/address"), addr)
 	if err != nil {
 		die("Could not generate new address:", err)
 	}
 	fmt.Printf("Created new address: %s\n", addr.Address)
+	printQuiet(addr.Address)
+	if walletAddressQR {
+		qr, err := qrString(addr.Address.String())
+		if err != nil {
+			die("Could not render address as a QR code:", err)
+		}
+		fmt.Fprint(promptOutput(), qr)
+	}
+}
+
+// walletderivecmd fetches the address at an index of the primary seed.
+func walletderivecmd(index string) {
+	if _, err := strconv.ParseUint(index, 10, 64); err != nil {
+		dieUsage("Could not parse index:", err)
+	}
+	addr := new(api.WalletAddressGET)
+	err := getAPI(walletCall("/wallet/address/"+index), addr)
+	if err != nil {
+		die("Could not derive address:", err)
+	}
+	fmt.Printf("Address %s: %s\n", index, addr.Address)
+	printQuiet(addr.Address)
+}
+
+// walletbackupcmd writes an encrypted backup of the wallet to a file.
+func walletbackupcmd(path string) {
+	abspath, err := filepath.Abs(path)
+	if err != nil {
+		die("Could not resolve path:", err)
+	}
+	password, err := ask("Wallet password: ")
+	if err != nil {
+		die("Reading password failed:", err)
+	}
+	qs := fmt.Sprintf("destination=%s&encryptionpassword=%s", url.QueryEscape(abspath), url.QueryEscape(password))
+	err = get(walletCall("/wallet/backup?" + qs))
+	if err != nil {
+		die("Could not create backup:", err)
+	}
+	fmt.Println("Wrote wallet backup to", abspath)
+}
+
+// walletreservescmd writes a proof of reserves to a file.
+func walletreservescmd(path string) {
+	qs := "challenge=" + url.QueryEscape(walletReservesChallenge)
+	if walletReservesAmount != "" {
+		amount, err := parseCurrency(walletReservesAmount)
+		if err != nil {
+			dieUsage("Could not parse amount:", err)
+		}
+		qs += "&amount=" + amount
+	}
+	var wrg api.WalletReservesGET
+	err := getAPI(walletCall("/wallet/reserves?"+qs), &wrg)
+	if err != nil {
+		die("Could not create proof of reserves:", err)
+	}
+	proof, err := json.MarshalIndent(wrg.Proof, "", "  ")
+	if err != nil {
+		die("Could not encode proof of reserves:", err)
+	}
+	if err := ioutil.WriteFile(path, proof, 0600); err != nil {
+		die("Could not write proof of reserves:", err)
+	}
+	fmt.Printf("Wrote a proof of %v in %v outputs at height %v to %v\n", currencyUnits(wrg.Proof.Total), len(wrg.Proof.Outputs), wrg.Proof.Height, path)
+}
+
+// walletverifyreservescmd checks a proof of reserves.
+func walletverifyreservescmd(path string) {
+	proof, err := ioutil.ReadFile(path)
+	if err != nil {
+		die("Could not read proof of reserves:", err)
+	}
+	var cvr api.ConsensusValidateReservesPOST
+	err = postResp("/consensus/validate/reserves", string(proof), &cvr)
+	if err != nil {
+		die("Proof of reserves is invalid:", err)
+	}
+	fmt.Printf("Proof of reserves is valid: %v controlled at height %v\n", currencyUnits(cvr.Total), cvr.Height)
+}
+
+// walletrestorecmd restores an encrypted backup into an uninitialized wallet.
+func walletrestorecmd(path string) {
+	abspath, err := filepath.Abs(path)
+	if err != nil {
+		die("Could not resolve path:", err)
+	}
+	password, err := ask("Backup password: ")
+	if err != nil {
+		die("Reading password failed:", err)
+	}
+	qs := fmt.Sprintf("source=%s&encryptionpassword=%s", url.QueryEscape(abspath), url.QueryEscape(password))
+	err = post(walletCall("/wallet/restore"), qs)
+	if err != nil {
+		die("Could not restore backup:", err)
+	}
+	fmt.Println("Wallet restored. Unlock it with the password of the backup to rescan the blockchain.")
 }
 
 // walletaddressescmd fetches the list of addresses that the wallet knows.
 func walletaddressescmd() {
 	addrs := new(api.WalletAddressesGET)
-	err := getAPI("/wallet/addresses", addrs)
+	err := getAPI(walletCall("/wallet/addresses"), addrs)
 	if err != nil {
 		die("Failed to fetch addresses:", err)
 	}
@@ -199,18 +608,48 @@ func walletaddressescmd() {
 	}
 }
 
+// walletreplacecmd replaces an unconfirmed transaction through the provided
+// API call, using the fee given with --fee.
+func walletreplacecmd(call string) api.WalletTransactionReplacePOST {
+	vals := ""
+	if walletReplaceFee != "" {
+		hastings, err := parseCurrency(walletReplaceFee)
+		if err != nil {
+			dieUsage("Could not parse fee:", err)
+		}
+		vals = "fee=" + hastings
+	}
+	var wtr api.WalletTransactionReplacePOST
+	if err := postResp(walletCall(call), vals, &wtr); err != nil {
+		die("Could not replace transaction:", err)
+	}
+	return wtr
+}
+
+// walletbumpfeecmd raises the fee of an unconfirmed transaction.
+func walletbumpfeecmd(txid string) {
+	wtr := walletreplacecmd("/wallet/transaction/" + txid + "/bumpfee")
+	fmt.Println("Replaced transaction", txid, "with", wtr.TransactionIDs[len(wtr.TransactionIDs)-1])
+}
+
+// walletcancelcmd cancels an unconfirmed transaction.
+func walletcancelcmd(txid string) {
+	wtr := walletreplacecmd("/wallet/transaction/" + txid + "/cancel")
+	fmt.Println("Cancelled transaction", txid, "with", wtr.TransactionIDs[len(wtr.TransactionIDs)-1])
+}
+
 // walletchangepasswordcmd changes the password of the wallet.
 func walletchangepasswordcmd() {
-	currentPassword, err := speakeasy.Ask(currentPasswordText)
+	currentPassword, err := ask(currentPasswordText)
 	if err != nil {
 		die("Reading password failed:", err)
 	}
-	newPassword, err := speakeasy.Ask(newPasswordText)
+	newPassword, err := ask(newPasswordText)
 	if err != nil {
 		die("Reading password failed:", err)
 	}
 	qs := fmt.Sprintf("newpassword=%s&encryptionpassword=%s", newPassword, currentPassword)
-	err = post("/wallet/changepassword", qs)
+	err = post(walletCall("/wallet/changepassword"), qs)
 	if err != nil {
 		die("Changing the password failed:", err)
 	}
@@ -222,7 +661,7 @@ func walletinitcmd() {
 	var er api.WalletInitPOST
 	qs := fmt.Sprintf("dictionary=%s", "english")
 	if initPassword {
-		password, err := speakeasy.Ask("Wallet password: ")
+		password, err := ask("Wallet password: ")
 		if err != nil {
 			die("Reading password failed:", err)
 		}
@@ -231,11 +670,12 @@ func walletinitcmd() {
 	if initForce {
 		qs += "&force=true"
 	}
-	err := postResp("/wallet/init", qs, &er)
+	err := postResp(walletCall("/wallet/init"), qs, &er)
 	if err != nil {
 		die("Error when encrypting wallet:", err)
 	}
 	fmt.Printf("Recovery seed:\n%s\n\n", er.PrimarySeed)
+	printQuiet(er.PrimarySeed)
 	if initPassword {
 		fmt.Printf("Wallet encrypted with given password\n")
 	} else {
@@ -245,13 +685,13 @@ func walletinitcmd() {
 
 // walletinitseedcmd initializes the wallet from a preexisting seed.
 func walletinitseedcmd() {
-	seed, err := speakeasy.Ask("Seed: ")
+	seed, err := ask("Seed: ")
 	if err != nil {
 		die("Reading seed failed:", err)
 	}
 	qs := fmt.Sprintf("&seed=%s&dictionary=%s", seed, "english")
 	if initPassword {
-		password, err := speakeasy.Ask("Wallet password: ")
+		password, err := ask("Wallet password: ")
 		if err != nil {
 			die("Reading password failed:", err)
 		}
@@ -260,7 +700,7 @@ func walletinitseedcmd() {
 	if initForce {
 		qs += "&force=true"
 	}
-	err = post("/wallet/init/seed", qs)
+	err = post(walletCall("/wallet/init/seed"), qs)
 	if err != nil {
 		die("Could not initialize wallet from seed:", err)
 	}
@@ -273,12 +713,12 @@ func walletinitseedcmd() {
 
 // walletload033xcmd loads a v0.3.3.x wallet into the current wallet.
 func walletload033xcmd(source string) {
-	password, err := speakeasy.Ask(askPasswordText)
+	password, err := ask(askPasswordText)
 	if err != nil {
 		die("Reading password failed:", err)
 	}
 	qs := fmt.Sprintf("source=%s&encryptionpassword=%s", abs(source), password)
-	err = post("/wallet/033x", qs)
+	err = post(walletCall("/wallet/033x"), qs)
 	if err != nil {
 		die("Loading wallet failed:", err)
 	}
@@ -287,16 +727,16 @@ func walletload033xcmd(source string) {
 
 // walletloadseedcmd adds a seed to the wallet's list of seeds
 func walletloadseedcmd() {
-	seed, err := speakeasy.Ask("New seed: ")
+	seed, err := ask("New seed: ")
 	if err != nil {
 		die("Reading seed failed:", err)
 	}
-	password, err := speakeasy.Ask(askPasswordText)
+	password, err := ask(askPasswordText)
 	if err != nil {
 		die("Reading password failed:", err)
 	}
 	qs := fmt.Sprintf("encryptionpassword=%s&seed=%s&dictionary=%s", password, seed, "english")
-	err = post("/wallet/seed", qs)
+	err = post(walletCall("/wallet/seed"), qs)
 	if err != nil {
 		die("Could not add seed:", err)
 	}
@@ -305,12 +745,16 @@ func walletloadseedcmd() {
 
 // walletloadsiagcmd loads a siag key set into the wallet.
 func walletloadsiagcmd(keyfiles string) {
-	password, err := speakeasy.Ask(askPasswordText)
+	password, err := ask(askPasswordText)
 	if err != nil {
 		die("Reading password failed:", err)
 	}
 	qs := fmt.Sprintf("keyfiles=%s&encryptionpassword=%s", keyfiles, password)
-	err = post("/wallet/siagkey", qs)
+	route := "/wallet/siagkey"
+	if walletWatchSiag {
+		route = "/wallet/siafunds/watchsiagaddress"
+	}
+	err = post(walletCall(route), qs)
 	if err != nil {
 		die("Loading siag key failed:", err)
 	}
@@ -319,16 +763,26 @@ func walletloadsiagcmd(keyfiles string) {
 
 // walletlockcmd locks the wallet
 func walletlockcmd() {
-	err := post("/wallet/lock", "")
+	err := post(walletCall("/wallet/lock"), "")
 	if err != nil {
 		die("Could not lock wallet:", err)
 	}
 }
 
+// walletreindexcmd rebuilds the wallet's balance and history.
+func walletreindexcmd() {
+	fmt.Println("Reindexing the wallet, this may take a while...")
+	err := post(walletCall("/wallet/reindex"), "")
+	if err != nil {
+		die("Could not reindex wallet:", err)
+	}
+	fmt.Println("Wallet reindexed.")
+}
+
 // walletseedcmd returns the current seed {
 func walletseedscmd() {
 	var seedInfo api.WalletSeedsGET
-	err := getAPI("/wallet/seeds", &seedInfo)
+	err := getAPI(walletCall("/wallet/seeds"), &seedInfo)
 	if err != nil {
 		die("Error retrieving the current seed:", err)
 	}
@@ -349,32 +803,523 @@ func walletseedscmd() {
 	}
 }
 
+// siacoinSendValues returns the parameters of a /wallet/siacoins call that
+// sends 'hastings' to 'dest', with the fee and memo flags applied.
+func siacoinSendValues(hastings, dest string) string {
+	vals := fmt.Sprintf("amount=%s&destination=%s", hastings, dest)
+	if walletConfirmTarget != 0 {
+		vals += fmt.Sprintf("&confirmtarget=%d&replaceable=%t", walletConfirmTarget, walletReplaceable)
+	} else if walletReplaceable {
+		dieUsage("--replaceable requires --confirm-target")
+	}
+	if walletMemo != "" {
+		vals += "&data=" + url.QueryEscape(walletMemo)
+	}
+	return vals
+}
+
 // walletsendsiacoinscmd sends siacoins to a destination address.
 func walletsendsiacoinscmd(amount, dest string) {
 	hastings, err := parseCurrency(amount)
 	if err != nil {
-		die("Could not parse amount:", err)
+		dieUsage("Could not parse amount:", err)
+	}
+	vals := siacoinSendValues(hastings, dest)
+	if walletDryRun {
+		var wsp api.WalletSiacoinsPOST
+		if err := postResp(walletCall("/wallet/siacoins"), vals+"&dryrun=true", &wsp); err != nil {
+			die("Could not build siacoin transaction:", err)
+		}
+		printDryRun(wsp.Transactions, walletAddresses())
+		return
+	}
+	fee := estimatedFee(750)
+	if walletConfirmTarget != 0 {
+		fee = fmt.Sprintf("picked to confirm within %d blocks", walletConfirmTarget)
 	}
-	err = post("/wallet/siacoins", fmt.Sprintf("amount=%s&destination=%s", hastings, dest))
+	confirmSend(
+		sendDetail{"Amount", siacoinAmount(hastings)},
+		sendDetail{"Fee", fee},
+		sendDetail{"Destination", dest},
+	)
+	var wsp api.WalletSiacoinsPOST
+	err = postResp(walletCall("/wallet/siacoins"), vals, &wsp)
 	if err != nil {
 		die("Could not send siacoins:", err)
 	}
-	fmt.Printf("Sent %s hastings to %s\n", hastings, dest)
+	fmt.Printf("Sent %s to %s\n", hastingsUnits(hastings), dest)
+	printQuietTransaction(wsp.TransactionIDs)
+	if walletConfirmTarget != 0 {
+		fmt.Printf("The fee targets confirmation within %d blocks", walletConfirmTarget)
+		if walletReplaceable {
+			fmt.Print("; it will be raised if the target is missed")
+		}
+		fmt.Println(".")
+	}
+}
+
+// walletsendcmd pays a payment URI, or sends the siacoins read from stdin
+// when the argument is '-'.
+func walletsendcmd(cmd *cobra.Command, args []string) {
+	if len(args) > 1 || (len(args) == 1 && (args[0] != "-" || walletSendURI != "")) {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	if len(args) == 1 {
+		walletsendbatchcmd()
+		return
+	}
+	if walletSendURI == "" {
+		dieUsage("A subcommand or --uri must be provided. Run 'wallet send --help' for usage.")
+	}
+	pu, err := types.ParsePaymentURI(walletSendURI)
+	if err != nil {
+		dieUsage("Could not parse payment URI:", err)
+	}
+	if pu.Amount.IsZero() {
+		die("The payment URI does not specify an amount; use 'wallet send siacoins' instead.")
+	}
+	if pu.Expired(types.CurrentTimestamp()) {
+		die("The payment request expired on", time.Unix(int64(pu.Expires), 0).Format("2006-01-02 15:04")+".")
+	}
+	if pu.Label != "" {
+		fmt.Println("Paying:", pu.Label)
+	}
+	if pu.Message != "" {
+		fmt.Println("Message:", pu.Message)
+	}
+	walletsendsiacoinscmd(pu.Amount.String(), pu.Address.String())
+}
+
+// walletrequestcmd creates a payment request for a new address and prints its
+// payment URI.
+func walletrequestcmd(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	vals := url.Values{}
+	vals.Set("label", walletRequestLabel)
+	vals.Set("message", walletRequestMessage)
+	if len(args) == 1 {
+		hastings, err := parseCurrency(args[0])
+		if err != nil {
+			dieUsage("Could not parse amount:", err)
+		}
+		vals.Set("amount", hastings)
+	}
+	if walletRequestExpires != "" {
+		d, err := time.ParseDuration(walletRequestExpires)
+		if err != nil || d <= 0 {
+			dieUsage("Could not parse expiry: must be a positive duration, e.g. 24h")
+		}
+		vals.Set("expires", strconv.FormatInt(time.Now().Add(d).Unix(), 10))
+	}
+	var wpr api.WalletPaymentRequest
+	if err := postResp(walletCall("/wallet/requests"), vals.Encode(), &wpr); err != nil {
+		die("Could not create payment request:", err)
+	}
+	fmt.Println(wpr.URI)
+	printQuiet(wpr.URI)
+}
+
+// walletrequestscmd lists the wallet's payment requests.
+func walletrequestscmd() {
+	var wrg api.WalletRequestsGET
+	if err := getAPI(walletCall("/wallet/requests"), &wrg); err != nil {
+		die("Could not fetch payment requests:", err)
+	}
+	if len(wrg.Requests) == 0 {
+		fmt.Println("No payment requests.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Address\tAmount\tReceived\tStatus\tExpires\tLabel")
+	for _, pr := range wrg.Requests {
+		amount := "any"
+		if !pr.Amount.IsZero() {
+			amount = currencyUnits(pr.Amount)
+		}
+		expires := "never"
+		if pr.Expires != 0 {
+			expires = time.Unix(int64(pr.Expires), 0).Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", pr.Address, amount, currencyUnits(pr.Received), pr.Status, expires, pr.Label)
+	}
+	w.Flush()
+}
+
+// walletsendallcmd sends the entire siacoin balance of the wallet to a
+// destination address.
+func walletsendallcmd(dest string) {
+	if walletDryRun {
+		var wsp api.WalletSiacoinsPOST
+		if err := postResp(walletCall("/wallet/siacoins"), "all=true&dryrun=true&destination="+dest, &wsp); err != nil {
+			die("Could not build siacoin transaction:", err)
+		}
+		printDryRun(wsp.Transactions, walletAddresses())
+		return
+	}
+	confirmSend(
+		sendDetail{"Amount", "entire spendable balance, minus the fee"},
+		sendDetail{"Destination", dest},
+	)
+	var wsp api.WalletSiacoinsPOST
+	err := postResp(walletCall("/wallet/siacoins"), "all=true&destination="+dest, &wsp)
+	if err != nil {
+		die("Could not send siacoins:", err)
+	}
+	fmt.Printf("Sent %s to %s\n", currencyUnits(wsp.Amount), dest)
+	printQuietTransaction(wsp.TransactionIDs)
 }
 
 // walletsendsiafundscmd sends siafunds to a destination address.
 func walletsendsiafundscmd(amount, dest string) {
-	err := post("/wallet/siafunds", fmt.Sprintf("amount=%s&destination=%s", amount, dest))
+	sf, err := parseSiafunds(amount)
+	if err != nil {
+		dieUsage("Could not parse amount:", err)
+	}
+	vals := fmt.Sprintf("amount=%s&destination=%s", sf, dest)
+	if walletDryRun {
+		var wsp api.WalletSiafundsPOST
+		if err := postResp(walletCall("/wallet/siafunds"), vals+"&dryrun=true", &wsp); err != nil {
+			die("Could not build siafund transaction:", err)
+		}
+		printDryRun(wsp.Transactions, walletAddresses())
+		return
+	}
+	confirmSend(
+		sendDetail{"Amount", siafundUnits(sf)},
+		sendDetail{"Fee", estimatedFee(750 * 5)},
+		sendDetail{"Destination", dest},
+	)
+	var wsp api.WalletSiafundsPOST
+	err = postResp(walletCall("/wallet/siafunds"), vals, &wsp)
 	if err != nil {
 		die("Could not send siafunds:", err)
 	}
-	fmt.Printf("Sent %s siafunds to %s\n", amount, dest)
+	fmt.Printf("Sent %s to %s\n", siafundUnits(sf), dest)
+	printQuietTransaction(wsp.TransactionIDs)
+}
+
+// walletsiafundssendmanycmd sends siafunds to several destination addresses
+// in one transaction.
+func walletsiafundssendmanycmd(cmd *cobra.Command, args []string) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	var outputs []types.SiafundOutput
+	for i := 0; i < len(args); i += 2 {
+		amount, err := parseSiafunds(args[i])
+		if err != nil {
+			dieUsage("Could not parse amount "+args[i]+":", err)
+		}
+		var dest types.UnlockHash
+		if err := dest.LoadString(args[i+1]); err != nil {
+			dieUsage("Could not parse address:", err)
+		}
+		outputs = append(outputs, types.SiafundOutput{Value: amount, UnlockHash: dest})
+	}
+	var details []sendDetail
+	for _, sfo := range outputs {
+		details = append(details, sendDetail{"Send", fmt.Sprintf("%v to %v", siafundUnits(sfo.Value), sfo.UnlockHash)})
+	}
+	details = append(details, sendDetail{"Fee", estimatedFee(5 * (1000 + 60*uint64(len(outputs))))})
+	confirmSend(details...)
+	outputsJSON, err := json.Marshal(outputs)
+	if err != nil {
+		die("Could not encode outputs:", err)
+	}
+	var wsp api.WalletSiafundsPOST
+	err = postResp(walletCall("/wallet/siafunds"), "outputs="+url.QueryEscape(string(outputsJSON)), &wsp)
+	if err != nil {
+		die("Could not send siafunds:", err)
+	}
+	for _, sfo := range outputs {
+		fmt.Printf("Sent %v to %v\n", siafundUnits(sfo.Value), sfo.UnlockHash)
+	}
+	printQuietTransaction(wsp.TransactionIDs)
+}
+
+// walletsiafundsclaimcmd claims the siacoin claim balance of the wallet's
+// siafunds.
+func walletsiafundsclaimcmd() {
+	status := new(api.WalletGET)
+	err := getAPI(walletCall("/wallet"), status)
+	if err != nil {
+		die("Could not get wallet status:", err)
+	}
+	err = post(walletCall("/wallet/siafunds/claim"), "")
+	if err != nil {
+		die("Could not claim siafunds:", err)
+	}
+	fmt.Printf("Claimed %v from %v\n", currencyUnits(status.SiacoinClaimBalance), siafundUnits(status.SiafundBalance))
+}
+
+// readSiafundTransaction reads a siafund transaction set from a file.
+func readSiafundTransaction(path string) []types.Transaction {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		die("Could not read transaction:", err)
+	}
+	var txns []types.Transaction
+	if err := json.Unmarshal(data, &txns); err != nil || len(txns) == 0 {
+		die("Could not decode transaction:", err)
+	}
+	return txns
+}
+
+// writeSiafundTransaction writes a siafund transaction set to a file.
+func writeSiafundTransaction(path string, txns []types.Transaction) {
+	data, err := json.MarshalIndent(txns, "", "  ")
+	if err != nil {
+		die("Could not encode transaction:", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		die("Could not write transaction:", err)
+	}
+}
+
+// walletsiafundspreparecmd prepares a siafund transaction to be signed with
+// siag keys.
+func walletsiafundspreparecmd(amount, dest, source, path string) {
+	sf, err := parseSiafunds(amount)
+	if err != nil {
+		dieUsage("Could not parse amount:", err)
+	}
+	var wspp api.WalletSiafundsPreparePOST
+	qs := fmt.Sprintf("amount=%s&destination=%s&source=%s", sf, dest, source)
+	err = postResp(walletCall("/wallet/siafunds/prepare"), qs, &wspp)
+	if err != nil {
+		die("Could not prepare siafund transaction:", err)
+	}
+	writeSiafundTransaction(path, wspp.Transactions)
+	fmt.Printf("Wrote a transaction sending %s from %s to %s to %v\n", siafundUnits(sf), source, dest, path)
+}
+
+// walletsiafundssigncmd signs the siafund inputs of a prepared transaction
+// with siag keys. It does not contact siad.
+func walletsiafundssigncmd(path, keyfiles string) {
+	txns := readSiafundTransaction(path)
+	txn := &txns[len(txns)-1]
+	if err := wallet.SignSiagTransaction(txn, strings.Split(keyfiles, ",")); err != nil {
+		die("Could not sign transaction:", err)
+	}
+	writeSiafundTransaction(path, txns)
+	fmt.Println("Signed a transaction with the siafund outputs:")
+	for _, sfo := range txn.SiafundOutputs {
+		fmt.Printf("  %v to %v\n", siafundUnits(sfo.Value), sfo.UnlockHash)
+	}
+}
+
+// walletsiafundsbroadcastcmd broadcasts a signed siafund transaction.
+func walletsiafundsbroadcastcmd(path string) {
+	txns, err := json.Marshal(readSiafundTransaction(path))
+	if err != nil {
+		die("Could not encode transaction:", err)
+	}
+	var wsp api.WalletSiafundsPOST
+	err = postResp(walletCall("/wallet/siafunds/broadcast"), "transactions="+url.QueryEscape(string(txns)), &wsp)
+	if err != nil {
+		die("Could not broadcast siafund transaction:", err)
+	}
+	fmt.Println("Broadcast siafund transaction", wsp.TransactionIDs[len(wsp.TransactionIDs)-1])
+}
+
+// readPartialTransaction reads a partial transaction from a file.
+func readPartialTransaction(path string) modules.PartialTransaction {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		die("Could not read partial transaction:", err)
+	}
+	var pt modules.PartialTransaction
+	if err := json.Unmarshal(data, &pt); err != nil {
+		die("Could not decode partial transaction:", err)
+	}
+	if err := pt.Validate(); err != nil {
+		die("Invalid partial transaction:", err)
+	}
+	return pt
+}
+
+// writePartialTransaction writes a partial transaction to a file.
+func writePartialTransaction(path string, pt modules.PartialTransaction) {
+	data, err := json.MarshalIndent(pt, "", "  ")
+	if err != nil {
+		die("Could not encode partial transaction:", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		die("Could not write partial transaction:", err)
+	}
+}
+
+// printPartialStatus prints whether a partial transaction can be finalized.
+func printPartialStatus(pt modules.PartialTransaction, path string) {
+	if pt.Complete() {
+		fmt.Printf("Wrote a partial transaction with all of its signatures to %v; it can be finalized.\n", path)
+	} else {
+		fmt.Printf("Wrote a partial transaction that still needs signatures to %v.\n", path)
+	}
+}
+
+// walletpartialcreatecmd turns a transaction set into a partial transaction.
+func walletpartialcreatecmd(txnPath, path string) {
+	txns, err := json.Marshal(readSiafundTransaction(txnPath))
+	if err != nil {
+		die("Could not encode transaction:", err)
+	}
+	qs := "transactions=" + url.QueryEscape(string(txns))
+	if walletPartialDesc != "" {
+		qs += "&description=" + url.QueryEscape(walletPartialDesc)
+	}
+	var wpp api.WalletPartialPOST
+	if err := postResp(walletCall("/wallet/partial/create"), qs, &wpp); err != nil {
+		die("Could not create partial transaction:", err)
+	}
+	writePartialTransaction(path, wpp.Partial)
+	printPartialStatus(wpp.Partial, path)
+}
+
+// walletpartialsigncmd adds the wallet's signatures to a partial
+// transaction.
+func walletpartialsigncmd(path string) {
+	data, err := json.Marshal(readPartialTransaction(path))
+	if err != nil {
+		die("Could not encode partial transaction:", err)
+	}
+	var wpp api.WalletPartialPOST
+	if err := postResp(walletCall("/wallet/partial/sign"), "partial="+url.QueryEscape(string(data)), &wpp); err != nil {
+		die("Could not sign partial transaction:", err)
+	}
+	writePartialTransaction(path, wpp.Partial)
+	printPartialStatus(wpp.Partial, path)
+}
+
+// walletpartialmergecmd combines several copies of a partial transaction.
+func walletpartialmergecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cmd.UsageFunc()(cmd)
+		exit(exitCodeUsage)
+	}
+	var pts []modules.PartialTransaction
+	for _, path := range args[1:] {
+		pts = append(pts, readPartialTransaction(path))
+	}
+	// Merging does not need any keys, so it is done without contacting siad.
+	pt, err := modules.MergePartialTransactions(pts)
+	if err != nil {
+		die("Could not merge partial transactions:", err)
+	}
+	writePartialTransaction(args[0], pt)
+	printPartialStatus(pt, args[0])
+}
+
+// walletpartialfinalizecmd turns a complete partial transaction into a signed
+// transaction set.
+func walletpartialfinalizecmd(path, txnPath string) {
+	data, err := json.Marshal(readPartialTransaction(path))
+	if err != nil {
+		die("Could not encode partial transaction:", err)
+	}
+	qs := fmt.Sprintf("partial=%s&broadcast=%t", url.QueryEscape(string(data)), walletPartialBroadcast)
+	var wpfp api.WalletPartialFinalizePOST
+	if err := postResp(walletCall("/wallet/partial/finalize"), qs, &wpfp); err != nil {
+		die("Could not finalize partial transaction:", err)
+	}
+	writeSiafundTransaction(txnPath, wpfp.Transactions)
+	txid := wpfp.TransactionIDs[len(wpfp.TransactionIDs)-1]
+	if walletPartialBroadcast {
+		fmt.Printf("Wrote and broadcast transaction %v to %v\n", txid, txnPath)
+	} else {
+		fmt.Printf("Wrote transaction %v to %v\n", txid, txnPath)
+	}
+}
+
+// walletpartialshowcmd prints the contents of a partial transaction.
+func walletpartialshowcmd(path string) {
+	pt := readPartialTransaction(path)
+	fmt.Println("Transaction:", pt.Transaction.ID())
+	if desc, ok := pt.Metadata["description"]; ok {
+		fmt.Println("Description:", desc)
+	}
+	fmt.Println("Inputs:")
+	for i, in := range pt.Inputs {
+		value := "unknown value"
+		if !in.Value.IsZero() {
+			switch in.Type {
+			case modules.PartialInputSiacoin:
+				value = currencyUnits(in.Value)
+			case modules.PartialInputSiafund:
+				value = siafundUnits(in.Value)
+			}
+		}
+		uc := pt.UnlockConditions(i)
+		fmt.Printf("  %-8v %v  %v  %v/%v signatures\n", in.Type, in.ParentID, value, len(in.Signatures), uc.SignaturesRequired)
+	}
+	fmt.Println("Outputs:")
+	for _, sco := range pt.Transaction.SiacoinOutputs {
+		fmt.Printf("  %v to %v\n", currencyUnits(sco.Value), sco.UnlockHash)
+	}
+	for _, sfo := range pt.Transaction.SiafundOutputs {
+		fmt.Printf("  %v to %v\n", siafundUnits(sfo.Value), sfo.UnlockHash)
+	}
+	for _, fee := range pt.Transaction.MinerFees {
+		fmt.Printf("  %v miner fee\n", currencyUnits(fee))
+	}
+}
+
+// walletsiafundstrackdircmd loads every siag key in a directory into the
+// wallet.
+func walletsiafundstrackdircmd(dir string) {
+	absdir, err := filepath.Abs(dir)
+	if err != nil {
+		die("Could not resolve path:", err)
+	}
+	password, err := ask(askPasswordText)
+	if err != nil {
+		die("Reading password failed:", err)
+	}
+	var wstd api.WalletSiafundsTrackDirPOST
+	qs := fmt.Sprintf("dir=%s&encryptionpassword=%s", url.QueryEscape(absdir), url.QueryEscape(password))
+	err = postResp(walletCall("/wallet/siafunds/trackdir"), qs, &wstd)
+	if err != nil {
+		die("Could not load siag keys:", err)
+	}
+	loaded := 0
+	for _, kf := range wstd.Keyfiles {
+		if kf.Error != "" {
+			fmt.Printf("%v: %v\n", filepath.Base(kf.Keyfile), kf.Error)
+			continue
+		}
+		fmt.Printf("%v: loaded address %v\n", filepath.Base(kf.Keyfile), kf.Address)
+		loaded++
+	}
+	fmt.Printf("Loaded %v of %v keyfiles.\n", loaded, len(wstd.Keyfiles))
+}
+
+// walletsiafundsautoclaimcmd enables or disables automatic claims of the
+// wallet's siacoin claim balance.
+func walletsiafundsautoclaimcmd(enable string) {
+	autoClaim, err := strconv.ParseBool(enable)
+	if err != nil {
+		dieUsage("Could not parse argument:", err)
+	}
+	err = post(walletCall("/wallet/settings"), "autoclaimsiafunds="+strconv.FormatBool(autoClaim))
+	if err != nil {
+		die("Could not change wallet settings:", err)
+	}
+	if autoClaim {
+		fmt.Println("Automatic siafund claims enabled")
+	} else {
+		fmt.Println("Automatic siafund claims disabled")
+	}
 }
 
 // walletbalancecmd retrieves and displays information about the wallet.
 func walletbalancecmd() {
 	status := new(api.WalletGET)
-	err := getAPI("/wallet", status)
+	err := getAPI(walletCall("/wallet"), status)
 	if err != nil {
 		die("Could not get wallet status:", err)
 	}
@@ -408,35 +1353,36 @@ Unlock the wallet to view balance
 Confirmed Balance:   %v
 Unconfirmed Delta:  %v
 Exact:               %v H
-Siafunds:            %v SF
-Siafund Claims:      %v H
+Siafunds:            %v
+Siafund Claims:      %v
 
 Estimated Fee:       %v / KB
 `, encStatus, currencyUnits(status.ConfirmedSiacoinBalance), delta,
-		status.ConfirmedSiacoinBalance, status.SiafundBalance, status.SiacoinClaimBalance,
-		fees.Maximum.Mul64(1e3).HumanString())
+		status.ConfirmedSiacoinBalance, siafundUnits(status.SiafundBalance), currencyUnits(status.SiacoinClaimBalance),
+		currencyUnits(fees.Maximum.Mul64(1e3)))
+	printQuiet(status.ConfirmedSiacoinBalance)
 }
 
 // walletsweepcmd sweeps coins and funds from a seed.
 func walletsweepcmd() {
-	seed, err := speakeasy.Ask("Seed: ")
+	seed, err := ask("Seed: ")
 	if err != nil {
 		die("Reading seed failed:", err)
 	}
 
 	var swept api.WalletSweepPOST
-	err = postResp("/wallet/sweep/seed", fmt.Sprintf("seed=%s&dictionary=%s", seed, "english"), &swept)
+	err = postResp(walletCall("/wallet/sweep/seed"), fmt.Sprintf("seed=%s&dictionary=%s", seed, "english"), &swept)
 	if err != nil {
 		die("Could not sweep seed:", err)
 	}
-	fmt.Printf("Swept %v and %v SF from seed.\n", currencyUnits(swept.Coins), swept.Funds)
+	fmt.Printf("Swept %v and %v from seed.\n", currencyUnits(swept.Coins), siafundUnits(swept.Funds))
 }
 
 // wallettransactionscmd lists all of the transactions related to the wallet,
 // providing a net flow of siacoins and siafunds for each.
 func wallettransactionscmd() {
 	wtg := new(api.WalletTransactionsGET)
-	err := getAPI("/wallet/transactions?startheight=0&endheight=10000000", wtg)
+	err := getAPI(walletCall("/wallet/transactions?startheight=0&endheight=10000000"), wtg)
 	if err != nil {
 		die("Could not fetch transaction history:", err)
 	}
@@ -471,24 +1417,63 @@ func wallettransactionscmd() {
 			}
 		}
 
-		// Convert the siacoins to a float.
-		incomingSiacoinsFloat, _ := new(big.Rat).SetFrac(incomingSiacoins.Big(), types.SiacoinPrecision.Big()).Float64()
-		outgoingSiacoinsFloat, _ := new(big.Rat).SetFrac(outgoingSiacoins.Big(), types.SiacoinPrecision.Big()).Float64()
-
 		// Print the results.
 		if txn.ConfirmationHeight < 1e9 {
 			fmt.Printf("%12v", txn.ConfirmationHeight)
 		} else {
 			fmt.Printf(" unconfirmed")
 		}
-		fmt.Printf("%67v%15.2f SC", txn.TransactionID, incomingSiacoinsFloat-outgoingSiacoinsFloat)
+		fmt.Printf("%67v%18v", txn.TransactionID, netCurrencyUnits(incomingSiacoins, outgoingSiacoins))
 		// For siafunds, need to avoid having a negative types.Currency.
 		if incomingSiafunds.Cmp(outgoingSiafunds) >= 0 {
-			fmt.Printf("%14v SF\n", incomingSiafunds.Sub(outgoingSiafunds))
+			fmt.Printf("%17v\n", siafundUnits(incomingSiafunds.Sub(outgoingSiafunds)))
 		} else {
-			fmt.Printf("-%14v SF\n", outgoingSiafunds.Sub(incomingSiafunds))
+			fmt.Printf("%17v\n", "-"+siafundUnits(outgoingSiafunds.Sub(incomingSiafunds)))
+		}
+		if memo, ok := wtg.Memos[txn.TransactionID.String()]; ok {
+			fmt.Printf("%18v %q\n", "memo:", memo)
+		}
+	}
+}
+
+// walletpendingcmd lists the unconfirmed transactions of the wallet.
+func walletpendingcmd() {
+	var wtu api.WalletTransactionsUnconfirmedGET
+	err := getAPI(walletCall("/wallet/unconfirmed"), &wtu)
+	if err != nil {
+		die("Could not fetch unconfirmed transactions:", err)
+	}
+	if len(wtu.Transactions) == 0 {
+		fmt.Println("No unconfirmed transactions.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Transaction ID\tAge\tNet Siacoins\tFee\tTarget")
+	for _, txn := range wtu.Transactions {
+		var incoming, outgoing, fee types.Currency
+		for _, input := range txn.Inputs {
+			if input.FundType == types.SpecifierSiacoinInput && input.WalletAddress {
+				outgoing = outgoing.Add(input.Value)
+			}
+		}
+		for _, output := range txn.Outputs {
+			if output.FundType == types.SpecifierSiacoinOutput && output.WalletAddress {
+				incoming = incoming.Add(output.Value)
+			} else if output.FundType == types.SpecifierMinerFee {
+				fee = fee.Add(output.Value)
+			}
+		}
+		target := "-"
+		if txn.ConfirmationTarget != 0 {
+			target = fmt.Sprintf("%v blocks", txn.ConfirmationTarget)
+		}
+		age := time.Duration(txn.Age) * time.Second
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", txn.TransactionID, age, netCurrencyUnits(incoming, outgoing), currencyUnits(fee), target)
+		if memo, ok := wtu.Memos[txn.TransactionID.String()]; ok {
+			fmt.Fprintf(w, "  memo: %q\t\t\t\t\n", memo)
 		}
 	}
+	w.Flush()
 }
 
 // walletunlockcmd unlocks a saved wallet
@@ -499,7 +1484,7 @@ func walletunlockcmd() {
 	if password != "" && !initPassword {
 		fmt.Println("Using SIA_WALLET_PASSWORD environment variable")
 		qs := fmt.Sprintf("encryptionpassword=%s&dictonary=%s", password, "english")
-		err := post("/wallet/unlock", qs)
+		err := post(walletCall("/wallet/unlock"), qs)
 		if err != nil {
 			fmt.Println("Automatic unlock failed!")
 		} else {
@@ -507,12 +1492,12 @@ func walletunlockcmd() {
 			return
 		}
 	}
-	password, err := speakeasy.Ask("Wallet password: ")
+	password, err := ask("Wallet password: ")
 	if err != nil {
 		die("Reading password failed:", err)
 	}
 	qs := fmt.Sprintf("encryptionpassword=%s&dictonary=%s", password, "english")
-	err = post("/wallet/unlock", qs)
+	err = post(walletCall("/wallet/unlock"), qs)
 	if err != nil {
 		die("Could not unlock wallet:", err)
 	}
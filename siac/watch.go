@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// The status commands accept --watch[=interval], which clears the terminal
+// and prints their output again at every interval until siac is interrupted.
+// Each refresh is rendered in full before the screen is cleared, so that slow
+// API calls do not leave the screen blank, and errors are shown in place of
+// the output instead of ending the watch.
+
+const (
+	// defaultWatchInterval is the refresh interval of --watch without a
+	// value.
+	defaultWatchInterval = 2 * time.Second
+
+	// clearScreen moves the cursor to the top left corner of the terminal
+	// and clears it.
+	clearScreen = "\033[H\033[2J"
+)
+
+var (
+	// watchInterval is set by the --watch flag.
+	watchInterval time.Duration
+
+	// watching is set while the output of a command is being refreshed, so
+	// that die reports errors instead of exiting.
+	watching bool
+)
+
+// watchError is the panic value used by die while watching.
+type watchError string
+
+// addWatchFlag adds the --watch flag to status commands.
+func addWatchFlag(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags().DurationVar(&watchInterval, "watch", 0, "refresh the output every 2s, or at the given interval, e.g. --watch=10s")
+		cmd.Flags().Lookup("watch").NoOptDefVal = defaultWatchInterval.String()
+		run := cmd.Run
+		cmd.Run = func(cmd *cobra.Command, args []string) {
+			if !cmd.Flags().Changed("watch") {
+				run(cmd, args)
+				return
+			}
+			if runningScript {
+				dieUsage("--watch cannot be used in a script.")
+			}
+			if watchInterval <= 0 {
+				dieUsage("The --watch interval must be positive.")
+			}
+			if jsonOutput {
+				dieUsage("--watch cannot be used with --json.")
+			} else if quietOutput {
+				dieUsage("--watch cannot be used with --quiet.")
+			}
+			watch(cmd.CommandPath(), watchInterval, func() { run(cmd, args) })
+		}
+	}
+}
+
+// watch prints the output of fn every interval, replacing its previous output.
+// It never returns; siac exits when it is interrupted.
+func watch(title string, interval time.Duration, fn func()) {
+	for {
+		out := refresh(fn)
+		fmt.Print(clearScreen)
+		fmt.Printf("Every %v: %v    %v\n\n", interval, title, time.Now().Format("2006-01-02 15:04:05"))
+		os.Stdout.Write(out)
+		time.Sleep(interval)
+	}
+}
+
+// refresh runs fn and returns what it printed to stdout. If fn calls die, the
+// error is returned as the output.
+func refresh(fn func()) []byte {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return []byte(fmt.Sprintln("Could not capture output:", err))
+	}
+	outChan := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		r.Close()
+		outChan <- buf.Bytes()
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	watching = true
+	func() {
+		defer func() {
+			if e := recover(); e != nil {
+				we, ok := e.(watchError)
+				if !ok {
+					panic(e)
+				}
+				fmt.Fprint(w, we)
+			}
+		}()
+		fn()
+	}()
+	watching = false
+	os.Stdout = stdout
+	w.Close()
+	return <-outChan
+}
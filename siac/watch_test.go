@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TestRefresh checks that refresh captures the output of a command, and the
+// errors that would make it exit.
+func TestRefresh(t *testing.T) {
+	out := refresh(func() { fmt.Println("Height: 7") })
+	if string(out) != "Height: 7\n" {
+		t.Errorf("expected the output of the command, got %q", out)
+	}
+	out = refresh(func() {
+		fmt.Println("Synced: Yes")
+		die("Could not get wallet status:", "no response from daemon")
+	})
+	if string(out) != "Synced: Yes\nCould not get wallet status: no response from daemon\n" {
+		t.Errorf("expected the output and the error of the command, got %q", out)
+	}
+	if watching {
+		t.Error("watching was not reset")
+	}
+}
+
+// TestWatchFlag checks the parsing of --watch with and without an interval.
+func TestWatchFlag(t *testing.T) {
+	defer func() { watchInterval = 0 }()
+	ran := false
+	cmd := &cobra.Command{Use: "status", Run: func(*cobra.Command, []string) { ran = true }}
+	addWatchFlag(cmd)
+
+	tests := []struct {
+		args     []string
+		interval time.Duration
+	}{
+		{[]string{"--watch"}, defaultWatchInterval},
+		{[]string{"--watch=10s"}, 10 * time.Second},
+	}
+	for _, test := range tests {
+		if err := cmd.ParseFlags(test.args); err != nil {
+			t.Fatal(err)
+		}
+		if watchInterval != test.interval {
+			t.Errorf("%v: expected an interval of %v, got %v", test.args, test.interval, watchInterval)
+		}
+	}
+
+	// Without --watch, the command runs once.
+	cmd = &cobra.Command{Use: "status", Run: func(*cobra.Command, []string) { ran = true }}
+	addWatchFlag(cmd)
+	cmd.Run(cmd, nil)
+	if !ran {
+		t.Error("command did not run")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// siad writes an access log of the API to the file named by --api-log. The
+// log is off by default. Passwords, seeds and keyfile paths are redacted from
+// the logged requests. The requests are logged at the info level of the api
+// module.
+
+// processAPILog makes the path of the access log absolute.
+func processAPILog(config Config) (Config, error) {
+	if config.Siad.APILog == "" {
+		return config, nil
+	}
+	path, err := filepath.Abs(config.Siad.APILog)
+	if err != nil {
+		return config, err
+	}
+	config.Siad.APILog = path
+	return config, nil
+}
+
+// enableAccessLog makes the server log every request to the access log at
+// path. The returned logger must be closed after the server has stopped. It
+// must be called before Serve, after the other middleware is enabled, so that
+// the requests rejected by the middleware are logged as well.
+func (srv *Server) enableAccessLog(path string) (*persist.Logger, error) {
+	log, err := persist.NewModuleFileLogger("api", path)
+	if err != nil {
+		return nil, err
+	}
+	srv.httpServer.Handler = api.LogRequests(srv.httpServer.Handler, log)
+	return log, nil
+}
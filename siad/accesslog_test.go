@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// TestAccessLog checks that the requests to the daemon routes are written to
+// the access log, and that the path of the log is made absolute.
+func TestAccessLog(t *testing.T) {
+	var config Config
+	config.Siad.APILog = "api.log"
+	config, err := processAPILog(config)
+	if err != nil || !filepath.IsAbs(config.Siad.APILog) {
+		t.Fatal("expected an absolute log path, got", config.Siad.APILog, err)
+	}
+
+	dir := build.TempDir("siad", t.Name())
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "api.log")
+	srv, err := NewServer("", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := srv.enableAccessLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/daemon/version", nil)
+	req.Header.Set("User-Agent", "Sia-Agent")
+	srv.httpServer.Handler.ServeHTTP(httptest.NewRecorder(), req)
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "GET /daemon/version 200 ") {
+		t.Fatalf("request was not logged:\n%s", b)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NebulousLabs/Sia/persist"
+
+	"github.com/NebulousLabs/fastrand"
+	"github.com/bgentry/speakeasy"
+	"golang.org/x/crypto/argon2"
+)
+
+// The API password is set with --api-password, the SIA_API_PASSWORD
+// environment variable, or at a prompt with --authenticate-api. siad saves a
+// salted hash of the password in the sia directory, never the password
+// itself. Once the hash is saved, the API stays protected: siad asks for the
+// password at startup if it is not given, and refuses to start if the
+// password does not match the hash. To change or remove the password, delete
+// the hash file.
+
+const (
+	// apiPasswordFile is the name of the file in the sia directory that
+	// contains the hash of the API password.
+	apiPasswordFile = "apipassword.json"
+
+	// apiPasswordSaltSize is the size of the salt of the API password hash.
+	apiPasswordSaltSize = 16
+)
+
+var (
+	// apiPasswordMetadata contains the header and version strings that
+	// identify the API password file.
+	apiPasswordMetadata = persist.Metadata{
+		Header:  "Siad API Password",
+		Version: "1.3.1",
+	}
+
+	// errAPIPasswordMismatch is returned when the API password does not match
+	// the saved hash.
+	errAPIPasswordMismatch = errors.New("the API password does not match the password saved in " + apiPasswordFile + "; delete the file to set a new password")
+
+	// errBlankAPIPassword is returned when a blank API password is entered.
+	errBlankAPIPassword = errors.New("password cannot be blank")
+)
+
+// apiPasswordHash is the salted argon2id hash of the API password.
+type apiPasswordHash struct {
+	Salt []byte `json:"salt"`
+	Hash []byte `json:"hash"`
+}
+
+// hashAPIPassword hashes an API password with a salt.
+func hashAPIPassword(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+}
+
+// newAPIPasswordHash hashes an API password with a random salt.
+func newAPIPasswordHash(password string) apiPasswordHash {
+	salt := fastrand.Bytes(apiPasswordSaltSize)
+	return apiPasswordHash{
+		Salt: salt,
+		Hash: hashAPIPassword(password, salt),
+	}
+}
+
+// matches returns whether password is the password that was hashed.
+func (ph apiPasswordHash) matches(password string) bool {
+	return subtle.ConstantTimeCompare(hashAPIPassword(password, ph.Salt), ph.Hash) == 1
+}
+
+// loadAPIPasswordHash loads the hash of the API password saved in the sia
+// directory. The bool is false if no hash was saved.
+func loadAPIPasswordHash(siaDir string) (apiPasswordHash, bool, error) {
+	var ph apiPasswordHash
+	err := persist.LoadJSON(apiPasswordMetadata, &ph, filepath.Join(siaDir, apiPasswordFile))
+	if os.IsNotExist(err) {
+		return apiPasswordHash{}, false, nil
+	} else if err != nil {
+		return apiPasswordHash{}, false, err
+	}
+	return ph, true, nil
+}
+
+// processAPIPassword returns the API password, checked against the hash saved
+// in the sia directory. The password is asked for if --authenticate-api was
+// given or a hash was saved, but no password was. The hash of a new password
+// is saved. An empty password disables authentication.
+func processAPIPassword(config Config, ask func(string) (string, error)) (string, error) {
+	stored, exists, err := loadAPIPasswordHash(config.Siad.SiaDir)
+	if err != nil {
+		return "", fmt.Errorf("unable to load the API password hash: %v", err)
+	}
+	password := config.APIPassword
+	if password == "" && (config.Siad.AuthenticateAPI || exists) {
+		password, err = ask("Enter API password: ")
+		if err != nil {
+			return "", err
+		}
+		if password == "" {
+			return "", errBlankAPIPassword
+		}
+	}
+	if password == "" {
+		return "", nil
+	}
+	if exists {
+		if !stored.matches(password) {
+			return "", errAPIPasswordMismatch
+		}
+		return password, nil
+	}
+	err = os.MkdirAll(config.Siad.SiaDir, 0700)
+	if err != nil {
+		return "", err
+	}
+	err = persist.SaveJSON(apiPasswordMetadata, newAPIPasswordHash(password), filepath.Join(config.Siad.SiaDir, apiPasswordFile))
+	if err != nil {
+		return "", fmt.Errorf("unable to save the API password hash: %v", err)
+	}
+	return password, nil
+}
+
+// askAPIPassword prompts for the API password without echoing it.
+func askAPIPassword(prompt string) (string, error) {
+	return speakeasy.Ask(prompt)
+}
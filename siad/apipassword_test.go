@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// TestProcessAPIPassword checks that the API password is hashed on first use,
+// checked against the hash afterwards, and asked for when it is not given.
+func TestProcessAPIPassword(t *testing.T) {
+	var config Config
+	config.Siad.SiaDir = build.TempDir("siad", t.Name())
+	os.RemoveAll(config.Siad.SiaDir)
+
+	asked := 0
+	answer := ""
+	ask := func(string) (string, error) {
+		asked++
+		return answer, nil
+	}
+
+	// Without a password, authentication is disabled and nothing is saved.
+	password, err := processAPIPassword(config, ask)
+	if err != nil || password != "" || asked != 0 {
+		t.Fatal("expected no password, got", password, err, asked)
+	}
+	if _, err := os.Stat(filepath.Join(config.Siad.SiaDir, apiPasswordFile)); !os.IsNotExist(err) {
+		t.Fatal("hash was saved without a password:", err)
+	}
+
+	// A new password is hashed and saved, not stored in plain text.
+	config.APIPassword = "foo"
+	password, err = processAPIPassword(config, ask)
+	if err != nil || password != "foo" {
+		t.Fatal("expected foo, got", password, err)
+	}
+	stored, exists, err := loadAPIPasswordHash(config.Siad.SiaDir)
+	if err != nil || !exists {
+		t.Fatal("hash was not saved:", exists, err)
+	}
+	if !stored.matches("foo") || stored.matches("bar") {
+		t.Fatal("saved hash does not match the password")
+	}
+
+	// A different password is rejected.
+	config.APIPassword = "bar"
+	if _, err := processAPIPassword(config, ask); err != errAPIPasswordMismatch {
+		t.Fatal("expected errAPIPasswordMismatch, got", err)
+	}
+
+	// Once a hash is saved, the password is asked for if it is not given.
+	config.APIPassword = ""
+	if _, err := processAPIPassword(config, ask); err != errBlankAPIPassword || asked != 1 {
+		t.Fatal("expected errBlankAPIPassword after asking, got", err, asked)
+	}
+	answer = "foo"
+	password, err = processAPIPassword(config, ask)
+	if err != nil || password != "foo" || asked != 2 {
+		t.Fatal("expected foo after asking, got", password, err, asked)
+	}
+}
+
+// TestAPIPasswordHashSalt checks that the same password is hashed with a
+// different salt each time.
+func TestAPIPasswordHashSalt(t *testing.T) {
+	ph1 := newAPIPasswordHash("foo")
+	ph2 := newAPIPasswordHash("foo")
+	if string(ph1.Hash) == string(ph2.Hash) {
+		t.Fatal("hashes of the same password should differ")
+	}
+	if !ph1.matches("foo") || !ph2.matches("foo") {
+		t.Fatal("hashes do not match the password")
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/spf13/pflag"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// siad reads its settings from the config file named by --config, in a
+// subset of TOML. The keys before the first section are the names of the
+// flags of siad, with '-' or '_' between words, and a flag given on the
+// command line overrides the setting of the file. The keys of the [host]
+// section are the parameters of /host [POST], and are applied to the host
+// after it is loaded; if the host refuses them, siad warns and starts anyway.
+// Values are quoted strings, booleans, numbers or arrays of strings on one
+// line, which are joined with commas. For example:
+//
+//	sia-directory = "/var/lib/sia"
+//	modules = ["gateway", "consensus", "transactionpool", "wallet", "host"]
+//	api-password = "foo"
+//	max_inbound_per_ip = 4
+//
+//	[host]
+//	acceptingcontracts = true
+//	netaddress = "host.example.com:9982"
+//	minstorageprice = "1000000000"
+//
+// The effective settings are served at /daemon/config, with the API password
+// redacted.
+
+// Sources of the settings reported by /daemon/config.
+const (
+	sourceDefault     = "default"
+	sourceEnvironment = "environment"
+	sourceFile        = "file"
+	sourceFlag        = "flag"
+)
+
+var (
+	// errConfigHostModule is returned if the config file has host settings
+	// but the host module is not loaded.
+	errConfigHostModule = errors.New("the [host] settings of the config file require the host module")
+
+	// unconfigurableFlags are the flags that cannot be set by the config
+	// file.
+	unconfigurableFlags = []string{"config", "help"}
+)
+
+type (
+	// DaemonConfig lists the effective settings of the daemon.
+	DaemonConfig struct {
+		File     string                `json:"file"`
+		Settings []DaemonConfigSetting `json:"settings"`
+		Host     map[string]string     `json:"host"`
+	}
+
+	// DaemonConfigSetting is the value of a flag of the daemon, and where the
+	// value came from: the default, the environment, the config file or the
+	// command line.
+	DaemonConfigSetting struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Source string `json:"source"`
+	}
+
+	// configEntry is a setting of the config file.
+	configEntry struct {
+		line  int
+		key   string
+		value string
+	}
+
+	// configFile is a parsed config file.
+	configFile struct {
+		settings []configEntry
+		host     url.Values
+	}
+)
+
+// parseConfigString parses a quoted string at the start of s, and returns it
+// with the rest of s.
+func parseConfigString(s string) (value, rest string, err error) {
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", errors.New("invalid string " + s[:i+1])
+			}
+			return value, s[i+1:], nil
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+// parseConfigValue parses the value of a setting. Arrays are joined with
+// commas.
+func parseConfigValue(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	var value, rest string
+	var err error
+	switch {
+	case s == "":
+		return "", errors.New("missing value")
+	case s[0] == '"' || s[0] == '\'':
+		value, rest, err = parseConfigString(s)
+	case s[0] == '[':
+		var items []string
+		rest = strings.TrimSpace(s[1:])
+		for err == nil && !strings.HasPrefix(rest, "]") {
+			if rest == "" {
+				return "", errors.New("unterminated array")
+			} else if rest[0] != '"' && rest[0] != '\'' {
+				return "", errors.New("arrays must hold quoted strings")
+			}
+			var item string
+			item, rest, err = parseConfigString(rest)
+			items = append(items, item)
+			rest = strings.TrimSpace(rest)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if rest != "" && !strings.HasPrefix(rest, "]") && err == nil {
+				return "", errors.New("expected ',' or ']' in array")
+			}
+		}
+		value, rest = strings.Join(items, ","), strings.TrimPrefix(rest, "]")
+	default:
+		value = s
+		if i := strings.IndexByte(s, '#'); i >= 0 {
+			value, rest = strings.TrimSpace(s[:i]), s[i:]
+		}
+		if _, perr := strconv.ParseBool(value); perr != nil {
+			if _, perr := strconv.ParseFloat(strings.Replace(value, "_", "", -1), 64); perr != nil {
+				return "", errors.New("values must be quoted strings, booleans, numbers or arrays, not " + value)
+			}
+			value = strings.Replace(value, "_", "", -1)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && rest[0] != '#' {
+		return "", errors.New("unexpected " + rest + " after value")
+	}
+	return value, nil
+}
+
+// validConfigKey returns whether key is a bare key, made of letters, digits,
+// '-' and '_'.
+func validConfigKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseConfigFile parses a config file. Syntax outside of the supported
+// subset of TOML, unknown sections and unknown host settings are rejected
+// with the number of the offending line.
+func parseConfigFile(r io.Reader) (configFile, error) {
+	cf := configFile{host: make(url.Values)}
+	seen := make(map[string]bool)
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return configFile{}, fmt.Errorf("line %v: unterminated section", n)
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && rest[0] != '#' {
+				return configFile{}, fmt.Errorf("line %v: unexpected %v after section", n, rest)
+			}
+			section = strings.TrimSpace(line[1:end])
+			if section != "host" {
+				return configFile{}, fmt.Errorf("line %v: unknown section [%v]", n, section)
+			} else if seen["["+section+"]"] {
+				return configFile{}, fmt.Errorf("line %v: section [%v] is defined twice", n, section)
+			}
+			seen["["+section+"]"] = true
+			continue
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return configFile{}, fmt.Errorf("line %v: expected key = value", n)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if !validConfigKey(key) {
+			return configFile{}, fmt.Errorf("line %v: invalid key %q, keys are made of letters, digits, '-' and '_'", n, key)
+		}
+		value, err := parseConfigValue(line[eq+1:])
+		if err != nil {
+			return configFile{}, fmt.Errorf("line %v: %v", n, err)
+		}
+		if section == "" {
+			key = strings.Replace(key, "_", "-", -1)
+		}
+		if seen[section+"."+key] {
+			return configFile{}, fmt.Errorf("line %v: %v is set twice", n, key)
+		}
+		seen[section+"."+key] = true
+		if section == "host" {
+			if err := api.CheckHostSettings(url.Values{key: {value}}); err != nil {
+				return configFile{}, fmt.Errorf("line %v: %v", n, err)
+			}
+			cf.host.Set(key, value)
+			continue
+		}
+		cf.settings = append(cf.settings, configEntry{line: n, key: key, value: value})
+	}
+	return cf, scanner.Err()
+}
+
+// applyConfigFile sets the flags to the settings of the config file, except
+// for the flags that were given on the command line, whose values are only
+// checked. Unknown settings are rejected. It returns the names of the flags
+// that it set.
+func applyConfigFile(cf configFile, flags *pflag.FlagSet) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, e := range cf.settings {
+		f := flags.Lookup(e.key)
+		if f == nil {
+			return nil, fmt.Errorf("line %v: unknown setting %v", e.line, e.key)
+		}
+		for _, name := range unconfigurableFlags {
+			if name == e.key {
+				return nil, fmt.Errorf("line %v: %v cannot be set by the config file", e.line, e.key)
+			}
+		}
+		if f.Changed {
+			// The command line wins, but a typo in the file is still an
+			// error. Check the value and put the flag back.
+			old := f.Value.String()
+			err := f.Value.Set(e.value)
+			f.Value.Set(old)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: invalid value for %v: %v", e.line, e.key, err)
+			}
+			continue
+		}
+		if err := flags.Set(e.key, e.value); err != nil {
+			return nil, fmt.Errorf("line %v: invalid value for %v: %v", e.line, e.key, err)
+		}
+		set[e.key] = true
+	}
+	return set, nil
+}
+
+// loadConfigFile reads the config file at path and applies it to the flags.
+// It returns the absolute path of the file, the names of the flags that it
+// set, and its host settings.
+func loadConfigFile(path string, flags *pflag.FlagSet) (string, map[string]bool, url.Values, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	defer file.Close()
+	cf, err := parseConfigFile(file)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("%v: %v", path, err)
+	}
+	set, err := applyConfigFile(cf, flags)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("%v: %v", path, err)
+	}
+	if set["api-password"] && runtime.GOOS != "windows" {
+		if info, err := file.Stat(); err == nil && info.Mode().Perm()&0077 != 0 {
+			fmt.Printf("Warning: %v holds the API password but can be read by other users; restrict it with 'chmod 600 %v'\n", path, path)
+		}
+	}
+	return path, set, cf.host, nil
+}
+
+// redactSetting returns the value of a flag as it is reported by
+// /daemon/config. The API password and the password of the gateway proxy are
+// redacted.
+func redactSetting(name, value string) string {
+	switch name {
+	case "api-password":
+		if value != "" {
+			return api.RedactedValue
+		}
+	case "gateway-proxy":
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), api.RedactedValue)
+				return u.String()
+			}
+		}
+	}
+	return value
+}
+
+// effectiveSettings lists the value and source of every flag, given the
+// flags that were set by the config file.
+func effectiveSettings(flags *pflag.FlagSet, fromFile map[string]bool) []DaemonConfigSetting {
+	var settings []DaemonConfigSetting
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" {
+			return
+		}
+		s := DaemonConfigSetting{
+			Name:   f.Name,
+			Value:  f.Value.String(),
+			Source: sourceDefault,
+		}
+		switch {
+		case fromFile[f.Name]:
+			s.Source = sourceFile
+		case f.Changed:
+			s.Source = sourceFlag
+		case f.Name == "api-password" && os.Getenv("SIA_API_PASSWORD") != "":
+			s.Value, s.Source = os.Getenv("SIA_API_PASSWORD"), sourceEnvironment
+		}
+		s.Value = redactSetting(f.Name, s.Value)
+		settings = append(settings, s)
+	})
+	return settings
+}
+
+// processHostSettings checks that the host module is loaded if the config
+// file has host settings.
+func processHostSettings(config Config) error {
+	if len(config.HostSettings) > 0 && !strings.Contains(config.Siad.Modules, "h") {
+		return errConfigHostModule
+	}
+	return nil
+}
+
+// setConfig records the effective settings of the daemon, to be served at
+// /daemon/config.
+func (srv *Server) setConfig(config Config) {
+	host := make(map[string]string)
+	for name := range config.HostSettings {
+		host[name] = config.HostSettings.Get(name)
+	}
+	srv.configMu.Lock()
+	srv.config = DaemonConfig{
+		File:     config.Siad.ConfigFile,
+		Settings: config.Settings,
+		Host:     host,
+	}
+	srv.configMu.Unlock()
+}
+
+// daemonConfigHandler handles the API call that returns the effective
+// settings of the daemon.
+func (srv *Server) daemonConfigHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	srv.configMu.Lock()
+	dc := srv.config
+	srv.configMu.Unlock()
+	if dc.Settings == nil {
+		dc.Settings = []DaemonConfigSetting{}
+	}
+	if dc.Host == nil {
+		dc.Host = map[string]string{}
+	}
+	api.WriteJSON(w, dc)
+}
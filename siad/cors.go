@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// siad allows web pages to call the API directly when their origins are
+// given with --api-cors-origins. Allowing every origin with "*" lets any web
+// page use the routes that do not require the API password, so it requires an
+// API password.
+
+var (
+	// corsMethods are the HTTP methods that --api-cors-methods may allow.
+	corsMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "PATCH"}
+
+	// errCORSWildcard is returned when every origin is allowed without an
+	// API password.
+	errCORSWildcard = errors.New("cannot allow every origin with --api-cors-origins '*' without setting an api password")
+)
+
+// splitList splits a comma-separated list, dropping empty items and
+// surrounding whitespace.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// processCORS checks the origins and methods allowed by the CORS flags, and
+// returns them as normalized comma-separated lists.
+func processCORS(config Config) (Config, error) {
+	var origins []string
+	for _, o := range splitList(config.Siad.APICORSOrigins) {
+		if o == "*" {
+			if !config.Siad.AuthenticateAPI && config.APIPassword == "" {
+				return config, errCORSWildcard
+			}
+			origins = append(origins, o)
+			continue
+		}
+		u, err := url.Parse(strings.TrimSuffix(o, "/"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			return config, errors.New("Unable to parse --api-cors-origins flag, an origin must be '*' or a scheme and host such as https://example.com, got: " + o)
+		}
+		origins = append(origins, strings.ToLower(u.Scheme+"://"+u.Host))
+	}
+
+	var methods []string
+	for _, m := range splitList(config.Siad.APICORSMethods) {
+		m = strings.ToUpper(m)
+		valid := false
+		for _, cm := range corsMethods {
+			valid = valid || m == cm
+		}
+		if !valid {
+			return config, errors.New("Unable to parse --api-cors-methods flag, unrecognized method: " + m)
+		}
+		methods = append(methods, m)
+	}
+	if len(origins) > 0 && len(methods) == 0 {
+		return config, errors.New("--api-cors-methods must allow at least one method")
+	}
+
+	config.Siad.APICORSOrigins = strings.Join(origins, ",")
+	config.Siad.APICORSMethods = strings.Join(methods, ",")
+	return config, nil
+}
+
+// corsConfig returns the CORS configuration of the API.
+func corsConfig(config Config) api.CORSConfig {
+	return api.CORSConfig{
+		AllowedOrigins: splitList(config.Siad.APICORSOrigins),
+		AllowedMethods: splitList(config.Siad.APICORSMethods),
+	}
+}
+
+// enableCORS makes the server allow the cross-origin requests of c. It must
+// be called before Serve.
+func (srv *Server) enableCORS(c api.CORSConfig) {
+	srv.httpServer.Handler = api.AllowCORS(srv.httpServer.Handler, c)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestProcessCORS checks that the CORS flags are validated and normalized.
+func TestProcessCORS(t *testing.T) {
+	tests := []struct {
+		origins, methods string
+		password         bool
+		wantOrigins      string
+		wantMethods      string
+		valid            bool
+	}{
+		{"", "GET,POST", false, "", "GET,POST", true},
+		{"https://Wallet.example.com/, http://localhost:3000", " get , post", false, "https://wallet.example.com,http://localhost:3000", "GET,POST", true},
+		{"*", "GET", true, "*", "GET", true},
+		{"*", "GET", false, "", "", false},
+		{"wallet.example.com", "GET", false, "", "", false},
+		{"ftp://wallet.example.com", "GET", false, "", "", false},
+		{"https://wallet.example.com/path", "GET", false, "", "", false},
+		{"https://wallet.example.com", "CONNECT", false, "", "", false},
+		{"https://wallet.example.com", "", false, "", "", false},
+	}
+	for _, test := range tests {
+		var config Config
+		config.Siad.APICORSOrigins = test.origins
+		config.Siad.APICORSMethods = test.methods
+		if test.password {
+			config.APIPassword = "foo"
+		}
+		config, err := processCORS(config)
+		if (err == nil) != test.valid {
+			t.Errorf("processCORS(%q, %q): unexpected error %v", test.origins, test.methods, err)
+			continue
+		}
+		if test.valid && (config.Siad.APICORSOrigins != test.wantOrigins || config.Siad.APICORSMethods != test.wantMethods) {
+			t.Errorf("processCORS(%q, %q): got %q, %q", test.origins, test.methods, config.Siad.APICORSOrigins, config.Siad.APICORSMethods)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// crashReportsDir is the directory within the sia directory that crash
+// reports are written to.
+const crashReportsDir = "crashes"
+
+var (
+	// ipv4Regexp and ipv6Regexp match the IP addresses that may appear in the
+	// message of a crash report, such as the address of a peer or a renter.
+	ipv4Regexp = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}\b`)
+	ipv6Regexp = regexp.MustCompile(`(?:[0-9a-fA-F]{0,4}:){2,7}[0-9a-fA-F]{1,4}\b`)
+)
+
+// anonymizeCrashReport removes the information that could identify the user
+// from a crash report before it is uploaded: the sia directory and home
+// directory of the user, and any IP addresses.
+func anonymizeCrashReport(cr build.CrashReport, siaDir string) build.CrashReport {
+	var replacements []string
+	if siaDir != "" {
+		replacements = append(replacements, siaDir, "<siadir>")
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+		replacements = append(replacements, home, "<home>")
+	}
+	scrub := func(s string) string {
+		s = strings.NewReplacer(replacements...).Replace(s)
+		s = ipv4Regexp.ReplaceAllString(s, "<ip>")
+		return ipv6Regexp.ReplaceAllString(s, "<ip>")
+	}
+	cr.Message = scrub(cr.Message)
+	cr.Stack = scrub(cr.Stack)
+	return cr
+}
+
+// uploadCrashReports sends the crash reports in dir that have not been
+// uploaded yet to url, after anonymizing them. Uploaded reports are marked so
+// that they are only sent once.
+func uploadCrashReports(dir, siaDir, url string) error {
+	reports, err := build.ReadCrashReports(dir)
+	if err != nil {
+		return err
+	}
+	for _, cr := range reports {
+		if cr.Uploaded {
+			continue
+		}
+		b, err := json.Marshal(anonymizeCrashReport(cr, siaDir))
+		if err != nil {
+			return err
+		}
+		resp, err := http.Post(url, "application/json", bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("crash report server returned %v", resp.Status)
+		}
+		cr.Uploaded = true
+		if err := build.WriteCrashReport(dir, cr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -23,7 +23,6 @@ import (
 	"github.com/NebulousLabs/Sia/modules/wallet"
 	"github.com/NebulousLabs/Sia/profile"
 
-	"github.com/bgentry/speakeasy"
 	"github.com/spf13/cobra"
 )
 
@@ -31,8 +30,9 @@ import (
 // sane, secure system.
 func verifyAPISecurity(config Config) error {
 	// Make sure that only the loopback address is allowed unless the
-	// --disable-api-security flag has been used.
-	if !config.Siad.AllowAPIBind {
+	// --disable-api-security flag has been used. The API may be served only
+	// on a Unix socket.
+	if !config.Siad.AllowAPIBind && config.Siad.APIaddr != "" {
 		addr := modules.NetAddress(config.Siad.APIaddr)
 		if !addr.IsLoopback() {
 			if addr.Host() == "" {
@@ -44,8 +44,8 @@ func verifyAPISecurity(config Config) error {
 	}
 
 	// If the --disable-api-security flag is used, enforce that
-	// --authenticate-api must also be used.
-	if config.Siad.AllowAPIBind && !config.Siad.AuthenticateAPI {
+	// --authenticate-api or --api-password must also be used.
+	if config.Siad.AllowAPIBind && !config.Siad.AuthenticateAPI && config.APIPassword == "" {
 		return errors.New("cannot use --disable-api-security without setting an api password")
 	}
 	return nil
@@ -63,8 +63,13 @@ func processNetAddr(addr string) string {
 
 // processModules makes the modules string lowercase to make checking if a
 // module in the string easier, and returns an error if the string contains an
-// invalid module character.
+// invalid module character. A comma-separated list of module names is
+// converted to module letters.
 func processModules(modules string) (string, error) {
+	modules, err := expandModuleNames(modules)
+	if err != nil {
+		return "", err
+	}
 	modules = strings.ToLower(modules)
 	validModules := "cghmrtwe"
 	invalidModules := modules
@@ -80,7 +85,7 @@ func processModules(modules string) (string, error) {
 // processProfileFlags checks that the flags given for profiling are valid.
 func processProfileFlags(profile string) (string, error) {
 	profile = strings.ToLower(profile)
-	validProfiles := "cmt"
+	validProfiles := "cmtp"
 
 	invalidProfiles := profile
 	for _, p := range validProfiles {
@@ -92,17 +97,69 @@ func processProfileFlags(profile string) (string, error) {
 	return profile, nil
 }
 
+// processWallets checks that the comma-separated list of named wallets
+// contains only unique names that are safe to use as directory names, and
+// returns the list with surrounding whitespace removed.
+func processWallets(wallets string) (string, error) {
+	if strings.TrimSpace(wallets) == "" {
+		return "", nil
+	}
+	seen := make(map[string]struct{})
+	var names []string
+	for _, name := range strings.Split(wallets, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return "", errors.New("Unable to parse --wallets flag, wallet names cannot be empty")
+		}
+		for _, c := range name {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return "", errors.New("Unable to parse --wallets flag, wallet names may only contain letters, numbers, '-' and '_': " + name)
+			}
+		}
+		if _, exists := seen[name]; exists {
+			return "", errors.New("Unable to parse --wallets flag, duplicate wallet name: " + name)
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return strings.Join(names, ","), nil
+}
+
+// recoverRenterModules are the modules loaded by --recover-renter. The
+// gateway is loaded so that the other modules can be created, but it never
+// connects to peers.
+const recoverRenterModules = "cgtwr"
+
 // processConfig checks the configuration values and performs cleanup on
 // incorrect-but-allowed values.
 func processConfig(config Config) (Config, error) {
-	var err1, err2 error
+	var err1, err2, err4, err5, err6, err7, err8, err9, err10 error
+	if config.Siad.RecoverRenter {
+		config.Siad.Modules = recoverRenterModules
+		config.Siad.NoBootstrap = true
+	}
 	config.Siad.APIaddr = processNetAddr(config.Siad.APIaddr)
 	config.Siad.RPCaddr = processNetAddr(config.Siad.RPCaddr)
 	config.Siad.HostAddr = processNetAddr(config.Siad.HostAddr)
 	config.Siad.Modules, err1 = processModules(config.Siad.Modules)
+	if err1 == nil {
+		err1 = checkModuleDependencies(config.Siad.Modules)
+	}
 	config.Siad.Profile, err2 = processProfileFlags(config.Siad.Profile)
 	err3 := verifyAPISecurity(config)
-	err := build.JoinErrors([]error{err1, err2, err3}, ", and ")
+	config.Siad.Wallets, err4 = processWallets(config.Siad.Wallets)
+	if err4 == nil && config.Siad.Wallets != "" && !strings.Contains(config.Siad.Modules, "w") {
+		err4 = errors.New("the --wallets flag requires the wallet module")
+	}
+	config.Siad.Features, err5 = processFeatures(config.Siad.Features)
+	config, err6 = processTLS(config)
+	config, err7 = processCORS(config)
+	config, err8 = processSocket(config)
+	config, err9 = processRateLimits(config)
+	config, err10 = processAPILog(config)
+	err11 := processHostSettings(config)
+	err12 := processLogging(config)
+	err := build.JoinErrors([]error{err1, err2, err3, err4, err5, err6, err7, err8, err9, err10, err11, err12}, ", and ")
 	if err != nil {
 		return Config{}, err
 	}
@@ -112,15 +169,8 @@ func processConfig(config Config) (Config, error) {
 // startDaemon uses the config parameters to initialize Sia modules and start
 // siad.
 func startDaemon(config Config) (err error) {
-	// Prompt user for API password.
-	if config.Siad.AuthenticateAPI {
-		config.APIPassword, err = speakeasy.Ask("Enter API password: ")
-		if err != nil {
-			return err
-		}
-		if config.APIPassword == "" {
-			return errors.New("password cannot be blank")
-		}
+	if config.APIPassword == "" {
+		config.APIPassword = os.Getenv("SIA_API_PASSWORD")
 	}
 
 	// Process the config variables after they are parsed by cobra.
@@ -129,8 +179,32 @@ func startDaemon(config Config) (err error) {
 		return err
 	}
 
+	// Check the API password against the saved hash, prompting for it if
+	// necessary.
+	config.APIPassword, err = processAPIPassword(config, askAPIPassword)
+	if err != nil {
+		return err
+	}
+
+	// Record crash reports in the sia directory, so that they survive a
+	// crash of the daemon. Reports are only uploaded if the user opted in.
+	crashDir := filepath.Join(config.Siad.SiaDir, crashReportsDir)
+	build.SetCrashDir(crashDir)
+	defer build.CaptureCrash("siad")
+	if config.Siad.CrashReportURL != "" {
+		go func() {
+			err := uploadCrashReports(crashDir, config.Siad.SiaDir, config.Siad.CrashReportURL)
+			if err != nil {
+				fmt.Println("Unable to upload crash reports:", err)
+			}
+		}()
+	}
+
 	// Print the Siad Version
 	fmt.Println("Sia Daemon v" + build.Version)
+	if config.Siad.RecoverRenter {
+		fmt.Println("Starting in renter recovery mode. The node will stay offline, and files can only be downloaded from existing contracts.")
+	}
 	// Print a startup message.
 	fmt.Println("Loading...")
 	loadStart := time.Now()
@@ -141,6 +215,51 @@ func startDaemon(config Config) (err error) {
 	if err != nil {
 		return err
 	}
+	srv.crashDir = crashDir
+	srv.siaDir = config.Siad.SiaDir
+	srv.setConfig(config)
+	// Close the modules that were loaded if siad fails to start.
+	defer srv.closeModules()
+	if strings.Contains(config.Siad.Profile, "p") {
+		srv.setProfiling(true)
+	}
+	if config.Siad.APISocket != "" {
+		if err := srv.listenSocket(config.Siad.APISocket); err != nil {
+			srv.Close()
+			return fmt.Errorf("unable to listen on the API socket: %v", err)
+		}
+		fmt.Println("Serving the API on", config.Siad.APISocket)
+	}
+	if config.Siad.APITLS {
+		cert, err := loadAPICertificate(config)
+		if err != nil {
+			srv.Close()
+			return fmt.Errorf("unable to load the API certificate: %v", err)
+		}
+		srv.enableTLS(cert)
+		fmt.Println("Serving the API over HTTPS, certificate fingerprint (SHA-256):", certificateFingerprint(cert))
+	}
+	if config.Siad.APICORSOrigins != "" {
+		srv.enableCORS(corsConfig(config))
+	}
+	if config.Siad.APILog != "" {
+		log, err := srv.enableAccessLog(config.Siad.APILog)
+		if err != nil {
+			srv.Close()
+			return fmt.Errorf("unable to open the API log: %v", err)
+		}
+		defer log.Close()
+		fmt.Println("Logging API requests to", config.Siad.APILog)
+	}
+
+	// Set the log levels before the modules open their logs.
+	configureLogging(config)
+
+	// Restore the feature flags before the modules check them.
+	err = loadFeatures(config.Siad.SiaDir, config.Siad.Features)
+	if err != nil {
+		return err
+	}
 
 	servErrs := make(chan error)
 	go func() {
@@ -152,130 +271,107 @@ func startDaemon(config Config) (err error) {
 	var g modules.Gateway
 	if strings.Contains(config.Siad.Modules, "g") {
 		i++
-		fmt.Printf("(%d/%d) Loading gateway...\n", i, len(config.Siad.Modules))
-		g, err = gateway.New(config.Siad.RPCaddr, !config.Siad.NoBootstrap, filepath.Join(config.Siad.SiaDir, modules.GatewayDir))
+		srv.setLoading(i, len(config.Siad.Modules), "gateway")
+		if config.Siad.RecoverRenter {
+			g, err = gateway.NewOffline(filepath.Join(config.Siad.SiaDir, modules.GatewayDir))
+		} else if config.Siad.GatewayProxy != "" {
+			g, err = gateway.NewWithProxy(config.Siad.RPCaddr, !config.Siad.NoBootstrap, filepath.Join(config.Siad.SiaDir, modules.GatewayDir), config.Siad.GatewayProxy)
+		} else {
+			g, err = gateway.New(config.Siad.RPCaddr, !config.Siad.NoBootstrap, filepath.Join(config.Siad.SiaDir, modules.GatewayDir))
+		}
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing gateway...")
-			err := g.Close()
-			if err != nil {
-				fmt.Println("Error during gateway shutdown:", err)
-			}
-		}()
+		srv.addModule("gateway", g)
+		if err := g.SetPeerLimits(config.Siad.GatewayPeerLimits); err != nil {
+			return err
+		}
 	}
 	var cs modules.ConsensusSet
 	if strings.Contains(config.Siad.Modules, "c") {
 		i++
-		fmt.Printf("(%d/%d) Loading consensus...\n", i, len(config.Siad.Modules))
+		srv.setLoading(i, len(config.Siad.Modules), "consensus")
 		cs, err = consensus.New(g, !config.Siad.NoBootstrap, filepath.Join(config.Siad.SiaDir, modules.ConsensusDir))
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing consensus...")
-			err := cs.Close()
-			if err != nil {
-				fmt.Println("Error during consensus set shutdown:", err)
-			}
-		}()
+		srv.addModule("consensus", cs)
 	}
-	var e modules.Explorer
-	if strings.Contains(config.Siad.Modules, "e") {
+	var tpool modules.TransactionPool
+	if strings.Contains(config.Siad.Modules, "t") {
 		i++
-		fmt.Printf("(%d/%d) Loading explorer...\n", i, len(config.Siad.Modules))
-		e, err = explorer.New(cs, filepath.Join(config.Siad.SiaDir, modules.ExplorerDir))
+		srv.setLoading(i, len(config.Siad.Modules), "transaction pool")
+		tpool, err = transactionpool.New(cs, g, filepath.Join(config.Siad.SiaDir, modules.TransactionPoolDir))
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing explorer...")
-			err := e.Close()
-			if err != nil {
-				fmt.Println("Error during explorer shutdown:", err)
-			}
-		}()
+		srv.addModule("transaction pool", tpool)
 	}
-	var tpool modules.TransactionPool
-	if strings.Contains(config.Siad.Modules, "t") {
+	var e modules.Explorer
+	if strings.Contains(config.Siad.Modules, "e") {
 		i++
-		fmt.Printf("(%d/%d) Loading transaction pool...\n", i, len(config.Siad.Modules))
-		tpool, err = transactionpool.New(cs, g, filepath.Join(config.Siad.SiaDir, modules.TransactionPoolDir))
+		srv.setLoading(i, len(config.Siad.Modules), "explorer")
+		e, err = explorer.New(cs, tpool, filepath.Join(config.Siad.SiaDir, modules.ExplorerDir))
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing transaction pool...")
-			err := tpool.Close()
-			if err != nil {
-				fmt.Println("Error during transaction pool shutdown:", err)
-			}
-		}()
+		srv.addModule("explorer", e)
 	}
 	var w modules.Wallet
 	if strings.Contains(config.Siad.Modules, "w") {
 		i++
-		fmt.Printf("(%d/%d) Loading wallet...\n", i, len(config.Siad.Modules))
+		srv.setLoading(i, len(config.Siad.Modules), "wallet")
 		w, err = wallet.New(cs, tpool, filepath.Join(config.Siad.SiaDir, modules.WalletDir))
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing wallet...")
-			err := w.Close()
+		srv.addModule("wallet", w)
+	}
+	namedWallets := make(map[string]modules.Wallet)
+	if config.Siad.Wallets != "" {
+		for _, name := range strings.Split(config.Siad.Wallets, ",") {
+			fmt.Printf("Loading wallet '%v'...\n", name)
+			nw, err := wallet.New(cs, tpool, filepath.Join(config.Siad.SiaDir, modules.NamedWalletsDir, name))
 			if err != nil {
-				fmt.Println("Error during wallet shutdown:", err)
+				return err
 			}
-		}()
+			namedWallets[name] = nw
+			srv.addModule("wallet '"+name+"'", nw)
+		}
 	}
 	var m modules.Miner
 	if strings.Contains(config.Siad.Modules, "m") {
 		i++
-		fmt.Printf("(%d/%d) Loading miner...\n", i, len(config.Siad.Modules))
+		srv.setLoading(i, len(config.Siad.Modules), "miner")
 		m, err = miner.New(cs, tpool, w, filepath.Join(config.Siad.SiaDir, modules.MinerDir))
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing miner...")
-			err := m.Close()
-			if err != nil {
-				fmt.Println("Error during miner shutdown:", err)
-			}
-		}()
+		srv.addModule("miner", m)
 	}
 	var h modules.Host
 	if strings.Contains(config.Siad.Modules, "h") {
 		i++
-		fmt.Printf("(%d/%d) Loading host...\n", i, len(config.Siad.Modules))
+		srv.setLoading(i, len(config.Siad.Modules), "host")
 		h, err = host.New(cs, tpool, w, config.Siad.HostAddr, filepath.Join(config.Siad.SiaDir, modules.HostDir))
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing host...")
-			err := h.Close()
-			if err != nil {
-				fmt.Println("Error during host shutdown:", err)
-			}
-		}()
+		srv.addModule("host", h)
 	}
 	var r modules.Renter
 	if strings.Contains(config.Siad.Modules, "r") {
 		i++
-		fmt.Printf("(%d/%d) Loading renter...\n", i, len(config.Siad.Modules))
-		r, err = renter.New(g, cs, w, tpool, filepath.Join(config.Siad.SiaDir, modules.RenterDir))
+		srv.setLoading(i, len(config.Siad.Modules), "renter")
+		if config.Siad.RecoverRenter {
+			r, err = renter.NewRecovery(g, cs, w, tpool, filepath.Join(config.Siad.SiaDir, modules.RenterDir))
+		} else {
+			r, err = renter.New(g, cs, w, tpool, filepath.Join(config.Siad.SiaDir, modules.RenterDir))
+		}
 		if err != nil {
 			return err
 		}
-		defer func() {
-			fmt.Println("Closing renter...")
-			err := r.Close()
-			if err != nil {
-				fmt.Println("Error during renter shutdown:", err)
-			}
-		}()
+		srv.addModule("renter", r)
 	}
 
 	// Create the Sia API
@@ -291,9 +387,29 @@ func startDaemon(config Config) (err error) {
 		tpool,
 		w,
 	)
+	limits, err := parseRateLimits(config.Siad.APIRateLimit)
+	if err != nil {
+		return err
+	}
+	if err := a.SetRateLimits(limits); err != nil {
+		return err
+	}
+	for name, nw := range namedWallets {
+		err = a.AddWallet(name, nw)
+		if err != nil {
+			return err
+		}
+	}
+	// The host can refuse its settings, e.g. it cannot accept contracts
+	// before the wallet has been unlocked once, which must not keep siad
+	// from starting.
+	if err := a.SetHostSettings(config.HostSettings); err != nil {
+		fmt.Println("Warning: unable to apply the host settings of the config file:", err)
+	}
 
 	// connect the API to the server
-	srv.mux.Handle("/", a)
+	srv.mux.Handle("/", srv.trackRequests(a))
+	srv.setReadinessChecks(a.ReadinessChecks)
 
 	// stop the server if a kill signal is caught
 	sigChan := make(chan os.Signal, 1)
@@ -301,14 +417,23 @@ func startDaemon(config Config) (err error) {
 	go func() {
 		<-sigChan
 		fmt.Println("\rCaught stop signal, quitting...")
-		srv.Close()
+		srv.requestStop()
 	}()
 
 	// Print a 'startup complete' message.
+	srv.setLoaded()
 	startupTime := time.Since(loadStart)
 	fmt.Println("Finished loading in", startupTime.Seconds(), "seconds")
 
-	err = <-servErrs
+	// Wait for a stop request, and close the modules before the API stops
+	// serving, so that it can report the progress of the shutdown.
+	select {
+	case <-srv.stopChan:
+		srv.shutdownModules()
+		srv.Close()
+		err = <-servErrs
+	case err = <-servErrs:
+	}
 	if err != nil {
 		build.Critical(err)
 	}
@@ -318,6 +443,19 @@ func startDaemon(config Config) (err error) {
 
 // startDaemonCmd is a passthrough function for startDaemon.
 func startDaemonCmd(cmd *cobra.Command, _ []string) {
+	// Read the settings that were not given as flags from the config file.
+	var fromFile map[string]bool
+	if globalConfig.Siad.ConfigFile != "" {
+		path, set, host, err := loadConfigFile(globalConfig.Siad.ConfigFile, cmd.Flags())
+		if err != nil {
+			die("Unable to read the config file:", err)
+		}
+		globalConfig.Siad.ConfigFile = path
+		globalConfig.HostSettings = host
+		fromFile = set
+	}
+	globalConfig.Settings = effectiveSettings(cmd.Flags(), fromFile)
+
 	var profileCPU, profileMem, profileTrace bool
 
 	profileCPU = strings.Contains(globalConfig.Siad.Profile, "c")
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -47,6 +48,9 @@ func TestUnitProcessModules(t *testing.T) {
 		{"T", "t"},
 		{"W", "w"},
 		{"E", "e"},
+		{"gateway,consensus,explorer", "gce"},
+		{"Gateway, tpool", "gt"},
+		{"wallet", "w"},
 	}
 	for _, testVal := range testVals {
 		out, err := processModules(testVal.in)
@@ -59,7 +63,7 @@ func TestUnitProcessModules(t *testing.T) {
 	}
 
 	// Test invalid modules.
-	invalidModules := []string{"abdfijklnopqsuvxyz", "cghmrtwez", "cz", "z", "cc", "ccz", "ccm", "cmm", "ccmm"}
+	invalidModules := []string{"abdfijklnopqsuvxyz", "cghmrtwez", "cz", "z", "cc", "ccz", "ccm", "cmm", "ccmm", "gateway,foo", "gateway,", "wallet,wallet"}
 	for _, invalidModule := range invalidModules {
 		_, err := processModules(invalidModule)
 		if err == nil {
@@ -68,6 +72,25 @@ func TestUnitProcessModules(t *testing.T) {
 	}
 }
 
+// TestCheckModuleDependencies checks that every module must be loaded with
+// the modules it requires.
+func TestCheckModuleDependencies(t *testing.T) {
+	for _, modules := range []string{"", "g", "gc", "gce", "gct", "gctw", "cghrtw", "cghmrtwe", recoverRenterModules} {
+		if err := checkModuleDependencies(modules); err != nil {
+			t.Errorf("checkModuleDependencies(%q): %v", modules, err)
+		}
+	}
+	for _, modules := range []string{"c", "e", "gcw", "gctr", "gctm", "gcth", "w"} {
+		if err := checkModuleDependencies(modules); err == nil {
+			t.Errorf("checkModuleDependencies(%q): expected an error", modules)
+		}
+	}
+	err := checkModuleDependencies("gcw")
+	if err == nil || !strings.Contains(err.Error(), "wallet (w) requires transactionpool (t)") {
+		t.Error("unexpected error:", err)
+	}
+}
+
 // TestUnitProcessProfile tests that processProfileFlags correctly processes profiles
 // passed to the --profile flag.
 func TestUnitProcessProfile(t *testing.T) {
@@ -84,6 +107,8 @@ func TestUnitProcessProfile(t *testing.T) {
 		{"C", "c"},
 		{"M", "m"},
 		{"T", "t"},
+		{"p", "p"},
+		{"cP", "cp"},
 	}
 	for _, testVal := range testVals {
 		out, err := processProfileFlags(testVal.in)
@@ -105,6 +130,46 @@ func TestUnitProcessProfile(t *testing.T) {
 	}
 }
 
+// TestUnitProcessWallets tests that processWallets correctly processes valid
+// wallet lists and rejects invalid ones.
+func TestUnitProcessWallets(t *testing.T) {
+	// Test valid wallet lists.
+	testVals := []struct {
+		in  string
+		out string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"hot", "hot"},
+		{"hot,cold-watch", "hot,cold-watch"},
+		{" hot , host_collateral ", "hot,host_collateral"},
+	}
+	for _, testVal := range testVals {
+		out, err := processWallets(testVal.in)
+		if err != nil {
+			t.Error("processWallets failed with error:", err)
+		}
+		if out != testVal.out {
+			t.Errorf("processWallets returned incorrect wallets: expected %q, got %q\n", testVal.out, out)
+		}
+	}
+
+	// Test invalid wallet lists.
+	invalidWallets := []string{
+		"hot,",
+		"hot,,cold",
+		"hot,hot",
+		"../hot",
+		"hot wallet",
+	}
+	for _, wallets := range invalidWallets {
+		_, err := processWallets(wallets)
+		if err == nil {
+			t.Error("processWallets didn't error on invalid wallets:", wallets)
+		}
+	}
+}
+
 // TestUnitProcessConfig probes the 'processConfig' function.
 func TestUnitProcessConfig(t *testing.T) {
 	// Test valid configs.
@@ -148,6 +213,27 @@ func TestUnitProcessConfig(t *testing.T) {
 	if err == nil {
 		t.Error("processModules didn't error on invalid module:", invalidModule)
 	}
+
+	// Named wallets require the wallet module.
+	config.Siad.Modules = "cgt"
+	config.Siad.Wallets = "hot"
+	_, err = processConfig(config)
+	if err == nil {
+		t.Error("processConfig didn't error on named wallets without the wallet module")
+	}
+
+	// Renter recovery mode overrides the module set and disables
+	// bootstrapping.
+	config.Siad.Modules = "cghmrtwe"
+	config.Siad.Wallets = ""
+	config.Siad.RecoverRenter = true
+	config, err = processConfig(config)
+	if err != nil {
+		t.Fatal("processConfig failed with error:", err)
+	}
+	if config.Siad.Modules != recoverRenterModules || !config.Siad.NoBootstrap {
+		t.Error("renter recovery mode did not restrict the modules:", config.Siad.Modules, config.Siad.NoBootstrap)
+	}
 }
 
 // TestVerifyAPISecurity checks that the verifyAPISecurity function is
@@ -199,4 +285,15 @@ func TestVerifyAPISecurity(t *testing.T) {
 	if err != nil {
 		t.Error("public + securityOff with authentication was rejected:", err)
 	}
+
+	// Check that a public hostname is accepted when security is disabled and
+	// the api password was given with --api-password.
+	var securityOffPublicPassword Config
+	securityOffPublicPassword.Siad.APIaddr = "sia.tech:9980"
+	securityOffPublicPassword.Siad.AllowAPIBind = true
+	securityOffPublicPassword.APIPassword = "foo"
+	err = verifyAPISecurity(securityOffPublicPassword)
+	if err != nil {
+		t.Error("public + securityOff with an api password was rejected:", err)
+	}
 }
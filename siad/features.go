@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// Feature flags are enabled with the --features flag, or at runtime through
+// /daemon/flags. Changes made through the API are saved in the sia directory
+// and restored when siad starts; the --features flag enables flags on top of
+// the saved state.
+
+const (
+	// featuresFile is the name of the file in the sia directory that
+	// contains the state of the feature flags.
+	featuresFile = "features.json"
+)
+
+var (
+	// featuresMetadata contains the header and version strings that identify
+	// the features file.
+	featuresMetadata = persist.Metadata{
+		Header:  "Siad Feature Flags",
+		Version: "1.3.1",
+	}
+)
+
+// DaemonFlags lists the feature flags of the daemon.
+type DaemonFlags struct {
+	Flags []modules.Feature `json:"flags"`
+}
+
+// splitFeatures splits a comma-separated list of feature flags.
+func splitFeatures(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// processFeatures checks that the feature flags in a comma-separated list are
+// registered, and returns the normalized list.
+func processFeatures(list string) (string, error) {
+	names := splitFeatures(list)
+	registered := make(map[string]bool)
+	for _, f := range modules.Features() {
+		registered[f.Name] = true
+	}
+	for _, name := range names {
+		if !registered[name] {
+			return "", fmt.Errorf("%v %q", modules.ErrUnknownFeature, name)
+		}
+	}
+	return strings.Join(names, ","), nil
+}
+
+// loadFeatures restores the state of the feature flags saved in the sia
+// directory, then enables the flags in the comma-separated list. Saved flags
+// that are no longer registered are ignored.
+func loadFeatures(siaDir, enabled string) error {
+	var saved map[string]bool
+	err := persist.LoadJSON(featuresMetadata, &saved, filepath.Join(siaDir, featuresFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for name, on := range saved {
+		modules.SetFeature(name, on)
+	}
+	for _, name := range splitFeatures(enabled) {
+		if err := modules.SetFeature(name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveFeatures saves the state of the feature flags in the sia directory.
+func saveFeatures(siaDir string) error {
+	state := make(map[string]bool)
+	for _, f := range modules.Features() {
+		state[f.Name] = f.Enabled
+	}
+	return persist.SaveJSON(featuresMetadata, state, filepath.Join(siaDir, featuresFile))
+}
+
+// daemonFlagsHandlerGET handles the API call that lists the feature flags.
+func (srv *Server) daemonFlagsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	api.WriteJSON(w, DaemonFlags{Flags: modules.Features()})
+}
+
+// daemonFlagsHandlerPOST handles the API call that enables and disables
+// feature flags. The new state is saved, so that it survives a restart.
+func (srv *Server) daemonFlagsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	enable, err := processFeatures(req.FormValue("enable"))
+	if err != nil {
+		api.WriteError(w, api.Error{Message: "Failed to enable feature flags: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	disable, err := processFeatures(req.FormValue("disable"))
+	if err != nil {
+		api.WriteError(w, api.Error{Message: "Failed to disable feature flags: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	srv.featuresMu.Lock()
+	defer srv.featuresMu.Unlock()
+	for _, name := range splitFeatures(enable) {
+		modules.SetFeature(name, true)
+	}
+	for _, name := range splitFeatures(disable) {
+		modules.SetFeature(name, false)
+	}
+	if srv.siaDir != "" {
+		if err := saveFeatures(srv.siaDir); err != nil {
+			api.WriteError(w, api.Error{Message: "Failed to save feature flags: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+	}
+	api.WriteSuccess(w)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// registerTestFeatures registers the feature flags used by the tests, unless
+// an earlier test has done so.
+func registerTestFeatures() {
+	for _, f := range modules.Features() {
+		if f.Name == "siad-test-a" {
+			return
+		}
+	}
+	modules.RegisterFeature("siad-test-a", "first test flag")
+	modules.RegisterFeature("siad-test-b", "second test flag")
+}
+
+// TestDaemonFlags checks that feature flags can be enabled and disabled
+// through /daemon/flags, and that their state is restored on startup.
+func TestDaemonFlags(t *testing.T) {
+	registerTestFeatures()
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.siaDir = build.TempDir("siad", t.Name())
+	os.RemoveAll(srv.siaDir)
+	if err := os.MkdirAll(srv.siaDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer modules.SetFeature("siad-test-a", false)
+	defer modules.SetFeature("siad-test-b", false)
+
+	post := func(query string) int {
+		req := httptest.NewRequest("POST", "/daemon/flags?"+query, nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		return rec.Code
+	}
+	flags := func() map[string]bool {
+		req := httptest.NewRequest("GET", "/daemon/flags", nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		var df DaemonFlags
+		if err := json.NewDecoder(rec.Body).Decode(&df); err != nil {
+			t.Fatal(err)
+		}
+		state := make(map[string]bool)
+		for _, f := range df.Flags {
+			state[f.Name] = f.Enabled
+		}
+		return state
+	}
+
+	if state := flags(); state["siad-test-a"] || state["siad-test-b"] {
+		t.Fatal("feature flags should start disabled:", state)
+	}
+	if code := post("enable=siad-test-a,siad-test-b"); code != http.StatusNoContent {
+		t.Fatal("unexpected status code:", code)
+	}
+	if code := post("disable=siad-test-b"); code != http.StatusNoContent {
+		t.Fatal("unexpected status code:", code)
+	}
+	if state := flags(); !state["siad-test-a"] || state["siad-test-b"] {
+		t.Fatal("wrong feature flags:", state)
+	}
+	if code := post("enable=siad-test-b,unknown"); code != http.StatusBadRequest {
+		t.Fatal("expected unknown flags to be rejected, got status code", code)
+	}
+	if modules.FeatureEnabled("siad-test-b") {
+		t.Fatal("flags should not change when the request is rejected")
+	}
+
+	// The saved state is restored, and --features enables flags on top of it.
+	modules.SetFeature("siad-test-a", false)
+	if err := loadFeatures(srv.siaDir, "siad-test-b"); err != nil {
+		t.Fatal(err)
+	}
+	if !modules.FeatureEnabled("siad-test-a") || !modules.FeatureEnabled("siad-test-b") {
+		t.Fatal("feature flags were not restored:", flags())
+	}
+}
+
+// TestProcessFeatures checks that the --features flag only accepts
+// registered feature flags.
+func TestProcessFeatures(t *testing.T) {
+	registerTestFeatures()
+	if list, err := processFeatures(" siad-test-a, ,siad-test-b"); err != nil || list != "siad-test-a,siad-test-b" {
+		t.Fatalf("unexpected result: %q, %v", list, err)
+	}
+	if list, err := processFeatures(""); err != nil || list != "" {
+		t.Fatalf("unexpected result: %q, %v", list, err)
+	}
+	if _, err := processFeatures("siad-test-a,unknown"); err == nil {
+		t.Fatal("expected unknown feature flags to be rejected")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// /healthz reports that siad is running, and /readyz whether it is ready to
+// serve requests: all modules are loaded and the readiness checks of the API
+// pass. Both routes are meant for load balancers and orchestrators, so they
+// do not require the Sia-Agent user agent or the API password. They respond
+// with 200 OK when healthy or ready, and 503 Service Unavailable otherwise.
+
+const (
+	// readinessStartup is the name of the readiness check that all modules
+	// are loaded.
+	readinessStartup = "startup"
+)
+
+type (
+	// DaemonHealth is the response of /healthz.
+	DaemonHealth struct {
+		Status string `json:"status"`
+	}
+
+	// DaemonReadiness is the response of /readyz. Checks lists every check
+	// that was made, and the reasons of the checks that failed.
+	DaemonReadiness struct {
+		Ready  bool                 `json:"ready"`
+		Checks []api.ReadinessCheck `json:"checks"`
+	}
+)
+
+// setReadinessChecks sets the function that runs the readiness checks of the
+// loaded modules.
+func (srv *Server) setReadinessChecks(checks func() []api.ReadinessCheck) {
+	srv.startupMu.Lock()
+	srv.readinessChecks = checks
+	srv.startupMu.Unlock()
+}
+
+// readiness runs the readiness checks.
+func (srv *Server) readiness() DaemonReadiness {
+	srv.startupMu.Lock()
+	ds := srv.startup
+	checks := srv.readinessChecks
+	srv.startupMu.Unlock()
+
+	startup := api.ReadinessCheck{Name: readinessStartup, Ready: ds.Finished}
+	if !ds.Finished {
+		startup.Reason = fmt.Sprintf("loading %v (%d/%d)", ds.Loading, ds.ModulesLoaded+1, ds.ModulesTotal)
+	}
+	dr := DaemonReadiness{
+		Ready:  startup.Ready,
+		Checks: []api.ReadinessCheck{startup},
+	}
+	if ds.Finished && checks != nil {
+		for _, c := range checks() {
+			dr.Ready = dr.Ready && c.Ready
+			dr.Checks = append(dr.Checks, c)
+		}
+	}
+	return dr
+}
+
+// healthzHandler handles API calls to /healthz.
+func (srv *Server) healthzHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		api.WriteError(w, api.Error{Message: "method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+	api.WriteJSON(w, DaemonHealth{Status: "ok"})
+}
+
+// readyzHandler handles API calls to /readyz.
+func (srv *Server) readyzHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		api.WriteError(w, api.Error{Message: "method not allowed"}, http.StatusMethodNotAllowed)
+		return
+	}
+	dr := srv.readiness()
+	if !dr.Ready {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(dr)
+		return
+	}
+	api.WriteJSON(w, dr)
+}
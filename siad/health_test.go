@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// TestHealthz checks that /healthz responds without the Sia-Agent user
+// agent, with or without a version prefix.
+func TestHealthz(t *testing.T) {
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	for _, path := range []string{"/healthz", "/v1/healthz"} {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		var dh DaemonHealth
+		if err := json.NewDecoder(rec.Body).Decode(&dh); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusOK || dh.Status != "ok" {
+			t.Fatal(path, "returned", rec.Code, dh)
+		}
+	}
+}
+
+// TestReadyz checks that /readyz reports the startup progress and the
+// readiness checks of the modules.
+func TestReadyz(t *testing.T) {
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	readyz := func() (int, DaemonReadiness) {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+		var dr DaemonReadiness
+		if err := json.NewDecoder(rec.Body).Decode(&dr); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, dr
+	}
+
+	// Not ready while the modules are loading.
+	srv.setLoading(2, 3, "consensus")
+	code, dr := readyz()
+	if code != http.StatusServiceUnavailable || dr.Ready || len(dr.Checks) != 1 || dr.Checks[0].Reason != "loading consensus (2/3)" {
+		t.Fatalf("unexpected readiness while loading: %v %+v", code, dr)
+	}
+
+	// Not ready if a check fails.
+	walletReady := false
+	srv.setReadinessChecks(func() []api.ReadinessCheck {
+		c := api.ReadinessCheck{Name: api.ReadinessWallet, Ready: walletReady}
+		if !walletReady {
+			c.Reason = "wallet is locked"
+		}
+		return []api.ReadinessCheck{c}
+	})
+	srv.setLoaded()
+	code, dr = readyz()
+	if code != http.StatusServiceUnavailable || dr.Ready || len(dr.Checks) != 2 || dr.Checks[1].Reason != "wallet is locked" {
+		t.Fatalf("unexpected readiness with a failed check: %v %+v", code, dr)
+	}
+
+	// Ready once every check passes.
+	walletReady = true
+	code, dr = readyz()
+	if code != http.StatusOK || !dr.Ready || len(dr.Checks) != 2 {
+		t.Fatalf("unexpected readiness: %v %+v", code, dr)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// The modules of siad write their logs at the level set by --log-level, and
+// the level of each module can be changed at runtime with /daemon/log. The
+// log files are rotated once they grow past --log-max-size MiB, keeping
+// --log-max-backups rotated files of each log.
+
+// DaemonLog lists the log levels of the modules, and the rotation of the log
+// files.
+type DaemonLog struct {
+	Default    string            `json:"default"`
+	Modules    map[string]string `json:"modules"`
+	MaxSize    int64             `json:"maxsize"`
+	MaxBackups int               `json:"maxbackups"`
+}
+
+// parseLogLevels parses the value of --log-level, a comma-separated list of
+// a default level and module=level items, e.g. 'warn,host=debug'. The
+// default level is keyed by "".
+func parseLogLevels(s string) (map[string]persist.LogLevel, error) {
+	levels := make(map[string]persist.LogLevel)
+	if s == "" {
+		return levels, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		var module string
+		name := strings.TrimSpace(item)
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			module, name = strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])
+			if module == "" {
+				return nil, errors.New("invalid --log-level item " + item + ", expected module=level")
+			}
+		}
+		if _, ok := levels[module]; ok {
+			return nil, errors.New("the --log-level flag sets the level of " + item + " twice")
+		}
+		level, err := persist.ParseLogLevel(name)
+		if err != nil {
+			return nil, errors.New("invalid --log-level item " + item + ": " + err.Error())
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+// processLogging checks the log level and log rotation flags.
+func processLogging(config Config) error {
+	if _, err := parseLogLevels(config.Siad.LogLevel); err != nil {
+		return err
+	}
+	if config.Siad.LogMaxSize < 0 || config.Siad.LogMaxBackups < 0 {
+		return errors.New("--log-max-size and --log-max-backups cannot be negative")
+	}
+	return nil
+}
+
+// configureLogging sets the log levels and the log rotation, before the
+// modules open their logs.
+func configureLogging(config Config) {
+	levels, _ := parseLogLevels(config.Siad.LogLevel)
+	for module, level := range levels {
+		if module == "" {
+			persist.SetDefaultLogLevel(level)
+		} else {
+			persist.SetLogLevel(module, level)
+		}
+	}
+	persist.SetLogRotation(int64(config.Siad.LogMaxSize)<<20, config.Siad.LogMaxBackups)
+}
+
+// daemonLogHandlerGET handles the API call that returns the log levels of
+// the modules.
+func (srv *Server) daemonLogHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	maxSize, maxBackups := persist.LogRotation()
+	dl := DaemonLog{
+		Default:    persist.DefaultLogLevel().String(),
+		Modules:    make(map[string]string),
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+	}
+	for _, module := range persist.LogModules() {
+		dl.Modules[module] = persist.ModuleLogLevel(module).String()
+	}
+	api.WriteJSON(w, dl)
+}
+
+// daemonLogHandlerPOST handles the API call that changes the log level of a
+// module, or the default log level if no module is given. The level
+// 'default' makes a module follow the default level again.
+func (srv *Server) daemonLogHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	module, name := req.FormValue("module"), req.FormValue("level")
+	if module != "" {
+		known := false
+		for _, m := range persist.LogModules() {
+			known = known || m == module
+		}
+		if !known {
+			api.WriteError(w, api.Error{Message: "unknown module " + module + ", the modules are " + strings.Join(persist.LogModules(), ", "), Param: "module"}, http.StatusBadRequest)
+			return
+		}
+		if name == "default" {
+			persist.ResetLogLevel(module)
+			api.WriteSuccess(w)
+			return
+		}
+	}
+	level, err := persist.ParseLogLevel(name)
+	if err != nil {
+		api.WriteError(w, api.Error{Message: err.Error(), Param: "level"}, http.StatusBadRequest)
+		return
+	}
+	if module == "" {
+		persist.SetDefaultLogLevel(level)
+	} else {
+		persist.SetLogLevel(module, level)
+	}
+	api.WriteSuccess(w)
+}
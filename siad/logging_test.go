@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/persist"
+)
+
+// TestParseLogLevels probes the parsing of --log-level.
+func TestParseLogLevels(t *testing.T) {
+	tests := []struct {
+		flag   string
+		levels map[string]persist.LogLevel
+	}{
+		{"", map[string]persist.LogLevel{}},
+		{"warn", map[string]persist.LogLevel{"": persist.LogLevelWarn}},
+		{"error, host=debug,renter = info", map[string]persist.LogLevel{
+			"":       persist.LogLevelError,
+			"host":   persist.LogLevelDebug,
+			"renter": persist.LogLevelInfo,
+		}},
+		{"gateway=WARN", map[string]persist.LogLevel{"gateway": persist.LogLevelWarn}},
+	}
+	for _, test := range tests {
+		levels, err := parseLogLevels(test.flag)
+		if err != nil {
+			t.Errorf("%q: %v", test.flag, err)
+		} else if !reflect.DeepEqual(levels, test.levels) {
+			t.Errorf("%q: expected %v, got %v", test.flag, test.levels, levels)
+		}
+	}
+	for _, flag := range []string{"loud", "warn,info", "host=debug,host=info", "=debug", "host=", ","} {
+		if _, err := parseLogLevels(flag); err == nil {
+			t.Errorf("expected %q to be rejected", flag)
+		}
+	}
+
+	var config Config
+	config.Siad.LogMaxSize = -1
+	if err := processLogging(config); err == nil {
+		t.Error("expected a negative --log-max-size to be rejected")
+	}
+}
+
+// TestDaemonLog checks that /daemon/log reports and changes the log levels of
+// the modules.
+func TestDaemonLog(t *testing.T) {
+	srv, err := NewServer("", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	defaultLevel := persist.DefaultLogLevel()
+	defer persist.SetDefaultLogLevel(defaultLevel)
+	defer persist.ResetLogLevel("logtest")
+
+	// Open a logger, so that its module is known.
+	dir := build.TempDir("siad", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	l, err := persist.NewModuleFileLogger("logtest", filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	logLevels := func() (dl DaemonLog) {
+		req := httptest.NewRequest("GET", "/daemon/log", nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		if err := json.NewDecoder(rec.Body).Decode(&dl); err != nil {
+			t.Fatal(err)
+		}
+		return dl
+	}
+	setLevel := func(query string) int {
+		req := httptest.NewRequest("POST", "/daemon/log?"+query, nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if dl := logLevels(); dl.Default != defaultLevel.String() || dl.Modules["logtest"] != defaultLevel.String() {
+		t.Fatalf("unexpected log levels: %+v", dl)
+	}
+
+	if code := setLevel("level=error"); code != 204 {
+		t.Fatal("unable to set the default level:", code)
+	}
+	if code := setLevel("module=logtest&level=debug"); code != 204 {
+		t.Fatal("unable to set the level of a module:", code)
+	}
+	if dl := logLevels(); dl.Default != "error" || dl.Modules["logtest"] != "debug" {
+		t.Fatalf("unexpected log levels: %+v", dl)
+	}
+	if code := setLevel("module=logtest&level=default"); code != 204 {
+		t.Fatal("unable to reset the level of a module:", code)
+	}
+	if dl := logLevels(); dl.Modules["logtest"] != "error" {
+		t.Fatalf("the module does not follow the default level: %+v", dl)
+	}
+
+	for _, query := range []string{"", "level=loud", "module=nosuchmodule&level=info", "level=default"} {
+		if code := setLevel(query); code != 400 {
+			t.Errorf("expected %q to be rejected, got %v", query, code)
+		}
+	}
+	if !strings.Contains(strings.Join(persist.LogModules(), ","), "logtest") {
+		t.Error("the module is not listed")
+	}
+}
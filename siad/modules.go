@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// The modules that siad loads are chosen with --modules, either as a string
+// of module letters, e.g. 'gce', or as a comma-separated list of module
+// names, e.g. 'gateway,consensus,explorer'. Every module must be loaded along
+// with the modules it depends on.
+
+// moduleLetters are the letters of the modules, in the order they are
+// loaded.
+const moduleLetters = "gctewmhr"
+
+var (
+	// moduleNames are the names of the modules, keyed by their letter.
+	moduleNames = map[byte]string{
+		'c': "consensus",
+		'e': "explorer",
+		'g': "gateway",
+		'h': "host",
+		'm': "miner",
+		'r': "renter",
+		't': "transactionpool",
+		'w': "wallet",
+	}
+
+	// moduleDependencies are the modules that each module requires, keyed
+	// by its letter.
+	moduleDependencies = map[byte]string{
+		'c': "g",
+		'e': "c",
+		'h': "ctw",
+		'm': "ctw",
+		'r': "gctw",
+		't': "gc",
+		'w': "ct",
+	}
+)
+
+// expandModuleNames converts a comma-separated list of module names to
+// module letters. A string that is not a list of names is returned
+// unchanged.
+func expandModuleNames(list string) (string, error) {
+	items := strings.Split(list, ",")
+	if len(items) == 1 && moduleLetter(strings.TrimSpace(items[0])) == 0 {
+		return list, nil
+	}
+	var letters string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		l := moduleLetter(item)
+		if l == 0 {
+			return "", errors.New("Unable to parse --modules flag, unrecognized module: " + item)
+		}
+		letters += string(l)
+	}
+	return letters, nil
+}
+
+// moduleLetter returns the letter of the module with the given name, or 0 if
+// there is no such module. The transaction pool may also be called tpool.
+func moduleLetter(name string) byte {
+	name = strings.ToLower(name)
+	if name == "tpool" {
+		return 't'
+	}
+	for l, n := range moduleNames {
+		if n == name {
+			return l
+		}
+	}
+	return 0
+}
+
+// checkModuleDependencies checks that the modules that each module requires
+// are loaded as well.
+func checkModuleDependencies(modules string) error {
+	var errs []string
+	for i := 0; i < len(moduleLetters); i++ {
+		l := moduleLetters[i]
+		if !strings.Contains(modules, string(l)) {
+			continue
+		}
+		var missing []string
+		for _, d := range []byte(moduleDependencies[l]) {
+			if !strings.Contains(modules, string(d)) {
+				missing = append(missing, moduleNames[d]+" ("+string(d)+")")
+			}
+		}
+		if len(missing) > 0 {
+			errs = append(errs, moduleNames[l]+" ("+string(l)+") requires "+strings.Join(missing, ", "))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New("Unable to load the modules of the --modules flag: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// siad serves the Go runtime profiles and execution traces at
+// /daemon/profile/:name, so that lock contention and other slowdowns can be
+// diagnosed on a running node. Profiling is off by default, as collecting the
+// mutex and block profiles slows the daemon down a little. It is turned on
+// with --profile p, or at runtime with POST /daemon/profile, and the profiles
+// require the API password.
+
+const (
+	// profileMutexFraction is the fraction of mutex contention events that
+	// are recorded while profiling is on, as with
+	// runtime.SetMutexProfileFraction.
+	profileMutexFraction = 10
+
+	// profileBlockRate is the number of nanoseconds spent blocked per
+	// recorded blocking event while profiling is on, as with
+	// runtime.SetBlockProfileRate.
+	profileBlockRate = 10000
+
+	// maxProfileSeconds is the longest CPU profile or execution trace that
+	// can be requested.
+	maxProfileSeconds = 300
+)
+
+var (
+	// timedProfiles are the profiles that are collected for a number of
+	// seconds, in addition to the profiles of runtime/pprof.
+	timedProfiles = []string{"cpu", "trace"}
+)
+
+// DaemonProfile reports whether profiling is on, and lists the profiles that
+// can be requested.
+type DaemonProfile struct {
+	Enabled  bool     `json:"enabled"`
+	Profiles []string `json:"profiles"`
+}
+
+// profileNames returns the names of the profiles that can be requested.
+func profileNames() []string {
+	names := append([]string(nil), timedProfiles...)
+	for _, p := range runtimepprof.Profiles() {
+		names = append(names, p.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setProfiling turns profiling on or off.
+func (srv *Server) setProfiling(enabled bool) {
+	srv.profilingMu.Lock()
+	defer srv.profilingMu.Unlock()
+	srv.profiling = enabled
+	if enabled {
+		runtime.SetMutexProfileFraction(profileMutexFraction)
+		runtime.SetBlockProfileRate(profileBlockRate)
+	} else {
+		runtime.SetMutexProfileFraction(0)
+		runtime.SetBlockProfileRate(0)
+	}
+}
+
+// profilingEnabled returns true if profiling is on.
+func (srv *Server) profilingEnabled() bool {
+	srv.profilingMu.Lock()
+	defer srv.profilingMu.Unlock()
+	return srv.profiling
+}
+
+// daemonProfileHandlerGET handles the API call that reports whether
+// profiling is on.
+func (srv *Server) daemonProfileHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	api.WriteJSON(w, DaemonProfile{
+		Enabled:  srv.profilingEnabled(),
+		Profiles: profileNames(),
+	})
+}
+
+// daemonProfileHandlerPOST handles the API call that turns profiling on or
+// off.
+func (srv *Server) daemonProfileHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	enabled, err := strconv.ParseBool(req.FormValue("enable"))
+	if err != nil {
+		api.WriteError(w, api.Error{Message: "unable to parse enable: " + err.Error(), Param: "enable"}, http.StatusBadRequest)
+		return
+	}
+	srv.setProfiling(enabled)
+	api.WriteSuccess(w)
+}
+
+// daemonProfileNameHandler handles the API call that collects a profile. The
+// response is the profile in the format of 'go tool pprof', or the execution
+// trace in the format of 'go tool trace'.
+func (srv *Server) daemonProfileNameHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	if !srv.profilingEnabled() {
+		api.WriteError(w, api.Error{Message: "profiling is off, start siad with --profile p or turn it on with /daemon/profile"}, http.StatusForbidden)
+		return
+	}
+	name := ps.ByName("name")
+	if name == "cpu" || name == "trace" {
+		if s := req.FormValue("seconds"); s != "" {
+			seconds, err := strconv.ParseUint(s, 10, 64)
+			if err != nil || seconds == 0 || seconds > maxProfileSeconds {
+				api.WriteError(w, api.Error{Message: "seconds must be between 1 and " + strconv.Itoa(maxProfileSeconds), Param: "seconds"}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	switch {
+	case name == "cpu":
+		pprof.Profile(w, req)
+	case name == "trace":
+		pprof.Trace(w, req)
+	case runtimepprof.Lookup(name) != nil:
+		pprof.Handler(name).ServeHTTP(w, req)
+	default:
+		api.WriteError(w, api.Error{Message: "unknown profile " + name, Param: "name"}, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestDaemonProfile checks that the profiles are only served while profiling
+// is on, and that profiling can be turned on at runtime.
+func TestDaemonProfile(t *testing.T) {
+	srv, err := NewServer("", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.setProfiling(false)
+	call := func(method, path string, vals url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(vals.Encode()))
+		req.Header.Set("User-Agent", "Sia-Agent")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call("GET", "/daemon/profile/heap", nil); rec.Code != http.StatusForbidden {
+		t.Fatal("expected 403 while profiling is off, got", rec.Code)
+	}
+	if rec := call("POST", "/daemon/profile", url.Values{"enable": {"foo"}}); rec.Code != http.StatusBadRequest {
+		t.Fatal("expected 400 for a bad enable value, got", rec.Code)
+	}
+	if rec := call("POST", "/daemon/profile", url.Values{"enable": {"true"}}); rec.Code != http.StatusNoContent {
+		t.Fatal("expected profiling to be turned on, got", rec.Code, rec.Body)
+	}
+	if rec := call("GET", "/daemon/profile", nil); !strings.Contains(rec.Body.String(), `"enabled":true`) || !strings.Contains(rec.Body.String(), `"mutex"`) {
+		t.Fatal("expected profiling to be on:", rec.Body)
+	}
+
+	for _, path := range []string{"/daemon/profile/heap", "/daemon/profile/mutex", "/daemon/profile/cpu?seconds=1", "/daemon/profile/trace?seconds=1"} {
+		rec := call("GET", path, nil)
+		if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Fatal("expected a profile from", path, "got", rec.Code, rec.Body)
+		}
+	}
+	for _, path := range []string{"/daemon/profile/foo", "/daemon/profile/cpu?seconds=0", "/daemon/profile/trace?seconds=301"} {
+		if rec := call("GET", path, nil); rec.Code != http.StatusBadRequest {
+			t.Fatal("expected 400 from", path, "got", rec.Code)
+		}
+	}
+
+	srv.setProfiling(false)
+	if rec := call("GET", "/daemon/profile/heap", nil); rec.Code != http.StatusForbidden {
+		t.Fatal("expected 403 after profiling was turned off, got", rec.Code)
+	}
+}
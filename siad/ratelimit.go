@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// The rate limits of the API are set with --api-rate-limit, a comma-separated
+// list of class=rate[:burst] items, where rate is the number of requests per
+// second that each client may make to the routes of the class, and burst the
+// number of requests it may make at once. The burst defaults to the rate,
+// rounded up. For example, 'read=20,expensive=0.1:2' lets each client make
+// 20 GET requests per second, and an expensive request every 10 seconds after
+// a burst of 2.
+
+// processRateLimits checks the --api-rate-limit flag.
+func processRateLimits(config Config) (Config, error) {
+	_, err := parseRateLimits(config.Siad.APIRateLimit)
+	return config, err
+}
+
+// parseRateLimits parses the value of --api-rate-limit.
+func parseRateLimits(s string) (map[string]api.RateLimit, error) {
+	limits := make(map[string]api.RateLimit)
+	for _, item := range splitList(s) {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.New("Unable to parse --api-rate-limit flag, expected class=rate[:burst], got: " + item)
+		}
+		class, value := strings.ToLower(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+		known := false
+		for _, c := range api.RouteClasses {
+			known = known || c == class
+		}
+		if !known {
+			return nil, errors.New("Unable to parse --api-rate-limit flag, unknown class " + class + ", the classes are: " + strings.Join(api.RouteClasses, ", "))
+		}
+		if _, ok := limits[class]; ok {
+			return nil, errors.New("Unable to parse --api-rate-limit flag, duplicate class: " + class)
+		}
+		var limit api.RateLimit
+		rate, burst := value, ""
+		if i := strings.Index(value, ":"); i >= 0 {
+			rate, burst = value[:i], value[i+1:]
+		}
+		var err error
+		limit.Rate, err = strconv.ParseFloat(rate, 64)
+		if err != nil || limit.Rate <= 0 || math.IsInf(limit.Rate, 0) {
+			return nil, errors.New("Unable to parse --api-rate-limit flag, invalid rate: " + item)
+		}
+		limit.Burst = int(math.Ceil(limit.Rate))
+		if burst != "" {
+			limit.Burst, err = strconv.Atoi(burst)
+			if err != nil || limit.Burst < 1 {
+				return nil, errors.New("Unable to parse --api-rate-limit flag, invalid burst: " + item)
+			}
+		}
+		limits[class] = limit
+	}
+	return limits, nil
+}
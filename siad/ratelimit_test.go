@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// TestParseRateLimits probes parseRateLimits.
+func TestParseRateLimits(t *testing.T) {
+	limits, err := parseRateLimits("read=20, Expensive=0.1:2,write=2.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]api.RateLimit{
+		api.RouteClassRead:      {Rate: 20, Burst: 20},
+		api.RouteClassWrite:     {Rate: 2.5, Burst: 3},
+		api.RouteClassExpensive: {Rate: 0.1, Burst: 2},
+	}
+	if len(limits) != len(want) {
+		t.Fatal("unexpected limits:", limits)
+	}
+	for class, limit := range want {
+		if limits[class] != limit {
+			t.Errorf("%v: expected %v, got %v", class, limit, limits[class])
+		}
+	}
+
+	if limits, err := parseRateLimits(""); err != nil || len(limits) != 0 {
+		t.Fatal("expected no limits, got", limits, err)
+	}
+	for _, s := range []string{"read", "cheap=1", "read=0", "read=-1", "read=x", "read=1:0", "read=1:x", "read=1,read=2"} {
+		if _, err := parseRateLimits(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
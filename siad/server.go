@@ -15,11 +15,15 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/NebulousLabs/Sia/api"
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/host/contractmanager"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/inconshreveable/go-update"
@@ -35,7 +39,81 @@ type (
 	Server struct {
 		httpServer *http.Server
 		mux        *http.ServeMux
-		listener   net.Listener
+
+		// listener is the TCP listener of the API, and socket the listener
+		// of its Unix socket. Either may be nil.
+		listener net.Listener
+		socket   net.Listener
+
+		// startup tracks the progress of loading the modules, and
+		// readinessChecks runs the readiness checks of the loaded modules
+		// once they are loaded.
+		startup         DaemonStartup
+		readinessChecks func() []api.ReadinessCheck
+		startupMu       sync.Mutex
+
+		// crashDir is the directory that crash reports are written to.
+		crashDir string
+
+		// siaDir is the sia directory, in which the state of the feature
+		// flags is saved. featuresMu serializes the saves.
+		siaDir     string
+		featuresMu sync.Mutex
+
+		// config lists the effective settings of the daemon.
+		config   DaemonConfig
+		configMu sync.Mutex
+
+		// profiling is true if the runtime profiles are served.
+		profiling   bool
+		profilingMu sync.Mutex
+
+		// modules are the loaded modules, in the order they were loaded,
+		// that have not been closed yet. shutdown tracks the progress of
+		// shutting down, inFlight the requests that are being served, and
+		// stopChan is closed once the daemon is asked to stop.
+		modules    []loadedModule
+		shutdown   DaemonShutdown
+		inFlight   sync.WaitGroup
+		stopChan   chan struct{}
+		shutdownMu sync.Mutex
+	}
+
+	// DaemonBandwidth lists the bandwidth limits of the daemon, and the
+	// traffic of each module since startup.
+	DaemonBandwidth struct {
+		MaxUpload   uint64 `json:"maxupload"`
+		MaxDownload uint64 `json:"maxdownload"`
+
+		Gateway DaemonBandwidthModule `json:"gateway"`
+		Host    DaemonBandwidthModule `json:"host"`
+		Renter  DaemonBandwidthModule `json:"renter"`
+	}
+
+	// DaemonBandwidthModule lists the bandwidth limits and priority of a
+	// module, and its traffic since startup.
+	DaemonBandwidthModule struct {
+		modules.BandwidthModuleLimits
+		modules.BandwidthModuleUsage
+	}
+
+	// DaemonCrashes lists the crash reports recorded by the daemon, newest
+	// first.
+	DaemonCrashes struct {
+		Crashes []build.CrashReport `json:"crashes"`
+	}
+
+	// DaemonStartup reports the progress of loading the modules, including
+	// the progress of any recovery that the host's storage is performing
+	// after an unclean shutdown.
+	DaemonStartup struct {
+		Finished      bool   `json:"finished"`
+		Loading       string `json:"loading"`
+		ModulesLoaded int    `json:"modulesloaded"`
+		ModulesTotal  int    `json:"modulestotal"`
+
+		WALRecoveryApplied uint64 `json:"walrecoveryapplied"`
+		WALRecoveryTotal   uint64 `json:"walrecoverytotal"`
 	}
 
 	// SiaConstants is a struct listing all of the constants in use.
@@ -337,43 +415,158 @@ func (srv *Server) daemonVersionHandler(w http.ResponseWriter, _ *http.Request,
 	api.WriteJSON(w, DaemonVersion{Version: build.Version})
 }
 
-// daemonStopHandler handles the API call to stop the daemon cleanly.
-func (srv *Server) daemonStopHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-	// can't write after we stop the server, so lie a bit.
-	api.WriteSuccess(w)
+// daemonStartupHandler handles the API call that requests the progress of
+// loading the modules.
+func (srv *Server) daemonStartupHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	srv.startupMu.Lock()
+	ds := srv.startup
+	srv.startupMu.Unlock()
+	ds.WALRecoveryApplied, ds.WALRecoveryTotal = contractmanager.RecoveryProgress()
+	api.WriteJSON(w, ds)
+}
 
-	// need to flush the response before shutting down the server
-	f, ok := w.(http.Flusher)
-	if !ok {
-		panic("Server does not support flushing")
+// daemonCrashesHandler handles the API call that lists the crash reports
+// recorded by the daemon.
+func (srv *Server) daemonCrashesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	crashes := []build.CrashReport{}
+	lp, err := api.ParseListParams(req, map[string]func(i, j int) bool{
+		"kind":   func(i, j int) bool { return crashes[i].Kind < crashes[j].Kind },
+		"module": func(i, j int) bool { return crashes[i].Module < crashes[j].Module },
+		"time":   func(i, j int) bool { return crashes[i].Time.Before(crashes[j].Time) },
+	})
+	if err != nil {
+		api.WriteError(w, api.Error{Message: "Failed to list crash reports: " + err.Error()}, http.StatusBadRequest)
+		return
 	}
-	f.Flush()
+	if srv.crashDir != "" {
+		reports, err := build.ReadCrashReports(srv.crashDir)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: "Failed to read crash reports: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		for i := len(reports) - 1; i >= 0; i-- {
+			crashes = append(crashes, reports[i])
+		}
+	}
+	start, end := lp.Apply(w, crashes)
+	api.WriteJSON(w, DaemonCrashes{Crashes: crashes[start:end]})
+}
 
-	if err := srv.Close(); err != nil {
-		build.Critical(err)
+// daemonBandwidthHandlerGET handles the API call that requests the bandwidth
+// limits and usage of the daemon.
+func (srv *Server) daemonBandwidthHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	limits, usage := modules.CurrentBandwidthLimits(), modules.CurrentBandwidthUsage()
+	api.WriteJSON(w, DaemonBandwidth{
+		MaxUpload:   limits.MaxUpload,
+		MaxDownload: limits.MaxDownload,
+		Gateway:     DaemonBandwidthModule{limits.Gateway, usage.Gateway},
+		Host:        DaemonBandwidthModule{limits.Host, usage.Host},
+		Renter:      DaemonBandwidthModule{limits.Renter, usage.Renter},
+	})
+}
+
+// daemonBandwidthHandlerPOST handles the API call that changes the bandwidth
+// limits of the daemon. Limits that are not provided are left unchanged.
+func (srv *Server) daemonBandwidthHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	limits := modules.CurrentBandwidthLimits()
+	params := map[string]*uint64{
+		"maxupload":          &limits.MaxUpload,
+		"maxdownload":        &limits.MaxDownload,
+		"gatewaymaxupload":   &limits.Gateway.MaxUpload,
+		"gatewaymaxdownload": &limits.Gateway.MaxDownload,
+		"gatewaypriority":    &limits.Gateway.Priority,
+		"hostmaxupload":      &limits.Host.MaxUpload,
+		"hostmaxdownload":    &limits.Host.MaxDownload,
+		"hostpriority":       &limits.Host.Priority,
+		"rentermaxupload":    &limits.Renter.MaxUpload,
+		"rentermaxdownload":  &limits.Renter.MaxDownload,
+		"renterpriority":     &limits.Renter.Priority,
+	}
+	for name, field := range params {
+		value := req.FormValue(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: "Failed to parse " + name + ": " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		*field = n
+	}
+	if err := modules.SetBandwidthLimits(limits); err != nil {
+		api.WriteError(w, api.Error{Message: "Failed to set bandwidth limits: " + err.Error()}, http.StatusBadRequest)
+		return
 	}
+	api.WriteSuccess(w)
+}
+
+// setLoading records that the i'th of n modules is being loaded, and prints a
+// progress message.
+func (srv *Server) setLoading(i, n int, name string) {
+	fmt.Printf("(%d/%d) Loading %v...\n", i, n, name)
+	srv.startupMu.Lock()
+	srv.startup.Loading = name
+	srv.startup.ModulesLoaded = i - 1
+	srv.startup.ModulesTotal = n
+	srv.startupMu.Unlock()
+}
+
+// setLoaded records that all of the modules have been loaded.
+func (srv *Server) setLoaded() {
+	srv.startupMu.Lock()
+	srv.startup.Finished = true
+	srv.startup.Loading = ""
+	srv.startup.ModulesLoaded = srv.startup.ModulesTotal
+	srv.startupMu.Unlock()
+}
+
+// daemonStopHandler handles the API call to stop the daemon cleanly. The
+// daemon shuts down in the background, and reports the progress at
+// /daemon/shutdown.
+func (srv *Server) daemonStopHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	srv.requestStop()
+	api.WriteSuccess(w)
 }
 
 func (srv *Server) daemonHandler(password string) http.Handler {
 	router := httprouter.New()
 
+	router.GET("/daemon/bandwidth", srv.daemonBandwidthHandlerGET)
+	router.POST("/daemon/bandwidth", api.RequirePassword(srv.daemonBandwidthHandlerPOST, password))
+	router.GET("/daemon/config", srv.daemonConfigHandler)
 	router.GET("/daemon/constants", srv.daemonConstantsHandler)
+	router.GET("/daemon/crashes", srv.daemonCrashesHandler)
+	router.GET("/daemon/flags", srv.daemonFlagsHandlerGET)
+	router.GET("/daemon/log", srv.daemonLogHandlerGET)
+	router.POST("/daemon/log", api.RequirePassword(srv.daemonLogHandlerPOST, password))
+	router.POST("/daemon/flags", api.RequirePassword(srv.daemonFlagsHandlerPOST, password))
+	router.GET("/daemon/profile", srv.daemonProfileHandlerGET)
+	router.POST("/daemon/profile", api.RequirePassword(srv.daemonProfileHandlerPOST, password))
+	router.GET("/daemon/profile/:name", api.RequirePassword(srv.daemonProfileNameHandler, password))
+	router.GET("/daemon/shutdown", srv.daemonShutdownHandler)
+	router.GET("/daemon/startup", srv.daemonStartupHandler)
 	router.GET("/daemon/version", srv.daemonVersionHandler)
 	router.GET("/daemon/update", srv.daemonUpdateHandlerGET)
-	router.POST("/daemon/update", srv.daemonUpdateHandlerPOST)
+	router.POST("/daemon/update", api.RequirePassword(srv.daemonUpdateHandlerPOST, password))
 	router.GET("/daemon/stop", api.RequirePassword(srv.daemonStopHandler, password))
 
 	return router
 }
 
-// NewServer creates a new net.http server listening on bindAddr.  Only the
-// /daemon/ routes are registered by this func, additional routes can be
-// registered later by calling serv.mux.Handle.
+// NewServer creates a new net.http server listening on bindAddr, or on no TCP
+// address if bindAddr is empty.  Only the /daemon/ routes are registered by
+// this func, additional routes can be registered later by calling
+// serv.mux.Handle.
 func NewServer(bindAddr, requiredUserAgent, requiredPassword string) (*Server, error) {
 	// Create the listener for the server
-	l, err := net.Listen("tcp", bindAddr)
-	if err != nil {
-		return nil, err
+	var l net.Listener
+	if bindAddr != "" {
+		var err error
+		l, err = net.Listen("tcp", bindAddr)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Create the Server
@@ -381,8 +574,11 @@ func NewServer(bindAddr, requiredUserAgent, requiredPassword string) (*Server, e
 	srv := &Server{
 		mux:      mux,
 		listener: l,
+		stopChan: make(chan struct{}),
 		httpServer: &http.Server{
-			Handler: mux,
+			// Strip the version prefix before routing, so that the
+			// /daemon routes are versioned along with the API.
+			Handler: api.ServeVersioned(mux),
 
 			// set reasonable timeout windows for requests, to prevent the Sia API
 			// server from leaking file descriptors due to slow, disappearing, or
@@ -405,26 +601,58 @@ func NewServer(bindAddr, requiredUserAgent, requiredPassword string) (*Server, e
 
 	// Register siad routes
 	srv.mux.Handle("/daemon/", api.RequireUserAgent(srv.daemonHandler(requiredPassword), requiredUserAgent))
+	srv.mux.HandleFunc("/healthz", srv.healthzHandler)
+	srv.mux.HandleFunc("/readyz", srv.readyzHandler)
 
 	return srv, nil
 }
 
+// listeners returns the listeners of the server.
+func (srv *Server) listeners() []net.Listener {
+	var ls []net.Listener
+	for _, l := range []net.Listener{srv.listener, srv.socket} {
+		if l != nil {
+			ls = append(ls, l)
+		}
+	}
+	return ls
+}
+
 func (srv *Server) Serve() error {
-	// The server will run until an error is encountered or the listener is
+	// The server will run until an error is encountered or the listeners are
 	// closed, via either the Close method or the signal handling above.
-	// Closing the listener will result in the benign error handled below.
-	err := srv.httpServer.Serve(srv.listener)
-	if err != nil && !strings.HasSuffix(err.Error(), "use of closed network connection") {
-		return err
+	// Closing a listener will result in the benign error handled below. If
+	// one listener fails, the others are closed as well.
+	listeners := srv.listeners()
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			err := srv.httpServer.Serve(l)
+			if err != nil && !strings.HasSuffix(err.Error(), "use of closed network connection") {
+				srv.Close()
+				errs <- err
+				return
+			}
+			errs <- nil
+		}(l)
 	}
-	return nil
+	var err error
+	for range listeners {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
 }
 
-// Close closes the Server's listener, causing the HTTP server to shut down.
+// Close closes the Server's listeners, causing the HTTP server to shut down.
 func (srv *Server) Close() error {
-	// Close the listener, which will cause Server.Serve() to return.
-	if err := srv.listener.Close(); err != nil {
-		return err
+	// Close the listeners, which will cause Server.Serve() to return.
+	var err error
+	for _, l := range srv.listeners() {
+		if e := l.Close(); e != nil && err == nil && !strings.HasSuffix(e.Error(), "use of closed network connection") {
+			err = e
+		}
 	}
-	return nil
+	return err
 }
@@ -1,6 +1,16 @@
 package main
 
-import "testing"
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
 
 // TestLatestRelease tests that the latestRelease function properly processes a
 // set of GitHub releases, returning the release with the highest version
@@ -66,3 +76,165 @@ func TestLatestRelease(t *testing.T) {
 		}
 	}
 }
+
+// TestDaemonBandwidth checks that the /daemon/bandwidth route changes only the
+// bandwidth limits that are provided, and rejects invalid limits.
+func TestDaemonBandwidth(t *testing.T) {
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	defer modules.SetBandwidthLimits(modules.DefaultBandwidthLimits)
+
+	post := func(query string) int {
+		req := httptest.NewRequest("POST", "/daemon/bandwidth?"+query, nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		return rec.Code
+	}
+	bandwidth := func() (db DaemonBandwidth) {
+		req := httptest.NewRequest("GET", "/daemon/bandwidth", nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		if err := json.NewDecoder(rec.Body).Decode(&db); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	if code := post("maxupload=1000000&hostpriority=5"); code != http.StatusNoContent {
+		t.Fatal("unexpected status code:", code)
+	}
+	if code := post("rentermaxdownload=500000"); code != http.StatusNoContent {
+		t.Fatal("unexpected status code:", code)
+	}
+	db := bandwidth()
+	if db.MaxUpload != 1000000 || db.MaxDownload != 0 || db.Host.Priority != 5 || db.Renter.MaxDownload != 500000 {
+		t.Fatalf("unexpected bandwidth limits: %+v", db)
+	}
+	if db.Gateway.Priority != modules.DefaultBandwidthLimits.Gateway.Priority {
+		t.Fatal("limits that were not provided should not change")
+	}
+
+	for _, query := range []string{"maxupload=-1", "hostmaxupload=fast", "gatewaypriority=0"} {
+		if code := post(query); code != http.StatusBadRequest {
+			t.Errorf("expected %v to be rejected, got status code %v", query, code)
+		}
+	}
+	if db := bandwidth(); db.MaxUpload != 1000000 || db.Gateway.Priority == 0 {
+		t.Fatal("invalid limits should not be applied")
+	}
+}
+
+// TestDaemonStartup checks that the /daemon/startup route reports the progress
+// of loading the modules.
+func TestDaemonStartup(t *testing.T) {
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	startup := func() (ds DaemonStartup) {
+		req := httptest.NewRequest("GET", "/daemon/startup", nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		if err := json.NewDecoder(rec.Body).Decode(&ds); err != nil {
+			t.Fatal(err)
+		}
+		return ds
+	}
+
+	srv.setLoading(2, 3, "consensus")
+	ds := startup()
+	if ds.Finished || ds.Loading != "consensus" || ds.ModulesLoaded != 1 || ds.ModulesTotal != 3 {
+		t.Fatalf("unexpected startup progress: %+v", ds)
+	}
+	srv.setLoaded()
+	ds = startup()
+	if !ds.Finished || ds.Loading != "" || ds.ModulesLoaded != 3 {
+		t.Fatalf("unexpected startup progress: %+v", ds)
+	}
+}
+
+// TestDaemonCrashes checks that the /daemon/crashes route lists the recorded
+// crash reports, newest first.
+func TestDaemonCrashes(t *testing.T) {
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.crashDir = build.TempDir("siad", t.Name())
+	os.RemoveAll(srv.crashDir)
+
+	crashes := func() (dc DaemonCrashes) {
+		req := httptest.NewRequest("GET", "/daemon/crashes", nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		if err := json.NewDecoder(rec.Body).Decode(&dc); err != nil {
+			t.Fatal(err)
+		}
+		return dc
+	}
+	if dc := crashes(); len(dc.Crashes) != 0 {
+		t.Fatal("expected no crash reports, got", dc.Crashes)
+	}
+
+	for _, id := range []string{"1-panic", "2-critical"} {
+		if err := build.WriteCrashReport(srv.crashDir, build.CrashReport{ID: id, Module: "host"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dc := crashes()
+	if len(dc.Crashes) != 2 || dc.Crashes[0].ID != "2-critical" || dc.Crashes[1].ID != "1-panic" {
+		t.Fatalf("unexpected crash reports: %+v", dc.Crashes)
+	}
+}
+
+// TestUploadCrashReports checks that crash reports are anonymized before they
+// are uploaded, and that each report is uploaded only once.
+func TestUploadCrashReports(t *testing.T) {
+	dir := build.TempDir("siad", t.Name())
+	os.RemoveAll(dir)
+	siaDir := "/var/lib/sia"
+	cr := build.CrashReport{
+		ID:      "1-panic",
+		Module:  "host",
+		Message: "could not read " + siaDir + "/host/host.json from 203.0.113.7:9982 and [2001:db8::1]:9982",
+		Stack:   "main.go:12 +0x1a5",
+	}
+	if err := build.WriteCrashReport(dir, cr); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []build.CrashReport
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var cr build.CrashReport
+		if err := json.NewDecoder(req.Body).Decode(&cr); err != nil {
+			t.Error(err)
+		}
+		received = append(received, cr)
+	}))
+	defer ts.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := uploadCrashReports(dir, siaDir, ts.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(received) != 1 {
+		t.Fatal("expected the report to be uploaded once, got", len(received))
+	}
+	msg := received[0].Message
+	if strings.Contains(msg, siaDir) || strings.Contains(msg, "203.0.113.7") || strings.Contains(msg, "2001:db8") {
+		t.Fatal("crash report was not anonymized:", msg)
+	}
+	if msg != "could not read <siadir>/host/host.json from <ip>:9982 and [<ip>]:9982" {
+		t.Fatal("unexpected anonymized message:", msg)
+	}
+	if received[0].Stack != cr.Stack {
+		t.Fatal("stack was altered by anonymization:", received[0].Stack)
+	}
+}
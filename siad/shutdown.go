@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/NebulousLabs/Sia/api"
+)
+
+// siad shuts down in stages when /daemon/stop is called or a stop signal is
+// caught. New requests to the module routes are refused, and the requests in
+// flight are given shutdownDrainTimeout to finish; long-polling requests are
+// cancelled so that they return at once. The modules are then closed in the
+// reverse of the order they were loaded in, so that each module is closed
+// before the modules it depends on. The host flushes the pending writes of the
+// contract manager and the wallet saves its state as they close. The API keeps
+// serving /daemon/shutdown, which reports the progress, until every module is
+// closed.
+
+const (
+	// shutdownDrainTimeout is how long siad waits for the requests in
+	// flight before it closes the modules.
+	shutdownDrainTimeout = 30 * time.Second
+)
+
+type (
+	// DaemonShutdown reports the progress of shutting down the daemon.
+	DaemonShutdown struct {
+		Stopping         bool   `json:"stopping"`
+		InFlightRequests int    `json:"inflightrequests"`
+		Closing          string `json:"closing"`
+		ModulesClosed    int    `json:"modulesclosed"`
+		ModulesTotal     int    `json:"modulestotal"`
+	}
+
+	// loadedModule is a module that is closed when the daemon shuts down.
+	loadedModule struct {
+		name   string
+		closer io.Closer
+	}
+)
+
+// addModule registers a loaded module, to be closed when the daemon shuts
+// down. Modules must be added in the order they are loaded.
+func (srv *Server) addModule(name string, closer io.Closer) {
+	srv.shutdownMu.Lock()
+	defer srv.shutdownMu.Unlock()
+	srv.modules = append(srv.modules, loadedModule{name: name, closer: closer})
+	srv.shutdown.ModulesTotal = len(srv.modules)
+}
+
+// requestStop asks the daemon to shut down. It can be called more than once.
+func (srv *Server) requestStop() {
+	srv.shutdownMu.Lock()
+	defer srv.shutdownMu.Unlock()
+	if !srv.shutdown.Stopping {
+		srv.shutdown.Stopping = true
+		close(srv.stopChan)
+	}
+}
+
+// trackRequests is middleware that counts the requests in flight, so that
+// the daemon can wait for them before closing the modules. Once the daemon is
+// stopping, new requests are refused, and the context of the requests in
+// flight is cancelled.
+func (srv *Server) trackRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		srv.shutdownMu.Lock()
+		if srv.shutdown.Stopping {
+			srv.shutdownMu.Unlock()
+			api.WriteError(w, api.Error{Message: "siad is shutting down"}, http.StatusServiceUnavailable)
+			return
+		}
+		srv.shutdown.InFlightRequests++
+		srv.inFlight.Add(1)
+		srv.shutdownMu.Unlock()
+		defer func() {
+			srv.shutdownMu.Lock()
+			srv.shutdown.InFlightRequests--
+			srv.shutdownMu.Unlock()
+			srv.inFlight.Done()
+		}()
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		go func() {
+			select {
+			case <-srv.stopChan:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		h.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// drainRequests waits for the requests in flight to finish, or for timeout
+// to expire. It returns false if requests are still in flight.
+func (srv *Server) drainRequests(timeout time.Duration) bool {
+	drained := make(chan struct{})
+	go func() {
+		srv.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// closeModules closes the loaded modules in the reverse of the order they
+// were loaded in, and reports the progress. It can be called more than once;
+// each module is closed only once.
+func (srv *Server) closeModules() {
+	for {
+		srv.shutdownMu.Lock()
+		if len(srv.modules) == 0 {
+			srv.shutdown.Closing = ""
+			srv.shutdownMu.Unlock()
+			return
+		}
+		m := srv.modules[len(srv.modules)-1]
+		srv.modules = srv.modules[:len(srv.modules)-1]
+		srv.shutdown.Closing = m.name
+		srv.shutdownMu.Unlock()
+
+		fmt.Printf("Closing %v...\n", m.name)
+		if err := m.closer.Close(); err != nil {
+			fmt.Printf("Error during %v shutdown: %v\n", m.name, err)
+		}
+
+		srv.shutdownMu.Lock()
+		srv.shutdown.ModulesClosed++
+		srv.shutdownMu.Unlock()
+	}
+}
+
+// shutdownModules waits for the requests in flight and closes the modules,
+// once the daemon is stopping.
+func (srv *Server) shutdownModules() {
+	srv.requestStop()
+	if !srv.drainRequests(shutdownDrainTimeout) {
+		fmt.Println("Requests are still in flight after", shutdownDrainTimeout, "closing the modules anyway")
+	}
+	srv.closeModules()
+}
+
+// daemonShutdownHandler handles the API call that requests the progress of
+// shutting down the daemon.
+func (srv *Server) daemonShutdownHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	srv.shutdownMu.Lock()
+	ds := srv.shutdown
+	srv.shutdownMu.Unlock()
+	api.WriteJSON(w, ds)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// closerFunc is an io.Closer that calls a function.
+type closerFunc func() error
+
+// Close implements io.Closer.
+func (f closerFunc) Close() error { return f() }
+
+// TestShutdown checks that the daemon stops taking requests, waits for the
+// requests in flight, and closes the modules in the reverse of the order they
+// were loaded in.
+func TestShutdown(t *testing.T) {
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	var closed []string
+	for _, name := range []string{"gateway", "consensus", "wallet", "host"} {
+		name := name
+		srv.addModule(name, closerFunc(func() error {
+			closed = append(closed, name)
+			if name == "wallet" {
+				return errors.New("wallet error")
+			}
+			return nil
+		}))
+	}
+	shutdown := func() (ds DaemonShutdown) {
+		req := httptest.NewRequest("GET", "/daemon/shutdown", nil)
+		rec := httptest.NewRecorder()
+		srv.daemonHandler("").ServeHTTP(rec, req)
+		if err := json.NewDecoder(rec.Body).Decode(&ds); err != nil {
+			t.Fatal(err)
+		}
+		return ds
+	}
+	if ds := shutdown(); ds.Stopping || ds.ModulesTotal != 4 {
+		t.Fatalf("unexpected shutdown progress: %+v", ds)
+	}
+
+	// Start a long-polling request, which returns when its context is
+	// cancelled.
+	h := srv.trackRequests(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+		w.WriteHeader(http.StatusOK)
+	}))
+	started := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		close(started)
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/consensus/waitforblock", nil))
+		done <- rec.Code
+	}()
+	<-started
+	for i := 0; i < 100 && shutdown().InFlightRequests != 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ds := shutdown(); ds.InFlightRequests != 1 {
+		t.Fatalf("expected a request in flight: %+v", ds)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.daemonHandler("").ServeHTTP(rec, httptest.NewRequest("GET", "/daemon/stop", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatal("expected /daemon/stop to succeed, got", rec.Code)
+	}
+	select {
+	case <-srv.stopChan:
+	default:
+		t.Fatal("expected the daemon to be stopping")
+	}
+	if code := <-done; code != http.StatusOK {
+		t.Fatal("expected the request in flight to finish, got", code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/consensus", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected new requests to be refused, got", rec.Code)
+	}
+
+	srv.shutdownModules()
+	if want := []string{"host", "wallet", "consensus", "gateway"}; !reflect.DeepEqual(closed, want) {
+		t.Fatalf("modules were closed in the wrong order: %v", closed)
+	}
+	if ds := shutdown(); !ds.Stopping || ds.InFlightRequests != 0 || ds.Closing != "" || ds.ModulesClosed != 4 {
+		t.Fatalf("unexpected shutdown progress: %+v", ds)
+	}
+
+	// Closing the modules again does nothing.
+	srv.closeModules()
+	if len(closed) != 4 {
+		t.Fatal("modules were closed twice:", closed)
+	}
+}
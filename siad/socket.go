@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// siad serves its API on a Unix socket with --api-socket, in addition to the
+// TCP address of --api-addr, or instead of it if --api-addr is empty. The
+// socket is only accessible to the user running siad, so on shared machines it
+// locks the API down to that user without an API password. siac connects to
+// the socket with --addr unix:///path/to/socket.
+
+const (
+	// apiSocketPerm are the permissions of the API socket.
+	apiSocketPerm = 0600
+)
+
+var (
+	// errNoAPIListener is returned when neither a TCP address nor a socket
+	// is given for the API.
+	errNoAPIListener = errors.New("--api-addr cannot be empty unless --api-socket is set")
+
+	// errSocketTLS is returned when --api-tls is used without a TCP address.
+	errSocketTLS = errors.New("--api-tls requires --api-addr, the API socket does not use TLS")
+)
+
+// processSocket checks the socket flags, and makes the socket path absolute.
+func processSocket(config Config) (Config, error) {
+	if config.Siad.APIaddr == "" {
+		if config.Siad.APISocket == "" {
+			return config, errNoAPIListener
+		}
+		if config.Siad.APITLS {
+			return config, errSocketTLS
+		}
+	}
+	if config.Siad.APISocket != "" {
+		path, err := filepath.Abs(config.Siad.APISocket)
+		if err != nil {
+			return config, err
+		}
+		config.Siad.APISocket = path
+	}
+	return config, nil
+}
+
+// listenSocket makes the server listen on a Unix socket at path as well. A
+// stale socket left by a siad that did not shut down cleanly is replaced. It
+// must be called before Serve.
+func (srv *Server) listenSocket(path string) error {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return errors.New(path + " exists and is not a socket")
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return errors.New("another process is listening on " + path)
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, apiSocketPerm); err != nil {
+		l.Close()
+		return err
+	}
+	srv.socket = l
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// TestProcessSocket checks that the API needs a TCP address or a socket, and
+// that TLS is not served on the socket alone.
+func TestProcessSocket(t *testing.T) {
+	var config Config
+	if _, err := processSocket(config); err != errNoAPIListener {
+		t.Fatal("expected errNoAPIListener, got", err)
+	}
+	config.Siad.APISocket = "siad.sock"
+	config, err := processSocket(config)
+	if err != nil || !filepath.IsAbs(config.Siad.APISocket) {
+		t.Fatal("expected an absolute socket path, got", config.Siad.APISocket, err)
+	}
+	config.Siad.APITLS = true
+	if _, err := processSocket(config); err != errSocketTLS {
+		t.Fatal("expected errSocketTLS, got", err)
+	}
+	config.Siad.APIaddr = "localhost:9980"
+	if _, err := processSocket(config); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestServeSocket checks that the API is served on a Unix socket that only
+// the current user can access.
+func TestServeSocket(t *testing.T) {
+	dir := build.TempDir("siad", t.Name())
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "siad.sock")
+
+	// Leave a stale socket behind, as a siad that crashed would.
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	srv, err := NewServer("", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.listenSocket(path); err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error)
+	go func() {
+		served <- srv.Serve()
+	}()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != apiSocketPerm {
+		t.Fatalf("expected socket permissions %v, got %v", os.FileMode(apiSocketPerm), fi.Mode().Perm())
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	req, err := http.NewRequest("GET", "http://siad/daemon/version", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "Sia-Agent")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("expected 200, got", resp.Status)
+	}
+
+	// A second siad cannot take over the socket.
+	srv2, err := NewServer("", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv2.listenSocket(path); err == nil {
+		t.Fatal("expected the socket to be in use")
+	}
+
+	// The socket is removed when the server is closed.
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("socket was not removed:", err)
+	}
+}
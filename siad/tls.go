@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// siad serves its API over HTTPS with --api-tls. The certificate is read from
+// --api-tls-cert and --api-tls-key if given; otherwise siad generates a
+// self-signed certificate and saves it in the sia directory, so that it stays
+// the same across restarts. The SHA-256 fingerprint of the certificate is
+// printed at startup, for siac's --cert-fingerprint.
+
+const (
+	// apiCertFile and apiKeyFile are the names of the files in the sia
+	// directory that contain the self-signed certificate of the API and its
+	// private key.
+	apiCertFile = "apicert.pem"
+	apiKeyFile  = "apikey.pem"
+
+	// apiCertValidity is how long a self-signed certificate is valid.
+	apiCertValidity = 10 * 365 * 24 * time.Hour
+)
+
+var (
+	// errTLSCertKey is returned when only one of --api-tls-cert and
+	// --api-tls-key is given.
+	errTLSCertKey = errors.New("--api-tls-cert and --api-tls-key must be used together")
+)
+
+// processTLS checks the TLS flags. Giving a certificate implies --api-tls.
+func processTLS(config Config) (Config, error) {
+	if (config.Siad.APITLSCert == "") != (config.Siad.APITLSKey == "") {
+		return config, errTLSCertKey
+	}
+	if config.Siad.APITLSCert != "" {
+		config.Siad.APITLS = true
+	}
+	return config, nil
+}
+
+// generateAPICertificate creates a self-signed certificate for the API,
+// valid for localhost and for the host of the API address, and writes it and
+// its private key to certFile and keyFile.
+func generateAPICertificate(apiAddr, certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Sia"}, CommonName: "siad"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(apiCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if host, _, err := net.SplitHostPort(apiAddr); err == nil && host != "" && host != "localhost" {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsLoopback() && !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// loadAPICertificate returns the certificate of the API, generating a
+// self-signed certificate if none was given and none was generated before.
+func loadAPICertificate(config Config) (tls.Certificate, error) {
+	certFile, keyFile := config.Siad.APITLSCert, config.Siad.APITLSKey
+	if certFile == "" {
+		certFile = filepath.Join(config.Siad.SiaDir, apiCertFile)
+		keyFile = filepath.Join(config.Siad.SiaDir, apiKeyFile)
+		if _, err := os.Stat(certFile); os.IsNotExist(err) {
+			if err := os.MkdirAll(config.Siad.SiaDir, 0700); err != nil {
+				return tls.Certificate{}, err
+			}
+			if err := generateAPICertificate(config.Siad.APIaddr, certFile, keyFile); err != nil {
+				return tls.Certificate{}, fmt.Errorf("unable to generate a certificate: %v", err)
+			}
+		}
+	}
+	return tls.LoadX509KeyPair(certFile, keyFile)
+}
+
+// certificateFingerprint returns the SHA-256 fingerprint of a certificate, in
+// the colon-separated hex format printed by openssl.
+func certificateFingerprint(cert tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(cert.Certificate[0])
+	hexBytes := make([]string, len(sum))
+	for i, b := range sum {
+		hexBytes[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(hexBytes, ":")
+}
+
+// apiTLSConfig returns the TLS configuration of the API server.
+func apiTLSConfig(cert tls.Certificate) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// enableTLS makes the server serve HTTPS with the certificate. It must be
+// called before Serve.
+func (srv *Server) enableTLS(cert tls.Certificate) {
+	srv.listener = tls.NewListener(srv.listener, apiTLSConfig(cert))
+}
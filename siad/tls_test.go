@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// TestProcessTLS checks that a certificate and key must be given together,
+// and that giving them enables TLS.
+func TestProcessTLS(t *testing.T) {
+	var config Config
+	config.Siad.APITLSCert = "cert.pem"
+	if _, err := processTLS(config); err != errTLSCertKey {
+		t.Fatal("expected errTLSCertKey, got", err)
+	}
+	config.Siad.APITLSKey = "key.pem"
+	config, err := processTLS(config)
+	if err != nil || !config.Siad.APITLS {
+		t.Fatal("expected TLS to be enabled, got", config.Siad.APITLS, err)
+	}
+}
+
+// TestLoadAPICertificate checks that a self-signed certificate is generated
+// once and reused afterwards.
+func TestLoadAPICertificate(t *testing.T) {
+	var config Config
+	config.Siad.SiaDir = build.TempDir("siad", t.Name())
+	config.Siad.APIaddr = "sia.tech:9980"
+	os.RemoveAll(config.Siad.SiaDir)
+
+	cert, err := loadAPICertificate(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "sia.tech"} {
+		if err := leaf.VerifyHostname(host); err != nil {
+			t.Error(err)
+		}
+	}
+
+	reloaded, err := loadAPICertificate(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := certificateFingerprint(cert)
+	if fp != certificateFingerprint(reloaded) {
+		t.Fatal("certificate was generated again")
+	}
+	if len(fp) != sha256.Size*3-1 || strings.Count(fp, ":") != sha256.Size-1 {
+		t.Fatal("bad fingerprint format:", fp)
+	}
+}
+
+// TestServeTLS checks that the API is served over HTTPS with the
+// certificate whose fingerprint is printed.
+func TestServeTLS(t *testing.T) {
+	var config Config
+	config.Siad.SiaDir = build.TempDir("siad", t.Name())
+	os.RemoveAll(config.Siad.SiaDir)
+	cert, err := loadAPICertificate(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := NewServer("localhost:0", "Sia-Agent", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.enableTLS(cert)
+	go srv.Serve()
+
+	fp, err := hex.DecodeString(strings.Replace(certificateFingerprint(cert), ":", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			sum := sha256.Sum256(rawCerts[0])
+			if hex.EncodeToString(sum[:]) != hex.EncodeToString(fp) {
+				return errors.New("fingerprint mismatch")
+			}
+			return nil
+		},
+	}}}
+	req, err := http.NewRequest("GET", "https://"+srv.listener.Addr().String()+"/daemon/version", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "Sia-Agent")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("expected 200, got", resp.Status)
+	}
+
+	// Plain HTTP is not served.
+	req.URL.Scheme = "http"
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Fatal("API was served over plain HTTP")
+		}
+	}
+}
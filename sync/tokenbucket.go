@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket limits the rate at which tokens, such as bytes sent over a
+// network, are consumed. Tokens accumulate at a fixed rate up to a burst size.
+// A consumer may take more tokens than the bucket holds, putting the bucket
+// into debt that later consumers have to wait for, so that large consumers are
+// not starved by a steady stream of small ones.
+type TokenBucket struct {
+	rate   float64 // tokens per second, zero if unlimited
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	mu sync.Mutex
+}
+
+// NewTokenBucket returns a full token bucket that is refilled at 'rate' tokens
+// per second, up to 'burst' tokens. A rate of zero disables the limit.
+func NewTokenBucket(rate, burst uint64) *TokenBucket {
+	return &TokenBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill adds the tokens that have accumulated since the last refill.
+func (tb *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(tb.last); elapsed > 0 {
+		tb.tokens += elapsed.Seconds() * tb.rate
+		tb.last = now
+	}
+	if tb.tokens > tb.burst || tb.rate == 0 {
+		tb.tokens = tb.burst
+	}
+}
+
+// reserveAt takes n tokens from the bucket at time 'now', and returns how
+// long the caller has to wait before consuming them.
+func (tb *TokenBucket) reserveAt(n uint64, now time.Time) time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill(now)
+	if tb.rate == 0 {
+		return 0
+	}
+	tb.tokens -= float64(n)
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+// Rate returns the rate at which the bucket is refilled, in tokens per second.
+func (tb *TokenBucket) Rate() uint64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return uint64(tb.rate)
+}
+
+// Reserve takes n tokens from the bucket, and returns how long the caller has
+// to wait before consuming them.
+func (tb *TokenBucket) Reserve(n uint64) time.Duration {
+	return tb.reserveAt(n, time.Now())
+}
+
+// SetRate changes the rate and burst size of the bucket. Tokens that were
+// accumulated before the change are kept, up to the new burst size, and so is
+// any debt. A bucket whose limit was disabled starts out full.
+func (tb *TokenBucket) SetRate(rate, burst uint64) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill(time.Now())
+	if tb.rate == 0 {
+		tb.tokens = float64(burst)
+	}
+	tb.rate, tb.burst = float64(rate), float64(burst)
+	tb.refill(tb.last)
+}
@@ -0,0 +1,63 @@
+package sync
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTokenBucket checks that a token bucket delays consumers once its burst
+// is used up, and that it refills at its rate.
+func TestTokenBucket(t *testing.T) {
+	tb := NewTokenBucket(1000, 500)
+	now := tb.last
+
+	// The burst can be consumed immediately.
+	if d := tb.reserveAt(500, now); d != 0 {
+		t.Fatal("burst should not be delayed, got", d)
+	}
+	// The next 250 tokens take a quarter of a second to accumulate, and the
+	// 250 after that another quarter.
+	if d := tb.reserveAt(250, now); d != 250*time.Millisecond {
+		t.Fatal("expected a delay of 250ms, got", d)
+	}
+	if d := tb.reserveAt(250, now); d != 500*time.Millisecond {
+		t.Fatal("expected a delay of 500ms, got", d)
+	}
+	// After the debt is repaid, the bucket refills up to its burst size.
+	now = now.Add(10 * time.Second)
+	if d := tb.reserveAt(500, now); d != 0 {
+		t.Fatal("refilled bucket should not delay, got", d)
+	}
+	if d := tb.reserveAt(1, now); d != time.Millisecond {
+		t.Fatal("bucket refilled beyond its burst size, delay", d)
+	}
+}
+
+// TestTokenBucketSetRate checks that changing the rate of a token bucket takes
+// effect immediately, and that a rate of zero disables the limit.
+func TestTokenBucketSetRate(t *testing.T) {
+	tb := NewTokenBucket(0, 0)
+	if d := tb.Reserve(1 << 30); d != 0 {
+		t.Fatal("unlimited bucket should not delay, got", d)
+	}
+
+	tb.SetRate(100, 0)
+	if tb.Rate() != 100 {
+		t.Fatal("rate was not changed")
+	}
+	if d := tb.Reserve(100); d < 900*time.Millisecond || d > time.Second {
+		t.Fatal("expected a delay of about a second, got", d)
+	}
+	tb.SetRate(200, 0)
+	if d := tb.Reserve(100); d < 900*time.Millisecond || d > time.Second {
+		t.Fatal("debt should be repaid at the new rate, got", d)
+	}
+
+	// Disabling the limit clears the debt, and the bucket starts out full
+	// when the limit is enabled again.
+	tb.SetRate(0, 0)
+	tb.SetRate(100, 100)
+	if d := tb.Reserve(100); d != 0 {
+		t.Fatal("debt should be cleared when the limit is disabled, got", d)
+	}
+}
@@ -143,6 +143,31 @@ func (b Block) MerkleRoot() crypto.Hash {
 	return tree.Root()
 }
 
+// MerkleProof returns the leaf at the given index of the Block's Merkle tree,
+// along with the hash set proving that the leaf is part of the Merkle root.
+// The leaves are ordered as in MerkleRoot. The proof can be checked with
+// crypto.VerifySegment.
+func (b Block) MerkleProof(index uint64) (leaf []byte, hashSet []crypto.Hash) {
+	tree := crypto.NewTree()
+	tree.SetIndex(index)
+	for _, payout := range b.MinerPayouts {
+		tree.PushObject(payout)
+	}
+	for _, txn := range b.Transactions {
+		tree.PushObject(txn)
+	}
+	_, proof, _, _ := tree.Prove()
+	if len(proof) == 0 {
+		return nil, nil
+	}
+	leaf = proof[0]
+	hashSet = make([]crypto.Hash, len(proof)-1)
+	for i, p := range proof[1:] {
+		copy(hashSet[i][:], p)
+	}
+	return leaf, hashSet
+}
+
 // MinerPayoutID returns the ID of the miner payout at the given index, which
 // is calculated by hashing the concatenation of the BlockID and the payout
 // index.
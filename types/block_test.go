@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/NebulousLabs/Sia/crypto"
@@ -207,6 +208,36 @@ func TestBlockCalculateSubsidy(t *testing.T) {
 	}
 }
 
+// TestBlockMerkleProof probes the MerkleProof function of the block type.
+func TestBlockMerkleProof(t *testing.T) {
+	b := Block{
+		MinerPayouts: []SiacoinOutput{
+			{Value: CalculateCoinbase(0)},
+			{Value: CalculateCoinbase(1)},
+		},
+		Transactions: []Transaction{
+			{ArbitraryData: [][]byte{{1}}},
+			{ArbitraryData: [][]byte{{2}}},
+			{ArbitraryData: [][]byte{{3}}},
+		},
+	}
+	root := b.MerkleRoot()
+	numLeaves := uint64(len(b.MinerPayouts) + len(b.Transactions))
+	for i := uint64(0); i < numLeaves; i++ {
+		leaf, hashSet := b.MerkleProof(i)
+		if !crypto.VerifySegment(leaf, hashSet, numLeaves, i, root) {
+			t.Error("proof of leaf", i, "does not verify")
+		}
+		if crypto.VerifySegment(leaf, hashSet, numLeaves, (i+1)%numLeaves, root) {
+			t.Error("proof of leaf", i, "verifies at the wrong index")
+		}
+	}
+	leaf, _ := b.MerkleProof(2)
+	if !bytes.Equal(leaf, encoding.Marshal(b.Transactions[0])) {
+		t.Error("wrong leaf returned for the first transaction")
+	}
+}
+
 // TestBlockMinerPayoutID probes the MinerPayout function of the block type.
 func TestBlockMinerPayoutID(t *testing.T) {
 	// Create a block with 2 miner payouts, and check that each payout has a
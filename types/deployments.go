@@ -0,0 +1,111 @@
+package types
+
+// deployments.go contains the consensus rule changes that miners can signal
+// support for. Signaling follows the version bits scheme: each deployment is
+// assigned a bit, and a block signals support for a deployment by including
+// an arbitrary data entry with that bit set. Once enough blocks in a window
+// signal support, the deployment locks in, and it activates one window later.
+
+import (
+	"encoding/binary"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+type (
+	// A Deployment is a consensus rule change that activates once enough
+	// miners signal support for it.
+	Deployment struct {
+		// Name is a short, human readable name for the deployment.
+		Name string
+
+		// Bit is the signaling bit assigned to the deployment. Bits may be
+		// reused once a previous deployment using the bit has activated or
+		// failed.
+		Bit uint
+
+		// Signaling is counted in the windows starting at or after
+		// StartHeight. If the deployment has not locked in by TimeoutHeight,
+		// it fails.
+		StartHeight   BlockHeight
+		TimeoutHeight BlockHeight
+	}
+)
+
+var (
+	// SpecifierDeploymentSignal prefixes the arbitrary data entry that a block
+	// uses to signal support for deployments. The specifier is followed by an
+	// 8 byte little-endian bitfield.
+	SpecifierDeploymentSignal = Specifier{'d', 'e', 'p', 'l', 'o', 'y', 'm', 'e', 'n', 't', ' ', 's', 'i', 'g'}
+
+	// DeploymentWindow is the number of blocks in each signaling window. The
+	// first window starts at the genesis block.
+	DeploymentWindow = build.Select(build.Var{
+		Dev:      BlockHeight(144),
+		Standard: BlockHeight(2016),
+		Testing:  BlockHeight(10),
+	}).(BlockHeight)
+
+	// DeploymentThreshold is the number of blocks in a window that need to
+	// signal support for a deployment for it to lock in.
+	DeploymentThreshold = build.Select(build.Var{
+		Dev:      BlockHeight(108),  // 75%
+		Standard: BlockHeight(1916), // 95%
+		Testing:  BlockHeight(8),    // 80%
+	}).(BlockHeight)
+
+	// Deployments lists the consensus rule changes that are known to this
+	// version of Sia.
+	Deployments = []Deployment{
+		{
+			// testdummy is used to exercise the signaling code. It never
+			// starts outside of dev builds.
+			Name: "testdummy",
+			Bit:  28,
+			StartHeight: build.Select(build.Var{
+				Dev:      BlockHeight(0),
+				Standard: BlockHeight(1 << 62),
+				Testing:  BlockHeight(1 << 62),
+			}).(BlockHeight),
+			TimeoutHeight: build.Select(build.Var{
+				Dev:      BlockHeight(10e3),
+				Standard: BlockHeight(1 << 62),
+				Testing:  BlockHeight(1 << 62),
+			}).(BlockHeight),
+		},
+	}
+)
+
+// DeploymentSignal returns an arbitrary data entry that signals support for
+// the deployments whose bits are set in the bitfield.
+func DeploymentSignal(bits uint64) []byte {
+	signal := make([]byte, SpecifierLen+8)
+	copy(signal, SpecifierDeploymentSignal[:])
+	binary.LittleEndian.PutUint64(signal[SpecifierLen:], bits)
+	return signal
+}
+
+// DeploymentSignalBits returns the bits of the deployments that the block
+// signals support for. The bitfields of all signaling entries in the block are
+// combined.
+func (b Block) DeploymentSignalBits() (bits uint64) {
+	for _, txn := range b.Transactions {
+		for _, arb := range txn.ArbitraryData {
+			if len(arb) != SpecifierLen+8 {
+				continue
+			}
+			var prefix Specifier
+			copy(prefix[:], arb)
+			if prefix != SpecifierDeploymentSignal {
+				continue
+			}
+			bits |= binary.LittleEndian.Uint64(arb[SpecifierLen:])
+		}
+	}
+	return bits
+}
+
+// Signals returns true if the bit of the deployment is set in the bitfield.
+func (d Deployment) Signals(bits uint64) bool {
+	return bits&(1<<d.Bit) != 0
+}
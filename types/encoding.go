@@ -169,10 +169,20 @@ func (d *decHelper) NextUint64() uint64 {
 
 // NextPrefix is like NextUint64, but performs sanity checks on the prefix.
 // Specifically, if the prefix multiplied by elemSize exceeds
-// encoding.MaxSliceSize, NextPrefix returns 0 and sets d.Err().
+// encoding.MaxSliceSize, or if the prefix exceeds the number of bytes left in
+// an in-memory input, NextPrefix returns 0 and sets d.Err().
 func (d *decHelper) NextPrefix(elemSize uintptr) uint64 {
 	n := d.NextUint64()
-	if n > 1<<31-1 || n*uint64(elemSize) > encoding.MaxSliceSize {
+	if n > encoding.MaxSliceLen || n*uint64(elemSize) > encoding.MaxSliceSize {
+		d.err = encoding.ErrSliceTooLarge
+		return 0
+	}
+	// Every element takes at least a byte to encode, so a prefix larger than
+	// the remaining input is invalid. Rejecting it here prevents a short
+	// input from causing a large allocation.
+	if lr, ok := d.r.(interface {
+		Len() int
+	}); ok && n > uint64(lr.Len()) {
 		d.err = encoding.ErrSliceTooLarge
 		return 0
 	}
@@ -165,6 +165,23 @@ func TestTooLargeDecoder(t *testing.T) {
 	}
 }
 
+// TestDecodePrefixExceedsInput checks that length prefixes larger than the
+// remaining input are rejected before the slice is allocated.
+func TestDecodePrefixExceedsInput(t *testing.T) {
+	// a transaction claiming 1e5 siacoin inputs, followed by less than 1e5
+	// bytes
+	enc := append(encoding.EncUint64(1e5), make([]byte, 1e3)...)
+	var txn Transaction
+	if err := encoding.Unmarshal(enc, &txn); err == nil || !strings.Contains(err.Error(), encoding.ErrSliceTooLarge.Error()) {
+		t.Fatal("expected ErrSliceTooLarge, got", err)
+	}
+	// a currency longer than the remaining input
+	var c Currency
+	if err := encoding.Unmarshal(append(encoding.EncUint64(10), 1, 2, 3), &c); err == nil || !strings.Contains(err.Error(), encoding.ErrSliceTooLarge.Error()) {
+		t.Fatal("expected ErrSliceTooLarge, got", err)
+	}
+}
+
 // TestCurrencyMarshalJSON probes the MarshalJSON and UnmarshalJSON functions
 // of the currency type.
 func TestCurrencyMarshalJSON(t *testing.T) {
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/encoding"
+)
+
+// fuzzDecode checks that decoding arbitrary data into obj, which must be a
+// pointer, never panics, and that an object that decodes successfully
+// survives a round trip: its encoding decodes to an object with the same
+// encoding. The encoding of some types, such as Currency, is not canonical,
+// so the encoding is not compared to data itself.
+func fuzzDecode(t *testing.T, data []byte, obj interface{}) {
+	if err := encoding.Unmarshal(data, obj); err != nil {
+		return
+	}
+	enc := encoding.Marshal(reflect.ValueOf(obj).Elem().Interface())
+	obj2 := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+	if err := encoding.Unmarshal(enc, obj2); err != nil {
+		t.Fatal("re-encoded object could not be decoded:", err)
+	}
+	if enc2 := encoding.Marshal(reflect.ValueOf(obj2).Elem().Interface()); !bytes.Equal(enc, enc2) {
+		t.Fatalf("object changed after a round trip:\n%x\n%x", enc, enc2)
+	}
+}
+
+// FuzzBlock fuzzes the decoding of blocks, which are relayed by peers.
+func FuzzBlock(f *testing.F) {
+	f.Add(encoding.Marshal(Block{}))
+	f.Add(encoding.Marshal(heavyBlock))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzDecode(t, data, new(Block))
+	})
+}
+
+// FuzzBlockHeader fuzzes the decoding of block headers, which are relayed by
+// peers and submitted by miners.
+func FuzzBlockHeader(f *testing.F) {
+	f.Add(encoding.Marshal(heavyBlock.Header()))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzDecode(t, data, new(BlockHeader))
+	})
+}
+
+// FuzzTransactionSet fuzzes the decoding of transaction sets, which are
+// relayed by peers and sent to hosts by renters.
+func FuzzTransactionSet(f *testing.F) {
+	f.Add(encoding.Marshal([]Transaction{}))
+	f.Add(encoding.Marshal(heavyBlock.Transactions))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzDecode(t, data, new([]Transaction))
+	})
+}
+
+// FuzzFileContractRevision fuzzes the decoding of file contract revisions,
+// which are sent to hosts by renters.
+func FuzzFileContractRevision(f *testing.F) {
+	f.Add(encoding.Marshal(heavyBlock.Transactions[0].FileContractRevisions[0]))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzDecode(t, data, new(FileContractRevision))
+	})
+}
+
+// FuzzTransactionSignatures fuzzes the decoding of transaction signatures,
+// which are exchanged by renters and hosts.
+func FuzzTransactionSignatures(f *testing.F) {
+	f.Add(encoding.Marshal(heavyBlock.Transactions[0].TransactionSignatures))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzDecode(t, data, new([]TransactionSignature))
+	})
+}
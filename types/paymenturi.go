@@ -0,0 +1,163 @@
+package types
+
+// paymenturi.go defines the sia: URI scheme, which encodes a request for a
+// payment to an address. The scheme follows BIP 21: the address is the path of
+// the URI and the amount is given in siacoins, so that URIs are readable and
+// can be produced by web shops and point-of-sale systems without a Sia node.
+//
+//	sia:<address>?amount=1.5&label=Coffee&message=Order%2042
+//
+// A request that is only valid for a limited time carries its expiry as a
+// Unix timestamp in the expires parameter. Parsers that do not know the
+// parameter ignore it.
+
+import (
+	"errors"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaymentURIScheme is the scheme of a payment URI.
+const PaymentURIScheme = "sia"
+
+var (
+	errPaymentURIScheme  = errors.New("payment URI must begin with " + PaymentURIScheme + ":")
+	errPaymentURIAmount  = errors.New("payment URI amount must be a non-negative number of siacoins with at most 24 decimal places")
+	errPaymentURIUnknown = errors.New("payment URI contains an unsupported required parameter")
+	errPaymentURIExpires = errors.New("payment URI expiry must be a Unix timestamp")
+)
+
+// A PaymentURI is a request for a payment to an address. A zero Amount means
+// that the payer chooses the amount. Label and Message are descriptions of the
+// recipient and the payment respectively, and are not part of the transaction.
+// A zero Expires means that the request does not expire.
+type PaymentURI struct {
+	Address UnlockHash
+	Amount  Currency
+	Label   string
+	Message string
+	Expires Timestamp
+}
+
+// ParsePaymentURI parses a sia: payment URI. As in BIP 21, unknown parameters
+// are ignored unless they are prefixed with "req-", in which case the URI is
+// rejected because the request cannot be honored.
+func ParsePaymentURI(s string) (PaymentURI, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return PaymentURI{}, err
+	}
+	if !strings.EqualFold(u.Scheme, PaymentURIScheme) {
+		return PaymentURI{}, errPaymentURIScheme
+	}
+	// Tolerate the sia://<address> form produced by some URI libraries.
+	addr := u.Opaque
+	if addr == "" {
+		addr = u.Host
+	}
+
+	var pu PaymentURI
+	if err := pu.Address.LoadString(addr); err != nil {
+		return PaymentURI{}, err
+	}
+	q, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return PaymentURI{}, err
+	}
+	for key := range q {
+		switch key {
+		case "amount":
+			pu.Amount, err = parseSiacoins(q.Get(key))
+			if err != nil {
+				return PaymentURI{}, err
+			}
+		case "label":
+			pu.Label = q.Get(key)
+		case "message":
+			pu.Message = q.Get(key)
+		case "expires":
+			expires, err := strconv.ParseUint(q.Get(key), 10, 64)
+			if err != nil {
+				return PaymentURI{}, errPaymentURIExpires
+			}
+			pu.Expires = Timestamp(expires)
+		default:
+			if strings.HasPrefix(key, "req-") {
+				return PaymentURI{}, errPaymentURIUnknown
+			}
+		}
+	}
+	return pu, nil
+}
+
+// String returns the sia: URI encoding of the payment request.
+func (pu PaymentURI) String() string {
+	q := make(url.Values)
+	if !pu.Amount.IsZero() {
+		q.Set("amount", formatSiacoins(pu.Amount))
+	}
+	if pu.Label != "" {
+		q.Set("label", pu.Label)
+	}
+	if pu.Message != "" {
+		q.Set("message", pu.Message)
+	}
+	if pu.Expires != 0 {
+		q.Set("expires", strconv.FormatUint(uint64(pu.Expires), 10))
+	}
+	s := PaymentURIScheme + ":" + pu.Address.String()
+	if len(q) != 0 {
+		// url.Values encodes spaces as '+', which BIP 21 parsers do not
+		// necessarily decode.
+		s += "?" + strings.Replace(q.Encode(), "+", "%20", -1)
+	}
+	return s
+}
+
+// Expired returns whether the payment request has expired at time t.
+func (pu PaymentURI) Expired(t Timestamp) bool {
+	return pu.Expires != 0 && t > pu.Expires
+}
+
+// QRPayload returns the URI in the form recommended for QR codes: the scheme
+// and address are upper case, so that QR encoders can store them in the
+// compact alphanumeric mode. Parsers treat both as case-insensitive.
+func (pu PaymentURI) QRPayload() string {
+	s := pu.String()
+	prefix := PaymentURIScheme + ":" + pu.Address.String()
+	return strings.ToUpper(prefix) + s[len(prefix):]
+}
+
+// parseSiacoins parses a decimal number of siacoins into hastings. Amounts
+// that are not a whole number of hastings are rejected rather than rounded.
+func parseSiacoins(s string) (Currency, error) {
+	if s == "" || strings.ContainsAny(s, "-+eE/") {
+		return Currency{}, errPaymentURIAmount
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Currency{}, errPaymentURIAmount
+	}
+	r.Mul(r, new(big.Rat).SetInt(SiacoinPrecision.Big()))
+	if !r.IsInt() {
+		return Currency{}, errPaymentURIAmount
+	}
+	return NewCurrency(r.Num()), nil
+}
+
+// formatSiacoins returns the exact decimal number of siacoins in c, without
+// trailing zeros.
+func formatSiacoins(c Currency) string {
+	const decimals = 24
+	s := c.String()
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
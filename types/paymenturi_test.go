@@ -0,0 +1,80 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPaymentURIRoundTrip checks that payment URIs survive being encoded and
+// parsed.
+func TestPaymentURIRoundTrip(t *testing.T) {
+	var uh UnlockHash
+	uh[0] = 1
+	uris := []PaymentURI{
+		{Address: uh},
+		{Address: uh, Amount: SiacoinPrecision.Mul64(3).Div64(2)},
+		{Address: uh, Amount: NewCurrency64(1), Label: "Coffee & Cake", Message: "Order 42"},
+		{Address: uh, Amount: SiacoinPrecision.Mul64(1e6)},
+		{Address: uh, Amount: NewCurrency64(1), Expires: 1500000000},
+	}
+	for _, pu := range uris {
+		parsed, err := ParsePaymentURI(pu.String())
+		if err != nil {
+			t.Fatal(pu.String(), err)
+		}
+		if parsed.Address != pu.Address || !parsed.Amount.Equals(pu.Amount) ||
+			parsed.Label != pu.Label || parsed.Message != pu.Message || parsed.Expires != pu.Expires {
+			t.Errorf("%v parsed as %+v", pu.String(), parsed)
+		}
+	}
+
+	if s := (PaymentURI{Address: uh, Amount: SiacoinPrecision.Mul64(3).Div64(2), Label: "a b"}).String(); s != "sia:"+uh.String()+"?amount=1.5&label=a%20b" {
+		t.Error("unexpected encoding:", s)
+	}
+
+	// The QR payload parses to the same request.
+	pu := PaymentURI{Address: uh, Amount: SiacoinPrecision, Label: "Coffee", Expires: 1500000000}
+	payload := pu.QRPayload()
+	if payload != "SIA:"+strings.ToUpper(uh.String())+"?amount=1&expires=1500000000&label=Coffee" {
+		t.Error("unexpected QR payload:", payload)
+	}
+	if parsed, err := ParsePaymentURI(payload); err != nil || parsed.Address != pu.Address || !parsed.Amount.Equals(pu.Amount) || parsed.Expires != pu.Expires {
+		t.Errorf("QR payload %v parsed as %+v (%v)", payload, parsed, err)
+	}
+}
+
+// TestParsePaymentURI probes the parsing of valid and invalid payment URIs.
+func TestParsePaymentURI(t *testing.T) {
+	var uh UnlockHash
+	addr := uh.String()
+
+	tests := []struct {
+		uri    string
+		amount Currency
+		valid  bool
+	}{
+		{"sia:" + addr, ZeroCurrency, true},
+		{"SIA:" + addr + "?amount=2", SiacoinPrecision.Mul64(2), true},
+		{"sia://" + addr + "?amount=0.000000000000000000000001", NewCurrency64(1), true},
+		{"sia:" + addr + "?amount=1&foo=bar", SiacoinPrecision, true},
+		{"sia:" + addr + "?amount=0.0000000000000000000000001", ZeroCurrency, false},
+		{"sia:" + addr + "?amount=-1", ZeroCurrency, false},
+		{"sia:" + addr + "?amount=1e3", ZeroCurrency, false},
+		{"sia:" + addr + "?amount=", ZeroCurrency, false},
+		{"sia:" + addr + "?req-foo=bar", ZeroCurrency, false},
+		{"sia:" + addr + "?expires=1500000000", ZeroCurrency, true},
+		{"sia:" + addr + "?expires=tomorrow", ZeroCurrency, false},
+		{"bitcoin:" + addr, ZeroCurrency, false},
+		{"sia:" + addr[1:], ZeroCurrency, false},
+	}
+	for _, test := range tests {
+		pu, err := ParsePaymentURI(test.uri)
+		if test.valid && err != nil {
+			t.Errorf("%v: unexpected error: %v", test.uri, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%v: expected an error", test.uri)
+		} else if test.valid && !pu.Amount.Equals(test.amount) {
+			t.Errorf("%v: expected amount %v, got %v", test.uri, test.amount, pu.Amount)
+		}
+	}
+}